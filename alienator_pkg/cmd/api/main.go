@@ -3,6 +3,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,18 +16,26 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/websocket"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/ruvnet/alienator/internal/analyzers/catalog"
 	"github.com/ruvnet/alienator/internal/api/graphql"
 	"github.com/ruvnet/alienator/internal/api/rest"
 	"github.com/ruvnet/alienator/internal/api/ws"
 	"github.com/ruvnet/alienator/internal/config"
-	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/hashing"
+	"github.com/ruvnet/alienator/internal/jobs"
+	"github.com/ruvnet/alienator/internal/logging"
 	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
 	"github.com/ruvnet/alienator/internal/repository"
+	"github.com/ruvnet/alienator/internal/server"
 	"github.com/ruvnet/alienator/internal/services"
+	"github.com/ruvnet/alienator/internal/signing"
+	"github.com/ruvnet/alienator/internal/version"
 	"github.com/ruvnet/alienator/pkg/metrics"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
 )
 
@@ -50,15 +60,43 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	showVersion := flag.Bool("version", false, "print version information and exit")
+	flag.Parse()
+	if *showVersion {
+		fmt.Println(version.Get())
+		return
+	}
+
 	// Load configuration
-	cfg := config.Load()
+	configPath := os.Getenv(config.ConfigFileEnv)
+	cfg, err := config.LoadFile(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
 
 	// Initialize logger
-	logger, _ := zap.NewProduction()
+	logger, logLevel, err := logging.NewLogger(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
 	defer logger.Sync()
 
-	// Initialize metrics
+	// Initialize metrics, batching per-analyzer updates when configured
+	batchConfig := metrics.DefaultBatchConfig()
 	metrics := metrics.NewMetrics()
+	if cfg.Metrics.BatchInterval > 0 {
+		batchConfig.FlushInterval = cfg.Metrics.BatchInterval
+		batchConfig.MaxPending = cfg.Metrics.BatchSize
+		if err := metrics.EnableBatching(batchConfig); err != nil {
+			logger.Fatal("Invalid metrics batching configuration", zap.Error(err))
+		}
+		defer metrics.Close()
+	}
+
+	// Cache keys and de-duplication fingerprints share one hash
+	if err := hashing.Configure(cfg.Hashing); err != nil {
+		logger.Fatal("Invalid hashing configuration", zap.Error(err))
+	}
 
 	// Initialize repository
 	repo := repository.NewRepository(cfg, logger)
@@ -66,11 +104,44 @@ func main() {
 
 	// Initialize services
 	anomalyService := services.NewAnomalyService(repo, logger)
+	anomalyService.SetPrecision(cfg.Output.Precision)
+	anomalyService.SetReviewBand(cfg.Review.Band)
+	anomalyService.SetExplainOnly(cfg.ExplainOnly)
+	anomalyService.SetProvenance(cfg.Provenance)
+	if cfg.Signing.Enabled {
+		signer, err := signing.NewSigner(cfg.Signing)
+		if err != nil {
+			logger.Fatal("Invalid signing configuration", zap.Error(err))
+		}
+		anomalyService.SetSigner(signer)
+		logger.Info("Signing detection results",
+			zap.String("algorithm", signer.Algorithm()),
+			zap.String("key_id", signer.KeyID()),
+		)
+	}
 	userService := services.NewUserService(repo, logger)
 	authService := services.NewAuthService(cfg, logger)
 
-	// Initialize anomaly detector
-	detector := core.NewAnomalyDetector(logger, metrics)
+	// Background jobs are queued in Redis for the worker to run
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	defer redisClient.Close()
+	jobService := services.NewJobService(jobs.NewRedisStore(redisClient, cfg.Worker.Jobs), cfg.Worker.Jobs, logger)
+
+	// Initialize anomaly detector, and one per preset for requests naming it
+	detector, err := catalog.NewDetector(cfg.Detector, logger, metrics)
+	if err != nil {
+		logger.Fatal("Invalid detector configuration", zap.Error(err))
+	}
+	anomalyService.SetDetector(detector)
+	presetDetectors, err := catalog.NewPresetDetectors(cfg.Detector, logger, metrics)
+	if err != nil {
+		logger.Fatal("Invalid detector preset", zap.Error(err))
+	}
+	anomalyService.SetPresets(presetDetectors)
 
 	// Initialize Gin router
 	router := gin.Default()
@@ -80,21 +151,68 @@ func main() {
 	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger(logger))
 	router.Use(middleware.Recovery())
-	router.Use(middleware.RateLimit(cfg.RateLimit))
+	// Negotiated first, so that rejections below answer in the requested schema
+	router.Use(middleware.SchemaVersion(cfg.Versioning))
+	// Shed load before any work is done for a request the server has no room for
+	requestLimiter := middleware.NewConcurrencyLimiter("all", cfg.Concurrency.MaxInFlight, cfg.Concurrency.RetryAfter, metrics)
+	analysisLimiter := middleware.NewConcurrencyLimiter("analysis", cfg.Concurrency.AnalysisMaxInFlight, cfg.Concurrency.RetryAfter, metrics)
+	router.Use(requestLimiter.Handler())
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit)
+	router.Use(rateLimiter.Handler())
+	requestLimits := middleware.NewRequestLimits(cfg.Limits)
+	router.Use(requestLimits.Handler())
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy", "timestamp": time.Now()})
 	})
 
+	// Readiness endpoint: not ready until the detector has warmed up, nor
+	// while a required analyzer is unhealthy, nor once shutdown has begun
+	drainer := server.NewDrainer(logger, requestLimiter, analysisLimiter)
+	router.GET("/ready", func(c *gin.Context) {
+		if drainer.Draining() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down", "timestamp": time.Now()})
+			return
+		}
+		if !detector.Ready() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "warming_up", "timestamp": time.Now()})
+			return
+		}
+		if !detector.Healthy() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "analyzer_unhealthy", "timestamp": time.Now()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ready", "timestamp": time.Now()})
+	})
+
+	// Version endpoint
+	router.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"build":                version.Get(),
+			"detector_fingerprint": detector.Fingerprint(),
+		})
+	})
+
 	// Metrics endpoint
 	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
-	// Swagger documentation
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// OpenAPI document, generated from the handler annotations, and its Swagger UI
+	router.GET("/openapi.json", rest.ServeOpenAPI)
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL("/openapi.json")))
 
 	// REST API routes
-	restHandler := rest.NewHandler(detector, anomalyService, userService, authService, logger)
+	restHandler := rest.NewHandler(detector, anomalyService, userService, authService, logger, logLevel)
+	restHandler.SetAnalysisGuards(analysisLimiter.Handler())
+	restHandler.SetJobService(jobService)
+	restHandler.SetLimits(requestLimits, rateLimiter)
+	restHandler.SetMetadataFilter(models.MetadataFilter{
+		Level: models.MetadataLevel(cfg.Output.Metadata),
+		Keys:  cfg.Output.MetadataKeys,
+	})
+	if cfg.Server.TLS.Enabled && cfg.Server.TLS.AdminClientAuth {
+		restHandler.SetAdminGuards(middleware.RequireClientCert())
+	}
 	v1 := router.Group("/api/v1")
 	v1.Use(middleware.Auth(authService))
 	restHandler.SetupRoutes(v1)
@@ -121,27 +239,113 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Serve HTTPS when configured, with certificates reloaded on rotation
+	var certReloader *server.CertReloader
+	var redirectSrv *http.Server
+	if cfg.Server.TLS.Enabled {
+		certReloader, err = server.NewCertReloader(cfg.Server.TLS, logger)
+		if err != nil {
+			logger.Fatal("Failed to load TLS certificate", zap.Error(err))
+		}
+		srv.TLSConfig, err = certReloader.TLSConfig(cfg.Server.TLS.MinVersion)
+		if err != nil {
+			logger.Fatal("Invalid TLS configuration", zap.Error(err))
+		}
+
+		if cfg.Server.TLS.RedirectPort != 0 {
+			redirectSrv = &http.Server{
+				Addr:         fmt.Sprintf(":%d", cfg.Server.TLS.RedirectPort),
+				Handler:      server.RedirectHandler(cfg.Server.Port),
+				ReadTimeout:  5 * time.Second,
+				WriteTimeout: 5 * time.Second,
+			}
+			go func() {
+				logger.Info("Redirecting HTTP to HTTPS", zap.Int("port", cfg.Server.TLS.RedirectPort))
+				if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					logger.Fatal("Failed to start HTTP redirect server", zap.Error(err))
+				}
+			}()
+		}
+	}
+
 	// Start server in a goroutine
 	go func() {
 		logger.Info("Starting API server",
 			zap.Int("port", cfg.Port),
+			zap.Bool("tls", cfg.Server.TLS.Enabled),
 			zap.String("environment", cfg.Environment),
+			zap.String("version", version.Version),
+			zap.String("commit", version.Commit),
 		)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if cfg.Server.TLS.Enabled {
+			// The certificate comes from the reloader's TLS configuration
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Fatal("Failed to start server", zap.Error(err))
 		}
 	}()
 
+	// Warm up the detector and check its analyzers' health in the background,
+	// retrying until it succeeds or the server shuts down; /ready reports 503
+	// until done, or while a required analyzer is unhealthy, so the load
+	// balancer holds traffic back
+	warmupCtx, stopWarmup := context.WithCancel(context.Background())
+	defer stopWarmup()
+	go func() {
+		if err := detector.WarmupWithRetry(warmupCtx, cfg.Detector.WarmupTimeout, cfg.Detector.WarmupBackoff); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Detector warm-up failed", zap.Error(err))
+		}
+	}()
+
+	// Reload detector thresholds, log level, rate and concurrency limits on SIGHUP
+	reloader := config.NewReloader(configPath, cfg, logger)
+	reloader.OnReload(func(cfg *config.Config) error { return detector.ApplyConfig(cfg.Detector) })
+	reloader.OnReload(func(cfg *config.Config) error {
+		for name, presetDetector := range presetDetectors {
+			resolved, err := cfg.Detector.WithPreset(name)
+			if err != nil {
+				return err
+			}
+			if err := presetDetector.ApplyConfig(resolved); err != nil {
+				return fmt.Errorf("preset %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	reloader.OnReload(logging.ReloadLevel(logLevel))
+	reloader.OnReload(func(cfg *config.Config) error {
+		rateLimiter.Update(cfg.RateLimit)
+		requestLimits.Update(cfg.Limits)
+		requestLimiter.Update(cfg.Concurrency.MaxInFlight, cfg.Concurrency.RetryAfter)
+		analysisLimiter.Update(cfg.Concurrency.AnalysisMaxInFlight, cfg.Concurrency.RetryAfter)
+		return nil
+	})
+	if certReloader != nil {
+		reloader.OnReload(func(*config.Config) error { return certReloader.Reload() })
+	}
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	go reloader.HandleSignals(reloadCtx)
+
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	logger.Info("Shutting down server...")
+	stopWarmup()
+	logger.Info("Shutting down server...",
+		zap.Duration("drain_delay", cfg.Server.DrainDelay),
+		zap.Duration("timeout", cfg.Server.ShutdownTimeout),
+	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
+	servers := []*http.Server{srv}
+	if redirectSrv != nil {
+		servers = append(servers, redirectSrv)
+	}
+	if err := drainer.Shutdown(cfg.Server.DrainDelay, cfg.Server.ShutdownTimeout, servers...); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
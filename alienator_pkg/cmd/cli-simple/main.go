@@ -1,11 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/ruvnet/alienator/internal/analyzers/compression"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/config"
 	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/sink"
+	"github.com/ruvnet/alienator/pkg/detector"
+	"github.com/ruvnet/alienator/internal/version"
 	"github.com/ruvnet/alienator/pkg/metrics"
 	"go.uber.org/zap"
 )
@@ -30,6 +41,15 @@ var rootCmd = &cobra.Command{
 `,
 }
 
+var (
+	analyzeContentType  string
+	analyzeLanguage     string
+	analyzeSample       float64
+	analyzeSampleWindow int
+	analyzeCalibration  string
+	analyzeTranscript   bool
+)
+
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze [file]",
 	Short: "🔬 XENOTYPE SCAN - Deep analysis of potential non-human intelligence patterns",
@@ -44,6 +64,17 @@ var analyzeCmd = &cobra.Command{
 		metrics := metrics.NewMetrics()
 		detector := core.NewAnomalyDetector(logger, metrics)
 
+		linguisticAnalyzer := linguistic.NewLinguisticAnalyzer()
+		if err := linguisticAnalyzer.Configure(map[string]interface{}{
+			"language":         analyzeLanguage,
+			"calibration_file": analyzeCalibration,
+		}); err != nil {
+			logger.Fatal("Invalid linguistic configuration", zap.Error(err))
+		}
+		detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+		detector.RegisterAnalyzer(linguisticAnalyzer)
+		detector.RegisterAnalyzer(compression.NewCompressionAnalyzer())
+
 		content, err := os.ReadFile(filename)
 		if err != nil {
 			logger.Fatal("Failed to read file", zap.Error(err))
@@ -59,11 +90,30 @@ var analyzeCmd = &cobra.Command{
 		fmt.Println("    ⚡ Initializing quantum pattern analyzers...")
 		fmt.Println("    🌌 Hyperdimensional matrix loading...")
 		
-		result, err := detector.AnalyzeText(string(content))
+		if analyzeTranscript {
+			printConversation(detector, string(content), logger)
+			return
+		}
+
+		contentType, err := core.ParseContentType(analyzeContentType)
+		if err != nil {
+			logger.Fatal("Invalid content type", zap.Error(err))
+		}
+
+		var result *models.AnomalyResult
+		if analyzeSample > 0 {
+			sampling := core.DefaultSamplingConfig()
+			sampling.Fraction = analyzeSample
+			sampling.WindowSize = analyzeSampleWindow
+			result, err = detector.AnalyzeTextSampled(string(content), contentType, sampling)
+		} else {
+			result, err = detector.AnalyzeTextAs(string(content), contentType)
+		}
 		if err != nil {
 			fmt.Println("    ❌ CRITICAL ERROR: Analysis system failure")
 			logger.Fatal("Analysis failed", zap.Error(err))
 		}
+		writeToSinks(filename, string(content), result, logger)
 
 		fmt.Println("\n" + `    ╔═══════════════════════════════════════════════════════════════╗`)
 		fmt.Println("    ║                    🔬 ANALYSIS RESULTS 🔬                    ║")
@@ -78,7 +128,17 @@ var analyzeCmd = &cobra.Command{
 			statusIcon = "🔴"
 		}
 		fmt.Printf("    ║  %s NON-HUMAN SIGNAL:       %-15s              ║\n", statusIcon, threatLevel)
+		fmt.Printf("    ║  📶 SEVERITY:                %-15s              ║\n", result.Severity)
+		fmt.Printf("    ║  📄 CONTENT TYPE:            %-15v              ║\n", result.Metadata["content_type"])
+		fmt.Printf("    ║  🧭 ANALYZER PROFILE:        %-15v              ║\n", result.Metadata["profile"])
+		if info, ok := result.Metadata["sampling"].(core.SampleInfo); ok && info.Strategy != core.SamplingStrategyFull {
+			fmt.Printf("    ║  ✂️ SAMPLED COVERAGE:        %5.1f%% (%d windows)          ║\n", info.Coverage*100, info.Windows)
+		}
+		if result.Outcome == models.OutcomeEmptyInput {
+			fmt.Println("    ║  🕳️ NO SIGNAL: target contains no analyzable words            ║")
+		}
 		fmt.Println("    ╚═══════════════════════════════════════════════════════════════╝")
+		printLanguage(result)
 
 		if len(result.Details) > 0 {
 			fmt.Println("\n    🔍 DETAILED XENOTYPE SIGNATURE ANALYSIS:")
@@ -88,6 +148,9 @@ var analyzeCmd = &cobra.Command{
 			}
 			fmt.Println("    └─────────────────────────────────────────────────────────────┘")
 		}
+
+		fmt.Println("\n    🗒️ FIELD REPORT:")
+		fmt.Printf("    %s\n", detector.ExplainText(result))
 		
 		fmt.Println("\n    📊 SCAN COMPLETE - Data archived to classified databases")
 		if result.IsAnomalous {
@@ -97,6 +160,24 @@ var analyzeCmd = &cobra.Command{
 	},
 }
 
+// writeToSinks routes the result for text through the sinks enabled in the
+// configuration file ($ALIENATOR_CONFIG) and environment
+func writeToSinks(filename, text string, result *models.AnomalyResult, logger *zap.Logger) {
+	cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	sinks, err := sink.New(cfg.Sinks)
+	if err != nil {
+		logger.Fatal("Failed to open result sinks", zap.Error(err))
+	}
+	defer sinks.Close()
+
+	if err := sinks.Write(context.Background(), sink.NewRecord(filename, "cli", result).WithInput(text)); err != nil {
+		logger.Error("Failed to write result to sinks", zap.Error(err))
+	}
+}
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "🌌 SYSTEM STATUS - Check detection array and scanner systems",
@@ -125,6 +206,15 @@ var statusCmd = &cobra.Command{
 		fmt.Println("    │  🚨 Alert Status:               [DEFCON 3 - ELEVATED]      │")
 		fmt.Println("    └─────────────────────────────────────────────────────────────┘")
 		
+		build := version.Get()
+		fmt.Println("\n    🏷️  BUILD:")
+		fmt.Println("    ┌─────────────────────────────────────────────────────────────┐")
+		fmt.Printf("    │  Version:    %-47s│\n", build.Version)
+		fmt.Printf("    │  Commit:     %-47s│\n", build.Commit)
+		fmt.Printf("    │  Built:      %-47s│\n", build.BuildDate)
+		fmt.Printf("    │  Runtime:    %-47s│\n", build.GoVersion+" "+build.Platform)
+		fmt.Println("    └─────────────────────────────────────────────────────────────┘")
+
 		fmt.Println("\n    🔍 CONTINUOUS MONITORING ACTIVE")
 		fmt.Println("    👽 Scanning for: Non-human intelligence patterns")
 		fmt.Println("    🌌 Monitoring: Quantum flux anomalies")
@@ -133,9 +223,202 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+var selftestThreshold float64
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "🧪 CALIBRATION RUN - Score the detection array against a labelled reference corpus",
+	Long:  "📊 Run all xenotype analyzers over a built-in labelled corpus and report precision, recall, F1,\nMatthews correlation coefficient and the calibration curve",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger, _ := zap.NewDevelopment()
+		defer logger.Sync()
+
+		ad := core.NewAnomalyDetector(logger, metrics.NewMetrics())
+		ad.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+		ad.RegisterAnalyzer(linguistic.NewLinguisticAnalyzer())
+		ad.RegisterAnalyzer(compression.NewCompressionAnalyzer())
+
+		m, err := ad.SelfTest(context.Background(), selftestThreshold)
+		if err != nil {
+			logger.Fatal("Self-test failed", zap.Error(err))
+		}
+
+		fmt.Println(`
+    ╔═══════════════════════════════════════════════════════════════╗
+    ║               🧪 DETECTION ARRAY SELF-TEST 🧪                ║
+    ╚═══════════════════════════════════════════════════════════════╝`)
+		fmt.Printf("    Samples: %d   Threshold: %.2f\n", m.TotalPredictions, m.Threshold)
+		fmt.Printf("    TP: %d   FP: %d   TN: %d   FN: %d\n", m.TruePositives, m.FalsePositives, m.TrueNegatives, m.FalseNegatives)
+		fmt.Printf("    Accuracy:  %6.3f\n", m.Accuracy)
+		fmt.Printf("    Precision: %6.3f\n", m.Precision)
+		fmt.Printf("    Recall:    %6.3f\n", m.Recall)
+		fmt.Printf("    F1:        %6.3f\n", m.F1Score)
+		fmt.Printf("    MCC:       %6.3f\n", m.MCC)
+		fmt.Printf("    ECE:       %6.3f\n", m.ExpectedCalibrationError)
+
+		fmt.Println("\n    📈 CALIBRATION CURVE (mean predicted vs. observed positive rate):")
+		for _, bin := range m.Calibration {
+			fmt.Printf("      [%.1f, %.1f)  n=%-3d  predicted=%.3f  observed=%.3f\n",
+				bin.Lower, bin.Upper, bin.Count, bin.MeanPredicted, bin.FractionTrue)
+		}
+	},
+}
+
+// printLanguage shows the analysis language and, when it was auto-detected,
+// the ranked candidates so uncertain detections are visible
+func printLanguage(result *models.AnomalyResult) {
+	detail, ok := result.Details["linguistic"]
+	if !ok {
+		return
+	}
+
+	if forced, _ := detail.Metadata["language_forced"].(bool); forced {
+		fmt.Printf("\n    🗣️ LANGUAGE: %v (forced)\n", detail.Metadata["detected_language"])
+		return
+	}
+
+	reliability := "reliable"
+	if reliable, _ := detail.Metadata["language_reliable"].(bool); !reliable {
+		reliability = "⚠️ unreliable, confidence reduced"
+	}
+	fmt.Printf("\n    🗣️ LANGUAGE: %v (%s)\n", detail.Metadata["detected_language"], reliability)
+	if candidates, ok := detail.Metadata["language_candidates"].([]linguistic.LanguageCandidate); ok {
+		for i, candidate := range candidates {
+			fmt.Printf("      %d. %-12s %-4s confidence=%.2f\n", i+1, candidate.Language, candidate.Code, candidate.Confidence)
+		}
+	}
+}
+
+// printConversation scores a "role: text" transcript turn by turn and
+// reports which speakers look machine-generated
+func printConversation(ad *core.AnomalyDetector, transcript string, logger *zap.Logger) {
+	turns, err := core.ParseTranscript(transcript)
+	if err != nil {
+		logger.Fatal("Invalid transcript", zap.Error(err))
+	}
+	conversation, err := ad.AnalyzeConversation(turns)
+	if err != nil {
+		fmt.Println("    ❌ CRITICAL ERROR: Analysis system failure")
+		logger.Fatal("Analysis failed", zap.Error(err))
+	}
+
+	fmt.Println("\n    💬 TRANSMISSION LOG ANALYSIS:")
+	fmt.Println("    ┌─────────────────────────────────────────────────────────────┐")
+	for _, turn := range conversation.Turns {
+		icon := "🟢"
+		if turn.Result.IsAnomalous {
+			icon = "🔴"
+		}
+		fmt.Printf("    │  %s #%-3d %-20s score=%5.2f  confidence=%5.2f │\n", icon, turn.Index+1, turn.Role, turn.Result.Score, turn.Result.Confidence)
+	}
+	fmt.Println("    └─────────────────────────────────────────────────────────────┘")
+
+	roles := make([]string, 0, len(conversation.Speakers))
+	for role := range conversation.Speakers {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	fmt.Println("\n    👥 SPEAKER PROFILES:")
+	for _, role := range roles {
+		speaker := conversation.Speakers[role]
+		fmt.Printf("      %-20s turns=%-3d anomalous=%-3d score=%5.2f  severity=%s\n",
+			role, speaker.Turns, speaker.AnomalousTurns, speaker.Score, speaker.Severity)
+	}
+
+	aggregate := conversation.Aggregate
+	fmt.Printf("\n    👽 CONVERSATION ANOMALY SCORE: %.2f (confidence %.2f, severity %s)\n", aggregate.Score, aggregate.Confidence, aggregate.Severity)
+	if len(conversation.AnomalousSpeakers) > 0 {
+		fmt.Printf("    🚨 WARNING: Non-human signal traced to: %s\n", strings.Join(conversation.AnomalousSpeakers, ", "))
+	}
+}
+
+var (
+	calibrateHuman    string
+	calibrateOut      string
+	calibrateMinWords int
+)
+
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "Derive linguistic baselines from a known-human corpus",
+	Long:  "Split the corpus into documents of --min-words words, measure each linguistic feature over them and write the 5th-95th percentile range of every feature as a calibration file. Pass the file to analyze --calibration to score against it instead of the built-in ranges.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := os.ReadFile(calibrateHuman)
+		if err != nil {
+			fmt.Printf("❌ Failed to read corpus: %v\n", err)
+			os.Exit(1)
+		}
+
+		opts := linguistic.DefaultCalibrationOptions()
+		opts.MinWords = calibrateMinWords
+		calibration, err := linguistic.NewLinguisticAnalyzer().Calibrate(linguistic.SplitCorpus(string(content), opts.MinWords), opts)
+		if err != nil {
+			fmt.Printf("❌ Calibration failed: %v\n", err)
+			os.Exit(1)
+		}
+		calibration.Source = calibrateHuman
+
+		if err := calibration.Save(calibrateOut); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Calibrated %d features on %d documents, written to %s\n", len(calibration.Features), calibration.Documents, calibrateOut)
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "🛠️ CONFIGURATION MATRIX - Inspect detection array settings",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "🧪 Validate a configuration file together with the environment",
+	Long:  "Load the YAML or JSON configuration file (default: $" + config.ConfigFileEnv + "), overlay environment variables and report every problem found.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := os.Getenv(config.ConfigFileEnv)
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		if _, err := config.LoadFile(path); err != nil {
+			fmt.Printf("    ❌ %v\n", err)
+			os.Exit(1)
+		}
+		if path == "" {
+			path = "defaults and environment"
+		}
+		fmt.Printf("    ✅ Configuration matrix stable (%s)\n", path)
+	},
+}
+
 func init() {
+	rootCmd.Version = version.Get().String()
+
+	analyzeCmd.Flags().StringVar(&analyzeContentType, "content-type", "auto", "input content type: auto, prose, code, data or log")
+	analyzeCmd.Flags().StringVar(&analyzeLanguage, "language", "auto", "force the analysis language (ISO 639-1 code such as es), or auto to detect it")
+	analyzeCmd.Flags().Float64Var(&analyzeSample, "sample", 0, "analyze only this fraction (0-1] of long texts (head, random middle windows and tail); 0 analyzes the full text")
+	analyzeCmd.Flags().BoolVar(&analyzeTranscript, "transcript", false, "treat the file as a \"role: text\" chat transcript and score each turn and speaker")
+	analyzeCmd.Flags().IntVar(&analyzeSampleWindow, "sample-window", core.DefaultSamplingConfig().WindowSize, "characters per sampled window")
+	analyzeCmd.Flags().StringVar(&analyzeCalibration, "calibration", "", "score linguistic features against the human baselines in this file, written by calibrate")
+
+	selftestCmd.Flags().Float64Var(&selftestThreshold, "threshold", detector.DefaultEvaluationThreshold, "score above which a sample is counted as anomalous")
+
 	rootCmd.AddCommand(analyzeCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(selftestCmd)
+
+	calibrateCmd.Flags().StringVar(&calibrateHuman, "human", "", "known-human corpus, with paragraphs separated by blank lines")
+	calibrateCmd.Flags().StringVar(&calibrateOut, "out", "calibration.json", "calibration file to write")
+	calibrateCmd.Flags().IntVar(&calibrateMinWords, "min-words", linguistic.DefaultCalibrationOptions().MinWords, "words per calibration document")
+	calibrateCmd.MarkFlagRequired("human")
+	rootCmd.AddCommand(calibrateCmd)
+
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
 }
 
 func main() {
@@ -0,0 +1,14 @@
+//go:build !lite
+
+package main
+
+import (
+	"github.com/ruvnet/alienator/internal/analyzers/embedding"
+	"github.com/ruvnet/alienator/internal/core"
+)
+
+// outlierAnalyzers returns the analyzers marking the sentences that are
+// semantic outliers in the text, for --top-sentences
+func outlierAnalyzers() []core.Analyzer {
+	return []core.Analyzer{embedding.NewEmbeddingAnalyzer()}
+}
@@ -0,0 +1,11 @@
+//go:build lite
+
+package main
+
+import "github.com/ruvnet/alienator/internal/core"
+
+// outlierAnalyzers returns no analyzers: without embeddings the lite build
+// can't mark semantic outliers, so --top-sentences ranks by the others alone
+func outlierAnalyzers() []core.Analyzer {
+	return nil
+}
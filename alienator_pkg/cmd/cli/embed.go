@@ -0,0 +1,70 @@
+//go:build !lite
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ruvnet/alienator/internal/analyzers/embedding"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+	"github.com/spf13/cobra"
+)
+
+var embedOut string
+
+var embedCmd = &cobra.Command{
+	Use:   "embed [file]",
+	Short: "Export the sentence embeddings of a text as JSON",
+	Long:  "Embed each sentence of the file (long texts are sampled) and write the vectors with their sentence index, text and cluster, e.g. to cluster or visualize them elsewhere.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Printf("❌ Failed to read file: %v\n", err)
+			os.Exit(1)
+		}
+
+		analyzer := embedding.NewEmbeddingAnalyzer()
+		if err := analyzer.Configure(map[string]interface{}{"include_embeddings": true}); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		tokens := tokenizer.New(tokenizer.Default, string(content))
+		result, err := analyzer.AnalyzeTokens(context.Background(), tokens)
+		if err != nil {
+			fmt.Printf("❌ Embedding failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		embedded := embedding.SentenceEmbeddings(result, tokens.Sentences())
+		if embedded == nil {
+			embedded = []embedding.SentenceEmbedding{}
+		}
+		encoded, err := json.MarshalIndent(map[string]interface{}{
+			"dimension":  result.Metadata["embedding_dimension"],
+			"embeddings": embedded,
+		}, "", "  ")
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		if embedOut == "" {
+			fmt.Println(string(encoded))
+			return
+		}
+		if err := os.WriteFile(embedOut, append(encoded, '\n'), 0o644); err != nil {
+			fmt.Printf("❌ Failed to write %s: %v\n", embedOut, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Wrote %d embeddings to %s\n", len(embedded), embedOut)
+	},
+}
+
+func init() {
+	embedCmd.Flags().StringVar(&embedOut, "out", "", "write the JSON to this file instead of stdout")
+	rootCmd.AddCommand(embedCmd)
+}
@@ -3,15 +3,32 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/ruvnet/alienator/internal/alert"
+	"github.com/ruvnet/alienator/internal/analyzers/catalog"
+	"github.com/ruvnet/alienator/internal/analyzers/compression"
+	"github.com/ruvnet/alienator/internal/analyzers/cryptographic"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/analyzers/injection"
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/analyzers/template"
+	"github.com/ruvnet/alienator/internal/batch"
 	"github.com/ruvnet/alienator/internal/config"
 	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/repl"
+	"github.com/ruvnet/alienator/internal/sink"
 	"github.com/ruvnet/alienator/internal/models/proto"
 	"github.com/ruvnet/alienator/internal/services"
+	"github.com/ruvnet/alienator/internal/version"
 	"github.com/ruvnet/alienator/pkg/metrics"
 	"go.uber.org/zap"
 )
@@ -22,6 +39,22 @@ var rootCmd = &cobra.Command{
 	Long:  "A command-line interface for the Alienator advanced detection system that identifies potential non-human intelligence signatures in AI-generated outputs.",
 }
 
+var (
+	analyzeContentType  string
+	analyzeLanguage     string
+	analyzeSample       float64
+	analyzeSampleWindow int
+	analyzeCalibration  string
+	analyzeWindows      int
+	analyzeMixedSpread  float64
+	analyzeTopSentences int
+	analyzeSettings     []string
+	analyzeBootstrap    int
+	analyzeBoilerplate  bool
+	analyzePrecision    int
+	analyzeProfile      string
+)
+
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze [file]",
 	Short: "Analyze AI output for non-human intelligence signatures",
@@ -32,41 +65,343 @@ var analyzeCmd = &cobra.Command{
 		logger, _ := zap.NewDevelopment()
 		defer logger.Sync()
 
+		cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
+		if err != nil {
+			logger.Fatal("Failed to load configuration", zap.Error(err))
+		}
+		if analyzeBoilerplate {
+			cfg.Detector.Boilerplate.Enabled = true
+		}
+		if err := applyPreset(&cfg.Detector, analyzeProfile); err != nil {
+			logger.Fatal("Invalid preset", zap.Error(err))
+		}
+		precision := outputPrecision(cmd, analyzePrecision, cfg.Output, logger)
 		metrics := metrics.NewMetrics()
 		detector := core.NewAnomalyDetector(logger, metrics)
+		if err := detector.ApplyConfig(cfg.Detector); err != nil {
+			logger.Fatal("Invalid detector configuration", zap.Error(err))
+		}
+
+		linguisticAnalyzer := linguistic.NewLinguisticAnalyzer()
+		analyzers := []core.Analyzer{entropy.NewEntropyAnalyzer(), linguisticAnalyzer, compression.NewCompressionAnalyzer()}
+		if analyzeTopSentences > 0 {
+			analyzers = append(analyzers, outlierAnalyzers()...)
+		}
+		if analyzeProfile != "" {
+			analyzers = withAllAnalyzers(analyzers)
+		}
+		if err := catalog.Register(detector, cfg.Detector, analyzers...); err != nil {
+			logger.Fatal("Invalid analyzer configuration", zap.Error(err))
+		}
+		// The flags take precedence over the configured language and calibration
+		linguisticFlags := make(map[string]interface{})
+		if cmd.Flags().Changed("language") {
+			linguisticFlags["language"] = analyzeLanguage
+		}
+		if analyzeCalibration != "" {
+			linguisticFlags["calibration_file"] = analyzeCalibration
+		}
+		if err := linguisticAnalyzer.Configure(linguisticFlags); err != nil {
+			logger.Fatal("Invalid linguistic configuration", zap.Error(err))
+		}
+
+		overrides := make([]core.ParameterOverride, 0, len(analyzeSettings))
+		for _, setting := range analyzeSettings {
+			override, err := core.ParseParameterOverride(setting)
+			if err != nil {
+				logger.Fatal("Invalid --set flag", zap.Error(err))
+			}
+			overrides = append(overrides, override)
+		}
+		if err := detector.ApplyParameterOverrides(overrides); err != nil {
+			logger.Fatal("Invalid analyzer parameter override", zap.Error(err))
+		}
 
 		content, err := os.ReadFile(filename)
 		if err != nil {
 			logger.Fatal("Failed to read file", zap.Error(err))
 		}
 
-		result, err := detector.AnalyzeText(string(content))
+		contentType, err := core.ParseContentType(analyzeContentType)
+		if err != nil {
+			logger.Fatal("Invalid content type", zap.Error(err))
+		}
+
+		var result *models.AnomalyResult
+		var coAuthorship *core.CoAuthorship
+		if analyzeWindows > 0 {
+			windowing := core.DefaultWindowConfig()
+			windowing.WindowSize = analyzeWindows
+			windowing.MixedSpread = analyzeMixedSpread
+			var windowed *core.WindowedResult
+			windowed, err = detector.AnalyzeTextWindowed(string(content), contentType, windowing)
+			if err == nil {
+				result, coAuthorship = windowed.Aggregate, windowed.CoAuthorship
+			}
+		} else if analyzeSample > 0 {
+			sampling := core.DefaultSamplingConfig()
+			sampling.Fraction = analyzeSample
+			sampling.WindowSize = analyzeSampleWindow
+			result, err = detector.AnalyzeTextSampled(string(content), contentType, sampling)
+		} else if analyzeBootstrap > 0 {
+			bootstrap := core.DefaultBootstrapConfig()
+			bootstrap.Replicates = analyzeBootstrap
+			result, err = detector.AnalyzeTextBootstrapped(string(content), contentType, bootstrap)
+		} else {
+			result, err = detector.AnalyzeTextAs(string(content), contentType)
+		}
 		if err != nil {
 			logger.Fatal("Analysis failed", zap.Error(err))
 		}
+		if analyzeTopSentences > 0 {
+			result.TopSentences, err = detector.TopSentences(string(content), result, analyzeTopSentences)
+			if err != nil {
+				logger.Fatal("Sentence analysis failed", zap.Error(err))
+			}
+		}
+		result = result.Rounded(precision)
+		writeToSinks(filename, string(content), result, logger)
 
-		fmt.Printf("👽 Anomaly Score: %.2f\n", result.Score)
-		fmt.Printf("🎯 Confidence: %.2f\n", result.Confidence)
+		fmt.Printf("👽 Anomaly Score: %.*f\n", precision, result.Score)
+		fmt.Printf("🎯 Confidence: %.*f\n", precision, result.Confidence)
 		fmt.Printf("🚨 Non-Human Signal Detected: %t\n", result.IsAnomalous)
+		fmt.Printf("📶 Severity: %s\n", result.Severity)
+		fmt.Printf("📄 Content Type: %v (profile: %v)\n", result.Metadata["content_type"], result.Metadata["profile"])
+		if info, ok := result.Metadata["sampling"].(core.SampleInfo); ok && info.Strategy != core.SamplingStrategyFull {
+			fmt.Printf("✂️ Sampled: %d windows, %.0f%% coverage (%s)\n", info.Windows, info.Coverage*100, info.Strategy)
+		}
+		if removed, ok := result.Metadata["boilerplate_removed"].([]core.BoilerplateRemoval); ok {
+			fmt.Printf("🧹 Boilerplate removed: %d passages\n", len(removed))
+		}
+		if estimate, ok := result.Metadata["bootstrap"].(core.BootstrapEstimate); ok {
+			if estimate.Replicates > 0 {
+				fmt.Printf("📊 Bootstrap: mean %.*f ± %.*f, %.0f%% interval [%.*f, %.*f] (%d replicates of %d sentences, heuristic confidence %.*f)\n",
+					precision, estimate.Mean, precision, estimate.StdErr, estimate.Level*100, precision, estimate.Lower, precision, estimate.Upper,
+					estimate.Replicates, estimate.Sentences, precision, result.Metadata["heuristic_confidence"])
+			} else {
+				fmt.Printf("📊 Bootstrap: skipped, %d sentence(s) are too few to resample\n", estimate.Sentences)
+			}
+		}
+		if coAuthorship != nil {
+			fmt.Printf("🤝 Co-authored Likelihood: %.*f (AI fraction %.0f%%, %d windows, spread %.*f, reliable: %t)\n",
+				precision, coAuthorship.Likelihood, coAuthorship.AIFraction*100, coAuthorship.Windows, precision, coAuthorship.ScoreStdDev, coAuthorship.Reliable)
+		}
+		if detail, ok := result.Details["linguistic"]; ok {
+			fmt.Printf("🗣️ Language: %v (forced: %v, reliable: %v)\n", detail.Metadata["detected_language"],
+				detail.Metadata["language_forced"], detail.Metadata["language_reliable"])
+		}
 
 		if len(result.Details) > 0 {
 			fmt.Println("\n🔬 Detailed Analysis:")
 			for analyzer, detail := range result.Details {
-				fmt.Printf("  • %s: %.2f\n", analyzer, detail.Score)
+				fmt.Printf("  • %s: %.*f\n", analyzer, precision, detail.Score)
+			}
+		}
+
+		if len(result.TopSentences) > 0 {
+			fmt.Println("\n🔎 Most Anomalous Sentences:")
+			for i, sentence := range result.TopSentences {
+				outlier := ""
+				if sentence.Outlier {
+					outlier = ", semantic outlier"
+				}
+				fmt.Printf("  %d. [offset %d] score %.*f, contribution %.*f%s\n     %q\n",
+					i+1, sentence.Offset, precision, sentence.Score, precision, sentence.Contribution, outlier, sentence.Text)
 			}
 		}
 	},
 }
 
+var (
+	batchContentType string
+	batchBoilerplate bool
+	batchPrecision   int
+	batchConcurrency int
+	batchJSON        bool
+	batchProfile     string
+	batchTemplates   bool
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [files or directories...]",
+	Short: "Analyze many files in parallel",
+	Long: "Analyze each file, and every file under each directory (hidden ones excepted), with --concurrency workers, printing " +
+		"one line per file as it completes and a summary at the end. A running count of the files done is shown on stderr when it " +
+		"is a terminal. With --json each line is a JSON object instead and no count is shown. Files that can't be read or analyzed " +
+		"are reported and the exit status is 1. With --strip-boilerplate, or boilerplate stripping enabled in the configuration, " +
+		"paragraphs repeated across the files, such as a shared footer or disclaimer, are removed before analysis along with text " +
+		"matching the configured boilerplate patterns; the files are then read twice. With --templates each file is also scored by how " +
+		"much of it is scaffolding recurring across the files, as in texts filled in from one template, with the template analyzer's " +
+		"parameters under detector.analyzers.template; the files are then read twice too.",
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger, _ := zap.NewDevelopment()
+		defer logger.Sync()
+
+		cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
+		if err != nil {
+			logger.Fatal("Failed to load configuration", zap.Error(err))
+		}
+		if batchBoilerplate {
+			cfg.Detector.Boilerplate.Enabled = true
+		}
+		if err := applyPreset(&cfg.Detector, batchProfile); err != nil {
+			logger.Fatal("Invalid preset", zap.Error(err))
+		}
+		precision := outputPrecision(cmd, batchPrecision, cfg.Output, logger)
+		detector := core.NewAnomalyDetector(logger, metrics.NewMetrics())
+		if err := detector.ApplyConfig(cfg.Detector); err != nil {
+			logger.Fatal("Invalid detector configuration", zap.Error(err))
+		}
+		analyzers := []core.Analyzer{entropy.NewEntropyAnalyzer(), linguistic.NewLinguisticAnalyzer(), compression.NewCompressionAnalyzer()}
+		if batchProfile != "" {
+			analyzers = withAllAnalyzers(analyzers)
+		}
+		if err := catalog.Register(detector, cfg.Detector, analyzers...); err != nil {
+			logger.Fatal("Invalid analyzer configuration", zap.Error(err))
+		}
+
+		contentType, err := core.ParseContentType(batchContentType)
+		if err != nil {
+			logger.Fatal("Invalid content type", zap.Error(err))
+		}
+		opts := batch.Options{Concurrency: batchConcurrency, ContentType: contentType}
+		if batchTemplates {
+			opts.Templates = template.NewTemplateAnalyzer()
+			if err := opts.Templates.Configure(cfg.Detector.Analyzers[opts.Templates.Name()].Parameters); err != nil {
+				logger.Fatal("Invalid template analyzer configuration", zap.Error(err))
+			}
+		}
+		files, err := batch.Collect(args)
+		if err != nil {
+			logger.Fatal("Failed to list files", zap.Error(err))
+		}
+		sinks, err := sink.New(cfg.Sinks)
+		if err != nil {
+			logger.Fatal("Failed to open result sinks", zap.Error(err))
+		}
+		defer sinks.Close()
+
+		// Interrupting stops starting files; those in flight complete
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		progress := !batchJSON && isTerminal(os.Stderr)
+		encoder := json.NewEncoder(os.Stdout)
+		done := 0
+		summary := batch.Run(ctx, detector, files, opts, func(outcome batch.Outcome) {
+			done++
+			if progress {
+				fmt.Fprint(os.Stderr, "\r\033[K")
+			}
+			if outcome.Err != nil {
+				if batchJSON {
+					encoder.Encode(map[string]interface{}{"file": outcome.Path, "error": outcome.Err.Error()})
+				} else {
+					fmt.Printf("%s  ❌ %v\n", outcome.Path, outcome.Err)
+				}
+			} else {
+				result := outcome.Result.Rounded(precision)
+				if err := sinks.Write(ctx, sink.NewRecord(outcome.Path, "cli", result)); err != nil {
+					logger.Error("Failed to write result to sinks", zap.Error(err))
+				}
+				switch {
+				case batchJSON && outcome.Template != nil:
+					encoder.Encode(map[string]interface{}{"file": outcome.Path, "result": result, "template": outcome.Template})
+				case batchJSON:
+					encoder.Encode(map[string]interface{}{"file": outcome.Path, "result": result})
+				default:
+					removed, _ := result.Metadata["boilerplate_removed"].([]core.BoilerplateRemoval)
+					templated := ""
+					if outcome.Template != nil {
+						templated = fmt.Sprintf("  🧩 %.*f", precision, outcome.Template.Score)
+					}
+					fmt.Printf("%s  👽 %.*f  🎯 %.*f  🚨 %t  🧹 %d%s\n", outcome.Path, precision, result.Score, precision, result.Confidence, result.IsAnomalous, len(removed), templated)
+				}
+			}
+			if progress {
+				fmt.Fprintf(os.Stderr, "⏳ %d/%d files", done, len(files))
+			}
+		})
+		if progress {
+			fmt.Fprint(os.Stderr, "\r\033[K")
+		}
+
+		if batchJSON {
+			totals := map[string]interface{}{
+				"files":       summary.Files,
+				"analyzed":    summary.Analyzed,
+				"anomalous":   summary.Anomalous,
+				"failed":      summary.Failed,
+				"duration_ms": summary.Duration.Milliseconds(),
+			}
+			if batchTemplates {
+				totals["templated"] = summary.Templated
+			}
+			encoder.Encode(map[string]interface{}{"summary": totals})
+		} else {
+			fmt.Printf("\n%d files analyzed, %d anomalous, %d failed in %s\n", summary.Analyzed, summary.Anomalous, summary.Failed, summary.Duration.Round(time.Millisecond))
+			if batchTemplates {
+				fmt.Printf("%d files templated\n", summary.Templated)
+			}
+			if skipped := summary.Files - summary.Analyzed - summary.Failed; skipped > 0 {
+				fmt.Printf("%d files skipped after interruption\n", skipped)
+			}
+		}
+		if summary.Failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// outputPrecision returns the decimals scores are written with: the
+// --precision flag if given, otherwise the configured output precision
+func outputPrecision(cmd *cobra.Command, flag int, output config.OutputConfig, logger *zap.Logger) int {
+	if !cmd.Flags().Changed("precision") {
+		return output.Precision
+	}
+	if flag < 0 || flag > models.MaxPrecision {
+		logger.Fatal("Invalid --precision flag", zap.Int("precision", flag), zap.Int("max", models.MaxPrecision))
+	}
+	return flag
+}
+
+// writeToSinks routes the result for text through the sinks enabled in the
+// configuration file ($ALIENATOR_CONFIG) and environment
+func writeToSinks(filename, text string, result *models.AnomalyResult, logger *zap.Logger) {
+	cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	sinks, err := sink.New(cfg.Sinks)
+	if err != nil {
+		logger.Fatal("Failed to open result sinks", zap.Error(err))
+	}
+	defer sinks.Close()
+
+	if err := sinks.Write(context.Background(), sink.NewRecord(filename, "cli", result).WithInput(text)); err != nil {
+		logger.Error("Failed to write result to sinks", zap.Error(err))
+	}
+}
+
+var broadcastAlert bool
+
 var broadcastCmd = &cobra.Command{
 	Use:   "broadcast [channel] [message]",
 	Short: "Broadcast detected anomaly alerts to a channel",
+	Long:  "Broadcast a message to a channel. With --alert the message is analyzed and the anomaly alert is broadcast instead, rendered with the channel's template from broadcast.alerts.",
 	Args:  cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		channel := args[0]
 		messageText := args[1]
 
-		cfg, err := config.Load()
+		cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
 		if err != nil {
 			fmt.Printf("Failed to load configuration: %v\n", err)
 			os.Exit(1)
@@ -82,12 +417,44 @@ var broadcastCmd = &cobra.Command{
 		}
 		defer messageBroker.Close()
 
-		eventBus := core.NewEventBus(logger)
+		eventBus := core.NewEventBus(core.DefaultEventBusConfig(), logger)
 		defer eventBus.Close()
 
 		broadcastService := services.NewBroadcastService(messageBroker, eventBus, logger)
+		broadcastService.SetRetryPolicy(services.RetryPolicy{
+			MaxRetries:     cfg.Broadcast.MaxRetries,
+			InitialBackoff: cfg.Broadcast.InitialBackoff,
+			MaxBackoff:     cfg.Broadcast.MaxBackoff,
+			OnRetry: func(attempt int, delay time.Duration, err error) {
+				fmt.Printf("⚠️ Attempt %d/%d failed (%v), retrying in %s\n", attempt, cfg.Broadcast.MaxRetries+1, err, delay.Round(time.Millisecond))
+			},
+		})
 
 		ctx := context.Background()
+		if broadcastAlert {
+			templates, err := alert.FromConfig(cfg.Broadcast.Alerts)
+			if err != nil {
+				logger.Fatal("Invalid broadcast alert templates", zap.Error(err))
+			}
+			broadcastService.SetAlertTemplates(templates)
+
+			detector, err := catalog.NewDetector(cfg.Detector, logger, metrics.NewMetrics())
+			if err != nil {
+				logger.Fatal("Failed to initialize detector", zap.Error(err))
+			}
+			result, err := detector.AnalyzeText(messageText)
+			if err != nil {
+				logger.Fatal("Analysis failed", zap.Error(err))
+			}
+
+			anomaly := broadcastService.NewAlert(fmt.Sprintf("cli_%d", time.Now().UnixNano()), result, messageText)
+			if err := broadcastService.BroadcastAlert(ctx, channel, anomaly); err != nil {
+				logger.Fatal("Failed to broadcast alert", zap.Error(err))
+			}
+			fmt.Printf("🛸 %s anomaly alert (score %.2f) broadcast to channel '%s' successfully\n", anomaly.Severity, anomaly.Score, channel)
+			return
+		}
+
 		now := time.Now()
 		msg := &proto.Message{
 			ID:        fmt.Sprintf("msg_%d", time.Now().UnixNano()),
@@ -98,6 +465,10 @@ var broadcastCmd = &cobra.Command{
 		}
 
 		if err := broadcastService.Broadcast(ctx, channel, msg); err != nil {
+			var broadcastErr *services.BroadcastError
+			if errors.As(err, &broadcastErr) {
+				fmt.Printf("❌ Broadcast failed after %d attempt(s)\n", broadcastErr.Attempts)
+			}
 			logger.Fatal("Failed to broadcast message", zap.Error(err))
 		}
 
@@ -113,7 +484,7 @@ var streamCmd = &cobra.Command{
 		action := args[0]
 		streamId := args[1]
 
-		cfg, err := config.Load()
+		cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
 		if err != nil {
 			fmt.Printf("Failed to load configuration: %v\n", err)
 			os.Exit(1)
@@ -129,7 +500,7 @@ var streamCmd = &cobra.Command{
 		}
 		defer messageQueue.Close()
 
-		eventBus := core.NewEventBus(logger)
+		eventBus := core.NewEventBus(core.DefaultEventBusConfig(), logger)
 		defer eventBus.Close()
 
 		streamService := services.NewStreamService(messageQueue, eventBus, logger)
@@ -175,7 +546,7 @@ var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Get Alienator system status",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.Load()
+		cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
 		if err != nil {
 			fmt.Printf("Failed to load configuration: %v\n", err)
 			os.Exit(1)
@@ -201,17 +572,383 @@ var statusCmd = &cobra.Command{
 			messageQueue.Close()
 		}
 
+		fmt.Printf("🏷️  Version: %s\n", version.Get())
+		fmt.Printf("🧩 Build profile: %s\n", core.BuildProfile)
 		fmt.Printf("🌌 Alienator configuration loaded successfully\n")
 		fmt.Printf("🔗 API Server: %s:%d\n", cfg.Server.Host, cfg.Server.Port)
 		fmt.Printf("🔧 Environment: %s\n", cfg.Logging.Level)
 	},
 }
 
+var (
+	calibrateHuman    string
+	calibrateOut      string
+	calibrateMinWords int
+)
+
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "Derive linguistic baselines from a known-human corpus",
+	Long:  "Split the corpus into documents of --min-words words, measure each linguistic feature over them and write the 5th-95th percentile range of every feature as a calibration file. Pass the file to analyze --calibration to score against it instead of the built-in ranges.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := os.ReadFile(calibrateHuman)
+		if err != nil {
+			fmt.Printf("❌ Failed to read corpus: %v\n", err)
+			os.Exit(1)
+		}
+
+		opts := linguistic.DefaultCalibrationOptions()
+		opts.MinWords = calibrateMinWords
+		calibration, err := linguistic.NewLinguisticAnalyzer().Calibrate(linguistic.SplitCorpus(string(content), opts.MinWords), opts)
+		if err != nil {
+			fmt.Printf("❌ Calibration failed: %v\n", err)
+			os.Exit(1)
+		}
+		calibration.Source = calibrateHuman
+
+		if err := calibration.Save(calibrateOut); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Calibrated %d features on %d documents, written to %s\n", len(calibration.Features), calibration.Documents, calibrateOut)
+	},
+}
+
+var (
+	tuneLabeled     string
+	tuneObjective   string
+	tuneFPCost      float64
+	tuneFNCost      float64
+	tuneContentType string
+	tuneProfile     string
+	tuneJSON        bool
+)
+
+var tuneThresholdCmd = &cobra.Command{
+	Use:   "tune-threshold",
+	Short: "Find the decision threshold that best separates a labeled set",
+	Long: "Score every sample of the --labeled file, one JSON object per line such as {\"text\": \"...\", \"anomalous\": true}, " +
+		"and sweep the decision thresholds between the scores for the one optimizing --objective: f1, the harmonic mean of " +
+		"precision and recall; youden, Youden's J (true positive rate + true negative rate - 1); or cost, the total cost of the " +
+		"errors, with false positives and negatives weighed by --fp-cost and --fn-cost (default from detector.tuning). A sample's " +
+		"content_type, when given, wins over --content-type. The set must hold both anomalous and normal samples. The threshold " +
+		"found goes under detector.thresholds for the content types the samples represent.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		logger, _ := zap.NewDevelopment()
+		defer logger.Sync()
+
+		cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
+		if err != nil {
+			logger.Fatal("Failed to load configuration", zap.Error(err))
+		}
+		if cmd.Flags().Changed("fp-cost") {
+			cfg.Detector.Tuning.FalsePositiveCost = tuneFPCost
+		}
+		if cmd.Flags().Changed("fn-cost") {
+			cfg.Detector.Tuning.FalseNegativeCost = tuneFNCost
+		}
+		if err := applyPreset(&cfg.Detector, tuneProfile); err != nil {
+			logger.Fatal("Invalid preset", zap.Error(err))
+		}
+		detector := core.NewAnomalyDetector(logger, metrics.NewMetrics())
+		if err := detector.ApplyConfig(cfg.Detector); err != nil {
+			logger.Fatal("Invalid detector configuration", zap.Error(err))
+		}
+		analyzers := []core.Analyzer{entropy.NewEntropyAnalyzer(), linguistic.NewLinguisticAnalyzer(), compression.NewCompressionAnalyzer()}
+		if tuneProfile != "" {
+			analyzers = withAllAnalyzers(analyzers)
+		}
+		if err := catalog.Register(detector, cfg.Detector, analyzers...); err != nil {
+			logger.Fatal("Invalid analyzer configuration", zap.Error(err))
+		}
+		contentType, err := core.ParseContentType(tuneContentType)
+		if err != nil {
+			logger.Fatal("Invalid content type", zap.Error(err))
+		}
+
+		file, err := os.Open(tuneLabeled)
+		if err != nil {
+			fmt.Printf("❌ Failed to open labeled set: %v\n", err)
+			os.Exit(1)
+		}
+		samples, err := core.ReadLabeledSamples(file)
+		file.Close()
+		if err != nil {
+			fmt.Printf("❌ Failed to read labeled set %s: %v\n", tuneLabeled, err)
+			os.Exit(1)
+		}
+		for i := range samples {
+			if samples[i].ContentType == "" {
+				samples[i].ContentType = contentType
+			}
+		}
+
+		tuning, err := detector.TuneThreshold(samples, tuneObjective)
+		if err != nil {
+			fmt.Printf("❌ Tuning failed: %v\n", err)
+			os.Exit(1)
+		}
+		if tuneJSON {
+			encoded, _ := json.MarshalIndent(tuning, "", "  ")
+			fmt.Println(string(encoded))
+			return
+		}
+
+		best := tuning.Best
+		fmt.Printf("✅ Best threshold for %s over %d samples: %.4f\n", tuning.Objective, tuning.Samples, best.Threshold)
+		fmt.Printf("   Precision: %.3f  Recall: %.3f  F1: %.3f  Youden's J: %.3f  Cost: %g\n", best.Precision, best.Recall, best.F1, best.Youden, best.Cost)
+		fmt.Printf("   True positives: %d  False positives: %d  True negatives: %d  False negatives: %d\n", best.TP, best.FP, best.TN, best.FN)
+	},
+}
+
+var replScrollback int
+
+var replCmd = &cobra.Command{
+	Use:   "repl",
+	Short: "Score text interactively",
+	Long: "Start an interactive session: each line typed is scored immediately, and :paste collects several lines into one text. " +
+		"Commands toggle analyzers (:enable crypto), set the decision threshold (:threshold 0.7), explain the last result (:explain) " +
+		"and list recent results for comparison (:history); :help lists them all. The detector settings come from the configuration " +
+		"file ($" + config.ConfigFileEnv + ") and environment.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
+		if err != nil {
+			fmt.Printf("Failed to load configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+		if err := detector.ApplyConfig(cfg.Detector); err != nil {
+			fmt.Printf("Invalid detector configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if err := catalog.Register(detector, cfg.Detector,
+			entropy.NewEntropyAnalyzer(),
+			linguistic.NewLinguisticAnalyzer(),
+			compression.NewCompressionAnalyzer(),
+			cryptographic.NewCryptographicAnalyzer(),
+			injection.NewInjectionAnalyzer(),
+		); err != nil {
+			fmt.Printf("Invalid analyzer configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		session := repl.NewSession(detector, os.Stdout)
+		session.SetScrollback(replScrollback)
+		fmt.Println("👽 Alienator interactive session. Type text to score it, :help for commands, :quit to leave.")
+		if err := session.Run(os.Stdin, true); err != nil {
+			fmt.Printf("\nFailed to read input: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println()
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect, export and import Alienator configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate a configuration file together with the environment",
+	Long:  "Load the YAML or JSON configuration file (default: $" + config.ConfigFileEnv + "), overlay environment variables and report every problem found.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := os.Getenv(config.ConfigFileEnv)
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		if _, err := config.LoadFile(path); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if path == "" {
+			path = "defaults and environment"
+		}
+		fmt.Printf("✅ Configuration is valid (%s)\n", path)
+	},
+}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print the detector configuration in effect as JSON",
+	Long: "Print the detector section of the configuration in effect (file $" + config.ConfigFileEnv + " and environment) as JSON, " +
+		"with the parameters of every analyzer spelled out, for config import in another environment. " +
+		"The content profile weights are built in and not part of it.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadFile(os.Getenv(config.ConfigFileEnv))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+		if err := catalog.Register(detector, cfg.Detector, catalog.All()...); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Invalid analyzer configuration: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.Detector.Analyzers = detector.AnalyzerConfigs(cfg.Detector.Analyzers)
+
+		out, err := json.MarshalIndent(map[string]interface{}{"detector": config.Export(cfg.Detector)}, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to encode configuration: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	},
+}
+
+var (
+	configImportOut    string
+	configImportDryRun bool
+)
+
+var configImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Install a detector configuration written by config export",
+	Long: "Validate the detector section in file, including every analyzer parameter, and write it into the configuration file " +
+		"(--out, default $" + config.ConfigFileEnv + "), keeping the file's other sections. The settings that change are listed; " +
+		"with --dry-run nothing is written.",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := configImportOut
+		if target == "" {
+			target = os.Getenv(config.ConfigFileEnv)
+		}
+		if target == "" {
+			fmt.Printf("❌ No configuration file to import into: pass --out or set $%s\n", config.ConfigFileEnv)
+			os.Exit(1)
+		}
+
+		imported, err := config.PrepareImport(args[0], target)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+		if err := detector.ApplyConfig(imported.Config.Detector); err != nil {
+			fmt.Printf("❌ Invalid detector configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if err := catalog.Register(detector, imported.Config.Detector, catalog.All()...); err != nil {
+			fmt.Printf("❌ Invalid analyzer configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(imported.Changes) == 0 {
+			fmt.Printf("✅ %s already holds this configuration\n", target)
+			return
+		}
+		fmt.Printf("%d settings change:\n", len(imported.Changes))
+		for _, path := range imported.Changes {
+			fmt.Printf("  • %s\n", path)
+		}
+		if configImportDryRun {
+			fmt.Println("Dry run: nothing was written")
+			return
+		}
+		if err := imported.Write(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Imported into %s\n", target)
+	},
+}
+
+// withAllAnalyzers returns analyzers followed by a new instance of every
+// other text analyzer, for a preset to choose from
+func withAllAnalyzers(analyzers []core.Analyzer) []core.Analyzer {
+	have := make(map[string]bool, len(analyzers))
+	for _, analyzer := range analyzers {
+		have[analyzer.Name()] = true
+	}
+	for _, analyzer := range catalog.All() {
+		if !have[analyzer.Name()] {
+			analyzers = append(analyzers, analyzer)
+		}
+	}
+	return analyzers
+}
+
+// applyPreset resolves the named preset into the detector configuration;
+// an empty name leaves it unchanged
+func applyPreset(cfg *config.DetectorConfig, name string) error {
+	if name == "" {
+		return nil
+	}
+	resolved, err := cfg.WithPreset(name)
+	if err != nil {
+		return err
+	}
+	*cfg = resolved
+	return nil
+}
+
+// profileUsage describes the --profile flag
+var profileUsage = "analyze as the named preset describes: its analyzers, weights, parameters, threshold and content type (built in: " +
+	strings.Join(config.DetectorConfig{}.PresetNames(), ", ") + ", or one defined under detector.presets)"
+
 func init() {
+	rootCmd.Version = version.Get().String()
+
+	analyzeCmd.Flags().StringVar(&analyzeContentType, "content-type", "auto", "input content type: auto, prose, code, data or log")
+	analyzeCmd.Flags().StringVar(&analyzeLanguage, "language", "auto", "force the analysis language (ISO 639-1 code such as es), or auto to detect it")
+	analyzeCmd.Flags().Float64Var(&analyzeSample, "sample", 0, "analyze only this fraction (0-1] of long texts (head, random middle windows and tail); 0 analyzes the full text")
+	analyzeCmd.Flags().IntVar(&analyzeSampleWindow, "sample-window", core.DefaultSamplingConfig().WindowSize, "characters per sampled window")
+	analyzeCmd.Flags().IntVar(&analyzeWindows, "windows", 0, "score windows of this many characters separately and estimate whether the text is co-authored by a person and a model; 0 disables it")
+	analyzeCmd.Flags().Float64Var(&analyzeMixedSpread, "mixed-spread", core.DefaultWindowConfig().MixedSpread, "standard deviation of window scores at which a text counts as certainly co-authored")
+	analyzeCmd.Flags().IntVar(&analyzeTopSentences, "top-sentences", 0, "also score each sentence and list this many contributing most to the score; 0 disables it")
+	analyzeCmd.Flags().StringVar(&analyzeCalibration, "calibration", "", "score linguistic features against the human baselines in this file, written by calibrate")
+	analyzeCmd.Flags().IntVar(&analyzeBootstrap, "bootstrap", 0, "resample the text's sentences this many times and report the score's spread, with the confidence taken from how often resamples agree with the verdict; 0 disables it")
+	analyzeCmd.Flags().StringArrayVar(&analyzeSettings, "set", nil, "override an analyzer parameter for this run, as analyzer.param=value; repeatable")
+	analyzeCmd.Flags().BoolVar(&analyzeBoilerplate, "strip-boilerplate", false, "remove text matching the configured boilerplate patterns (signatures, disclaimers, ...) before analysis")
+	analyzeCmd.Flags().IntVar(&analyzePrecision, "precision", models.DefaultPrecision, "decimals to round scores and confidences to in the output and sinks (default from output.precision)")
+	analyzeCmd.Flags().StringVar(&analyzeProfile, "profile", "", profileUsage)
+
 	rootCmd.AddCommand(analyzeCmd)
+	batchCmd.Flags().StringVar(&batchContentType, "content-type", "auto", "input content type: auto, prose, code, data or log")
+	batchCmd.Flags().BoolVar(&batchBoilerplate, "strip-boilerplate", false, "remove paragraphs repeated across the files and text matching the configured boilerplate patterns before analysis")
+	batchCmd.Flags().IntVar(&batchPrecision, "precision", models.DefaultPrecision, "decimals to round scores and confidences to in the output and sinks (default from output.precision)")
+	batchCmd.Flags().IntVar(&batchConcurrency, "concurrency", runtime.NumCPU(), "how many files to analyze at once")
+	batchCmd.Flags().BoolVar(&batchJSON, "json", false, "print one JSON object per file and a final summary object instead of text")
+	batchCmd.Flags().StringVar(&batchProfile, "profile", "", profileUsage)
+	batchCmd.Flags().BoolVar(&batchTemplates, "templates", false, "also score each file by how much of it is scaffolding shared across the files, as filled in from one template")
+	rootCmd.AddCommand(batchCmd)
+	broadcastCmd.Flags().BoolVar(&broadcastAlert, "alert", false, "analyze the message and broadcast the rendered anomaly alert instead of the raw text")
 	rootCmd.AddCommand(broadcastCmd)
 	rootCmd.AddCommand(streamCmd)
 	rootCmd.AddCommand(statusCmd)
+
+	calibrateCmd.Flags().StringVar(&calibrateHuman, "human", "", "known-human corpus, with paragraphs separated by blank lines")
+	calibrateCmd.Flags().StringVar(&calibrateOut, "out", "calibration.json", "calibration file to write")
+	calibrateCmd.Flags().IntVar(&calibrateMinWords, "min-words", linguistic.DefaultCalibrationOptions().MinWords, "words per calibration document")
+	calibrateCmd.MarkFlagRequired("human")
+	rootCmd.AddCommand(calibrateCmd)
+
+	tuneThresholdCmd.Flags().StringVar(&tuneLabeled, "labeled", "", "labeled set, one JSON object with text and anomalous per line")
+	tuneThresholdCmd.Flags().StringVar(&tuneObjective, "objective", core.ObjectiveF1, "what the threshold optimizes: f1, youden or cost")
+	tuneThresholdCmd.Flags().Float64Var(&tuneFPCost, "fp-cost", 1, "cost of flagging a normal text, for --objective cost (default from detector.tuning)")
+	tuneThresholdCmd.Flags().Float64Var(&tuneFNCost, "fn-cost", 1, "cost of missing an anomalous text, for --objective cost (default from detector.tuning)")
+	tuneThresholdCmd.Flags().StringVar(&tuneContentType, "content-type", "auto", "content type of the samples that don't give one: auto, prose, code, data or log")
+	tuneThresholdCmd.Flags().StringVar(&tuneProfile, "profile", "", profileUsage)
+	tuneThresholdCmd.Flags().BoolVar(&tuneJSON, "json", false, "print the best point and every threshold swept as JSON")
+	tuneThresholdCmd.MarkFlagRequired("labeled")
+	rootCmd.AddCommand(tuneThresholdCmd)
+
+	replCmd.Flags().IntVar(&replScrollback, "scrollback", repl.DefaultScrollback, "how many recent results :history keeps")
+	rootCmd.AddCommand(replCmd)
+
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configExportCmd)
+	configImportCmd.Flags().StringVar(&configImportOut, "out", "", "configuration file to write (default $"+config.ConfigFileEnv+")")
+	configImportCmd.Flags().BoolVar(&configImportDryRun, "dry-run", false, "validate and list the changes without writing anything")
+	configCmd.AddCommand(configImportCmd)
+	rootCmd.AddCommand(configCmd)
 }
 
 func main() {
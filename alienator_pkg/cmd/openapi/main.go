@@ -0,0 +1,27 @@
+// Command openapi regenerates the OpenAPI document of the REST API from the
+// handler annotations. Run it through go generate in internal/api/rest.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ruvnet/alienator/internal/api/openapi"
+)
+
+func main() {
+	root := flag.String("root", ".", "module root the annotated sources are found under")
+	output := flag.String("o", "internal/api/rest/openapi.json", "file to write the document to")
+	flag.Parse()
+
+	spec, err := openapi.Generate(openapi.DefaultSources(*root))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "openapi: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, spec, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "openapi: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,27 +12,77 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/ruvnet/alienator/internal/alert"
+	"github.com/ruvnet/alienator/internal/analyzers/catalog"
 	"github.com/ruvnet/alienator/internal/config"
 	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/hashing"
+	"github.com/ruvnet/alienator/internal/jobs"
+	"github.com/ruvnet/alienator/internal/logging"
+	"github.com/ruvnet/alienator/internal/outbox"
 	"github.com/ruvnet/alienator/internal/queue"
+	"github.com/ruvnet/alienator/internal/repository"
 	"github.com/ruvnet/alienator/internal/services"
+	"github.com/ruvnet/alienator/internal/sink"
+	"github.com/ruvnet/alienator/internal/version"
+	"github.com/ruvnet/alienator/pkg/detector"
 	"github.com/ruvnet/alienator/pkg/metrics"
 	"go.uber.org/zap"
 )
 
 func main() {
+	showVersion := flag.Bool("version", false, "print version information and exit")
+	selfTest := flag.Bool("selftest", false, "score the built-in labelled corpus with the configured detector, print the metrics and exit")
+	selfTestThreshold := flag.Float64("selftest-threshold", detector.DefaultEvaluationThreshold, "score above which a self-test sample is counted as anomalous")
+	flag.Parse()
+	if *showVersion {
+		fmt.Println(version.Get())
+		return
+	}
+
 	// Load configuration
-	cfg, err := config.Load()
+	configPath := os.Getenv(config.ConfigFileEnv)
+	cfg, err := config.LoadFile(configPath)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to load configuration: %v", err))
 	}
 
-	logger, _ := zap.NewProduction()
+	logger, logLevel, err := logging.NewLogger(cfg.Logging)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize logger: %v", err))
+	}
 	defer logger.Sync()
 
+	// Cache keys and de-duplication fingerprints share one hash
+	if err := hashing.Configure(cfg.Hashing); err != nil {
+		logger.Fatal("Invalid hashing configuration", zap.Error(err))
+	}
+
 	// Initialize metrics
 	metrics := metrics.NewMetrics()
 
+	// Initialize anomaly detector, running the configured analyzers
+	detector, err := catalog.NewDetector(cfg.Detector, logger, metrics)
+	if err != nil {
+		logger.Fatal("Invalid detector configuration", zap.Error(err))
+	}
+
+	// Score the self-test corpus with the configured detector instead of
+	// consuming messages
+	if *selfTest {
+		report, err := detector.SelfTest(context.Background(), *selfTestThreshold)
+		if err != nil {
+			logger.Fatal("Detector self-test failed", zap.Error(err))
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			logger.Fatal("Failed to write self-test report", zap.Error(err))
+		}
+		return
+	}
+
 	// Initialize core messaging infrastructure
 	messageBroker, err := core.NewNATSBroker(cfg.NATS, logger)
 	if err != nil {
@@ -43,24 +96,95 @@ func main() {
 	}
 	defer messageQueue.Close()
 
-	eventBus := core.NewEventBus(logger)
+	eventBus := core.NewEventBus(core.DefaultEventBusConfig(), logger)
 	defer eventBus.Close()
 
 	// Initialize services
 	processingService := services.NewProcessingService(messageQueue, eventBus, logger)
 	broadcastService := services.NewBroadcastService(messageBroker, eventBus, logger)
+	broadcastService.SetMetrics(metrics)
+	broadcastService.SetRetryPolicy(services.RetryPolicy{
+		MaxRetries:     cfg.Broadcast.MaxRetries,
+		InitialBackoff: cfg.Broadcast.InitialBackoff,
+		MaxBackoff:     cfg.Broadcast.MaxBackoff,
+	})
+	alertTemplates, err := alert.FromConfig(cfg.Broadcast.Alerts)
+	if err != nil {
+		logger.Fatal("Invalid broadcast alert templates", zap.Error(err))
+	}
+	broadcastService.SetAlertTemplates(alertTemplates)
 	streamService := services.NewStreamService(messageQueue, eventBus, logger)
 
-	// Initialize anomaly detector
-	detector := core.NewAnomalyDetector(logger, metrics)
+	// Shut down on SIGINT or SIGTERM, including while still warming up
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Warm up the detector before consuming any messages, retrying until it
+	// succeeds; it only gives up when the worker is stopped
+	if err := detector.WarmupWithRetry(ctx, cfg.Detector.WarmupTimeout, cfg.Detector.WarmupBackoff); err != nil {
+		logger.Info("VibeCast worker stopped during detector warm-up", zap.Error(err))
+		return
+	}
 
 	// Initialize queue consumers
-	messageConsumer := queue.NewMessageConsumer(detector, processingService, logger)
-	broadcastConsumer := queue.NewBroadcastConsumer(broadcastService, logger)
-	streamConsumer := queue.NewStreamConsumer(streamService, logger)
+	messageConsumer := queue.NewMessageConsumer(detector, processingService, cfg.Worker.Message, metrics, logger)
+	messageConsumer.SetProvenance(cfg.Provenance)
+	messageConsumer.SetAlerts(broadcastService, cfg.Broadcast.Alerts.Targets)
+	broadcastConsumer := queue.NewBroadcastConsumer(broadcastService, cfg.Worker.Broadcast, metrics, logger)
+	streamConsumer := queue.NewStreamConsumer(streamService, cfg.Worker.Stream, metrics, logger)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Bridge the simple API's Redis requests into the detection pipeline
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	defer redisClient.Close()
+	bridgeConsumer := queue.NewBridgeConsumer(detector, queue.NewRedisBridgeTransport(redisClient), cfg.Worker.Bridge, metrics, logger)
+	bridgeConsumer.SetProvenance(cfg.Provenance)
+
+	// Keep the occurrence counts of de-duplicated anomalies in Redis
+	messageConsumer.SetOccurrenceStore(queue.NewRedisOccurrenceStore(redisClient, "alienator:occurrences:", cfg.Worker.Message.OccurrenceRetention))
+
+	var repo repository.Repository
+	if cfg.Worker.Jobs.Enabled || cfg.Worker.Outbox.Enabled {
+		repo = repository.NewRepository(cfg, logger)
+		defer repo.Close()
+	}
+
+	// Route results to the configured sinks as well
+	resultSinks, err := sink.New(cfg.Sinks)
+	if err != nil {
+		logger.Fatal("Failed to initialize result sinks", zap.Error(err))
+	}
+	defer resultSinks.Close()
+
+	// Persist results through the outbox, so a database outage only delays them
+	var resultOutbox *outbox.Outbox
+	var resultSink sink.ResultSink
+	if cfg.Worker.Outbox.Enabled {
+		resultOutbox, err = outbox.Open(cfg.Worker.Outbox, repo, metrics, logger)
+		if err != nil {
+			logger.Fatal("Failed to open result outbox", zap.Error(err))
+		}
+		defer resultOutbox.Close()
+		resultSink = sink.NewMulti(resultSinks, resultOutbox)
+	} else if resultSinks.Len() > 0 {
+		resultSink = resultSinks
+	}
+	if resultSink != nil {
+		messageConsumer.SetSink(resultSink)
+		bridgeConsumer.SetSink(resultSink)
+	}
+
+	// Run background jobs queued through the admin API
+	var jobRunner *jobs.Runner
+	if cfg.Worker.Jobs.Enabled {
+		jobRunner = jobs.NewRunner(jobs.NewRedisStore(redisClient, cfg.Worker.Jobs), cfg.Worker.Jobs, logger)
+		if err := registerRetrain(jobRunner, repo, cfg.Worker.Jobs.Retrain, logger); err != nil {
+			logger.Fatal("Failed to set up retrain jobs", zap.Error(err))
+		}
+	}
 
 	// Start workers
 	var wg sync.WaitGroup
@@ -95,6 +219,36 @@ func main() {
 		}
 	}()
 
+	// Start bridge worker
+	if cfg.Worker.Bridge.Enabled {
+		if err := bridgeConsumer.Start(ctx); err != nil {
+			logger.Fatal("Bridge worker failed", zap.Error(err))
+		}
+		defer bridgeConsumer.Stop()
+	}
+
+	// Start outbox flusher
+	if resultOutbox != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Info("Starting outbox flusher", zap.Int("depth", resultOutbox.Depth()))
+			if err := resultOutbox.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("Outbox flusher failed", zap.Error(err))
+			}
+		}()
+	}
+
+	// Start job runner
+	if jobRunner != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Info("Starting job runner", zap.Strings("kinds", jobRunner.Kinds()))
+			jobRunner.Start(ctx)
+		}()
+	}
+
 	// Health check endpoint
 	go func() {
 		ticker := time.NewTicker(30 * time.Second)
@@ -112,12 +266,16 @@ func main() {
 		}
 	}()
 
+	// Reload detector thresholds and log level on SIGHUP
+	reloader := config.NewReloader(configPath, cfg, logger)
+	reloader.OnReload(func(cfg *config.Config) error { return detector.ApplyConfig(cfg.Detector) })
+	reloader.OnReload(logging.ReloadLevel(logLevel))
+	go reloader.HandleSignals(ctx)
+
 	logger.Info("VibeCast worker started successfully")
 
 	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	<-ctx.Done()
 	logger.Info("Shutting down VibeCast worker...")
 
 	cancel()
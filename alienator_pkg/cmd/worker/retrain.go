@@ -0,0 +1,99 @@
+//go:build !lite
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/analyzers"
+	"github.com/ruvnet/alienator/internal/analyzers/ml"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/jobs"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultTrainingEpochs matches the neural detector's own default
+	defaultTrainingEpochs = 100
+	// trainingRounds splits training so retrain jobs can report progress
+	// between rounds
+	trainingRounds = 10
+)
+
+// registerRetrain runs retrain jobs on a neural detector of the worker's own
+func registerRetrain(runner *jobs.Runner, store jobs.TrainingStore, cfg config.RetrainConfig, logger *zap.Logger) error {
+	neuralDetector, err := ml.NewNeuralDetector(nil)
+	if err != nil {
+		return err
+	}
+	// Retrain jobs are bounded by worker.jobs.retrain.timeout instead
+	if err := neuralDetector.Configure(map[string]interface{}{"training_timeout": time.Duration(0)}); err != nil {
+		return err
+	}
+	runner.Register(jobs.KindRetrain, jobs.RetrainHandler(&neuralTrainer{detector: neuralDetector}, store, cfg))
+	return nil
+}
+
+// neuralTrainer retrains the worker's neural detector for retrain jobs
+type neuralTrainer struct {
+	detector *ml.NeuralDetector
+	mu       sync.Mutex // guards loss and epoch
+	loss     float64    // mean loss of the network kept by the last training
+	epoch    int        // epoch of the last training that produced it
+}
+
+// Convergence implements jobs.ConvergenceReporter
+func (t *neuralTrainer) Convergence() (float64, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.loss, t.epoch
+}
+
+// Train implements jobs.Trainer. Each series becomes a time series of
+// one-second steps; the detector only uses the order of the values. Once a
+// round stops early, because the loss plateaued or the deadline passed, the
+// rounds left are skipped.
+func (t *neuralTrainer) Train(ctx context.Context, series []jobs.Series, epochs int, progress func(fraction float64)) error {
+	start := time.Now()
+	data := make([]*analyzers.TimeSeries, 0, len(series))
+	for _, s := range series {
+		ts := &analyzers.TimeSeries{Name: s.Name, DataPoints: make([]analyzers.DataPoint, len(s.Values))}
+		for i, value := range s.Values {
+			ts.DataPoints[i] = analyzers.DataPoint{Timestamp: start.Add(time.Duration(i) * time.Second), Value: value}
+		}
+		data = append(data, ts)
+	}
+
+	if epochs <= 0 {
+		epochs = defaultTrainingEpochs
+	}
+	perRound := (epochs + trainingRounds - 1) / trainingRounds
+	for done := 0; done < epochs; done += perRound {
+		round := perRound
+		if remaining := epochs - done; remaining < round {
+			round = remaining
+		}
+		if err := t.detector.Configure(map[string]interface{}{"epochs": round}); err != nil {
+			return err
+		}
+		if err := t.detector.Train(ctx, data); err != nil {
+			if done > 0 && errors.Is(err, context.DeadlineExceeded) {
+				// Keep what the earlier rounds trained
+				break
+			}
+			return err
+		}
+		training := t.detector.LastTraining()
+		t.mu.Lock()
+		t.loss, t.epoch = training.Loss, done+training.BestEpoch
+		t.mu.Unlock()
+		if training.Truncated() {
+			break
+		}
+		progress(float64(done+round) / float64(epochs))
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build lite
+
+package main
+
+import (
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/jobs"
+	"go.uber.org/zap"
+)
+
+// registerRetrain leaves retrain jobs to full-build workers: the lite build
+// has no neural detector to train, so it claims none
+func registerRetrain(runner *jobs.Runner, store jobs.TrainingStore, cfg config.RetrainConfig, logger *zap.Logger) error {
+	logger.Warn("Retrain jobs need a full-build worker; this lite worker won't run them")
+	return nil
+}
@@ -0,0 +1,272 @@
+// Package alert renders anomaly alerts for people. Operators define Go
+// text/template messages that are filled in with the anomaly's score,
+// severity, strongest analyzer and an excerpt of the text, and wrapped in a
+// JSON or Slack Block Kit payload before being published.
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Output formats
+const (
+	// FormatJSON wraps the rendered message in a JSON object together with
+	// the alert's fields
+	FormatJSON = "json"
+	// FormatSlack produces a Slack Block Kit message, ready to be posted to
+	// an incoming webhook
+	FormatSlack = "slack"
+)
+
+// DefaultTemplate is used when no template is configured
+const DefaultTemplate = `{{upper .Severity}} anomaly (score {{printf "%.2f" .Score}}, confidence {{printf "%.2f" .Confidence}}){{if .TopAnalyzer}}, strongest signal from {{.TopAnalyzer}}{{end}}`
+
+// DefaultExcerptLength is the number of characters of the text kept in an
+// alert when no length is configured
+const DefaultExcerptLength = 200
+
+// Alert holds the fields of an anomaly available to templates
+type Alert struct {
+	ID          string             `json:"id"`
+	Score       float64            `json:"score"`
+	Confidence  float64            `json:"confidence"`
+	Severity    string             `json:"severity"`
+	IsAnomalous bool               `json:"is_anomalous"`
+	TopAnalyzer string             `json:"top_analyzer,omitempty"`
+	TopScore    float64            `json:"top_score,omitempty"`
+	Analyzers   map[string]float64 `json:"analyzers,omitempty"`
+	Excerpt     string             `json:"excerpt,omitempty"`
+	Timestamp   time.Time          `json:"timestamp"`
+}
+
+// New builds the alert for a detection result. The excerpt is the start of
+// text, cut at a word boundary to at most excerptLength characters; zero or
+// less omits it.
+func New(id string, result *models.AnomalyResult, text string, excerptLength int) *Alert {
+	alert := &Alert{
+		ID:          id,
+		Score:       result.Score,
+		Confidence:  result.Confidence,
+		Severity:    string(result.Severity),
+		IsAnomalous: result.IsAnomalous,
+		Excerpt:     Excerpt(text, excerptLength),
+		Timestamp:   result.Timestamp,
+	}
+	if alert.Severity == "" {
+		alert.Severity = string(models.SeverityNone)
+	}
+	if alert.Timestamp.IsZero() {
+		alert.Timestamp = time.Now().UTC()
+	}
+
+	if len(result.Details) > 0 {
+		alert.Analyzers = make(map[string]float64, len(result.Details))
+		names := make([]string, 0, len(result.Details))
+		for name, detail := range result.Details {
+			alert.Analyzers[name] = detail.Score
+			names = append(names, name)
+		}
+		// Sorted so that ties always name the same analyzer
+		sort.Strings(names)
+		for _, name := range names {
+			if alert.TopAnalyzer == "" || alert.Analyzers[name] > alert.TopScore {
+				alert.TopAnalyzer = name
+				alert.TopScore = alert.Analyzers[name]
+			}
+		}
+	}
+
+	return alert
+}
+
+// Excerpt returns the start of text with whitespace collapsed, cut at a word
+// boundary to at most length characters and marked with an ellipsis when
+// shortened
+func Excerpt(text string, length int) string {
+	if length <= 0 {
+		return ""
+	}
+
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= length {
+		return string(runes)
+	}
+
+	cut := length
+	for i := length; i > length/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + "…"
+}
+
+// templateFuncs are available to alert templates in addition to the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"percent": func(value float64) string {
+		return fmt.Sprintf("%.0f%%", value*100)
+	},
+}
+
+// Template renders alerts in one output format
+type Template struct {
+	format string
+	text   *template.Template
+}
+
+// NewTemplate parses a text/template for the given format. An empty text
+// uses DefaultTemplate and an empty format FormatJSON.
+func NewTemplate(format, text string) (*Template, error) {
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatJSON && format != FormatSlack {
+		return nil, fmt.Errorf("unknown alert format %q (expected %s or %s)", format, FormatJSON, FormatSlack)
+	}
+	if text == "" {
+		text = DefaultTemplate
+	}
+
+	parsed, err := template.New("alert").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert template: %w", err)
+	}
+	return &Template{format: format, text: parsed}, nil
+}
+
+// Format returns the output format, FormatJSON or FormatSlack
+func (t *Template) Format() string {
+	return t.format
+}
+
+// ContentType returns the media type of rendered payloads
+func (t *Template) ContentType() string {
+	return "application/json"
+}
+
+// Message executes the template alone, without the payload around it
+func (t *Template) Message(alert *Alert) (string, error) {
+	var buf bytes.Buffer
+	if err := t.text.Execute(&buf, alert); err != nil {
+		return "", fmt.Errorf("failed to render alert: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Render executes the template and wraps the message in the payload of the
+// template's format
+func (t *Template) Render(alert *Alert) ([]byte, error) {
+	message, err := t.Message(alert)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.format == FormatSlack {
+		return json.Marshal(slackPayload(message, alert))
+	}
+	return json.Marshal(struct {
+		Message string `json:"message"`
+		*Alert
+	}{message, alert})
+}
+
+// slackPayload lays an alert out as Slack blocks. The top-level text is the
+// fallback shown in notifications.
+func slackPayload(message string, alert *Alert) map[string]interface{} {
+	field := func(text string) map[string]interface{} {
+		return map[string]interface{}{"type": "mrkdwn", "text": text}
+	}
+
+	facts := []interface{}{
+		field("*Severity:* " + alert.Severity),
+		field(fmt.Sprintf("*Score:* %.2f", alert.Score)),
+		field(fmt.Sprintf("*Confidence:* %.2f", alert.Confidence)),
+	}
+	if alert.TopAnalyzer != "" {
+		facts = append(facts, field(fmt.Sprintf("*Top analyzer:* %s (%.2f)", alert.TopAnalyzer, alert.TopScore)))
+	}
+
+	blocks := []interface{}{
+		map[string]interface{}{"type": "section", "text": field(message)},
+		map[string]interface{}{"type": "section", "fields": facts},
+	}
+	if alert.Excerpt != "" {
+		blocks = append(blocks, map[string]interface{}{"type": "section", "text": field("> " + alert.Excerpt)})
+	}
+	blocks = append(blocks, map[string]interface{}{
+		"type":     "context",
+		"elements": []interface{}{field(fmt.Sprintf("Alert %s at %s", alert.ID, alert.Timestamp.UTC().Format(time.RFC3339)))},
+	})
+
+	return map[string]interface{}{"text": message, "blocks": blocks}
+}
+
+// Templates selects the template for each broadcast channel
+type Templates struct {
+	fallback      *Template
+	channels      map[string]*Template
+	excerptLength int
+}
+
+// FromConfig parses the default and per-channel templates in cfg
+func FromConfig(cfg config.AlertsConfig) (*Templates, error) {
+	fallback, err := NewTemplate(cfg.Default.Format, cfg.Default.Template)
+	if err != nil {
+		return nil, fmt.Errorf("default: %w", err)
+	}
+
+	templates := &Templates{
+		fallback:      fallback,
+		channels:      make(map[string]*Template, len(cfg.Channels)),
+		excerptLength: cfg.ExcerptLength,
+	}
+	for channel, channelCfg := range cfg.Channels {
+		// A channel may override only the format or only the wording
+		format, text := channelCfg.Format, channelCfg.Template
+		if format == "" {
+			format = cfg.Default.Format
+		}
+		if text == "" {
+			text = cfg.Default.Template
+		}
+		tmpl, err := NewTemplate(format, text)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", channel, err)
+		}
+		templates.channels[channel] = tmpl
+	}
+	return templates, nil
+}
+
+// DefaultTemplates renders every channel's alerts as JSON with DefaultTemplate
+func DefaultTemplates() *Templates {
+	fallback, _ := NewTemplate(FormatJSON, DefaultTemplate)
+	return &Templates{fallback: fallback, channels: map[string]*Template{}, excerptLength: DefaultExcerptLength}
+}
+
+// For returns the template of channel, or the default one
+func (t *Templates) For(channel string) *Template {
+	if tmpl, ok := t.channels[channel]; ok {
+		return tmpl
+	}
+	return t.fallback
+}
+
+// ExcerptLength returns the configured excerpt length
+func (t *Templates) ExcerptLength() int {
+	return t.excerptLength
+}
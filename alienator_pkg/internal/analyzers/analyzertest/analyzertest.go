@@ -0,0 +1,99 @@
+//go:build analyzertest
+
+package analyzertest
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Analyzer is the part of an analyzer exercised by the harness
+type Analyzer interface {
+	Analyze(ctx context.Context, text string) (*models.AnalysisResult, error)
+}
+
+// Range is an inclusive range of expected scores
+type Range struct {
+	Min float64
+	Max float64
+}
+
+// Contains reports whether score lies within the range
+func (r Range) Contains(score float64) bool {
+	return score >= r.Min && score <= r.Max
+}
+
+// Any accepts every valid score
+var Any = Range{Min: 0, Max: 1}
+
+// AssertScore analyzes text and fails the test unless the result is valid
+// (see AssertValid) and its score lies within expected
+func AssertScore(t testing.TB, analyzer Analyzer, text string, expected Range) *models.AnalysisResult {
+	t.Helper()
+
+	result := AssertValid(t, analyzer, text)
+	if result != nil && !expected.Contains(result.Score) {
+		t.Errorf("score %g outside expected range [%g, %g] for %q", result.Score, expected.Min, expected.Max, excerpt(text))
+	}
+	return result
+}
+
+// AssertValid analyzes text and fails the test if the analysis errors or the
+// result breaks an invariant every analyzer must keep: score and confidence
+// within [0, 1], no NaN or infinite metadata values, and the same result
+// when the text is analyzed again
+func AssertValid(t testing.TB, analyzer Analyzer, text string) *models.AnalysisResult {
+	t.Helper()
+
+	result, err := analyzer.Analyze(context.Background(), text)
+	if err != nil {
+		t.Errorf("analysis failed for %q: %v", excerpt(text), err)
+		return nil
+	}
+	for _, problem := range Invariants(result) {
+		t.Errorf("%s for %q", problem, excerpt(text))
+	}
+
+	again, err := analyzer.Analyze(context.Background(), text)
+	if err != nil {
+		t.Errorf("second analysis failed for %q: %v", excerpt(text), err)
+		return result
+	}
+	for _, diff := range diffFeatures(Features(result), Features(again)) {
+		t.Errorf("analysis is not repeatable for %q: %s", excerpt(text), diff)
+	}
+	return result
+}
+
+// Invariants returns the invariants result breaks, if any
+func Invariants(result *models.AnalysisResult) []string {
+	if result == nil {
+		return []string{"nil result"}
+	}
+
+	var problems []string
+	if !Any.Contains(result.Score) {
+		problems = append(problems, "score "+format(result.Score)+" outside [0, 1]")
+	}
+	if !Any.Contains(result.Confidence) {
+		problems = append(problems, "confidence "+format(result.Confidence)+" outside [0, 1]")
+	}
+	for key, value := range Features(result) {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			problems = append(problems, "metadata "+key+" is "+format(value))
+		}
+	}
+	return problems
+}
+
+// excerpt shortens text for failure messages
+func excerpt(text string) string {
+	const limit = 60
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:limit]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,9 @@
+// Package analyzertest is a test harness for analyzers: score assertions,
+// invariant checks, labeled human and AI fixtures, golden files recording
+// each analyzer's output on the fixtures, and a fuzz entry point.
+//
+// Everything but this comment is only compiled with the analyzertest build
+// tag, so that the harness never ends up in production binaries:
+//
+//	go test -tags analyzertest ./tests/...
+package analyzertest
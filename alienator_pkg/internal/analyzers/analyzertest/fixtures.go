@@ -0,0 +1,69 @@
+//go:build analyzertest
+
+package analyzertest
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Label says who wrote a sample
+type Label string
+
+// Sample labels
+const (
+	LabelHuman Label = "human"
+	LabelAI    Label = "ai"
+)
+
+// Sample is a labeled fixture text
+type Sample struct {
+	Name  string // label/file name without extension, e.g. human/porch
+	Label Label
+	Text  string
+}
+
+//go:embed samples
+var samples embed.FS
+
+// Samples returns every fixture, ordered by name
+func Samples() []Sample {
+	var all []Sample
+	err := fs.WalkDir(samples, "samples", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || path.Ext(file) != ".txt" {
+			return err
+		}
+		content, err := samples.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(file, "samples/"), ".txt")
+		all = append(all, Sample{
+			Name:  name,
+			Label: Label(path.Dir(name)),
+			Text:  string(content),
+		})
+		return nil
+	})
+	if err != nil {
+		// The fixtures are embedded, so this is a broken build
+		panic("analyzertest: failed to read samples: " + err.Error())
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// SamplesLabeled returns the fixtures with the given label
+func SamplesLabeled(label Label) []Sample {
+	var labeled []Sample
+	for _, sample := range Samples() {
+		if sample.Label == label {
+			labeled = append(labeled, sample)
+		}
+	}
+	return labeled
+}
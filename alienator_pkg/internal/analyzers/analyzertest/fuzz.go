@@ -0,0 +1,46 @@
+//go:build analyzertest
+
+package analyzertest
+
+import (
+	"strings"
+	"testing"
+)
+
+// edgeCases seed the fuzz corpus with inputs analyzers tend to mishandle
+var edgeCases = []string{
+	"",
+	" ",
+	"\n\n\n",
+	".",
+	"...!?",
+	"a",
+	"word",
+	"A sentence without an ending",
+	strings.Repeat("a", 500),
+	strings.Repeat("the ", 200),
+	"日本語のテキストです。句読点も含みます。",
+	"emoji 👽🛸 and ​ zero width",
+	"\x00\xff invalid utf-8 \xc3\x28",
+	"0123456789 3.14159 1e10 -42",
+}
+
+// Fuzz checks that analyzer keeps its invariants (see AssertValid) on
+// arbitrary text, seeded with the fixtures and known edge cases. Call it
+// from a fuzz test:
+//
+//	func FuzzEntropyAnalyzer(f *testing.F) {
+//		analyzertest.Fuzz(f, entropy.NewEntropyAnalyzer())
+//	}
+func Fuzz(f *testing.F, analyzer Analyzer) {
+	for _, sample := range Samples() {
+		f.Add(sample.Text)
+	}
+	for _, text := range edgeCases {
+		f.Add(text)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		AssertValid(t, analyzer, text)
+	})
+}
@@ -0,0 +1,172 @@
+//go:build analyzertest
+
+package analyzertest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// update rewrites golden files instead of comparing against them:
+//
+//	go test -tags analyzertest ./tests -run Golden -update
+var update = flag.Bool("update", false, "rewrite analyzertest golden files")
+
+// Tolerance is the largest difference between two values of a feature still
+// treated as equal, relative to the feature's magnitude when above 1. It
+// absorbs floating point noise such as map iteration order changing the
+// order of a sum.
+const Tolerance = 1e-9
+
+// Golden is the recorded output of one analyzer on every fixture
+type Golden map[string]map[string]float64
+
+// Features returns the numeric values of a result: its score, confidence and
+// every int or float64 metadata value, which together cover the feature math
+// of an analyzer
+func Features(result *models.AnalysisResult) map[string]float64 {
+	features := map[string]float64{
+		"score":      result.Score,
+		"confidence": result.Confidence,
+	}
+	for key, value := range result.Metadata {
+		switch v := value.(type) {
+		case float64:
+			features["metadata."+key] = v
+		case int:
+			features["metadata."+key] = float64(v)
+		}
+	}
+	return features
+}
+
+// AssertGolden runs analyzer over every fixture and compares the features of
+// each result with those recorded in the golden file at path, usually
+// testdata/golden/<analyzer>.json in the calling package. With -update, or
+// when the file does not exist yet, the file is written instead.
+func AssertGolden(t testing.TB, analyzer Analyzer, path string) {
+	t.Helper()
+
+	got := make(Golden)
+	for _, sample := range Samples() {
+		if result := AssertValid(t, analyzer, sample.Text); result != nil {
+			got[sample.Name] = Features(result)
+		}
+	}
+	if t.Failed() {
+		return
+	}
+
+	if _, err := os.Stat(path); *update || os.IsNotExist(err) {
+		if err := writeGolden(path, got); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		t.Logf("wrote golden file %s", path)
+		return
+	}
+
+	want, err := readGolden(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	for _, diff := range diffGolden(want, got) {
+		t.Errorf("%s: %s (rerun with -update if the change is intended)", path, diff)
+	}
+}
+
+// diffGolden lists the differences between the recorded and current features
+func diffGolden(want, got Golden) []string {
+	var diffs []string
+	for _, sample := range sortedKeys(want, got) {
+		switch {
+		case want[sample] == nil:
+			diffs = append(diffs, fmt.Sprintf("%s: new sample", sample))
+		case got[sample] == nil:
+			diffs = append(diffs, fmt.Sprintf("%s: sample no longer analyzed", sample))
+		default:
+			for _, diff := range diffFeatures(want[sample], got[sample]) {
+				diffs = append(diffs, sample+": "+diff)
+			}
+		}
+	}
+	return diffs
+}
+
+// diffFeatures lists the features that differ by more than Tolerance
+func diffFeatures(want, got map[string]float64) []string {
+	var diffs []string
+	for _, feature := range sortedKeys(want, got) {
+		w, wok := want[feature]
+		g, gok := got[feature]
+		switch {
+		case !gok:
+			diffs = append(diffs, fmt.Sprintf("%s missing, was %s", feature, format(w)))
+		case !wok:
+			diffs = append(diffs, fmt.Sprintf("%s is new (%s)", feature, format(g)))
+		case !equalWithin(w, g):
+			diffs = append(diffs, fmt.Sprintf("%s changed from %s to %s", feature, format(w), format(g)))
+		}
+	}
+	return diffs
+}
+
+// equalWithin compares features to Tolerance, relative to their magnitude
+// when above 1
+func equalWithin(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) <= Tolerance*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func readGolden(path string) (Golden, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var golden Golden
+	if err := json.Unmarshal(content, &golden); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return golden, nil
+}
+
+func writeGolden(path string, golden Golden) error {
+	content, err := json.MarshalIndent(golden, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// sortedKeys returns the union of the maps' keys in order
+func sortedKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+	for _, m := range []map[string]V{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func format(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,93 @@
+// Package catalog lists the text analyzers of this build and builds
+// detectors running those a detector configuration enables. It lives apart
+// from package core, which every analyzer depends on.
+package catalog
+
+import (
+	"fmt"
+
+	"github.com/ruvnet/alienator/internal/analyzers/claims"
+	"github.com/ruvnet/alienator/internal/analyzers/compression"
+	"github.com/ruvnet/alienator/internal/analyzers/cryptographic"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/analyzers/injection"
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/analyzers/repetition"
+	"github.com/ruvnet/alienator/internal/analyzers/sentiment"
+	"github.com/ruvnet/alienator/internal/analyzers/uniformity"
+	"github.com/ruvnet/alienator/internal/analyzers/watermark"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+	"github.com/ruvnet/alienator/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// All returns a new instance of every text analyzer in this build: the ones
+// every build has, then those only the full build adds
+func All() []core.Analyzer {
+	analyzers := []core.Analyzer{
+		entropy.NewEntropyAnalyzer(),
+		linguistic.NewLinguisticAnalyzer(),
+		compression.NewCompressionAnalyzer(),
+		cryptographic.NewCryptographicAnalyzer(),
+		uniformity.NewUniformityAnalyzer(),
+		repetition.NewRepetitionAnalyzer(),
+		sentiment.NewSentimentAnalyzer(),
+		claims.NewClaimsAnalyzer(),
+		watermark.NewWatermarkAnalyzer(),
+		injection.NewInjectionAnalyzer(),
+	}
+	return append(analyzers, fullAnalyzers()...)
+}
+
+// Register registers the analyzers the configuration enables, then applies
+// their configured parameters
+func Register(detector *core.AnomalyDetector, cfg config.DetectorConfig, analyzers ...core.Analyzer) error {
+	for _, analyzer := range analyzers {
+		if cfg.AnalyzerEnabled(analyzer.Name()) {
+			detector.RegisterAnalyzer(analyzer)
+		}
+	}
+	return detector.ConfigureAnalyzers(cfg.Analyzers)
+}
+
+// NewDetector builds a detector from the detector configuration, running
+// the analyzers of this build it enables with their configured parameters
+func NewDetector(cfg config.DetectorConfig, logger *zap.Logger, metrics *metrics.Metrics) (*core.AnomalyDetector, error) {
+	detector := core.NewAnomalyDetector(logger, metrics)
+	if err := detector.ApplyConfig(cfg); err != nil {
+		return nil, err
+	}
+	textTokenizer, err := tokenizer.FromConfig(cfg.Segmentation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentence segmentation configuration: %w", err)
+	}
+	detector.SetTokenizer(textTokenizer)
+	if err := detector.SetFeatureCache(core.FeatureCacheConfig{
+		Analyzers: cfg.Cache.Analyzers,
+		Size:      cfg.Cache.Size,
+	}); err != nil {
+		return nil, fmt.Errorf("invalid analyzer cache configuration: %w", err)
+	}
+	if err := Register(detector, cfg, All()...); err != nil {
+		return nil, fmt.Errorf("invalid analyzer configuration: %w", err)
+	}
+	return detector, nil
+}
+
+// NewPresetDetectors builds a detector for every preset, built in or
+// configured, with the preset resolved into the detector configuration
+func NewPresetDetectors(cfg config.DetectorConfig, logger *zap.Logger, metrics *metrics.Metrics) (map[string]*core.AnomalyDetector, error) {
+	detectors := make(map[string]*core.AnomalyDetector)
+	for _, name := range cfg.PresetNames() {
+		resolved, err := cfg.WithPreset(name)
+		if err != nil {
+			return nil, err
+		}
+		if detectors[name], err = NewDetector(resolved, logger, metrics); err != nil {
+			return nil, fmt.Errorf("preset %s: %w", name, err)
+		}
+	}
+	return detectors, nil
+}
@@ -0,0 +1,13 @@
+//go:build !lite
+
+package catalog
+
+import (
+	"github.com/ruvnet/alienator/internal/analyzers/embedding"
+	"github.com/ruvnet/alienator/internal/core"
+)
+
+// fullAnalyzers returns the analyzers the lite build leaves out
+func fullAnalyzers() []core.Analyzer {
+	return []core.Analyzer{embedding.NewEmbeddingAnalyzer()}
+}
@@ -0,0 +1,11 @@
+//go:build lite
+
+package catalog
+
+import "github.com/ruvnet/alienator/internal/core"
+
+// fullAnalyzers returns no analyzers: the lite build leaves out the
+// embedding analyzer
+func fullAnalyzers() []core.Analyzer {
+	return nil
+}
@@ -0,0 +1,265 @@
+package claims
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// ClaimsAnalyzer measures how densely text packs confident declarative
+// claims. A sentence is a claim when it states rather than asks or exclaims,
+// is long enough and isn't the writer speaking of themselves ("my week was
+// long"). A claim is confident when it carries no hedge ("may", "likely",
+// "it seems"), and counts fully when it also cites a specific number.
+// Assistant output tends to chain such claims sentence after sentence, where
+// people hedge, ask and digress. It is a stylistic heuristic, orthogonal to
+// the statistical features, so the analyzer carries little weight in the
+// ensemble until validated.
+type ClaimsAnalyzer struct {
+	name               string
+	densityBaseline    float64 // claim density usual in human writing
+	numberWeight       float64 // share of a confident claim's weight earned by citing a number
+	minSentences       int     // below this many sentences nothing is scored
+	minClaimWords      int     // shorter sentences are not claims
+	detectionThreshold float64 // score at which claim-dense text is reported
+	fullConfidenceAt   int     // sentences needed for full confidence
+	mu                 sync.RWMutex
+}
+
+// NewClaimsAnalyzer creates a claim density analyzer
+func NewClaimsAnalyzer() *ClaimsAnalyzer {
+	return &ClaimsAnalyzer{
+		name:               "claims",
+		densityBaseline:    0.4,
+		numberWeight:       0.3,
+		minSentences:       3,
+		minClaimWords:      5,
+		detectionThreshold: 0.6,
+		fullConfidenceAt:   20,
+	}
+}
+
+// Name returns the analyzer name
+func (ca *ClaimsAnalyzer) Name() string {
+	return ca.name
+}
+
+// Description returns what the analyzer measures
+func (ca *ClaimsAnalyzer) Description() string {
+	return "Measures the density of confident declarative claims: unhedged statements, especially those citing specific numbers"
+}
+
+// Parameters returns the options accepted by Configure
+func (ca *ClaimsAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "density_baseline", Type: models.ParameterNumber, Description: "Claim density usual in human writing, in [0, 1); only density beyond it scores", Default: 0.4},
+		{Name: "number_weight", Type: models.ParameterNumber, Description: "Share of a confident claim's weight earned by citing a specific number, in [0, 1]", Default: 0.3},
+		{Name: "min_sentences", Type: models.ParameterInteger, Description: "Fewest sentences needed to score the text", Default: 3},
+		{Name: "min_claim_words", Type: models.ParameterInteger, Description: "Fewest words in a sentence for it to count as a claim", Default: 5},
+		{Name: "detection_threshold", Type: models.ParameterNumber, Description: "Score at or above which the text is reported as claim-dense, in (0, 1]", Default: 0.6},
+	}
+}
+
+// Configure updates the analyzer configuration
+func (ca *ClaimsAnalyzer) Configure(config map[string]interface{}) error {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	if baseline, ok := config["density_baseline"].(float64); ok {
+		if baseline < 0 || baseline >= 1 {
+			return fmt.Errorf("density_baseline must be in [0, 1), got %f", baseline)
+		}
+		ca.densityBaseline = baseline
+	}
+	if weight, ok := config["number_weight"].(float64); ok {
+		if weight < 0 || weight > 1 {
+			return fmt.Errorf("number_weight must be in [0, 1], got %f", weight)
+		}
+		ca.numberWeight = weight
+	}
+	if sentences, ok := config["min_sentences"].(int); ok {
+		if sentences < 1 {
+			return fmt.Errorf("min_sentences must be positive, got %d", sentences)
+		}
+		ca.minSentences = sentences
+	}
+	if words, ok := config["min_claim_words"].(int); ok {
+		if words < 1 {
+			return fmt.Errorf("min_claim_words must be positive, got %d", words)
+		}
+		ca.minClaimWords = words
+	}
+	if threshold, ok := config["detection_threshold"].(float64); ok {
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("detection_threshold must be in (0, 1], got %f", threshold)
+		}
+		ca.detectionThreshold = threshold
+	}
+
+	return nil
+}
+
+// CanAnalyze reports whether the text has sentences enough to be scored
+func (ca *ClaimsAnalyzer) CanAnalyze(tokens *tokenizer.Tokens) error {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+
+	if sentences := len(tokens.SentenceWords()); sentences < ca.minSentences {
+		return fmt.Errorf("needs at least %d sentences, got %d", ca.minSentences, sentences)
+	}
+	return nil
+}
+
+// Analyze measures the claim density of the text
+func (ca *ClaimsAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return ca.AnalyzeTokens(ctx, tokenizer.New(tokenizer.Default, text))
+}
+
+// AnalyzeTokens measures the claim density of already tokenized text
+func (ca *ClaimsAnalyzer) AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+
+	sentences := tokens.Sentences()
+	sentenceWords := tokens.CleanSentenceWords()
+	metadata := map[string]interface{}{
+		"sentence_count": len(sentences),
+		"claim_dense":    false,
+	}
+	if len(sentences) < ca.minSentences {
+		return &models.AnalysisResult{
+			Score:      0.0,
+			Confidence: 0.0,
+			Metadata:   metadata,
+		}, nil
+	}
+
+	var claims, hedged, confident, numeric int
+	weight := 0.0
+	for i, sentence := range sentences {
+		words := sentenceWords[i]
+		if !ca.isClaim(sentence, words) {
+			continue
+		}
+		claims++
+		if isHedged(words) {
+			hedged++
+			continue
+		}
+		confident++
+		weight += 1 - ca.numberWeight
+		if citesNumber(words) {
+			numeric++
+			weight += ca.numberWeight
+		}
+	}
+
+	n := float64(len(sentences))
+	density := weight / n
+	score := math.Max(0, (density-ca.densityBaseline)/(1-ca.densityBaseline))
+
+	metadata["claim_sentences"] = claims
+	metadata["hedged_claims"] = hedged
+	metadata["confident_claims"] = confident
+	metadata["numeric_claims"] = numeric
+	metadata["claim_fraction"] = float64(claims) / n
+	metadata["confident_fraction"] = float64(confident) / n
+	metadata["claim_density"] = density
+	metadata["claim_dense"] = score >= ca.detectionThreshold
+	if claims > 0 {
+		metadata["hedge_rate"] = float64(hedged) / float64(claims)
+	}
+
+	return &models.AnalysisResult{
+		Score:      math.Min(1, score),
+		Confidence: math.Min(1.0, n/float64(ca.fullConfidenceAt)),
+		Metadata:   metadata,
+	}, nil
+}
+
+// isClaim reports whether a sentence states something about the world:
+// long enough, neither a question nor an exclamation, and not the writer
+// speaking of themselves
+func (ca *ClaimsAnalyzer) isClaim(sentence string, words []string) bool {
+	if len(words) < ca.minClaimWords {
+		return false
+	}
+	trimmed := strings.TrimRightFunc(sentence, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`"')”’`, r)
+	})
+	if strings.HasSuffix(trimmed, "?") || strings.HasSuffix(trimmed, "!") {
+		return false
+	}
+	for _, word := range words {
+		if personalWords[word] {
+			return false
+		}
+	}
+	return true
+}
+
+// isHedged reports whether the words soften their claim
+func isHedged(words []string) bool {
+	for _, word := range words {
+		if hedgeWords[word] {
+			return true
+		}
+	}
+	joined := " " + strings.Join(words, " ") + " "
+	for _, phrase := range hedgePhrases {
+		if strings.Contains(joined, " "+phrase+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// citesNumber reports whether the words contain a specific figure: digits,
+// or a magnitude such as "percent" or "million"
+func citesNumber(words []string) bool {
+	for _, word := range words {
+		if magnitudes[word] || strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// personalWords mark a sentence as the writer speaking of themselves
+var personalWords = toSet("i", "i'm", "i've", "i'd", "i'll", "me", "my", "mine", "myself")
+
+// hedgeWords soften a claim wherever they appear in it
+var hedgeWords = toSet(
+	"may", "might", "could", "perhaps", "possibly", "probably", "likely", "unlikely", "maybe",
+	"apparently", "seemingly", "arguably", "presumably", "supposedly", "roughly", "approximately",
+	"somewhat", "suggest", "suggests", "suggested", "appear", "appears", "appeared", "seem", "seems",
+	"seemed", "believe", "believes", "think", "thinks", "guess", "suppose", "sometimes", "often",
+	"usually", "generally", "typically", "tend", "tends", "unclear", "uncertain", "estimate",
+	"estimated", "allegedly", "reportedly", "potentially", "conceivably", "partly", "partially",
+)
+
+// hedgePhrases soften a claim when they appear in it as a whole
+var hedgePhrases = []string{
+	"in my opinion", "it is possible", "it's possible", "not sure", "kind of", "sort of",
+	"as far as i know", "to some extent", "to some degree", "more or less", "i feel",
+}
+
+// magnitudes are the words citing a figure without digits
+var magnitudes = toSet(
+	"percent", "percentage", "hundred", "thousand", "million", "billion", "trillion",
+	"dozen", "twice", "thrice", "double", "triple", "tenfold", "hundredfold",
+)
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
@@ -5,20 +5,24 @@ import (
 	"fmt"
 	"sync"
 	"time"
-
-	"github.com/ruvnet/alienator/internal/analyzers/alerts"
 )
 
+// AlertManager raises alerts for the high-severity anomalies a composite
+// analyzer finds
+type AlertManager interface {
+	ProcessAnomaly(ctx context.Context, anomaly Anomaly) error
+}
+
 // CompositeAnalyzer combines multiple analyzers to provide comprehensive anomaly detection
 type CompositeAnalyzer struct {
 	analyzers    []Analyzer
-	alertManager *alerts.Manager
+	alertManager AlertManager
 	weights      map[AnomalyType]float64
 	mu           sync.RWMutex
 }
 
 // NewCompositeAnalyzer creates a new composite analyzer
-func NewCompositeAnalyzer(analyzers []Analyzer, alertManager *alerts.Manager) *CompositeAnalyzer {
+func NewCompositeAnalyzer(analyzers []Analyzer, alertManager AlertManager) *CompositeAnalyzer {
 	weights := map[AnomalyType]float64{
 		AnomalyTypeStatistical: 0.25,
 		AnomalyTypePattern:     0.25,
@@ -45,6 +45,16 @@ func (ca *CompressionAnalyzer) Name() string {
 	return ca.name
 }
 
+// Description returns what the analyzer measures
+func (ca *CompressionAnalyzer) Description() string {
+	return "Measures how well the text compresses; generated text is often unusually predictable"
+}
+
+// Parameters returns nil: the analyzer has no options
+func (ca *CompressionAnalyzer) Parameters() []models.AnalyzerParameter {
+	return nil
+}
+
 // Analyze performs compression analysis on the text
 func (ca *CompressionAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
 	if len(text) == 0 {
@@ -0,0 +1,90 @@
+package cryptographic
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// BloomFilter is a probabilistic set: MayContain never misses an added item
+// but reports items that were never added with a configurable false positive
+// rate. It needs about 1.2 bytes per item at a 1% rate and 1.8 bytes at 0.1%,
+// whatever the size of the items.
+type BloomFilter struct {
+	bits   []uint64
+	m      uint64 // number of bits
+	k      int    // number of hash functions
+	filled int
+}
+
+// NewBloomFilter sizes a filter for the expected number of items and the
+// target false positive rate
+func NewBloomFilter(expectedItems int, falsePositiveRate float64) (*BloomFilter, error) {
+	if expectedItems <= 0 {
+		return nil, fmt.Errorf("expected items must be positive, got %d", expectedItems)
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, fmt.Errorf("false positive rate must be between 0 and 1, got %f", falsePositiveRate)
+	}
+
+	// Optimal sizing: m = -n ln p / (ln 2)^2 bits and k = (m / n) ln 2 hashes
+	n := float64(expectedItems)
+	m := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := int(math.Round(float64(m) / n * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &BloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}, nil
+}
+
+// Add inserts item into the filter
+func (b *BloomFilter) Add(item string) {
+	h1, h2 := bloomHashes(item)
+	for i := 0; i < b.k; i++ {
+		bit := (h1 + uint64(i)*h2) % b.m
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+	b.filled++
+}
+
+// MayContain reports false if item was definitely never added, and true if
+// it probably was
+func (b *BloomFilter) MayContain(item string) bool {
+	h1, h2 := bloomHashes(item)
+	for i := 0; i < b.k; i++ {
+		bit := (h1 + uint64(i)*h2) % b.m
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the number of items added
+func (b *BloomFilter) Len() int {
+	return b.filled
+}
+
+// SizeBytes returns the memory used by the bit array
+func (b *BloomFilter) SizeBytes() int {
+	return len(b.bits) * 8
+}
+
+// bloomHashes derives the two base hashes combined into the k bit positions
+// (Kirsch-Mitzenmacher double hashing)
+func bloomHashes(item string) (uint64, uint64) {
+	a := fnv.New64a()
+	a.Write([]byte(item))
+	b := fnv.New64()
+	b.Write([]byte(item))
+	// An odd step visits distinct positions whatever the filter size
+	return a.Sum64(), b.Sum64() | 1
+}
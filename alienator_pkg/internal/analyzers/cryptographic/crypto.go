@@ -6,11 +6,13 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"fmt"
 	"hash"
 	"math"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/ruvnet/alienator/internal/models"
 	"golang.org/x/crypto/blake2b"
@@ -18,6 +20,25 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
+// Patterns shared by every analysis, compiled once
+var (
+	hexOnlyPattern    = regexp.MustCompile(`^[a-fA-F0-9]+$`)
+	base64OnlyPattern = regexp.MustCompile(`^[A-Za-z0-9+/]+={0,2}$`)
+	base32OnlyPattern = regexp.MustCompile(`^[A-Z2-7]+={0,6}$`)
+
+	hexPattern     = regexp.MustCompile(`\b[a-fA-F0-9]{16,}\b`)
+	base64Pattern  = regexp.MustCompile(`[A-Za-z0-9+/]{20,}={0,2}`)
+	base32Pattern  = regexp.MustCompile(`[A-Z2-7]{20,}={0,6}`)
+	percentPattern = regexp.MustCompile(`%[0-9a-fA-F]{2}`)
+
+	jsonPattern    = regexp.MustCompile(`\{[^{}]*"[^"]*"[^{}]*:[^{}]*\}`)
+	xmlPattern     = regexp.MustCompile(`<[^>]+>[^<]*</[^>]+>`)
+	csvPattern     = regexp.MustCompile(`[^,\n]+,[^,\n]+,[^,\n]+`)
+	kvPattern      = regexp.MustCompile(`[a-zA-Z_][a-zA-Z0-9_]*\s*[=:]\s*[^\s,;]+`)
+	bracketPattern = regexp.MustCompile(`\[[^\[\]]{10,}\]`)
+	quotedPattern  = regexp.MustCompile(`"[^"]{10,}"`)
+)
+
 // CryptographicAnalyzer analyzes cryptographic patterns in text
 type CryptographicAnalyzer struct {
 	name string
@@ -32,6 +53,12 @@ type CryptographicAnalyzer struct {
 	uniformityThreshold float64
 	// Collision detection
 	collisionThreshold int
+	// Bounds on each regex pass over adversarial input
+	limits MatchLimits
+	// Known-hash screening, nil until a dataset is loaded
+	knownHashes     *KnownHashSet
+	knownHashFPRate float64
+	mu              sync.RWMutex
 }
 
 // HashAlgorithm represents a hash algorithm for analysis
@@ -49,6 +76,7 @@ type HashAnalysis struct {
 	IsValidHash  bool
 	Collisions   int
 	Pattern      string
+	Known        bool // found in the known-hash dataset
 }
 
 // NewCryptographicAnalyzer creates a new cryptographic analyzer
@@ -62,9 +90,9 @@ func NewCryptographicAnalyzer() *CryptographicAnalyzer {
 		"sha3_256": regexp.MustCompile(`\b[a-fA-F0-9]{64}\b`), // Same length as SHA256
 		"blake2b": regexp.MustCompile(`\b[a-fA-F0-9]{128}\b`), // 64-byte default
 		"blake2s": regexp.MustCompile(`\b[a-fA-F0-9]{64}\b`),  // 32-byte default
-		"base64":  regexp.MustCompile(`[A-Za-z0-9+/]{20,}={0,2}`),
-		"base32":  regexp.MustCompile(`[A-Z2-7]{20,}={0,6}`),
-		"hex":     regexp.MustCompile(`\b[a-fA-F0-9]{16,}\b`),
+		"base64":  base64Pattern,
+		"base32":  base32Pattern,
+		"hex":     hexPattern,
 	}
 
 	// Define hash algorithms for testing
@@ -87,6 +115,8 @@ func NewCryptographicAnalyzer() *CryptographicAnalyzer {
 		entropyThreshold:    7.5, // High entropy expected for hashes
 		uniformityThreshold: 0.1, // Low chi-square indicates uniform distribution
 		collisionThreshold:  2,   // Maximum expected collisions for analysis
+		limits:              DefaultMatchLimits(),
+		knownHashFPRate:     DefaultKnownHashFalsePositiveRate,
 	}
 }
 
@@ -95,8 +125,77 @@ func (ca *CryptographicAnalyzer) Name() string {
 	return ca.name
 }
 
+// Description returns what the analyzer measures
+func (ca *CryptographicAnalyzer) Description() string {
+	return "Looks for hashes, encoded blobs and other high-entropy tokens that suggest machine-generated content"
+}
+
+// Parameters returns the options accepted by Configure
+func (ca *CryptographicAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "max_regex_matches", Type: models.ParameterInteger, Description: "Matches collected per pattern pass; later matches are ignored", Default: DefaultMatchLimits().MaxMatches},
+		{Name: "max_regex_input", Type: models.ParameterInteger, Description: "Bytes of text scanned per pattern pass; the rest is ignored", Default: DefaultMatchLimits().MaxInputLength},
+		{Name: "max_hashes", Type: models.ParameterInteger, Description: "Hashes analyzed one by one; larger dumps are reservoir sampled down to this many", Default: DefaultMatchLimits().MaxHashes},
+		{Name: "known_hash_file", Type: models.ParameterString, Description: "File of known hashes, one per line, loaded into a Bloom filter", Default: nil},
+		{Name: "known_hash_false_positive_rate", Type: models.ParameterNumber, Description: "False positive rate of the known hash Bloom filter, between 0 and 1", Default: DefaultKnownHashFalsePositiveRate},
+	}
+}
+
+// SetMatchLimits replaces the bounds applied to each regex pass
+func (ca *CryptographicAnalyzer) SetMatchLimits(limits MatchLimits) error {
+	if err := limits.Validate(); err != nil {
+		return fmt.Errorf("invalid match limits: %w", err)
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.limits = limits
+	return nil
+}
+
+// Configure updates the analyzer configuration. "max_regex_matches",
+// "max_regex_input" and "max_hashes" override the match limits,
+// "known_hash_false_positive_rate" sizes the Bloom filter of later loads and
+// "known_hash_file" loads a known-hash dataset.
+func (ca *CryptographicAnalyzer) Configure(config map[string]interface{}) error {
+	ca.mu.RLock()
+	limits := ca.limits
+	ca.mu.RUnlock()
+
+	if maxMatches, ok := config["max_regex_matches"].(int); ok {
+		limits.MaxMatches = maxMatches
+	}
+	if maxInput, ok := config["max_regex_input"].(int); ok {
+		limits.MaxInputLength = maxInput
+	}
+	if maxHashes, ok := config["max_hashes"].(int); ok {
+		limits.MaxHashes = maxHashes
+	}
+	if err := ca.SetMatchLimits(limits); err != nil {
+		return err
+	}
+
+	if rate, ok := config["known_hash_false_positive_rate"].(float64); ok {
+		if rate <= 0 || rate >= 1 {
+			return fmt.Errorf("known hash false positive rate must be between 0 and 1, got %f", rate)
+		}
+		ca.mu.Lock()
+		ca.knownHashFPRate = rate
+		ca.mu.Unlock()
+	}
+	if path, ok := config["known_hash_file"].(string); ok && path != "" {
+		return ca.LoadKnownHashBloom(path)
+	}
+	return nil
+}
+
 // Analyze performs cryptographic analysis on the text
 func (ca *CryptographicAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	ca.mu.RLock()
+	pass := newRegexPass(ca.limits)
+	knownHashes := ca.knownHashes
+	ca.mu.RUnlock()
+
 	if len(text) == 0 {
 		return &models.AnalysisResult{
 			Score:      0.0,
@@ -106,29 +205,43 @@ func (ca *CryptographicAnalyzer) Analyze(ctx context.Context, text string) (*mod
 	}
 
 	// Detect hash patterns
-	detectedHashes := ca.detectHashPatterns(text)
+	detectedHashes := ca.detectHashPatterns(pass, text)
 
-	// Analyze each detected hash
-	hashAnalyses := make([]HashAnalysis, 0)
-	for _, hashStr := range detectedHashes {
+	// Analyze each detected hash, or a sample of them in a dump
+	sampledHashes := sampleHashes(detectedHashes, pass.limits.MaxHashes)
+	hashAnalyses := make([]HashAnalysis, 0, len(sampledHashes))
+	for _, hashStr := range sampledHashes {
 		analysis := ca.analyzeHash(hashStr)
 		hashAnalyses = append(hashAnalyses, analysis)
 	}
 
+	// Screen against the known-hash dataset
+	knownCount, knownFailed := 0, 0
+	if knownHashes != nil && len(sampledHashes) > 0 {
+		var known map[string]bool
+		known, knownFailed = knownHashes.Match(sampledHashes)
+		for i := range hashAnalyses {
+			if known[sampledHashes[i]] {
+				hashAnalyses[i].Known = true
+				knownCount++
+			}
+		}
+	}
+
 	// Detect collision patterns
-	collisions := ca.detectCollisions(detectedHashes)
+	collisions := ca.detectCollisions(sampledHashes)
 
 	// Calculate hash entropy distribution
-	entropyDistribution := ca.calculateEntropyDistribution(detectedHashes)
+	entropyDistribution := ca.calculateEntropyDistribution(hashAnalyses)
 
 	// Analyze encoding patterns (base64, base32, hex)
-	encodingPatterns := ca.analyzeEncodingPatterns(text)
+	encodingPatterns := ca.analyzeEncodingPatterns(pass, text)
 
 	// Calculate cryptographic randomness
-	randomnessScore := ca.calculateCryptographicRandomness(text)
+	randomnessScore := ca.calculateCryptographicRandomness(pass, text)
 
 	// Detect structured data patterns
-	structuredPatterns := ca.detectStructuredPatterns(text)
+	structuredPatterns := ca.detectStructuredPatterns(pass, text)
 
 	// Calculate overall anomaly score
 	score := ca.calculateAnomalyScore(hashAnalyses, collisions, entropyDistribution,
@@ -142,24 +255,47 @@ func (ca *CryptographicAnalyzer) Analyze(ctx context.Context, text string) (*mod
 		Confidence: confidence,
 		Metadata: map[string]interface{}{
 			"detected_hashes":      len(detectedHashes),
+			"known_hashes":         knownCount,
 			"hash_analyses":        ca.summarizeHashAnalyses(hashAnalyses),
 			"collisions_detected":  collisions,
 			"entropy_distribution": entropyDistribution,
 			"encoding_patterns":    encodingPatterns,
 			"randomness_score":     randomnessScore,
 			"structured_patterns":  structuredPatterns,
+			// Set when a regex pass hit MatchLimits; counts are then lower bounds
+			"match_limit_exceeded": pass.matchLimitExceeded,
+			"input_truncated":      pass.inputTruncated,
+			// Set when more hashes were detected than MaxHashes; the per-hash
+			// figures above then describe a sample of analyzed_hashes
+			"hashes_sampled":  len(sampledHashes) < len(detectedHashes),
+			"analyzed_hashes": len(sampledHashes),
+			// Hashes whose known-hash lookup failed, counted as unknown
+			"known_hash_failures": knownFailed,
 		},
 	}, nil
 }
 
-// detectHashPatterns detects potential hash values in text
-func (ca *CryptographicAnalyzer) detectHashPatterns(text string) []string {
+// detectHashPatterns detects potential hash values in text, at most
+// MaxMatches of them
+func (ca *CryptographicAnalyzer) detectHashPatterns(pass *regexPass, text string) []string {
 	hashes := make([]string, 0)
 	seen := make(map[string]bool)
 
+	// Visit patterns in a fixed order so that the same hashes are kept when
+	// the match limit is reached
+	algorithms := make([]string, 0, len(ca.hashPatterns))
+	for algorithm := range ca.hashPatterns {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+
 	// Check each hash pattern
-	for algorithm, pattern := range ca.hashPatterns {
-		matches := pattern.FindAllString(text, -1)
+	for _, algorithm := range algorithms {
+		if len(hashes) >= pass.limits.MaxMatches {
+			pass.matchLimitExceeded = true
+			break
+		}
+		matches := pass.findAll(ca.hashPatterns[algorithm], text)
 		for _, match := range matches {
 			// Filter by length constraints
 			if len(match) >= ca.minHashLength && len(match) <= ca.maxHashLength {
@@ -170,9 +306,12 @@ func (ca *CryptographicAnalyzer) detectHashPatterns(text string) []string {
 				}
 			}
 		}
-		_ = algorithm // Use algorithm for potential future filtering
 	}
 
+	if len(hashes) > pass.limits.MaxMatches {
+		pass.matchLimitExceeded = true
+		hashes = hashes[:pass.limits.MaxMatches]
+	}
 	return hashes
 }
 
@@ -195,7 +334,7 @@ func (ca *CryptographicAnalyzer) identifyHashAlgorithm(hashStr string) string {
 	length := len(hashStr)
 	
 	// Check if it's hexadecimal
-	if matched, _ := regexp.MatchString("^[a-fA-F0-9]+$", hashStr); matched {
+	if hexOnlyPattern.MatchString(hashStr) {
 		switch length {
 		case 32:
 			return "MD5"
@@ -213,12 +352,12 @@ func (ca *CryptographicAnalyzer) identifyHashAlgorithm(hashStr string) string {
 	}
 
 	// Check for Base64 pattern
-	if matched, _ := regexp.MatchString("^[A-Za-z0-9+/]+={0,2}$", hashStr); matched {
+	if base64OnlyPattern.MatchString(hashStr) {
 		return "Base64"
 	}
 
 	// Check for Base32 pattern
-	if matched, _ := regexp.MatchString("^[A-Z2-7]+={0,6}$", hashStr); matched {
+	if base32OnlyPattern.MatchString(hashStr) {
 		return "Base32"
 	}
 
@@ -284,7 +423,7 @@ func (ca *CryptographicAnalyzer) isValidHashFormat(hashStr string) bool {
 	length := len(hashStr)
 	
 	// Hexadecimal hashes
-	if matched, _ := regexp.MatchString("^[a-fA-F0-9]+$", hashStr); matched {
+	if hexOnlyPattern.MatchString(hashStr) {
 		validLengths := []int{32, 40, 56, 64, 96, 128} // Common hash lengths
 		for _, validLen := range validLengths {
 			if length == validLen {
@@ -294,12 +433,12 @@ func (ca *CryptographicAnalyzer) isValidHashFormat(hashStr string) bool {
 	}
 
 	// Base64 encoded
-	if matched, _ := regexp.MatchString("^[A-Za-z0-9+/]+={0,2}$", hashStr); matched && length >= 20 {
+	if base64OnlyPattern.MatchString(hashStr) && length >= 20 {
 		return true
 	}
 
 	// Base32 encoded
-	if matched, _ := regexp.MatchString("^[A-Z2-7]+={0,6}$", hashStr); matched && length >= 20 {
+	if base32OnlyPattern.MatchString(hashStr) && length >= 20 {
 		return true
 	}
 
@@ -418,9 +557,10 @@ func (ca *CryptographicAnalyzer) detectCollisions(hashes []string) map[string]in
 	return collisions
 }
 
-// calculateEntropyDistribution calculates entropy distribution across all hashes
-func (ca *CryptographicAnalyzer) calculateEntropyDistribution(hashes []string) map[string]interface{} {
-	if len(hashes) == 0 {
+// calculateEntropyDistribution summarizes the entropies of the analyzed
+// hashes in a single pass, without collecting them
+func (ca *CryptographicAnalyzer) calculateEntropyDistribution(analyses []HashAnalysis) map[string]interface{} {
+	if len(analyses) == 0 {
 		return map[string]interface{}{
 			"mean":     0.0,
 			"variance": 0.0,
@@ -429,38 +569,27 @@ func (ca *CryptographicAnalyzer) calculateEntropyDistribution(hashes []string) m
 		}
 	}
 
-	entropies := make([]float64, len(hashes))
-	sum := 0.0
-
-	for i, hash := range hashes {
-		entropy := ca.calculateHashEntropy(hash)
-		entropies[i] = entropy
-		sum += entropy
-	}
-
-	mean := sum / float64(len(entropies))
-
-	// Calculate variance
-	varianceSum := 0.0
-	for _, entropy := range entropies {
-		diff := entropy - mean
-		varianceSum += diff * diff
+	// Welford's algorithm keeps the mean and squared deviations up to date
+	mean, squares := 0.0, 0.0
+	min, max := math.Inf(1), math.Inf(-1)
+	for i, analysis := range analyses {
+		delta := analysis.Entropy - mean
+		mean += delta / float64(i+1)
+		squares += delta * (analysis.Entropy - mean)
+		min = math.Min(min, analysis.Entropy)
+		max = math.Max(max, analysis.Entropy)
 	}
-	variance := varianceSum / float64(len(entropies))
-
-	// Find min and max
-	sort.Float64s(entropies)
 
 	return map[string]interface{}{
 		"mean":     mean,
-		"variance": variance,
-		"min":      entropies[0],
-		"max":      entropies[len(entropies)-1],
+		"variance": squares / float64(len(analyses)),
+		"min":      min,
+		"max":      max,
 	}
 }
 
 // analyzeEncodingPatterns analyzes encoding patterns in text
-func (ca *CryptographicAnalyzer) analyzeEncodingPatterns(text string) map[string]int {
+func (ca *CryptographicAnalyzer) analyzeEncodingPatterns(pass *regexPass, text string) map[string]int {
 	patterns := map[string]int{
 		"base64":    0,
 		"base32":    0,
@@ -470,36 +599,31 @@ func (ca *CryptographicAnalyzer) analyzeEncodingPatterns(text string) map[string
 	}
 
 	// Base64 pattern
-	base64Pattern := regexp.MustCompile(`[A-Za-z0-9+/]{20,}={0,2}`)
-	patterns["base64"] = len(base64Pattern.FindAllString(text, -1))
+	patterns["base64"] = pass.count(base64Pattern, text)
 
 	// Base32 pattern
-	base32Pattern := regexp.MustCompile(`[A-Z2-7]{20,}={0,6}`)
-	patterns["base32"] = len(base32Pattern.FindAllString(text, -1))
+	patterns["base32"] = pass.count(base32Pattern, text)
 
 	// Hexadecimal pattern
-	hexPattern := regexp.MustCompile(`\b[a-fA-F0-9]{16,}\b`)
-	patterns["hex"] = len(hexPattern.FindAllString(text, -1))
+	patterns["hex"] = pass.count(hexPattern, text)
 
 	// URL encoding
-	urlPattern := regexp.MustCompile(`%[0-9a-fA-F]{2}`)
-	patterns["url_encoded"] = len(urlPattern.FindAllString(text, -1))
+	patterns["url_encoded"] = pass.count(percentPattern, text)
 
 	// Percent encoding (broader)
-	percentPattern := regexp.MustCompile(`%[0-9a-fA-F]{2}`)
-	patterns["percent_encoded"] = len(percentPattern.FindAllString(text, -1))
+	patterns["percent_encoded"] = patterns["url_encoded"]
 
 	return patterns
 }
 
 // calculateCryptographicRandomness calculates randomness metrics
-func (ca *CryptographicAnalyzer) calculateCryptographicRandomness(text string) float64 {
+func (ca *CryptographicAnalyzer) calculateCryptographicRandomness(pass *regexPass, text string) float64 {
 	if len(text) == 0 {
 		return 0.0
 	}
 
 	// Extract potential cryptographic data (hex, base64, etc.)
-	cryptoData := ca.extractCryptographicData(text)
+	cryptoData := ca.extractCryptographicData(pass, text)
 	if len(cryptoData) == 0 {
 		return 0.0
 	}
@@ -531,17 +655,15 @@ func (ca *CryptographicAnalyzer) calculateCryptographicRandomness(text string) f
 }
 
 // extractCryptographicData extracts potential cryptographic data from text
-func (ca *CryptographicAnalyzer) extractCryptographicData(text string) []string {
+func (ca *CryptographicAnalyzer) extractCryptographicData(pass *regexPass, text string) []string {
 	data := make([]string, 0)
 
 	// Extract hex strings
-	hexPattern := regexp.MustCompile(`\b[a-fA-F0-9]{16,}\b`)
-	hexMatches := hexPattern.FindAllString(text, -1)
+	hexMatches := pass.findAll(hexPattern, text)
 	data = append(data, hexMatches...)
 
 	// Extract base64 strings
-	base64Pattern := regexp.MustCompile(`[A-Za-z0-9+/]{20,}={0,2}`)
-	base64Matches := base64Pattern.FindAllString(text, -1)
+	base64Matches := pass.findAll(base64Pattern, text)
 	data = append(data, base64Matches...)
 
 	return data
@@ -641,7 +763,7 @@ func (ca *CryptographicAnalyzer) calculateFrequencyTest(data string) float64 {
 }
 
 // detectStructuredPatterns detects structured data patterns
-func (ca *CryptographicAnalyzer) detectStructuredPatterns(text string) map[string]int {
+func (ca *CryptographicAnalyzer) detectStructuredPatterns(pass *regexPass, text string) map[string]int {
 	patterns := map[string]int{
 		"json_like":    0,
 		"xml_like":     0,
@@ -652,28 +774,22 @@ func (ca *CryptographicAnalyzer) detectStructuredPatterns(text string) map[strin
 	}
 
 	// JSON-like patterns
-	jsonPattern := regexp.MustCompile(`\{[^{}]*"[^"]*"[^{}]*:[^{}]*\}`)
-	patterns["json_like"] = len(jsonPattern.FindAllString(text, -1))
+	patterns["json_like"] = pass.count(jsonPattern, text)
 
 	// XML-like patterns
-	xmlPattern := regexp.MustCompile(`<[^>]+>[^<]*</[^>]+>`)
-	patterns["xml_like"] = len(xmlPattern.FindAllString(text, -1))
+	patterns["xml_like"] = pass.count(xmlPattern, text)
 
 	// CSV-like patterns
-	csvPattern := regexp.MustCompile(`[^,\n]+,[^,\n]+,[^,\n]+`)
-	patterns["csv_like"] = len(csvPattern.FindAllString(text, -1))
+	patterns["csv_like"] = pass.count(csvPattern, text)
 
 	// Key-value patterns
-	kvPattern := regexp.MustCompile(`[a-zA-Z_][a-zA-Z0-9_]*\s*[=:]\s*[^\s,;]+`)
-	patterns["key_value"] = len(kvPattern.FindAllString(text, -1))
+	patterns["key_value"] = pass.count(kvPattern, text)
 
 	// Bracketed content
-	bracketPattern := regexp.MustCompile(`\[[^\[\]]{10,}\]`)
-	patterns["bracketed"] = len(bracketPattern.FindAllString(text, -1))
+	patterns["bracketed"] = pass.count(bracketPattern, text)
 
 	// Quoted content
-	quotedPattern := regexp.MustCompile(`"[^"]{10,}"`)
-	patterns["quoted"] = len(quotedPattern.FindAllString(text, -1))
+	patterns["quoted"] = pass.count(quotedPattern, text)
 
 	return patterns
 }
@@ -774,6 +890,11 @@ func (ca *CryptographicAnalyzer) calculateHashScore(analyses []HashAnalysis) flo
 			hashScore += 0.2
 		}
 
+		// Hashes from a known leaked or weak list are suspicious
+		if analysis.Known {
+			hashScore += 0.3
+		}
+
 		// Weak patterns are suspicious
 		if analysis.Pattern == "weak" || analysis.Pattern == "repeated" {
 			hashScore += 0.3
@@ -838,6 +959,7 @@ func (ca *CryptographicAnalyzer) summarizeHashAnalyses(analyses []HashAnalysis)
 	algorithms := make(map[string]int)
 	patterns := make(map[string]int)
 	validCount := 0
+	knownCount := 0
 	totalEntropy := 0.0
 
 	for _, analysis := range analyses {
@@ -847,6 +969,9 @@ func (ca *CryptographicAnalyzer) summarizeHashAnalyses(analyses []HashAnalysis)
 		if analysis.IsValidHash {
 			validCount++
 		}
+		if analysis.Known {
+			knownCount++
+		}
 		
 		totalEntropy += analysis.Entropy
 	}
@@ -855,6 +980,7 @@ func (ca *CryptographicAnalyzer) summarizeHashAnalyses(analyses []HashAnalysis)
 		"algorithms":     algorithms,
 		"patterns":       patterns,
 		"valid_count":    validCount,
+		"known_count":    knownCount,
 		"average_entropy": totalEntropy / float64(len(analyses)),
 	}
 }
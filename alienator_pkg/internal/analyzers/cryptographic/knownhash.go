@@ -0,0 +1,224 @@
+package cryptographic
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultKnownHashFalsePositiveRate is the Bloom filter false positive rate
+// used when loading a known-hash dataset
+const DefaultKnownHashFalsePositiveRate = 0.001
+
+// KnownHashSet screens hashes against a dataset of known (leaked or weak)
+// hashes. A Bloom filter rules out most hashes; the rare positive hits are
+// looked up in an index, loaded once, of a 64-bit digest and file offset per
+// hash, and a digest match is confirmed by reading that one line of the
+// dataset, so the result is exact. The index takes 16 bytes per hash however
+// long the hashes are.
+//
+// The dataset has one hash per line, compared case-insensitively. Blank lines
+// and lines starting with '#' are skipped, and anything after a ':' is
+// ignored, so "HASH:COUNT" lists such as Have I Been Pwned's can be used as
+// they are.
+type KnownHashSet struct {
+	path  string
+	bloom *BloomFilter
+	// The digests of the hashes in ascending order, and the offset in the
+	// dataset of the hash with each digest
+	digests []uint64
+	offsets []int64
+}
+
+// LoadKnownHashSet reads the dataset at path into a Bloom filter with the
+// given false positive rate and a digest index
+func LoadKnownHashSet(path string, falsePositiveRate float64) (*KnownHashSet, error) {
+	set := &KnownHashSet{path: path}
+	if err := scanKnownHashes(path, func(hash string, offset int64) {
+		set.digests = append(set.digests, knownHashDigest(hash))
+		set.offsets = append(set.offsets, offset)
+	}); err != nil {
+		return nil, err
+	}
+	if len(set.digests) == 0 {
+		return nil, fmt.Errorf("known hash file %s contains no hashes", path)
+	}
+	sort.Sort(digestIndex{set})
+
+	bloom, err := NewBloomFilter(len(set.digests), falsePositiveRate)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanKnownHashes(path, func(hash string, _ int64) {
+		bloom.Add(hash)
+	}); err != nil {
+		return nil, err
+	}
+	set.bloom = bloom
+	return set, nil
+}
+
+// digestIndex sorts the index of a KnownHashSet by digest
+type digestIndex struct{ *KnownHashSet }
+
+func (d digestIndex) Len() int           { return len(d.digests) }
+func (d digestIndex) Less(i, j int) bool { return d.digests[i] < d.digests[j] }
+func (d digestIndex) Swap(i, j int) {
+	d.digests[i], d.digests[j] = d.digests[j], d.digests[i]
+	d.offsets[i], d.offsets[j] = d.offsets[j], d.offsets[i]
+}
+
+// Len returns the number of hashes in the dataset
+func (s *KnownHashSet) Len() int {
+	return s.bloom.Len()
+}
+
+// Check confirms that the dataset file, which confirms every match, is still
+// readable and that the filter holds hashes
+func (s *KnownHashSet) Check() error {
+	if s.bloom.Len() == 0 {
+		return fmt.Errorf("known hash filter for %s is empty", s.path)
+	}
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("known hash file is no longer readable: %w", err)
+	}
+	return file.Close()
+}
+
+// Match returns the hashes that are in the dataset. Hashes the Bloom filter
+// rules out cost nothing further; the others are looked up in the index,
+// and each digest match costs one read of the dataset file. A hash whose
+// match could not be read is treated as unknown and counted in failed,
+// rather than failing the analysis: ValidateHealth reports the file gone.
+func (s *KnownHashSet) Match(hashes []string) (known map[string]bool, failed int) {
+	known = make(map[string]bool)
+	var file *os.File
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	for _, hash := range hashes {
+		normalized := normalizeKnownHash(hash)
+		if !s.bloom.MayContain(normalized) {
+			continue
+		}
+		digest := knownHashDigest(normalized)
+		for i := sort.Search(len(s.digests), func(i int) bool { return s.digests[i] >= digest }); i < len(s.digests) && s.digests[i] == digest; i++ {
+			if file == nil {
+				var err error
+				if file, err = os.Open(s.path); err != nil {
+					failed++
+					break
+				}
+			}
+			match, err := knownHashAt(file, s.offsets[i], normalized)
+			if err != nil {
+				failed++
+				break
+			}
+			if match {
+				known[hash] = true
+				break
+			}
+		}
+	}
+	return known, failed
+}
+
+// knownHashAt reports whether the dataset holds hash at offset
+func knownHashAt(file *os.File, offset int64, hash string) (bool, error) {
+	// One byte more than the hash shows whether the line's hash ends there
+	buf := make([]byte, len(hash)+1)
+	n, err := file.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read known hash file: %w", err)
+	}
+	if n < len(hash) || !strings.EqualFold(string(buf[:len(hash)]), hash) {
+		return false, nil
+	}
+	return n == len(hash) || strings.IndexByte(":\r\n \t", buf[len(hash)]) >= 0, nil
+}
+
+// knownHashDigest is the 64-bit digest of a normalized hash kept in the index
+func knownHashDigest(hash string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(hash))
+	return h.Sum64()
+}
+
+// scanKnownHashes calls visit with each normalized hash in the dataset and
+// the offset in the file where it starts
+func scanKnownHashes(path string, visit func(hash string, offset int64)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open known hash file: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		line, err := reader.ReadString('\n')
+		start := offset
+		offset += int64(len(line))
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			if i := strings.IndexByte(trimmed, ':'); i >= 0 {
+				trimmed = trimmed[:i]
+			}
+			leading := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+			visit(normalizeKnownHash(trimmed), start+int64(leading))
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read known hash file %s: %w", path, err)
+		}
+	}
+}
+
+func normalizeKnownHash(hash string) string {
+	return strings.ToLower(strings.TrimSpace(hash))
+}
+
+// LoadKnownHashBloom loads the known-hash dataset at path; detected hashes
+// found in it are flagged as known in later analyses. The Bloom filter uses
+// the rate set with the "known_hash_false_positive_rate" option.
+func (ca *CryptographicAnalyzer) LoadKnownHashBloom(path string) error {
+	ca.mu.RLock()
+	rate := ca.knownHashFPRate
+	ca.mu.RUnlock()
+
+	set, err := LoadKnownHashSet(path, rate)
+	if err != nil {
+		return err
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.knownHashes = set
+	return nil
+}
+
+// ValidateHealth reports whether the loaded known-hash dataset, if any, can
+// still confirm matches
+func (ca *CryptographicAnalyzer) ValidateHealth(ctx context.Context) error {
+	ca.mu.RLock()
+	knownHashes := ca.knownHashes
+	ca.mu.RUnlock()
+
+	if knownHashes == nil {
+		return nil
+	}
+	return knownHashes.Check()
+}
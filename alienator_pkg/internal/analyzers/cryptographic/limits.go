@@ -0,0 +1,117 @@
+package cryptographic
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"unicode/utf8"
+)
+
+// MatchLimits bounds the work done by each regex pass over the text, so that
+// adversarial input (e.g. a megabyte of hex) cannot produce huge match slices
+// or hold the CPU for long
+type MatchLimits struct {
+	MaxMatches     int // matches collected per pass; later matches are ignored
+	MaxInputLength int // bytes of text scanned per pass; the rest is ignored
+	// MaxHashes is the number of detected hashes analyzed one by one; from a
+	// dump with more, a uniform random sample of this size is analyzed
+	MaxHashes int
+}
+
+// DefaultMatchLimits returns limits that no realistic text reaches: 1000
+// matches and 256 KiB of input per pass, and 500 hashes analyzed
+func DefaultMatchLimits() MatchLimits {
+	return MatchLimits{
+		MaxMatches:     1000,
+		MaxInputLength: 256 * 1024,
+		MaxHashes:      500,
+	}
+}
+
+// Validate checks that the limits are positive
+func (l MatchLimits) Validate() error {
+	if l.MaxMatches <= 0 {
+		return fmt.Errorf("maximum regex matches must be positive, got %d", l.MaxMatches)
+	}
+	if l.MaxInputLength <= 0 {
+		return fmt.Errorf("maximum regex input length must be positive, got %d", l.MaxInputLength)
+	}
+	if l.MaxHashes <= 0 {
+		return fmt.Errorf("maximum hashes must be positive, got %d", l.MaxHashes)
+	}
+	return nil
+}
+
+// reservoirSeed seeds hash sampling, so that the same text always yields the
+// same sample and score
+const reservoirSeed = 1
+
+// sampleHashes returns hashes unchanged when there are at most max of them,
+// and otherwise a uniform sample of max hashes drawn in one pass with
+// reservoir sampling
+func sampleHashes(hashes []string, max int) []string {
+	if len(hashes) <= max {
+		return hashes
+	}
+
+	rng := rand.New(rand.NewSource(reservoirSeed))
+	reservoir := make([]string, max)
+	copy(reservoir, hashes[:max])
+	for i := max; i < len(hashes); i++ {
+		if j := rng.Intn(i + 1); j < max {
+			reservoir[j] = hashes[i]
+		}
+	}
+	return reservoir
+}
+
+// regexPass runs the regex passes of one analysis within the limits and
+// records whether any of them was cut short
+type regexPass struct {
+	limits             MatchLimits
+	matchLimitExceeded bool
+	inputTruncated     bool
+}
+
+func newRegexPass(limits MatchLimits) *regexPass {
+	return &regexPass{limits: limits}
+}
+
+// findAll returns at most MaxMatches matches of re in the first
+// MaxInputLength bytes of text
+func (p *regexPass) findAll(re *regexp.Regexp, text string) []string {
+	text = p.truncate(text)
+
+	// Asking for one match more than the limit tells whether it was reached
+	// without scanning the rest of the text
+	matches := re.FindAllString(text, p.limits.MaxMatches+1)
+	if len(matches) > p.limits.MaxMatches {
+		p.matchLimitExceeded = true
+		matches = matches[:p.limits.MaxMatches]
+	}
+	return matches
+}
+
+// count returns the number of matches of re, capped like findAll
+func (p *regexPass) count(re *regexp.Regexp, text string) int {
+	return len(p.findAll(re, text))
+}
+
+// truncate cuts text to MaxInputLength bytes without splitting a character
+func (p *regexPass) truncate(text string) string {
+	if len(text) <= p.limits.MaxInputLength {
+		return text
+	}
+	p.inputTruncated = true
+
+	end := p.limits.MaxInputLength
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
+
+// exceeded reports whether any pass hit a limit
+func (p *regexPass) exceeded() bool {
+	return p.matchLimitExceeded || p.inputTruncated
+}
@@ -3,11 +3,14 @@ package embedding
 import (
 	"context"
 	"math"
-	"sort"
+	"math/rand"
+	"runtime"
 	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
 )
 
 // EmbeddingAnalyzer analyzes text using embedding-based techniques
@@ -17,12 +20,23 @@ type EmbeddingAnalyzer struct {
 	embeddingDim   int
 	vocabularySize int
 	// Clustering parameters
-	numClusters    int
-	maxIterations  int
+	numClusters          int
+	maxIterations        int
 	convergenceThreshold float64
+	kmeansAlgorithm      string // KMeansFull or KMeansMiniBatch
+	kmeansBatchSize      int    // Embeddings per mini-batch iteration
+	kmeansWorkers        int    // Goroutines assigning points to centroids
+	kmeansSeed           int64  // Seeds mini-batch sampling
 	// Outlier detection parameters
 	outlierThreshold  float64
 	distanceMetric    string
+	// Memory guard parameters
+	maxEmbeddings           int // Embeddings beyond this are sampled down
+	pairwiseSampleThreshold int // Above this, pairwise metrics use sampled pairs
+	pairwiseSampleSize      int // Number of pairs sampled for pairwise estimates
+	// Output parameters
+	includeEmbeddings       bool // Return the vectors and cluster assignments in metadata
+	mu                      sync.RWMutex
 }
 
 // WordEmbedding represents a simple word embedding
@@ -42,14 +56,21 @@ type ClusterResult struct {
 // NewEmbeddingAnalyzer creates a new embedding analyzer
 func NewEmbeddingAnalyzer() *EmbeddingAnalyzer {
 	return &EmbeddingAnalyzer{
-		name:                 "embedding",
-		embeddingDim:         50, // Reduced dimension for efficiency
-		vocabularySize:       1000,
-		numClusters:          5,
-		maxIterations:        100,
-		convergenceThreshold: 1e-4,
-		outlierThreshold:     2.0, // Standard deviations
-		distanceMetric:       "euclidean",
+		name:                    "embedding",
+		embeddingDim:            50, // Reduced dimension for efficiency
+		vocabularySize:          1000,
+		numClusters:             5,
+		maxIterations:           100,
+		convergenceThreshold:    1e-4,
+		kmeansAlgorithm:         KMeansFull,
+		kmeansBatchSize:         100,
+		kmeansWorkers:           runtime.NumCPU(),
+		kmeansSeed:              1,
+		outlierThreshold:        2.0, // Standard deviations
+		distanceMetric:          "euclidean",
+		maxEmbeddings:           1000,
+		pairwiseSampleThreshold: 200,
+		pairwiseSampleSize:      10000,
 	}
 }
 
@@ -58,8 +79,81 @@ func (ea *EmbeddingAnalyzer) Name() string {
 	return ea.name
 }
 
+// Description returns what the analyzer measures
+func (ea *EmbeddingAnalyzer) Description() string {
+	return "Clusters word embeddings to find semantic outliers and unnaturally tight topics"
+}
+
+// Parameters returns the options accepted by Configure
+func (ea *EmbeddingAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "max_embeddings", Type: models.ParameterInteger, Description: "Most words embedded per analysis", Default: 1000},
+		{Name: "pairwise_sample_threshold", Type: models.ParameterInteger, Description: "Embeddings above which pairwise similarity is sampled rather than computed in full", Default: 200},
+		{Name: "pairwise_sample_size", Type: models.ParameterInteger, Description: "Pairs sampled when pairwise similarity is sampled", Default: 10000},
+		{Name: "kmeans_algorithm", Type: models.ParameterString, Description: "K-means variant used for clustering", Default: KMeansFull, Values: []string{KMeansFull, KMeansMiniBatch}},
+		{Name: "kmeans_batch_size", Type: models.ParameterInteger, Description: "Embeddings per mini-batch k-means iteration", Default: 100},
+		{Name: "kmeans_workers", Type: models.ParameterInteger, Description: "Goroutines assigning embeddings to clusters; defaults to the CPU count", Default: nil},
+		{Name: "kmeans_seed", Type: models.ParameterInteger, Description: "Seed for centroid initialization and batch sampling", Default: 1},
+		{Name: "include_embeddings", Type: models.ParameterBoolean, Description: "Include the embedding vectors in the result", Default: false},
+	}
+}
+
+// Configure updates the analyzer configuration
+func (ea *EmbeddingAnalyzer) Configure(config map[string]interface{}) error {
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+
+	if maxEmbeddings, ok := config["max_embeddings"].(int); ok && maxEmbeddings > 0 {
+		ea.maxEmbeddings = maxEmbeddings
+	}
+
+	if threshold, ok := config["pairwise_sample_threshold"].(int); ok && threshold > 1 {
+		ea.pairwiseSampleThreshold = threshold
+	}
+
+	if sampleSize, ok := config["pairwise_sample_size"].(int); ok && sampleSize > 0 {
+		ea.pairwiseSampleSize = sampleSize
+	}
+
+	if algorithm, ok := config["kmeans_algorithm"].(string); ok {
+		if err := validateKMeansAlgorithm(algorithm); err != nil {
+			return err
+		}
+		ea.kmeansAlgorithm = algorithm
+	}
+
+	if batchSize, ok := config["kmeans_batch_size"].(int); ok && batchSize > 0 {
+		ea.kmeansBatchSize = batchSize
+	}
+
+	if workers, ok := config["kmeans_workers"].(int); ok && workers > 0 {
+		ea.kmeansWorkers = workers
+	}
+
+	if seed, ok := config["kmeans_seed"].(int); ok {
+		ea.kmeansSeed = int64(seed)
+	}
+
+	// Off by default: the vectors are far larger than the rest of the result
+	if include, ok := config["include_embeddings"].(bool); ok {
+		ea.includeEmbeddings = include
+	}
+
+	return nil
+}
+
 // Analyze performs embedding-based analysis on the text
 func (ea *EmbeddingAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return ea.AnalyzeTokens(ctx, tokenizer.New(tokenizer.Default, text))
+}
+
+// AnalyzeTokens performs embedding-based analysis on already tokenized text
+func (ea *EmbeddingAnalyzer) AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	text := tokens.Text()
+
+	ea.mu.RLock()
+	defer ea.mu.RUnlock()
+
 	if len(text) == 0 {
 		return &models.AnalysisResult{
 			Score:      0.0,
@@ -69,7 +163,7 @@ func (ea *EmbeddingAnalyzer) Analyze(ctx context.Context, text string) (*models.
 	}
 
 	// Generate text embeddings
-	embeddings := ea.generateTextEmbeddings(text)
+	embeddings, sentenceIndices := ea.generateTextEmbeddings(tokens)
 	if len(embeddings) == 0 {
 		return &models.AnalysisResult{
 			Score:      0.0,
@@ -78,11 +172,21 @@ func (ea *EmbeddingAnalyzer) Analyze(ctx context.Context, text string) (*models.
 		}, nil
 	}
 
+	// Cap the number of embeddings so pathological inputs cannot exhaust memory
+	originalEmbeddings := len(embeddings)
+	embeddings, sentenceIndices = ea.sampleEmbeddings(embeddings, sentenceIndices)
+	embeddingsSampled := len(embeddings) < originalEmbeddings
+	pairwiseSampled := len(embeddings) > ea.pairwiseSampleThreshold
+
 	// Perform k-means clustering
 	clusters, clusterAssignments := ea.performKMeansClustering(embeddings)
 
-	// Detect outliers
+	// Detect outliers, and the sentences they were embedded from
 	outliers, outlierScore := ea.detectOutliers(embeddings, clusters, clusterAssignments)
+	outlierSentences := make([]int, len(outliers))
+	for i, outlier := range outliers {
+		outlierSentences[i] = sentenceIndices[outlier]
+	}
 
 	// Calculate centroid distances
 	centroidDistances := ea.calculateCentroidDistances(embeddings, clusters, clusterAssignments)
@@ -103,64 +207,104 @@ func (ea *EmbeddingAnalyzer) Analyze(ctx context.Context, text string) (*models.
 	// Calculate confidence
 	confidence := ea.calculateConfidence(text, embeddings, outlierScore, coherenceScore)
 
+	metadata := map[string]interface{}{
+		"num_embeddings":        len(embeddings),
+		"num_clusters":          len(clusters),
+		"kmeans_algorithm":      ea.kmeansAlgorithm,
+		"cluster_inertia":       ea.clusterInertia(embeddings, clusters, clusterAssignments),
+		"num_outliers":          len(outliers),
+		"outlier_sentences":     outlierSentences,
+		"outlier_score":         outlierScore,
+		"coherence_score":       coherenceScore,
+		"semantic_density":      semanticDensity,
+		"dimensional_variance":  dimensionalVariance,
+		"avg_centroid_distance": ea.calculateMean(centroidDistances),
+		"embedding_dimension":   ea.embeddingDim,
+		"original_embeddings":   originalEmbeddings,
+		"embeddings_sampled":    embeddingsSampled,
+		"pairwise_sampled":      pairwiseSampled,
+	}
+	if ea.includeEmbeddings {
+		metadata["embeddings"] = embeddings
+		metadata["cluster_assignments"] = clusterAssignments
+		metadata["embedding_sentences"] = sentenceIndices
+	}
+
 	return &models.AnalysisResult{
 		Score:      score,
 		Confidence: confidence,
-		Metadata: map[string]interface{}{
-			"num_embeddings":        len(embeddings),
-			"num_clusters":          len(clusters),
-			"num_outliers":          len(outliers),
-			"outlier_score":         outlierScore,
-			"coherence_score":       coherenceScore,
-			"semantic_density":      semanticDensity,
-			"dimensional_variance":  dimensionalVariance,
-			"avg_centroid_distance": ea.calculateMean(centroidDistances),
-			"embedding_dimension":   ea.embeddingDim,
-		},
+		Metadata:   metadata,
 	}, nil
 }
 
-// generateTextEmbeddings generates simple embeddings for text segments
-func (ea *EmbeddingAnalyzer) generateTextEmbeddings(text string) [][]float64 {
-	// Split text into sentences for embedding generation
-	sentences := ea.splitIntoSentences(text)
-	embeddings := make([][]float64, 0, len(sentences))
+// sampleEmbeddings reduces embeddings to at most maxEmbeddings using evenly
+// spaced selection, so the subset still covers the whole document. The
+// sentence indices are sampled alongside.
+func (ea *EmbeddingAnalyzer) sampleEmbeddings(embeddings [][]float64, sentenceIndices []int) ([][]float64, []int) {
+	if ea.maxEmbeddings <= 0 || len(embeddings) <= ea.maxEmbeddings {
+		return embeddings, sentenceIndices
+	}
 
-	for _, sentence := range sentences {
-		if len(strings.TrimSpace(sentence)) == 0 {
-			continue
-		}
-		
-		embedding := ea.generateSentenceEmbedding(sentence)
-		if embedding != nil {
-			embeddings = append(embeddings, embedding)
+	sampled := make([][]float64, ea.maxEmbeddings)
+	sampledIndices := make([]int, ea.maxEmbeddings)
+	step := float64(len(embeddings)) / float64(ea.maxEmbeddings)
+	for i := range sampled {
+		sampled[i] = embeddings[int(float64(i)*step)]
+		sampledIndices[i] = sentenceIndices[int(float64(i)*step)]
+	}
+
+	return sampled, sampledIndices
+}
+
+// forEachPair visits every pair of embedding indices, or a deterministic random
+// sample of pairs once the embedding count exceeds pairwiseSampleThreshold
+func (ea *EmbeddingAnalyzer) forEachPair(n int, visit func(i, j int)) {
+	if n <= ea.pairwiseSampleThreshold {
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				visit(i, j)
+			}
 		}
+		return
 	}
 
-	return embeddings
+	// Seed from the input size so repeated analyses of the same text agree
+	rng := rand.New(rand.NewSource(int64(n)))
+	for k := 0; k < ea.pairwiseSampleSize; k++ {
+		i := rng.Intn(n)
+		j := rng.Intn(n - 1)
+		if j >= i {
+			j++
+		}
+		visit(i, j)
+	}
 }
 
-// splitIntoSentences splits text into sentences
-func (ea *EmbeddingAnalyzer) splitIntoSentences(text string) []string {
-	// Simple sentence splitting - could be enhanced with more sophisticated NLP
-	sentences := strings.FieldsFunc(text, func(c rune) bool {
-		return c == '.' || c == '!' || c == '?'
-	})
+// generateTextEmbeddings generates simple embeddings for text segments,
+// returning with them the index of each embedded sentence in tokens.Sentences()
+func (ea *EmbeddingAnalyzer) generateTextEmbeddings(tokens *tokenizer.Tokens) ([][]float64, []int) {
+	sentences := tokens.Sentences()
+	sentenceWords := tokens.SentenceWords()
+	embeddings := make([][]float64, 0, len(sentences))
+	sentenceIndices := make([]int, 0, len(sentences))
 
-	result := make([]string, 0, len(sentences))
-	for _, sentence := range sentences {
-		sentence = strings.TrimSpace(sentence)
-		if len(sentence) > 10 { // Filter very short sentences
-			result = append(result, sentence)
+	for i, sentence := range sentences {
+		if len(sentence) <= 10 { // Filter very short sentences
+			continue
+		}
+		
+		embedding := ea.generateSentenceEmbedding(sentenceWords[i])
+		if embedding != nil {
+			embeddings = append(embeddings, embedding)
+			sentenceIndices = append(sentenceIndices, i)
 		}
 	}
 
-	return result
+	return embeddings, sentenceIndices
 }
 
 // generateSentenceEmbedding generates a simple embedding for a sentence
-func (ea *EmbeddingAnalyzer) generateSentenceEmbedding(sentence string) []float64 {
-	words := strings.Fields(strings.ToLower(sentence))
+func (ea *EmbeddingAnalyzer) generateSentenceEmbedding(words []string) []float64 {
 	if len(words) == 0 {
 		return nil
 	}
@@ -365,23 +509,23 @@ func (ea *EmbeddingAnalyzer) performKMeansClustering(embeddings [][]float64) ([]
 	}
 
 	k := ea.numClusters
+	if ea.kmeansAlgorithm == KMeansMiniBatch {
+		centroids, assignments := ea.performMiniBatchKMeans(embeddings, k)
+		return ea.buildClusters(embeddings, centroids, assignments), assignments
+	}
 	dim := len(embeddings[0])
 
 	// Initialize centroids randomly
 	centroids := ea.initializeCentroids(embeddings, k)
 	assignments := make([]int, len(embeddings))
+	all := make([]int, len(embeddings))
+	for i := range all {
+		all[i] = i
+	}
 	
 	for iteration := 0; iteration < ea.maxIterations; iteration++ {
-		changed := false
-
 		// Assign points to nearest centroid
-		for i, point := range embeddings {
-			newAssignment := ea.findNearestCentroid(point, centroids)
-			if newAssignment != assignments[i] {
-				changed = true
-				assignments[i] = newAssignment
-			}
-		}
+		changed := ea.assignPoints(embeddings, all, centroids, assignments, ea.kmeansWorkers)
 
 		if !changed {
 			break
@@ -429,7 +573,12 @@ func (ea *EmbeddingAnalyzer) performKMeansClustering(embeddings [][]float64) ([]
 		}
 	}
 
-	// Build cluster results
+	return ea.buildClusters(embeddings, centroids, assignments), assignments
+}
+
+// buildClusters groups the embeddings by their assigned centroid
+func (ea *EmbeddingAnalyzer) buildClusters(embeddings [][]float64, centroids [][]float64, assignments []int) []ClusterResult {
+	k := len(centroids)
 	clusters := make([]ClusterResult, k)
 	for i := 0; i < k; i++ {
 		clusterPoints := make([][]float64, 0)
@@ -447,7 +596,7 @@ func (ea *EmbeddingAnalyzer) performKMeansClustering(embeddings [][]float64) ([]
 		}
 	}
 
-	return clusters, assignments
+	return clusters
 }
 
 // initializeCentroids initializes k centroids using k-means++ method
@@ -643,17 +792,16 @@ func (ea *EmbeddingAnalyzer) calculateEmbeddingCoherence(embeddings [][]float64)
 	}
 
 	// Calculate pairwise similarities
-	similarities := make([]float64, 0)
-	
-	for i := 0; i < len(embeddings); i++ {
-		for j := i + 1; j < len(embeddings); j++ {
-			similarity := ea.cosineSimilarity(embeddings[i], embeddings[j])
-			similarities = append(similarities, similarity)
-		}
-	}
+	totalSimilarity := 0.0
+	pairCount := 0
+
+	ea.forEachPair(len(embeddings), func(i, j int) {
+		totalSimilarity += ea.cosineSimilarity(embeddings[i], embeddings[j])
+		pairCount++
+	})
 
 	// Return average similarity as coherence measure
-	return ea.calculateMean(similarities)
+	return totalSimilarity / float64(pairCount)
 }
 
 // cosineSimilarity calculates cosine similarity between two vectors
@@ -689,13 +837,10 @@ func (ea *EmbeddingAnalyzer) calculateSemanticDensity(embeddings [][]float64) fl
 	totalDistance := 0.0
 	pairCount := 0
 
-	for i := 0; i < len(embeddings); i++ {
-		for j := i + 1; j < len(embeddings); j++ {
-			distance := ea.euclideanDistance(embeddings[i], embeddings[j])
-			totalDistance += distance
-			pairCount++
-		}
-	}
+	ea.forEachPair(len(embeddings), func(i, j int) {
+		totalDistance += ea.euclideanDistance(embeddings[i], embeddings[j])
+		pairCount++
+	})
 
 	avgDistance := totalDistance / float64(pairCount)
 	
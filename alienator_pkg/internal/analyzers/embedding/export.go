@@ -0,0 +1,43 @@
+package embedding
+
+import (
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// SentenceEmbedding is the embedding of one sentence, for clustering or
+// visualizing sentences outside the analyzer
+type SentenceEmbedding struct {
+	Sentence int       `json:"sentence"` // index among the text's sentences
+	Text     string    `json:"text"`
+	Cluster  int       `json:"cluster"`
+	Vector   []float64 `json:"vector"`
+}
+
+// SentenceEmbeddings pairs the vectors of a result produced with the
+// "include_embeddings" option with the sentences they embed. sentences must
+// be the tokenizer's sentences of the analyzed text. It returns nil when the
+// result carries no embeddings.
+func SentenceEmbeddings(result *models.AnalysisResult, sentences []string) []SentenceEmbedding {
+	if result == nil {
+		return nil
+	}
+	vectors, _ := result.Metadata["embeddings"].([][]float64)
+	clusters, _ := result.Metadata["cluster_assignments"].([]int)
+	indices, _ := result.Metadata["embedding_sentences"].([]int)
+	if len(vectors) == 0 || len(clusters) != len(vectors) || len(indices) != len(vectors) {
+		return nil
+	}
+
+	embedded := make([]SentenceEmbedding, len(vectors))
+	for i, vector := range vectors {
+		embedded[i] = SentenceEmbedding{
+			Sentence: indices[i],
+			Cluster:  clusters[i],
+			Vector:   vector,
+		}
+		if indices[i] < len(sentences) {
+			embedded[i].Text = sentences[indices[i]]
+		}
+	}
+	return embedded
+}
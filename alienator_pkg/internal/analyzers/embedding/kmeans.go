@@ -0,0 +1,148 @@
+package embedding
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// K-means variants selectable with the "kmeans_algorithm" option
+const (
+	// KMeansFull runs Lloyd's algorithm over every embedding each iteration
+	KMeansFull = "full"
+	// KMeansMiniBatch updates the centroids from a random batch of embeddings
+	// each iteration, trading a little cluster quality for much less work on
+	// long documents
+	KMeansMiniBatch = "mini_batch"
+)
+
+// parallelAssignMin is the fewest points worth splitting across goroutines
+// when assigning them to centroids
+const parallelAssignMin = 256
+
+// assignPoints assigns each of the indexed points to its nearest centroid,
+// splitting the work across up to workers goroutines, and reports whether any
+// assignment changed. Every point is written by exactly one goroutine, so the
+// result does not depend on scheduling.
+func (ea *EmbeddingAnalyzer) assignPoints(embeddings [][]float64, indices []int, centroids [][]float64, assignments []int, workers int) bool {
+	if workers < 2 || len(indices) < parallelAssignMin {
+		return ea.assignRange(embeddings, indices, centroids, assignments)
+	}
+
+	if workers > len(indices) {
+		workers = len(indices)
+	}
+	chunk := (len(indices) + workers - 1) / workers
+	changed := make([]bool, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * chunk
+		if start >= len(indices) {
+			break
+		}
+		end := start + chunk
+		if end > len(indices) {
+			end = len(indices)
+		}
+
+		wg.Add(1)
+		go func(w int, part []int) {
+			defer wg.Done()
+			changed[w] = ea.assignRange(embeddings, part, centroids, assignments)
+		}(w, indices[start:end])
+	}
+	wg.Wait()
+
+	for _, c := range changed {
+		if c {
+			return true
+		}
+	}
+	return false
+}
+
+func (ea *EmbeddingAnalyzer) assignRange(embeddings [][]float64, indices []int, centroids [][]float64, assignments []int) bool {
+	changed := false
+	for _, i := range indices {
+		if nearest := ea.findNearestCentroid(embeddings[i], centroids); nearest != assignments[i] {
+			assignments[i] = nearest
+			changed = true
+		}
+	}
+	return changed
+}
+
+// performMiniBatchKMeans clusters the embeddings with mini-batch k-means
+// (Sculley, 2010). Each iteration samples a batch with a generator seeded by
+// the configured seed and moves each centroid towards its batch points with a
+// per-centroid learning rate of 1/count, so results are reproducible.
+func (ea *EmbeddingAnalyzer) performMiniBatchKMeans(embeddings [][]float64, k int) ([][]float64, []int) {
+	n := len(embeddings)
+	centroids := ea.initializeCentroids(embeddings, k)
+	counts := make([]int, k)
+	assignments := make([]int, n)
+	rng := rand.New(rand.NewSource(ea.kmeansSeed))
+
+	batchSize := ea.kmeansBatchSize
+	if batchSize > n {
+		batchSize = n
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+
+	for iteration := 0; iteration < ea.maxIterations; iteration++ {
+		// Partial Fisher-Yates shuffle: the first batchSize entries are the batch
+		for i := 0; i < batchSize; i++ {
+			j := i + rng.Intn(n-i)
+			order[i], order[j] = order[j], order[i]
+		}
+		batch := order[:batchSize]
+		ea.assignPoints(embeddings, batch, centroids, assignments, ea.kmeansWorkers)
+
+		shift := 0.0
+		for _, i := range batch {
+			cluster := assignments[i]
+			counts[cluster]++
+			rate := 1 / float64(counts[cluster])
+
+			before := append([]float64(nil), centroids[cluster]...)
+			for d, value := range embeddings[i] {
+				centroids[cluster][d] += rate * (value - centroids[cluster][d])
+			}
+			if moved := ea.euclideanDistance(before, centroids[cluster]); moved > shift {
+				shift = moved
+			}
+		}
+
+		if shift <= ea.convergenceThreshold {
+			break
+		}
+	}
+
+	// Final assignment of every embedding, order being a permutation of all
+	ea.assignPoints(embeddings, order, centroids, assignments, ea.kmeansWorkers)
+	return centroids, assignments
+}
+
+// clusterInertia returns the sum of squared distances from each embedding to
+// its cluster centroid, the quantity k-means minimizes
+func (ea *EmbeddingAnalyzer) clusterInertia(embeddings [][]float64, clusters []ClusterResult, assignments []int) float64 {
+	inertia := 0.0
+	for i, embedding := range embeddings {
+		distance := ea.euclideanDistance(embedding, clusters[assignments[i]].Centroid)
+		inertia += distance * distance
+	}
+	return inertia
+}
+
+// validateKMeansAlgorithm checks a "kmeans_algorithm" option
+func validateKMeansAlgorithm(algorithm string) error {
+	switch algorithm {
+	case KMeansFull, KMeansMiniBatch:
+		return nil
+	default:
+		return fmt.Errorf("unsupported kmeans_algorithm %q (expected %s or %s)", algorithm, KMeansFull, KMeansMiniBatch)
+	}
+}
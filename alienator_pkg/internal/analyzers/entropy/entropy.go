@@ -7,6 +7,7 @@ import (
 	"unicode"
 
 	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
 )
 
 // EntropyAnalyzer analyzes text entropy patterns
@@ -57,8 +58,24 @@ func (ea *EntropyAnalyzer) Name() string {
 	return ea.name
 }
 
+// Description returns what the analyzer measures
+func (ea *EntropyAnalyzer) Description() string {
+	return "Compares character entropy and distribution with English text"
+}
+
+// Parameters returns nil: the analyzer has no options
+func (ea *EntropyAnalyzer) Parameters() []models.AnalyzerParameter {
+	return nil
+}
+
 // Analyze performs entropy analysis on the text
 func (ea *EntropyAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return ea.AnalyzeTokens(ctx, tokenizer.New(tokenizer.Default, text))
+}
+
+// AnalyzeTokens performs entropy analysis on already tokenized text
+func (ea *EntropyAnalyzer) AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	text := tokens.Text()
 	if len(text) == 0 {
 		return &models.AnalysisResult{
 			Score:      0.0,
@@ -69,7 +86,7 @@ func (ea *EntropyAnalyzer) Analyze(ctx context.Context, text string) (*models.An
 
 	// Calculate Shannon entropy
 	charEntropy := ea.calculateShannonEntropy(text)
-	wordEntropy := ea.calculateWordEntropy(text)
+	wordEntropy := ea.calculateWordEntropy(tokens.LowerWords())
 	lineEntropy := ea.calculateLineEntropy(text)
 
 	// Chi-square test for character distribution
@@ -141,8 +158,7 @@ func (ea *EntropyAnalyzer) calculateShannonEntropy(text string) float64 {
 }
 
 // calculateWordEntropy calculates Shannon entropy for words
-func (ea *EntropyAnalyzer) calculateWordEntropy(text string) float64 {
-	words := strings.Fields(strings.ToLower(text))
+func (ea *EntropyAnalyzer) calculateWordEntropy(words []string) float64 {
 	if len(words) == 0 {
 		return 0
 	}
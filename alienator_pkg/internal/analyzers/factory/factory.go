@@ -1,9 +1,11 @@
-package analyzers
+// Package factory creates the time-series analyzers by type. It lives apart
+// from package analyzers, whose types every analyzer it creates depends on.
+package factory
 
 import (
 	"fmt"
 
-	"github.com/ruvnet/alienator/internal/analyzers/alerts"
+	"github.com/ruvnet/alienator/internal/analyzers"
 	"github.com/ruvnet/alienator/internal/analyzers/ml"
 	"github.com/ruvnet/alienator/internal/analyzers/pattern"
 	"github.com/ruvnet/alienator/internal/analyzers/statistical"
@@ -22,20 +24,20 @@ const (
 
 // Factory creates analyzers based on type and configuration
 type Factory struct {
-	alertManager *alerts.Manager
+	alertManager analyzers.AlertManager
 }
 
 // NewFactory creates a new analyzer factory
-func NewFactory(alertManager *alerts.Manager) *Factory {
+func NewFactory(alertManager analyzers.AlertManager) *Factory {
 	return &Factory{
 		alertManager: alertManager,
 	}
 }
 
 // CreateAnalyzer creates an analyzer of the specified type
-func (f *Factory) CreateAnalyzer(analyzerType AnalyzerType, config *Configuration) (Analyzer, error) {
+func (f *Factory) CreateAnalyzer(analyzerType AnalyzerType, config *analyzers.Configuration) (analyzers.Analyzer, error) {
 	if config == nil {
-		config = DefaultConfiguration()
+		config = analyzers.DefaultConfiguration()
 	}
 
 	switch analyzerType {
@@ -53,18 +55,18 @@ func (f *Factory) CreateAnalyzer(analyzerType AnalyzerType, config *Configuratio
 }
 
 // CreateCompositeAnalyzer creates a composite analyzer that combines multiple analyzers
-func (f *Factory) CreateCompositeAnalyzer(types []AnalyzerType, config *Configuration) (*CompositeAnalyzer, error) {
-	analyzers := make([]Analyzer, 0, len(types))
+func (f *Factory) CreateCompositeAnalyzer(types []AnalyzerType, config *analyzers.Configuration) (*analyzers.CompositeAnalyzer, error) {
+	created := make([]analyzers.Analyzer, 0, len(types))
 
 	for _, analyzerType := range types {
 		analyzer, err := f.CreateAnalyzer(analyzerType, config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create %s analyzer: %w", analyzerType, err)
 		}
-		analyzers = append(analyzers, analyzer)
+		created = append(created, analyzer)
 	}
 
-	return NewCompositeAnalyzer(analyzers, f.alertManager), nil
+	return analyzers.NewCompositeAnalyzer(created, f.alertManager), nil
 }
 
 // GetSupportedTypes returns all supported analyzer types
@@ -78,7 +80,7 @@ func (f *Factory) GetSupportedTypes() []AnalyzerType {
 }
 
 // ValidateConfiguration validates analyzer configuration
-func (f *Factory) ValidateConfiguration(analyzerType AnalyzerType, config *Configuration) error {
+func (f *Factory) ValidateConfiguration(analyzerType AnalyzerType, config *analyzers.Configuration) error {
 	if config == nil {
 		return fmt.Errorf("configuration cannot be nil")
 	}
@@ -0,0 +1,445 @@
+package injection
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// Sources of a signature match
+const (
+	SourceText   = "text"
+	SourceBase64 = "base64"
+)
+
+// SignatureMatch records a signature found in the text. For matches inside
+// decoded base64, Offset and Length locate the encoded run in the original
+// text.
+type SignatureMatch struct {
+	ID       string  `json:"id"`
+	Category string  `json:"category"`
+	Source   string  `json:"source"`
+	Offset   int     `json:"offset"`
+	Length   int     `json:"length"`
+	Weight   float64 `json:"weight"`
+}
+
+// DecodedSegment describes a base64 run that decoded to readable text. The
+// preview has every signature match replaced by a redaction marker, so that
+// the payload is not echoed into logs or downstream prompts.
+type DecodedSegment struct {
+	Offset        int      `json:"offset"`
+	EncodedLength int      `json:"encoded_length"`
+	DecodedLength int      `json:"decoded_length"`
+	Depth         int      `json:"depth"`
+	Signatures    []string `json:"signatures,omitempty"`
+	Preview       string   `json:"preview"`
+}
+
+// InjectionAnalyzer flags prompt-injection and jailbreak phrasing: text trying
+// to override a model's instructions, switch its persona, extract its system
+// prompt or smuggle in chat template tokens. Base64 runs are decoded and
+// rescanned, since encoding instructions is a common way past keyword filters.
+type InjectionAnalyzer struct {
+	name       string
+	signatures []compiledSignature
+	// Decoding parameters
+	decodeBase64       bool           // rescan base64 runs that decode to text
+	maxDecodeDepth     int            // nested encodings followed
+	maxDecodedSegments int            // decoded runs per analysis; later runs are ignored
+	encodedPattern     *regexp.Regexp // candidate base64 runs of min_encoded_length or more
+	// Reporting parameters
+	detectionThreshold float64 // score at which an injection is reported
+	previewLength      int     // runes of decoded content shown in metadata
+	maxInputLength     int     // bytes of text scanned; the rest is ignored
+	fullConfidenceAt   int     // words needed for full confidence in a clean result
+	mu                 sync.RWMutex
+}
+
+// NewInjectionAnalyzer creates an injection analyzer using the default ruleset
+func NewInjectionAnalyzer() *InjectionAnalyzer {
+	signatures, err := DefaultRuleset().compile()
+	if err != nil {
+		panic(fmt.Sprintf("invalid default injection ruleset: %v", err))
+	}
+	return &InjectionAnalyzer{
+		name:               "injection",
+		signatures:         signatures,
+		decodeBase64:       true,
+		maxDecodeDepth:     2,
+		maxDecodedSegments: 16,
+		encodedPattern:     encodedRunPattern(16),
+		detectionThreshold: 0.5,
+		previewLength:      80,
+		maxInputLength:     256 * 1024,
+		fullConfidenceAt:   50,
+	}
+}
+
+// Name returns the analyzer name
+func (ia *InjectionAnalyzer) Name() string {
+	return ia.name
+}
+
+// Description returns what the analyzer measures
+func (ia *InjectionAnalyzer) Description() string {
+	return "Matches prompt-injection signatures, including inside base64-encoded segments"
+}
+
+// Parameters returns the options accepted by Configure
+func (ia *InjectionAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "ruleset_file", Type: models.ParameterString, Description: "JSON signature ruleset file replacing the built-in one", Default: nil},
+		{Name: "decode_base64", Type: models.ParameterBoolean, Description: "Also scan base64-encoded segments after decoding them", Default: true},
+		{Name: "max_decode_depth", Type: models.ParameterInteger, Description: "Times nested encodings are decoded", Default: 2},
+		{Name: "min_encoded_length", Type: models.ParameterInteger, Description: "Shortest run treated as an encoded segment; at least 8", Default: 16},
+		{Name: "max_decoded_segments", Type: models.ParameterInteger, Description: "Most encoded segments decoded per input", Default: 16},
+		{Name: "detection_threshold", Type: models.ParameterNumber, Description: "Score at or above which injection is reported, in (0, 1]", Default: 0.5},
+		{Name: "preview_length", Type: models.ParameterInteger, Description: "Characters of each match quoted in the result", Default: 80},
+		{Name: "max_input_length", Type: models.ParameterInteger, Description: "Bytes of text scanned; the rest is ignored", Default: 256 * 1024},
+	}
+}
+
+// SetRuleset replaces the signatures matched by the analyzer
+func (ia *InjectionAnalyzer) SetRuleset(ruleset Ruleset) error {
+	signatures, err := ruleset.compile()
+	if err != nil {
+		return fmt.Errorf("invalid injection ruleset: %w", err)
+	}
+
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+	ia.signatures = signatures
+	return nil
+}
+
+// Configure updates the analyzer configuration. "ruleset_file" replaces the
+// default signatures with those of a ruleset file.
+func (ia *InjectionAnalyzer) Configure(config map[string]interface{}) error {
+	if path, ok := config["ruleset_file"].(string); ok && path != "" {
+		ruleset, err := LoadRuleset(path)
+		if err != nil {
+			return err
+		}
+		if err := ia.SetRuleset(ruleset); err != nil {
+			return err
+		}
+	}
+
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	if decode, ok := config["decode_base64"].(bool); ok {
+		ia.decodeBase64 = decode
+	}
+	if depth, ok := config["max_decode_depth"].(int); ok {
+		if depth < 1 {
+			return fmt.Errorf("max_decode_depth must be positive, got %d", depth)
+		}
+		ia.maxDecodeDepth = depth
+	}
+	if length, ok := config["min_encoded_length"].(int); ok {
+		if length < 8 {
+			return fmt.Errorf("min_encoded_length must be at least 8, got %d", length)
+		}
+		ia.encodedPattern = encodedRunPattern(length)
+	}
+	if segments, ok := config["max_decoded_segments"].(int); ok {
+		if segments < 1 {
+			return fmt.Errorf("max_decoded_segments must be positive, got %d", segments)
+		}
+		ia.maxDecodedSegments = segments
+	}
+	if threshold, ok := config["detection_threshold"].(float64); ok {
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("detection_threshold must be in (0, 1], got %f", threshold)
+		}
+		ia.detectionThreshold = threshold
+	}
+	if length, ok := config["preview_length"].(int); ok && length >= 0 {
+		ia.previewLength = length
+	}
+	if length, ok := config["max_input_length"].(int); ok && length > 0 {
+		ia.maxInputLength = length
+	}
+
+	return nil
+}
+
+// Analyze scans the text for injection signatures
+func (ia *InjectionAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return ia.AnalyzeTokens(ctx, tokenizer.New(tokenizer.Default, text))
+}
+
+// AnalyzeTokens scans already tokenized text for injection signatures
+func (ia *InjectionAnalyzer) AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	ia.mu.RLock()
+	defer ia.mu.RUnlock()
+
+	text := tokens.Text()
+	metadata := map[string]interface{}{
+		"injection_detected": false,
+		"signature_count":    0,
+	}
+	if strings.TrimSpace(text) == "" {
+		return &models.AnalysisResult{
+			Score:      0.0,
+			Confidence: 0.0,
+			Metadata:   metadata,
+		}, nil
+	}
+
+	truncated := false
+	if len(text) > ia.maxInputLength {
+		text, truncated = truncate(text, ia.maxInputLength), true
+	}
+
+	scan := &scanState{matched: make(map[string]bool)}
+	ia.scan(text, 0, len(text), 0, scan)
+
+	weight := 0.0
+	categories := make(map[string]bool)
+	for _, match := range scan.matches {
+		weight += match.Weight
+		categories[match.Category] = true
+	}
+	score := math.Min(1.0, weight)
+	detected := score >= ia.detectionThreshold
+
+	confidence := math.Min(1.0, float64(len(tokens.Words()))/float64(ia.fullConfidenceAt))
+	if detected {
+		confidence = 1.0
+	}
+
+	metadata["injection_detected"] = detected
+	metadata["signature_count"] = len(scan.matches)
+	metadata["injection_weight"] = weight
+	metadata["matched_signatures"] = scan.matches
+	metadata["categories"] = sortedKeys(categories)
+	metadata["decoded_segments"] = len(scan.segments)
+	metadata["decoded_content"] = scan.segments
+	if truncated {
+		metadata["input_truncated"] = true
+	}
+
+	return &models.AnalysisResult{
+		Score:      score,
+		Confidence: confidence,
+		Metadata:   metadata,
+	}, nil
+}
+
+// Spans locates the signatures matched in a result of this analyzer. Matches
+// inside decoded base64 cover the whole encoded run.
+func (ia *InjectionAnalyzer) Spans(result *models.AnalysisResult) []models.TextSpan {
+	matches, _ := result.Metadata["matched_signatures"].([]SignatureMatch)
+	spans := make([]models.TextSpan, 0, len(matches))
+	for _, match := range matches {
+		spans = append(spans, models.TextSpan{
+			Analyzer: ia.name,
+			Rule:     match.ID,
+			Category: match.Category,
+			Offset:   match.Offset,
+			Length:   match.Length,
+		})
+	}
+	return spans
+}
+
+// scanState collects the matches of one analysis. Each signature is counted
+// once, at its first occurrence.
+type scanState struct {
+	matched  map[string]bool
+	matches  []SignatureMatch
+	segments []DecodedSegment
+}
+
+// scan matches every signature in text and then, within the decode depth,
+// rescans each base64 run that decodes to readable text. At depth 0 text is
+// the input itself; deeper, offset and length locate the outermost encoded
+// run in the input.
+func (ia *InjectionAnalyzer) scan(text string, offset, length, depth int, state *scanState) []string {
+	found := make([]string, 0)
+	for _, signature := range ia.signatures {
+		loc := signature.firstMatch(text)
+		if loc == nil {
+			continue
+		}
+		found = append(found, signature.ID)
+		if state.matched[signature.ID] {
+			continue
+		}
+		state.matched[signature.ID] = true
+
+		match := SignatureMatch{
+			ID:       signature.ID,
+			Category: signature.Category,
+			Source:   SourceText,
+			Offset:   offset + loc[0],
+			Length:   loc[1] - loc[0],
+			Weight:   signature.Weight,
+		}
+		if depth > 0 {
+			match.Source = SourceBase64
+			match.Offset, match.Length = offset, length
+		}
+		state.matches = append(state.matches, match)
+	}
+
+	if !ia.decodeBase64 || depth >= ia.maxDecodeDepth {
+		return found
+	}
+	for _, loc := range ia.encodedPattern.FindAllStringIndex(text, -1) {
+		if len(state.segments) >= ia.maxDecodedSegments {
+			break
+		}
+		decoded, ok := decodeBase64(text[loc[0]:loc[1]])
+		if !ok {
+			continue
+		}
+
+		segmentOffset, segmentLength := offset+loc[0], loc[1]-loc[0]
+		if depth > 0 {
+			segmentOffset, segmentLength = offset, length
+		}
+		index := len(state.segments)
+		state.segments = append(state.segments, DecodedSegment{
+			Offset:        segmentOffset,
+			EncodedLength: loc[1] - loc[0],
+			DecodedLength: len(decoded),
+			Depth:         depth + 1,
+		})
+		signatures, redacted := ia.scanDecoded(decoded, segmentOffset, segmentLength, depth+1, state)
+		state.segments[index].Signatures = signatures
+		state.segments[index].Preview = redacted
+	}
+	return found
+}
+
+// scanDecoded scans decoded content and returns the signatures found in it
+// with its redacted preview
+func (ia *InjectionAnalyzer) scanDecoded(decoded string, offset, length, depth int, state *scanState) ([]string, string) {
+	signatures := ia.scan(decoded, offset, length, depth, state)
+
+	var spans [][2]int
+	var labels []string
+	for _, signature := range ia.signatures {
+		for _, re := range signature.patterns {
+			for _, loc := range re.FindAllStringIndex(decoded, -1) {
+				spans = append(spans, [2]int{loc[0], loc[1]})
+				labels = append(labels, signature.ID)
+			}
+		}
+	}
+	return signatures, preview(redact(decoded, spans, labels), ia.previewLength)
+}
+
+// redact replaces each span of text with a marker naming its signature.
+// Spans overlapping an earlier one are merged into it.
+func redact(text string, spans [][2]int, labels []string) string {
+	order := make([]int, len(spans))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return spans[order[a]][0] < spans[order[b]][0] })
+
+	var sb strings.Builder
+	position := 0
+	for _, i := range order {
+		start, end := spans[i][0], spans[i][1]
+		if end <= position {
+			continue
+		}
+		if start < position {
+			start = position
+		} else {
+			sb.WriteString(text[position:start])
+			sb.WriteString("[redacted:" + labels[i] + "]")
+		}
+		position = end
+	}
+	sb.WriteString(text[position:])
+	return sb.String()
+}
+
+// preview cuts text to at most length runes, marking the cut
+func preview(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:length]) + "…"
+}
+
+// encodedRunPattern matches runs of at least minLength standard or URL-safe
+// base64 characters with optional padding
+func encodedRunPattern(minLength int) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`[A-Za-z0-9+/_-]{%d,}={0,2}`, minLength))
+}
+
+// base64Encodings are tried in turn when decoding a run
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// decodeBase64 decodes a base64 run and reports whether it held readable
+// text. Long words and identifiers also look like base64, but decode to
+// binary noise that fails the readability check.
+func decodeBase64(run string) (string, bool) {
+	for _, encoding := range base64Encodings {
+		decoded, err := encoding.DecodeString(run)
+		if err != nil || len(decoded) == 0 || !utf8.Valid(decoded) {
+			continue
+		}
+		if readable(string(decoded)) {
+			return string(decoded), true
+		}
+	}
+	return "", false
+}
+
+// readable reports whether text is mostly printable and contains letters
+func readable(text string) bool {
+	printable, letters, total := 0, 0, 0
+	for _, r := range text {
+		total++
+		if unicode.IsPrint(r) || unicode.IsSpace(r) {
+			printable++
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return float64(printable) >= 0.95*float64(total) && float64(letters) >= 0.5*float64(total)
+}
+
+// truncate cuts text to at most maxBytes bytes without splitting a character
+func truncate(text string, maxBytes int) string {
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
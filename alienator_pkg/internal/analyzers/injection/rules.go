@@ -0,0 +1,212 @@
+package injection
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RulesetVersion is the ruleset file format read by LoadRuleset
+const RulesetVersion = 1
+
+// Signature categories used by the default ruleset
+const (
+	CategoryInstructionOverride = "instruction_override"
+	CategoryRoleHijack          = "role_hijack"
+	CategoryPromptLeak          = "prompt_leak"
+	CategorySafetyBypass        = "safety_bypass"
+	CategoryDelimiter           = "delimiter_injection"
+)
+
+// Signature is one injection or jailbreak pattern. A signature matches when
+// its regular expression or any of its keywords occurs in the text. Keywords
+// match whole words, ignoring case and the amount of whitespace between them.
+type Signature struct {
+	ID          string   `json:"id"`
+	Category    string   `json:"category"`
+	Description string   `json:"description,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+	Weight      float64  `json:"weight"` // contribution to the score, in (0, 1]
+}
+
+// Ruleset is a versioned list of signatures
+type Ruleset struct {
+	Version    int         `json:"version"`
+	Signatures []Signature `json:"signatures"`
+}
+
+// DefaultRuleset returns the built-in signatures covering common instruction
+// overrides, persona jailbreaks, system prompt extraction, safety bypass
+// requests and chat template delimiters
+func DefaultRuleset() Ruleset {
+	return Ruleset{
+		Version: RulesetVersion,
+		Signatures: []Signature{
+			{
+				ID:          "ignore-previous-instructions",
+				Category:    CategoryInstructionOverride,
+				Description: "asks the model to discard its earlier instructions",
+				Pattern:     `(?i)\b(ignore|disregard|forget|override|bypass)\s+(all\s+|any\s+|the\s+|your\s+)*(previous|prior|above|earlier|preceding|original|system)\s+(instructions|prompts?|rules|directions|guidelines|context)\b`,
+				Weight:      1.0,
+			},
+			{
+				ID:          "new-instructions",
+				Category:    CategoryInstructionOverride,
+				Description: "announces replacement instructions",
+				Keywords:    []string{"new instructions:", "your new task is", "from now on you will", "from now on, you will"},
+				Weight:      0.6,
+			},
+			{
+				ID:          "dan-persona",
+				Category:    CategoryRoleHijack,
+				Description: "invokes the DAN jailbreak persona",
+				Pattern:     `\bDAN\b|(?i)\bdo\s+anything\s+now\b`,
+				Weight:      0.9,
+			},
+			{
+				ID:          "persona-switch",
+				Category:    CategoryRoleHijack,
+				Description: "reassigns the model's identity or mode",
+				Pattern:     `(?i)\b(you\s+are\s+now|pretend\s+(to\s+be|you\s+are)|act\s+as\s+if\s+you\s+(are|have)\s+no)\b|(?i)\b(developer|god|jailbreak|unrestricted)\s+mode\b`,
+				Weight:      0.6,
+			},
+			{
+				ID:          "system-prompt-leak",
+				Category:    CategoryPromptLeak,
+				Description: "asks for the hidden system prompt",
+				Pattern:     `(?i)\b(reveal|print|repeat|show|output|leak)\s+(me\s+)?(your|the)\s+(system\s+prompt|initial\s+instructions|hidden\s+instructions|instructions\s+above)\b`,
+				Weight:      0.8,
+			},
+			{
+				ID:          "no-restrictions",
+				Category:    CategorySafetyBypass,
+				Description: "asks the model to drop its restrictions",
+				Keywords: []string{
+					"without any restrictions", "no ethical guidelines", "ignore your guidelines",
+					"ignore all safety", "no content policy", "not bound by any rules",
+				},
+				Weight: 0.7,
+			},
+			{
+				ID:          "chat-delimiter",
+				Category:    CategoryDelimiter,
+				Description: "embeds chat template control tokens",
+				Pattern:     `<\|(im_start|im_end|system|endoftext)\|>|\[/?INST\]|<<SYS>>|(?im)^\s*#{2,}\s*(system|instruction)s?\s*:`,
+				Weight:      0.8,
+			},
+		},
+	}
+}
+
+// LoadRuleset reads and compiles a ruleset file
+func LoadRuleset(path string) (Ruleset, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return Ruleset{}, fmt.Errorf("failed to read ruleset file: %w", err)
+	}
+	var ruleset Ruleset
+	if err := json.Unmarshal(encoded, &ruleset); err != nil {
+		return Ruleset{}, fmt.Errorf("failed to parse ruleset file %s: %w", path, err)
+	}
+	if _, err := ruleset.compile(); err != nil {
+		return Ruleset{}, fmt.Errorf("invalid ruleset file %s: %w", path, err)
+	}
+	return ruleset, nil
+}
+
+// Validate checks the version and that every signature compiles
+func (r Ruleset) Validate() error {
+	_, err := r.compile()
+	return err
+}
+
+// compiledSignature is a signature with its pattern and keywords compiled
+type compiledSignature struct {
+	Signature
+	patterns []*regexp.Regexp
+}
+
+func (r Ruleset) compile() ([]compiledSignature, error) {
+	if r.Version != RulesetVersion {
+		return nil, fmt.Errorf("unsupported ruleset version %d (expected %d)", r.Version, RulesetVersion)
+	}
+	if len(r.Signatures) == 0 {
+		return nil, fmt.Errorf("ruleset has no signatures")
+	}
+
+	seen := make(map[string]bool, len(r.Signatures))
+	compiled := make([]compiledSignature, 0, len(r.Signatures))
+	for _, signature := range r.Signatures {
+		if signature.ID == "" {
+			return nil, fmt.Errorf("signature without an id")
+		}
+		if seen[signature.ID] {
+			return nil, fmt.Errorf("duplicate signature id %q", signature.ID)
+		}
+		seen[signature.ID] = true
+		if signature.Weight <= 0 || signature.Weight > 1 {
+			return nil, fmt.Errorf("signature %q weight must be in (0, 1], got %g", signature.ID, signature.Weight)
+		}
+		if signature.Pattern == "" && len(signature.Keywords) == 0 {
+			return nil, fmt.Errorf("signature %q needs a pattern or keywords", signature.ID)
+		}
+
+		cs := compiledSignature{Signature: signature}
+		if signature.Pattern != "" {
+			re, err := regexp.Compile(signature.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("signature %q has an invalid pattern: %w", signature.ID, err)
+			}
+			cs.patterns = append(cs.patterns, re)
+		}
+		for _, keyword := range signature.Keywords {
+			re, err := keywordPattern(keyword)
+			if err != nil {
+				return nil, fmt.Errorf("signature %q has an invalid keyword: %w", signature.ID, err)
+			}
+			cs.patterns = append(cs.patterns, re)
+		}
+		compiled = append(compiled, cs)
+	}
+	return compiled, nil
+}
+
+// keywordPattern matches keyword case-insensitively, with any run of
+// whitespace between its words and word boundaries at alphanumeric ends
+func keywordPattern(keyword string) (*regexp.Regexp, error) {
+	keyword = strings.TrimSpace(keyword)
+	words := strings.Fields(keyword)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty keyword")
+	}
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	pattern := strings.Join(words, `\s+`)
+	if isWordByte(keyword[0]) {
+		pattern = `\b` + pattern
+	}
+	if isWordByte(keyword[len(keyword)-1]) {
+		pattern += `\b`
+	}
+	return regexp.Compile(`(?i)` + pattern)
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+// firstMatch returns the byte range of the earliest match of any of the
+// signature's patterns, or nil
+func (cs compiledSignature) firstMatch(text string) []int {
+	var first []int
+	for _, re := range cs.patterns {
+		if loc := re.FindStringIndex(text); loc != nil && (first == nil || loc[0] < first[0]) {
+			first = loc
+		}
+	}
+	return first
+}
@@ -0,0 +1,326 @@
+package linguistic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// paragraphSeparator matches the blank lines between paragraphs
+var paragraphSeparator = regexp.MustCompile(`\n\s*\n`)
+
+// CalibrationVersion is the calibration file format written by Calibrate
+const CalibrationVersion = 1
+
+// Names of the features scored against a human baseline, as reported in the
+// analysis metadata
+const (
+	FeatureAvgSentenceLength    = "avg_sentence_length"
+	FeatureAvgWordLength        = "avg_word_length"
+	FeaturePunctuationDensity   = "punctuation_density"
+	FeatureCapitalizationRatio  = "capitalization_ratio"
+	FeatureRepetitionScore      = "repetition_score"
+	FeatureVocabularyRichness   = "vocabulary_richness"
+	FeatureTransitionSmoothness = "transition_smoothness"
+	FeaturePerplexity           = "perplexity"
+	FeatureVowelRatio           = "vowel_ratio"
+	FeatureWordLengthVariance   = "word_length_variance"
+	FeatureFunctionWordRatio    = "function_word_ratio"
+	FeatureSentenceComplexity   = "sentence_complexity"
+)
+
+// Baseline is the range a feature normally spans in human writing. Scores
+// are normalized over [Low, High]; the distribution statistics are recorded
+// for reference when the baseline comes from a corpus.
+type Baseline struct {
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+	Mean   float64 `json:"mean,omitempty"`
+	StdDev float64 `json:"stddev,omitempty"`
+	Median float64 `json:"median,omitempty"`
+}
+
+// DefaultBaselines returns the hand-tuned ranges used without a calibration
+func DefaultBaselines() map[string]Baseline {
+	return map[string]Baseline{
+		FeatureAvgSentenceLength:    {Low: 10, High: 25},
+		FeatureAvgWordLength:        {Low: 3, High: 7},
+		FeaturePunctuationDensity:   {Low: 0.02, High: 0.15},
+		FeatureCapitalizationRatio:  {Low: 0.02, High: 0.12},
+		FeatureRepetitionScore:      {Low: 0.0, High: 0.1},
+		FeatureVocabularyRichness:   {Low: 0.3, High: 0.8},
+		FeatureTransitionSmoothness: {Low: 0.2, High: 0.8},
+		FeaturePerplexity:           {Low: 0.1, High: 1.0},
+		FeatureVowelRatio:           {Low: 0.35, High: 0.50},
+		FeatureWordLengthVariance:   {Low: 2.0, High: 10.0},
+		FeatureFunctionWordRatio:    {Low: 0.2, High: 0.6},
+		FeatureSentenceComplexity:   {Low: 0.5, High: 3.0},
+	}
+}
+
+// Calibration holds feature baselines measured on a known-human corpus
+type Calibration struct {
+	Version         int                 `json:"version"`
+	Source          string              `json:"source,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	Documents       int                 `json:"documents"`
+	LowerPercentile float64             `json:"lower_percentile"`
+	UpperPercentile float64             `json:"upper_percentile"`
+	Features        map[string]Baseline `json:"features"`
+}
+
+// Validate checks the calibration's version and baselines. Features it does
+// not list keep their default baseline.
+func (c *Calibration) Validate() error {
+	if c.Version != CalibrationVersion {
+		return fmt.Errorf("unsupported calibration version %d (expected %d)", c.Version, CalibrationVersion)
+	}
+	defaults := DefaultBaselines()
+	for name, baseline := range c.Features {
+		if _, ok := defaults[name]; !ok {
+			return fmt.Errorf("unknown calibration feature %q", name)
+		}
+		if err := baseline.validate(); err != nil {
+			return fmt.Errorf("calibration feature %q %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validate checks that the range is finite and not inverted
+func (b Baseline) validate() error {
+	if math.IsNaN(b.Low) || math.IsNaN(b.High) || math.IsInf(b.Low, 0) || math.IsInf(b.High, 0) {
+		return errors.New("has a non-finite range")
+	}
+	if b.Low > b.High {
+		return fmt.Errorf("has low %g above high %g", b.Low, b.High)
+	}
+	return nil
+}
+
+// LoadCalibration reads a calibration file written by Calibration.Save
+func LoadCalibration(path string) (*Calibration, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calibration file: %w", err)
+	}
+	var calibration Calibration
+	if err := json.Unmarshal(encoded, &calibration); err != nil {
+		return nil, fmt.Errorf("failed to parse calibration file %s: %w", path, err)
+	}
+	if err := calibration.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid calibration file %s: %w", path, err)
+	}
+	return &calibration, nil
+}
+
+// Save writes the calibration as indented JSON
+func (c *Calibration) Save(path string) error {
+	encoded, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode calibration: %w", err)
+	}
+	if err := os.WriteFile(path, append(encoded, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write calibration file: %w", err)
+	}
+	return nil
+}
+
+// CalibrationOptions controls how a corpus is measured
+type CalibrationOptions struct {
+	// MinWords is the size documents are built up to from paragraphs; the
+	// features of shorter texts are too noisy to calibrate with
+	MinWords int
+	// MinDocuments is the fewest documents a corpus must yield
+	MinDocuments int
+	// LowerPercentile and UpperPercentile, in (0, 1), bound the baseline range
+	LowerPercentile float64
+	UpperPercentile float64
+}
+
+// DefaultCalibrationOptions returns the options used by the calibrate command
+func DefaultCalibrationOptions() CalibrationOptions {
+	return CalibrationOptions{
+		MinWords:        80,
+		MinDocuments:    20,
+		LowerPercentile: 0.05,
+		UpperPercentile: 0.95,
+	}
+}
+
+// Validate checks the options
+func (o CalibrationOptions) Validate() error {
+	if o.MinWords < 1 {
+		return fmt.Errorf("min words must be positive, got %d", o.MinWords)
+	}
+	if o.MinDocuments < 2 {
+		return fmt.Errorf("min documents must be at least 2, got %d", o.MinDocuments)
+	}
+	if o.LowerPercentile <= 0 || o.UpperPercentile >= 1 || o.LowerPercentile >= o.UpperPercentile {
+		return fmt.Errorf("percentiles must satisfy 0 < lower < upper < 1, got %g and %g", o.LowerPercentile, o.UpperPercentile)
+	}
+	return nil
+}
+
+// SplitCorpus splits a corpus into documents of at least minWords words,
+// joining consecutive paragraphs. A short trailing remainder is merged into
+// the last document.
+func SplitCorpus(corpus string, minWords int) []string {
+	var documents []string
+	var current []string
+	words := 0
+	for _, paragraph := range paragraphSeparator.Split(corpus, -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		current = append(current, paragraph)
+		words += len(strings.Fields(paragraph))
+		if words >= minWords {
+			documents = append(documents, strings.Join(current, "\n\n"))
+			current, words = nil, 0
+		}
+	}
+	if len(current) > 0 {
+		remainder := strings.Join(current, "\n\n")
+		if len(documents) == 0 {
+			documents = append(documents, remainder)
+		} else {
+			documents[len(documents)-1] += "\n\n" + remainder
+		}
+	}
+	return documents
+}
+
+// Calibrate measures the baseline features over documents of known-human
+// text. Each feature's baseline spans the configured percentiles of its
+// values across the documents.
+func (la *LinguisticAnalyzer) Calibrate(documents []string, opts CalibrationOptions) (*Calibration, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if len(documents) < opts.MinDocuments {
+		return nil, fmt.Errorf("calibration needs at least %d documents of %d words, got %d", opts.MinDocuments, opts.MinWords, len(documents))
+	}
+
+	values := make(map[string][]float64)
+	for _, document := range documents {
+		for name, value := range la.measureFeatures(tokenizer.New(tokenizer.Default, document)) {
+			values[name] = append(values[name], value)
+		}
+	}
+
+	features := make(map[string]Baseline, len(values))
+	for name, samples := range values {
+		sort.Float64s(samples)
+		mean, stddev := meanStdDev(samples)
+		features[name] = Baseline{
+			Low:    percentile(samples, opts.LowerPercentile),
+			High:   percentile(samples, opts.UpperPercentile),
+			Mean:   mean,
+			StdDev: stddev,
+			Median: percentile(samples, 0.5),
+		}
+	}
+
+	return &Calibration{
+		Version:         CalibrationVersion,
+		CreatedAt:       time.Now().UTC(),
+		Documents:       len(documents),
+		LowerPercentile: opts.LowerPercentile,
+		UpperPercentile: opts.UpperPercentile,
+		Features:        features,
+	}, nil
+}
+
+// SetCalibration replaces the default baselines with the calibrated ones;
+// nil restores the defaults
+func (la *LinguisticAnalyzer) SetCalibration(calibration *Calibration) error {
+	baselines := DefaultBaselines()
+	if calibration != nil {
+		if err := calibration.Validate(); err != nil {
+			return err
+		}
+		for name, baseline := range calibration.Features {
+			baselines[name] = baseline
+		}
+	}
+
+	la.mu.Lock()
+	defer la.mu.Unlock()
+	la.baselines = baselines
+	return nil
+}
+
+// ValidateHealth checks that every feature has a usable baseline to be
+// scored against
+func (la *LinguisticAnalyzer) ValidateHealth(ctx context.Context) error {
+	la.mu.RLock()
+	defer la.mu.RUnlock()
+
+	for name := range DefaultBaselines() {
+		baseline, ok := la.baselines[name]
+		if !ok {
+			return fmt.Errorf("feature %q has no baseline", name)
+		}
+		if err := baseline.validate(); err != nil {
+			return fmt.Errorf("baseline of feature %q %w", name, err)
+		}
+	}
+	return nil
+}
+
+// measureFeatures returns the value of each baseline feature for the text
+func (la *LinguisticAnalyzer) measureFeatures(tokens *tokenizer.Tokens) map[string]float64 {
+	return map[string]float64{
+		FeatureAvgSentenceLength:    la.calculateAverageSentenceLength(tokens.SentenceWords()),
+		FeatureAvgWordLength:        la.calculateAverageWordLength(tokens.Words()),
+		FeaturePunctuationDensity:   la.calculatePunctuationDensity(tokens.Characters()),
+		FeatureCapitalizationRatio:  la.calculateCapitalizationRatio(tokens.Text()),
+		FeatureRepetitionScore:      la.calculateRepetitionScore(tokens.LowerWords()),
+		FeatureVocabularyRichness:   la.calculateVocabularyRichness(tokens.LowerWords()),
+		FeatureTransitionSmoothness: la.calculateTransitionSmoothness(tokens.SentenceWords()),
+		FeaturePerplexity:           la.calculatePerplexity(tokens.LowerWords()),
+		FeatureVowelRatio:           la.calculateVowelRatio(tokens.Text()),
+		FeatureWordLengthVariance:   la.calculateWordLengthVariance(tokens.Words()),
+		FeatureFunctionWordRatio:    la.calculateFunctionWordRatio(tokens.LowerWords()),
+		FeatureSentenceComplexity:   la.calculateSentenceComplexity(tokens.Sentences(), tokens.SentenceWords()),
+	}
+}
+
+// percentile interpolates the p-th quantile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	fraction := position - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*fraction
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		diff := value - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(values))
+	return mean, math.Sqrt(variance)
+}
@@ -0,0 +1,46 @@
+package linguistic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abadojack/whatlanggo"
+	"github.com/ruvnet/alienator/internal/language"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// autoLanguage marks that the analysis language is detected from the text
+const autoLanguage whatlanggo.Lang = -1
+
+// LanguageCandidate is a ranked language guess for the analyzed text
+type LanguageCandidate = language.Candidate
+
+// ParseLanguage resolves an ISO 639-1 ("es") or ISO 639-3 ("spa") code, or
+// "auto", to the language used for analysis
+func ParseLanguage(code string) (whatlanggo.Lang, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" || code == "auto" {
+		return autoLanguage, nil
+	}
+
+	if lang := whatlanggo.CodeToLang(code); lang != -1 {
+		return lang, nil
+	}
+	for lang := whatlanggo.Afr; lang <= whatlanggo.Zul; lang++ {
+		if lang.Iso6391() == code {
+			return lang, nil
+		}
+	}
+
+	return autoLanguage, fmt.Errorf("unsupported language code: %s", code)
+}
+
+// detectLanguage returns the language detected in the text, taking the
+// detection ctx carries when the detector shares one
+func detectLanguage(ctx context.Context, tokens *tokenizer.Tokens) *language.Detection {
+	if detection, ok := language.FromContext(ctx); ok {
+		return detection
+	}
+	return tokens.Language()
+}
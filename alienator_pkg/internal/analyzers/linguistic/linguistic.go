@@ -2,13 +2,22 @@ package linguistic
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"regexp"
 	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/abadojack/whatlanggo"
 	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// Word cleaning patterns, compiled once rather than for every word
+var (
+	nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]`)
+	nonLetter       = regexp.MustCompile(`[^a-zA-Z]`)
 )
 
 // LinguisticAnalyzer analyzes linguistic patterns in text
@@ -16,14 +25,14 @@ type LinguisticAnalyzer struct {
 	name string
 	// Language detection
 	languageDetector *whatlanggo.Info
+	// language forces the analysis language; autoLanguage detects it
+	language whatlanggo.Lang
+	mu       sync.RWMutex
 	// Grammar patterns
 	commonWords     map[string]float64
 	functionWords   map[string]bool
-	// Structural analysis thresholds
-	vowelRatioMin   float64
-	vowelRatioMax   float64
-	wordLengthMin   float64
-	wordLengthMax   float64
+	// Human baselines the features are normalized against
+	baselines       map[string]Baseline
 	// Perplexity calculation
 	bigramFreqs     map[string]float64
 	trigramFreqs    map[string]float64
@@ -72,12 +81,10 @@ func NewLinguisticAnalyzer() *LinguisticAnalyzer {
 	
 	return &LinguisticAnalyzer{
 		name:          "linguistic",
+		language:      autoLanguage,
 		commonWords:   commonWords,
 		functionWords: functionWords,
-		vowelRatioMin: 0.35,
-		vowelRatioMax: 0.50,
-		wordLengthMin: 4.0,
-		wordLengthMax: 6.0,
+		baselines:     DefaultBaselines(),
 		aiPatterns:    aiPatterns,
 		botPatterns:   botPatterns,
 		bigramFreqs:   make(map[string]float64),
@@ -85,13 +92,63 @@ func NewLinguisticAnalyzer() *LinguisticAnalyzer {
 	}
 }
 
+// unreliableLanguagePenalty scales confidence when language detection is unreliable
+const unreliableLanguagePenalty = 0.8
+
 // Name returns the analyzer name
 func (la *LinguisticAnalyzer) Name() string {
 	return la.name
 }
 
+// Description returns what the analyzer measures
+func (la *LinguisticAnalyzer) Description() string {
+	return "Measures word choice, sentence structure and phrasing typical of generated or bot-written text"
+}
+
+// Parameters returns the options accepted by Configure
+func (la *LinguisticAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "language", Type: models.ParameterString, Description: "ISO 639-1 or 639-3 code of the analysis language, or auto to detect it", Default: "auto"},
+		{Name: "calibration_file", Type: models.ParameterString, Description: "JSON calibration file of feature baselines replacing the hand-tuned ones", Default: nil},
+	}
+}
+
+// Configure updates the analyzer configuration. The "language" key takes an
+// ISO 639-1 or 639-3 code that overrides detection, or "auto";
+// "calibration_file" loads human baselines written by the calibrate command.
+func (la *LinguisticAnalyzer) Configure(config map[string]interface{}) error {
+	if path, ok := config["calibration_file"].(string); ok && path != "" {
+		calibration, err := LoadCalibration(path)
+		if err != nil {
+			return fmt.Errorf("invalid linguistic configuration: %w", err)
+		}
+		if err := la.SetCalibration(calibration); err != nil {
+			return fmt.Errorf("invalid linguistic configuration: %w", err)
+		}
+	}
+
+	la.mu.Lock()
+	defer la.mu.Unlock()
+
+	if code, ok := config["language"].(string); ok {
+		language, err := ParseLanguage(code)
+		if err != nil {
+			return fmt.Errorf("invalid linguistic configuration: %w", err)
+		}
+		la.language = language
+	}
+
+	return nil
+}
+
 // Analyze performs linguistic analysis on the text
 func (la *LinguisticAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return la.AnalyzeTokens(ctx, tokenizer.New(tokenizer.Default, text))
+}
+
+// AnalyzeTokens performs linguistic analysis on already tokenized text
+func (la *LinguisticAnalyzer) AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	text := tokens.Text()
 	if len(text) == 0 {
 		return &models.AnalysisResult{
 			Score:      0.0,
@@ -100,41 +157,56 @@ func (la *LinguisticAnalyzer) Analyze(ctx context.Context, text string) (*models
 		}, nil
 	}
 
-	// Language detection
-	languageInfo := whatlanggo.Detect(text)
+	la.mu.RLock()
+	forcedLanguage := la.language
+	baselines := la.baselines
+	la.mu.RUnlock()
+
+	// Language detection, unless the caller forced the language
 	language := "English" // Default to English, could enhance with proper language names
-	if languageInfo.IsReliable() {
-		language = string(languageInfo.Lang)
+	langConfidence := 1.0
+	langReliable := true
+	var candidates []LanguageCandidate
+	if forcedLanguage != autoLanguage {
+		language = forcedLanguage.String()
+	} else {
+		detection := detectLanguage(ctx, tokens)
+		candidates = detection.Candidates
+		langReliable = detection.Reliable
+		if langReliable {
+			language = detection.Name
+		}
+		langConfidence = detection.Confidence
 	}
-	langConfidence := languageInfo.Confidence
 	
 	// Calculate perplexity
-	perplexity := la.calculatePerplexity(text)
+	perplexity := la.calculatePerplexity(tokens.LowerWords())
 	
 	// Grammar and structure checking
-	grammarScore := la.checkGrammarPatterns(text)
+	grammarScore := la.checkGrammarPatterns(tokens.Sentences(), tokens.SentenceWords())
 	
 	// Non-anthropic pattern detection
 	aiPatternScore := la.detectAIPatterns(text)
 	botPatternScore := la.detectBotPatterns(text)
+	aiPhrases := matchedPhrases(text, la.aiPatterns)
 	
 	// Structural heuristics
 	vowelRatio := la.calculateVowelRatio(text)
-	wordLengthVariance := la.calculateWordLengthVariance(text)
-	functionWordRatio := la.calculateFunctionWordRatio(text)
-	sentenceComplexity := la.calculateSentenceComplexity(text)
+	wordLengthVariance := la.calculateWordLengthVariance(tokens.Words())
+	functionWordRatio := la.calculateFunctionWordRatio(tokens.LowerWords())
+	sentenceComplexity := la.calculateSentenceComplexity(tokens.Sentences(), tokens.SentenceWords())
 	
 	// Original features
-	avgSentenceLength := la.calculateAverageSentenceLength(text)
-	avgWordLength := la.calculateAverageWordLength(text)
-	punctuationDensity := la.calculatePunctuationDensity(text)
+	avgSentenceLength := la.calculateAverageSentenceLength(tokens.SentenceWords())
+	avgWordLength := la.calculateAverageWordLength(tokens.Words())
+	punctuationDensity := la.calculatePunctuationDensity(tokens.Characters())
 	capitalRatio := la.calculateCapitalizationRatio(text)
-	repetitionScore := la.calculateRepetitionScore(text)
-	vocabularyRichness := la.calculateVocabularyRichness(text)
-	transitionSmoothness := la.calculateTransitionSmoothness(text)
+	repetitionScore := la.calculateRepetitionScore(tokens.LowerWords())
+	vocabularyRichness := la.calculateVocabularyRichness(tokens.LowerWords())
+	transitionSmoothness := la.calculateTransitionSmoothness(tokens.SentenceWords())
 	
 	// Combine all features into anomaly score
-	score := la.calculateEnhancedAnomalyScore(
+	score := la.calculateEnhancedAnomalyScore(baselines,
 		avgSentenceLength, avgWordLength, punctuationDensity, capitalRatio,
 		repetitionScore, vocabularyRichness, transitionSmoothness,
 		perplexity, grammarScore, aiPatternScore, botPatternScore,
@@ -142,8 +214,15 @@ func (la *LinguisticAnalyzer) Analyze(ctx context.Context, text string) (*models
 		langConfidence)
 	
 	// Calculate enhanced confidence
-	confidence := la.calculateEnhancedConfidence(text, language, langConfidence,
+	confidence := la.calculateEnhancedConfidence(tokens.Words(), language, langConfidence,
 		perplexity, grammarScore)
+	if !langReliable {
+		// Features are calibrated per language, so a shaky guess weakens all of them
+		confidence *= unreliableLanguagePenalty
+	}
+	if tokens.NaiveWords() {
+		confidence *= tokenizer.NaiveWordsPenalty
+	}
 	
 	return &models.AnalysisResult{
 		Score:      score,
@@ -151,9 +230,13 @@ func (la *LinguisticAnalyzer) Analyze(ctx context.Context, text string) (*models
 		Metadata: map[string]interface{}{
 			"detected_language":      language,
 			"language_confidence":    langConfidence,
+			"language_forced":        forcedLanguage != autoLanguage,
+			"language_reliable":      langReliable,
+			"language_candidates":    candidates,
 			"perplexity":             perplexity,
 			"grammar_score":          grammarScore,
 			"ai_pattern_score":       aiPatternScore,
+			"ai_phrases":             aiPhrases,
 			"bot_pattern_score":      botPatternScore,
 			"vowel_ratio":            vowelRatio,
 			"word_length_variance":   wordLengthVariance,
@@ -166,24 +249,21 @@ func (la *LinguisticAnalyzer) Analyze(ctx context.Context, text string) (*models
 			"repetition_score":       repetitionScore,
 			"vocabulary_richness":    vocabularyRichness,
 			"transition_smoothness":  transitionSmoothness,
+			"naive_word_split":       tokens.NaiveWords(),
 		},
 	}, nil
 }
 
 // calculateAverageSentenceLength calculates the average sentence length
-func (la *LinguisticAnalyzer) calculateAverageSentenceLength(text string) float64 {
-	sentenceRegex := regexp.MustCompile(`[.!?]+`)
-	sentences := sentenceRegex.Split(text, -1)
-	
-	if len(sentences) == 0 {
+func (la *LinguisticAnalyzer) calculateAverageSentenceLength(sentenceWords [][]string) float64 {
+	if len(sentenceWords) == 0 {
 		return 0
 	}
 	
 	totalWords := 0
 	validSentences := 0
 	
-	for _, sentence := range sentences {
-		words := strings.Fields(strings.TrimSpace(sentence))
+	for _, words := range sentenceWords {
 		if len(words) > 0 {
 			totalWords += len(words)
 			validSentences++
@@ -198,8 +278,7 @@ func (la *LinguisticAnalyzer) calculateAverageSentenceLength(text string) float6
 }
 
 // calculateAverageWordLength calculates the average word length
-func (la *LinguisticAnalyzer) calculateAverageWordLength(text string) float64 {
-	words := strings.Fields(text)
+func (la *LinguisticAnalyzer) calculateAverageWordLength(words []string) float64 {
 	if len(words) == 0 {
 		return 0
 	}
@@ -207,7 +286,7 @@ func (la *LinguisticAnalyzer) calculateAverageWordLength(text string) float64 {
 	totalChars := 0
 	for _, word := range words {
 		// Remove punctuation for word length calculation
-		cleanWord := regexp.MustCompile(`[^a-zA-Z0-9]`).ReplaceAllString(word, "")
+		cleanWord := nonAlphanumeric.ReplaceAllString(word, "")
 		totalChars += len(cleanWord)
 	}
 	
@@ -215,19 +294,19 @@ func (la *LinguisticAnalyzer) calculateAverageWordLength(text string) float64 {
 }
 
 // calculatePunctuationDensity calculates the density of punctuation marks
-func (la *LinguisticAnalyzer) calculatePunctuationDensity(text string) float64 {
-	if len(text) == 0 {
+func (la *LinguisticAnalyzer) calculatePunctuationDensity(chars []rune) float64 {
+	if len(chars) == 0 {
 		return 0
 	}
 	
 	punctCount := 0
-	for _, char := range text {
+	for _, char := range chars {
 		if unicode.IsPunct(char) {
 			punctCount++
 		}
 	}
 	
-	return float64(punctCount) / float64(len(text))
+	return float64(punctCount) / float64(len(chars))
 }
 
 // calculateCapitalizationRatio calculates the ratio of uppercase to total letters
@@ -252,8 +331,7 @@ func (la *LinguisticAnalyzer) calculateCapitalizationRatio(text string) float64
 }
 
 // calculateRepetitionScore analyzes repetition patterns
-func (la *LinguisticAnalyzer) calculateRepetitionScore(text string) float64 {
-	words := strings.Fields(strings.ToLower(text))
+func (la *LinguisticAnalyzer) calculateRepetitionScore(words []string) float64 {
 	if len(words) < 2 {
 		return 0
 	}
@@ -288,8 +366,7 @@ func (la *LinguisticAnalyzer) calculateRepetitionScore(text string) float64 {
 }
 
 // calculateVocabularyRichness calculates type-token ratio
-func (la *LinguisticAnalyzer) calculateVocabularyRichness(text string) float64 {
-	words := strings.Fields(strings.ToLower(text))
+func (la *LinguisticAnalyzer) calculateVocabularyRichness(words []string) float64 {
 	if len(words) == 0 {
 		return 0
 	}
@@ -297,7 +374,7 @@ func (la *LinguisticAnalyzer) calculateVocabularyRichness(text string) float64 {
 	uniqueWords := make(map[string]bool)
 	for _, word := range words {
 		// Clean word of punctuation
-		cleanWord := regexp.MustCompile(`[^a-zA-Z0-9]`).ReplaceAllString(word, "")
+		cleanWord := nonAlphanumeric.ReplaceAllString(word, "")
 		if len(cleanWord) > 0 {
 			uniqueWords[cleanWord] = true
 		}
@@ -307,20 +384,17 @@ func (la *LinguisticAnalyzer) calculateVocabularyRichness(text string) float64 {
 }
 
 // calculateTransitionSmoothness analyzes sentence-to-sentence transitions
-func (la *LinguisticAnalyzer) calculateTransitionSmoothness(text string) float64 {
-	sentenceRegex := regexp.MustCompile(`[.!?]+`)
-	sentences := sentenceRegex.Split(text, -1)
-	
-	if len(sentences) < 2 {
+func (la *LinguisticAnalyzer) calculateTransitionSmoothness(sentenceWords [][]string) float64 {
+	if len(sentenceWords) < 2 {
 		return 1.0 // No transitions to analyze
 	}
 	
 	smoothTransitions := 0
 	totalTransitions := 0
 	
-	for i := 1; i < len(sentences); i++ {
-		prevWords := strings.Fields(strings.TrimSpace(sentences[i-1]))
-		currWords := strings.Fields(strings.TrimSpace(sentences[i]))
+	for i := 1; i < len(sentenceWords); i++ {
+		prevWords := sentenceWords[i-1]
+		currWords := sentenceWords[i]
 		
 		if len(prevWords) > 0 && len(currWords) > 0 {
 			// Check for word overlap between adjacent sentences
@@ -425,8 +499,7 @@ func (la *LinguisticAnalyzer) calculateConfidence(text string, avgSentenceLength
 }
 
 // calculatePerplexity calculates text perplexity based on n-gram frequencies
-func (la *LinguisticAnalyzer) calculatePerplexity(text string) float64 {
-	words := strings.Fields(strings.ToLower(text))
+func (la *LinguisticAnalyzer) calculatePerplexity(words []string) float64 {
 	if len(words) < 3 {
 		return 0.0
 	}
@@ -495,18 +568,12 @@ func (la *LinguisticAnalyzer) buildNgramModels(words []string) {
 }
 
 // checkGrammarPatterns checks for grammatical structures and coherence
-func (la *LinguisticAnalyzer) checkGrammarPatterns(text string) float64 {
+func (la *LinguisticAnalyzer) checkGrammarPatterns(sentences []string, sentenceWords [][]string) float64 {
 	grammarScore := 0.0
-	sentences := regexp.MustCompile(`[.!?]+`).Split(text, -1)
 	
 	validSentences := 0
-	for _, sentence := range sentences {
-		sentence = strings.TrimSpace(sentence)
-		if len(sentence) == 0 {
-			continue
-		}
-		
-		words := strings.Fields(sentence)
+	for i, sentence := range sentences {
+		words := sentenceWords[i]
 		if len(words) == 0 {
 			continue
 		}
@@ -523,7 +590,7 @@ func (la *LinguisticAnalyzer) checkGrammarPatterns(text string) float64 {
 		nounLikeWords := 0
 		verbLikeWords := 0
 		for _, word := range words {
-			cleanWord := strings.ToLower(regexp.MustCompile(`[^a-zA-Z]`).ReplaceAllString(word, ""))
+			cleanWord := strings.ToLower(nonLetter.ReplaceAllString(word, ""))
 			if len(cleanWord) > 0 {
 				// Simple heuristics for word types
 				if strings.HasSuffix(cleanWord, "ing") || strings.HasSuffix(cleanWord, "ed") {
@@ -549,7 +616,7 @@ func (la *LinguisticAnalyzer) checkGrammarPatterns(text string) float64 {
 		// Check function word usage
 		functionWords := 0
 		for _, word := range words {
-			cleanWord := strings.ToLower(regexp.MustCompile(`[^a-zA-Z]`).ReplaceAllString(word, ""))
+			cleanWord := strings.ToLower(nonLetter.ReplaceAllString(word, ""))
 			if la.functionWords[cleanWord] {
 				functionWords++
 			}
@@ -606,6 +673,18 @@ func (la *LinguisticAnalyzer) detectAIPatterns(text string) float64 {
 	return (aiScore*0.7 + formalScore*0.3)
 }
 
+// matchedPhrases returns the patterns that occur in text, in pattern order
+func matchedPhrases(text string, patterns []string) []string {
+	lowerText := strings.ToLower(text)
+	matched := make([]string, 0)
+	for _, pattern := range patterns {
+		if strings.Contains(lowerText, pattern) {
+			matched = append(matched, pattern)
+		}
+	}
+	return matched
+}
+
 // detectBotPatterns detects bot-like conversational patterns
 func (la *LinguisticAnalyzer) detectBotPatterns(text string) float64 {
 	lowerText := strings.ToLower(text)
@@ -647,8 +726,7 @@ func (la *LinguisticAnalyzer) calculateVowelRatio(text string) float64 {
 }
 
 // calculateWordLengthVariance calculates variance in word lengths
-func (la *LinguisticAnalyzer) calculateWordLengthVariance(text string) float64 {
-	words := strings.Fields(text)
+func (la *LinguisticAnalyzer) calculateWordLengthVariance(words []string) float64 {
 	if len(words) == 0 {
 		return 0.0
 	}
@@ -657,7 +735,7 @@ func (la *LinguisticAnalyzer) calculateWordLengthVariance(text string) float64 {
 	total := 0.0
 	
 	for i, word := range words {
-		cleanWord := regexp.MustCompile(`[^a-zA-Z0-9]`).ReplaceAllString(word, "")
+		cleanWord := nonAlphanumeric.ReplaceAllString(word, "")
 		length := float64(len(cleanWord))
 		lengths[i] = length
 		total += length
@@ -675,15 +753,14 @@ func (la *LinguisticAnalyzer) calculateWordLengthVariance(text string) float64 {
 }
 
 // calculateFunctionWordRatio calculates the ratio of function words
-func (la *LinguisticAnalyzer) calculateFunctionWordRatio(text string) float64 {
-	words := strings.Fields(strings.ToLower(text))
+func (la *LinguisticAnalyzer) calculateFunctionWordRatio(words []string) float64 {
 	if len(words) == 0 {
 		return 0.0
 	}
 	
 	functionWords := 0
 	for _, word := range words {
-		cleanWord := regexp.MustCompile(`[^a-zA-Z]`).ReplaceAllString(word, "")
+		cleanWord := nonLetter.ReplaceAllString(word, "")
 		if la.functionWords[cleanWord] {
 			functionWords++
 		}
@@ -693,8 +770,7 @@ func (la *LinguisticAnalyzer) calculateFunctionWordRatio(text string) float64 {
 }
 
 // calculateSentenceComplexity calculates average syntactic complexity
-func (la *LinguisticAnalyzer) calculateSentenceComplexity(text string) float64 {
-	sentences := regexp.MustCompile(`[.!?]+`).Split(text, -1)
+func (la *LinguisticAnalyzer) calculateSentenceComplexity(sentences []string, sentenceWords [][]string) float64 {
 	if len(sentences) == 0 {
 		return 0.0
 	}
@@ -702,13 +778,8 @@ func (la *LinguisticAnalyzer) calculateSentenceComplexity(text string) float64 {
 	totalComplexity := 0.0
 	validSentences := 0
 	
-	for _, sentence := range sentences {
-		sentence = strings.TrimSpace(sentence)
-		if len(sentence) == 0 {
-			continue
-		}
-		
-		words := strings.Fields(sentence)
+	for i, sentence := range sentences {
+		words := sentenceWords[i]
 		if len(words) == 0 {
 			continue
 		}
@@ -719,7 +790,7 @@ func (la *LinguisticAnalyzer) calculateSentenceComplexity(text string) float64 {
 		// Count clauses (rough approximation using commas and conjunctions)
 		clauses := 1 + strings.Count(sentence, ",")
 		for _, word := range words {
-			cleanWord := strings.ToLower(regexp.MustCompile(`[^a-zA-Z]`).ReplaceAllString(word, ""))
+			cleanWord := strings.ToLower(nonLetter.ReplaceAllString(word, ""))
 			if cleanWord == "and" || cleanWord == "but" || cleanWord == "because" || 
 			   cleanWord == "although" || cleanWord == "while" || cleanWord == "since" {
 				clauses++
@@ -739,7 +810,7 @@ func (la *LinguisticAnalyzer) calculateSentenceComplexity(text string) float64 {
 }
 
 // calculateEnhancedAnomalyScore combines all linguistic features
-func (la *LinguisticAnalyzer) calculateEnhancedAnomalyScore(
+func (la *LinguisticAnalyzer) calculateEnhancedAnomalyScore(baselines map[string]Baseline,
 	avgSentenceLength, avgWordLength, punctuationDensity, capitalRatio,
 	repetitionScore, vocabularyRichness, transitionSmoothness,
 	perplexity, grammarScore, aiPatternScore, botPatternScore,
@@ -747,18 +818,22 @@ func (la *LinguisticAnalyzer) calculateEnhancedAnomalyScore(
 	langConfidence float64) float64 {
 	
 	score := 0.0
+	normalize := func(feature string, value float64, invert bool) float64 {
+		baseline := baselines[feature]
+		return la.normalizeFeature(value, baseline.Low, baseline.High, invert)
+	}
 	
 	// Original linguistic features (reduced weights)
-	sentenceLengthScore := la.normalizeFeature(avgSentenceLength, 10, 25, true)
-	wordLengthScore := la.normalizeFeature(avgWordLength, 3, 7, false)
-	punctuationScoreNorm := la.normalizeFeature(punctuationDensity, 0.02, 0.15, false)
-	capitalScoreNorm := la.normalizeFeature(capitalRatio, 0.02, 0.12, true)
-	repetitionScoreNorm := la.normalizeFeature(repetitionScore, 0.0, 0.1, true)
-	vocabScore := la.normalizeFeature(vocabularyRichness, 0.3, 0.8, false)
-	transitionScore := la.normalizeFeature(transitionSmoothness, 0.2, 0.8, true)
+	sentenceLengthScore := normalize(FeatureAvgSentenceLength, avgSentenceLength, true)
+	wordLengthScore := normalize(FeatureAvgWordLength, avgWordLength, false)
+	punctuationScoreNorm := normalize(FeaturePunctuationDensity, punctuationDensity, false)
+	capitalScoreNorm := normalize(FeatureCapitalizationRatio, capitalRatio, true)
+	repetitionScoreNorm := normalize(FeatureRepetitionScore, repetitionScore, true)
+	vocabScore := normalize(FeatureVocabularyRichness, vocabularyRichness, false)
+	transitionScore := normalize(FeatureTransitionSmoothness, transitionSmoothness, true)
 	
 	// Enhanced features
-	perplexityScore := la.normalizeFeature(perplexity, 0.1, 1.0, false) // Lower perplexity = more predictable = higher anomaly
+	perplexityScore := normalize(FeaturePerplexity, perplexity, false) // Lower perplexity = more predictable = higher anomaly
 	grammarScoreNorm := 1.0 - grammarScore // Poor grammar might indicate AI
 	
 	// AI/Bot patterns (direct scores)
@@ -767,13 +842,13 @@ func (la *LinguisticAnalyzer) calculateEnhancedAnomalyScore(
 	
 	// Structural heuristics
 	vowelScore := 0.0
-	if vowelRatio < la.vowelRatioMin || vowelRatio > la.vowelRatioMax {
+	if vowels := baselines[FeatureVowelRatio]; vowelRatio < vowels.Low || vowelRatio > vowels.High {
 		vowelScore = 0.3
 	}
 	
-	wordVarianceScore := la.normalizeFeature(wordLengthVariance, 2.0, 10.0, true) // Less variance might indicate AI
-	functionWordScore := la.normalizeFeature(functionWordRatio, 0.2, 0.6, true) // Unusual ratios might indicate AI
-	complexityScore := la.normalizeFeature(sentenceComplexity, 0.5, 3.0, false)
+	wordVarianceScore := normalize(FeatureWordLengthVariance, wordLengthVariance, true) // Less variance might indicate AI
+	functionWordScore := normalize(FeatureFunctionWordRatio, functionWordRatio, true) // Unusual ratios might indicate AI
+	complexityScore := normalize(FeatureSentenceComplexity, sentenceComplexity, false)
 	
 	// Language confidence (low confidence might indicate non-native generation)
 	langScore := 1.0 - langConfidence
@@ -789,10 +864,9 @@ func (la *LinguisticAnalyzer) calculateEnhancedAnomalyScore(
 }
 
 // calculateEnhancedConfidence determines confidence with language detection
-func (la *LinguisticAnalyzer) calculateEnhancedConfidence(text, language string, 
+func (la *LinguisticAnalyzer) calculateEnhancedConfidence(words []string, language string, 
 	langConfidence, perplexity, grammarScore float64) float64 {
 	
-	words := strings.Fields(text)
 	wordCount := len(words)
 	
 	// Base confidence on text length
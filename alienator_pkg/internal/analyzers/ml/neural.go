@@ -2,6 +2,7 @@ package ml
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -29,16 +30,58 @@ type TrainingData struct {
 	Outputs []float64
 }
 
+// Training stop reasons reported in TrainingReport.Stopped
+const (
+	TrainingCompleted = "completed" // every epoch ran
+	TrainingPlateaued = "plateaued" // the loss stopped improving
+	TrainingTimedOut  = "timed_out" // the time budget or the context deadline ran out
+)
+
+// Training defaults, see Configure
+const (
+	DefaultTrainingTimeout = 30 * time.Second
+	DefaultCheckInterval   = 256
+	DefaultPatience        = 10
+	DefaultMinDelta        = 1e-5
+	DefaultOptimizer       = OptimizerAdam
+	DefaultBatchSize       = 16
+	DefaultMomentum        = 0.9
+)
+
+// TrainingReport describes the last training run. The network kept is the
+// one from the epoch with the lowest loss, which is the last epoch unless
+// training stopped early.
+type TrainingReport struct {
+	Epochs    int           // epochs completed
+	BestEpoch int           // 1-based epoch whose network was kept
+	Loss      float64       // mean loss of the kept network's epoch
+	Stopped   string        // why training ended
+	Optimizer string        // name of the optimizer that trained it
+	Duration  time.Duration // time spent training
+}
+
+// Truncated reports whether training ended before every epoch ran
+func (r TrainingReport) Truncated() bool {
+	return r.Stopped != "" && r.Stopped != TrainingCompleted
+}
+
 // NeuralDetector implements ML-based anomaly detection using a simple neural network
 type NeuralDetector struct {
-	config       *analyzers.Configuration
-	network      *NeuralNetwork
-	isTrained    bool
-	trainingData []TrainingData
-	scaler       *MinMaxScaler
-	windowSize   int
-	threshold    float64
-	mu           sync.RWMutex
+	config          *analyzers.Configuration
+	network         *NeuralNetwork
+	isTrained       bool
+	trainingData    []TrainingData
+	scaler          *MinMaxScaler
+	windowSize      int
+	threshold       float64
+	trainingTimeout time.Duration // bounds each Train call; 0 leaves it to the context
+	checkInterval   int           // samples between context checks during an epoch
+	patience        int           // epochs without improvement before stopping; 0 disables early stopping
+	minDelta        float64       // smallest loss decrease that counts as an improvement
+	optimizer       Optimizer
+	batchSize       int // samples whose gradients are averaged into each update
+	lastTraining    TrainingReport
+	mu              sync.RWMutex
 }
 
 // MinMaxScaler for data normalization
@@ -60,12 +103,18 @@ func NewNeuralDetector(config *analyzers.Configuration) (*NeuralDetector, error)
 	}
 
 	detector := &NeuralDetector{
-		config:       config,
-		windowSize:   windowSize,
-		threshold:    0.5, // Anomaly threshold
-		scaler:       &MinMaxScaler{},
-		trainingData: make([]TrainingData, 0),
-	}
+		config:          config,
+		windowSize:      windowSize,
+		threshold:       0.5, // Anomaly threshold
+		scaler:          &MinMaxScaler{},
+		trainingData:    make([]TrainingData, 0),
+		trainingTimeout: DefaultTrainingTimeout,
+		checkInterval:   DefaultCheckInterval,
+		patience:        DefaultPatience,
+		minDelta:        DefaultMinDelta,
+		batchSize:       DefaultBatchSize,
+	}
+	detector.optimizer, _ = NewOptimizer(DefaultOptimizer, DefaultMomentum)
 
 	// Initialize neural network
 	detector.network = NewNeuralNetwork(windowSize, windowSize/2, 1, 0.01)
@@ -138,9 +187,9 @@ func (d *NeuralDetector) Analyze(ctx context.Context, data *analyzers.TimeSeries
 		}, nil
 	}
 
-	if !d.isTrained {
-		// Auto-train if not trained yet
-		if err := d.autoTrain(data); err != nil {
+	if !d.IsTrained() {
+		// Auto-train if not trained yet, within the analysis' context
+		if err := d.autoTrain(ctx, data); err != nil {
 			return nil, fmt.Errorf("failed to auto-train: %w", err)
 		}
 	}
@@ -175,6 +224,14 @@ func (d *NeuralDetector) Analyze(ctx context.Context, data *analyzers.TimeSeries
 		"network_hidden_size": d.network.hiddenSize,
 		"processing_time_ms":  duration.Milliseconds(),
 	}
+	if training := d.LastTraining(); training.Stopped != "" {
+		metadata["training_epochs"] = training.Epochs
+		metadata["training_stopped"] = training.Stopped
+		metadata["training_truncated"] = training.Truncated()
+		metadata["training_loss"] = training.Loss
+		metadata["training_best_epoch"] = training.BestEpoch
+		metadata["training_optimizer"] = training.Optimizer
+	}
 
 	return &analyzers.AnalysisResult{
 		Anomalies: anomalies,
@@ -184,7 +241,15 @@ func (d *NeuralDetector) Analyze(ctx context.Context, data *analyzers.TimeSeries
 	}, nil
 }
 
-// Configure updates the detector configuration
+// Configure updates the detector configuration. Besides the threshold,
+// window_size, learning_rate and epochs, training is tuned by
+// training_timeout (a time.Duration or a duration string such as "10s"; 0
+// leaves the bound to the caller's context), check_interval (samples between
+// cancellation checks), patience (epochs the loss may fail to improve by
+// min_delta before training stops; 0 runs every epoch), min_delta,
+// optimizer ("sgd", "momentum" or "adam"), momentum (for "momentum") and
+// batch_size (samples whose gradients are averaged into each update; 1
+// updates after every sample).
 func (d *NeuralDetector) Configure(config map[string]interface{}) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -204,9 +269,82 @@ func (d *NeuralDetector) Configure(config map[string]interface{}) error {
 		d.network.learningRate = learningRate
 	}
 
+	if epochs, ok := config["epochs"].(int); ok && epochs > 0 {
+		if d.config.Metadata == nil {
+			d.config.Metadata = make(map[string]interface{})
+		}
+		d.config.Metadata["epochs"] = epochs
+	}
+
+	switch timeout := config["training_timeout"].(type) {
+	case time.Duration:
+		if timeout < 0 {
+			return fmt.Errorf("training_timeout must not be negative, got %s", timeout)
+		}
+		d.trainingTimeout = timeout
+	case string:
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil || parsed < 0 {
+			return fmt.Errorf("training_timeout must be a non-negative duration, got %q", timeout)
+		}
+		d.trainingTimeout = parsed
+	}
+
+	if interval, ok := config["check_interval"].(int); ok {
+		if interval < 1 {
+			return fmt.Errorf("check_interval must be at least 1, got %d", interval)
+		}
+		d.checkInterval = interval
+	}
+
+	if patience, ok := config["patience"].(int); ok {
+		if patience < 0 {
+			return fmt.Errorf("patience must not be negative, got %d", patience)
+		}
+		d.patience = patience
+	}
+
+	if minDelta, ok := config["min_delta"].(float64); ok {
+		if minDelta < 0 {
+			return fmt.Errorf("min_delta must not be negative, got %g", minDelta)
+		}
+		d.minDelta = minDelta
+	}
+
+	if batchSize, ok := config["batch_size"].(int); ok {
+		if batchSize < 1 {
+			return fmt.Errorf("batch_size must be at least 1, got %d", batchSize)
+		}
+		d.batchSize = batchSize
+	}
+
+	name, named := config["optimizer"].(string)
+	momentum, hasMomentum := config["momentum"].(float64)
+	if named || hasMomentum {
+		if !named {
+			name = d.optimizer.Name()
+		}
+		if !hasMomentum {
+			momentum = DefaultMomentum
+		}
+		optimizer, err := NewOptimizer(name, momentum)
+		if err != nil {
+			return err
+		}
+		d.optimizer = optimizer
+	}
+
 	return nil
 }
 
+// LastTraining returns the report of the last training run that produced a
+// network; the zero report before any
+func (d *NeuralDetector) LastTraining() TrainingReport {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.lastTraining
+}
+
 // IsReady returns true if the detector is trained and ready
 func (d *NeuralDetector) IsReady() bool {
 	d.mu.RLock()
@@ -221,8 +359,35 @@ func (d *NeuralDetector) IsTrained() bool {
 	return d.isTrained
 }
 
-// Train trains the neural network with the given data
+// ValidateHealth checks that the network exists and, once trained, that
+// its weights have not diverged
+func (d *NeuralDetector) ValidateHealth(ctx context.Context) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.network == nil {
+		return fmt.Errorf("neural network is not initialized")
+	}
+	if !d.isTrained {
+		return nil
+	}
+	for _, layer := range [][][]float64{d.network.weights1, d.network.weights2, {d.network.biases1, d.network.biases2}} {
+		for _, row := range layer {
+			for _, weight := range row {
+				if math.IsNaN(weight) || math.IsInf(weight, 0) {
+					return fmt.Errorf("neural network weights have diverged")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Train trains the neural network with the given data, within the
+// configured training timeout and with early stopping once the loss
+// plateaus; see LastTraining for how it ended
 func (d *NeuralDetector) Train(ctx context.Context, data []*analyzers.TimeSeries) error {
+	start := time.Now()
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -271,11 +436,47 @@ func (d *NeuralDetector) Train(ctx context.Context, data []*analyzers.TimeSeries
 	if customEpochs, ok := d.config.Metadata["epochs"].(int); ok {
 		epochs = customEpochs
 	}
+	if d.trainingTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.trainingTimeout)
+		defer cancel()
+	}
+
+	report, err := d.trainEpochs(ctx, epochs)
+	report.Duration = time.Since(start)
+	if err != nil {
+		return err
+	}
+	d.lastTraining = report
+	d.isTrained = true
+	return nil
+}
+
+// trainEpochs runs up to epochs passes over the training data, checking ctx
+// every checkInterval samples. It keeps the network of the epoch with the
+// lowest loss, stopping once the loss has not improved by minDelta for
+// patience epochs. When ctx's deadline passes after an epoch completed,
+// training ends early with the best network so far; cancellation, or a
+// deadline before any epoch completed, is an error.
+func (d *NeuralDetector) trainEpochs(ctx context.Context, epochs int) (TrainingReport, error) {
+	report := TrainingReport{Stopped: TrainingCompleted, Optimizer: d.optimizer.Name()}
+	initial := d.network.clone()
+	var best *NeuralNetwork
+	bestLoss := math.Inf(1)
+	stale := 0
+	d.optimizer.Reset()
+	params := d.network.parameters()
+	grads := zerosLike(params)
 
 	for epoch := 0; epoch < epochs; epoch++ {
 		var totalLoss float64
+		batched := 0
+
+		for i, sample := range d.trainingData {
+			if i%d.checkInterval == 0 && ctx.Err() != nil {
+				return d.stopTraining(ctx, report, initial, best)
+			}
 
-		for _, sample := range d.trainingData {
 			// Forward pass
 			prediction := d.network.Forward(sample.Inputs)
 
@@ -288,26 +489,74 @@ func (d *NeuralDetector) Train(ctx context.Context, data []*analyzers.TimeSeries
 			loss /= float64(len(sample.Outputs))
 			totalLoss += loss
 
-			// Backward pass
-			d.network.Backward(sample.Inputs, sample.Outputs, prediction)
+			// Backward pass, updating once a batch is complete
+			d.network.accumulate(sample.Inputs, sample.Outputs, prediction, grads)
+			batched++
+			if batched == d.batchSize || i == len(d.trainingData)-1 {
+				d.step(params, grads, batched)
+				batched = 0
+			}
 		}
 
-		// Optional: Log training progress
-		if epoch%20 == 0 {
-			avgLoss := totalLoss / float64(len(d.trainingData))
-			_ = avgLoss // Could log this in a real implementation
+		report.Epochs = epoch + 1
+		avgLoss := totalLoss / float64(len(d.trainingData))
+		if avgLoss < bestLoss-d.minDelta || best == nil {
+			stale = 0
+		} else {
+			stale++
+		}
+		if avgLoss < bestLoss {
+			bestLoss = avgLoss
+			best = d.network.clone()
+			report.BestEpoch, report.Loss = report.Epochs, avgLoss
+		}
+		if d.patience > 0 && stale >= d.patience {
+			report.Stopped = TrainingPlateaued
+			break
 		}
+	}
 
-		// Check for context cancellation
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+	if best != nil {
+		d.network = best
+	}
+	return report, nil
+}
+
+// step applies the mean of the gradients accumulated over batched samples
+// to params, then clears them for the next batch
+func (d *NeuralDetector) step(params, grads [][]float64, batched int) {
+	scale := 1 / float64(batched)
+	for _, row := range grads {
+		for j := range row {
+			row[j] *= scale
 		}
 	}
+	d.optimizer.Step(params, grads, d.network.learningRate)
+	for _, row := range grads {
+		for j := range row {
+			row[j] = 0
+		}
+	}
+}
 
-	d.isTrained = true
-	return nil
+// stopTraining ends training interrupted by ctx, keeping the best network
+// so far when the deadline passed after an epoch completed. Otherwise the
+// network is restored to what it was before training.
+func (d *NeuralDetector) stopTraining(ctx context.Context, report TrainingReport, initial, best *NeuralNetwork) (TrainingReport, error) {
+	// The interrupted epoch left the network half-updated
+	if best != nil {
+		d.network = best
+	} else {
+		d.network = initial
+	}
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return report, ctx.Err()
+	}
+	if best == nil {
+		return report, fmt.Errorf("training timed out before completing an epoch: %w", ctx.Err())
+	}
+	report.Stopped = TrainingTimedOut
+	return report, nil
 }
 
 // Close cleans up resources
@@ -321,9 +570,9 @@ func (d *NeuralDetector) Close() error {
 }
 
 // autoTrain performs automatic training on the given data
-func (d *NeuralDetector) autoTrain(data *analyzers.TimeSeries) error {
+func (d *NeuralDetector) autoTrain(ctx context.Context, data *analyzers.TimeSeries) error {
 	// Use the current data for training (simplified approach)
-	return d.Train(context.Background(), []*analyzers.TimeSeries{data})
+	return d.Train(ctx, []*analyzers.TimeSeries{data})
 }
 
 // extractValues converts data points to float64 values
@@ -465,6 +714,24 @@ func (d *NeuralDetector) toFloat64(value interface{}) (float64, error) {
 
 // Neural Network methods
 
+// clone returns a deep copy of the network
+func (nn *NeuralNetwork) clone() *NeuralNetwork {
+	copied := *nn
+	copied.weights1 = copyMatrix(nn.weights1)
+	copied.weights2 = copyMatrix(nn.weights2)
+	copied.biases1 = append([]float64(nil), nn.biases1...)
+	copied.biases2 = append([]float64(nil), nn.biases2...)
+	return &copied
+}
+
+func copyMatrix(matrix [][]float64) [][]float64 {
+	copied := make([][]float64, len(matrix))
+	for i, row := range matrix {
+		copied[i] = append([]float64(nil), row...)
+	}
+	return copied
+}
+
 // Forward performs forward propagation
 func (nn *NeuralNetwork) Forward(inputs []float64) []float64 {
 	if len(inputs) != nn.inputSize {
@@ -494,12 +761,36 @@ func (nn *NeuralNetwork) Forward(inputs []float64) []float64 {
 	return outputs
 }
 
-// Backward performs backpropagation
+// Backward performs backpropagation, updating the weights from this one
+// sample by plain gradient descent
 func (nn *NeuralNetwork) Backward(inputs, targets, predictions []float64) {
+	params := nn.parameters()
+	grads := zerosLike(params)
+	nn.accumulate(inputs, targets, predictions, grads)
+	for i, row := range params {
+		for j := range row {
+			row[j] -= nn.learningRate * grads[i][j]
+		}
+	}
+}
+
+// parameters returns the rows of the network's weights and biases, which
+// optimizers update in place: the input-to-hidden weights by input, the
+// hidden-to-output weights by hidden unit, then the hidden and output biases
+func (nn *NeuralNetwork) parameters() [][]float64 {
+	params := make([][]float64, 0, nn.inputSize+nn.hiddenSize+2)
+	params = append(params, nn.weights1...)
+	params = append(params, nn.weights2...)
+	return append(params, nn.biases1, nn.biases2)
+}
+
+// accumulate adds the gradients of the squared error of one sample to
+// grads, laid out as parameters lists them
+func (nn *NeuralNetwork) accumulate(inputs, targets, predictions []float64, grads [][]float64) {
 	// Calculate output layer errors
 	outputErrors := make([]float64, nn.outputSize)
 	for k := 0; k < nn.outputSize; k++ {
-		outputErrors[k] = (targets[k] - predictions[k]) * sigmoidDerivative(predictions[k])
+		outputErrors[k] = (predictions[k] - targets[k]) * sigmoidDerivative(predictions[k])
 	}
 
 	// Calculate hidden layer values (needed for backprop)
@@ -522,24 +813,27 @@ func (nn *NeuralNetwork) Backward(inputs, targets, predictions []float64) {
 		hiddenErrors[j] = sum * sigmoidDerivative(hidden[j])
 	}
 
-	// Update weights and biases (output layer)
+	weights2 := grads[nn.inputSize : nn.inputSize+nn.hiddenSize]
+	biases1, biases2 := grads[nn.inputSize+nn.hiddenSize], grads[nn.inputSize+nn.hiddenSize+1]
+
+	// Output layer gradients
 	for j := 0; j < nn.hiddenSize; j++ {
 		for k := 0; k < nn.outputSize; k++ {
-			nn.weights2[j][k] += nn.learningRate * outputErrors[k] * hidden[j]
+			weights2[j][k] += outputErrors[k] * hidden[j]
 		}
 	}
 	for k := 0; k < nn.outputSize; k++ {
-		nn.biases2[k] += nn.learningRate * outputErrors[k]
+		biases2[k] += outputErrors[k]
 	}
 
-	// Update weights and biases (hidden layer)
+	// Hidden layer gradients
 	for i := 0; i < nn.inputSize; i++ {
 		for j := 0; j < nn.hiddenSize; j++ {
-			nn.weights1[i][j] += nn.learningRate * hiddenErrors[j] * inputs[i]
+			grads[i][j] += hiddenErrors[j] * inputs[i]
 		}
 	}
 	for j := 0; j < nn.hiddenSize; j++ {
-		nn.biases1[j] += nn.learningRate * hiddenErrors[j]
+		biases1[j] += hiddenErrors[j]
 	}
 }
 
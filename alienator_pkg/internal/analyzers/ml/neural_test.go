@@ -0,0 +1,134 @@
+package ml
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/analyzers"
+)
+
+func TestTrainingTimeoutKeepsBestNetwork(t *testing.T) {
+	detector := newTestDetector(t, map[string]interface{}{"training_timeout": 50 * time.Millisecond, "check_interval": 1, "epochs": 1000000})
+
+	start := time.Now()
+	if err := detector.Train(context.Background(), []*analyzers.TimeSeries{sineSeries(200)}); err != nil {
+		t.Fatalf("Expected training cut short by its timeout to succeed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the timeout to bound training, took %s", elapsed)
+	}
+	report := detector.LastTraining()
+	if report.Stopped != TrainingTimedOut || !report.Truncated() || report.Epochs == 0 || report.Epochs >= 1000000 {
+		t.Errorf("Expected training to time out after some epochs, got %+v", report)
+	}
+	if !detector.IsTrained() || report.BestEpoch < 1 || report.BestEpoch > report.Epochs {
+		t.Errorf("Expected the network of a completed epoch kept, got %+v", report)
+	}
+
+	result, err := detector.Analyze(context.Background(), sineSeries(200))
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if result.Metadata["training_truncated"] != true || result.Metadata["training_stopped"] != TrainingTimedOut {
+		t.Errorf("Expected the truncated training in the metadata, got %v", result.Metadata)
+	}
+}
+
+func TestTrainingInterruptedBeforeAnEpoch(t *testing.T) {
+	detector := newTestDetector(t, map[string]interface{}{"check_interval": 1})
+	initial := detector.network.clone()
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if err := detector.Train(expired, []*analyzers.TimeSeries{sineSeries(200)}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline before any epoch completed to fail training, got %v", err)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := detector.Train(canceled, []*analyzers.TimeSeries{sineSeries(200)}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation to fail training, got %v", err)
+	}
+
+	if detector.IsTrained() || detector.LastTraining().Stopped != "" {
+		t.Errorf("Expected no training recorded, got %+v", detector.LastTraining())
+	}
+	if !reflect.DeepEqual(detector.network, initial) {
+		t.Error("Expected the network restored to what it was before training")
+	}
+}
+
+func TestEarlyStopping(t *testing.T) {
+	// No decrease reaches min_delta, so every epoch after the first is stale
+	detector := newTestDetector(t, map[string]interface{}{"patience": 3, "min_delta": 10.0, "epochs": 100})
+
+	result, err := detector.Analyze(context.Background(), sineSeries(200))
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	report := detector.LastTraining()
+	if report.Stopped != TrainingPlateaued || report.Epochs != 4 || !report.Truncated() {
+		t.Errorf("Expected training to stop 3 epochs after the first, got %+v", report)
+	}
+	if result.Metadata["training_truncated"] != true || result.Metadata["training_stopped"] != TrainingPlateaued || result.Metadata["training_epochs"] != 4 {
+		t.Errorf("Expected the early stop in the metadata, got %v", result.Metadata)
+	}
+
+	detector = newTestDetector(t, map[string]interface{}{"epochs": 3})
+	result, err = detector.Analyze(context.Background(), sineSeries(200))
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if result.Metadata["training_truncated"] != false || result.Metadata["training_stopped"] != TrainingCompleted {
+		t.Errorf("Expected training that ran every epoch not to be truncated, got %v", result.Metadata)
+	}
+}
+
+// wreckingOptimizer leaves the parameters alone on its first step and sets
+// them all to 100 on the later ones, so that the loss only gets worse
+type wreckingOptimizer struct {
+	steps int
+}
+
+func (o *wreckingOptimizer) Name() string { return "wrecking" }
+
+func (o *wreckingOptimizer) Step(params, grads [][]float64, learningRate float64) {
+	o.steps++
+	if o.steps == 1 {
+		return
+	}
+	for _, row := range params {
+		for j := range row {
+			row[j] = 100
+		}
+	}
+}
+
+func (o *wreckingOptimizer) Reset() { o.steps = 0 }
+
+func TestTrainingRestoresBestNetwork(t *testing.T) {
+	// One batch makes one step an epoch
+	detector := newTestDetector(t, map[string]interface{}{"batch_size": 1000, "epochs": 4})
+	detector.optimizer = &wreckingOptimizer{}
+	// A network of zeros predicts 0.5 everywhere, closer to the sine than
+	// the 1 the wrecked one predicts
+	for _, row := range detector.network.parameters() {
+		for j := range row {
+			row[j] = 0
+		}
+	}
+	initial := detector.network.clone()
+
+	if err := detector.Train(context.Background(), []*analyzers.TimeSeries{sineSeries(200)}); err != nil {
+		t.Fatalf("Training failed: %v", err)
+	}
+	report := detector.LastTraining()
+	if report.Stopped != TrainingCompleted || report.Epochs != 4 || report.BestEpoch != 1 {
+		t.Errorf("Expected every epoch run and the first kept, got %+v", report)
+	}
+	if !reflect.DeepEqual(detector.network, initial) {
+		t.Error("Expected the network of the first epoch, left alone by the optimizer, to be restored")
+	}
+}
@@ -0,0 +1,123 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// Optimizer names accepted by NewOptimizer
+const (
+	OptimizerSGD      = "sgd"      // plain gradient descent
+	OptimizerMomentum = "momentum" // gradient descent with momentum
+	OptimizerAdam     = "adam"     // Adam, per-parameter adaptive steps
+)
+
+// Optimizer updates a network's parameters from the gradients of the loss.
+// Params and grads hold the same rows, as listed by
+// NeuralNetwork.parameters. Reset drops the state kept between steps.
+type Optimizer interface {
+	Name() string
+	Step(params, grads [][]float64, learningRate float64)
+	Reset()
+}
+
+// NewOptimizer returns the named optimizer. Momentum is the fraction of the
+// previous step carried into the next for "momentum"; the other optimizers
+// ignore it.
+func NewOptimizer(name string, momentum float64) (Optimizer, error) {
+	switch name {
+	case OptimizerSGD:
+		return &sgdOptimizer{}, nil
+	case OptimizerMomentum:
+		if momentum < 0 || momentum >= 1 {
+			return nil, fmt.Errorf("momentum must be in [0, 1), got %g", momentum)
+		}
+		return &sgdOptimizer{momentum: momentum}, nil
+	case OptimizerAdam:
+		return &adamOptimizer{beta1: 0.9, beta2: 0.999, epsilon: 1e-8}, nil
+	default:
+		return nil, fmt.Errorf("unknown optimizer %q (expected %s, %s or %s)", name, OptimizerSGD, OptimizerMomentum, OptimizerAdam)
+	}
+}
+
+// sgdOptimizer is gradient descent, with momentum when it is positive
+type sgdOptimizer struct {
+	momentum float64
+	velocity [][]float64
+}
+
+func (o *sgdOptimizer) Name() string {
+	if o.momentum > 0 {
+		return OptimizerMomentum
+	}
+	return OptimizerSGD
+}
+
+func (o *sgdOptimizer) Step(params, grads [][]float64, learningRate float64) {
+	if o.momentum == 0 {
+		for i, row := range params {
+			for j := range row {
+				row[j] -= learningRate * grads[i][j]
+			}
+		}
+		return
+	}
+
+	if o.velocity == nil {
+		o.velocity = zerosLike(grads)
+	}
+	for i, row := range params {
+		for j := range row {
+			o.velocity[i][j] = o.momentum*o.velocity[i][j] - learningRate*grads[i][j]
+			row[j] += o.velocity[i][j]
+		}
+	}
+}
+
+func (o *sgdOptimizer) Reset() {
+	o.velocity = nil
+}
+
+// adamOptimizer scales each parameter's step by running estimates of its
+// gradient's mean and variance, corrected for their start at zero
+type adamOptimizer struct {
+	beta1, beta2, epsilon float64
+	mean, variance        [][]float64
+	steps                 int
+}
+
+func (o *adamOptimizer) Name() string { return OptimizerAdam }
+
+func (o *adamOptimizer) Step(params, grads [][]float64, learningRate float64) {
+	if o.mean == nil {
+		o.mean = zerosLike(grads)
+		o.variance = zerosLike(grads)
+	}
+	o.steps++
+	meanCorrection := 1 - math.Pow(o.beta1, float64(o.steps))
+	varianceCorrection := 1 - math.Pow(o.beta2, float64(o.steps))
+
+	for i, row := range params {
+		for j := range row {
+			g := grads[i][j]
+			o.mean[i][j] = o.beta1*o.mean[i][j] + (1-o.beta1)*g
+			o.variance[i][j] = o.beta2*o.variance[i][j] + (1-o.beta2)*g*g
+			mean := o.mean[i][j] / meanCorrection
+			variance := o.variance[i][j] / varianceCorrection
+			row[j] -= learningRate * mean / (math.Sqrt(variance) + o.epsilon)
+		}
+	}
+}
+
+func (o *adamOptimizer) Reset() {
+	o.mean, o.variance, o.steps = nil, nil, 0
+}
+
+// zerosLike returns rows of zeros shaped like rows
+func zerosLike(rows [][]float64) [][]float64 {
+	zeros := make([][]float64, len(rows))
+	for i, row := range rows {
+		zeros[i] = make([]float64, len(row))
+	}
+	return zeros
+}
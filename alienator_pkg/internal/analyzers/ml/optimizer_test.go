@@ -0,0 +1,147 @@
+package ml
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/analyzers"
+)
+
+func TestOptimizerSteps(t *testing.T) {
+	grads := [][][]float64{{{0.5, -1}}, {{0.25, -1}}}
+	cases := []struct {
+		name     string
+		momentum float64
+		expected [][]float64 // params after each step
+	}{
+		{OptimizerSGD, 0, [][]float64{{0.95, 2.1}, {0.925, 2.2}}},
+		// The velocity after the first step, -0.05 and 0.1, carries into the second
+		{OptimizerMomentum, 0.9, [][]float64{{0.95, 2.1}, {0.88, 2.29}}},
+		// The first step is the learning rate against the gradient's sign; the
+		// second is 0.1 * (0.07 / 0.19) / sqrt(0.00031225 / 0.001999) for the
+		// first parameter, whose gradient halved
+		{OptimizerAdam, 0, [][]float64{{0.900000002, 2.099999999}, {0.8067820404774624, 2.199999998}}},
+	}
+	for _, tc := range cases {
+		optimizer, err := NewOptimizer(tc.name, tc.momentum)
+		if err != nil {
+			t.Fatalf("NewOptimizer(%s) failed: %v", tc.name, err)
+		}
+		if optimizer.Name() != tc.name {
+			t.Errorf("Expected optimizer %s, got %s", tc.name, optimizer.Name())
+		}
+		for round := 0; round < 2; round++ {
+			params := [][]float64{{1, 2}}
+			for step, grad := range grads {
+				optimizer.Step(params, grad, 0.1)
+				for j, expected := range tc.expected[step] {
+					if math.Abs(params[0][j]-expected) > 1e-9 {
+						t.Errorf("%s step %d: expected parameter %d to be %.10f, got %.10f", tc.name, step+1, j, expected, params[0][j])
+					}
+				}
+			}
+			// Reset starts over as a new optimizer would
+			optimizer.Reset()
+		}
+	}
+
+	if _, err := NewOptimizer(OptimizerMomentum, 1); err == nil {
+		t.Error("Expected a momentum of 1 to be rejected")
+	}
+	if _, err := NewOptimizer("rmsprop", 0); err == nil {
+		t.Error("Expected an unknown optimizer to be rejected")
+	}
+}
+
+// sineSeries returns n points of a sine wave
+func sineSeries(n int) *analyzers.TimeSeries {
+	series := &analyzers.TimeSeries{Name: "sine", DataPoints: make([]analyzers.DataPoint, n)}
+	start := time.Now()
+	for i := range series.DataPoints {
+		series.DataPoints[i] = analyzers.DataPoint{Timestamp: start.Add(time.Duration(i) * time.Second), Value: math.Sin(float64(i) / 5)}
+	}
+	return series
+}
+
+// newTestDetector returns a detector with 10-point windows, training
+// without a time limit or early stopping
+func newTestDetector(t *testing.T, config map[string]interface{}) *NeuralDetector {
+	t.Helper()
+	detector, err := NewNeuralDetector(nil)
+	if err != nil {
+		t.Fatalf("NewNeuralDetector failed: %v", err)
+	}
+	settings := map[string]interface{}{"window_size": 10, "training_timeout": "0s", "patience": 0}
+	for name, value := range config {
+		settings[name] = value
+	}
+	if err := detector.Configure(settings); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	return detector
+}
+
+func TestTrainingLossDecreases(t *testing.T) {
+	for _, name := range []string{OptimizerSGD, OptimizerMomentum, OptimizerAdam} {
+		detector := newTestDetector(t, map[string]interface{}{"optimizer": name, "learning_rate": 0.05, "epochs": 1})
+		data := []*analyzers.TimeSeries{sineSeries(200)}
+		if err := detector.Train(context.Background(), data); err != nil {
+			t.Fatalf("%s: training failed: %v", name, err)
+		}
+		first := detector.LastTraining()
+
+		// Training again carries on from the network the first run left
+		if err := detector.Configure(map[string]interface{}{"epochs": 30}); err != nil {
+			t.Fatalf("Configure failed: %v", err)
+		}
+		if err := detector.Train(context.Background(), data); err != nil {
+			t.Fatalf("%s: training failed: %v", name, err)
+		}
+		last := detector.LastTraining()
+		if last.Optimizer != name || last.Epochs != 30 || last.Loss >= first.Loss {
+			t.Errorf("%s: expected the loss to fall below %.5f over 30 more epochs, got %+v", name, first.Loss, last)
+		}
+	}
+}
+
+// countingOptimizer counts the steps of the optimizer it wraps
+type countingOptimizer struct {
+	Optimizer
+	steps int
+}
+
+func (o *countingOptimizer) Step(params, grads [][]float64, learningRate float64) {
+	o.steps++
+	o.Optimizer.Step(params, grads, learningRate)
+}
+
+func TestBatchingCoversEpoch(t *testing.T) {
+	// 200 points in windows of 10 make 190 samples
+	cases := map[int]int{1: 190, 16: 12, 95: 2, 500: 1}
+	for batchSize, steps := range cases {
+		detector := newTestDetector(t, map[string]interface{}{"batch_size": batchSize, "epochs": 2})
+		counter := &countingOptimizer{Optimizer: detector.optimizer}
+		detector.optimizer = counter
+		if err := detector.Train(context.Background(), []*analyzers.TimeSeries{sineSeries(200)}); err != nil {
+			t.Fatalf("Training failed: %v", err)
+		}
+		if counter.steps != 2*steps {
+			t.Errorf("Expected batches of %d to take %d steps an epoch, the last with the remaining samples, got %d over 2 epochs", batchSize, steps, counter.steps)
+		}
+	}
+}
+
+func TestBatchStepAveragesGradients(t *testing.T) {
+	detector := newTestDetector(t, map[string]interface{}{"optimizer": OptimizerSGD, "learning_rate": 1.0})
+	params := [][]float64{{1, 1}}
+	grads := [][]float64{{3, -6}} // summed over 3 samples
+	detector.step(params, grads, 3)
+	if params[0][0] != 0 || params[0][1] != 3 {
+		t.Errorf("Expected the mean gradient applied, got %v", params[0])
+	}
+	if grads[0][0] != 0 || grads[0][1] != 0 {
+		t.Errorf("Expected the gradients cleared for the next batch, got %v", grads[0])
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ruvnet/alienator/internal/analyzers"
+	"github.com/ruvnet/alienator/pkg/metrics"
 )
 
 // Pattern represents a detected pattern
@@ -19,6 +20,8 @@ type Pattern struct {
 	Confidence  float64               `json:"confidence"`
 	StartTime   time.Time             `json:"start_time"`
 	EndTime     time.Time             `json:"end_time"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	Revision    uint64                `json:"revision"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -44,6 +47,19 @@ type Matcher struct {
 	minPatternLength int
 	maxPatternLength int
 	similarityThreshold float64
+	// revision is bumped on every registry change and stamped on the changed
+	// pattern, giving clients a cursor for GetPatternsDelta
+	revision         uint64
+	// Bounds on the registry, which would otherwise grow for as long as the
+	// matcher runs
+	limits           RegistryLimits
+	// The latest removals, oldest first, and the revision of the newest one
+	// dropped from them
+	removals         []PatternRemoval
+	removalsFloor    uint64
+	expiredPatterns  int
+	evictedPatterns  int
+	metrics          *metrics.Metrics
 	mu               sync.RWMutex
 }
 
@@ -62,6 +78,7 @@ func NewMatcher(config *analyzers.Configuration) (*Matcher, error) {
 		minPatternLength:    3,
 		maxPatternLength:    20,
 		similarityThreshold: 0.8,
+		limits:              DefaultRegistryLimits(),
 	}
 
 	return matcher, nil
@@ -111,6 +128,8 @@ func (m *Matcher) Analyze(ctx context.Context, data *analyzers.TimeSeries) (*ana
 
 	duration := time.Since(start)
 
+	// Other analyses may be changing the registry
+	m.mu.RLock()
 	metadata := map[string]interface{}{
 		"patterns_detected":     len(detectedPatterns),
 		"patterns_total":        len(m.patterns),
@@ -118,8 +137,12 @@ func (m *Matcher) Analyze(ctx context.Context, data *analyzers.TimeSeries) (*ana
 		"min_pattern_length":    m.minPatternLength,
 		"max_pattern_length":    m.maxPatternLength,
 		"similarity_threshold":  m.similarityThreshold,
+		"patterns_cursor":       m.revision,
+		"patterns_expired":      m.expiredPatterns,
+		"patterns_evicted":      m.evictedPatterns,
 		"processing_time_ms":    duration.Milliseconds(),
 	}
+	m.mu.RUnlock()
 
 	// Add pattern details to metadata
 	patternDetails := make(map[string]interface{})
@@ -157,6 +180,25 @@ func (m *Matcher) Configure(config map[string]interface{}) error {
 		m.similarityThreshold = threshold
 	}
 
+	limits := m.limits
+	if maxPatterns, ok := config["max_patterns"].(int); ok {
+		limits.MaxPatterns = maxPatterns
+	}
+	if ttl, ok := config["pattern_ttl"].(string); ok {
+		duration, err := time.ParseDuration(ttl)
+		if err != nil {
+			return fmt.Errorf("invalid pattern_ttl %q: %w", ttl, err)
+		}
+		limits.TTL = duration
+	}
+	if policy, ok := config["eviction_policy"].(string); ok {
+		limits.EvictionPolicy = policy
+	}
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	m.limits = limits
+
 	if windowSize, ok := config["window_size"].(int); ok {
 		m.config.WindowSize = windowSize
 		// Resize buffers if needed
@@ -182,6 +224,7 @@ func (m *Matcher) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.patterns = make(map[string]*Pattern)
+	m.removals = nil
 	m.sequenceBuffer = nil
 	m.valueBuffer = nil
 	m.timestampBuffer = nil
@@ -202,6 +245,61 @@ func (m *Matcher) GetPatterns() map[string]*Pattern {
 	return result
 }
 
+// GetPatternsSince returns copies of the patterns added or updated after t;
+// GetRemovedSince returns those removed
+func (m *Matcher) GetPatternsSince(t time.Time) map[string]*Pattern {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string]*Pattern)
+	for k, v := range m.patterns {
+		if v.UpdatedAt.After(t) {
+			pattern := *v
+			result[k] = &pattern
+		}
+	}
+	return result
+}
+
+// GetPatternsDelta returns what changed in the registry since the given
+// cursor: copies of the patterns added or updated, and the patterns removed,
+// leaving out those detected again since. Start with cursor 0 to receive the
+// whole registry, then pass the returned cursor on the next call. Unlike
+// GetPatternsSince, it cannot miss changes made within the same clock tick
+// as the previous poll.
+func (m *Matcher) GetPatternsDelta(cursor uint64) PatternDelta {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	delta := PatternDelta{
+		Patterns: make(map[string]*Pattern),
+		Removed:  make([]PatternRemoval, 0),
+		Reset:    cursor == 0 || cursor < m.removalsFloor,
+		Cursor:   m.revision,
+	}
+	for k, v := range m.patterns {
+		if delta.Reset || v.Revision > cursor {
+			pattern := *v
+			delta.Patterns[k] = &pattern
+		}
+	}
+	if !delta.Reset {
+		for _, removal := range m.removals {
+			if _, ok := m.patterns[removal.ID]; !ok && removal.Revision > cursor {
+				delta.Removed = append(delta.Removed, removal)
+			}
+		}
+	}
+	return delta
+}
+
+// Cursor returns the current registry revision, for use with GetPatternsDelta
+func (m *Matcher) Cursor() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.revision
+}
+
 // updateBuffers adds new data points to the internal buffers
 func (m *Matcher) updateBuffers(dataPoints []analyzers.DataPoint) {
 	m.mu.Lock()
@@ -431,7 +529,7 @@ func (m *Matcher) detectSpikePatterns() []*Pattern {
 				Type:       PatternTypeSpike,
 				Sequence:   []interface{}{current},
 				Frequency:  1,
-				Confidence: min(zScore/5.0, 1.0), // Normalize to 0-1
+				Confidence: math.Min(zScore/5.0, 1.0), // Normalize to 0-1
 				StartTime:  m.timestampBuffer[i],
 				EndTime:    m.timestampBuffer[i],
 				Metadata: map[string]interface{}{
@@ -473,7 +571,7 @@ func (m *Matcher) detectDropPatterns() []*Pattern {
 				Type:       PatternTypeDrop,
 				Sequence:   []interface{}{current},
 				Frequency:  1,
-				Confidence: min(zScore/5.0, 1.0), // Normalize to 0-1
+				Confidence: math.Min(zScore/5.0, 1.0), // Normalize to 0-1
 				StartTime:  m.timestampBuffer[i],
 				EndTime:    m.timestampBuffer[i],
 				Metadata: map[string]interface{}{
@@ -495,6 +593,7 @@ func (m *Matcher) updatePatterns(newPatterns []*Pattern) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	now := time.Now()
 	for _, newPattern := range newPatterns {
 		// Check if similar pattern already exists
 		var existingPattern *Pattern
@@ -510,11 +609,24 @@ func (m *Matcher) updatePatterns(newPatterns []*Pattern) {
 			existingPattern.Frequency++
 			existingPattern.EndTime = newPattern.EndTime
 			existingPattern.Confidence = (existingPattern.Confidence + newPattern.Confidence) / 2
+			m.touch(existingPattern, now)
 		} else {
-			// Add new pattern
-			m.patterns[newPattern.ID] = newPattern
+			// Add a copy of the new pattern: the caller keeps reading the
+			// detection, which other analyses may update once registered
+			stored := *newPattern
+			m.touch(&stored, now)
+			m.patterns[stored.ID] = &stored
 		}
 	}
+
+	m.prunePatterns(now)
+}
+
+// touch marks a pattern as changed at the next registry revision
+func (m *Matcher) touch(pattern *Pattern, now time.Time) {
+	m.revision++
+	pattern.Revision = m.revision
+	pattern.UpdatedAt = now
 }
 
 // detectPatternAnomalies identifies anomalies based on pattern violations
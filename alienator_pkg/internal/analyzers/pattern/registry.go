@@ -0,0 +1,190 @@
+package pattern
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ruvnet/alienator/pkg/metrics"
+)
+
+// Eviction policies applied when the pattern registry is full
+const (
+	// EvictLeastRecentlyUsed removes the patterns seen longest ago
+	EvictLeastRecentlyUsed = "lru"
+	// EvictLeastFrequentlyUsed removes the patterns seen least often, the
+	// oldest first among equally frequent ones
+	EvictLeastFrequentlyUsed = "lfu"
+)
+
+// Reasons a pattern was removed from the registry
+const (
+	// RemovedExpired marks a pattern not seen within the TTL
+	RemovedExpired = "expired"
+	// RemovedEvicted marks a pattern evicted to keep the registry within
+	// its maximum size
+	RemovedEvicted = "evicted"
+)
+
+// PatternRemoval records a pattern removed from the registry, so that delta
+// queries report it. The latest MaxPatterns removals are kept.
+type PatternRemoval struct {
+	ID        string    `json:"id"`
+	Reason    string    `json:"reason"` // RemovedExpired or RemovedEvicted
+	RemovedAt time.Time `json:"removed_at"`
+	Revision  uint64    `json:"revision"`
+}
+
+// PatternDelta is what changed in the registry after a cursor
+type PatternDelta struct {
+	Patterns map[string]*Pattern `json:"patterns"` // added or updated
+	Removed  []PatternRemoval    `json:"removed"`  // oldest first
+	// Reset is set for cursor 0, or a cursor older than the removals kept:
+	// Patterns then holds the whole registry and replaces what the client
+	// had, and Removed is empty
+	Reset  bool   `json:"reset"`
+	Cursor uint64 `json:"cursor"` // to pass on the next call
+}
+
+// Registry bounds used unless configured otherwise
+const (
+	DefaultMaxPatterns = 1000
+	DefaultPatternTTL  = time.Hour
+)
+
+// RegistryLimits bounds the pattern registry of a long-running matcher
+type RegistryLimits struct {
+	MaxPatterns    int           // patterns kept; the excess is evicted by EvictionPolicy
+	TTL            time.Duration // patterns not seen for this long expire; zero disables expiry
+	EvictionPolicy string        // EvictLeastRecentlyUsed or EvictLeastFrequentlyUsed
+}
+
+// DefaultRegistryLimits returns the default registry bounds: 1000 patterns,
+// expiring after an hour unseen, least recently used evicted first
+func DefaultRegistryLimits() RegistryLimits {
+	return RegistryLimits{
+		MaxPatterns:    DefaultMaxPatterns,
+		TTL:            DefaultPatternTTL,
+		EvictionPolicy: EvictLeastRecentlyUsed,
+	}
+}
+
+// Validate checks the registry bounds
+func (l RegistryLimits) Validate() error {
+	if l.MaxPatterns <= 0 {
+		return fmt.Errorf("max patterns must be positive, got %d", l.MaxPatterns)
+	}
+	if l.TTL < 0 {
+		return fmt.Errorf("pattern TTL must not be negative, got %s", l.TTL)
+	}
+	switch l.EvictionPolicy {
+	case EvictLeastRecentlyUsed, EvictLeastFrequentlyUsed:
+		return nil
+	default:
+		return fmt.Errorf("unknown eviction policy %q (expected lru or lfu)", l.EvictionPolicy)
+	}
+}
+
+// SetRegistryLimits replaces the registry bounds; an over-full registry is
+// pruned on the next analysis
+func (m *Matcher) SetRegistryLimits(limits RegistryLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits = limits
+	return nil
+}
+
+// SetMetrics enables Prometheus metrics for the registry size and evictions
+func (m *Matcher) SetMetrics(metrics *metrics.Metrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = metrics
+}
+
+// RegistrySize returns the number of patterns in the registry
+func (m *Matcher) RegistrySize() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.patterns)
+}
+
+// GetRemovedSince returns the patterns removed from the registry after t,
+// oldest first, leaving out those detected again since. Only the latest
+// MaxPatterns removals are kept; GetPatternsDelta reports when some were
+// dropped.
+func (m *Matcher) GetRemovedSince(t time.Time) []PatternRemoval {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	removed := make([]PatternRemoval, 0)
+	for _, removal := range m.removals {
+		if _, ok := m.patterns[removal.ID]; !ok && removal.RemovedAt.After(t) {
+			removed = append(removed, removal)
+		}
+	}
+	return removed
+}
+
+// removePattern drops a pattern from the registry and records its removal
+// at the next registry revision. Callers must hold m.mu.
+func (m *Matcher) removePattern(id, reason string, now time.Time) {
+	delete(m.patterns, id)
+	m.revision++
+	m.removals = append(m.removals, PatternRemoval{ID: id, Reason: reason, RemovedAt: now, Revision: m.revision})
+}
+
+// prunePatterns expires patterns not seen within the TTL, then evicts by
+// policy until the registry fits, and drops the oldest removals beyond
+// MaxPatterns. Callers must hold m.mu.
+func (m *Matcher) prunePatterns(now time.Time) {
+	expired := 0
+	if m.limits.TTL > 0 {
+		for id, pattern := range m.patterns {
+			if now.Sub(pattern.UpdatedAt) > m.limits.TTL {
+				m.removePattern(id, RemovedExpired, now)
+				expired++
+			}
+		}
+	}
+
+	evicted := 0
+	if excess := len(m.patterns) - m.limits.MaxPatterns; excess > 0 {
+		candidates := make([]*Pattern, 0, len(m.patterns))
+		for _, pattern := range m.patterns {
+			candidates = append(candidates, pattern)
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			a, b := candidates[i], candidates[j]
+			if m.limits.EvictionPolicy == EvictLeastFrequentlyUsed && a.Frequency != b.Frequency {
+				return a.Frequency < b.Frequency
+			}
+			// Revisions order patterns by last update without clock ties
+			return a.Revision < b.Revision
+		})
+		for _, pattern := range candidates[:excess] {
+			m.removePattern(pattern.ID, RemovedEvicted, now)
+		}
+		evicted = excess
+	}
+
+	if excess := len(m.removals) - m.limits.MaxPatterns; excess > 0 {
+		m.removalsFloor = m.removals[excess-1].Revision
+		m.removals = append([]PatternRemoval(nil), m.removals[excess:]...)
+	}
+
+	m.expiredPatterns += expired
+	m.evictedPatterns += evicted
+	if m.metrics != nil {
+		m.metrics.UpdatePatternRegistrySize(float64(len(m.patterns)))
+		if expired > 0 {
+			m.metrics.RecordPatternEvictions("expired", expired)
+		}
+		if evicted > 0 {
+			m.metrics.RecordPatternEvictions("capacity", evicted)
+		}
+	}
+}
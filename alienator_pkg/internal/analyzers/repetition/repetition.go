@@ -0,0 +1,213 @@
+package repetition
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// RepetitionAnalyzer detects the repetition artifacts of degenerate sampling:
+// phrases of several words recurring far apart and loops repeating the same
+// span back to back. The linguistic analyzer's repetition score counts short
+// adjacent repeats, which weighs these failure modes no more than ordinary
+// phrasing.
+type RepetitionAnalyzer struct {
+	name string
+	// Phrase parameters
+	minPhraseLength         int     // shortest repeated phrase, in words
+	maxPhraseLength         int     // repeated phrases are grown up to this many words
+	phraseCoverageThreshold float64 // share of words in repeat occurrences scoring 1
+	// Loop parameters
+	maxLoopPeriod         int     // longest repeating span, in words
+	minLoopRepeats        int     // repetitions needed to call a span a loop
+	loopCoverageThreshold float64 // share of words in looped repetitions scoring 1
+	fullConfidenceAt      int     // words needed for full confidence
+	mu                    sync.RWMutex
+}
+
+// NewRepetitionAnalyzer creates a repetition analyzer looking for repeated
+// phrases of 5 to 10 words and loops of up to 50 words
+func NewRepetitionAnalyzer() *RepetitionAnalyzer {
+	return &RepetitionAnalyzer{
+		name:                    "repetition",
+		minPhraseLength:         5,
+		maxPhraseLength:         10,
+		phraseCoverageThreshold: 0.15,
+		maxLoopPeriod:           50,
+		minLoopRepeats:          3,
+		loopCoverageThreshold:   0.05,
+		fullConfidenceAt:        150,
+	}
+}
+
+// Name returns the analyzer name
+func (ra *RepetitionAnalyzer) Name() string {
+	return ra.name
+}
+
+// Description returns what the analyzer measures
+func (ra *RepetitionAnalyzer) Description() string {
+	return "Finds repeated phrases and looping output across the whole text"
+}
+
+// Parameters returns the options accepted by Configure
+func (ra *RepetitionAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "min_phrase_length", Type: models.ParameterInteger, Description: "Shortest repeated phrase counted, in tokens; at least 2", Default: 5},
+		{Name: "max_phrase_length", Type: models.ParameterInteger, Description: "Longest repeated phrase counted, in tokens; at least min_phrase_length", Default: 10},
+		{Name: "phrase_coverage_threshold", Type: models.ParameterNumber, Description: "Share of the text covered by repeated phrases that scores as anomalous, in (0, 1]", Default: 0.15},
+		{Name: "max_loop_period", Type: models.ParameterInteger, Description: "Longest loop looked for, in tokens", Default: 50},
+		{Name: "min_loop_repeats", Type: models.ParameterInteger, Description: "Fewest back-to-back repeats that make a loop; at least 2", Default: 3},
+		{Name: "loop_coverage_threshold", Type: models.ParameterNumber, Description: "Share of the text covered by loops that scores as anomalous, in (0, 1]", Default: 0.05},
+	}
+}
+
+// Configure updates the analyzer configuration
+func (ra *RepetitionAnalyzer) Configure(config map[string]interface{}) error {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	minLength, maxLength := ra.minPhraseLength, ra.maxPhraseLength
+	if length, ok := config["min_phrase_length"].(int); ok {
+		minLength = length
+	}
+	if length, ok := config["max_phrase_length"].(int); ok {
+		maxLength = length
+	}
+	if minLength < 2 {
+		return fmt.Errorf("min_phrase_length must be at least 2, got %d", minLength)
+	}
+	if maxLength < minLength {
+		return fmt.Errorf("max_phrase_length (%d) must be at least min_phrase_length (%d)", maxLength, minLength)
+	}
+	ra.minPhraseLength, ra.maxPhraseLength = minLength, maxLength
+
+	if period, ok := config["max_loop_period"].(int); ok {
+		if period <= 0 {
+			return fmt.Errorf("max_loop_period must be positive, got %d", period)
+		}
+		ra.maxLoopPeriod = period
+	}
+	if repeats, ok := config["min_loop_repeats"].(int); ok {
+		if repeats < 2 {
+			return fmt.Errorf("min_loop_repeats must be at least 2, got %d", repeats)
+		}
+		ra.minLoopRepeats = repeats
+	}
+	if threshold, ok := config["phrase_coverage_threshold"].(float64); ok {
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("phrase_coverage_threshold must be in (0, 1], got %f", threshold)
+		}
+		ra.phraseCoverageThreshold = threshold
+	}
+	if threshold, ok := config["loop_coverage_threshold"].(float64); ok {
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("loop_coverage_threshold must be in (0, 1], got %f", threshold)
+		}
+		ra.loopCoverageThreshold = threshold
+	}
+
+	return nil
+}
+
+// CanAnalyze reports whether the text has words enough for a phrase to
+// repeat: twice the shortest phrase length
+func (ra *RepetitionAnalyzer) CanAnalyze(tokens *tokenizer.Tokens) error {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+
+	if words, _ := tokens.NormalizedWords(); len(words) < 2*ra.minPhraseLength {
+		return fmt.Errorf("needs at least %d words, got %d", 2*ra.minPhraseLength, len(words))
+	}
+	return nil
+}
+
+// Analyze looks for repeated phrases and loops in the text
+func (ra *RepetitionAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return ra.AnalyzeTokens(ctx, tokenizer.New(tokenizer.Default, text))
+}
+
+// AnalyzeTokens looks for repeated phrases and loops in already tokenized text
+func (ra *RepetitionAnalyzer) AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+
+	words, original := tokens.NormalizedWords()
+	metadata := map[string]interface{}{
+		"word_count":    len(words),
+		"loop_detected": false,
+	}
+	if len(words) < 2*ra.minPhraseLength {
+		return &models.AnalysisResult{
+			Score:      0.0,
+			Confidence: 0.0,
+			Metadata:   metadata,
+		}, nil
+	}
+
+	// A loop must span at least two phrases, so that emphatic repeats such as
+	// "very, very, very" do not count
+	loops := FindLoops(words, ra.maxLoopPeriod, ra.minLoopRepeats, 2*ra.minPhraseLength)
+	loopedWords := 0
+	var longestLoop *Loop
+	for i := range loops {
+		loopedWords += loops[i].Length() - loops[i].Period
+		if longestLoop == nil || loops[i].Length() > longestLoop.Length() {
+			longestLoop = &loops[i]
+		}
+	}
+	loopCoverage := float64(loopedWords) / float64(len(words))
+	loopScore := math.Min(1.0, loopCoverage/ra.loopCoverageThreshold)
+
+	phrases, repeatedWords := FindRepeatedPhrases(words, ra.minPhraseLength, ra.maxPhraseLength)
+	var longestPhrase *Phrase
+	for i := range phrases {
+		if longestPhrase == nil || phrases[i].Length*phrases[i].Occurrences > longestPhrase.Length*longestPhrase.Occurrences {
+			longestPhrase = &phrases[i]
+		}
+	}
+	phraseCoverage := float64(repeatedWords) / float64(len(words))
+	phraseScore := math.Min(1.0, phraseCoverage/ra.phraseCoverageThreshold)
+
+	metadata["loops"] = len(loops)
+	metadata["loop_coverage"] = loopCoverage
+	metadata["loop_score"] = loopScore
+	metadata["repeated_phrases"] = len(phrases)
+	metadata["repeated_coverage"] = phraseCoverage
+	metadata["phrase_score"] = phraseScore
+	if longestLoop != nil {
+		metadata["loop_detected"] = true
+		metadata["loop_span"] = span(original, longestLoop.Start, longestLoop.Period)
+		metadata["loop_start"] = longestLoop.Start
+		metadata["loop_period"] = longestLoop.Period
+		metadata["loop_repeats"] = longestLoop.Repeats
+	}
+	if longestPhrase != nil {
+		metadata["repeated_span"] = span(original, longestPhrase.Start, longestPhrase.Length)
+		metadata["repeated_start"] = longestPhrase.Start
+		metadata["repeated_length"] = longestPhrase.Length
+		metadata["repeated_period"] = longestPhrase.Period
+		metadata["repeated_occurrences"] = longestPhrase.Occurrences
+	}
+
+	confidence := math.Min(1.0, float64(len(words))/float64(ra.fullConfidenceAt))
+	if tokens.NaiveWords() {
+		confidence *= tokenizer.NaiveWordsPenalty
+		metadata["naive_word_split"] = true
+	}
+	return &models.AnalysisResult{
+		Score:      math.Max(loopScore, phraseScore),
+		Confidence: confidence,
+		Metadata:   metadata,
+	}, nil
+}
+
+// span joins length original words from start
+func span(words []string, start, length int) string {
+	return strings.Join(words[start:start+length], " ")
+}
@@ -0,0 +1,143 @@
+package repetition
+
+import (
+	"sort"
+	"strings"
+)
+
+// Loop is a run of words repeating with a fixed period, the signature of
+// degenerate greedy or low-temperature decoding
+type Loop struct {
+	Start   int // index of the first word
+	Period  int // words in one repetition
+	Repeats int // whole repetitions of the period
+}
+
+// Length returns the number of words covered by the loop
+func (l Loop) Length() int {
+	return l.Period * l.Repeats
+}
+
+// Phrase is a phrase recurring later in the text
+type Phrase struct {
+	Start       int // index of the first word of the first occurrence
+	Length      int // words in the phrase
+	Period      int // words between the starts of the first two occurrences
+	Occurrences int // non-overlapping occurrences
+}
+
+// FindLoops returns the loops of words repeating with a period of at most
+// maxPeriod words, at least minRepeats times and over at least minWords
+// words, in order of position. Words already in a loop of a shorter period
+// are not reported again for its multiples.
+func FindLoops(words []string, maxPeriod, minRepeats, minWords int) []Loop {
+	covered := make([]bool, len(words))
+	var loops []Loop
+
+	for period := 1; period <= maxPeriod && period < len(words); period++ {
+		for i := 0; i+period < len(words); {
+			if words[i] != words[i+period] {
+				i++
+				continue
+			}
+
+			// words[i:end+period] repeats with this period
+			end := i
+			for end+period < len(words) && words[end] == words[end+period] {
+				end++
+			}
+			loop := Loop{Start: i, Period: period, Repeats: (end - i + period) / period}
+			if loop.Repeats >= minRepeats && loop.Length() >= minWords &&
+				!covered[loop.Start] && !covered[loop.Start+loop.Length()-1] {
+				loops = append(loops, loop)
+				for j := loop.Start; j < loop.Start+loop.Length(); j++ {
+					covered[j] = true
+				}
+			}
+			i = end + 1
+		}
+	}
+
+	sort.Slice(loops, func(a, b int) bool { return loops[a].Start < loops[b].Start })
+	return loops
+}
+
+// FindRepeatedPhrases returns the phrases of minLength to maxLength words
+// occurring more than once without overlapping, in order of first
+// occurrence, together with the number of words covered by repeat
+// occurrences. Phrases are grown from repeated minLength-grams as far as the
+// first two occurrences agree, so a recurring sentence fragment is reported
+// once rather than as each of its n-grams.
+func FindRepeatedPhrases(words []string, minLength, maxLength int) ([]Phrase, int) {
+	if minLength <= 0 || len(words) < 2*minLength {
+		return nil, 0
+	}
+
+	positions := make(map[string][]int)
+	var seeds []string
+	for i := 0; i+minLength <= len(words); i++ {
+		key := strings.Join(words[i:i+minLength], " ")
+		if _, ok := positions[key]; !ok {
+			seeds = append(seeds, key)
+		}
+		positions[key] = append(positions[key], i)
+	}
+
+	claimed := make([]bool, len(words))  // words of reported phrases
+	repeated := make([]bool, len(words)) // words of repeat occurrences
+	var phrases []Phrase
+
+	for _, key := range seeds {
+		occurrences := nonOverlapping(positions[key], minLength)
+		if len(occurrences) < 2 || claimed[occurrences[0]] {
+			continue
+		}
+
+		first, second := occurrences[0], occurrences[1]
+		length := minLength
+		for length < maxLength && first+length < second && second+length < len(words) &&
+			words[first+length] == words[second+length] {
+			length++
+		}
+
+		phrases = append(phrases, Phrase{
+			Start:       first,
+			Length:      length,
+			Period:      second - first,
+			Occurrences: len(occurrences),
+		})
+		for k, start := range occurrences {
+			// Later occurrences may share only part of the grown phrase
+			span := minLength
+			for span < length && start+span < len(words) && words[start+span] == words[first+span] {
+				span++
+			}
+			for j := start; j < start+span; j++ {
+				claimed[j] = true
+				if k > 0 {
+					repeated[j] = true
+				}
+			}
+		}
+	}
+
+	repeatedWords := 0
+	for _, r := range repeated {
+		if r {
+			repeatedWords++
+		}
+	}
+	return phrases, repeatedWords
+}
+
+// nonOverlapping keeps the positions of n-grams of the given length that do
+// not overlap an earlier kept one
+func nonOverlapping(positions []int, length int) []int {
+	kept := make([]int, 0, len(positions))
+	for _, position := range positions {
+		if len(kept) == 0 || position >= kept[len(kept)-1]+length {
+			kept = append(kept, position)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,150 @@
+package sentiment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MaxValence bounds the valence of a lexicon word in either direction
+const MaxValence = 5.0
+
+// Lexicon assigns a valence to emotive words, from -MaxValence (strongly
+// negative) to MaxValence (strongly positive). A negator among the three
+// words before an emotive word flips its valence. Contrasts are the phrases
+// introducing the other side of an argument, counted as the signature of a
+// pro/con structure. Words and phrases are matched in lower case.
+type Lexicon struct {
+	Valences  map[string]float64 `json:"valences"`
+	Negators  []string           `json:"negators"`
+	Contrasts []string           `json:"contrasts"`
+}
+
+// DefaultLexicon returns a small general-purpose English lexicon in the
+// style of AFINN
+func DefaultLexicon() Lexicon {
+	valences := make(map[string]float64, len(defaultPositive)+len(defaultNegative))
+	for word, valence := range defaultPositive {
+		valences[word] = valence
+	}
+	for word, valence := range defaultNegative {
+		valences[word] = -valence
+	}
+	return Lexicon{
+		Valences:  valences,
+		Negators:  append([]string(nil), defaultNegators...),
+		Contrasts: append([]string(nil), defaultContrasts...),
+	}
+}
+
+// LoadLexicon reads a JSON lexicon file
+func LoadLexicon(path string) (Lexicon, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return Lexicon{}, fmt.Errorf("failed to read lexicon file: %w", err)
+	}
+	var lexicon Lexicon
+	if err := json.Unmarshal(encoded, &lexicon); err != nil {
+		return Lexicon{}, fmt.Errorf("failed to parse lexicon file %s: %w", path, err)
+	}
+	if err := lexicon.Validate(); err != nil {
+		return Lexicon{}, fmt.Errorf("invalid lexicon file %s: %w", path, err)
+	}
+	return lexicon, nil
+}
+
+// Validate checks that the lexicon has words and that every valence is a
+// non-zero value within MaxValence
+func (l Lexicon) Validate() error {
+	if len(l.Valences) == 0 {
+		return errors.New("lexicon has no words")
+	}
+	for word, valence := range l.Valences {
+		if strings.TrimSpace(word) == "" {
+			return errors.New("lexicon words must not be empty")
+		}
+		if valence == 0 || valence < -MaxValence || valence > MaxValence {
+			return fmt.Errorf("valence of %q must be non-zero and within ±%g, got %g", word, MaxValence, valence)
+		}
+	}
+	return nil
+}
+
+// compiledLexicon is a lexicon keyed for lookups
+type compiledLexicon struct {
+	valences  map[string]float64
+	negators  map[string]bool
+	contrasts []string
+}
+
+func (l Lexicon) compile() compiledLexicon {
+	compiled := compiledLexicon{
+		valences: make(map[string]float64, len(l.Valences)),
+		negators: make(map[string]bool, len(l.Negators)),
+	}
+	for word, valence := range l.Valences {
+		compiled.valences[strings.ToLower(word)] = valence
+	}
+	for _, negator := range l.Negators {
+		compiled.negators[strings.ToLower(negator)] = true
+	}
+	for _, contrast := range l.Contrasts {
+		if contrast = strings.ToLower(strings.TrimSpace(contrast)); contrast != "" {
+			compiled.contrasts = append(compiled.contrasts, contrast)
+		}
+	}
+	return compiled
+}
+
+var defaultPositive = map[string]float64{
+	"good": 3, "great": 3, "excellent": 3, "amazing": 4, "awesome": 4, "wonderful": 4,
+	"fantastic": 4, "brilliant": 4, "love": 3, "loved": 3, "loves": 3, "liked": 2,
+	"enjoy": 2, "enjoyed": 2, "happy": 3, "glad": 3, "delighted": 3,
+	"pleased": 3, "excited": 3, "thrilled": 4, "proud": 2, "fun": 4, "beautiful": 3,
+	"best": 3, "better": 2, "nice": 3, "perfect": 3, "favorite": 2, "win": 4,
+	"won": 3, "success": 2, "successful": 3, "effective": 2, "efficient": 2, "helpful": 2,
+	"useful": 2, "valuable": 2, "benefit": 2, "benefits": 2, "advantage": 2, "advantages": 2,
+	"improve": 2, "improved": 2, "improvement": 2, "strong": 2, "strength": 2, "strengths": 2,
+	"positive": 2, "reliable": 2, "convenient": 2, "easy": 1, "impressive": 3, "recommend": 2,
+	"grateful": 3, "thanks": 2, "thank": 2, "hope": 2, "hopeful": 2, "lucky": 3,
+	"wow": 4, "cool": 1, "incredible": 4, "superb": 5, "outstanding": 5, "joy": 3,
+	"opportunity": 2, "opportunities": 2, "promising": 3, "secure": 2, "safe": 1, "fair": 2,
+	"laugh": 1, "laughed": 1, "laughing": 1, "funny": 4, "smile": 2, "smiled": 2,
+	"cute": 2, "sweet": 2, "cozy": 2, "delicious": 3, "tasty": 2, "relaxed": 2,
+	"relief": 1, "relieved": 2, "finally": 1, "yay": 3, "gorgeous": 3, "lovely": 3,
+}
+
+var defaultNegative = map[string]float64{
+	"bad": 3, "terrible": 3, "awful": 3, "horrible": 3, "worst": 3, "worse": 3,
+	"hate": 3, "hated": 3, "hates": 3, "dislike": 2, "sad": 2, "unhappy": 2,
+	"angry": 3, "annoyed": 2, "annoying": 2, "furious": 3, "upset": 2, "disappointed": 2,
+	"disappointing": 2, "frustrated": 2, "frustrating": 2, "boring": 3, "ugly": 3, "stupid": 2,
+	"fail": 2, "failed": 2, "failure": 2, "lose": 3, "lost": 3, "loss": 3,
+	"problem": 2, "problems": 2, "issue": 1, "issues": 1, "risk": 2, "risks": 2,
+	"disadvantage": 2, "disadvantages": 2, "drawback": 2, "drawbacks": 2, "downside": 2, "downsides": 2,
+	"weak": 2, "weakness": 2, "weaknesses": 2, "negative": 2, "difficult": 1, "hard": 1,
+	"expensive": 2, "costly": 2, "slow": 2, "broken": 1, "wrong": 2, "poor": 2,
+	"harm": 2, "harmful": 2, "dangerous": 2, "concern": 1, "concerns": 1, "worried": 3,
+	"worry": 3, "afraid": 2, "fear": 2, "pain": 2, "painful": 2, "sorry": 1,
+	"crap": 3, "ugh": 2, "damn": 2, "mess": 2, "disaster": 2, "useless": 2,
+	"forgot": 1, "bothered": 2, "judgmental": 2, "tired": 2, "exhausted": 2, "stuck": 2,
+	"lazy": 1, "mad": 3, "cried": 2, "scared": 2, "awkward": 2, "embarrassed": 2,
+	"messy": 2, "sick": 2, "ruined": 2, "hell": 4, "crazy": 2, "missing": 2,
+	"late": 1, "lonely": 2, "miserable": 3, "stressed": 2, "stressful": 2, "rude": 2,
+}
+
+var defaultNegators = []string{
+	"not", "no", "never", "neither", "nor", "without", "hardly", "barely",
+	"don't", "doesn't", "didn't", "isn't", "aren't", "wasn't", "weren't", "won't",
+	"can't", "cannot", "couldn't", "shouldn't", "wouldn't", "dont", "doesnt", "didnt",
+	"isnt", "wasnt", "cant",
+}
+
+var defaultContrasts = []string{
+	"however", "on the other hand", "on one hand", "that said", "conversely",
+	"nevertheless", "nonetheless", "in contrast", "pros and cons",
+	"advantages and disadvantages", "benefits and drawbacks", "while some", "others argue",
+	"it is important to consider", "it's important to consider", "both sides",
+}
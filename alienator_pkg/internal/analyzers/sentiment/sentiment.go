@@ -0,0 +1,290 @@
+package sentiment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// negationWindow is how many words before an emotive word a negator flips it
+const negationWindow = 3
+
+// SentimentAnalyzer flags a tone that is more even than people write in.
+// Each sentence is scored with a valence lexicon and classed positive,
+// negative or neutral. Text that is almost entirely neutral, or whose
+// positive and negative sentences cancel out in a pro/con structure, is
+// typical of assistant output. Both are weak signals on their own: plenty of
+// human writing is dry or weighs both sides, so the analyzer carries little
+// weight in the ensemble.
+type SentimentAnalyzer struct {
+	name               string
+	lexicon            compiledLexicon
+	neutralBaseline    float64 // share of neutral sentences usual in human writing
+	minSentences       int     // below this many sentences nothing is scored
+	minPolarSentences  int     // positive and negative sentences needed to judge balance
+	detectionThreshold float64 // score at which a pattern is reported
+	fullConfidenceAt   int     // sentences needed for full confidence
+	mu                 sync.RWMutex
+}
+
+// NewSentimentAnalyzer creates a sentiment analyzer using the default lexicon
+func NewSentimentAnalyzer() *SentimentAnalyzer {
+	return &SentimentAnalyzer{
+		name:               "sentiment",
+		lexicon:            DefaultLexicon().compile(),
+		neutralBaseline:    0.6,
+		minSentences:       5,
+		minPolarSentences:  4,
+		detectionThreshold: 0.7,
+		fullConfidenceAt:   30,
+	}
+}
+
+// Name returns the analyzer name
+func (sa *SentimentAnalyzer) Name() string {
+	return sa.name
+}
+
+// Description returns what the analyzer measures
+func (sa *SentimentAnalyzer) Description() string {
+	return "Measures the distribution of sentence sentiment, flagging unnatural neutrality and evenly balanced pro/con structure"
+}
+
+// Parameters returns the options accepted by Configure
+func (sa *SentimentAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "lexicon_file", Type: models.ParameterString, Description: "JSON valence lexicon file replacing the built-in one", Default: nil},
+		{Name: "neutral_baseline", Type: models.ParameterNumber, Description: "Share of neutral sentences usual in human writing, in [0, 1); only neutrality beyond it scores", Default: 0.6},
+		{Name: "min_sentences", Type: models.ParameterInteger, Description: "Fewest sentences needed to score the text", Default: 5},
+		{Name: "min_polar_sentences", Type: models.ParameterInteger, Description: "Fewest positive and negative sentences together needed to judge their balance; at least 2", Default: 4},
+		{Name: "detection_threshold", Type: models.ParameterNumber, Description: "Score at or above which neutral tone or balanced structure is reported, in (0, 1]", Default: 0.7},
+	}
+}
+
+// SetLexicon replaces the valence lexicon
+func (sa *SentimentAnalyzer) SetLexicon(lexicon Lexicon) error {
+	if err := lexicon.Validate(); err != nil {
+		return fmt.Errorf("invalid sentiment lexicon: %w", err)
+	}
+
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	sa.lexicon = lexicon.compile()
+	return nil
+}
+
+// ValidateHealth checks that the lexicon has emotive words to score with
+func (sa *SentimentAnalyzer) ValidateHealth(ctx context.Context) error {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+
+	if len(sa.lexicon.valences) == 0 {
+		return errors.New("sentiment lexicon has no words")
+	}
+	return nil
+}
+
+// Configure updates the analyzer configuration. "lexicon_file" replaces the
+// default lexicon with that of a lexicon file. Every setting is checked
+// before any is applied, so a rejected configuration changes nothing.
+func (sa *SentimentAnalyzer) Configure(config map[string]interface{}) error {
+	var lexicon *compiledLexicon
+	if path, ok := config["lexicon_file"].(string); ok && path != "" {
+		loaded, err := LoadLexicon(path)
+		if err != nil {
+			return err
+		}
+		compiled := loaded.compile()
+		lexicon = &compiled
+	}
+
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	baseline, minSentences, minPolarSentences, threshold := sa.neutralBaseline, sa.minSentences, sa.minPolarSentences, sa.detectionThreshold
+	if value, ok := config["neutral_baseline"].(float64); ok {
+		if value < 0 || value >= 1 {
+			return fmt.Errorf("neutral_baseline must be in [0, 1), got %f", value)
+		}
+		baseline = value
+	}
+	if value, ok := config["min_sentences"].(int); ok {
+		if value < 1 {
+			return fmt.Errorf("min_sentences must be positive, got %d", value)
+		}
+		minSentences = value
+	}
+	if value, ok := config["min_polar_sentences"].(int); ok {
+		if value < 2 {
+			return fmt.Errorf("min_polar_sentences must be at least 2, got %d", value)
+		}
+		minPolarSentences = value
+	}
+	if value, ok := config["detection_threshold"].(float64); ok {
+		if value <= 0 || value > 1 {
+			return fmt.Errorf("detection_threshold must be in (0, 1], got %f", value)
+		}
+		threshold = value
+	}
+
+	if lexicon != nil {
+		sa.lexicon = *lexicon
+	}
+	sa.neutralBaseline, sa.minSentences, sa.minPolarSentences, sa.detectionThreshold = baseline, minSentences, minPolarSentences, threshold
+	return nil
+}
+
+// CanAnalyze reports whether the text has sentences enough to be scored
+func (sa *SentimentAnalyzer) CanAnalyze(tokens *tokenizer.Tokens) error {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+
+	if sentences := len(tokens.CleanSentenceWords()); sentences < sa.minSentences {
+		return fmt.Errorf("needs at least %d sentences, got %d", sa.minSentences, sentences)
+	}
+	return nil
+}
+
+// Analyze scores the sentiment of each sentence of the text
+func (sa *SentimentAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return sa.AnalyzeTokens(ctx, tokenizer.New(tokenizer.Default, text))
+}
+
+// AnalyzeTokens scores the sentiment of each sentence of already tokenized text
+func (sa *SentimentAnalyzer) AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	sa.mu.RLock()
+	defer sa.mu.RUnlock()
+
+	sentences := tokens.CleanSentenceWords()
+	metadata := map[string]interface{}{
+		"sentence_count":     len(sentences),
+		"neutral_tone":       false,
+		"balanced_structure": false,
+	}
+	if len(sentences) < sa.minSentences {
+		return &models.AnalysisResult{
+			Score:      0.0,
+			Confidence: 0.0,
+			Metadata:   metadata,
+		}, nil
+	}
+
+	var positive, negative, neutral, emotive, words int
+	var positiveMass, negativeMass float64
+	polarities := make([]float64, len(sentences))
+	var cleaned []string
+	for i, sentenceWords := range sentences {
+		cleaned = append(cleaned, sentenceWords...)
+		words += len(sentenceWords)
+
+		polarity, hits := sa.sentencePolarity(sentenceWords)
+		polarities[i] = polarity
+		emotive += hits
+		switch {
+		case polarity > 0:
+			positive++
+			positiveMass += polarity
+		case polarity < 0:
+			negative++
+			negativeMass -= polarity
+		default:
+			neutral++
+		}
+	}
+
+	n := float64(len(sentences))
+	neutralFraction := float64(neutral) / n
+	neutrality := math.Max(0, (neutralFraction-sa.neutralBaseline)/(1-sa.neutralBaseline))
+
+	// Balance is the share of sentiment cancelled out between the two sides,
+	// counted in full only when the text also signals the contrast
+	contrasts := sa.countContrasts(cleaned)
+	balance := 0.0
+	if positive > 0 && negative > 0 && positive+negative >= sa.minPolarSentences {
+		evenness := 1 - math.Abs(positiveMass-negativeMass)/(positiveMass+negativeMass)
+		structure := math.Min(1, float64(contrasts)/2)
+		balance = evenness * (0.5 + 0.5*structure)
+	}
+
+	mean, stddev := meanAndStdDev(polarities)
+	metadata["positive_sentences"] = positive
+	metadata["negative_sentences"] = negative
+	metadata["neutral_sentences"] = neutral
+	metadata["positive_fraction"] = float64(positive) / n
+	metadata["negative_fraction"] = float64(negative) / n
+	metadata["neutral_fraction"] = neutralFraction
+	metadata["mean_polarity"] = mean
+	metadata["polarity_stddev"] = stddev
+	metadata["contrast_markers"] = contrasts
+	metadata["neutrality_score"] = neutrality
+	metadata["balance_score"] = balance
+	metadata["neutral_tone"] = neutrality >= sa.detectionThreshold
+	metadata["balanced_structure"] = balance >= sa.detectionThreshold
+	if words > 0 {
+		metadata["emotive_density"] = float64(emotive) / float64(words)
+	}
+
+	return &models.AnalysisResult{
+		Score:      math.Min(1, math.Max(neutrality, balance)),
+		Confidence: math.Min(1.0, n/float64(sa.fullConfidenceAt)),
+		Metadata:   metadata,
+	}, nil
+}
+
+// sentencePolarity sums the valences of the emotive words of a sentence,
+// flipping those shortly after a negator, and counts the emotive words
+func (sa *SentimentAnalyzer) sentencePolarity(words []string) (float64, int) {
+	polarity := 0.0
+	hits := 0
+	lastNegator := -negationWindow - 1
+	for i, word := range words {
+		if sa.lexicon.negators[word] || strings.HasSuffix(word, "n't") {
+			lastNegator = i
+			continue
+		}
+		valence, ok := sa.lexicon.valences[word]
+		if !ok {
+			continue
+		}
+		if i-lastNegator <= negationWindow {
+			valence = -valence
+		}
+		polarity += valence
+		hits++
+	}
+	return polarity, hits
+}
+
+// countContrasts counts the contrast phrases among the words
+func (sa *SentimentAnalyzer) countContrasts(words []string) int {
+	if len(sa.lexicon.contrasts) == 0 {
+		return 0
+	}
+	joined := " " + strings.Join(words, " ") + " "
+	count := 0
+	for _, contrast := range sa.lexicon.contrasts {
+		count += strings.Count(joined, " "+contrast+" ")
+	}
+	return count
+}
+
+func meanAndStdDev(values []float64) (float64, float64) {
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		diff := value - mean
+		variance += diff * diff
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
@@ -0,0 +1,239 @@
+// Package template finds the fixed scaffolding that texts mass-generated
+// from one template share. Unlike the other analyzers it scores a batch of
+// texts together: a span of words is part of a template when it recurs in
+// enough of the batch's items, and each item scores by how much of it such
+// spans cover, leaving only the variable slots filled in.
+package template
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// Slot marks the variable parts of an item in TemplateScore.Template
+const Slot = "{}"
+
+// TemplateAnalyzer scores the items of a batch by how templated they are
+type TemplateAnalyzer struct {
+	name              string
+	spanLength        int     // words in the spans templates are built from
+	minSupport        float64 // share of the items a span must recur in
+	minItems          int     // fewest items a template is looked for in
+	coverageThreshold float64 // share of an item's words in template spans scoring 1
+	mu                sync.RWMutex
+}
+
+// TemplateScore is how templated one item of a batch is. Coverage is the
+// share of its words in spans recurring across the batch, Spans are those
+// spans as the item words them, and Template is the item with each run of
+// the words in between, its variable slots, replaced by Slot.
+type TemplateScore struct {
+	Index     int      `json:"index"`
+	Score     float64  `json:"score"`
+	Templated bool     `json:"templated"`
+	Coverage  float64  `json:"coverage"`
+	Slots     int      `json:"slots"`
+	Spans     []string `json:"spans,omitempty"`
+	Template  string   `json:"template,omitempty"`
+}
+
+// NewTemplateAnalyzer creates a template analyzer building templates from
+// spans of 4 words recurring in half the items of batches of 3 or more
+func NewTemplateAnalyzer() *TemplateAnalyzer {
+	return &TemplateAnalyzer{
+		name:              "template",
+		spanLength:        4,
+		minSupport:        0.5,
+		minItems:          3,
+		coverageThreshold: 0.5,
+	}
+}
+
+// Name returns the analyzer name
+func (ta *TemplateAnalyzer) Name() string {
+	return ta.name
+}
+
+// Description returns what the analyzer measures
+func (ta *TemplateAnalyzer) Description() string {
+	return "Finds scaffolding shared across a batch of texts, as filled in from one template"
+}
+
+// Parameters returns the options accepted by Configure
+func (ta *TemplateAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "span_length", Type: models.ParameterInteger, Description: "Words in the spans templates are built from; at least 2", Default: 4},
+		{Name: "min_support", Type: models.ParameterNumber, Description: "Share of the items a span must recur in to belong to a template, in (0, 1]; always at least 2 items", Default: 0.5},
+		{Name: "min_items", Type: models.ParameterInteger, Description: "Fewest items in a batch to look for a template in; at least 2", Default: 3},
+		{Name: "coverage_threshold", Type: models.ParameterNumber, Description: "Share of an item covered by template spans that scores as templated, in (0, 1]", Default: 0.5},
+	}
+}
+
+// Configure updates the analyzer configuration
+func (ta *TemplateAnalyzer) Configure(config map[string]interface{}) error {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+
+	if length, ok := integer(config["span_length"]); ok {
+		if length < 2 {
+			return fmt.Errorf("span_length must be at least 2, got %d", length)
+		}
+		ta.spanLength = length
+	}
+	if support, ok := config["min_support"].(float64); ok {
+		if support <= 0 || support > 1 {
+			return fmt.Errorf("min_support must be in (0, 1], got %f", support)
+		}
+		ta.minSupport = support
+	}
+	if items, ok := integer(config["min_items"]); ok {
+		if items < 2 {
+			return fmt.Errorf("min_items must be at least 2, got %d", items)
+		}
+		ta.minItems = items
+	}
+	if threshold, ok := config["coverage_threshold"].(float64); ok {
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("coverage_threshold must be in (0, 1], got %f", threshold)
+		}
+		ta.coverageThreshold = threshold
+	}
+	return nil
+}
+
+// integer returns value as an int, accepting whole numbers decoded as
+// float64 from JSON
+func integer(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), true
+		}
+	}
+	return 0, false
+}
+
+// DetectTemplates scores every item by how templated it is within items,
+// in order
+func (ta *TemplateAnalyzer) DetectTemplates(items []string) []TemplateScore {
+	batch := ta.NewBatch()
+	for _, item := range items {
+		batch.Add(item)
+	}
+	scores := make([]TemplateScore, len(items))
+	for i, item := range items {
+		scores[i] = batch.Score(i, item)
+	}
+	return scores
+}
+
+// DetectTemplates scores every item by how templated it is within items,
+// with the default settings
+func DetectTemplates(items []string) []TemplateScore {
+	return NewTemplateAnalyzer().DetectTemplates(items)
+}
+
+// Batch gathers the spans of a batch's items one item at a time, so that
+// the items need not be held in memory together. Every item is added before
+// any is scored; scoring is then safe for concurrent use.
+type Batch struct {
+	spanLength        int
+	minSupport        float64
+	minItems          int
+	coverageThreshold float64
+	items             int
+	counts            map[string]int // items each span occurs in
+}
+
+// NewBatch returns an empty batch with the analyzer's current settings
+func (ta *TemplateAnalyzer) NewBatch() *Batch {
+	ta.mu.RLock()
+	defer ta.mu.RUnlock()
+	return &Batch{
+		spanLength:        ta.spanLength,
+		minSupport:        ta.minSupport,
+		minItems:          ta.minItems,
+		coverageThreshold: ta.coverageThreshold,
+		counts:            make(map[string]int),
+	}
+}
+
+// Add counts the spans of one item of the batch
+func (b *Batch) Add(text string) {
+	b.items++
+	words, _ := tokenizer.New(tokenizer.Default, text).NormalizedWords()
+	seen := make(map[string]bool)
+	for i := 0; i+b.spanLength <= len(words); i++ {
+		key := strings.Join(words[i:i+b.spanLength], " ")
+		if !seen[key] {
+			seen[key] = true
+			b.counts[key]++
+		}
+	}
+}
+
+// Items returns the number of items added
+func (b *Batch) Items() int {
+	return b.items
+}
+
+// Score scores one item added to the batch, reporting it under index
+func (b *Batch) Score(index int, text string) TemplateScore {
+	score := TemplateScore{Index: index}
+	words, original := tokenizer.New(tokenizer.Default, text).NormalizedWords()
+	if b.items < b.minItems || len(words) < b.spanLength {
+		return score
+	}
+
+	support := int(math.Ceil(b.minSupport * float64(b.items)))
+	if support < 2 {
+		support = 2
+	}
+	covered := make([]bool, len(words))
+	for i := 0; i+b.spanLength <= len(words); i++ {
+		if b.counts[strings.Join(words[i:i+b.spanLength], " ")] < support {
+			continue
+		}
+		for j := i; j < i+b.spanLength; j++ {
+			covered[j] = true
+		}
+	}
+
+	var template []string
+	coveredWords := 0
+	for start := 0; start < len(words); {
+		end := start
+		for end < len(words) && covered[end] == covered[start] {
+			end++
+		}
+		if covered[start] {
+			span := strings.Join(original[start:end], " ")
+			score.Spans = append(score.Spans, span)
+			template = append(template, span)
+			coveredWords += end - start
+		} else {
+			score.Slots++
+			template = append(template, Slot)
+		}
+		start = end
+	}
+	if coveredWords == 0 {
+		score.Slots = 0
+		return score
+	}
+
+	score.Coverage = float64(coveredWords) / float64(len(words))
+	score.Score = math.Min(1, score.Coverage/b.coverageThreshold)
+	score.Templated = score.Coverage >= b.coverageThreshold
+	score.Template = strings.Join(template, " ")
+	return score
+}
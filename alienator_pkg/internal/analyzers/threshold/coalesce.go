@@ -0,0 +1,156 @@
+package threshold
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/analyzers"
+)
+
+// boundBreachKey groups violations of the configured threshold bounds, which
+// are coalesced together whichever band (warning, critical, ...) was crossed
+const boundBreachKey = "bound"
+
+// breach is a run of consecutive violations of one rule being coalesced into
+// a single anomaly
+type breach struct {
+	anomaly analyzers.Anomaly
+	start   time.Time
+	end     time.Time
+	points  int
+}
+
+// breachTracker coalesces consecutive violations of the same rule into one
+// anomaly per sustained breach. A breach ends when the rule is next evaluated
+// without a violation or when the gap between two violations exceeds the
+// window. A zero window disables coalescing: every violation is reported as
+// its own anomaly.
+type breachTracker struct {
+	window time.Duration
+	open   map[string]*breach
+}
+
+func newBreachTracker(window time.Duration) *breachTracker {
+	return &breachTracker{
+		window: window,
+		open:   make(map[string]*breach),
+	}
+}
+
+// extends reports whether a violation of key at timestamp would continue the
+// open breach rather than start a new one
+func (t *breachTracker) extends(key string, timestamp time.Time) bool {
+	if t.window <= 0 {
+		return false
+	}
+	b, ok := t.open[key]
+	return ok && timestamp.Sub(b.end) <= t.window
+}
+
+// observe records a violation of key and returns any anomalies that are now
+// complete: the violation itself when coalescing is disabled, or the previous
+// breach when this violation comes too late to extend it
+func (t *breachTracker) observe(key string, anomaly *analyzers.Anomaly) []analyzers.Anomaly {
+	if t.window <= 0 {
+		return []analyzers.Anomaly{*anomaly}
+	}
+
+	if t.extends(key, anomaly.Timestamp) {
+		t.open[key].add(anomaly)
+		return nil
+	}
+
+	completed := t.close(key)
+	t.open[key] = &breach{
+		anomaly: *anomaly,
+		start:   anomaly.Timestamp,
+		end:     anomaly.Timestamp,
+		points:  1,
+	}
+	return completed
+}
+
+// close ends the open breach of key, if any, and returns its anomaly
+func (t *breachTracker) close(key string) []analyzers.Anomaly {
+	b, ok := t.open[key]
+	if !ok {
+		return nil
+	}
+	delete(t.open, key)
+	return []analyzers.Anomaly{b.finish()}
+}
+
+// flush ends every open breach, returning their anomalies in start order
+func (t *breachTracker) flush() []analyzers.Anomaly {
+	anomalies := make([]analyzers.Anomaly, 0, len(t.open))
+	for key := range t.open {
+		anomalies = append(anomalies, t.close(key)...)
+	}
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].Timestamp.Before(anomalies[j].Timestamp)
+	})
+	return anomalies
+}
+
+// add extends the breach with a later violation. The anomaly keeps its start
+// time but takes the value, score and message of the worst point, and the
+// highest severity seen.
+func (b *breach) add(anomaly *analyzers.Anomaly) {
+	b.end = anomaly.Timestamp
+	b.points++
+
+	if severityRank(anomaly.Severity) > severityRank(b.anomaly.Severity) {
+		b.anomaly.Severity = anomaly.Severity
+	}
+	// Scores saturate, so ties go to the point furthest past the threshold
+	if anomaly.Score > b.anomaly.Score ||
+		(anomaly.Score == b.anomaly.Score && deviation(anomaly) > deviation(&b.anomaly)) {
+		b.anomaly.Score = anomaly.Score
+		b.anomaly.Value = anomaly.Value
+		b.anomaly.Expected = anomaly.Expected
+		b.anomaly.Message = anomaly.Message
+	}
+}
+
+// finish returns the coalesced anomaly annotated with the breach extent
+func (b *breach) finish() analyzers.Anomaly {
+	anomaly := b.anomaly
+	metadata := make(map[string]interface{}, len(anomaly.Metadata)+6)
+	for k, v := range anomaly.Metadata {
+		metadata[k] = v
+	}
+	metadata["coalesced"] = true
+	metadata["start_time"] = b.start
+	metadata["end_time"] = b.end
+	metadata["duration"] = b.end.Sub(b.start)
+	metadata["peak_value"] = anomaly.Value
+	metadata["point_count"] = b.points
+	anomaly.Metadata = metadata
+	return anomaly
+}
+
+// deviation is how far the anomaly's value lies from the threshold it crossed
+func deviation(anomaly *analyzers.Anomaly) float64 {
+	value, ok := anomaly.Value.(float64)
+	expected, ok2 := anomaly.Expected.(float64)
+	if !ok || !ok2 {
+		return 0
+	}
+	return math.Abs(value - expected)
+}
+
+func severityRank(severity analyzers.Severity) int {
+	switch severity {
+	case analyzers.SeverityLow:
+		return 1
+	case analyzers.SeverityMedium:
+		return 2
+	case analyzers.SeverityHigh:
+		return 3
+	case analyzers.SeverityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
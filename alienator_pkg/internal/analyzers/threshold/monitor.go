@@ -2,12 +2,16 @@ package threshold
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"sync"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
 	"github.com/ruvnet/alienator/internal/analyzers"
+	"github.com/ruvnet/alienator/internal/hashing"
 )
 
 // ThresholdRule represents a threshold monitoring rule
@@ -58,6 +62,12 @@ type Monitor struct {
 	adaptiveMode   bool
 	statistics     *analyzers.Statistics
 	valueHistory   []float64
+	// Consecutive violations of a rule no further apart than this are
+	// reported as one anomaly; zero reports every violating point
+	coalesceWindow time.Duration
+	// Concurrent analyses of the same series, keyed by its content hash,
+	// share one evaluation
+	flights        singleflight.Group
 	mu             sync.RWMutex
 }
 
@@ -99,8 +109,38 @@ func (m *Monitor) Type() analyzers.AnomalyType {
 	return analyzers.AnomalyTypeThreshold
 }
 
-// Analyze performs threshold-based anomaly detection
+// Analyze performs threshold-based anomaly detection. Identical series
+// analyzed concurrently are evaluated once, so that a sustained breach
+// submitted by several callers at the same time is reported, and counted
+// against its rules, once; every caller gets the same result.
 func (m *Monitor) Analyze(ctx context.Context, data *analyzers.TimeSeries) (*analyzers.AnalysisResult, error) {
+	key, err := seriesKey(data)
+	if err != nil {
+		return nil, err
+	}
+	result, err, _ := m.flights.Do(key, func() (interface{}, error) {
+		return m.analyze(data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*analyzers.AnalysisResult), nil
+}
+
+// seriesKey returns the content hash of a series' name and points
+func seriesKey(data *analyzers.TimeSeries) (string, error) {
+	content, err := json.Marshal(struct {
+		Name       string                `json:"name"`
+		DataPoints []analyzers.DataPoint `json:"data_points"`
+	}{data.Name, data.DataPoints})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash series: %w", err)
+	}
+	return hashing.Sum(content), nil
+}
+
+// analyze evaluates a series for Analyze
+func (m *Monitor) analyze(data *analyzers.TimeSeries) (*analyzers.AnalysisResult, error) {
 	start := time.Now()
 
 	if len(data.DataPoints) < m.config.MinDataPoints {
@@ -144,6 +184,7 @@ func (m *Monitor) Analyze(ctx context.Context, data *analyzers.TimeSeries) (*ana
 		"threshold_config":     m.thresholdConfig,
 		"statistics":           m.statistics,
 		"history_size":         len(m.valueHistory),
+		"coalesce_window":      m.coalesceWindow.String(),
 		"processing_time_ms":   duration.Milliseconds(),
 	}
 
@@ -175,6 +216,17 @@ func (m *Monitor) Configure(config map[string]interface{}) error {
 		m.adaptiveMode = adaptiveMode
 	}
 
+	if window, ok := config["coalesce_window"].(string); ok {
+		duration, err := time.ParseDuration(window)
+		if err != nil {
+			return fmt.Errorf("invalid coalesce_window %q: %w", window, err)
+		}
+		if duration < 0 {
+			return fmt.Errorf("coalesce_window must not be negative, got %s", window)
+		}
+		m.coalesceWindow = duration
+	}
+
 	if thresholds, ok := config["thresholds"].(map[string]interface{}); ok {
 		if upper, ok := thresholds["upper"].(float64); ok {
 			m.thresholdConfig.UpperBound = &upper
@@ -256,6 +308,17 @@ func (m *Monitor) GetRules() map[string]*ThresholdRule {
 	return result
 }
 
+// SetCoalesceWindow sets how far apart consecutive violations of the same rule
+// may be and still be reported as a single sustained-breach anomaly, with its
+// start_time, end_time, duration and peak_value in the metadata. Zero (the
+// default) reports one anomaly per violating point. Breaches are coalesced
+// within each analyzed series.
+func (m *Monitor) SetCoalesceWindow(window time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.coalesceWindow = window
+}
+
 // EnableAdaptiveMode enables or disables adaptive threshold adjustment
 func (m *Monitor) EnableAdaptiveMode(enabled bool) {
 	m.mu.Lock()
@@ -340,11 +403,14 @@ func (m *Monitor) checkThresholds(dataPoints []analyzers.DataPoint, values []flo
 	defer m.mu.Unlock()
 
 	var anomalies []analyzers.Anomaly
+	breaches := newBreachTracker(m.coalesceWindow)
 
 	for i, value := range values {
 		// Check against configured threshold bounds
 		if anomaly := m.checkThresholdBounds(dataPoints[i], value); anomaly != nil {
-			anomalies = append(anomalies, *anomaly)
+			anomalies = append(anomalies, breaches.observe(boundBreachKey, anomaly)...)
+		} else {
+			anomalies = append(anomalies, breaches.close(boundBreachKey)...)
 		}
 
 		// Check against custom rules
@@ -353,41 +419,49 @@ func (m *Monitor) checkThresholds(dataPoints []analyzers.DataPoint, values []flo
 				continue
 			}
 
-			// Check cooldown
-			if time.Since(rule.LastTriggered) < rule.Cooldown {
+			// Check cooldown, which only applies between breaches: a
+			// sustained breach keeps extending until it ends
+			extending := breaches.extends(rule.ID, dataPoints[i].Timestamp)
+			if !extending && time.Since(rule.LastTriggered) < rule.Cooldown {
+				anomalies = append(anomalies, breaches.close(rule.ID)...)
+				continue
+			}
+
+			if !m.evaluateRule(rule, value) {
+				anomalies = append(anomalies, breaches.close(rule.ID)...)
 				continue
 			}
 
-			if m.evaluateRule(rule, value) {
+			if !extending {
 				rule.Count++
-				rule.LastTriggered = time.Now()
-
-				anomaly := analyzers.Anomaly{
-					ID:        fmt.Sprintf("threshold_%s_%d", rule.ID, dataPoints[i].Timestamp.Unix()),
-					Type:      analyzers.AnomalyTypeThreshold,
-					Severity:  rule.Severity,
-					Score:     m.calculateRuleScore(rule, value),
-					Timestamp: dataPoints[i].Timestamp,
-					Value:     value,
-					Expected:  rule.Value,
-					Source:    m.Name(),
-					Message:   fmt.Sprintf("Threshold rule '%s' violated: %s %s %.2f", rule.Name, rule.Metric, rule.Operator, rule.Value),
-					Metadata: map[string]interface{}{
-						"rule_id":          rule.ID,
-						"rule_name":        rule.Name,
-						"rule_operator":    string(rule.Operator),
-						"rule_value":       rule.Value,
-						"actual_value":     value,
-						"violation_count":  rule.Count,
-						"rule_metadata":    rule.Metadata,
-					},
-				}
-				anomalies = append(anomalies, anomaly)
 			}
+			rule.LastTriggered = time.Now()
+
+			anomaly := analyzers.Anomaly{
+				ID:        fmt.Sprintf("threshold_%s_%d", rule.ID, dataPoints[i].Timestamp.Unix()),
+				Type:      analyzers.AnomalyTypeThreshold,
+				Severity:  rule.Severity,
+				Score:     m.calculateRuleScore(rule, value),
+				Timestamp: dataPoints[i].Timestamp,
+				Value:     value,
+				Expected:  rule.Value,
+				Source:    m.Name(),
+				Message:   fmt.Sprintf("Threshold rule '%s' violated: %s %s %.2f", rule.Name, rule.Metric, rule.Operator, rule.Value),
+				Metadata: map[string]interface{}{
+					"rule_id":          rule.ID,
+					"rule_name":        rule.Name,
+					"rule_operator":    string(rule.Operator),
+					"rule_value":       rule.Value,
+					"actual_value":     value,
+					"violation_count":  rule.Count,
+					"rule_metadata":    rule.Metadata,
+				},
+			}
+			anomalies = append(anomalies, breaches.observe(rule.ID, &anomaly)...)
 		}
 	}
 
-	return anomalies
+	return append(anomalies, breaches.flush()...)
 }
 
 // checkThresholdBounds checks value against configured threshold bounds
@@ -0,0 +1,113 @@
+package uniformity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Reference is a human reference distribution for the lengths of one kind of
+// text unit. Human sentence and paragraph lengths are close to log-normal, so
+// the reference is the log-normal with the given spread of log lengths.
+// Lengths are divided by their own mean before testing, which makes the test
+// compare the shape of the distribution - how much lengths vary - rather
+// than whether the writer prefers long or short sentences.
+type Reference struct {
+	Sigma float64 // standard deviation of the log lengths
+}
+
+// DefaultSentenceReference is the spread of sentence lengths in words found
+// across human-written English prose
+func DefaultSentenceReference() Reference {
+	return Reference{Sigma: 0.55}
+}
+
+// DefaultParagraphReference is the spread of paragraph lengths in words found
+// across human-written English prose
+func DefaultParagraphReference() Reference {
+	return Reference{Sigma: 0.65}
+}
+
+// Validate checks the reference distribution
+func (r Reference) Validate() error {
+	if r.Sigma <= 0 || math.IsNaN(r.Sigma) || math.IsInf(r.Sigma, 0) {
+		return fmt.Errorf("reference sigma must be positive, got %f", r.Sigma)
+	}
+	return nil
+}
+
+// CDF returns the probability that a mean-normalized length is at most x
+func (r Reference) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	// ln X ~ N(-sigma^2/2, sigma^2) gives E[X] = 1
+	return 0.5 * math.Erfc(-(math.Log(x)+r.Sigma*r.Sigma/2)/(r.Sigma*math.Sqrt2))
+}
+
+// CV returns the coefficient of variation of lengths drawn from the reference
+func (r Reference) CV() float64 {
+	return math.Sqrt(math.Expm1(r.Sigma * r.Sigma))
+}
+
+// KSTest is the outcome of a one-sample Kolmogorov-Smirnov test
+type KSTest struct {
+	Statistic float64 // largest distance between the sample and reference CDFs
+	PValue    float64 // probability of a distance at least this large under the reference
+}
+
+// KolmogorovSmirnov tests whether the mean-normalized lengths could have been
+// drawn from the reference distribution. Normalizing by the sample mean makes
+// the p-value somewhat conservative, which errs on the side of not flagging
+// human text.
+func KolmogorovSmirnov(lengths []int, reference Reference) KSTest {
+	n := len(lengths)
+	if n == 0 {
+		return KSTest{PValue: 1}
+	}
+
+	mean := 0.0
+	for _, length := range lengths {
+		mean += float64(length)
+	}
+	mean /= float64(n)
+	if mean == 0 {
+		return KSTest{PValue: 1}
+	}
+
+	normalized := make([]float64, n)
+	for i, length := range lengths {
+		normalized[i] = float64(length) / mean
+	}
+	sort.Float64s(normalized)
+
+	d := 0.0
+	for i, x := range normalized {
+		cdf := reference.CDF(x)
+		d = math.Max(d, math.Max(float64(i+1)/float64(n)-cdf, cdf-float64(i)/float64(n)))
+	}
+
+	return KSTest{Statistic: d, PValue: kolmogorovPValue(d, n)}
+}
+
+// kolmogorovPValue evaluates the Kolmogorov distribution's survival function
+// with Stephens' small-sample correction
+func kolmogorovPValue(d float64, n int) float64 {
+	sqrtN := math.Sqrt(float64(n))
+	lambda := (sqrtN + 0.12 + 0.11/sqrtN) * d
+	if lambda < 0.2 {
+		return 1
+	}
+
+	sum := 0.0
+	sign := 1.0
+	for k := 1; k <= 100; k++ {
+		term := sign * math.Exp(-2*float64(k*k)*lambda*lambda)
+		sum += term
+		if math.Abs(term) < 1e-12 {
+			break
+		}
+		sign = -sign
+	}
+	return math.Max(0, math.Min(1, 2*sum))
+}
@@ -0,0 +1,279 @@
+package uniformity
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// paragraphBreak matches the blank lines separating paragraphs
+var paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+
+// UniformityAnalyzer flags sentence and paragraph lengths that vary less than
+// human writing does. Each length distribution is tested against a human
+// reference with a Kolmogorov-Smirnov test; only departures towards
+// uniformity count, since unusually varied lengths are no sign of generation.
+type UniformityAnalyzer struct {
+	name               string
+	sentenceReference  Reference
+	paragraphReference Reference
+	significance       float64 // p-value at which uniformity is reported
+	minSentences       int     // below this many sentences the sentence test is skipped
+	minParagraphs      int     // below this many paragraphs the paragraph test is skipped
+	fullConfidenceAt   int     // sentences needed for full confidence
+	mu                 sync.RWMutex
+}
+
+// NewUniformityAnalyzer creates a uniformity analyzer using the default human
+// reference distributions
+func NewUniformityAnalyzer() *UniformityAnalyzer {
+	return &UniformityAnalyzer{
+		name:               "uniformity",
+		sentenceReference:  DefaultSentenceReference(),
+		paragraphReference: DefaultParagraphReference(),
+		significance:       0.01,
+		minSentences:       8,
+		minParagraphs:      4,
+		fullConfidenceAt:   40,
+	}
+}
+
+// Name returns the analyzer name
+func (ua *UniformityAnalyzer) Name() string {
+	return ua.name
+}
+
+// Description returns what the analyzer measures
+func (ua *UniformityAnalyzer) Description() string {
+	return "Tests whether sentence and paragraph lengths are more uniform than in human-written prose"
+}
+
+// Parameters returns the options accepted by Configure
+func (ua *UniformityAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "significance", Type: models.ParameterNumber, Description: "Significance level of the uniformity test, between 0 and 1", Default: 0.01},
+		{Name: "min_sentences", Type: models.ParameterInteger, Description: "Fewest sentences needed to test sentence lengths; must exceed 1", Default: 8},
+		{Name: "min_paragraphs", Type: models.ParameterInteger, Description: "Fewest paragraphs needed to test paragraph lengths; must exceed 1", Default: 4},
+		{Name: "sentence_reference_sigma", Type: models.ParameterNumber, Description: "Spread of human sentence lengths the text is compared with", Default: DefaultSentenceReference().Sigma},
+		{Name: "paragraph_reference_sigma", Type: models.ParameterNumber, Description: "Spread of human paragraph lengths the text is compared with", Default: DefaultParagraphReference().Sigma},
+	}
+}
+
+// SetReferences replaces the human reference distributions tested against
+func (ua *UniformityAnalyzer) SetReferences(sentences, paragraphs Reference) error {
+	if err := sentences.Validate(); err != nil {
+		return fmt.Errorf("invalid sentence reference: %w", err)
+	}
+	if err := paragraphs.Validate(); err != nil {
+		return fmt.Errorf("invalid paragraph reference: %w", err)
+	}
+
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+	ua.sentenceReference = sentences
+	ua.paragraphReference = paragraphs
+	return nil
+}
+
+// ValidateHealth checks the human reference distributions tested against
+func (ua *UniformityAnalyzer) ValidateHealth(ctx context.Context) error {
+	ua.mu.RLock()
+	defer ua.mu.RUnlock()
+
+	if err := ua.sentenceReference.Validate(); err != nil {
+		return fmt.Errorf("invalid sentence reference: %w", err)
+	}
+	if err := ua.paragraphReference.Validate(); err != nil {
+		return fmt.Errorf("invalid paragraph reference: %w", err)
+	}
+	return nil
+}
+
+// Configure updates the analyzer configuration
+func (ua *UniformityAnalyzer) Configure(config map[string]interface{}) error {
+	ua.mu.RLock()
+	sentences, paragraphs := ua.sentenceReference, ua.paragraphReference
+	ua.mu.RUnlock()
+
+	if sigma, ok := config["sentence_reference_sigma"].(float64); ok {
+		sentences.Sigma = sigma
+	}
+	if sigma, ok := config["paragraph_reference_sigma"].(float64); ok {
+		paragraphs.Sigma = sigma
+	}
+	if err := ua.SetReferences(sentences, paragraphs); err != nil {
+		return err
+	}
+
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+
+	if significance, ok := config["significance"].(float64); ok {
+		if significance <= 0 || significance >= 1 {
+			return fmt.Errorf("significance must be between 0 and 1, got %f", significance)
+		}
+		ua.significance = significance
+	}
+	if minSentences, ok := config["min_sentences"].(int); ok && minSentences > 1 {
+		ua.minSentences = minSentences
+	}
+	if minParagraphs, ok := config["min_paragraphs"].(int); ok && minParagraphs > 1 {
+		ua.minParagraphs = minParagraphs
+	}
+
+	return nil
+}
+
+// Analyze tests the sentence and paragraph lengths of the text
+func (ua *UniformityAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return ua.AnalyzeTokens(ctx, tokenizer.New(tokenizer.Default, text))
+}
+
+// AnalyzeTokens tests the sentence and paragraph lengths of already tokenized text
+func (ua *UniformityAnalyzer) AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	text := tokens.Text()
+
+	ua.mu.RLock()
+	defer ua.mu.RUnlock()
+
+	if len(text) == 0 {
+		return &models.AnalysisResult{
+			Score:      0.0,
+			Confidence: 0.0,
+			Metadata:   map[string]interface{}{},
+		}, nil
+	}
+
+	sentenceLengths := make([]int, 0, len(tokens.SentenceWords()))
+	for _, words := range tokens.SentenceWords() {
+		sentenceLengths = append(sentenceLengths, len(words))
+	}
+	paragraphLengths := paragraphWordCounts(text)
+
+	metadata := map[string]interface{}{
+		"significance":        ua.significance,
+		"sentence_count":      len(sentenceLengths),
+		"paragraph_count":     len(paragraphLengths),
+		"uniformity_detected": false,
+	}
+
+	score := 0.0
+	tested := false
+	var decisive *KSTest
+	if len(sentenceLengths) >= ua.minSentences {
+		test, levelScore := ua.testLengths("sentence", sentenceLengths, ua.sentenceReference, metadata)
+		score, tested, decisive = levelScore, true, &test
+	}
+	if len(paragraphLengths) >= ua.minParagraphs {
+		test, levelScore := ua.testLengths("paragraph", paragraphLengths, ua.paragraphReference, metadata)
+		if !tested || levelScore > score {
+			score, decisive = levelScore, &test
+		}
+		tested = true
+	}
+
+	if !tested {
+		return &models.AnalysisResult{
+			Score:      0.0,
+			Confidence: 0.0,
+			Metadata:   metadata,
+		}, nil
+	}
+
+	metadata["ks_statistic"] = decisive.Statistic
+	metadata["p_value"] = decisive.PValue
+	metadata["uniformity_detected"] = score >= 1.0
+
+	return &models.AnalysisResult{
+		Score:      score,
+		Confidence: math.Min(1.0, float64(len(sentenceLengths))/float64(ua.fullConfidenceAt)),
+		Metadata:   metadata,
+	}, nil
+}
+
+// testLengths runs the test for one kind of unit, records its statistics
+// under the unit's prefix and returns the test with its uniformity score
+func (ua *UniformityAnalyzer) testLengths(unit string, lengths []int, reference Reference, metadata map[string]interface{}) (KSTest, float64) {
+	test := KolmogorovSmirnov(lengths, reference)
+	mean, cv := meanAndCV(lengths)
+
+	metadata[unit+"_length_mean"] = mean
+	metadata[unit+"_length_cv"] = cv
+	metadata[unit+"_length_entropy"] = lengthEntropy(lengths)
+	metadata[unit+"_ks_statistic"] = test.Statistic
+	metadata[unit+"_p_value"] = test.PValue
+
+	// A misfit from lengths more varied than the reference is not uniformity
+	if cv >= reference.CV() {
+		return test, 0
+	}
+	return test, ua.calculateUniformityScore(test.PValue)
+}
+
+// calculateUniformityScore maps the p-value onto [0, 1] on a log scale,
+// reaching 1 at the significance level
+func (ua *UniformityAnalyzer) calculateUniformityScore(p float64) float64 {
+	if p <= 0 {
+		return 1
+	}
+	return math.Max(0, math.Min(1, math.Log(p)/math.Log(ua.significance)))
+}
+
+// paragraphWordCounts returns the number of words in each non-empty paragraph
+func paragraphWordCounts(text string) []int {
+	paragraphs := paragraphBreak.Split(text, -1)
+	counts := make([]int, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		if words := len(strings.Fields(paragraph)); words > 0 {
+			counts = append(counts, words)
+		}
+	}
+	return counts
+}
+
+func meanAndCV(lengths []int) (float64, float64) {
+	mean := 0.0
+	for _, length := range lengths {
+		mean += float64(length)
+	}
+	mean /= float64(len(lengths))
+	if mean == 0 {
+		return 0, 0
+	}
+
+	variance := 0.0
+	for _, length := range lengths {
+		diff := float64(length) - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(lengths))
+	return mean, math.Sqrt(variance) / mean
+}
+
+// lengthEntropy returns the Shannon entropy of the lengths normalized by its
+// maximum for the sample size: 0 when every length is the same, 1 when all
+// differ
+func lengthEntropy(lengths []int) float64 {
+	if len(lengths) < 2 {
+		return 0
+	}
+
+	counts := make(map[int]int)
+	for _, length := range lengths {
+		counts[length]++
+	}
+
+	entropy := 0.0
+	n := float64(len(lengths))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy / math.Log2(n)
+}
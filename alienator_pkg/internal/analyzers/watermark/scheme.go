@@ -0,0 +1,90 @@
+package watermark
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// Scheme describes a "green list" watermark. Generators that embed such a
+// watermark split the vocabulary at every step into a green and a red list,
+// seeded by a hash of the preceding tokens and a secret key, and bias
+// sampling towards green tokens. Detection replays the same partition.
+type Scheme struct {
+	Name         string  // label reported in result metadata
+	Key          uint64  // hash key shared with the generator
+	Gamma        float64 // fraction of the vocabulary on the green list, in (0, 1)
+	ContextWidth int     // preceding tokens hashed with each token; 0 hashes the token alone
+}
+
+// DefaultScheme returns the partition used by the common green list
+// watermark: a quarter of the vocabulary is green, seeded by the previous token
+func DefaultScheme() Scheme {
+	return Scheme{
+		Name:         "green-list",
+		Key:          15485863,
+		Gamma:        0.25,
+		ContextWidth: 1,
+	}
+}
+
+// Validate checks that the scheme describes a usable partition
+func (s Scheme) Validate() error {
+	if s.Gamma <= 0 || s.Gamma >= 1 {
+		return fmt.Errorf("watermark gamma %f is outside (0, 1)", s.Gamma)
+	}
+	if s.ContextWidth < 0 {
+		return fmt.Errorf("watermark context width must not be negative, got %d", s.ContextWidth)
+	}
+	return nil
+}
+
+// IsGreen reports whether token falls on the green list given the tokens
+// preceding it. Only the last ContextWidth tokens of context are used.
+func (s Scheme) IsGreen(context []string, token string) bool {
+	if len(context) > s.ContextWidth {
+		context = context[len(context)-s.ContextWidth:]
+	}
+
+	h := fnv.New64a()
+	var key [8]byte
+	binary.LittleEndian.PutUint64(key[:], s.Key)
+	h.Write(key[:])
+	for _, previous := range context {
+		h.Write([]byte(previous))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(token))
+
+	// Map the hash onto [0, 1) and compare against the green fraction
+	return float64(mix(h.Sum64())>>11)/float64(1<<53) < s.Gamma
+}
+
+// mix spreads every input bit over the whole hash (the splitmix64
+// finalizer). FNV alone barely changes its high bits when only the last
+// bytes of the input differ, which would bias the partition.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// zScore measures how far the observed green token count lies above the
+// count expected from unwatermarked text
+func (s Scheme) zScore(green, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	expected := s.Gamma * float64(total)
+	return (float64(green) - expected) / math.Sqrt(float64(total)*s.Gamma*(1-s.Gamma))
+}
+
+// pValue is the one-sided probability of a z-score at least this large in
+// unwatermarked text
+func pValue(z float64) float64 {
+	return 0.5 * math.Erfc(z/math.Sqrt2)
+}
@@ -0,0 +1,207 @@
+package watermark
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// WatermarkAnalyzer estimates whether text carries a green list watermark by
+// testing whether green tokens are over-represented under the configured
+// scheme. Without the generator's key and partition the test has no power,
+// so the score only reflects watermarks that match the scheme.
+type WatermarkAnalyzer struct {
+	name   string
+	scheme Scheme
+	// Detection parameters
+	zThreshold           float64 // z-score at which a watermark is reported
+	minTokens            int     // below this many scored tokens the test is skipped
+	fullConfidenceAt     int     // scored tokens needed for full confidence
+	ignoreRepeatedTokens bool    // score each (context, token) pair once
+	mu                   sync.RWMutex
+}
+
+// NewWatermarkAnalyzer creates a watermark analyzer using the default scheme
+func NewWatermarkAnalyzer() *WatermarkAnalyzer {
+	return &WatermarkAnalyzer{
+		name:                 "watermark",
+		scheme:               DefaultScheme(),
+		zThreshold:           4.0,
+		minTokens:            16,
+		fullConfidenceAt:     200,
+		ignoreRepeatedTokens: true,
+	}
+}
+
+// Name returns the analyzer name
+func (wa *WatermarkAnalyzer) Name() string {
+	return wa.name
+}
+
+// Description returns what the analyzer measures
+func (wa *WatermarkAnalyzer) Description() string {
+	return "Tests for a green-list statistical watermark embedded by the generating model"
+}
+
+// Parameters returns the options accepted by Configure
+func (wa *WatermarkAnalyzer) Parameters() []models.AnalyzerParameter {
+	return []models.AnalyzerParameter{
+		{Name: "scheme", Type: models.ParameterString, Description: "Label of the watermark scheme reported in the result", Default: DefaultScheme().Name},
+		{Name: "key", Type: models.ParameterInteger, Description: "Hash key shared with the generator; the default is the common public key", Default: nil},
+		{Name: "gamma", Type: models.ParameterNumber, Description: "Fraction of the vocabulary on the green list, between 0 and 1", Default: DefaultScheme().Gamma},
+		{Name: "context_width", Type: models.ParameterInteger, Description: "Preceding tokens hashed with each token; 0 hashes the token alone", Default: DefaultScheme().ContextWidth},
+		{Name: "z_threshold", Type: models.ParameterNumber, Description: "Z-score above which the text is considered watermarked", Default: 4.0},
+		{Name: "min_tokens", Type: models.ParameterInteger, Description: "Fewest scored tokens needed to test for a watermark", Default: 16},
+		{Name: "ignore_repeated_tokens", Type: models.ParameterBoolean, Description: "Score each context and token pair only once", Default: true},
+	}
+}
+
+// SetScheme replaces the watermark scheme tested for
+func (wa *WatermarkAnalyzer) SetScheme(scheme Scheme) error {
+	if err := scheme.Validate(); err != nil {
+		return fmt.Errorf("invalid watermark scheme: %w", err)
+	}
+
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+	wa.scheme = scheme
+	return nil
+}
+
+// Configure updates the analyzer configuration. Scheme keys ("scheme",
+// "key", "gamma", "context_width") override the current scheme field by field.
+func (wa *WatermarkAnalyzer) Configure(config map[string]interface{}) error {
+	wa.mu.RLock()
+	scheme := wa.scheme
+	wa.mu.RUnlock()
+
+	if name, ok := config["scheme"].(string); ok && name != "" {
+		scheme.Name = name
+	}
+	switch key := config["key"].(type) {
+	case int:
+		scheme.Key = uint64(key)
+	case int64:
+		scheme.Key = uint64(key)
+	case uint64:
+		scheme.Key = key
+	}
+	if gamma, ok := config["gamma"].(float64); ok {
+		scheme.Gamma = gamma
+	}
+	if width, ok := config["context_width"].(int); ok {
+		scheme.ContextWidth = width
+	}
+	if err := wa.SetScheme(scheme); err != nil {
+		return err
+	}
+
+	wa.mu.Lock()
+	defer wa.mu.Unlock()
+
+	if threshold, ok := config["z_threshold"].(float64); ok && threshold > 0 {
+		wa.zThreshold = threshold
+	}
+	if minTokens, ok := config["min_tokens"].(int); ok && minTokens > 0 {
+		wa.minTokens = minTokens
+	}
+	if ignore, ok := config["ignore_repeated_tokens"].(bool); ok {
+		wa.ignoreRepeatedTokens = ignore
+	}
+
+	return nil
+}
+
+// Analyze performs the green list test on the text
+func (wa *WatermarkAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return wa.AnalyzeTokens(ctx, tokenizer.New(tokenizer.Default, text))
+}
+
+// AnalyzeTokens performs the green list test on already tokenized text
+func (wa *WatermarkAnalyzer) AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	text := tokens.Text()
+
+	wa.mu.RLock()
+	defer wa.mu.RUnlock()
+
+	if len(text) == 0 {
+		return &models.AnalysisResult{
+			Score:      0.0,
+			Confidence: 0.0,
+			Metadata:   map[string]interface{}{},
+		}, nil
+	}
+
+	words, _ := tokens.NormalizedWords()
+	green, scored := wa.countGreenTokens(words)
+
+	metadata := map[string]interface{}{
+		"scheme":        wa.scheme.Name,
+		"gamma":         wa.scheme.Gamma,
+		"tokens":        len(words),
+		"tokens_scored": scored,
+		"green_tokens":  green,
+	}
+
+	if scored < wa.minTokens {
+		metadata["watermark_detected"] = false
+		return &models.AnalysisResult{
+			Score:      0.0,
+			Confidence: 0.0,
+			Metadata:   metadata,
+		}, nil
+	}
+
+	z := wa.scheme.zScore(green, scored)
+	p := pValue(z)
+
+	metadata["green_fraction"] = float64(green) / float64(scored)
+	metadata["z_score"] = z
+	metadata["p_value"] = p
+	metadata["z_threshold"] = wa.zThreshold
+	metadata["watermark_detected"] = z >= wa.zThreshold
+
+	return &models.AnalysisResult{
+		Score:      wa.calculateWatermarkScore(z),
+		Confidence: math.Min(1.0, float64(scored)/float64(wa.fullConfidenceAt)),
+		Metadata:   metadata,
+	}, nil
+}
+
+// countGreenTokens counts the tokens on the green list among those with a
+// full context window
+func (wa *WatermarkAnalyzer) countGreenTokens(tokens []string) (green, scored int) {
+	seen := make(map[string]bool)
+
+	for i := wa.scheme.ContextWidth; i < len(tokens); i++ {
+		prefix := tokens[i-wa.scheme.ContextWidth : i]
+
+		// Repeated phrases would otherwise count the same green decision
+		// many times and inflate the statistic
+		if wa.ignoreRepeatedTokens {
+			key := strings.Join(prefix, "\x00") + "\x00" + tokens[i]
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+
+		scored++
+		if wa.scheme.IsGreen(prefix, tokens[i]) {
+			green++
+		}
+	}
+
+	return green, scored
+}
+
+// calculateWatermarkScore maps the z-score onto [0, 1], reaching 1 at the
+// detection threshold. Green token deficits count as no evidence.
+func (wa *WatermarkAnalyzer) calculateWatermarkScore(z float64) float64 {
+	return math.Max(0, math.Min(1, z/wa.zThreshold))
+}
@@ -14,6 +14,7 @@ import (
 type Handler struct {
 	detector *core.AnomalyDetector
 	logger   *zap.Logger
+	metadata models.MetadataFilter
 }
 
 // NewHandler creates a new API handler
@@ -21,9 +22,17 @@ func NewHandler(detector *core.AnomalyDetector, logger *zap.Logger) *Handler {
 	return &Handler{
 		detector: detector,
 		logger:   logger,
+		metadata: models.MetadataFilter{Level: models.MetadataSummary},
 	}
 }
 
+// SetMetadataFilter sets how much metadata analysis results carry,
+// models.MetadataSummary unless set. A request may ask for another level
+// with the metadata query parameter.
+func (h *Handler) SetMetadataFilter(filter models.MetadataFilter) {
+	h.metadata = filter
+}
+
 // SetupRoutes configures the API routes
 func (h *Handler) SetupRoutes(router *gin.Engine) {
 	v1 := router.Group("/api/v1")
@@ -34,9 +43,10 @@ func (h *Handler) SetupRoutes(router *gin.Engine) {
 	}
 }
 
-// AnalyzeTextRequest represents the request payload for text analysis
+// AnalyzeTextRequest represents the request payload for text analysis. Empty
+// text is accepted and reported with the empty input outcome.
 type AnalyzeTextRequest struct {
-	Text    string            `json:"text" binding:"required"`
+	Text    string            `json:"text" binding:"max=1000000"`
 	Options map[string]string `json:"options,omitempty"`
 }
 
@@ -49,9 +59,24 @@ func (h *Handler) AnalyzeText(c *gin.Context) {
 		return
 	}
 
+	contentType, err := core.ParseContentType(req.Options["content_type"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := h.metadata
+	if level, ok := c.GetQuery("metadata"); ok {
+		filter.Level = models.MetadataLevel(level)
+	}
+	if !filter.Level.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metadata must be none, summary or full"})
+		return
+	}
+
 	startTime := time.Now()
 	
-	result, err := h.detector.AnalyzeText(req.Text)
+	result, err := h.detector.AnalyzeTextAs(req.Text, contentType)
 	if err != nil {
 		h.logger.Error("Analysis failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Analysis failed"})
@@ -63,7 +88,7 @@ func (h *Handler) AnalyzeText(c *gin.Context) {
 
 	response := &models.AnalysisResponse{
 		ID:       generateRequestID(),
-		Result:   result,
+		Result:   result.Filtered(filter),
 		Duration: duration,
 	}
 
@@ -71,6 +96,7 @@ func (h *Handler) AnalyzeText(c *gin.Context) {
 		zap.String("request_id", response.ID),
 		zap.Float64("score", result.Score),
 		zap.Bool("is_anomalous", result.IsAnomalous),
+		zap.String("severity", string(result.Severity)),
 		zap.Duration("duration", duration))
 
 	c.JSON(http.StatusOK, response)
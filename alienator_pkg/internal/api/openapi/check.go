@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ginParamPattern matches the ":id" and "*path" parameters of gin routes
+var ginParamPattern = regexp.MustCompile(`[:*](\w+)`)
+
+// DriftError lists the differences between a document and a router
+type DriftError struct {
+	// Undocumented are the routes served but missing from the document
+	Undocumented []string
+	// Unserved are the operations documented but not served
+	Unserved []string
+}
+
+func (e *DriftError) Error() string {
+	var parts []string
+	if len(e.Undocumented) > 0 {
+		parts = append(parts, "undocumented routes: "+strings.Join(e.Undocumented, ", "))
+	}
+	if len(e.Unserved) > 0 {
+		parts = append(parts, "documented but not served: "+strings.Join(e.Unserved, ", "))
+	}
+	return "OpenAPI document does not match the router: " + strings.Join(parts, "; ")
+}
+
+// Check compares the operations of a document with the routes a router
+// serves under the document's base path, and returns a *DriftError if any
+// route is undocumented or any operation is not served. Routes outside the
+// base path are ignored.
+func Check(spec []byte, routes gin.RoutesInfo) error {
+	var doc struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	basePath := ""
+	if len(doc.Servers) > 0 {
+		basePath = strings.TrimSuffix(doc.Servers[0].URL, "/")
+	}
+
+	documented := make(map[string]bool)
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	served := make(map[string]bool)
+	for _, route := range routes {
+		path, ok := strings.CutPrefix(route.Path, basePath)
+		if !ok || (path != "" && !strings.HasPrefix(path, "/")) {
+			continue
+		}
+		served[route.Method+" "+ginParamPattern.ReplaceAllString(path, "{$1}")] = true
+	}
+
+	drift := &DriftError{}
+	for operation := range served {
+		if !documented[operation] {
+			drift.Undocumented = append(drift.Undocumented, operation)
+		}
+	}
+	for operation := range documented {
+		if !served[operation] {
+			drift.Unserved = append(drift.Unserved, operation)
+		}
+	}
+	if len(drift.Undocumented) == 0 && len(drift.Unserved) == 0 {
+		return nil
+	}
+	sort.Strings(drift.Undocumented)
+	sort.Strings(drift.Unserved)
+	return drift
+}
@@ -0,0 +1,459 @@
+// Package openapi generates the OpenAPI document of the REST API from the
+// swag-style annotations on its handlers and the models they reference, and
+// checks it against the routes a router actually registers
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+// modelsPrefix qualifies the model types annotations refer to
+const modelsPrefix = "models."
+
+// Sources locates the annotated Go sources a document is generated from
+type Sources struct {
+	// General is the file holding the general API annotations (@title,
+	// @version, @description, @BasePath and @securityDefinitions)
+	General string
+	// Handlers is the directory of the annotated handlers
+	Handlers string
+	// Models is the directory of the package annotations refer to as "models."
+	Models string
+}
+
+// DefaultSources returns the sources of this module's API, relative to its root
+func DefaultSources(root string) Sources {
+	return Sources{
+		General:  filepath.Join(root, "cmd", "api", "main.go"),
+		Handlers: filepath.Join(root, "internal", "api", "rest"),
+		Models:   filepath.Join(root, "internal", "models"),
+	}
+}
+
+// operation is one annotated handler
+type operation struct {
+	id          string
+	method      string
+	path        string
+	summary     string
+	description string
+	tags        []string
+	accept      []string
+	produce     []string
+	params      []param
+	responses   []response
+}
+
+// param is one @Param annotation
+type param struct {
+	name        string
+	in          string
+	typ         string
+	required    bool
+	description string
+	attributes  map[string]string
+}
+
+// response is one @Success or @Failure annotation
+type response struct {
+	code        int
+	typ         string
+	description string
+	failure     bool
+}
+
+var (
+	paramPattern    = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)\s+(true|false)\s+"([^"]*)"\s*(.*)$`)
+	responsePattern = regexp.MustCompile(`^(\d+)\s+\{(\w+)\}\s+(\S+)\s*(?:"([^"]*)")?`)
+	attrPattern     = regexp.MustCompile(`(\w+)\(([^)]*)\)`)
+	routePattern    = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]$`)
+	envelopePattern = regexp.MustCompile(`^([\w.]+)\{(\w+)=(\S+)\}$`)
+)
+
+// Generate builds the OpenAPI document from the annotated sources. The
+// output is deterministic, so a checked-in copy can be compared against it.
+func Generate(src Sources) ([]byte, error) {
+	general, err := parseGeneral(src.General)
+	if err != nil {
+		return nil, err
+	}
+	operations, err := parseOperations(src.Handlers)
+	if err != nil {
+		return nil, err
+	}
+	models, err := parseModels(src.Models)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]map[string]interface{})
+	for _, op := range operations {
+		path := op.path
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		if _, ok := paths[path][op.method]; ok {
+			return nil, fmt.Errorf("%s %s is annotated twice, the second time on %s", strings.ToUpper(op.method), path, op.id)
+		}
+		built, err := models.operation(op)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op.id, err)
+		}
+		paths[path][op.method] = built
+	}
+
+	schemas, err := models.schemas()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := map[string]interface{}{
+		"openapi": Version,
+		"info": map[string]interface{}{
+			"title":       general["title"],
+			"version":     general["version"],
+			"description": general["description"],
+		},
+		"servers": []interface{}{map[string]interface{}{"url": general["BasePath"]}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"ApiKeyAuth": map[string]interface{}{
+					"type": "apiKey",
+					"in":   "header",
+					"name": "Authorization",
+				},
+			},
+		},
+	}
+	encoded, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+	return append(encoded, '\n'), nil
+}
+
+// parseGeneral reads the general API annotations of a file
+func parseGeneral(file string) (map[string]string, error) {
+	parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	general := make(map[string]string)
+	for _, group := range parsed.Comments {
+		for _, comment := range group.List {
+			key, value := annotation(comment.Text)
+			if key != "" && general[key] == "" {
+				general[key] = value
+			}
+		}
+	}
+	for _, key := range []string{"title", "version", "BasePath"} {
+		if general[key] == "" {
+			return nil, fmt.Errorf("%s has no @%s annotation", file, key)
+		}
+	}
+	return general, nil
+}
+
+// parseOperations reads the annotated functions of a package directory
+func parseOperations(dir string) ([]operation, error) {
+	files, err := parseDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []operation
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			op, err := parseOperation(fn)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fn.Name.Name, err)
+			}
+			if op != nil {
+				operations = append(operations, *op)
+			}
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].path != operations[j].path {
+			return operations[i].path < operations[j].path
+		}
+		return operations[i].method < operations[j].method
+	})
+	return operations, nil
+}
+
+// parseOperation reads the annotations of a function, or returns nil if it
+// has no @Router
+func parseOperation(fn *ast.FuncDecl) (*operation, error) {
+	op := &operation{id: fn.Name.Name}
+	for _, comment := range fn.Doc.List {
+		key, value := annotation(comment.Text)
+		switch key {
+		case "Summary":
+			op.summary = value
+		case "Description":
+			op.description = value
+		case "Tags":
+			op.tags = splitList(value)
+		case "Accept":
+			op.accept = append(op.accept, splitList(value)...)
+		case "Produce":
+			op.produce = append(op.produce, splitList(value)...)
+		case "Param":
+			match := paramPattern.FindStringSubmatch(value)
+			if match == nil {
+				return nil, fmt.Errorf("malformed @Param %q", value)
+			}
+			p := param{
+				name:        match[1],
+				in:          match[2],
+				typ:         match[3],
+				required:    match[4] == "true",
+				description: match[5],
+				attributes:  make(map[string]string),
+			}
+			for _, attr := range attrPattern.FindAllStringSubmatch(match[6], -1) {
+				p.attributes[attr[1]] = attr[2]
+			}
+			op.params = append(op.params, p)
+		case "Success", "Failure":
+			match := responsePattern.FindStringSubmatch(value)
+			if match == nil {
+				return nil, fmt.Errorf("malformed @%s %q", key, value)
+			}
+			code, _ := strconv.Atoi(match[1])
+			typ := match[3]
+			if match[2] == "array" {
+				typ = "[]" + typ
+			}
+			op.responses = append(op.responses, response{code: code, typ: typ, description: match[4], failure: key == "Failure"})
+		case "Router":
+			match := routePattern.FindStringSubmatch(value)
+			if match == nil {
+				return nil, fmt.Errorf("malformed @Router %q", value)
+			}
+			op.path = match[1]
+			op.method = strings.ToLower(match[2])
+		}
+	}
+	if op.path == "" {
+		return nil, nil
+	}
+	return op, nil
+}
+
+// annotation splits a "// @Key value" comment line
+func annotation(text string) (string, string) {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "//"))
+	if !strings.HasPrefix(text, "@") {
+		return "", ""
+	}
+	key, value, _ := strings.Cut(text[1:], " ")
+	return key, strings.TrimSpace(value)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseDir parses the non-test Go files of a directory, sorted by name
+func parseDir(dir string) ([]*ast.File, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// mediaType expands the short forms annotations use for MIME types
+func mediaType(name string) string {
+	switch name {
+	case "json":
+		return "application/json"
+	case "plain":
+		return "text/plain"
+	}
+	return name
+}
+
+// encodesJSON reports whether a media type carries the response schema as
+// JSON, as opposed to an opaque file
+func encodesJSON(mediaType string) bool {
+	return strings.HasSuffix(mediaType, "json")
+}
+
+// operation builds the OpenAPI operation object of an annotated handler,
+// recording the models it references
+func (m *modelSet) operation(op operation) (map[string]interface{}, error) {
+	built := map[string]interface{}{
+		"operationId": op.id,
+		"summary":     op.summary,
+		"tags":        op.tags,
+	}
+	if op.description != "" {
+		built["description"] = op.description
+	}
+
+	accept := op.accept
+	if len(accept) == 0 {
+		accept = []string{"json"}
+	}
+	produce := op.produce
+	if len(produce) == 0 {
+		produce = []string{"json"}
+	}
+
+	parameters := make([]interface{}, 0, len(op.params))
+	for _, p := range op.params {
+		if p.in == "body" {
+			schema, err := m.schemaOf(p.typ)
+			if err != nil {
+				return nil, err
+			}
+			content := make(map[string]interface{})
+			for _, name := range accept {
+				content[mediaType(name)] = map[string]interface{}{"schema": schema}
+			}
+			built["requestBody"] = map[string]interface{}{
+				"description": p.description,
+				"required":    p.required,
+				"content":     content,
+			}
+			continue
+		}
+		schema := map[string]interface{}{"type": primitive(p.typ)}
+		if value, ok := p.attributes["default"]; ok {
+			schema["default"] = literal(schema["type"].(string), value)
+		}
+		if values, ok := p.attributes["Enums"]; ok {
+			var enum []interface{}
+			for _, value := range splitList(values) {
+				enum = append(enum, literal(schema["type"].(string), value))
+			}
+			schema["enum"] = enum
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name":        p.name,
+			"in":          p.in,
+			"required":    p.required || p.in == "path",
+			"description": p.description,
+			"schema":      schema,
+		})
+	}
+	if len(parameters) > 0 {
+		built["parameters"] = parameters
+	}
+
+	responses := make(map[string]interface{})
+	for _, r := range op.responses {
+		code := strconv.Itoa(r.code)
+		if _, ok := responses[code]; ok {
+			continue
+		}
+		description := r.description
+		if description == "" {
+			description = http.StatusText(r.code)
+		}
+		schema, err := m.schemaOf(r.typ)
+		if err != nil {
+			return nil, err
+		}
+		// Errors are always reported as JSON, whatever the route produces
+		types := produce
+		if r.failure {
+			types = []string{"json"}
+		}
+		content := make(map[string]interface{})
+		for _, name := range types {
+			media := mediaType(name)
+			if encodesJSON(media) {
+				content[media] = map[string]interface{}{"schema": schema}
+			} else {
+				content[media] = map[string]interface{}{
+					"schema": map[string]interface{}{"type": "string", "format": "binary"},
+				}
+			}
+		}
+		responses[code] = map[string]interface{}{
+			"description": description,
+			"content":     content,
+		}
+	}
+	built["responses"] = responses
+	return built, nil
+}
+
+// primitive maps an annotation type to an OpenAPI primitive type
+func primitive(typ string) string {
+	switch typ {
+	case "int", "integer":
+		return "integer"
+	case "number", "float", "float64":
+		return "number"
+	case "bool", "boolean":
+		return "boolean"
+	}
+	return "string"
+}
+
+// literal converts an annotation value to the JSON value of a primitive type
+func literal(typ, value string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
@@ -0,0 +1,308 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// modelSet holds the type declarations of the models package and records
+// which of them the document references
+type modelSet struct {
+	types map[string]*ast.TypeSpec
+	enums map[string][]interface{}
+	used  map[string]bool
+}
+
+// parseModels reads the type and constant declarations of the models package
+func parseModels(dir string) (*modelSet, error) {
+	files, err := parseDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &modelSet{
+		types: make(map[string]*ast.TypeSpec),
+		enums: make(map[string][]interface{}),
+		used:  make(map[string]bool),
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Doc == nil && len(gen.Specs) == 1 {
+						spec.Doc = gen.Doc
+					}
+					m.types[spec.Name.Name] = spec
+				case *ast.ValueSpec:
+					if gen.Tok == token.CONST {
+						m.addEnumValues(spec)
+					}
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// addEnumValues records the string constants declared with a named type as
+// the allowed values of that type
+func (m *modelSet) addEnumValues(spec *ast.ValueSpec) {
+	typ, ok := spec.Type.(*ast.Ident)
+	if !ok {
+		return
+	}
+	for _, value := range spec.Values {
+		lit, ok := value.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(lit.Value); err == nil {
+			m.enums[typ.Name] = append(m.enums[typ.Name], unquoted)
+		}
+	}
+}
+
+// schemaOf returns the schema of an annotation type: a primitive, a model,
+// a slice of either, or a model envelope such as
+// models.APIResponse{data=[]models.User}
+func (m *modelSet) schemaOf(typ string) (map[string]interface{}, error) {
+	if elem, ok := strings.CutPrefix(typ, "[]"); ok {
+		items, err := m.schemaOf(elem)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	}
+	if match := envelopePattern.FindStringSubmatch(typ); match != nil {
+		envelope, err := m.schemaOf(match[1])
+		if err != nil {
+			return nil, err
+		}
+		field, err := m.schemaOf(match[3])
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"allOf": []interface{}{
+				envelope,
+				map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{match[2]: field},
+				},
+			},
+		}, nil
+	}
+	if name, ok := strings.CutPrefix(typ, modelsPrefix); ok {
+		if _, ok := m.types[name]; !ok {
+			return nil, fmt.Errorf("unknown model %s", typ)
+		}
+		return m.ref(name), nil
+	}
+	return map[string]interface{}{"type": primitive(typ)}, nil
+}
+
+// ref references a model's component schema
+func (m *modelSet) ref(name string) map[string]interface{} {
+	m.used[name] = true
+	return map[string]interface{}{"$ref": "#/components/schemas/" + modelsPrefix + name}
+}
+
+// schemas returns the component schemas of every referenced model, and of
+// the models those reference in turn
+func (m *modelSet) schemas() (map[string]interface{}, error) {
+	schemas := make(map[string]interface{})
+	for {
+		pending := make([]string, 0)
+		for name := range m.used {
+			if _, done := schemas[modelsPrefix+name]; !done {
+				pending = append(pending, name)
+			}
+		}
+		if len(pending) == 0 {
+			return schemas, nil
+		}
+		sort.Strings(pending)
+		for _, name := range pending {
+			spec := m.types[name]
+			schema := m.exprSchema(spec.Type)
+			if spec.Doc != nil {
+				schema["description"] = strings.TrimSpace(spec.Doc.Text())
+			}
+			if values, ok := m.enums[name]; ok {
+				schema["enum"] = values
+			}
+			schemas[modelsPrefix+name] = schema
+		}
+	}
+}
+
+// exprSchema returns the schema of a Go type expression
+func (m *modelSet) exprSchema(expr ast.Expr) map[string]interface{} {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		switch expr.Name {
+		case "string":
+			return map[string]interface{}{"type": "string"}
+		case "bool":
+			return map[string]interface{}{"type": "boolean"}
+		case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "byte", "rune":
+			return map[string]interface{}{"type": "integer"}
+		case "int64", "uint64":
+			return map[string]interface{}{"type": "integer", "format": "int64"}
+		case "float32", "float64":
+			return map[string]interface{}{"type": "number"}
+		case "any":
+			return map[string]interface{}{}
+		}
+		if _, ok := m.types[expr.Name]; ok {
+			return m.ref(expr.Name)
+		}
+		return map[string]interface{}{}
+	case *ast.StarExpr:
+		return m.exprSchema(expr.X)
+	case *ast.ArrayType:
+		if ident, ok := expr.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": m.exprSchema(expr.Elt)}
+	case *ast.MapType:
+		return map[string]interface{}{"type": "object", "additionalProperties": m.exprSchema(expr.Value)}
+	case *ast.SelectorExpr:
+		switch fmt.Sprintf("%s.%s", expr.X, expr.Sel.Name) {
+		case "time.Time":
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		case "time.Duration":
+			return map[string]interface{}{"type": "integer", "format": "int64", "description": "Nanoseconds"}
+		case "uuid.UUID":
+			return map[string]interface{}{"type": "string", "format": "uuid"}
+		}
+		return map[string]interface{}{}
+	case *ast.StructType:
+		return m.structSchema(expr)
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema returns the object schema of a struct, keyed by JSON name,
+// with the fields of embedded models inlined, as encoding/json does for
+// embedded structs and pointers to them
+func (m *modelSet) structSchema(st *ast.StructType) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(unquoted)
+			}
+		}
+		jsonName, jsonOptions, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			embeddedType := field.Type
+			if star, ok := embeddedType.(*ast.StarExpr); ok {
+				embeddedType = star.X
+			}
+			if ident, ok := embeddedType.(*ast.Ident); ok && jsonName == "" {
+				if spec, ok := m.types[ident.Name]; ok {
+					if embedded, ok := spec.Type.(*ast.StructType); ok {
+						inlined := m.structSchema(embedded)
+						for name, property := range inlined["properties"].(map[string]interface{}) {
+							properties[name] = property
+						}
+						if names, ok := inlined["required"].([]string); ok {
+							required = append(required, names...)
+						}
+						continue
+					}
+				}
+			}
+		}
+
+		for _, name := range fieldNames(field) {
+			if !ast.IsExported(name) {
+				continue
+			}
+			if jsonName != "" {
+				name = jsonName
+			}
+			property := m.exprSchema(field.Type)
+			if doc := fieldDoc(field); doc != "" {
+				if _, isRef := property["$ref"]; isRef {
+					property = map[string]interface{}{"allOf": []interface{}{property}, "description": doc}
+				} else {
+					property["description"] = doc
+				}
+			}
+			properties[name] = property
+			if !strings.Contains(jsonOptions, "omitempty") && isRequired(tag) {
+				required = append(required, name)
+			}
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fieldNames returns the names of a field, or the type name of an embedded one
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		switch typ := field.Type.(type) {
+		case *ast.Ident:
+			return []string{typ.Name}
+		case *ast.StarExpr:
+			if ident, ok := typ.X.(*ast.Ident); ok {
+				return []string{ident.Name}
+			}
+		case *ast.SelectorExpr:
+			return []string{typ.Sel.Name}
+		}
+		return nil
+	}
+	names := make([]string, 0, len(field.Names))
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	return names
+}
+
+// fieldDoc returns the comment above or beside a field
+func fieldDoc(field *ast.Field) string {
+	if field.Doc != nil {
+		return strings.TrimSpace(field.Doc.Text())
+	}
+	if field.Comment != nil {
+		return strings.TrimSpace(field.Comment.Text())
+	}
+	return ""
+}
+
+// isRequired reports whether validation tags require a field
+func isRequired(tag reflect.StructTag) bool {
+	for _, key := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(tag.Get(key), ",") {
+			if rule == "required" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// errorStatus maps a service error to an HTTP status code by its category,
+// returning fallback for errors outside the categories
+func errorStatus(err error, fallback int) int {
+	if code, ok := apperrors.CodeOf(err); ok {
+		return code.HTTPStatus()
+	}
+	return fallback
+}
+
+// isClientError reports whether err belongs to one of the error categories, in
+// which case its message is safe to return to the client
+func isClientError(err error) bool {
+	return errorStatus(err, 0) != 0
+}
+
+// respondServiceError writes the error response for a failed service call. Client
+// errors carry their own message; internal errors are logged and replaced by
+// internalMessage.
+func (h *Handler) respondServiceError(c *gin.Context, err error, code, internalMessage string) {
+	message := err.Error()
+	if !isClientError(err) {
+		h.logger.Error(internalMessage, zap.Error(err))
+		message = internalMessage
+	}
+	h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
+		Success: false,
+		Error: &models.APIError{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
@@ -0,0 +1,135 @@
+package rest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// GetAnomalyEvidence godoc
+// @Summary Download the evidence bundle of a detection
+// @Description Package everything about a stored detection into one file for reporting: the input, or only its hash with include_input=false (which also leaves out span text, the explanation and non-numeric analyzer metadata), the stored verdict, signature and its verification, per-analyzer scores and features, matched spans with offsets, the explanation and the detector version. As a zip archive the bundle is evidence.json, with the analyzed text alongside as input.txt when included.
+// @Tags anomalies
+// @Produce json
+// @Produce application/zip
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Anomaly ID"
+// @Param format query string false "json or zip" default(json)
+// @Param include_input query bool false "Include the analyzed input rather than only its hash" default(true)
+// @Success 200 {object} models.EvidenceBundle
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /anomalies/{id}/evidence [get]
+func (h *Handler) GetAnomalyEvidence(c *gin.Context) {
+	anomalyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_ANOMALY_ID",
+				Message: "Invalid anomaly ID format",
+			},
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	includeInput, err := strconv.ParseBool(c.DefaultQuery("include_input", "true"))
+	if (format != "json" && format != "zip") || err != nil {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_REQUEST",
+				Message: "format must be json or zip and include_input a boolean",
+			},
+		})
+		return
+	}
+
+	anomaly, err := h.anomalyService.GetAnomalyData(anomalyID)
+	if err != nil {
+		h.respondServiceError(c, err, "ANOMALY_NOT_FOUND", "Failed to get anomaly data")
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	userRole, _ := middleware.GetUserRole(c)
+	if userRole != "admin" && anomaly.UserID != userID {
+		h.respond(c, http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "ACCESS_DENIED",
+				Message: "Access denied to this anomaly data",
+			},
+		})
+		return
+	}
+
+	bundle, err := h.anomalyService.EvidenceBundle(anomaly, includeInput)
+	if err != nil {
+		h.respondServiceError(c, err, "EVIDENCE_FAILED", "Failed to build evidence bundle")
+		return
+	}
+
+	body, err := json.MarshalIndent(bundle, "", "  ")
+	if err == nil && format == "zip" {
+		body, err = zipEvidence(body, bundle)
+	}
+	if err != nil {
+		h.respondServiceError(c, err, "EVIDENCE_FAILED", "Failed to build evidence bundle")
+		return
+	}
+
+	contentType := "application/json"
+	if format == "zip" {
+		contentType = "application/zip"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="evidence-%s.%s"`, anomalyID, format))
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// evidenceFile is one file of an evidence archive
+type evidenceFile struct {
+	name string
+	body []byte
+}
+
+// zipEvidence archives the encoded bundle as evidence.json, with the
+// analyzed text as input.txt when the bundle includes it
+func zipEvidence(encoded []byte, bundle *models.EvidenceBundle) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	files := []evidenceFile{{"evidence.json", encoded}}
+	if text, ok := bundle.Input["text"].(string); ok {
+		files = append(files, evidenceFile{"input.txt", []byte(text)})
+	}
+
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: bundle.GeneratedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to evidence archive: %w", file.name, err)
+		}
+		if _, err := w.Write(file.body); err != nil {
+			return nil, fmt.Errorf("failed to add %s to evidence archive: %w", file.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close evidence archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// explainOnly reports whether the request asks for an explain-only
+// detection, responding with 400 and false when the explain_only query
+// parameter is not a boolean or explain-only detections are disabled
+func (h *Handler) explainOnly(c *gin.Context) (bool, bool) {
+	value, ok := c.GetQuery("explain_only")
+	if !ok {
+		return false, true
+	}
+	explainOnly, err := strconv.ParseBool(value)
+	if err != nil {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_REQUEST",
+				Message: "explain_only must be true or false",
+			},
+		})
+		return false, false
+	}
+	if explainOnly && !h.anomalyService.ExplainOnlyEnabled() {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_REQUEST",
+				Message: "Explain-only detections are not enabled on this server",
+			},
+		})
+		return false, false
+	}
+	return explainOnly, true
+}
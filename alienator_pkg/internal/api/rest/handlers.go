@@ -2,6 +2,11 @@
 package rest
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,19 +14,34 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/ruvnet/alienator/internal/core"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/logging"
 	"github.com/ruvnet/alienator/internal/middleware"
 	"github.com/ruvnet/alienator/internal/models"
 	"github.com/ruvnet/alienator/internal/services"
+	"github.com/ruvnet/alienator/internal/validation"
+	"github.com/ruvnet/alienator/pkg/detector"
 	"go.uber.org/zap"
 )
 
+// apiKeyHeader carries the caller's API key, which selects their detection profile
+const apiKeyHeader = "X-API-Key"
+
 // Handler handles REST API requests
 type Handler struct {
 	detector       *core.AnomalyDetector
 	anomalyService *services.AnomalyService
 	userService    *services.UserService
 	authService    *services.AuthService
+	jobService     *services.JobService
 	logger         *zap.Logger
+	logLevel       zap.AtomicLevel
+	validator      *validation.Validator
+	adminGuards    []gin.HandlerFunc
+	analysisGuards []gin.HandlerFunc
+	limits         *middleware.RequestLimits
+	rateLimiter    *middleware.RateLimiter
+	metadata       models.MetadataFilter
 }
 
 // NewHandler creates a new REST API handler
@@ -31,6 +51,7 @@ func NewHandler(
 	userService *services.UserService,
 	authService *services.AuthService,
 	logger *zap.Logger,
+	logLevel zap.AtomicLevel,
 ) *Handler {
 	return &Handler{
 		detector:       detector,
@@ -38,9 +59,45 @@ func NewHandler(
 		userService:    userService,
 		authService:    authService,
 		logger:         logger,
+		logLevel:       logLevel,
+		validator:      validation.NewValidator(),
+		metadata:       models.MetadataFilter{Level: models.MetadataSummary},
 	}
 }
 
+// SetAdminGuards adds middleware run before every admin-only route, such as
+// middleware.RequireClientCert for mutual TLS. Call it before SetupRoutes.
+func (h *Handler) SetAdminGuards(guards ...gin.HandlerFunc) {
+	h.adminGuards = guards
+}
+
+// SetAnalysisGuards adds middleware run before every detection route, such
+// as a middleware.ConcurrencyLimiter sized for the expensive analysis work.
+// Call it before SetupRoutes.
+func (h *Handler) SetAnalysisGuards(guards ...gin.HandlerFunc) {
+	h.analysisGuards = guards
+}
+
+// SetJobService enables the routes that queue and report on background jobs
+// run by the worker. Call it before SetupRoutes.
+func (h *Handler) SetJobService(jobService *services.JobService) {
+	h.jobService = jobService
+}
+
+// SetLimits has the NDJSON bulk routes enforce the item and line limits of
+// limits, and the limits endpoint report those limits with the rate limit
+// policy of rateLimiter. Either may be nil. Call it before serving requests.
+func (h *Handler) SetLimits(limits *middleware.RequestLimits, rateLimiter *middleware.RateLimiter) {
+	h.limits = limits
+	h.rateLimiter = rateLimiter
+}
+
+// analysis returns the handler chain for a detection route
+func (h *Handler) analysis(handler gin.HandlerFunc) []gin.HandlerFunc {
+	chain := make([]gin.HandlerFunc, 0, len(h.analysisGuards)+1)
+	return append(append(chain, h.analysisGuards...), handler)
+}
+
 // SetupRoutes configures all REST API routes
 func (h *Handler) SetupRoutes(router *gin.RouterGroup) {
 	// Authentication routes
@@ -50,6 +107,7 @@ func (h *Handler) SetupRoutes(router *gin.RouterGroup) {
 		auth.POST("/login", h.Login)
 		auth.POST("/refresh", h.RefreshToken)
 		auth.POST("/logout", middleware.OptionalAuth(h.authService), h.Logout)
+		auth.GET("/password-policy", h.GetPasswordPolicy)
 	}
 
 	// User routes
@@ -62,7 +120,8 @@ func (h *Handler) SetupRoutes(router *gin.RouterGroup) {
 		users.GET("/stats", h.GetUserStats)
 		
 		// Admin only routes
-		admin := users.Group("/")
+		admin := users.Group("")
+		admin.Use(h.adminGuards...)
 		admin.Use(middleware.AdminOnly())
 		{
 			admin.GET("", h.ListUsers)
@@ -75,21 +134,69 @@ func (h *Handler) SetupRoutes(router *gin.RouterGroup) {
 	anomalies := router.Group("/anomalies")
 	anomalies.Use(middleware.Auth(h.authService))
 	{
-		anomalies.POST("/detect", h.DetectAnomaly)
+		anomalies.POST("/detect", h.analysis(h.DetectAnomaly)...)
+		anomalies.POST("/detect/ndjson", h.analysis(h.DetectAnomalyNDJSON)...)
+		if h.limits != nil {
+			// The stream is bounded per line and in items instead
+			h.limits.ExemptRoute(anomalies.BasePath() + "/detect/ndjson")
+		}
+		anomalies.POST("/verify", h.VerifyAnomaly)
 		anomalies.GET("", h.ListAnomalies)
+		anomalies.GET("/search", h.SearchAnomalies)
 		anomalies.GET("/:id", h.GetAnomaly)
+		anomalies.GET("/:id/evidence", h.GetAnomalyEvidence)
 		anomalies.DELETE("/:id", h.DeleteAnomaly)
 		anomalies.GET("/stats", h.GetAnomalyStats)
+		anomalies.GET("/stats/timeseries", h.GetAnomalyStatsTimeSeries)
 	}
 
+	// Analyzer discovery, for clients choosing per-request analyzers
+	router.GET("/analyzers", middleware.Auth(h.authService), h.ListAnalyzers)
+	router.GET("/analyzers/health", middleware.Auth(h.authService), h.AnalyzerHealth)
+
+	// Request limits, for clients sizing their requests
+	router.GET("/limits", h.GetLimits)
+
 	// System routes
 	system := router.Group("/system")
+	system.Use(h.adminGuards...)
 	system.Use(middleware.Auth(h.authService))
 	system.Use(middleware.AdminOnly())
 	{
 		system.GET("/health", h.SystemHealth)
 		system.GET("/stats", h.SystemStats)
 	}
+
+	// Admin routes
+	admin := router.Group("/admin")
+	admin.Use(h.adminGuards...)
+	admin.Use(middleware.Auth(h.authService))
+	admin.Use(middleware.AdminOnly())
+	{
+		admin.GET("/log-level", h.GetLogLevel)
+		admin.PUT("/log-level", h.SetLogLevel)
+		admin.GET("/profiles", h.ListDetectionProfiles)
+		admin.POST("/profiles", h.CreateDetectionProfile)
+		admin.GET("/profiles/:name", h.GetDetectionProfile)
+		admin.PUT("/profiles/:name", h.UpdateDetectionProfile)
+		admin.DELETE("/profiles/:name", h.DeleteDetectionProfile)
+		admin.POST("/profiles/:name/api-keys", h.AssignDetectionProfile)
+		admin.GET("/allowlist", h.ListAllowlistEntries)
+		admin.POST("/allowlist", h.CreateAllowlistEntry)
+		admin.GET("/allowlist/:id", h.GetAllowlistEntry)
+		admin.PUT("/allowlist/:id", h.UpdateAllowlistEntry)
+		admin.DELETE("/allowlist/:id", h.DeleteAllowlistEntry)
+		admin.GET("/review-queue", h.ListReviewQueue)
+		admin.GET("/review-queue/:id", h.GetReviewItem)
+		admin.POST("/review-queue/:id/label", h.LabelReviewItem)
+		admin.GET("/detector/selftest", h.analysis(h.DetectorSelfTest)...)
+		if h.jobService != nil {
+			admin.POST("/detector/retrain", h.RetrainDetector)
+			admin.GET("/jobs", h.ListJobs)
+			admin.GET("/jobs/:id", h.GetJob)
+			admin.POST("/jobs/:id/cancel", h.CancelJob)
+		}
+	}
 }
 
 // Authentication Handlers
@@ -107,21 +214,13 @@ func (h *Handler) SetupRoutes(router *gin.RouterGroup) {
 // @Router /auth/register [post]
 func (h *Handler) Register(c *gin.Context) {
 	var req models.RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Error: &models.APIError{
-				Code:    "INVALID_REQUEST",
-				Message: "Invalid request format",
-				Details: err.Error(),
-			},
-		})
+	if !h.bindAndValidate(c, &req) {
 		return
 	}
 
 	// Validate password strength
 	if err := h.authService.ValidatePasswordStrength(req.Password); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "WEAK_PASSWORD",
@@ -135,7 +234,7 @@ func (h *Handler) Register(c *gin.Context) {
 	hashedPassword, err := h.authService.HashPassword(req.Password)
 	if err != nil {
 		h.logger.Error("Failed to hash password", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.respond(c, http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "INTERNAL_ERROR",
@@ -148,15 +247,16 @@ func (h *Handler) Register(c *gin.Context) {
 	// Create user
 	user, err := h.userService.CreateUser(&req, hashedPassword)
 	if err != nil {
-		status := http.StatusConflict
-		if err.Error() == "failed to create user" {
-			status = http.StatusInternalServerError
+		message := err.Error()
+		if !isClientError(err) {
+			h.logger.Error("Failed to register user", zap.Error(err))
+			message = "Failed to create user"
 		}
-		c.JSON(status, models.APIResponse{
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "REGISTRATION_FAILED",
-				Message: err.Error(),
+				Message: message,
 			},
 		})
 		return
@@ -166,7 +266,7 @@ func (h *Handler) Register(c *gin.Context) {
 	token, expiresAt, err := h.authService.GenerateToken(user)
 	if err != nil {
 		h.logger.Error("Failed to generate token", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.respond(c, http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "TOKEN_GENERATION_FAILED",
@@ -182,7 +282,7 @@ func (h *Handler) Register(c *gin.Context) {
 		ExpiresAt: expiresAt,
 	}
 
-	c.JSON(http.StatusCreated, models.APIResponse{
+	h.respond(c, http.StatusCreated, models.APIResponse{
 		Success: true,
 		Data:    response,
 	})
@@ -201,22 +301,25 @@ func (h *Handler) Register(c *gin.Context) {
 // @Router /auth/login [post]
 func (h *Handler) Login(c *gin.Context) {
 	var req models.LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Error: &models.APIError{
-				Code:    "INVALID_REQUEST",
-				Message: "Invalid request format",
-				Details: err.Error(),
-			},
-		})
+	if !h.bindAndValidate(c, &req) {
 		return
 	}
 
 	// Get user by email
 	user, err := h.userService.GetUserByEmail(req.Email)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			h.logger.Error("Failed to look up user for login", zap.Error(err))
+			h.respond(c, http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "LOGIN_FAILED",
+					Message: "Failed to process login",
+				},
+			})
+			return
+		}
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "INVALID_CREDENTIALS",
@@ -228,7 +331,7 @@ func (h *Handler) Login(c *gin.Context) {
 
 	// Validate credentials
 	if err := h.authService.ValidateUserCredentials(req.Email, req.Password, user); err != nil {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, errorStatus(err, http.StatusUnauthorized), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "INVALID_CREDENTIALS",
@@ -242,7 +345,7 @@ func (h *Handler) Login(c *gin.Context) {
 	token, expiresAt, err := h.authService.GenerateToken(user)
 	if err != nil {
 		h.logger.Error("Failed to generate token", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.respond(c, http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "TOKEN_GENERATION_FAILED",
@@ -258,7 +361,7 @@ func (h *Handler) Login(c *gin.Context) {
 		ExpiresAt: expiresAt,
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    response,
 	})
@@ -278,7 +381,7 @@ func (h *Handler) Login(c *gin.Context) {
 func (h *Handler) RefreshToken(c *gin.Context) {
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "MISSING_TOKEN",
@@ -291,7 +394,7 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	tokenString := authHeader[7:] // Remove "Bearer " prefix
 	newToken, expiresAt, err := h.authService.RefreshToken(tokenString)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "REFRESH_FAILED",
@@ -303,7 +406,7 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 
 	user, err := h.authService.GetUserFromToken(newToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.respond(c, http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "USER_FETCH_FAILED",
@@ -319,7 +422,7 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 		ExpiresAt: expiresAt,
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    response,
 	})
@@ -336,12 +439,26 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 func (h *Handler) Logout(c *gin.Context) {
 	// In a JWT implementation, logout is typically handled client-side
 	// by removing the token. Server-side logout would require token blacklisting.
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    gin.H{"message": "Logged out successfully"},
 	})
 }
 
+// GetPasswordPolicy godoc
+// @Summary Get password policy
+// @Description Get the password rules enforced on registration
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.APIResponse{data=models.PasswordPolicy}
+// @Router /auth/password-policy [get]
+func (h *Handler) GetPasswordPolicy(c *gin.Context) {
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.authService.PasswordPolicy(),
+	})
+}
+
 // User Handlers
 
 // GetProfile godoc
@@ -358,7 +475,7 @@ func (h *Handler) Logout(c *gin.Context) {
 func (h *Handler) GetProfile(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "UNAUTHORIZED",
@@ -370,7 +487,10 @@ func (h *Handler) GetProfile(c *gin.Context) {
 
 	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			h.logger.Error("Failed to get user", zap.Error(err), zap.String("user_id", userID.String()))
+		}
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "USER_NOT_FOUND",
@@ -380,7 +500,7 @@ func (h *Handler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    user,
 	})
@@ -401,7 +521,7 @@ func (h *Handler) GetProfile(c *gin.Context) {
 func (h *Handler) UpdateProfile(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "UNAUTHORIZED",
@@ -412,35 +532,28 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 	}
 
 	var req models.UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Error: &models.APIError{
-				Code:    "INVALID_REQUEST",
-				Message: "Invalid request format",
-				Details: err.Error(),
-			},
-		})
+	if !h.bindAndValidate(c, &req) {
 		return
 	}
 
 	user, err := h.userService.UpdateUser(userID, &req)
 	if err != nil {
-		status := http.StatusBadRequest
-		if err.Error() == "user not found" {
-			status = http.StatusNotFound
+		message := err.Error()
+		if !isClientError(err) {
+			h.logger.Error("Failed to update user", zap.Error(err), zap.String("user_id", userID.String()))
+			message = "Failed to update user"
 		}
-		c.JSON(status, models.APIResponse{
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "UPDATE_FAILED",
-				Message: err.Error(),
+				Message: message,
 			},
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    user,
 	})
@@ -459,7 +572,7 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 func (h *Handler) DeleteProfile(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "UNAUTHORIZED",
@@ -470,17 +583,22 @@ func (h *Handler) DeleteProfile(c *gin.Context) {
 	}
 
 	if err := h.userService.DeleteUser(userID); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+		message := err.Error()
+		if !isClientError(err) {
+			h.logger.Error("Failed to delete user", zap.Error(err), zap.String("user_id", userID.String()))
+			message = "Failed to delete user"
+		}
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "DELETE_FAILED",
-				Message: err.Error(),
+				Message: message,
 			},
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    gin.H{"message": "Profile deleted successfully"},
 	})
@@ -499,7 +617,7 @@ func (h *Handler) DeleteProfile(c *gin.Context) {
 func (h *Handler) GetUserStats(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "UNAUTHORIZED",
@@ -511,7 +629,7 @@ func (h *Handler) GetUserStats(c *gin.Context) {
 
 	stats, err := h.userService.GetUserStats(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "STATS_FAILED",
@@ -521,7 +639,7 @@ func (h *Handler) GetUserStats(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    stats,
 	})
@@ -546,7 +664,7 @@ func (h *Handler) ListUsers(c *gin.Context) {
 
 	users, meta, err := h.userService.ListUsers(page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.respond(c, http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "FETCH_FAILED",
@@ -556,7 +674,7 @@ func (h *Handler) ListUsers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    users,
 		Meta:    meta,
@@ -580,7 +698,7 @@ func (h *Handler) ListUsers(c *gin.Context) {
 func (h *Handler) GetUser(c *gin.Context) {
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "INVALID_USER_ID",
@@ -592,7 +710,10 @@ func (h *Handler) GetUser(c *gin.Context) {
 
 	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			h.logger.Error("Failed to get user", zap.Error(err), zap.String("user_id", userID.String()))
+		}
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "USER_NOT_FOUND",
@@ -602,7 +723,7 @@ func (h *Handler) GetUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    user,
 	})
@@ -624,7 +745,7 @@ func (h *Handler) GetUser(c *gin.Context) {
 func (h *Handler) DeleteUser(c *gin.Context) {
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "INVALID_USER_ID",
@@ -635,17 +756,22 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	}
 
 	if err := h.userService.DeleteUser(userID); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+		message := err.Error()
+		if !isClientError(err) {
+			h.logger.Error("Failed to delete user", zap.Error(err), zap.String("user_id", userID.String()))
+			message = "Failed to delete user"
+		}
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "DELETE_FAILED",
-				Message: err.Error(),
+				Message: message,
 			},
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    gin.H{"message": "User deleted successfully"},
 	})
@@ -655,20 +781,27 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 
 // DetectAnomaly godoc
 // @Summary Detect anomalies in data
-// @Description Analyze data for anomalies using ML algorithms
+// @Description Analyze data for anomalies using ML algorithms. A "text" field in data is scored by the text analyzers, optionally restricted to "analyzers" and reweighted by "weights"; unknown analyzer names are rejected with 400. With explain_only=true the expensive analyzers are left out and the result, explaining the verdict in plain language, is neither stored nor signed; it is meant for quick feedback, not as the authoritative verdict.
 // @Tags anomalies
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
+// @Param Accept-Version header string false "Response schema version (v1, v2)" default(v2)
+// @Param X-API-Key header string false "API key selecting the caller's detection profile"
+// @Param metadata query string false "Result metadata to include: none, summary (features left out) or full" Enums(none, summary, full)
+// @Param explain_only query bool false "Run only the cheap analyzers and explain the verdict, without storing it"
 // @Param request body models.DetectionRequest true "Detection request"
 // @Success 200 {object} models.APIResponse{data=models.DetectionResult}
 // @Failure 400 {object} models.APIResponse
 // @Failure 401 {object} models.APIResponse
+// @Failure 406 {object} models.APIResponse
+// @Failure 413 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse
 // @Router /anomalies/detect [post]
 func (h *Handler) DetectAnomaly(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "UNAUTHORIZED",
@@ -678,23 +811,50 @@ func (h *Handler) DetectAnomaly(c *gin.Context) {
 		return
 	}
 
+	filter, ok := h.metadataFilter(c)
+	if !ok {
+		return
+	}
+	explainOnly, ok := h.explainOnly(c)
+	if !ok {
+		return
+	}
+	if explainOnly {
+		// The explanation is what an explain-only request is for
+		filter.Keys = append([]string{"explanations", "analyzers"}, filter.Keys...)
+	}
+
 	var req models.DetectionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Error: &models.APIError{
-				Code:    "INVALID_REQUEST",
-				Message: "Invalid request format",
-				Details: err.Error(),
-			},
-		})
+	if !h.bindAndValidate(c, &req) {
 		return
 	}
 
-	result, err := h.anomalyService.ProcessDetection(userID, &req)
+	result, err := h.processWithinTimeout(c, userID, &req, explainOnly)
 	if err != nil {
+		if timedOut(err) {
+			h.respond(c, http.StatusServiceUnavailable, models.APIResponse{
+				Success: false,
+				Error:   middleware.RequestTimedOut(h.limitsConfig().RequestTimeout),
+			})
+			return
+		}
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		// Unknown analyzers and other bad selections are the client's to fix
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			h.respond(c, http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "INVALID_ANALYZER_SELECTION",
+					Message: "Invalid analyzer selection",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
 		h.logger.Error("Anomaly detection failed", zap.Error(err), zap.String("user_id", userID.String()))
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.respond(c, http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "DETECTION_FAILED",
@@ -705,19 +865,166 @@ func (h *Handler) DetectAnomaly(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
-		Data:    result,
+		Data:    result.Filtered(filter),
 	})
 }
 
+// maxNDJSONLineSize bounds a single line of an NDJSON bulk request when no
+// request limits are set
+const maxNDJSONLineSize = 1 << 20
+
+// DetectAnomalyNDJSON godoc
+// @Summary Detect anomalies in a stream of items
+// @Description Read newline-delimited DetectionRequest objects (each with an optional id) and stream one NDJSON result line per item as it completes. Invalid items produce an error line and do not stop the stream. A stream over the item or line limit, or running past the request timeout, ends with an error line giving the limit.
+// @Tags anomalies
+// @Accept application/x-ndjson
+// @Produce application/x-ndjson
+// @Param Authorization header string true "Bearer token"
+// @Param X-API-Key header string false "API key selecting the caller's detection profile"
+// @Param metadata query string false "Result metadata to include: none, summary (features left out) or full" Enums(none, summary, full)
+// @Success 200 {object} models.BulkDetectionResult
+// @Failure 401 {object} models.APIResponse
+// @Router /anomalies/detect/ndjson [post]
+func (h *Handler) DetectAnomalyNDJSON(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "UNAUTHORIZED",
+				Message: "User authentication required",
+			},
+		})
+		return
+	}
+
+	filter, ok := h.metadataFilter(c)
+	if !ok {
+		return
+	}
+
+	apiKey := c.GetHeader(apiKeyHeader)
+	scanner := bufio.NewScanner(c.Request.Body)
+	limits := h.limitsConfig()
+	maxLineBytes := h.maxLineBytes()
+	scanner.Buffer(make([]byte, 0, min(64*1024, maxLineBytes)), maxLineBytes)
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+
+	line, processed := 0, 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if err := c.Request.Context().Err(); err != nil {
+			if timedOut(err) {
+				h.writeNDJSONError(encoder, c, line, middleware.RequestTimedOut(limits.RequestTimeout))
+			}
+			break
+		}
+		if limits.MaxBatchItems > 0 && processed >= limits.MaxBatchItems {
+			h.writeNDJSONError(encoder, c, line, batchTooLarge(limits.MaxBatchItems))
+			break
+		}
+
+		output := h.detectNDJSONItem(c.Request.Context(), userID, apiKey, line, raw, filter)
+		if err := encoder.Encode(output); err != nil {
+			h.logger.Warn("Failed to write NDJSON result, client likely disconnected", zap.Error(err))
+			return
+		}
+		c.Writer.Flush()
+		processed++
+	}
+
+	if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+		h.writeNDJSONError(encoder, c, line+1, lineTooLarge(maxLineBytes))
+	} else if err != nil {
+		h.writeNDJSONError(encoder, c, line+1, &models.APIError{
+			Code:    "INVALID_REQUEST",
+			Message: "Failed to read request stream",
+			Details: err.Error(),
+		})
+	}
+
+	h.logger.Info("NDJSON bulk detection completed",
+		zap.String("user_id", userID.String()),
+		zap.Int("items", processed),
+	)
+}
+
+// writeNDJSONError writes an error line for the stream as a whole
+func (h *Handler) writeNDJSONError(encoder *json.Encoder, c *gin.Context, line int, apiErr *models.APIError) {
+	encoder.Encode(models.BulkDetectionResult{Line: line, Error: apiErr})
+	c.Writer.Flush()
+}
+
+// detectNDJSONItem decodes, validates and scores a single NDJSON line, its
+// result carrying the metadata filter selects
+func (h *Handler) detectNDJSONItem(ctx context.Context, userID uuid.UUID, apiKey string, line int, raw []byte, filter models.MetadataFilter) models.BulkDetectionResult {
+	var item models.BulkDetectionItem
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return models.BulkDetectionResult{
+			Line: line,
+			Error: &models.APIError{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid JSON object",
+				Details: err.Error(),
+			},
+		}
+	}
+
+	output := models.BulkDetectionResult{ID: item.ID, Line: line}
+
+	if err := h.validator.ValidateStruct(&item); err != nil {
+		output.Error = &models.APIError{
+			Code:    "VALIDATION_FAILED",
+			Message: "Request validation failed",
+		}
+		var validationErr *validation.ValidationError
+		if errors.As(err, &validationErr) {
+			output.ValidationErrors = validationErr.Errors
+		} else {
+			output.Error.Details = err.Error()
+		}
+		return output
+	}
+
+	result, err := h.anomalyService.ProcessDetection(ctx, userID, apiKey, &item.DetectionRequest)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			output.Error = &models.APIError{
+				Code:    "INVALID_ANALYZER_SELECTION",
+				Message: "Invalid analyzer selection",
+				Details: err.Error(),
+			}
+			return output
+		}
+		h.logger.Error("Anomaly detection failed", zap.Error(err), zap.String("user_id", userID.String()), zap.Int("line", line))
+		output.Error = &models.APIError{
+			Code:    "DETECTION_FAILED",
+			Message: "Failed to process anomaly detection",
+		}
+		return output
+	}
+
+	output.Result = result.Filtered(filter)
+	return output
+}
+
 // ListAnomalies godoc
 // @Summary List anomaly detection results
-// @Description Get paginated list of anomaly detection results
+// @Description Get paginated list of anomaly detection results, optionally only those tagged with a source
 // @Tags anomalies
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
+// @Param source query string false "Only detections tagged with this source"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} models.APIResponse{data=[]models.AnomalyData}
@@ -726,7 +1033,7 @@ func (h *Handler) DetectAnomaly(c *gin.Context) {
 func (h *Handler) ListAnomalies(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "UNAUTHORIZED",
@@ -745,14 +1052,15 @@ func (h *Handler) ListAnomalies(c *gin.Context) {
 	var meta *models.Meta
 	var err error
 
+	source := c.Query("source")
 	if userRole == "admin" {
-		anomalies, meta, err = h.anomalyService.ListAnomalyData(page, limit)
+		anomalies, meta, err = h.anomalyService.ListAnomalyData(source, page, limit)
 	} else {
-		anomalies, meta, err = h.anomalyService.GetUserAnomalyData(userID, page, limit)
+		anomalies, meta, err = h.anomalyService.GetUserAnomalyData(userID, source, page, limit)
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.respond(c, http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "FETCH_FAILED",
@@ -762,13 +1070,100 @@ func (h *Handler) ListAnomalies(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    anomalies,
 		Meta:    meta,
 	})
 }
 
+// SearchAnomalies godoc
+// @Summary Search anomaly detection results
+// @Description Search the stored detections' data and algorithm by text, most relevant first. The query supports "quoted phrases", -excluded words and or. Admins search every user's detections, other users their own.
+// @Tags anomalies
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param q query string true "Search query"
+// @Param source query string false "Only detections tagged with this source"
+// @Param min_score query number false "Lowest score to include"
+// @Param max_score query number false "Highest score to include"
+// @Param from query string false "Earliest detection time (RFC 3339)"
+// @Param to query string false "Latest detection time, exclusive (RFC 3339)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} models.APIResponse{data=[]models.AnomalySearchResult}
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Router /anomalies/search [get]
+func (h *Handler) SearchAnomalies(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "UNAUTHORIZED",
+				Message: "User authentication required",
+			},
+		})
+		return
+	}
+
+	search := models.AnomalySearch{Query: c.Query("q"), UserID: &userID, Source: c.Query("source")}
+	if userRole, _ := middleware.GetUserRole(c); userRole == "admin" {
+		search.UserID = nil
+	}
+	for param, target := range map[string]**float64{"min_score": &search.MinScore, "max_score": &search.MaxScore} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		score, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			h.respond(c, http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "INVALID_SCORE_FILTER",
+					Message: "Invalid " + param + " parameter, expected a number",
+				},
+			})
+			return
+		}
+		*target = &score
+	}
+	for param, target := range map[string]*time.Time{"from": &search.From, "to": &search.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.respond(c, http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "INVALID_TIME_RANGE",
+					Message: "Invalid " + param + " parameter, expected an RFC 3339 timestamp",
+				},
+			})
+			return
+		}
+		*target = parsed
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	results, meta, err := h.anomalyService.SearchAnomalyData(search, page, limit)
+	if err != nil {
+		h.respondServiceError(c, err, "SEARCH_FAILED", "Failed to search anomaly data")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    results,
+		Meta:    meta,
+	})
+}
+
 // GetAnomaly godoc
 // @Summary Get anomaly detection result
 // @Description Get specific anomaly detection result by ID
@@ -785,7 +1180,7 @@ func (h *Handler) ListAnomalies(c *gin.Context) {
 func (h *Handler) GetAnomaly(c *gin.Context) {
 	anomalyID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "INVALID_ANOMALY_ID",
@@ -797,7 +1192,10 @@ func (h *Handler) GetAnomaly(c *gin.Context) {
 
 	anomaly, err := h.anomalyService.GetAnomalyData(anomalyID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			h.logger.Error("Failed to get anomaly data", zap.Error(err), zap.String("anomaly_id", anomalyID.String()))
+		}
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "ANOMALY_NOT_FOUND",
@@ -812,7 +1210,7 @@ func (h *Handler) GetAnomaly(c *gin.Context) {
 	userRole, _ := middleware.GetUserRole(c)
 	
 	if userRole != "admin" && anomaly.UserID != userID {
-		c.JSON(http.StatusForbidden, models.APIResponse{
+		h.respond(c, http.StatusForbidden, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "ACCESS_DENIED",
@@ -822,12 +1220,79 @@ func (h *Handler) GetAnomaly(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    anomaly,
 	})
 }
 
+// VerifyAnomaly godoc
+// @Summary Verify a signed detection result
+// @Description Check that a detection result has not been altered since it was signed. Either give the ID of a stored detection, whose stored data is checked too, or the result as returned by the detect endpoint, optionally with the data it was computed on.
+// @Tags anomalies
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body models.VerificationRequest true "Verification request"
+// @Success 200 {object} models.APIResponse{data=models.VerificationResult}
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /anomalies/verify [post]
+func (h *Handler) VerifyAnomaly(c *gin.Context) {
+	var req models.VerificationRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	if (req.ID == nil) == (req.Result == nil) {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_REQUEST",
+				Message: "Exactly one of id or result is required",
+			},
+		})
+		return
+	}
+
+	var verification *models.VerificationResult
+	var err error
+	if req.ID != nil {
+		anomaly, getErr := h.anomalyService.GetAnomalyData(*req.ID)
+		if getErr != nil {
+			h.respondServiceError(c, getErr, "ANOMALY_NOT_FOUND", "Failed to get anomaly data")
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		userRole, _ := middleware.GetUserRole(c)
+		if userRole != "admin" && anomaly.UserID != userID {
+			h.respond(c, http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "ACCESS_DENIED",
+					Message: "Access denied to this anomaly data",
+				},
+			})
+			return
+		}
+		verification, err = h.anomalyService.VerifyStoredDetection(anomaly)
+	} else {
+		verification, err = h.anomalyService.VerifyDetection(req.Result, req.Data)
+	}
+	if err != nil {
+		h.respondServiceError(c, err, "VERIFICATION_FAILED", "Failed to verify detection result")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    verification,
+	})
+}
+
 // DeleteAnomaly godoc
 // @Summary Delete anomaly detection result
 // @Description Delete specific anomaly detection result by ID
@@ -844,7 +1309,7 @@ func (h *Handler) GetAnomaly(c *gin.Context) {
 func (h *Handler) DeleteAnomaly(c *gin.Context) {
 	anomalyID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "INVALID_ANOMALY_ID",
@@ -857,7 +1322,10 @@ func (h *Handler) DeleteAnomaly(c *gin.Context) {
 	// Check if user can delete this anomaly data
 	anomaly, err := h.anomalyService.GetAnomalyData(anomalyID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			h.logger.Error("Failed to get anomaly data", zap.Error(err), zap.String("anomaly_id", anomalyID.String()))
+		}
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "ANOMALY_NOT_FOUND",
@@ -871,7 +1339,7 @@ func (h *Handler) DeleteAnomaly(c *gin.Context) {
 	userRole, _ := middleware.GetUserRole(c)
 	
 	if userRole != "admin" && anomaly.UserID != userID {
-		c.JSON(http.StatusForbidden, models.APIResponse{
+		h.respond(c, http.StatusForbidden, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "ACCESS_DENIED",
@@ -882,7 +1350,8 @@ func (h *Handler) DeleteAnomaly(c *gin.Context) {
 	}
 
 	if err := h.anomalyService.DeleteAnomalyData(anomalyID); err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.logger.Error("Failed to delete anomaly data", zap.Error(err), zap.String("anomaly_id", anomalyID.String()))
+		h.respond(c, errorStatus(err, http.StatusInternalServerError), models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "DELETE_FAILED",
@@ -892,7 +1361,7 @@ func (h *Handler) DeleteAnomaly(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    gin.H{"message": "Anomaly data deleted successfully"},
 	})
@@ -911,7 +1380,7 @@ func (h *Handler) DeleteAnomaly(c *gin.Context) {
 func (h *Handler) GetAnomalyStats(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "UNAUTHORIZED",
@@ -932,7 +1401,7 @@ func (h *Handler) GetAnomalyStats(c *gin.Context) {
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+		h.respond(c, http.StatusInternalServerError, models.APIResponse{
 			Success: false,
 			Error: &models.APIError{
 				Code:    "STATS_FAILED",
@@ -942,65 +1411,874 @@ func (h *Handler) GetAnomalyStats(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    stats,
 	})
 }
 
-// System Handlers
-
-// SystemHealth godoc
-// @Summary System health check (Admin only)
-// @Description Get system health status
-// @Tags system
-// @Accept json
+// GetAnomalyStatsTimeSeries godoc
+// @Summary Get anomaly detection statistics over time
+// @Description Get detection counts and mean scores bucketed by interval, optionally for one source or with a bucket per source to compare detection rates across sources. Admins see all users' detections.
+// @Tags anomalies
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Success 200 {object} models.APIResponse{data=models.HealthCheck}
+// @Param interval query string false "Bucket size (1m, 1h, 1d, 1w, 1mo)" default(1h)
+// @Param source query string false "Only detections tagged with this source"
+// @Param group_by query string false "Give each source buckets of its own (source)"
+// @Param from query string false "Range start (RFC 3339), defaults to 24 hours before to"
+// @Param to query string false "Range end (RFC 3339), defaults to now"
+// @Success 200 {object} models.APIResponse{data=models.AnomalyStatsTimeSeries}
+// @Failure 400 {object} models.APIResponse
 // @Failure 401 {object} models.APIResponse
-// @Failure 403 {object} models.APIResponse
-// @Router /system/health [get]
-func (h *Handler) SystemHealth(c *gin.Context) {
-	health := models.HealthCheck{
-		Status:    "healthy",
-		Version:   "2.0.0",
-		Timestamp: time.Now(),
-		Services: map[string]string{
-			"database": "healthy",
-			"api":      "healthy",
-		},
-		Uptime: "running",
+// @Router /anomalies/stats/timeseries [get]
+func (h *Handler) GetAnomalyStatsTimeSeries(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		h.respond(c, http.StatusUnauthorized, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "UNAUTHORIZED",
+				Message: "User authentication required",
+			},
+		})
+		return
+	}
+
+	var from, to time.Time
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.respond(c, http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "INVALID_TIME_RANGE",
+					Message: "Invalid " + param + " parameter, expected an RFC 3339 timestamp",
+				},
+			})
+			return
+		}
+		*target = parsed
+	}
+
+	scope := &userID
+	if userRole, _ := middleware.GetUserRole(c); userRole == "admin" {
+		scope = nil
+	}
+
+	series, err := h.anomalyService.GetAnomalyStatsTimeSeries(scope, c.Query("source"), c.Query("interval"), c.Query("group_by"), from, to)
+	if err != nil {
+		h.respondServiceError(c, err, "STATS_FAILED", "Failed to get anomaly statistics")
+		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
-		Data:    health,
+		Data:    series,
 	})
 }
 
-// SystemStats godoc
-// @Summary System statistics (Admin only)
-// @Description Get system-wide statistics
-// @Tags system
-// @Accept json
+// ListAnalyzers godoc
+// @Summary List analyzers
+// @Description List the registered text analyzers with what they measure, their weight for each content type, whether they are enabled and initialized, the options they accept and their circuit breaker state; an analyzer whose circuit is open is left out of detections until it recovers. The names can be passed as "analyzers" and "weights" in detection requests.
+// @Tags anomalies
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Success 200 {object} models.APIResponse
+// @Success 200 {object} models.APIResponse{data=[]models.AnalyzerInfo}
 // @Failure 401 {object} models.APIResponse
-// @Failure 403 {object} models.APIResponse
-// @Router /system/stats [get]
-func (h *Handler) SystemStats(c *gin.Context) {
-	// This would typically gather system metrics
-	stats := gin.H{
-		"timestamp":     time.Now(),
-		"total_users":   0, // Would be calculated from database
-		"total_anomalies": 0, // Would be calculated from database
-		"api_version":   "2.0.0",
+// @Router /analyzers [get]
+func (h *Handler) ListAnalyzers(c *gin.Context) {
+	analyzers := make([]models.AnalyzerInfo, 0)
+	if h.detector != nil {
+		analyzers = h.detector.DescribeAnalyzers()
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    analyzers,
+	})
+}
+
+// AnalyzerHealth godoc
+// @Summary Check analyzer health
+// @Description Run the analyzers' self-checks of the resources they loaded (calibration, lexicon, reference distributions, known-hash dataset, trained model). Fails with 503 when a required analyzer is unhealthy, which also fails readiness until a later check passes.
+// @Tags anomalies
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} models.APIResponse{data=models.AnalyzerHealthReport}
+// @Failure 401 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse{data=models.AnalyzerHealthReport}
+// @Router /analyzers/health [get]
+func (h *Handler) AnalyzerHealth(c *gin.Context) {
+	report := models.AnalyzerHealthReport{
+		Healthy:   true,
+		CheckedAt: time.Now(),
+		Analyzers: make([]models.AnalyzerHealth, 0),
+	}
+	if h.detector != nil {
+		report = h.detector.CheckHealth(c.Request.Context())
+	}
+
+	if !report.Healthy {
+		h.respond(c, http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Data:    report,
+			Error: &models.APIError{
+				Code:    "ANALYZER_UNHEALTHY",
+				Message: "A required analyzer failed its health check",
+			},
+		})
+		return
+	}
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// DetectorSelfTest godoc
+// @Summary Self-test the detector (Admin only)
+// @Description Score the built-in labelled corpus with the configured analyzers and report the confusion matrix with accuracy, precision, recall and F1, the Matthews correlation coefficient, and the calibration curve of the scores. The analyses are not stored and don't move the normalization baselines.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param threshold query number false "Score above which a sample is counted as anomalous" default(0.7)
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /admin/detector/selftest [get]
+func (h *Handler) DetectorSelfTest(c *gin.Context) {
+	threshold := detector.DefaultEvaluationThreshold
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			h.respond(c, http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "INVALID_THRESHOLD",
+					Message: "Invalid threshold parameter, expected a number",
+				},
+			})
+			return
+		}
+		threshold = parsed
+	}
+
+	report, err := h.detector.SelfTest(c.Request.Context(), threshold)
+	if err != nil {
+		h.respondServiceError(c, err, "SELFTEST_FAILED", "Failed to run the detector self-test")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// System Handlers
+
+// SystemHealth godoc
+// @Summary System health check (Admin only)
+// @Description Get system health status
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} models.APIResponse{data=models.HealthCheck}
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /system/health [get]
+func (h *Handler) SystemHealth(c *gin.Context) {
+	health := models.HealthCheck{
+		Status:    "healthy",
+		Version:   "2.0.0",
+		Timestamp: time.Now(),
+		Services: map[string]string{
+			"database": "healthy",
+			"api":      "healthy",
+		},
+		Uptime: "running",
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    health,
+	})
+}
+
+// SystemStats godoc
+// @Summary System statistics (Admin only)
+// @Description Get system-wide statistics
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /system/stats [get]
+func (h *Handler) SystemStats(c *gin.Context) {
+	// This would typically gather system metrics
+	stats := gin.H{
+		"timestamp":     time.Now(),
+		"total_users":   0, // Would be calculated from database
+		"total_anomalies": 0, // Would be calculated from database
+		"api_version":   "2.0.0",
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    stats,
 	})
-}
\ No newline at end of file
+}
+
+// Admin Handlers
+
+// GetLogLevel godoc
+// @Summary Get the active log level (Admin only)
+// @Description Get the log level currently applied to the server logger
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} models.APIResponse{data=models.LogLevelResponse}
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /admin/log-level [get]
+func (h *Handler) GetLogLevel(c *gin.Context) {
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    models.LogLevelResponse{Level: h.logLevel.Level().String()},
+	})
+}
+
+// SetLogLevel godoc
+// @Summary Change the log level at runtime (Admin only)
+// @Description Change the server log level without a restart
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body models.LogLevelRequest true "New log level (debug, info, warn, error)"
+// @Success 200 {object} models.APIResponse{data=models.LogLevelResponse}
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /admin/log-level [put]
+func (h *Handler) SetLogLevel(c *gin.Context) {
+	var req models.LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request format",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil || req.Level == "" {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_LOG_LEVEL",
+				Message: "Invalid log level",
+				Details: "Supported levels: debug, info, warn, error, dpanic, panic, fatal",
+			},
+		})
+		return
+	}
+
+	previous := h.logLevel.Level()
+	h.logLevel.SetLevel(level)
+
+	userID, _ := middleware.GetUserID(c)
+	h.logger.Warn("Log level changed",
+		zap.String("previous", previous.String()),
+		zap.String("level", level.String()),
+		zap.String("user_id", userID.String()),
+	)
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: models.LogLevelResponse{
+			Level:    level.String(),
+			Previous: previous.String(),
+		},
+	})
+}
+
+// ListDetectionProfiles godoc
+// @Summary List detection profiles (Admin only)
+// @Description List the named detection profiles that API keys can be bound to
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} models.APIResponse{data=[]models.DetectionProfile}
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /admin/profiles [get]
+func (h *Handler) ListDetectionProfiles(c *gin.Context) {
+	profiles, err := h.anomalyService.ListDetectionProfiles()
+	if err != nil {
+		h.respondServiceError(c, err, "PROFILE_LIST_FAILED", "Failed to list detection profiles")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    profiles,
+	})
+}
+
+// CreateDetectionProfile godoc
+// @Summary Create a detection profile (Admin only)
+// @Description Create a named threshold and feature weight profile
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body models.DetectionProfileRequest true "Profile definition"
+// @Success 201 {object} models.APIResponse{data=models.DetectionProfile}
+// @Failure 400 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Router /admin/profiles [post]
+func (h *Handler) CreateDetectionProfile(c *gin.Context) {
+	var req models.DetectionProfileRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	profile, err := h.anomalyService.CreateDetectionProfile(&req)
+	if err != nil {
+		h.respondServiceError(c, err, "PROFILE_CREATE_FAILED", "Failed to create detection profile")
+		return
+	}
+
+	h.respond(c, http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    profile,
+	})
+}
+
+// GetDetectionProfile godoc
+// @Summary Get a detection profile (Admin only)
+// @Description Get a detection profile by name
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Profile name"
+// @Success 200 {object} models.APIResponse{data=models.DetectionProfile}
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/profiles/{name} [get]
+func (h *Handler) GetDetectionProfile(c *gin.Context) {
+	profile, err := h.anomalyService.GetDetectionProfile(c.Param("name"))
+	if err != nil {
+		h.respondServiceError(c, err, "PROFILE_NOT_FOUND", "Failed to get detection profile")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    profile,
+	})
+}
+
+// UpdateDetectionProfile godoc
+// @Summary Update a detection profile (Admin only)
+// @Description Replace the threshold, algorithm and weights of a detection profile
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Profile name"
+// @Param request body models.DetectionProfileRequest true "Profile definition"
+// @Success 200 {object} models.APIResponse{data=models.DetectionProfile}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/profiles/{name} [put]
+func (h *Handler) UpdateDetectionProfile(c *gin.Context) {
+	var req models.DetectionProfileRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	profile, err := h.anomalyService.UpdateDetectionProfile(c.Param("name"), &req)
+	if err != nil {
+		h.respondServiceError(c, err, "PROFILE_UPDATE_FAILED", "Failed to update detection profile")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    profile,
+	})
+}
+
+// DeleteDetectionProfile godoc
+// @Summary Delete a detection profile (Admin only)
+// @Description Delete a detection profile; API keys bound to it fall back to the default profile
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Profile name"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/profiles/{name} [delete]
+func (h *Handler) DeleteDetectionProfile(c *gin.Context) {
+	if err := h.anomalyService.DeleteDetectionProfile(c.Param("name")); err != nil {
+		h.respondServiceError(c, err, "PROFILE_DELETE_FAILED", "Failed to delete detection profile")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    gin.H{"message": "Detection profile deleted successfully"},
+	})
+}
+
+// AssignDetectionProfile godoc
+// @Summary Bind an API key to a detection profile (Admin only)
+// @Description Apply the profile to detection requests sent with the given API key
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Profile name"
+// @Param request body models.AssignProfileRequest true "API key to bind"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/profiles/{name}/api-keys [post]
+func (h *Handler) AssignDetectionProfile(c *gin.Context) {
+	var req models.AssignProfileRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	if err := h.anomalyService.AssignDetectionProfile(c.Param("name"), req.APIKey); err != nil {
+		h.respondServiceError(c, err, "PROFILE_ASSIGN_FAILED", "Failed to assign detection profile")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    gin.H{"message": "API key bound to detection profile"},
+	})
+}
+
+// allowlistEntryID parses the entry ID path parameter, responding with 400 if
+// it is malformed
+func (h *Handler) allowlistEntryID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_ALLOWLIST_ID",
+				Message: "Invalid allowlist entry ID format",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// ListAllowlistEntries godoc
+// @Summary List allowlist entries (Admin only)
+// @Description List the phrases, regexes and input hashes that suppress known-benign detections
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} models.APIResponse{data=[]models.AllowlistEntry}
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /admin/allowlist [get]
+func (h *Handler) ListAllowlistEntries(c *gin.Context) {
+	entries, err := h.anomalyService.ListAllowlistEntries()
+	if err != nil {
+		h.respondServiceError(c, err, "ALLOWLIST_LIST_FAILED", "Failed to list allowlist entries")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
+
+// CreateAllowlistEntry godoc
+// @Summary Create an allowlist entry (Admin only)
+// @Description Register a phrase, regex or input hash whose matches down-weight the listed analyzers, or the whole score
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body models.AllowlistEntryRequest true "Allowlist entry"
+// @Success 201 {object} models.APIResponse{data=models.AllowlistEntry}
+// @Failure 400 {object} models.APIResponse
+// @Router /admin/allowlist [post]
+func (h *Handler) CreateAllowlistEntry(c *gin.Context) {
+	var req models.AllowlistEntryRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	entry, err := h.anomalyService.CreateAllowlistEntry(&req)
+	if err != nil {
+		h.respondServiceError(c, err, "ALLOWLIST_CREATE_FAILED", "Failed to create allowlist entry")
+		return
+	}
+
+	h.respond(c, http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    entry,
+	})
+}
+
+// GetAllowlistEntry godoc
+// @Summary Get an allowlist entry (Admin only)
+// @Description Get an allowlist entry by ID
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Allowlist entry ID"
+// @Success 200 {object} models.APIResponse{data=models.AllowlistEntry}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/allowlist/{id} [get]
+func (h *Handler) GetAllowlistEntry(c *gin.Context) {
+	id, ok := h.allowlistEntryID(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.anomalyService.GetAllowlistEntry(id)
+	if err != nil {
+		h.respondServiceError(c, err, "ALLOWLIST_NOT_FOUND", "Failed to get allowlist entry")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    entry,
+	})
+}
+
+// UpdateAllowlistEntry godoc
+// @Summary Update an allowlist entry (Admin only)
+// @Description Replace the match and suppression of an allowlist entry
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Allowlist entry ID"
+// @Param request body models.AllowlistEntryRequest true "Allowlist entry"
+// @Success 200 {object} models.APIResponse{data=models.AllowlistEntry}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/allowlist/{id} [put]
+func (h *Handler) UpdateAllowlistEntry(c *gin.Context) {
+	id, ok := h.allowlistEntryID(c)
+	if !ok {
+		return
+	}
+	var req models.AllowlistEntryRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	entry, err := h.anomalyService.UpdateAllowlistEntry(id, &req)
+	if err != nil {
+		h.respondServiceError(c, err, "ALLOWLIST_UPDATE_FAILED", "Failed to update allowlist entry")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    entry,
+	})
+}
+
+// DeleteAllowlistEntry godoc
+// @Summary Delete an allowlist entry (Admin only)
+// @Description Delete an allowlist entry; matching inputs are scored normally again
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Allowlist entry ID"
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/allowlist/{id} [delete]
+func (h *Handler) DeleteAllowlistEntry(c *gin.Context) {
+	id, ok := h.allowlistEntryID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.anomalyService.DeleteAllowlistEntry(id); err != nil {
+		h.respondServiceError(c, err, "ALLOWLIST_DELETE_FAILED", "Failed to delete allowlist entry")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    gin.H{"message": "Allowlist entry deleted successfully"},
+	})
+}
+
+// reviewItemID parses the review item ID path parameter, responding with 400
+// when it is malformed
+func (h *Handler) reviewItemID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_REVIEW_ID",
+				Message: "Invalid review item ID format",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// ListReviewQueue godoc
+// @Summary List the review queue (Admin only)
+// @Description List the stored detections queued for a human label because their score fell within the review band of their threshold, oldest first
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param status query string false "Only items with this status" Enums(pending, labeled)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} models.APIResponse{data=[]models.ReviewItem}
+// @Failure 400 {object} models.APIResponse
+// @Failure 401 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /admin/review-queue [get]
+func (h *Handler) ListReviewQueue(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	items, meta, err := h.anomalyService.ListReviewQueue(c.Query("status"), page, limit)
+	if err != nil {
+		h.respondServiceError(c, err, "REVIEW_LIST_FAILED", "Failed to list review queue")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    items,
+		Meta:    meta,
+	})
+}
+
+// GetReviewItem godoc
+// @Summary Get a review queue item (Admin only)
+// @Description Get a queued detection; GET /anomalies/{anomaly_data_id} returns the detection itself
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Review item ID"
+// @Success 200 {object} models.APIResponse{data=models.ReviewItem}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/review-queue/{id} [get]
+func (h *Handler) GetReviewItem(c *gin.Context) {
+	id, ok := h.reviewItemID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.anomalyService.GetReviewItem(id)
+	if err != nil {
+		h.respondServiceError(c, err, "REVIEW_GET_FAILED", "Failed to get review item")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    item,
+	})
+}
+
+// LabelReviewItem godoc
+// @Summary Label a review queue item (Admin only)
+// @Description Record whether a queued detection is anomalous or normal. Retraining from stored results uses the detections labeled normal and leaves out those labeled anomalous, whatever the detector judged. Labeling again replaces the label.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Review item ID"
+// @Param request body models.ReviewLabelRequest true "Label"
+// @Success 200 {object} models.APIResponse{data=models.ReviewItem}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/review-queue/{id}/label [post]
+func (h *Handler) LabelReviewItem(c *gin.Context) {
+	id, ok := h.reviewItemID(c)
+	if !ok {
+		return
+	}
+	var req models.ReviewLabelRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+	userID, _ := middleware.GetUserID(c)
+
+	item, err := h.anomalyService.LabelReviewItem(id, userID, &req)
+	if err != nil {
+		h.respondServiceError(c, err, "REVIEW_LABEL_FAILED", "Failed to label review item")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    item,
+	})
+}
+
+// RetrainDetector godoc
+// @Summary Retrain the neural detector (Admin only)
+// @Description Queue retraining of the neural detector from the configured dataset or from recent stored results judged or labeled normal. The worker runs the job; poll GET /admin/jobs/{id} for its progress.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body models.RetrainRequest true "Retraining request"
+// @Success 202 {object} models.APIResponse{data=models.Job}
+// @Failure 400 {object} models.APIResponse
+// @Router /admin/detector/retrain [post]
+func (h *Handler) RetrainDetector(c *gin.Context) {
+	var req models.RetrainRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	job, err := h.jobService.RetrainDetector(c.Request.Context(), &req, userID)
+	if err != nil {
+		h.respondServiceError(c, err, "RETRAIN_FAILED", "Failed to queue detector retraining")
+		return
+	}
+
+	h.respond(c, http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Data:    job,
+	})
+}
+
+// ListJobs godoc
+// @Summary List background jobs (Admin only)
+// @Description List recent background jobs, newest first. Finished jobs are kept for the configured retention.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param status query string false "Only jobs with this status" Enums(queued, running, succeeded, failed, cancelled)
+// @Param kind query string false "Only jobs of this kind"
+// @Param limit query int false "Maximum number of jobs" default(50)
+// @Success 200 {object} models.APIResponse{data=[]models.Job}
+// @Failure 400 {object} models.APIResponse
+// @Router /admin/jobs [get]
+func (h *Handler) ListJobs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	jobs, err := h.jobService.ListJobs(c.Request.Context(), c.Query("status"), c.Query("kind"), limit)
+	if err != nil {
+		h.respondServiceError(c, err, "JOB_LIST_FAILED", "Failed to list jobs")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    jobs,
+	})
+}
+
+// jobID parses the job ID path parameter, responding with 400 when it is not a UUID
+func (h *Handler) jobID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_JOB_ID",
+				Message: "Invalid job ID format",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// GetJob godoc
+// @Summary Get a background job (Admin only)
+// @Description Get the status, progress and, once finished, the result or error of a background job
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.APIResponse{data=models.Job}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Router /admin/jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	id, ok := h.jobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobService.GetJob(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "JOB_GET_FAILED", "Failed to get job")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    job,
+	})
+}
+
+// CancelJob godoc
+// @Summary Cancel a background job (Admin only)
+// @Description Cancel a queued job at once, or ask the worker running a job to stop; the job reports cancel_requested until it does
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.APIResponse{data=models.Job}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse
+// @Router /admin/jobs/{id}/cancel [post]
+func (h *Handler) CancelJob(c *gin.Context) {
+	id, ok := h.jobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobService.CancelJob(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "JOB_CANCEL_FAILED", "Failed to cancel job")
+		return
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    job,
+	})
+}
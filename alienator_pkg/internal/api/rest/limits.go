@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// GetLimits godoc
+// @Summary Get request limits
+// @Description Get the limits enforced on each request: the largest body, the most items and largest line of an NDJSON bulk request, the time allowed per request and the rate limit per client IP, with the route groups given a rate limit of their own; a route is limited by the first group with a path prefix it starts with. Zero means no limit. Requests over a limit fail with its value in the error details.
+// @Tags system
+// @Produce json
+// @Success 200 {object} models.APIResponse{data=models.RequestLimits}
+// @Router /limits [get]
+func (h *Handler) GetLimits(c *gin.Context) {
+	limits := h.limitsConfig()
+	response := models.RequestLimits{
+		MaxBodyBytes:     limits.MaxBodyBytes,
+		MaxBatchItems:    limits.MaxBatchItems,
+		MaxLineBytes:     h.maxLineBytes(),
+		RequestTimeoutMS: limits.RequestTimeout.Milliseconds(),
+	}
+	if h.rateLimiter != nil {
+		rateLimit := h.rateLimiter.Config()
+		response.RateLimit = models.RateLimitPolicy{
+			RequestsPerMinute: rateLimit.RequestsPerMinute,
+			Burst:             rateLimit.Burst,
+		}
+		for _, group := range rateLimit.Groups {
+			response.RateLimit.Groups = append(response.RateLimit.Groups, models.RateLimitGroupPolicy{
+				Name:              group.Name,
+				Paths:             group.Paths,
+				RequestsPerMinute: group.RequestsPerMinute,
+				Burst:             group.Burst,
+			})
+		}
+	}
+
+	h.respond(c, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    response,
+	})
+}
+
+// limitsConfig returns the request limits in effect, none if unset
+func (h *Handler) limitsConfig() config.LimitsConfig {
+	if h.limits == nil {
+		return config.LimitsConfig{}
+	}
+	return h.limits.Config()
+}
+
+// maxLineBytes returns the largest line accepted in an NDJSON request
+func (h *Handler) maxLineBytes() int {
+	if limit := h.limitsConfig().MaxLineBytes; limit > 0 {
+		return limit
+	}
+	return maxNDJSONLineSize
+}
+
+// processWithinTimeout runs a detection, an explain-only one if asked,
+// giving up once the request's deadline passes or the client goes away. The
+// detection runs in the request's context, so one given up on stops its
+// analyzers and is not stored.
+func (h *Handler) processWithinTimeout(c *gin.Context, userID uuid.UUID, req *models.DetectionRequest, explainOnly bool) (*models.DetectionResult, error) {
+	type outcome struct {
+		result *models.DetectionResult
+		err    error
+	}
+	ctx := c.Request.Context()
+	apiKey := c.GetHeader(apiKeyHeader)
+	done := make(chan outcome, 1)
+	detect := h.anomalyService.ProcessDetection
+	if explainOnly {
+		detect = h.anomalyService.ExplainDetection
+	}
+	go func() {
+		result, err := detect(ctx, userID, apiKey, req)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// batchTooLarge is the error reported for the first NDJSON item over the limit
+func batchTooLarge(limit int) *models.APIError {
+	return middleware.Rejected("BATCH_TOO_LARGE", "Too many items in the request; the remaining items were not processed",
+		fmt.Sprintf("Limit: %d items per request", limit), 0, int64(limit), int64(limit)+1)
+}
+
+// lineTooLarge is the error reported for an NDJSON line over the limit, which
+// is read only up to the limit: it is reported as one byte over
+func lineTooLarge(limit int) *models.APIError {
+	return middleware.Rejected("LINE_TOO_LARGE", "Request line is too large; the remaining items were not processed",
+		fmt.Sprintf("Limit: %d bytes per line", limit), 0, int64(limit), int64(limit)+1)
+}
+
+// timedOut reports whether err is the request running out of time rather
+// than the client going away
+func timedOut(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
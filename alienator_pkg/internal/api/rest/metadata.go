@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// SetMetadataFilter sets how much metadata detection results carry,
+// models.MetadataSummary unless set. A request may ask for another level
+// with the metadata query parameter; the allowed keys are kept regardless.
+func (h *Handler) SetMetadataFilter(filter models.MetadataFilter) {
+	h.metadata = filter
+}
+
+// metadataFilter returns the metadata filter for the request, responding
+// with 400 and false when it asks for an unsupported level
+func (h *Handler) metadataFilter(c *gin.Context) (models.MetadataFilter, bool) {
+	filter := h.metadata
+	if level, ok := c.GetQuery("metadata"); ok {
+		filter.Level = models.MetadataLevel(level)
+	}
+	if !filter.Level.Valid() {
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_REQUEST",
+				Message: "metadata must be none, summary or full",
+			},
+		})
+		return filter, false
+	}
+	return filter, true
+}
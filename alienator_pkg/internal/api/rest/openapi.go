@@ -0,0 +1,26 @@
+package rest
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate go run ../../../cmd/openapi -root ../../.. -o openapi.json
+
+// openAPISpec is the OpenAPI document generated from the handler annotations
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPISpec returns the OpenAPI document of the routes SetupRoutes registers
+func OpenAPISpec() []byte {
+	return openAPISpec
+}
+
+// ServeOpenAPI serves the OpenAPI document, for client generators and the
+// Swagger UI
+func ServeOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openAPISpec)
+}
@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/validation"
+)
+
+// bindAndValidate decodes the JSON body into obj and validates its struct tags,
+// responding with field-level errors and returning false when either fails
+func (h *Handler) bindAndValidate(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respond(c, http.StatusRequestEntityTooLarge, models.APIResponse{
+				Success: false,
+				Error:   middleware.BodyTooLarge(tooLarge.Limit, c.Request.ContentLength),
+			})
+			return false
+		}
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request format",
+				Details: err.Error(),
+			},
+		})
+		return false
+	}
+
+	if err := h.validator.ValidateStruct(obj); err != nil {
+		var validationErr *validation.ValidationError
+		if !errors.As(err, &validationErr) {
+			h.respond(c, http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "VALIDATION_FAILED",
+					Message: "Request validation failed",
+					Details: err.Error(),
+				},
+			})
+			return false
+		}
+
+		h.respond(c, http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error: &models.APIError{
+				Code:    "VALIDATION_FAILED",
+				Message: "Request validation failed",
+			},
+			ValidationErrors: validationErr.Errors,
+		})
+		return false
+	}
+
+	return true
+}
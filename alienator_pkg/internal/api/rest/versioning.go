@@ -0,0 +1,12 @@
+package rest
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// respond serializes an API response in the schema version negotiated for the request
+func (h *Handler) respond(c *gin.Context, status int, response models.APIResponse) {
+	middleware.Respond(c, status, response)
+}
@@ -0,0 +1,190 @@
+// Package batch analyzes many files for the CLI: directories are walked
+// recursively, a pool of workers reads and analyzes the files, and outcomes
+// are handed back as each file completes. Only the files being analyzed are
+// held in memory.
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/analyzers/template"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Options control a batch run
+type Options struct {
+	// Concurrency is how many files are analyzed at once; below 1 uses one
+	// worker per CPU
+	Concurrency int
+	ContentType core.ContentType
+	// Templates, when set, also scores each file by how templated it is
+	// within the batch, see template.Batch
+	Templates *template.TemplateAnalyzer
+}
+
+// Outcome is the analysis of one file: its result, or why it could not be
+// read or analyzed. Template is how templated the file is, when asked for.
+type Outcome struct {
+	Path     string
+	Result   *models.AnomalyResult
+	Template *template.TemplateScore
+	Err      error
+}
+
+// Summary totals a batch run. Files cancelled before they were analyzed are
+// counted in Files only.
+type Summary struct {
+	Files     int
+	Analyzed  int
+	Anomalous int
+	Templated int // files found templated, when asked for
+	Failed    int
+	Duration  time.Duration
+}
+
+// Collect expands paths into the files to analyze. Files are kept as given;
+// directories are walked recursively in lexical order, skipping the hidden
+// files and directories inside them.
+func Collect(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if file != path && strings.HasPrefix(entry.Name(), ".") {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.Type().IsRegular() {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Run analyzes files with the detector, calling emit with each outcome as
+// its file completes, from one goroutine at a time. With the detector's
+// boilerplate policy enabled or templates asked for, every file is first
+// read once to find the paragraphs or spans they share. Once ctx is done no
+// more files are started.
+func Run(ctx context.Context, detector *core.AnomalyDetector, files []string, opts Options, emit func(Outcome)) Summary {
+	start := time.Now()
+	shared := detector.NewSharedBoilerplate()
+	var templates *template.Batch
+	if opts.Templates != nil {
+		templates = opts.Templates.NewBatch()
+	}
+	if shared != nil || templates != nil {
+		for _, file := range files {
+			if ctx.Err() != nil {
+				break
+			}
+			// Unreadable files are reported by the analysis below
+			if content, err := os.ReadFile(file); err == nil {
+				shared.Add(string(content))
+				if templates != nil {
+					templates.Add(string(content))
+				}
+			}
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = runtime.NumCPU()
+	}
+	if concurrency > len(files) {
+		concurrency = len(files)
+	}
+
+	indexes := make(chan int)
+	outcomes := make(chan Outcome, concurrency)
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				outcomes <- analyzeFile(detector, files[index], index, opts.ContentType, shared, templates)
+			}
+		}()
+	}
+	go func() {
+		defer close(indexes)
+		for index := range files {
+			select {
+			case indexes <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		workers.Wait()
+		close(outcomes)
+	}()
+
+	summary := Summary{Files: len(files)}
+	for outcome := range outcomes {
+		if outcome.Template != nil && outcome.Template.Templated {
+			summary.Templated++
+		}
+		switch {
+		case outcome.Err != nil:
+			summary.Failed++
+		case outcome.Result.IsAnomalous:
+			summary.Anomalous++
+			fallthrough
+		default:
+			summary.Analyzed++
+		}
+		if emit != nil {
+			emit(outcome)
+		}
+	}
+	summary.Duration = time.Since(start)
+	return summary
+}
+
+// analyzeFile reads and analyzes one file, the index-th of the batch
+func analyzeFile(detector *core.AnomalyDetector, path string, index int, contentType core.ContentType, shared *core.SharedBoilerplate, templates *template.Batch) Outcome {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Outcome{Path: path, Err: fmt.Errorf("failed to read file: %w", err)}
+	}
+	result, err := detector.AnalyzeBatchText(string(content), contentType, shared)
+	if err != nil {
+		return Outcome{Path: path, Err: fmt.Errorf("analysis failed: %w", err)}
+	}
+	outcome := Outcome{Path: path, Result: result}
+	if templates != nil {
+		score := templates.Score(index, string(content))
+		outcome.Template = &score
+	}
+	return outcome
+}
@@ -1,22 +1,40 @@
 package config
 
 import (
-	"os"
+	"fmt"
+	"net"
+	"runtime"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/ruvnet/alienator/internal/models"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server    ServerConfig     `json:"server"`
-	Database  DatabaseConfig  `json:"database"`
-	Redis     RedisConfig     `json:"redis"`
-	NATS      NATSConfig      `json:"nats"`
-	Detector  DetectorConfig  `json:"detector"`
-	Auth      AuthConfig      `json:"auth"`
-	JWT       JWTConfig       `json:"jwt"`
-	Logging   LoggingConfig   `json:"logging"`
-	RateLimit RateLimitConfig `json:"rate_limit"`
+	Server      ServerConfig      `json:"server"`
+	Database    DatabaseConfig    `json:"database"`
+	Redis       RedisConfig       `json:"redis"`
+	NATS        NATSConfig        `json:"nats"`
+	Broadcast   BroadcastConfig   `json:"broadcast"`
+	Detector    DetectorConfig    `json:"detector"`
+	Auth        AuthConfig        `json:"auth"`
+	JWT         JWTConfig         `json:"jwt"`
+	Logging     LoggingConfig     `json:"logging"`
+	RateLimit   RateLimitConfig   `json:"rate_limit"`
+	Limits      LimitsConfig      `json:"limits"`
+	Concurrency ConcurrencyConfig `json:"concurrency"`
+	Versioning  VersioningConfig  `json:"versioning"`
+	Worker      WorkerConfig      `json:"worker"`
+	Sinks       SinksConfig       `json:"sinks"`
+	Signing     SigningConfig     `json:"signing"`
+	Hashing     HashingConfig     `json:"hashing"`
+	Output      OutputConfig      `json:"output"`
+	Metrics     MetricsConfig     `json:"metrics"`
+	Review      ReviewConfig      `json:"review"`
+	ExplainOnly ExplainOnlyConfig `json:"explain_only"`
+	Provenance  ProvenanceConfig  `json:"provenance"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -26,6 +44,26 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `json:"read_timeout"`
 	WriteTimeout time.Duration `json:"write_timeout"`
 	IdleTimeout  time.Duration `json:"idle_timeout"`
+	TLS          TLSConfig     `json:"tls"`
+	// On shutdown, readiness fails for DrainDelay so load balancers stop
+	// sending traffic, then in-flight requests get up to ShutdownTimeout
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+	DrainDelay      time.Duration `json:"drain_delay"`
+}
+
+// TLSConfig enables HTTPS. Certificates are reloaded when their files change,
+// so they can be rotated without a restart. With a client CA, clients may
+// present certificates signed by it; AdminClientAuth then requires one on the
+// admin routes (mutual TLS).
+type TLSConfig struct {
+	Enabled         bool   `json:"enabled"`
+	CertFile        string `json:"cert_file"`
+	KeyFile         string `json:"key_file"`
+	ClientCAFile    string `json:"client_ca_file"`
+	AdminClientAuth bool   `json:"admin_client_auth"`
+	MinVersion      string `json:"min_version"` // 1.2 or 1.3
+	// RedirectPort serves plain HTTP redirects to HTTPS; zero disables it
+	RedirectPort int `json:"redirect_port"`
 }
 
 // DatabaseConfig contains database configuration
@@ -46,20 +84,265 @@ type RedisConfig struct {
 	DB       int    `json:"db"`
 }
 
+// Addr returns the host:port address of the Redis server
+func (r RedisConfig) Addr() string {
+	return net.JoinHostPort(r.Host, strconv.Itoa(r.Port))
+}
+
 // NATSConfig contains NATS configuration
 type NATSConfig struct {
 	URL string `json:"url"`
 }
 
+// BroadcastConfig controls retries when publishing broadcast messages. Each
+// retry waits twice as long as the previous one, up to MaxBackoff, with
+// random jitter so that clients don't retry in lockstep.
+type BroadcastConfig struct {
+	MaxRetries     int           `json:"max_retries"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	Alerts         AlertsConfig  `json:"alerts"`
+}
+
+// AlertsConfig renders anomaly alerts before they are broadcast. Channels
+// maps a channel ID to its own template; other channels use Default.
+// ExcerptLength is the number of characters of the analyzed text included.
+// The worker broadcasts an alert to each of Targets for every anomalous
+// result it analyzes.
+type AlertsConfig struct {
+	ExcerptLength int                            `json:"excerpt_length"`
+	Default       AlertTemplateConfig            `json:"default"`
+	Channels      map[string]AlertTemplateConfig `json:"channels"`
+	Targets       []string                       `json:"targets"`
+}
+
+// AlertTemplateConfig is a Go text/template rendered with the alert's fields
+// (.Score, .Confidence, .Severity, .TopAnalyzer, .Excerpt, ...). Format is
+// "json" or "slack" (Block Kit); an empty Template keeps the default wording.
+type AlertTemplateConfig struct {
+	Format   string `json:"format"`
+	Template string `json:"template"`
+}
+
 // DetectorConfig contains detector configuration
 type DetectorConfig struct {
 	Enabled bool `json:"enabled"`
+	// WarmupTimeout bounds each startup warm-up run before serving traffic;
+	// a failed run is retried after WarmupBackoff, doubling up to a minute
+	WarmupTimeout  time.Duration               `json:"warmup_timeout"`
+	WarmupBackoff  time.Duration               `json:"warmup_backoff"`
+	Severity       SeverityConfig              `json:"severity"`
+	Thresholds     ThresholdsConfig            `json:"thresholds"`
+	Confidence     ConfidenceConfig            `json:"confidence"`
+	Normalization  NormalizationConfig         `json:"normalization"`
+	Combination    CombinationConfig           `json:"combination"`
+	CodeBlocks     CodeBlocksConfig            `json:"code_blocks"`
+	Boilerplate    BoilerplateConfig           `json:"boilerplate"`
+	Execution      ExecutionConfig             `json:"execution"`
+	CircuitBreaker CircuitBreakerConfig        `json:"circuit_breaker"`
+	Health         HealthConfig                `json:"health"`
+	Fallbacks      map[string][]FallbackConfig `json:"fallbacks"`
+	Segmentation   SegmentationConfig          `json:"segmentation"`
+	Cache          CacheConfig                 `json:"cache"`
+	Tuning         TuningConfig                `json:"tuning"`
+	Language       LanguageConfig              `json:"language"`
+	Analyzers      map[string]AnalyzerConfig   `json:"analyzers"`
+	// ContentType, unless auto, is used in place of classifying texts whose
+	// content type is left to the detector
+	ContentType string `json:"content_type"`
+	// EnabledAnalyzers, when set, are the only analyzers run
+	EnabledAnalyzers []string `json:"enabled_analyzers"`
+	// Weights are laid over the content profile weights of the named analyzers
+	Weights map[string]float64 `json:"weights"`
+	// Presets are selectable by name, alongside the built-in presets
+	Presets map[string]PresetConfig `json:"presets"`
+}
+
+// AnalyzerConfig tunes one analyzer by name: Parameters are passed to its
+// Configure method, checked against the parameters it documents, and a
+// disabled analyzer is not registered. Analyzers without an entry run with
+// their defaults.
+type AnalyzerConfig struct {
+	Disabled   bool                   `json:"disabled"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
+// AnalyzerEnabled reports whether the named analyzer is to be registered:
+// it is not disabled, and listed in EnabledAnalyzers when that is set
+func (c DetectorConfig) AnalyzerEnabled(name string) bool {
+	if c.Analyzers[name].Disabled {
+		return false
+	}
+	if len(c.EnabledAnalyzers) == 0 {
+		return true
+	}
+	for _, enabled := range c.EnabledAnalyzers {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheConfig lists the deterministic analyzers whose results are cached by
+// content hash, and how many results are kept per analyzer. Nothing is
+// cached by default.
+type CacheConfig struct {
+	Analyzers []string `json:"analyzers"`
+	Size      int      `json:"size"`
+}
+
+// SegmentationConfig selects how text is split into sentences: "rules"
+// understands abbreviations, initials, decimals and ellipses, while
+// "punctuation" splits at every '.', '!' or '?'. Abbreviations are added to
+// the built-in list of words whose period never ends a sentence. Words
+// selects how text is split into words: "auto" segments the words of text
+// detected as Chinese, Japanese, Thai or another script without spaces
+// between words, while "whitespace" splits on whitespace only.
+type SegmentationConfig struct {
+	Mode          string   `json:"mode"`
+	Abbreviations []string `json:"abbreviations"`
+	Words         string   `json:"words"`
+}
+
+// LanguageConfig sets whether the language of a text is detected once per
+// analysis and shared with every analyzer, and reported with the result.
+// Off, each analyzer that needs the language detects it itself.
+type LanguageConfig struct {
+	Shared bool `json:"shared"`
+}
+
+// TuningConfig sets the costs of a flagged normal text and of a missed
+// anomalous one, weighed when tuning a decision threshold for cost
+type TuningConfig struct {
+	FalsePositiveCost float64 `json:"false_positive_cost"`
+	FalseNegativeCost float64 `json:"false_negative_cost"`
+}
+
+// CircuitBreakerConfig sets when an analyzer that keeps failing is left out
+// of analyses: after Failures consecutive errors or timeouts, until a probe
+// made every Cooldown finds it working again. Failures of 0 disables it.
+type CircuitBreakerConfig struct {
+	Failures int           `json:"failures"`
+	Cooldown time.Duration `json:"cooldown"`
+}
+
+// HealthConfig lists the analyzers that must pass their health check, at
+// startup and on GET /api/v1/analyzers/health, for the service to report
+// ready; empty requires all of them. Timeout bounds a check of them all.
+type HealthConfig struct {
+	Required []string      `json:"required"`
+	Timeout  time.Duration `json:"timeout"`
+}
+
+// FallbackConfig names an analyzer whose score stands in for another's when
+// that one can't run for an input, its precondition unmet or its circuit
+// open. Fallbacks are keyed by the analyzer they stand in for and tried in
+// order; the first with a result counts at Weight, in (0, 1], times the
+// confidence weight of the analyzer it replaces.
+type FallbackConfig struct {
+	Analyzer string  `json:"analyzer"`
+	Weight   float64 `json:"weight"`
+}
+
+// ExecutionConfig orders the analyzers, cheapest first, and bounds how long
+// one analysis may take. With a budget analyzers run one at a time in order
+// and those that would start after it is spent are skipped; 0 runs them all
+// at once.
+type ExecutionConfig struct {
+	Order  []string      `json:"order"`
+	Budget time.Duration `json:"budget"`
+}
+
+// NormalizationConfig selects how analyzer scores are brought onto a common
+// scale before weighting: none, zscore, minmax or percentile, each against a
+// running baseline of an analyzer's last Window scores. Scores are weighted
+// raw until the baseline holds MinSamples of them.
+type NormalizationConfig struct {
+	Strategy   string `json:"strategy"`
+	Window     int    `json:"window"`
+	MinSamples int    `json:"min_samples"`
+}
+
+// CombinationConfig selects how analyzer results are combined into a
+// verdict: "fusion" flags the weighted mean score above the content type's
+// threshold, while with "vote" every analyzer votes anomalous when its score
+// is above its entry in VoteThresholds, or the content type's threshold, and
+// the result is flagged when the weighted share of anomalous votes is above
+// Quorum.
+type CombinationConfig struct {
+	Mode           string             `json:"mode"`
+	Quorum         float64            `json:"quorum"`
+	VoteThresholds map[string]float64 `json:"vote_thresholds"`
+}
+
+// CodeBlocksConfig selects how code blocks within prose are handled: "off"
+// analyzes mixed documents as one text, "split" analyzes prose and code with
+// their own profiles and combines the results, and "strip" analyzes only the
+// prose. Fenced blocks are always recognized, indented ones with Indented.
+type CodeBlocksConfig struct {
+	Mode     string `json:"mode"`
+	Indented bool   `json:"indented"`
+}
+
+// BoilerplateConfig enables stripping boilerplate before analysis: text
+// matching any of Patterns, regular expressions, is removed, and batches
+// also drop the paragraphs of at least MinLength characters repeated, up to
+// case, punctuation and digits, in MinRepeats or more of their texts
+type BoilerplateConfig struct {
+	Enabled    bool     `json:"enabled"`
+	Patterns   []string `json:"patterns"`
+	MinRepeats int      `json:"min_repeats"`
+	MinLength  int      `json:"min_length"`
+}
+
+// ConfidenceConfig holds the range per-analyzer confidences are clamped to
+// and how they are aggregated: mean, min, weighted-mean or harmonic-mean.
+// Analyzers less confident than MinContributing are left out of the
+// ensemble; 0 lets every analyzer contribute.
+type ConfidenceConfig struct {
+	Floor           float64 `json:"floor"`
+	Ceiling         float64 `json:"ceiling"`
+	Aggregation     string  `json:"aggregation"`
+	MinContributing float64 `json:"min_contributing"`
+}
+
+// ThresholdsConfig holds, per content type, the score above which a result
+// is flagged as anomalous
+type ThresholdsConfig struct {
+	Prose float64 `json:"prose"`
+	Code  float64 `json:"code"`
+	Data  float64 `json:"data"`
+	Log   float64 `json:"log"`
+}
+
+// SeverityConfig holds the score bands used to classify result severity
+type SeverityConfig struct {
+	Low           float64 `json:"low"`
+	Medium        float64 `json:"medium"`
+	High          float64 `json:"high"`
+	Critical      float64 `json:"critical"`
+	MinConfidence float64 `json:"min_confidence"`
 }
 
 // AuthConfig contains authentication configuration
 type AuthConfig struct {
-	JWTSecret string        `json:"jwt_secret"`
-	TokenTTL  time.Duration `json:"token_ttl"`
+	JWTSecret      string               `json:"jwt_secret"`
+	TokenTTL       time.Duration        `json:"token_ttl"`
+	PasswordPolicy PasswordPolicyConfig `json:"password_policy"`
+}
+
+// PasswordPolicyConfig controls the password rules enforced on registration
+type PasswordPolicyConfig struct {
+	MinLength      int  `json:"min_length"`
+	MaxLength      int  `json:"max_length"`
+	RequireUpper   bool `json:"require_upper"`
+	RequireLower   bool `json:"require_lower"`
+	RequireDigit   bool `json:"require_digit"`
+	RequireSpecial bool `json:"require_special"`
+	// CommonPasswordsFile is a newline-separated list of breached or common
+	// passwords to reject; empty disables the check
+	CommonPasswordsFile string `json:"common_passwords_file"`
 }
 
 // JWTConfig contains JWT configuration
@@ -71,13 +354,299 @@ type JWTConfig struct {
 
 // LoggingConfig contains logging configuration
 type LoggingConfig struct {
-	Level string `json:"level"`
+	Level  string `json:"level"`
+	Format string `json:"format"` // json or console
+}
+
+// WorkerConfig contains queue consumer concurrency configuration
+type WorkerConfig struct {
+	Message   ConsumerConfig `json:"message"`
+	Broadcast ConsumerConfig `json:"broadcast"`
+	Stream    ConsumerConfig `json:"stream"`
+	Bridge    BridgeConfig   `json:"bridge"`
+	Jobs      JobsConfig     `json:"jobs"`
+	Outbox    OutboxConfig   `json:"outbox"`
+}
+
+// ConsumerConfig bounds the concurrency of a single queue consumer
+type ConsumerConfig struct {
+	Workers     int `json:"workers"`       // worker goroutines per queue
+	MaxInFlight int `json:"max_in_flight"` // messages processed concurrently across all workers
+	// Near-identical anomalies within this window are counted instead of
+	// passed on; zero disables de-duplication
+	SuppressionWindow time.Duration `json:"suppression_window"`
+	// How long the occurrence count of a kept anomaly is stored after its
+	// last duplicate; zero stores it without expiry
+	OccurrenceRetention time.Duration `json:"occurrence_retention"`
+}
+
+// BridgeConfig connects the simple API to the detection pipeline: the worker
+// analyzes the requests published on RequestChannel and publishes each result
+// to ResultChannel and appends it to ResultStream, whichever are set
+type BridgeConfig struct {
+	Enabled        bool   `json:"enabled"`
+	RequestChannel string `json:"request_channel"`
+	ResultChannel  string `json:"result_channel"`
+	ResultStream   string `json:"result_stream"`
+	Workers        int    `json:"workers"`       // goroutines running detection
+	MaxInFlight    int    `json:"max_in_flight"` // requests queued or analyzed at once
+}
+
+// JobsConfig controls background jobs. The API queues long operations such
+// as retraining in Redis and the first worker to poll claims each one;
+// Enabled only controls whether this worker runs jobs.
+type JobsConfig struct {
+	Enabled      bool          `json:"enabled"`
+	Workers      int           `json:"workers"`       // jobs run at once per worker process
+	PollInterval time.Duration `json:"poll_interval"` // delay between checks for queued jobs and cancellations
+	LeaseTimeout time.Duration `json:"lease_timeout"` // how long a running job stays claimed without a heartbeat before it is requeued
+	KeyPrefix    string        `json:"key_prefix"`    // prefix of the Redis keys holding jobs
+	Retention    time.Duration `json:"retention"`     // how long finished jobs can be queried
+	Retrain      RetrainConfig `json:"retrain"`
+}
+
+// OutboxConfig persists the worker's results to the database through a
+// durable local outbox: each result is appended to a file in Dir before it
+// is acknowledged, and a flusher commits the results to the database in
+// order, retrying failed commits after a delay doubling from InitialBackoff
+// up to MaxBackoff until they succeed
+type OutboxConfig struct {
+	Enabled        bool          `json:"enabled"`
+	Dir            string        `json:"dir"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+}
+
+// RetrainConfig describes the data the neural detector is retrained on
+type RetrainConfig struct {
+	Dataset    string        `json:"dataset"`     // JSON dataset file used by the "dataset" source
+	Lookback   time.Duration `json:"lookback"`    // age of the oldest stored result used by the "stored" source
+	MaxRecords int           `json:"max_records"` // stored results read per retraining
+	// Timeout bounds a retraining; the best model trained by then is kept
+	Timeout time.Duration `json:"timeout"`
+}
+
+// SinksConfig selects where detection results are written, in addition to
+// the caller's own output; any number of sinks may be enabled at once
+type SinksConfig struct {
+	Stdout    StdoutSinkConfig    `json:"stdout"`
+	File      FileSinkConfig      `json:"file"`
+	Webhook   WebhookSinkConfig   `json:"webhook"`
+	Kafka     KafkaSinkConfig     `json:"kafka"`
+	Slack     SlackSinkConfig     `json:"slack"`
+	PagerDuty PagerDutySinkConfig `json:"pagerduty"`
+}
+
+// StdoutSinkConfig writes each result as a JSON line to standard output
+type StdoutSinkConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// FileSinkConfig appends each result as a JSON line to Path. Once the file
+// reaches MaxSizeMB it is rotated to Path.1, keeping MaxBackups old files.
+type FileSinkConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Path       string `json:"path"`
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxBackups int    `json:"max_backups"`
+}
+
+// WebhookSinkConfig POSTs each result as JSON to URL. With a Secret, the
+// body's HMAC-SHA256 is sent in the X-Alienator-Signature header.
+type WebhookSinkConfig struct {
+	Enabled bool          `json:"enabled"`
+	URL     string        `json:"url"`
+	Secret  string        `json:"secret"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+// KafkaSinkConfig produces each result to Topic through a Kafka REST proxy
+// (Confluent REST API v2) at RESTURL, keyed by the result ID
+type KafkaSinkConfig struct {
+	Enabled bool          `json:"enabled"`
+	RESTURL string        `json:"rest_url"`
+	Topic   string        `json:"topic"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+// SlackSinkConfig posts an alert for each result scoring at least MinScore,
+// to an incoming WebhookURL or, with a bot Token, to Channel through
+// chat.postMessage. Template is an alert template (see AlertTemplateConfig)
+// for the message text. Rate-limited posts are retried up to MaxRetries
+// times, honoring Slack's Retry-After.
+type SlackSinkConfig struct {
+	Enabled    bool          `json:"enabled"`
+	WebhookURL string        `json:"webhook_url"`
+	Token      string        `json:"token"`
+	Channel    string        `json:"channel"`
+	APIURL     string        `json:"api_url"` // chat.postMessage endpoint, for testing or proxies
+	Template   string        `json:"template"`
+	MinScore   float64       `json:"min_score"`
+	MaxRetries int           `json:"max_retries"`
+	Timeout    time.Duration `json:"timeout"`
+}
+
+// PagerDutySinkConfig triggers a PagerDuty incident through the Events API
+// v2 for each result scoring at least MinScore, routed by the integration's
+// RoutingKey. Incidents are deduplicated by the content hash of the input;
+// with Resolve, a later result for the same input below MinScore resolves
+// the incident if it arrives within ResolveWindow of the last trigger.
+type PagerDutySinkConfig struct {
+	Enabled    bool          `json:"enabled"`
+	RoutingKey string        `json:"routing_key"`
+	URL        string        `json:"url"`    // Events API endpoint, defaults to PagerDuty's
+	Source     string        `json:"source"` // reported as the affected system
+	Template   string        `json:"template"`
+	MinScore   float64       `json:"min_score"`
+	Resolve    bool          `json:"resolve"`
+	MaxRetries int           `json:"max_retries"`
+	Timeout    time.Duration `json:"timeout"`
+	// ResolveWindow is how long a triggered incident is remembered for
+	// resolving
+	ResolveWindow time.Duration `json:"resolve_window"`
+}
+
+// SigningConfig makes detection results tamper-evident by signing each
+// verdict together with a hash of its input and the signing time. Algorithm
+// is "hmac-sha256", keyed by Secret, or "ed25519", with the PEM-encoded
+// PKCS#8 private key in PrivateKeyFile. KeyID is recorded with every
+// signature so that keys can be rotated.
+type SigningConfig struct {
+	Enabled        bool   `json:"enabled"`
+	Algorithm      string `json:"algorithm"`
+	KeyID          string `json:"key_id"`
+	Secret         string `json:"secret"`
+	PrivateKeyFile string `json:"private_key_file"`
+}
+
+// HashingConfig selects how inputs are hashed wherever the service needs to
+// recognize "the same input": analysis cache keys and de-duplication
+// fingerprints. Algorithm is "sha256", for collision resistance, or
+// "xxhash", for speed. Unicode is "nfc", "nfkc" or "none"; NFKC also folds
+// compatibility characters such as fullwidth letters.
+type HashingConfig struct {
+	Algorithm          string `json:"algorithm"`
+	Unicode            string `json:"unicode"`
+	CollapseWhitespace bool   `json:"collapse_whitespace"` // treat any run of whitespace as one space
+}
+
+// OutputConfig controls how results are written out. Scores and confidences
+// are rounded to Precision decimals in API responses, stored detections and
+// CLI output; the detector computes with full precision. Metadata is how much
+// metadata API responses carry, "none", "summary" or "full", unless a request
+// asks for another level; MetadataKeys are metadata keys kept at every level.
+type OutputConfig struct {
+	Precision    int      `json:"precision"`
+	Metadata     string   `json:"metadata"`
+	MetadataKeys []string `json:"metadata_keys"`
+}
+
+// MetricsConfig controls how per-analyzer metrics are recorded. With a
+// positive BatchInterval, updates are held and written every BatchInterval,
+// or once BatchSize are held, rather than on each analysis; scrapes lag by
+// at most the interval. Zero records updates directly.
+type MetricsConfig struct {
+	BatchInterval time.Duration `json:"batch_interval"`
+	BatchSize     int           `json:"batch_size"`
+}
+
+// ReviewConfig controls the review queue. Stored detections whose score is
+// within Band of their threshold are queued for a human label; labels then
+// decide whether they are used to retrain the detector. The default band is
+// 0.05; zero disables the queue.
+type ReviewConfig struct {
+	Band float64 `json:"band"`
+}
+
+// ExplainOnlyConfig controls explain-only detections, asked for with
+// explain_only=true on /anomalies/detect for quick feedback on why text looks
+// suspicious. They leave out the Exclude analyzers, the expensive ones, and
+// are neither stored, signed nor queued for review; the full detection stays
+// the authoritative one.
+type ExplainOnlyConfig struct {
+	Enabled bool     `json:"enabled"`
+	Exclude []string `json:"exclude"`
+}
+
+// ProvenanceConfig tags each detection with the source its data came from,
+// e.g. "support-tickets" or "forum-posts", so detection rates can be
+// compared across sources. Requests name their source; those that don't are
+// tagged Default. When Sources is set, only the sources it lists are
+// accepted.
+type ProvenanceConfig struct {
+	Default string   `json:"default"`
+	Sources []string `json:"sources"`
+}
+
+// MaxSourceLength bounds the length of a source name
+const MaxSourceLength = 64
+
+// Resolve returns the source to tag a detection naming source with: source
+// itself, or Default when it names none. It fails for a source outside
+// Sources.
+func (p ProvenanceConfig) Resolve(source string) (string, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		source = p.Default
+	}
+	if len(source) > MaxSourceLength {
+		return "", fmt.Errorf("source is longer than %d characters", MaxSourceLength)
+	}
+	if source == "" || len(p.Sources) == 0 {
+		return source, nil
+	}
+	for _, allowed := range p.Sources {
+		if source == allowed {
+			return source, nil
+		}
+	}
+	return "", fmt.Errorf("unknown source %q (expected one of %s)", source, strings.Join(p.Sources, ", "))
 }
 
-// RateLimitConfig contains rate limiting configuration
+// RateLimitConfig limits the requests of each client IP. Routes at or below
+// one of a group's Paths, matched by whole path segments, are limited by the
+// first such group instead, with a budget of their own, so that cheap routes
+// and expensive ones needn't share a limit; RequestsPerMinute and Burst limit
+// the rest.
 type RateLimitConfig struct {
-	RequestsPerMinute int `json:"requests_per_minute"`
-	Burst             int `json:"burst"`
+	RequestsPerMinute int              `json:"requests_per_minute"`
+	Burst             int              `json:"burst"`
+	Groups            []RateLimitGroup `json:"groups"`
+}
+
+// RateLimitGroup is the rate limit of a group of routes
+type RateLimitGroup struct {
+	Name              string   `json:"name"`
+	Paths             []string `json:"paths"` // paths the group's routes are at or below
+	RequestsPerMinute int      `json:"requests_per_minute"`
+	Burst             int      `json:"burst"`
+}
+
+// LimitsConfig bounds each API request and is reported to clients at
+// GET /api/v1/limits. Bodies over MaxBodyBytes are rejected with 413; NDJSON
+// streams are bounded instead by MaxLineBytes per line and MaxBatchItems
+// items. RequestTimeout bounds the time spent on a request. Zero disables a
+// limit, except MaxLineBytes.
+type LimitsConfig struct {
+	MaxBodyBytes   int           `json:"max_body_bytes"`
+	MaxBatchItems  int           `json:"max_batch_items"`
+	MaxLineBytes   int           `json:"max_line_bytes"`
+	RequestTimeout time.Duration `json:"request_timeout"`
+}
+
+// ConcurrencyConfig bounds the requests served at once. Requests over a limit
+// are shed with 503 and a Retry-After header; analysis requests, which are
+// far more expensive than health checks, have their own lower limit.
+type ConcurrencyConfig struct {
+	MaxInFlight         int           `json:"max_in_flight"`
+	AnalysisMaxInFlight int           `json:"analysis_max_in_flight"`
+	RetryAfter          time.Duration `json:"retry_after"`
+}
+
+// VersioningConfig contains response schema version negotiation configuration
+type VersioningConfig struct {
+	Header         string `json:"header"`
+	DefaultVersion string `json:"default_version"`
 }
 
 // BrokerConfig configuration
@@ -95,64 +664,436 @@ type QueueConfig struct {
 	BufferSize int           `json:"buffer_size"`
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from environment variables over the defaults.
+// Unparseable values are ignored; use LoadFile to have them reported.
 func Load() *Config {
+	cfg := Defaults()
+	applyEnv(cfg, &envReader{})
+	return cfg
+}
+
+// Defaults returns the configuration used when nothing is overridden
+func Defaults() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         getEnvInt("PORT", 8080),
-			Host:         getEnv("HOST", "0.0.0.0"),
-			ReadTimeout:  time.Duration(getEnvInt("READ_timeout", 10)) * time.Second,
-			WriteTimeout: time.Duration(getEnvInt("write_timeout", 10)) * time.Second,
-			IdleTimeout:  time.Duration(getEnvInt("idle_timeout", 60)) * time.Second,
+			Port:         8080,
+			Host:         "0.0.0.0",
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  60 * time.Second,
+			TLS: TLSConfig{
+				MinVersion: "1.2",
+			},
+			ShutdownTimeout: 30 * time.Second,
+			DrainDelay:      5 * time.Second,
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			DBName:   getEnv("DB_NAME", "vibecast"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+			Host:     "localhost",
+			Port:     5432,
+			User:     "postgres",
+			Password: "password",
+			DBName:   "vibecast",
+			SSLMode:  "disable",
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnvInt("REDIS_PORT", 6379),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvInt("REDIS_DB", 0),
+			Host:     "localhost",
+			Port:     6379,
+			Password: "",
+			DB:       0,
 		},
 		NATS: NATSConfig{
-			URL: getEnv("NATS_URL", "nats://localhost:4222"),
+			URL: "nats://localhost:4222",
+		},
+		Broadcast: BroadcastConfig{
+			MaxRetries:     3,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     5000 * time.Millisecond,
+			Alerts: AlertsConfig{
+				ExcerptLength: 200,
+				Default: AlertTemplateConfig{
+					Format: "json",
+				},
+			},
+		},
+		Detector: DetectorConfig{
+			WarmupTimeout: 60 * time.Second,
+			WarmupBackoff: time.Second,
+			Severity: SeverityConfig{
+				Low:           0.5,
+				Medium:        0.7,
+				High:          0.85,
+				Critical:      0.95,
+				MinConfidence: 0.3,
+			},
+			Thresholds: ThresholdsConfig{
+				Prose: 0.7,
+				Code:  0.7,
+				Data:  0.7,
+				Log:   0.7,
+			},
+			Confidence: ConfidenceConfig{
+				Floor:           0.0,
+				Ceiling:         1.0,
+				Aggregation:     "mean",
+				MinContributing: 0.0,
+			},
+			Normalization: NormalizationConfig{
+				Strategy:   "none",
+				Window:     1000,
+				MinSamples: 30,
+			},
+			Combination: CombinationConfig{
+				Mode:   "fusion",
+				Quorum: 0.5,
+			},
+			CodeBlocks: CodeBlocksConfig{
+				Mode:     "off",
+				Indented: true,
+			},
+			Boilerplate: BoilerplateConfig{
+				Patterns: []string{
+					`(?ms)^--[ \t]*$.*\z`,
+					`(?im)^.*\b(confidential|privileged)\b.*\b(intended|addressee|recipient)\b.*$`,
+					`(?im)^sent from my \w+.*$`,
+					`(?im)^.*\bunsubscribe\b.*$`,
+				},
+				MinRepeats: 3,
+				MinLength:  40,
+			},
+			Execution: ExecutionConfig{
+				Order: []string{
+					"linguistic", "cryptographic", "entropy", "compression", "uniformity",
+					"repetition", "sentiment", "claims", "injection", "watermark", "embedding",
+				},
+			},
+			CircuitBreaker: CircuitBreakerConfig{
+				Failures: 5,
+				Cooldown: 30 * time.Second,
+			},
+			Health: HealthConfig{
+				Timeout: 10 * time.Second,
+			},
+			Segmentation: SegmentationConfig{
+				Mode:  "rules",
+				Words: "auto",
+			},
+			Cache: CacheConfig{
+				Size: 1024,
+			},
+			Language: LanguageConfig{
+				Shared: true,
+			},
+			Tuning: TuningConfig{
+				FalsePositiveCost: 1,
+				FalseNegativeCost: 1,
+			},
+			ContentType: "auto",
 		},
 		Auth: AuthConfig{
-			JWTSecret: getEnv("JWT_SECRET", "your-secret-key"),
-			TokenTTL:  time.Duration(getEnvInt("TOKEN_TTL", 24)) * time.Hour,
+			JWTSecret: "your-secret-key",
+			TokenTTL:  24 * time.Hour,
+			PasswordPolicy: PasswordPolicyConfig{
+				MinLength:           8,
+				MaxLength:           72,
+				RequireUpper:        true,
+				RequireLower:        true,
+				RequireDigit:        true,
+				RequireSpecial:      true,
+				CommonPasswordsFile: "",
+			},
 		},
 		JWT: JWTConfig{
-			Secret:         getEnv("JWT_SECRET", "your-secret-key"),
-			ExpirationTime: time.Duration(getEnvInt("JWT_EXPIRATION_HOURS", 24)) * time.Hour,
-			Issuer:         getEnv("JWT_ISSUER", "alienator-system"),
+			Secret:         "your-secret-key",
+			ExpirationTime: 24 * time.Hour,
+			Issuer:         "alienator-system",
 		},
 		Logging: LoggingConfig{
-			Level: getEnv("LOG_LEVEL", "info"),
+			Level:  "info",
+			Format: "json",
 		},
 		RateLimit: RateLimitConfig{
-			RequestsPerMinute: getEnvInt("RATE_LIMIT_REQUESTS_PER_MINUTE", 1000),
-			Burst:             getEnvInt("RATE_LIMIT_BURST", 100),
+			RequestsPerMinute: 1000,
+			Burst:             100,
+			Groups: []RateLimitGroup{
+				{Name: "health", Paths: []string{"/health", "/ready", "/version", "/metrics"}, RequestsPerMinute: 6000, Burst: 600},
+				{Name: "auth", Paths: []string{"/api/v1/auth/"}, RequestsPerMinute: 60, Burst: 10},
+				{Name: "analysis", Paths: []string{"/api/v1/anomalies/detect"}, RequestsPerMinute: 300, Burst: 30},
+			},
+		},
+		Limits: LimitsConfig{
+			MaxBodyBytes:   1 << 20,
+			MaxBatchItems:  1000,
+			MaxLineBytes:   1 << 20,
+			RequestTimeout: 30 * time.Second,
+		},
+		Metrics: MetricsConfig{
+			BatchSize: 4096,
+		},
+		Concurrency: ConcurrencyConfig{
+			MaxInFlight:         1000,
+			AnalysisMaxInFlight: runtime.NumCPU() * 4,
+			RetryAfter:          time.Second,
+		},
+		Versioning: VersioningConfig{
+			Header:         "Accept-Version",
+			DefaultVersion: "v2",
+		},
+		Worker: WorkerConfig{
+			Message: ConsumerConfig{
+				Workers:             runtime.NumCPU(),
+				MaxInFlight:         runtime.NumCPU()*2,
+				OccurrenceRetention: 7 * 24 * time.Hour,
+			},
+			Broadcast: ConsumerConfig{
+				Workers:     1,
+				MaxInFlight: 1,
+			},
+			Stream: ConsumerConfig{
+				Workers:     1,
+				MaxInFlight: 2,
+			},
+			Bridge: BridgeConfig{
+				Enabled:        true,
+				RequestChannel: "alienator:analyze",
+				ResultChannel:  "alienator:results",
+				Workers:        runtime.NumCPU(),
+				MaxInFlight:    runtime.NumCPU()*2,
+			},
+			Jobs: JobsConfig{
+				Enabled:      true,
+				Workers:      1,
+				PollInterval: 2 * time.Second,
+				LeaseTimeout: 30 * time.Second,
+				KeyPrefix:    "alienator:jobs:",
+				Retention:    7 * 24 * time.Hour,
+				Retrain: RetrainConfig{
+					Lookback:   7 * 24 * time.Hour,
+					MaxRecords: 10000,
+					Timeout:    10 * time.Minute,
+				},
+			},
+			Outbox: OutboxConfig{
+				Dir:            "alienator-outbox",
+				InitialBackoff: 500 * time.Millisecond,
+				MaxBackoff:     30 * time.Second,
+			},
+		},
+		Sinks: SinksConfig{
+			File: FileSinkConfig{
+				Path:       "alienator-results.jsonl",
+				MaxSizeMB:  100,
+				MaxBackups: 5,
+			},
+			Webhook: WebhookSinkConfig{
+				Timeout: 5 * time.Second,
+			},
+			Kafka: KafkaSinkConfig{
+				Topic:   "alienator-results",
+				Timeout: 5 * time.Second,
+			},
+			Slack: SlackSinkConfig{
+				MinScore:   0.8,
+				MaxRetries: 3,
+				Timeout:    5 * time.Second,
+			},
+			PagerDuty: PagerDutySinkConfig{
+				Source:        "alienator",
+				MinScore:      0.9,
+				Resolve:       true,
+				MaxRetries:    3,
+				Timeout:       5 * time.Second,
+				ResolveWindow: 24 * time.Hour,
+			},
+		},
+		Signing: SigningConfig{
+			Algorithm: "hmac-sha256",
+		},
+		Hashing: HashingConfig{
+			Algorithm:          "sha256",
+			Unicode:            "nfc",
+			CollapseWhitespace: true,
+		},
+		Output: OutputConfig{
+			Precision: models.DefaultPrecision,
+			Metadata:  string(models.MetadataSummary),
+		},
+		Review: ReviewConfig{
+			Band: 0.05,
+		},
+		ExplainOnly: ExplainOnlyConfig{
+			Enabled: true,
+			Exclude: []string{"embedding", "neural-detector"},
 		},
 	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+// applyEnv overrides cfg with the environment variables that are set
+func applyEnv(cfg *Config, env *envReader) {
+	env.intVar(&cfg.Server.Port, "PORT")
+	env.stringVar(&cfg.Server.Host, "HOST")
+	env.durationVar(&cfg.Server.ReadTimeout, "READ_timeout", time.Second)
+	env.durationVar(&cfg.Server.WriteTimeout, "write_timeout", time.Second)
+	env.durationVar(&cfg.Server.IdleTimeout, "idle_timeout", time.Second)
+	env.boolVar(&cfg.Server.TLS.Enabled, "TLS_ENABLED")
+	env.stringVar(&cfg.Server.TLS.CertFile, "TLS_CERT_FILE")
+	env.stringVar(&cfg.Server.TLS.KeyFile, "TLS_KEY_FILE")
+	env.stringVar(&cfg.Server.TLS.ClientCAFile, "TLS_CLIENT_CA_FILE")
+	env.boolVar(&cfg.Server.TLS.AdminClientAuth, "TLS_ADMIN_CLIENT_AUTH")
+	env.stringVar(&cfg.Server.TLS.MinVersion, "TLS_MIN_VERSION")
+	env.intVar(&cfg.Server.TLS.RedirectPort, "TLS_REDIRECT_PORT")
+	env.durationVar(&cfg.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT", time.Second)
+	env.durationVar(&cfg.Server.DrainDelay, "SHUTDOWN_DRAIN_DELAY", time.Second)
+	env.stringVar(&cfg.Database.Host, "DB_HOST")
+	env.intVar(&cfg.Database.Port, "DB_PORT")
+	env.stringVar(&cfg.Database.User, "DB_USER")
+	env.stringVar(&cfg.Database.Password, "DB_PASSWORD")
+	env.stringVar(&cfg.Database.DBName, "DB_NAME")
+	env.stringVar(&cfg.Database.SSLMode, "DB_SSL_MODE")
+	env.stringVar(&cfg.Redis.Host, "REDIS_HOST")
+	env.intVar(&cfg.Redis.Port, "REDIS_PORT")
+	env.stringVar(&cfg.Redis.Password, "REDIS_PASSWORD")
+	env.intVar(&cfg.Redis.DB, "REDIS_DB")
+	env.stringVar(&cfg.NATS.URL, "NATS_URL")
+	env.intVar(&cfg.Broadcast.MaxRetries, "BROADCAST_MAX_RETRIES")
+	env.durationVar(&cfg.Broadcast.InitialBackoff, "BROADCAST_INITIAL_BACKOFF_MS", time.Millisecond)
+	env.durationVar(&cfg.Broadcast.MaxBackoff, "BROADCAST_MAX_BACKOFF_MS", time.Millisecond)
+	env.intVar(&cfg.Broadcast.Alerts.ExcerptLength, "BROADCAST_ALERT_EXCERPT_LENGTH")
+	env.stringVar(&cfg.Broadcast.Alerts.Default.Format, "BROADCAST_ALERT_FORMAT")
+	env.stringVar(&cfg.Broadcast.Alerts.Default.Template, "BROADCAST_ALERT_TEMPLATE")
+	env.listVar(&cfg.Broadcast.Alerts.Targets, "BROADCAST_ALERT_TARGETS")
+	env.durationVar(&cfg.Detector.WarmupTimeout, "DETECTOR_WARMUP_TIMEOUT", time.Second)
+	env.durationVar(&cfg.Detector.WarmupBackoff, "DETECTOR_WARMUP_BACKOFF_MS", time.Millisecond)
+	env.floatVar(&cfg.Detector.Severity.Low, "SEVERITY_LOW")
+	env.floatVar(&cfg.Detector.Severity.Medium, "SEVERITY_MEDIUM")
+	env.floatVar(&cfg.Detector.Severity.High, "SEVERITY_HIGH")
+	env.floatVar(&cfg.Detector.Severity.Critical, "SEVERITY_CRITICAL")
+	env.floatVar(&cfg.Detector.Severity.MinConfidence, "SEVERITY_MIN_CONFIDENCE")
+	env.floatVar(&cfg.Detector.Thresholds.Prose, "DETECTOR_THRESHOLD_PROSE")
+	env.floatVar(&cfg.Detector.Thresholds.Code, "DETECTOR_THRESHOLD_CODE")
+	env.floatVar(&cfg.Detector.Thresholds.Data, "DETECTOR_THRESHOLD_DATA")
+	env.floatVar(&cfg.Detector.Thresholds.Log, "DETECTOR_THRESHOLD_LOG")
+	env.floatVar(&cfg.Detector.Confidence.Floor, "CONFIDENCE_FLOOR")
+	env.floatVar(&cfg.Detector.Confidence.Ceiling, "CONFIDENCE_CEILING")
+	env.stringVar(&cfg.Detector.Confidence.Aggregation, "CONFIDENCE_AGGREGATION")
+	env.floatVar(&cfg.Detector.Confidence.MinContributing, "CONFIDENCE_MIN_CONTRIBUTING")
+	env.stringVar(&cfg.Detector.Normalization.Strategy, "DETECTOR_NORMALIZATION")
+	env.intVar(&cfg.Detector.Normalization.Window, "DETECTOR_NORMALIZATION_WINDOW")
+	env.intVar(&cfg.Detector.Normalization.MinSamples, "DETECTOR_NORMALIZATION_MIN_SAMPLES")
+	env.stringVar(&cfg.Detector.Combination.Mode, "DETECTOR_COMBINATION")
+	env.floatVar(&cfg.Detector.Combination.Quorum, "DETECTOR_VOTE_QUORUM")
+	env.stringVar(&cfg.Detector.CodeBlocks.Mode, "DETECTOR_CODE_BLOCKS")
+	env.boolVar(&cfg.Detector.CodeBlocks.Indented, "DETECTOR_CODE_BLOCKS_INDENTED")
+	env.boolVar(&cfg.Detector.Boilerplate.Enabled, "DETECTOR_STRIP_BOILERPLATE")
+	env.intVar(&cfg.Detector.Boilerplate.MinRepeats, "DETECTOR_BOILERPLATE_MIN_REPEATS")
+	env.intVar(&cfg.Detector.Boilerplate.MinLength, "DETECTOR_BOILERPLATE_MIN_LENGTH")
+	env.listVar(&cfg.Detector.Execution.Order, "DETECTOR_ANALYZER_ORDER")
+	env.durationVar(&cfg.Detector.Execution.Budget, "DETECTOR_BUDGET_MS", time.Millisecond)
+	env.intVar(&cfg.Detector.CircuitBreaker.Failures, "DETECTOR_BREAKER_FAILURES")
+	env.durationVar(&cfg.Detector.CircuitBreaker.Cooldown, "DETECTOR_BREAKER_COOLDOWN", time.Second)
+	env.listVar(&cfg.Detector.Health.Required, "DETECTOR_HEALTH_REQUIRED")
+	env.durationVar(&cfg.Detector.Health.Timeout, "DETECTOR_HEALTH_TIMEOUT", time.Second)
+	env.stringVar(&cfg.Detector.Segmentation.Mode, "SENTENCE_SEGMENTATION")
+	env.listVar(&cfg.Detector.Segmentation.Abbreviations, "SENTENCE_ABBREVIATIONS")
+	env.stringVar(&cfg.Detector.Segmentation.Words, "WORD_SEGMENTATION")
+	env.listVar(&cfg.Detector.Cache.Analyzers, "DETECTOR_CACHE_ANALYZERS")
+	env.intVar(&cfg.Detector.Cache.Size, "DETECTOR_CACHE_SIZE")
+	env.boolVar(&cfg.Detector.Language.Shared, "DETECTOR_LANGUAGE_SHARED")
+	env.floatVar(&cfg.Detector.Tuning.FalsePositiveCost, "DETECTOR_TUNING_FP_COST")
+	env.floatVar(&cfg.Detector.Tuning.FalseNegativeCost, "DETECTOR_TUNING_FN_COST")
+	env.stringVar(&cfg.Detector.ContentType, "DETECTOR_CONTENT_TYPE")
+	env.listVar(&cfg.Detector.EnabledAnalyzers, "DETECTOR_ANALYZERS")
+	env.stringVar(&cfg.Auth.JWTSecret, "JWT_SECRET")
+	env.durationVar(&cfg.Auth.TokenTTL, "TOKEN_TTL", time.Hour)
+	env.intVar(&cfg.Auth.PasswordPolicy.MinLength, "PASSWORD_MIN_LENGTH")
+	env.intVar(&cfg.Auth.PasswordPolicy.MaxLength, "PASSWORD_MAX_LENGTH")
+	env.boolVar(&cfg.Auth.PasswordPolicy.RequireUpper, "PASSWORD_REQUIRE_UPPER")
+	env.boolVar(&cfg.Auth.PasswordPolicy.RequireLower, "PASSWORD_REQUIRE_LOWER")
+	env.boolVar(&cfg.Auth.PasswordPolicy.RequireDigit, "PASSWORD_REQUIRE_DIGIT")
+	env.boolVar(&cfg.Auth.PasswordPolicy.RequireSpecial, "PASSWORD_REQUIRE_SPECIAL")
+	env.stringVar(&cfg.Auth.PasswordPolicy.CommonPasswordsFile, "PASSWORD_COMMON_LIST")
+	env.stringVar(&cfg.JWT.Secret, "JWT_SECRET")
+	env.durationVar(&cfg.JWT.ExpirationTime, "JWT_EXPIRATION_HOURS", time.Hour)
+	env.stringVar(&cfg.JWT.Issuer, "JWT_ISSUER")
+	env.stringVar(&cfg.Logging.Level, "LOG_LEVEL")
+	env.stringVar(&cfg.Logging.Format, "LOG_FORMAT")
+	env.intVar(&cfg.RateLimit.RequestsPerMinute, "RATE_LIMIT_REQUESTS_PER_MINUTE")
+	env.intVar(&cfg.RateLimit.Burst, "RATE_LIMIT_BURST")
+	env.intVar(&cfg.Limits.MaxBodyBytes, "MAX_BODY_BYTES")
+	env.intVar(&cfg.Limits.MaxBatchItems, "MAX_BATCH_ITEMS")
+	env.intVar(&cfg.Limits.MaxLineBytes, "MAX_LINE_BYTES")
+	env.durationVar(&cfg.Limits.RequestTimeout, "REQUEST_TIMEOUT", time.Second)
+	env.durationVar(&cfg.Metrics.BatchInterval, "METRICS_BATCH_INTERVAL", time.Millisecond)
+	env.intVar(&cfg.Metrics.BatchSize, "METRICS_BATCH_SIZE")
+	env.intVar(&cfg.Concurrency.MaxInFlight, "MAX_IN_FLIGHT_REQUESTS")
+	env.intVar(&cfg.Concurrency.AnalysisMaxInFlight, "ANALYSIS_MAX_IN_FLIGHT_REQUESTS")
+	env.durationVar(&cfg.Concurrency.RetryAfter, "LOAD_SHED_RETRY_AFTER", time.Second)
+	env.stringVar(&cfg.Versioning.Header, "API_VERSION_HEADER")
+	env.stringVar(&cfg.Versioning.DefaultVersion, "API_DEFAULT_VERSION")
+	env.intVar(&cfg.Worker.Message.Workers, "WORKER_MESSAGE_WORKERS")
+	env.intVar(&cfg.Worker.Message.MaxInFlight, "WORKER_MESSAGE_MAX_IN_FLIGHT")
+	env.durationVar(&cfg.Worker.Message.SuppressionWindow, "WORKER_MESSAGE_SUPPRESSION_WINDOW", time.Second)
+	env.durationVar(&cfg.Worker.Message.OccurrenceRetention, "WORKER_MESSAGE_OCCURRENCE_RETENTION", time.Hour)
+	env.intVar(&cfg.Worker.Broadcast.Workers, "WORKER_BROADCAST_WORKERS")
+	env.intVar(&cfg.Worker.Broadcast.MaxInFlight, "WORKER_BROADCAST_MAX_IN_FLIGHT")
+	env.intVar(&cfg.Worker.Stream.Workers, "WORKER_STREAM_WORKERS")
+	env.intVar(&cfg.Worker.Stream.MaxInFlight, "WORKER_STREAM_MAX_IN_FLIGHT")
+	env.boolVar(&cfg.Worker.Bridge.Enabled, "WORKER_BRIDGE_ENABLED")
+	env.stringVar(&cfg.Worker.Bridge.RequestChannel, "WORKER_BRIDGE_REQUEST_CHANNEL")
+	env.stringVar(&cfg.Worker.Bridge.ResultChannel, "WORKER_BRIDGE_RESULT_CHANNEL")
+	env.stringVar(&cfg.Worker.Bridge.ResultStream, "WORKER_BRIDGE_RESULT_STREAM")
+	env.intVar(&cfg.Worker.Bridge.Workers, "WORKER_BRIDGE_WORKERS")
+	env.intVar(&cfg.Worker.Bridge.MaxInFlight, "WORKER_BRIDGE_MAX_IN_FLIGHT")
+	env.boolVar(&cfg.Worker.Jobs.Enabled, "WORKER_JOBS_ENABLED")
+	env.intVar(&cfg.Worker.Jobs.Workers, "WORKER_JOBS_WORKERS")
+	env.durationVar(&cfg.Worker.Jobs.PollInterval, "WORKER_JOBS_POLL_INTERVAL", time.Second)
+	env.durationVar(&cfg.Worker.Jobs.LeaseTimeout, "WORKER_JOBS_LEASE_TIMEOUT", time.Second)
+	env.stringVar(&cfg.Worker.Jobs.KeyPrefix, "WORKER_JOBS_KEY_PREFIX")
+	env.durationVar(&cfg.Worker.Jobs.Retention, "WORKER_JOBS_RETENTION", time.Hour)
+	env.stringVar(&cfg.Worker.Jobs.Retrain.Dataset, "WORKER_RETRAIN_DATASET")
+	env.durationVar(&cfg.Worker.Jobs.Retrain.Lookback, "WORKER_RETRAIN_LOOKBACK", time.Hour)
+	env.intVar(&cfg.Worker.Jobs.Retrain.MaxRecords, "WORKER_RETRAIN_MAX_RECORDS")
+	env.durationVar(&cfg.Worker.Jobs.Retrain.Timeout, "WORKER_RETRAIN_TIMEOUT", time.Second)
+	env.boolVar(&cfg.Worker.Outbox.Enabled, "WORKER_OUTBOX_ENABLED")
+	env.stringVar(&cfg.Worker.Outbox.Dir, "WORKER_OUTBOX_DIR")
+	env.durationVar(&cfg.Worker.Outbox.InitialBackoff, "WORKER_OUTBOX_INITIAL_BACKOFF_MS", time.Millisecond)
+	env.durationVar(&cfg.Worker.Outbox.MaxBackoff, "WORKER_OUTBOX_MAX_BACKOFF_MS", time.Millisecond)
+	env.boolVar(&cfg.Sinks.Stdout.Enabled, "SINK_STDOUT_ENABLED")
+	env.boolVar(&cfg.Sinks.File.Enabled, "SINK_FILE_ENABLED")
+	env.stringVar(&cfg.Sinks.File.Path, "SINK_FILE_PATH")
+	env.intVar(&cfg.Sinks.File.MaxSizeMB, "SINK_FILE_MAX_SIZE_MB")
+	env.intVar(&cfg.Sinks.File.MaxBackups, "SINK_FILE_MAX_BACKUPS")
+	env.boolVar(&cfg.Sinks.Webhook.Enabled, "SINK_WEBHOOK_ENABLED")
+	env.stringVar(&cfg.Sinks.Webhook.URL, "SINK_WEBHOOK_URL")
+	env.stringVar(&cfg.Sinks.Webhook.Secret, "SINK_WEBHOOK_SECRET")
+	env.durationVar(&cfg.Sinks.Webhook.Timeout, "SINK_WEBHOOK_TIMEOUT", time.Second)
+	env.boolVar(&cfg.Sinks.Kafka.Enabled, "SINK_KAFKA_ENABLED")
+	env.stringVar(&cfg.Sinks.Kafka.RESTURL, "SINK_KAFKA_REST_URL")
+	env.stringVar(&cfg.Sinks.Kafka.Topic, "SINK_KAFKA_TOPIC")
+	env.durationVar(&cfg.Sinks.Kafka.Timeout, "SINK_KAFKA_TIMEOUT", time.Second)
+	env.boolVar(&cfg.Sinks.Slack.Enabled, "SINK_SLACK_ENABLED")
+	env.stringVar(&cfg.Sinks.Slack.WebhookURL, "SINK_SLACK_WEBHOOK_URL")
+	env.stringVar(&cfg.Sinks.Slack.Token, "SINK_SLACK_TOKEN")
+	env.stringVar(&cfg.Sinks.Slack.Channel, "SINK_SLACK_CHANNEL")
+	env.floatVar(&cfg.Sinks.Slack.MinScore, "SINK_SLACK_MIN_SCORE")
+	env.boolVar(&cfg.Sinks.PagerDuty.Enabled, "SINK_PAGERDUTY_ENABLED")
+	env.stringVar(&cfg.Sinks.PagerDuty.RoutingKey, "SINK_PAGERDUTY_ROUTING_KEY")
+	env.floatVar(&cfg.Sinks.PagerDuty.MinScore, "SINK_PAGERDUTY_MIN_SCORE")
+	env.boolVar(&cfg.Sinks.PagerDuty.Resolve, "SINK_PAGERDUTY_RESOLVE")
+	env.boolVar(&cfg.Signing.Enabled, "SIGNING_ENABLED")
+	env.stringVar(&cfg.Signing.Algorithm, "SIGNING_ALGORITHM")
+	env.stringVar(&cfg.Signing.KeyID, "SIGNING_KEY_ID")
+	env.stringVar(&cfg.Signing.Secret, "SIGNING_SECRET")
+	env.stringVar(&cfg.Signing.PrivateKeyFile, "SIGNING_PRIVATE_KEY_FILE")
+	env.stringVar(&cfg.Hashing.Algorithm, "HASHING_ALGORITHM")
+	env.stringVar(&cfg.Hashing.Unicode, "HASHING_UNICODE")
+	env.boolVar(&cfg.Hashing.CollapseWhitespace, "HASHING_COLLAPSE_WHITESPACE")
+	env.intVar(&cfg.Output.Precision, "OUTPUT_PRECISION")
+	env.stringVar(&cfg.Output.Metadata, "OUTPUT_METADATA")
+	env.listVar(&cfg.Output.MetadataKeys, "OUTPUT_METADATA_KEYS")
+	env.floatVar(&cfg.Review.Band, "REVIEW_BAND")
+	env.boolVar(&cfg.ExplainOnly.Enabled, "EXPLAIN_ONLY_ENABLED")
+	env.listVar(&cfg.ExplainOnly.Exclude, "EXPLAIN_ONLY_EXCLUDE")
+	env.stringVar(&cfg.Provenance.Default, "PROVENANCE_DEFAULT")
+	env.listVar(&cfg.Provenance.Sources, "PROVENANCE_SOURCES")
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-	}
-	return defaultValue
-}
\ No newline at end of file
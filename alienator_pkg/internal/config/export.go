@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Export returns a configuration section as the values a configuration file
+// would hold: keyed by the json tags, with durations written as "60s" and
+// unset lists and maps as empty ones, so that LoadFile reads them back
+// unchanged
+func Export(section interface{}) map[string]interface{} {
+	values, _ := exportValue(reflect.ValueOf(section)).(map[string]interface{})
+	return values
+}
+
+func exportValue(v reflect.Value) interface{} {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		values := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			name := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+			if name != "" && name != "-" {
+				values[name] = exportValue(v.Field(i))
+			}
+		}
+		return values
+	case reflect.Map:
+		values := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values[iter.Key().String()] = exportValue(iter.Value())
+		}
+		return values
+	case reflect.Slice:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = exportValue(v.Index(i))
+		}
+		return items
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return v.Elem().Interface()
+	default:
+		return v.Interface()
+	}
+}
+
+// Import is a detector section ready to replace the one in a configuration
+// file, see PrepareImport
+type Import struct {
+	// Target is the configuration file written by Write
+	Target string
+	// Config is the configuration in effect once the file is written, with
+	// the environment applied
+	Config *Config
+	// Changes lists the dotted paths of the settings that change
+	Changes []string
+
+	values map[string]interface{}
+}
+
+// PrepareImport reads the file at source, which must hold only a detector
+// section such as one written from Export, and lays it over the
+// configuration file at target, which need not exist yet. The result is
+// validated as LoadFile would, but nothing is written until Write, so a
+// failed or dry-run import leaves the target untouched.
+func PrepareImport(source, target string) (*Import, error) {
+	imported, err := readConfigFile(source)
+	if err != nil {
+		return nil, err
+	}
+	detector, ok := imported["detector"].(map[string]interface{})
+	if !ok || len(imported) != 1 {
+		return nil, fmt.Errorf("%s must hold a detector section and nothing else", source)
+	}
+
+	values := make(map[string]interface{})
+	if _, err := os.Stat(target); err == nil {
+		if values, err = readConfigFile(target); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	// The current configuration is compared even when it is invalid, so an
+	// import can repair it
+	current, _ := loadValues(values)
+
+	values["detector"] = detector
+	next, problems := loadValues(values)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return &Import{
+		Target:  target,
+		Config:  next,
+		Changes: changedSettings(current, next),
+		values:  values,
+	}, nil
+}
+
+// Write replaces the target file, in JSON if it ends in .json and YAML
+// otherwise. Other sections are kept, but comments in a YAML file are lost.
+func (i *Import) Write() error {
+	var raw []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(i.Target), ".json") {
+		raw, err = json.MarshalIndent(i.values, "", "  ")
+		raw = append(raw, '\n')
+	} else {
+		raw, err = yaml.Marshal(i.values)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	// Written beside the target and renamed over it, so readers never see
+	// half a file
+	tmp, err := os.CreateTemp(filepath.Dir(i.Target), filepath.Base(i.Target)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), i.Target); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the environment variable holding the path of an
+// optional configuration file
+const ConfigFileEnv = "ALIENATOR_CONFIG"
+
+// ValidationError lists every problem found while loading a configuration,
+// so that all of them can be fixed in one go
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// LoadFile loads the configuration in layers: the defaults, then the YAML or
+// JSON file at path (skipped when path is empty), then environment variables.
+// The result is validated, and every problem found in any layer is reported
+// together in a *ValidationError.
+func LoadFile(path string) (*Config, error) {
+	values := make(map[string]interface{})
+	if path != "" {
+		var err error
+		if values, err = readConfigFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, problems := loadValues(values)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// loadValues lays the values of a configuration file and then the
+// environment over the defaults, returning the result even if it has
+// problems
+func loadValues(values map[string]interface{}) (*Config, []string) {
+	cfg := Defaults()
+	problems := applyValues(reflect.ValueOf(cfg).Elem(), values, "")
+
+	env := &envReader{}
+	applyEnv(cfg, env)
+	problems = append(problems, env.problems...)
+	problems = append(problems, cfg.problems()...)
+	return cfg, problems
+}
+
+// readConfigFile decodes a configuration file into generic values. Files
+// ending in .json are parsed as JSON, anything else as YAML.
+func readConfigFile(path string) (map[string]interface{}, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(raw, &values)
+	} else {
+		err = yaml.Unmarshal(raw, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// applyValues copies file values onto the struct v, matching keys against
+// the json tags. Unknown keys and values of the wrong type are reported with
+// their dotted path rather than silently ignored.
+func applyValues(v reflect.Value, values map[string]interface{}, prefix string) []string {
+	problems := make([]string, 0)
+
+	fields := make(map[string]int, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		name := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := prefix + key
+		index, ok := fields[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: unknown key", path))
+			continue
+		}
+
+		field := v.Field(index)
+		value := values[key]
+		if field.Kind() == reflect.Struct {
+			nested, ok := value.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: expected a section, got %v", path, value))
+				continue
+			}
+			problems = append(problems, applyValues(field, nested, path+".")...)
+			continue
+		}
+		if field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.Struct {
+			problems = append(problems, applyMap(field, value, path)...)
+			continue
+		}
+		if field.Kind() == reflect.Map {
+			problems = append(problems, applyValueMap(field, value, path)...)
+			continue
+		}
+
+		if err := setValue(field, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+
+	return problems
+}
+
+// applyMap fills a map of sections, such as per-channel settings, keyed by
+// name. Entries are merged into any the map already holds.
+func applyMap(field reflect.Value, value interface{}, path string) []string {
+	entries, ok := value.(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%s: expected a section, got %v", path, value)}
+	}
+	if field.IsNil() {
+		field.Set(reflect.MakeMap(field.Type()))
+	}
+
+	problems := make([]string, 0)
+	for name, entry := range entries {
+		nested, ok := entry.(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s: expected a section, got %v", path, name, entry))
+			continue
+		}
+		item := reflect.New(field.Type().Elem()).Elem()
+		if existing := field.MapIndex(reflect.ValueOf(name)); existing.IsValid() {
+			item.Set(existing)
+		}
+		problems = append(problems, applyValues(item, nested, path+"."+name+".")...)
+		field.SetMapIndex(reflect.ValueOf(name), item)
+	}
+	return problems
+}
+
+// applyValueMap fills a map of single values, such as per-analyzer
+// thresholds, keyed by name. The file's entries replace any the map holds.
+func applyValueMap(field reflect.Value, value interface{}, path string) []string {
+	entries, ok := value.(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%s: expected a section, got %v", path, value)}
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	problems := make([]string, 0)
+	values := reflect.MakeMapWithSize(field.Type(), len(entries))
+	for _, name := range names {
+		entry := entries[name]
+		item := reflect.New(field.Type().Elem()).Elem()
+		if err := setValue(item, entry); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.%s: %v", path, name, err))
+			continue
+		}
+		values.SetMapIndex(reflect.ValueOf(name), item)
+	}
+	field.Set(values)
+	return problems
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	stringsType  = reflect.TypeOf([]string(nil))
+)
+
+// setValue assigns a decoded JSON or YAML value to a configuration field
+func setValue(field reflect.Value, value interface{}) error {
+	if field.Type() == durationType {
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a duration such as \"10s\", got %v", value)
+		}
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", text)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	if field.Type() == stringsType {
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list of strings, got %v", value)
+		}
+		list := make([]string, len(items))
+		for i, item := range items {
+			text, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected a list of strings, got %v", item)
+			}
+			list[i] = text
+		}
+		field.Set(reflect.ValueOf(list))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Interface:
+		// Free-form values, such as analyzer parameters, are checked by
+		// whoever uses them. Numbers are float64 whether the file is YAML
+		// or JSON.
+		switch v := value.(type) {
+		case int:
+			field.Set(reflect.ValueOf(float64(v)))
+		case string, bool, float64:
+			field.Set(reflect.ValueOf(value))
+		default:
+			return fmt.Errorf("expected a string, number or true or false, got %v", value)
+		}
+	case reflect.String:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %v", value)
+		}
+		field.SetString(text)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("expected true or false, got %v", value)
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		switch n := value.(type) {
+		case int:
+			field.SetInt(int64(n))
+		case float64:
+			if n != float64(int64(n)) {
+				return fmt.Errorf("expected an integer, got %v", n)
+			}
+			field.SetInt(int64(n))
+		default:
+			return fmt.Errorf("expected an integer, got %v", value)
+		}
+	case reflect.Float64:
+		switch n := value.(type) {
+		case int:
+			field.SetFloat(float64(n))
+		case float64:
+			field.SetFloat(n)
+		default:
+			return fmt.Errorf("expected a number, got %v", value)
+		}
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// envReader overrides settings from environment variables, recording values
+// that fail to parse
+type envReader struct {
+	problems []string
+}
+
+func (e *envReader) stringVar(target *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*target = value
+	}
+}
+
+// listVar reads a comma-separated list, ignoring blanks around the items
+func (e *envReader) listVar(target *[]string, key string) {
+	if value := os.Getenv(key); value != "" {
+		list := make([]string, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*target = list
+	}
+}
+
+func (e *envReader) intVar(target *int, key string) {
+	if value := os.Getenv(key); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s: expected an integer, got %q", key, value))
+			return
+		}
+		*target = n
+	}
+}
+
+func (e *envReader) floatVar(target *float64, key string) {
+	if value := os.Getenv(key); value != "" {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s: expected a number, got %q", key, value))
+			return
+		}
+		*target = f
+	}
+}
+
+func (e *envReader) boolVar(target *bool, key string) {
+	if value := os.Getenv(key); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s: expected true or false, got %q", key, value))
+			return
+		}
+		*target = b
+	}
+}
+
+// durationVar reads a whole number of units, e.g. seconds for DETECTOR_WARMUP_TIMEOUT
+func (e *envReader) durationVar(target *time.Duration, key string, unit time.Duration) {
+	if value := os.Getenv(key); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s: expected a whole number of %s, got %q", key, unitName(unit), value))
+			return
+		}
+		*target = time.Duration(n) * unit
+	}
+}
+
+func unitName(unit time.Duration) string {
+	switch unit {
+	case time.Millisecond:
+		return "milliseconds"
+	case time.Second:
+		return "seconds"
+	case time.Hour:
+		return "hours"
+	default:
+		return unit.String()
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PresetConfig bundles the detector tuning for one kind of input under a
+// name, selected with the CLI's --profile flag or a detection request's
+// preset field. Analyzers lists the analyzers to run, disabling the others;
+// empty keeps the configured ones. Weights are laid over the configured
+// weights and Parameters over the configured analyzer parameters. A
+// Threshold, when set, decides every content type, and a ContentType other
+// than auto is used for texts whose content type is left to the detector.
+type PresetConfig struct {
+	Description string                            `json:"description"`
+	ContentType string                            `json:"content_type"`
+	Analyzers   []string                          `json:"analyzers"`
+	Weights     map[string]float64                `json:"weights"`
+	Parameters  map[string]map[string]interface{} `json:"parameters"`
+	Threshold   float64                           `json:"threshold"`
+}
+
+// BuiltinPresets returns the presets available without configuration.
+// Presets defined in detector.presets replace those of the same name.
+func BuiltinPresets() map[string]PresetConfig {
+	return map[string]PresetConfig{
+		"academic-prose": {
+			Description: "Essays and papers: leans on style, semantics and watermarks; repeated terminology is expected",
+			ContentType: "prose",
+			Analyzers:   []string{"linguistic", "embedding", "entropy", "compression", "uniformity", "repetition", "sentiment", "watermark"},
+			Weights:     map[string]float64{"linguistic": 1.8, "watermark": 1.2, "sentiment": 0.3},
+			Parameters: map[string]map[string]interface{}{
+				"repetition": {"phrase_coverage_threshold": 0.25},
+				"sentiment":  {"min_sentences": 8},
+			},
+		},
+		"source-code": {
+			Description: "Source files: structure and entropy only, tolerant of the repetition code is made of",
+			ContentType: "code",
+			Analyzers:   []string{"entropy", "compression", "cryptographic", "uniformity", "repetition", "injection"},
+			Parameters: map[string]map[string]interface{}{
+				"repetition": {"phrase_coverage_threshold": 0.4, "min_loop_repeats": 5},
+			},
+		},
+		"chat-logs": {
+			Description: "Short conversational turns: tone and injection attempts count, long-range statistics need little text",
+			ContentType: "prose",
+			Analyzers:   []string{"linguistic", "entropy", "compression", "repetition", "sentiment", "injection"},
+			Weights:     map[string]float64{"injection": 1.5, "sentiment": 0.8},
+			Parameters: map[string]map[string]interface{}{
+				"repetition": {"min_phrase_length": 3},
+				"sentiment":  {"min_sentences": 3, "min_polar_sentences": 2},
+			},
+		},
+		"leak-scan": {
+			Description: "Dumps and logs searched for hashes, keys and other secrets",
+			ContentType: "data",
+			Analyzers:   []string{"cryptographic", "entropy", "compression"},
+			Weights:     map[string]float64{"cryptographic": 2.0},
+			Parameters: map[string]map[string]interface{}{
+				"cryptographic": {"max_hashes": 5000},
+			},
+			Threshold: 0.6,
+		},
+	}
+}
+
+// preset returns the named preset, preferring one configured in Presets to
+// a built-in one
+func (c DetectorConfig) preset(name string) (PresetConfig, bool) {
+	if preset, ok := c.Presets[name]; ok {
+		return preset, true
+	}
+	preset, ok := BuiltinPresets()[name]
+	return preset, ok
+}
+
+// PresetNames returns the names of the built-in and configured presets, sorted
+func (c DetectorConfig) PresetNames() []string {
+	presets := BuiltinPresets()
+	for name, preset := range c.Presets {
+		presets[name] = preset
+	}
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithPreset returns a copy of the detector configuration with the named
+// preset resolved into it, for a detector that analyzes as the preset
+// describes. The copy shares nothing the preset changes with c.
+func (c DetectorConfig) WithPreset(name string) (DetectorConfig, error) {
+	preset, ok := c.preset(name)
+	if !ok {
+		return c, fmt.Errorf("unknown preset %q (expected one of %s)", name, strings.Join(c.PresetNames(), ", "))
+	}
+
+	resolved := c
+	analyzers := make(map[string]AnalyzerConfig, len(c.Analyzers)+len(preset.Parameters))
+	for analyzer, cfg := range c.Analyzers {
+		analyzers[analyzer] = cfg
+	}
+	if len(preset.Analyzers) > 0 {
+		resolved.EnabledAnalyzers = append([]string(nil), preset.Analyzers...)
+		// The preset's analyzers run even where the configuration disables them
+		for _, analyzer := range preset.Analyzers {
+			if cfg, ok := analyzers[analyzer]; ok && cfg.Disabled {
+				cfg.Disabled = false
+				analyzers[analyzer] = cfg
+			}
+		}
+	}
+	for analyzer, parameters := range preset.Parameters {
+		cfg := analyzers[analyzer]
+		merged := make(map[string]interface{}, len(cfg.Parameters)+len(parameters))
+		for parameter, value := range cfg.Parameters {
+			merged[parameter] = value
+		}
+		for parameter, value := range parameters {
+			merged[parameter] = value
+		}
+		cfg.Parameters = merged
+		analyzers[analyzer] = cfg
+	}
+	resolved.Analyzers = analyzers
+
+	if len(preset.Weights) > 0 {
+		weights := make(map[string]float64, len(c.Weights)+len(preset.Weights))
+		for analyzer, weight := range c.Weights {
+			weights[analyzer] = weight
+		}
+		for analyzer, weight := range preset.Weights {
+			weights[analyzer] = weight
+		}
+		resolved.Weights = weights
+	}
+	if preset.Threshold > 0 {
+		resolved.Thresholds = ThresholdsConfig{Prose: preset.Threshold, Code: preset.Threshold, Data: preset.Threshold, Log: preset.Threshold}
+	}
+	if preset.ContentType != "" && preset.ContentType != "auto" {
+		resolved.ContentType = preset.ContentType
+	}
+	return resolved, nil
+}
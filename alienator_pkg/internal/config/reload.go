@@ -0,0 +1,162 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// ApplyFunc applies the reloadable parts of a configuration to a running
+// component, e.g. the detector's severity bands or the log level
+type ApplyFunc func(cfg *Config) error
+
+// Reloader re-reads the configuration file on demand and applies the settings
+// that can change without a restart: the detector's language, content type,
+// enabled analyzers, weights, severity bands, decision thresholds, tuning
+// costs, execution plan and fallback chains, its confidence, normalization,
+// combination, code block, boilerplate, circuit breaker and health check
+// policies, as well as the log level and the rate, request and concurrency
+// limits. Changes to any other setting (listen ports, connection strings,
+// ...) are ignored with a warning until the process restarts.
+type Reloader struct {
+	path     string
+	logger   *zap.Logger
+	mu       sync.Mutex
+	current  *Config
+	appliers []ApplyFunc
+}
+
+// NewReloader creates a reloader for the file at path (empty for defaults and
+// environment only), starting from the configuration already in effect
+func NewReloader(path string, current *Config, logger *zap.Logger) *Reloader {
+	return &Reloader{
+		path:    path,
+		logger:  logger,
+		current: current,
+	}
+}
+
+// OnReload registers a function called with every successfully loaded
+// configuration
+func (r *Reloader) OnReload(apply ApplyFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.appliers = append(r.appliers, apply)
+}
+
+// Current returns the configuration in effect
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload loads and validates the configuration, then applies its reloadable
+// settings. On any failure the previous configuration stays in effect: an
+// invalid file is rejected before anything is applied, and if an applier
+// fails the ones that already ran are given the previous configuration again.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaded, err := LoadFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	next := r.current.withReloadable(loaded)
+	for _, path := range changedSettings(next, loaded) {
+		r.logger.Warn("Configuration change requires a restart and was ignored", zap.String("setting", path))
+	}
+
+	for i, apply := range r.appliers {
+		if err := apply(next); err != nil {
+			for _, revert := range r.appliers[:i] {
+				if revertErr := revert(r.current); revertErr != nil {
+					r.logger.Error("Failed to restore previous configuration", zap.Error(revertErr))
+				}
+			}
+			return fmt.Errorf("failed to apply configuration: %w", err)
+		}
+	}
+
+	changed := changedSettings(r.current, next)
+	r.current = next
+	r.logger.Info("Configuration reloaded", zap.String("file", r.path), zap.Strings("changed", changed))
+	return nil
+}
+
+// HandleSignals reloads the configuration on every SIGHUP until ctx is done.
+// Failed reloads are logged and leave the previous configuration in effect.
+func (r *Reloader) HandleSignals(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.logger.Info("Received SIGHUP, reloading configuration", zap.String("file", r.path))
+			if err := r.Reload(); err != nil {
+				r.logger.Error("Configuration reload failed, keeping previous configuration", zap.Error(err))
+			}
+		}
+	}
+}
+
+// withReloadable returns a copy of c with the reloadable settings taken from
+// next
+func (c *Config) withReloadable(next *Config) *Config {
+	merged := *c
+	merged.Detector.Severity = next.Detector.Severity
+	merged.Detector.Thresholds = next.Detector.Thresholds
+	merged.Detector.Tuning = next.Detector.Tuning
+	merged.Detector.Confidence = next.Detector.Confidence
+	merged.Detector.Normalization = next.Detector.Normalization
+	merged.Detector.Combination = next.Detector.Combination
+	merged.Detector.CodeBlocks = next.Detector.CodeBlocks
+	merged.Detector.Boilerplate = next.Detector.Boilerplate
+	merged.Detector.Execution = next.Detector.Execution
+	merged.Detector.CircuitBreaker = next.Detector.CircuitBreaker
+	merged.Detector.Health = next.Detector.Health
+	merged.Detector.Fallbacks = next.Detector.Fallbacks
+	merged.Detector.Language = next.Detector.Language
+	merged.Detector.ContentType = next.Detector.ContentType
+	merged.Detector.EnabledAnalyzers = next.Detector.EnabledAnalyzers
+	merged.Detector.Weights = next.Detector.Weights
+	merged.Logging.Level = next.Logging.Level
+	merged.RateLimit = next.RateLimit
+	merged.Limits = next.Limits
+	merged.Concurrency = next.Concurrency
+	return &merged
+}
+
+// changedSettings lists the dotted paths of the settings that differ between
+// a and b
+func changedSettings(a, b *Config) []string {
+	return diffValues(reflect.ValueOf(*a), reflect.ValueOf(*b), "")
+}
+
+func diffValues(a, b reflect.Value, prefix string) []string {
+	changed := make([]string, 0)
+	for i := 0; i < a.NumField(); i++ {
+		path := prefix + strings.Split(a.Type().Field(i).Tag.Get("json"), ",")[0]
+		if a.Field(i).Kind() == reflect.Struct {
+			changed = append(changed, diffValues(a.Field(i), b.Field(i), path+".")...)
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,445 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap/zapcore"
+)
+
+// Validate checks the configuration for missing required settings and values
+// out of range, returning a *ValidationError listing every problem
+func (c *Config) Validate() error {
+	if problems := c.problems(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// problems returns one message per invalid setting, keyed by its file path
+func (c *Config) problems() []string {
+	v := &validator{}
+
+	v.port("server.port", c.Server.Port)
+	v.positive("server.read_timeout", float64(c.Server.ReadTimeout))
+	v.positive("server.write_timeout", float64(c.Server.WriteTimeout))
+	v.positive("server.idle_timeout", float64(c.Server.IdleTimeout))
+	v.positive("server.shutdown_timeout", float64(c.Server.ShutdownTimeout))
+	v.check(c.Server.DrainDelay >= 0, "server.drain_delay: must not be negative, got %s", c.Server.DrainDelay)
+	if tls := c.Server.TLS; tls.Enabled {
+		v.required("server.tls.cert_file", tls.CertFile)
+		v.required("server.tls.key_file", tls.KeyFile)
+		v.check(!tls.AdminClientAuth || tls.ClientCAFile != "", "server.tls.admin_client_auth: requires server.tls.client_ca_file")
+		if tls.MinVersion != "" {
+			v.oneOf("server.tls.min_version", tls.MinVersion, "1.2", "1.3")
+		}
+		if tls.RedirectPort != 0 {
+			v.port("server.tls.redirect_port", tls.RedirectPort)
+			v.check(tls.RedirectPort != c.Server.Port, "server.tls.redirect_port: must differ from server.port %d", c.Server.Port)
+		}
+	}
+
+	v.required("database.host", c.Database.Host)
+	v.port("database.port", c.Database.Port)
+	v.required("database.dbname", c.Database.DBName)
+
+	v.required("redis.host", c.Redis.Host)
+	v.port("redis.port", c.Redis.Port)
+	v.check(c.Redis.DB >= 0, "redis.db: must not be negative, got %d", c.Redis.DB)
+
+	v.required("nats.url", c.NATS.URL)
+
+	v.check(c.Broadcast.MaxRetries >= 0, "broadcast.max_retries: must not be negative, got %d", c.Broadcast.MaxRetries)
+	v.positive("broadcast.initial_backoff", float64(c.Broadcast.InitialBackoff))
+	v.check(c.Broadcast.MaxBackoff >= c.Broadcast.InitialBackoff,
+		"broadcast.max_backoff: must be at least initial_backoff (%s), got %s", c.Broadcast.InitialBackoff, c.Broadcast.MaxBackoff)
+	alerts := c.Broadcast.Alerts
+	v.check(alerts.ExcerptLength >= 0, "broadcast.alerts.excerpt_length: must not be negative, got %d", alerts.ExcerptLength)
+	v.oneOf("broadcast.alerts.default.format", alerts.Default.Format, "json", "slack")
+	for channel, tmpl := range alerts.Channels {
+		v.check(channel != "", "broadcast.alerts.channels: must not contain empty channel IDs")
+		if tmpl.Format != "" {
+			v.oneOf("broadcast.alerts.channels."+channel+".format", tmpl.Format, "json", "slack")
+		}
+	}
+	for _, channel := range alerts.Targets {
+		v.check(channel != "", "broadcast.alerts.targets: must not contain empty channel IDs")
+	}
+
+	v.positive("detector.warmup_timeout", float64(c.Detector.WarmupTimeout))
+	v.positive("detector.warmup_backoff", float64(c.Detector.WarmupBackoff))
+	severity := c.Detector.Severity
+	bands := []struct {
+		name  string
+		value float64
+	}{
+		{"low", severity.Low},
+		{"medium", severity.Medium},
+		{"high", severity.High},
+		{"critical", severity.Critical},
+	}
+	for i, band := range bands {
+		v.unit("detector.severity."+band.name, band.value)
+		if i > 0 && band.value <= bands[i-1].value {
+			v.add("detector.severity.%s: must be above %s (%g), got %g", band.name, bands[i-1].name, bands[i-1].value, band.value)
+		}
+	}
+	v.unit("detector.severity.min_confidence", severity.MinConfidence)
+	thresholds := c.Detector.Thresholds
+	for _, threshold := range []struct {
+		name  string
+		value float64
+	}{
+		{"prose", thresholds.Prose},
+		{"code", thresholds.Code},
+		{"data", thresholds.Data},
+		{"log", thresholds.Log},
+	} {
+		v.check(threshold.value > 0 && threshold.value < 1,
+			"detector.thresholds.%s: must be between 0 and 1 exclusive, got %g", threshold.name, threshold.value)
+	}
+
+	confidence := c.Detector.Confidence
+	v.unit("detector.confidence.floor", confidence.Floor)
+	v.unit("detector.confidence.ceiling", confidence.Ceiling)
+	v.unit("detector.confidence.min_contributing", confidence.MinContributing)
+	v.check(confidence.Floor <= confidence.Ceiling,
+		"detector.confidence.floor: must not exceed ceiling (%g), got %g", confidence.Ceiling, confidence.Floor)
+	v.oneOf("detector.confidence.aggregation", confidence.Aggregation, "mean", "min", "weighted-mean", "harmonic-mean")
+	normalization := c.Detector.Normalization
+	v.oneOf("detector.normalization.strategy", normalization.Strategy, "none", "zscore", "minmax", "percentile")
+	v.positive("detector.normalization.window", float64(normalization.Window))
+	v.check(normalization.MinSamples >= 1 && normalization.MinSamples <= normalization.Window,
+		"detector.normalization.min_samples: must be between 1 and window (%d), got %d", normalization.Window, normalization.MinSamples)
+	combination := c.Detector.Combination
+	v.oneOf("detector.combination.mode", combination.Mode, "fusion", "vote")
+	v.check(combination.Quorum >= 0 && combination.Quorum < 1,
+		"detector.combination.quorum: must be at least 0 and below 1, got %g", combination.Quorum)
+	for name, threshold := range combination.VoteThresholds {
+		v.check(threshold > 0 && threshold < 1,
+			"detector.combination.vote_thresholds.%s: must be between 0 and 1 exclusive, got %g", name, threshold)
+	}
+	v.oneOf("detector.code_blocks.mode", c.Detector.CodeBlocks.Mode, "off", "split", "strip")
+	boilerplate := c.Detector.Boilerplate
+	for _, pattern := range boilerplate.Patterns {
+		_, err := regexp.Compile(pattern)
+		v.check(err == nil, "detector.boilerplate.patterns: invalid pattern %q: %v", pattern, err)
+	}
+	v.check(boilerplate.MinRepeats >= 2, "detector.boilerplate.min_repeats: must be at least 2, got %d", boilerplate.MinRepeats)
+	v.positive("detector.boilerplate.min_length", float64(boilerplate.MinLength))
+	execution := c.Detector.Execution
+	v.check(execution.Budget >= 0, "detector.execution.budget: must not be negative, got %s", execution.Budget)
+	listed := make(map[string]bool, len(execution.Order))
+	for _, name := range execution.Order {
+		v.check(name != "", "detector.execution.order: must not contain empty names")
+		v.check(name == "" || !listed[name], "detector.execution.order: %q is listed twice", name)
+		listed[name] = true
+	}
+	breaker := c.Detector.CircuitBreaker
+	v.check(breaker.Failures >= 0, "detector.circuit_breaker.failures: must not be negative, got %d", breaker.Failures)
+	v.check(breaker.Failures == 0 || breaker.Cooldown > 0,
+		"detector.circuit_breaker.cooldown: must be positive, got %s", breaker.Cooldown)
+	health := c.Detector.Health
+	v.positive("detector.health.timeout", float64(health.Timeout))
+	required := make(map[string]bool, len(health.Required))
+	for _, name := range health.Required {
+		v.check(name != "", "detector.health.required: must not contain empty names")
+		v.check(name == "" || !required[name], "detector.health.required: %q is listed twice", name)
+		required[name] = true
+	}
+	for name, chain := range c.Detector.Fallbacks {
+		v.check(name != "", "detector.fallbacks: must not be keyed by an empty name")
+		fallbacks := make(map[string]bool, len(chain))
+		for _, fallback := range chain {
+			v.check(fallback.Analyzer != "", "detector.fallbacks.%s: must not contain empty names", name)
+			v.check(fallback.Analyzer != name, "detector.fallbacks.%s: must not fall back to itself", name)
+			v.check(fallback.Analyzer == "" || !fallbacks[fallback.Analyzer], "detector.fallbacks.%s: %q is listed twice", name, fallback.Analyzer)
+			v.check(fallback.Weight > 0 && fallback.Weight <= 1,
+				"detector.fallbacks.%s: weight of %q must be in (0, 1], got %g", name, fallback.Analyzer, fallback.Weight)
+			fallbacks[fallback.Analyzer] = true
+		}
+	}
+	v.oneOf("detector.segmentation.mode", c.Detector.Segmentation.Mode, "rules", "punctuation")
+	v.oneOf("detector.segmentation.words", c.Detector.Segmentation.Words, "auto", "whitespace")
+	for _, abbreviation := range c.Detector.Segmentation.Abbreviations {
+		v.check(strings.Trim(abbreviation, ". ") != "", "detector.segmentation.abbreviations: must not contain empty entries")
+	}
+	cache := c.Detector.Cache
+	v.positive("detector.cache.size", float64(cache.Size))
+	cached := make(map[string]bool, len(cache.Analyzers))
+	for _, name := range cache.Analyzers {
+		v.check(name != "", "detector.cache.analyzers: must not contain empty names")
+		v.check(name == "" || !cached[name], "detector.cache.analyzers: %q is listed twice", name)
+		cached[name] = true
+	}
+	v.positive("detector.tuning.false_positive_cost", c.Detector.Tuning.FalsePositiveCost)
+	v.positive("detector.tuning.false_negative_cost", c.Detector.Tuning.FalseNegativeCost)
+
+	for name, analyzer := range c.Detector.Analyzers {
+		v.check(name != "", "detector.analyzers: must not contain empty analyzer names")
+		for parameter := range analyzer.Parameters {
+			v.check(parameter != "", "detector.analyzers.%s.parameters: must not contain empty names", name)
+		}
+	}
+	v.oneOf("detector.content_type", c.Detector.ContentType, contentTypes...)
+	v.analyzerNames("detector.enabled_analyzers", c.Detector.EnabledAnalyzers)
+	v.weights("detector.weights", c.Detector.Weights)
+	for name, preset := range c.Detector.Presets {
+		path := "detector.presets." + name
+		v.check(presetName.MatchString(name), "detector.presets: name %q must be lowercase letters, digits and dashes", name)
+		if preset.ContentType != "" {
+			v.oneOf(path+".content_type", preset.ContentType, contentTypes...)
+		}
+		v.analyzerNames(path+".analyzers", preset.Analyzers)
+		v.weights(path+".weights", preset.Weights)
+		for analyzer, parameters := range preset.Parameters {
+			v.check(analyzer != "", "%s.parameters: must not contain empty analyzer names", path)
+			for parameter := range parameters {
+				v.check(parameter != "", "%s.parameters.%s: must not contain empty names", path, analyzer)
+			}
+		}
+		v.unit(path+".threshold", preset.Threshold)
+	}
+
+	v.required("auth.jwt_secret", c.Auth.JWTSecret)
+	v.positive("auth.token_ttl", float64(c.Auth.TokenTTL))
+	policy := c.Auth.PasswordPolicy
+	v.check(policy.MinLength >= 1, "auth.password_policy.min_length: must be at least 1, got %d", policy.MinLength)
+	v.check(policy.MaxLength >= policy.MinLength,
+		"auth.password_policy.max_length: must be at least min_length (%d), got %d", policy.MinLength, policy.MaxLength)
+
+	v.required("jwt.secret", c.JWT.Secret)
+	v.positive("jwt.expiration_time", float64(c.JWT.ExpirationTime))
+
+	if c.Logging.Level != "" {
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+			v.add("logging.level: unknown level %q (expected debug, info, warn or error)", c.Logging.Level)
+		}
+	}
+	if c.Logging.Format != "" {
+		v.oneOf("logging.format", strings.ToLower(c.Logging.Format), "json", "console")
+	}
+
+	v.check(c.RateLimit.RequestsPerMinute > 0, "rate_limit.requests_per_minute: must be positive, got %d", c.RateLimit.RequestsPerMinute)
+	v.check(c.RateLimit.Burst > 0, "rate_limit.burst: must be positive, got %d", c.RateLimit.Burst)
+	rateLimitGroups := make(map[string]bool, len(c.RateLimit.Groups))
+	for i, group := range c.RateLimit.Groups {
+		path := fmt.Sprintf("rate_limit.groups[%d]", i)
+		v.required(path+".name", group.Name)
+		v.check(!rateLimitGroups[group.Name], "%s.name: duplicate group %q", path, group.Name)
+		rateLimitGroups[group.Name] = true
+		v.check(len(group.Paths) > 0, "%s.paths: at least one path is required", path)
+		for _, prefix := range group.Paths {
+			v.check(strings.HasPrefix(prefix, "/"), "%s.paths: must start with /, got %q", path, prefix)
+		}
+		v.check(group.RequestsPerMinute > 0, "%s.requests_per_minute: must be positive, got %d", path, group.RequestsPerMinute)
+		v.check(group.Burst > 0, "%s.burst: must be positive, got %d", path, group.Burst)
+	}
+
+	v.check(c.Limits.MaxBodyBytes >= 0, "limits.max_body_bytes: must not be negative, got %d", c.Limits.MaxBodyBytes)
+	v.check(c.Limits.MaxBatchItems >= 0, "limits.max_batch_items: must not be negative, got %d", c.Limits.MaxBatchItems)
+	v.check(c.Limits.MaxLineBytes > 0, "limits.max_line_bytes: must be positive, got %d", c.Limits.MaxLineBytes)
+	v.check(c.Limits.RequestTimeout >= 0, "limits.request_timeout: must not be negative, got %s", c.Limits.RequestTimeout)
+	v.check(c.Metrics.BatchInterval >= 0, "metrics.batch_interval: must not be negative, got %s", c.Metrics.BatchInterval)
+	v.check(c.Metrics.BatchSize > 0, "metrics.batch_size: must be positive, got %d", c.Metrics.BatchSize)
+
+	v.check(c.Concurrency.MaxInFlight > 0, "concurrency.max_in_flight: must be positive, got %d", c.Concurrency.MaxInFlight)
+	v.check(c.Concurrency.AnalysisMaxInFlight > 0, "concurrency.analysis_max_in_flight: must be positive, got %d", c.Concurrency.AnalysisMaxInFlight)
+	v.check(c.Concurrency.AnalysisMaxInFlight <= c.Concurrency.MaxInFlight, "concurrency.analysis_max_in_flight: must not exceed max_in_flight %d, got %d", c.Concurrency.MaxInFlight, c.Concurrency.AnalysisMaxInFlight)
+	v.positive("concurrency.retry_after", float64(c.Concurrency.RetryAfter))
+
+	v.required("versioning.header", c.Versioning.Header)
+	v.required("versioning.default_version", c.Versioning.DefaultVersion)
+
+	for name, consumer := range map[string]ConsumerConfig{
+		"message":   c.Worker.Message,
+		"broadcast": c.Worker.Broadcast,
+		"stream":    c.Worker.Stream,
+	} {
+		v.check(consumer.Workers > 0, "worker.%s.workers: must be positive, got %d", name, consumer.Workers)
+		v.check(consumer.MaxInFlight > 0, "worker.%s.max_in_flight: must be positive, got %d", name, consumer.MaxInFlight)
+		v.check(consumer.SuppressionWindow >= 0, "worker.%s.suppression_window: must not be negative, got %s", name, consumer.SuppressionWindow)
+		v.check(consumer.OccurrenceRetention >= 0, "worker.%s.occurrence_retention: must not be negative, got %s", name, consumer.OccurrenceRetention)
+	}
+	if bridge := c.Worker.Bridge; bridge.Enabled {
+		v.required("worker.bridge.request_channel", bridge.RequestChannel)
+		v.check(bridge.ResultChannel != "" || bridge.ResultStream != "", "worker.bridge: result_channel or result_stream is required")
+		v.check(bridge.ResultChannel != bridge.RequestChannel, "worker.bridge.result_channel: must differ from request_channel %q", bridge.RequestChannel)
+		v.check(bridge.Workers > 0, "worker.bridge.workers: must be positive, got %d", bridge.Workers)
+		v.check(bridge.MaxInFlight > 0, "worker.bridge.max_in_flight: must be positive, got %d", bridge.MaxInFlight)
+	}
+	v.required("worker.jobs.key_prefix", c.Worker.Jobs.KeyPrefix)
+	v.positive("worker.jobs.retention", float64(c.Worker.Jobs.Retention))
+	if jobs := c.Worker.Jobs; jobs.Enabled {
+		v.check(jobs.Workers > 0, "worker.jobs.workers: must be positive, got %d", jobs.Workers)
+		v.positive("worker.jobs.poll_interval", float64(jobs.PollInterval))
+		// Running jobs renew their lease every poll interval
+		v.check(jobs.LeaseTimeout > jobs.PollInterval, "worker.jobs.lease_timeout: must exceed poll_interval %s, got %s", jobs.PollInterval, jobs.LeaseTimeout)
+		v.positive("worker.jobs.retrain.lookback", float64(jobs.Retrain.Lookback))
+		v.check(jobs.Retrain.MaxRecords > 0, "worker.jobs.retrain.max_records: must be positive, got %d", jobs.Retrain.MaxRecords)
+		v.positive("worker.jobs.retrain.timeout", float64(jobs.Retrain.Timeout))
+	}
+	if outbox := c.Worker.Outbox; outbox.Enabled {
+		v.required("worker.outbox.dir", outbox.Dir)
+		v.positive("worker.outbox.initial_backoff", float64(outbox.InitialBackoff))
+		v.check(outbox.MaxBackoff >= outbox.InitialBackoff, "worker.outbox.max_backoff: must be at least initial_backoff %s, got %s", outbox.InitialBackoff, outbox.MaxBackoff)
+	}
+
+	if file := c.Sinks.File; file.Enabled {
+		v.required("sinks.file.path", file.Path)
+		v.check(file.MaxSizeMB > 0, "sinks.file.max_size_mb: must be positive, got %d", file.MaxSizeMB)
+		v.check(file.MaxBackups >= 0, "sinks.file.max_backups: must not be negative, got %d", file.MaxBackups)
+	}
+	if webhook := c.Sinks.Webhook; webhook.Enabled {
+		v.url("sinks.webhook.url", webhook.URL)
+		v.positive("sinks.webhook.timeout", float64(webhook.Timeout))
+	}
+	if kafka := c.Sinks.Kafka; kafka.Enabled {
+		v.url("sinks.kafka.rest_url", kafka.RESTURL)
+		v.required("sinks.kafka.topic", kafka.Topic)
+		v.positive("sinks.kafka.timeout", float64(kafka.Timeout))
+	}
+	if slack := c.Sinks.Slack; slack.Enabled {
+		if slack.Token != "" {
+			v.required("sinks.slack.channel", slack.Channel)
+			if slack.APIURL != "" {
+				v.url("sinks.slack.api_url", slack.APIURL)
+			}
+		} else {
+			v.url("sinks.slack.webhook_url", slack.WebhookURL)
+		}
+		v.unit("sinks.slack.min_score", slack.MinScore)
+		v.check(slack.MaxRetries >= 0, "sinks.slack.max_retries: must not be negative, got %d", slack.MaxRetries)
+		v.positive("sinks.slack.timeout", float64(slack.Timeout))
+	}
+	if pagerDuty := c.Sinks.PagerDuty; pagerDuty.Enabled {
+		v.required("sinks.pagerduty.routing_key", pagerDuty.RoutingKey)
+		if pagerDuty.URL != "" {
+			v.url("sinks.pagerduty.url", pagerDuty.URL)
+		}
+		v.required("sinks.pagerduty.source", pagerDuty.Source)
+		v.unit("sinks.pagerduty.min_score", pagerDuty.MinScore)
+		v.check(pagerDuty.MaxRetries >= 0, "sinks.pagerduty.max_retries: must not be negative, got %d", pagerDuty.MaxRetries)
+		v.positive("sinks.pagerduty.timeout", float64(pagerDuty.Timeout))
+		if pagerDuty.Resolve {
+			v.positive("sinks.pagerduty.resolve_window", float64(pagerDuty.ResolveWindow))
+		}
+	}
+
+	if signing := c.Signing; signing.Enabled {
+		v.oneOf("signing.algorithm", signing.Algorithm, "hmac-sha256", "ed25519")
+		switch signing.Algorithm {
+		case "hmac-sha256":
+			v.check(len(signing.Secret) >= 32, "signing.secret: must be at least 32 characters for hmac-sha256")
+		case "ed25519":
+			v.required("signing.private_key_file", signing.PrivateKeyFile)
+		}
+	}
+
+	v.oneOf("hashing.algorithm", c.Hashing.Algorithm, "sha256", "xxhash")
+	v.oneOf("hashing.unicode", c.Hashing.Unicode, "nfc", "nfkc", "none")
+
+	v.check(c.Output.Precision >= 0 && c.Output.Precision <= models.MaxPrecision,
+		"output.precision: must be between 0 and %d, got %d", models.MaxPrecision, c.Output.Precision)
+	v.oneOf("output.metadata", c.Output.Metadata, "none", "summary", "full")
+	v.check(c.Review.Band >= 0 && c.Review.Band < 1, "review.band: must be in [0, 1), got %v", c.Review.Band)
+	for _, name := range c.ExplainOnly.Exclude {
+		v.check(name != "", "explain_only.exclude: must not contain empty names")
+	}
+	seenSources := make(map[string]bool, len(c.Provenance.Sources))
+	for _, source := range c.Provenance.Sources {
+		v.check(source != "" && len(source) <= MaxSourceLength,
+			"provenance.sources: names must be 1 to %d characters, got %q", MaxSourceLength, source)
+		v.check(!seenSources[source], "provenance.sources: duplicate source %q", source)
+		seenSources[source] = true
+	}
+	if _, err := c.Provenance.Resolve(c.Provenance.Default); err != nil {
+		v.add("provenance.default: %v", err)
+	}
+
+	return v.sorted()
+}
+
+// contentTypes are the values of detector.content_type
+var contentTypes = []string{"auto", "prose", "code", "data", "log"}
+
+// presetName matches the names presets may be defined under
+var presetName = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// validator collects validation problems
+type validator struct {
+	problems []string
+}
+
+func (v *validator) add(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		v.add(format, args...)
+	}
+}
+
+func (v *validator) required(path, value string) {
+	v.check(strings.TrimSpace(value) != "", "%s: is required", path)
+}
+
+// url requires an absolute http or https URL
+func (v *validator) url(path, value string) {
+	parsed, err := url.Parse(value)
+	v.check(err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "",
+		"%s: must be an http or https URL, got %q", path, value)
+}
+
+func (v *validator) port(path string, port int) {
+	v.check(port >= 1 && port <= 65535, "%s: must be between 1 and 65535, got %d", path, port)
+}
+
+func (v *validator) positive(path string, value float64) {
+	v.check(value > 0, "%s: must be positive", path)
+}
+
+func (v *validator) unit(path string, value float64) {
+	v.check(value >= 0 && value <= 1, "%s: must be between 0 and 1, got %g", path, value)
+}
+
+// analyzerNames requires a list of analyzer names without empty or repeated
+// entries
+func (v *validator) analyzerNames(path string, names []string) {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		v.check(name != "", "%s: must not contain empty names", path)
+		v.check(name == "" || !seen[name], "%s: %q is listed twice", path, name)
+		seen[name] = true
+	}
+}
+
+// weights requires analyzer weights keyed by name and not negative
+func (v *validator) weights(path string, weights map[string]float64) {
+	for name, weight := range weights {
+		v.check(name != "", "%s: must not be keyed by an empty name", path)
+		v.check(weight >= 0, "%s.%s: must not be negative, got %g", path, name, weight)
+	}
+}
+
+func (v *validator) oneOf(path, value string, allowed ...string) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	v.add("%s: must be one of %s, got %q", path, strings.Join(allowed, ", "), value)
+}
+
+// sorted returns the problems ordered by setting path
+func (v *validator) sorted() []string {
+	problems := append([]string(nil), v.problems...)
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,308 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// DefaultBoilerplatePatterns match common email boilerplate: a signature
+// after a "--" line, confidentiality notices, mobile signatures and
+// unsubscribe footers
+var DefaultBoilerplatePatterns = []string{
+	`(?ms)^--[ \t]*$.*\z`,
+	`(?im)^.*\b(confidential|privileged)\b.*\b(intended|addressee|recipient)\b.*$`,
+	`(?im)^sent from my \w+.*$`,
+	`(?im)^.*\bunsubscribe\b.*$`,
+}
+
+// BoilerplatePolicy controls the stripping of boilerplate before analysis,
+// so that disclaimers and signatures shared by many documents don't dominate
+// their scores. Text matching one of Patterns is removed from every
+// analysis; AnalyzeBatch also removes the paragraphs of at least MinLength
+// characters that appear, nearly unchanged, in MinRepeats or more of its
+// texts.
+type BoilerplatePolicy struct {
+	Enabled    bool
+	Patterns   []string
+	MinRepeats int
+	MinLength  int
+}
+
+// DefaultBoilerplatePolicy returns the policy matching the detector's
+// original behaviour: nothing is stripped. Enabled, it strips the default
+// patterns and paragraphs repeated in three texts of a batch.
+func DefaultBoilerplatePolicy() BoilerplatePolicy {
+	return BoilerplatePolicy{
+		Enabled:    false,
+		Patterns:   append([]string(nil), DefaultBoilerplatePatterns...),
+		MinRepeats: 3,
+		MinLength:  40,
+	}
+}
+
+// Validate checks that the patterns compile and the repetition settings are
+// usable
+func (p BoilerplatePolicy) Validate() error {
+	if _, err := p.compile(); err != nil {
+		return err
+	}
+	if p.MinRepeats < 2 {
+		return fmt.Errorf("boilerplate must repeat in at least 2 texts, got %d", p.MinRepeats)
+	}
+	if p.MinLength <= 0 {
+		return fmt.Errorf("boilerplate minimum length must be positive, got %d", p.MinLength)
+	}
+	return nil
+}
+
+// compile compiles the patterns
+func (p BoilerplatePolicy) compile() ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(p.Patterns))
+	for _, pattern := range p.Patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boilerplate pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, compiled)
+	}
+	return patterns, nil
+}
+
+// Sources of removed boilerplate
+const (
+	// BoilerplatePattern is text matching a configured pattern
+	BoilerplatePattern = "pattern"
+	// BoilerplateRepeated is a paragraph repeated across the texts of a batch
+	BoilerplateRepeated = "repeated"
+)
+
+// BoilerplateRemoval records a piece of text stripped before analysis
+type BoilerplateRemoval struct {
+	Source  string `json:"source"`
+	Pattern string `json:"pattern,omitempty"`
+	Text    string `json:"text"`
+}
+
+// SetBoilerplatePolicy replaces the policy for stripping boilerplate
+func (ad *AnomalyDetector) SetBoilerplatePolicy(policy BoilerplatePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	patterns, _ := policy.compile()
+	ad.settingsMu.Lock()
+	ad.boilerplate = policy
+	ad.boilerplatePatterns = patterns
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// boilerplatePolicy returns the current boilerplate policy and its compiled
+// patterns
+func (ad *AnomalyDetector) boilerplatePolicy() (BoilerplatePolicy, []*regexp.Regexp) {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.boilerplate, ad.boilerplatePatterns
+}
+
+// AnalyzeBatch analyzes several texts as AnalyzeTextAs would, first
+// stripping the paragraphs they share when the boilerplate policy is
+// enabled. Each result lists what was removed from its text as
+// boilerplate_removed in the metadata.
+func (ad *AnomalyDetector) AnalyzeBatch(texts []string, contentType ContentType) ([]*models.AnomalyResult, error) {
+	shared := ad.NewSharedBoilerplate()
+	for _, text := range texts {
+		shared.Add(text)
+	}
+
+	results := make([]*models.AnomalyResult, len(texts))
+	for i, text := range texts {
+		result, err := ad.AnalyzeBatchText(text, contentType, shared)
+		if err != nil {
+			return nil, fmt.Errorf("analysis of text %d failed: %w", i, err)
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// SharedBoilerplate finds the paragraphs repeated across the texts of a
+// batch too large to hold at once: Add every text, then analyze each with
+// AnalyzeBatchText. Only paragraph fingerprints are kept. Add is not safe
+// for concurrent use, and must not be called once analysis has started;
+// AnalyzeBatchText is.
+type SharedBoilerplate struct {
+	minRepeats int
+	minLength  int
+	counts     map[string]int
+	once       sync.Once
+	repeated   map[string]bool
+}
+
+// NewSharedBoilerplate returns an empty SharedBoilerplate for the current
+// boilerplate policy, or nil, which AnalyzeBatchText accepts, when the
+// policy is disabled
+func (ad *AnomalyDetector) NewSharedBoilerplate() *SharedBoilerplate {
+	policy, _ := ad.boilerplatePolicy()
+	if !policy.Enabled {
+		return nil
+	}
+	return &SharedBoilerplate{
+		minRepeats: policy.MinRepeats,
+		minLength:  policy.MinLength,
+		counts:     make(map[string]int),
+	}
+}
+
+// Add counts the paragraphs of one text of the batch
+func (b *SharedBoilerplate) Add(text string) {
+	if b == nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, paragraph := range paragraphBreak.Split(text, -1) {
+		fingerprint := paragraphFingerprint(paragraph)
+		if len(fingerprint) < b.minLength || seen[fingerprint] {
+			continue
+		}
+		seen[fingerprint] = true
+		b.counts[fingerprint]++
+	}
+}
+
+// repeatedParagraphs returns the fingerprints of the paragraphs found in
+// minRepeats or more of the texts added
+func (b *SharedBoilerplate) repeatedParagraphs() map[string]bool {
+	if b == nil {
+		return nil
+	}
+	b.once.Do(func() {
+		b.repeated = make(map[string]bool)
+		for fingerprint, count := range b.counts {
+			if count >= b.minRepeats {
+				b.repeated[fingerprint] = true
+			}
+		}
+		b.counts = nil
+	})
+	return b.repeated
+}
+
+// AnalyzeBatchText analyzes one text of a batch as AnalyzeBatch would,
+// stripping the paragraphs shared records as repeated across the batch
+func (ad *AnomalyDetector) AnalyzeBatchText(text string, contentType ContentType, shared *SharedBoilerplate) (*models.AnomalyResult, error) {
+	start := time.Now()
+	result, err := ad.stripAndAnalyze(recordingBaselines(context.Background()), text, contentType, Selection{}, shared.repeatedParagraphs())
+	if err != nil {
+		return nil, err
+	}
+	return ad.finishResult(text, result, start), nil
+}
+
+// stripAndAnalyze strips boilerplate from text, the paragraphs whose
+// fingerprints are in repeated as well as matches of the policy's patterns,
+// then analyzes what is left
+func (ad *AnomalyDetector) stripAndAnalyze(ctx context.Context, text string, contentType ContentType, selection Selection, repeated map[string]bool) (*models.AnomalyResult, error) {
+	text, removed, offsets := ad.stripBoilerplate(text, repeated)
+	result, err := ad.analyzeText(withOffsets(ctx, offsets), text, contentType, selection)
+	if err != nil {
+		return nil, err
+	}
+	if len(removed) > 0 {
+		result.Metadata["boilerplate_removed"] = removed
+	}
+	return result, nil
+}
+
+// stripBoilerplate removes the paragraphs of text whose fingerprints are in
+// repeated, then the matches of the boilerplate patterns, if the policy is
+// enabled. The offset map locates the stripped text in text, or is nil when
+// text was kept as it is.
+func (ad *AnomalyDetector) stripBoilerplate(text string, repeated map[string]bool) (string, []BoilerplateRemoval, *offsetMap) {
+	policy, patterns := ad.boilerplatePolicy()
+	if !policy.Enabled {
+		return text, nil, nil
+	}
+
+	var removed []BoilerplateRemoval
+	var offsets *offsetMap
+	if len(repeated) > 0 {
+		var stripped derivedText
+		breaks := paragraphBreak.FindAllStringIndex(text, -1)
+		start, kept := 0, 0
+		for i := 0; i <= len(breaks); i++ {
+			end := len(text)
+			if i < len(breaks) {
+				end = breaks[i][0]
+			}
+			paragraph := text[start:end]
+			if repeated[paragraphFingerprint(paragraph)] {
+				removed = append(removed, BoilerplateRemoval{Source: BoilerplateRepeated, Text: strings.TrimSpace(paragraph)})
+			} else {
+				if kept > 0 {
+					stripped.insert("\n\n", breaks[i-1][0])
+				}
+				stripped.copy(text, start, end)
+				kept++
+			}
+			if i < len(breaks) {
+				start = breaks[i][1]
+			}
+		}
+		text, offsets = stripped.result()
+	}
+
+	for i, pattern := range patterns {
+		matches := pattern.FindAllStringIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var stripped derivedText
+		last := 0
+		for _, match := range matches {
+			if trimmed := strings.TrimSpace(text[match[0]:match[1]]); trimmed != "" {
+				removed = append(removed, BoilerplateRemoval{Source: BoilerplatePattern, Pattern: policy.Patterns[i], Text: trimmed})
+			}
+			stripped.copy(text, last, match[0])
+			last = match[1]
+		}
+		stripped.copy(text, last, len(text))
+		parent := offsets
+		text, offsets = stripped.result()
+		offsets.parent = parent
+	}
+	return text, removed, offsets
+}
+
+// paragraphBreak separates paragraphs: a blank line, possibly holding
+// whitespace
+var paragraphBreak = regexp.MustCompile(`\n[ \t\r]*\n`)
+
+// paragraphFingerprint reduces a paragraph to its lower-cased words with
+// every digit replaced by 0, so copies differing only in case, spacing,
+// punctuation, dates or numbers share a fingerprint
+func paragraphFingerprint(paragraph string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(paragraph) {
+		switch {
+		case unicode.IsDigit(r):
+			r = '0'
+		case !unicode.IsLetter(r):
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// BootstrapConfig controls bootstrap confidence estimation: the sentences of
+// a text are resampled with replacement Replicates times, and each resample
+// is scored
+type BootstrapConfig struct {
+	Replicates int     // resamples to score, at least 2
+	Level      float64 // coverage of the reported interval, in (0, 1)
+	Seed       int64   // seed for resampling, for reproducible runs
+}
+
+// DefaultBootstrapConfig returns a configuration scoring 50 resamples and
+// reporting a 95% interval
+func DefaultBootstrapConfig() BootstrapConfig {
+	return BootstrapConfig{
+		Replicates: 50,
+		Level:      0.95,
+		Seed:       1,
+	}
+}
+
+// Validate checks that the bootstrap configuration is usable
+func (c BootstrapConfig) Validate() error {
+	if c.Replicates < 2 {
+		return fmt.Errorf("bootstrap needs at least 2 replicates, got %d", c.Replicates)
+	}
+	if c.Level <= 0 || c.Level >= 1 {
+		return fmt.Errorf("bootstrap interval level %f is outside (0, 1)", c.Level)
+	}
+	return nil
+}
+
+// BootstrapEstimate summarizes the scores of the resampled texts. Support is
+// the share of them on the same side of the decision threshold as the score
+// of the whole text. Texts of fewer than two sentences are not resampled and
+// have no replicates.
+type BootstrapEstimate struct {
+	Replicates int     `json:"replicates"`
+	Sentences  int     `json:"sentences"`
+	Mean       float64 `json:"mean"`
+	StdErr     float64 `json:"std_err"`
+	Lower      float64 `json:"lower"`
+	Upper      float64 `json:"upper"`
+	Level      float64 `json:"level"`
+	Support    float64 `json:"support"`
+}
+
+// AnalyzeTextBootstrapped analyzes text, then estimates how stable its score
+// is by bootstrapping its sentences. The result's confidence becomes the
+// bootstrap support for its verdict, with the original confidence kept in
+// metadata as heuristic_confidence and the estimate as bootstrap.
+//
+// Every sentence is run through the analyzers once; only combining their
+// results into a score is repeated for each resample, so the cost is about
+// that of analyzing the text a second time, sentence by sentence.
+func (ad *AnomalyDetector) AnalyzeTextBootstrapped(text string, contentType ContentType, cfg BootstrapConfig) (*models.AnomalyResult, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := ad.analyze(recordingBaselines(context.Background()), text, contentType, Selection{})
+	if err != nil {
+		return nil, err
+	}
+	if result.Outcome == models.OutcomeAnalyzed {
+		estimate, err := ad.bootstrap(text, result, cfg)
+		if err != nil {
+			return nil, err
+		}
+		result.Metadata["bootstrap"] = *estimate
+		if estimate.Replicates > 0 {
+			result.Metadata["heuristic_confidence"] = result.Confidence
+			result.Confidence = estimate.Support
+			result.Severity = ad.severityBands().Classify(result.Score, result.Confidence)
+		}
+	}
+	return ad.finishResult(text, result, start), nil
+}
+
+// sentenceResults holds the analyzer results of one sentence
+type sentenceResults struct {
+	length  float64
+	results map[string]*models.AnalysisResult
+}
+
+// bootstrap scores resamples of the sentences of text, which was analyzed
+// into result
+func (ad *AnomalyDetector) bootstrap(text string, result *models.AnomalyResult, cfg BootstrapConfig) (*BootstrapEstimate, error) {
+	contentType := ContentTypeProse
+	if name, ok := result.Metadata["content_type"].(string); ok {
+		contentType = ContentType(name)
+	}
+	profile := ad.profileFor(contentType)
+	threshold := ad.decisionThresholds().For(contentType)
+
+	// The sentences resampled are those analyzed, without boilerplate
+	text, _, _ = ad.stripBoilerplate(text, nil)
+	var sentences []sentenceResults
+	for i, sentence := range ad.currentTokenizer().Sentences(text) {
+		if IsEffectivelyEmpty(sentence) {
+			continue
+		}
+		run, err := ad.runAnalyzers(context.Background(), sentence, profile, Selection{})
+		if err != nil {
+			return nil, fmt.Errorf("analysis of sentence %d failed: %w", i, err)
+		}
+		sentences = append(sentences, sentenceResults{length: float64(len(sentence)), results: run.results})
+	}
+	estimate := &BootstrapEstimate{Sentences: len(sentences), Level: cfg.Level}
+	if len(sentences) < 2 {
+		return estimate, nil
+	}
+
+	sample := make([]int, len(sentences))
+	for i := range sample {
+		sample[i] = i
+	}
+	// Pooling sentence results only approximates analyzing the whole text,
+	// so the resamples are shifted by the gap between the two, centring them
+	// on the text's score
+	offset := result.Score - ad.pooledScore(sentences, sample, profile, threshold)
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	scores := make([]float64, cfg.Replicates)
+	anomalous := result.Score > threshold
+	agreeing := 0
+	for r := range scores {
+		for i := range sample {
+			sample[i] = rng.Intn(len(sentences))
+		}
+		scores[r] = math.Max(0, math.Min(1, ad.pooledScore(sentences, sample, profile, threshold)+offset))
+		if (scores[r] > threshold) == anomalous {
+			agreeing++
+		}
+	}
+
+	mean := 0.0
+	for _, score := range scores {
+		mean += score
+	}
+	mean /= float64(len(scores))
+	variance := 0.0
+	for _, score := range scores {
+		variance += (score - mean) * (score - mean)
+	}
+	sort.Float64s(scores)
+
+	estimate.Replicates = cfg.Replicates
+	estimate.Mean = mean
+	estimate.StdErr = math.Sqrt(variance / float64(len(scores)-1))
+	estimate.Lower = quantile(scores, (1-cfg.Level)/2)
+	estimate.Upper = quantile(scores, 1-(1-cfg.Level)/2)
+	estimate.Support = float64(agreeing) / float64(len(scores))
+	return estimate, nil
+}
+
+// pooledScore scores the sentences at the sampled indices as one text: each
+// analyzer's score is the mean of its sentence scores weighted by length
+// times confidence, and the pooled results are combined as in an analysis
+func (ad *AnomalyDetector) pooledScore(sentences []sentenceResults, sample []int, profile AnalyzerProfile, threshold float64) float64 {
+	pooled := make(map[string]*models.AnalysisResult)
+	weights := make(map[string]float64)
+	lengths := make(map[string]float64)
+	for _, i := range sample {
+		for name, result := range sentences[i].results {
+			entry, ok := pooled[name]
+			if !ok {
+				entry = &models.AnalysisResult{}
+				pooled[name] = entry
+			}
+			weight := sentences[i].length * result.Confidence
+			entry.Score += result.Score * weight
+			entry.Confidence += result.Confidence * sentences[i].length
+			weights[name] += weight
+			lengths[name] += sentences[i].length
+		}
+	}
+	for name, entry := range pooled {
+		if weights[name] > 0 {
+			entry.Score /= weights[name]
+		}
+		entry.Confidence /= lengths[name]
+	}
+
+	contributing, _ := ad.confidencePolicy().contributing(pooled)
+	return ad.aggregateWeightedResults(contributing, nil, profile, threshold).Score
+}
+
+// quantile returns the q-quantile of sorted values, interpolating linearly
+func quantile(sorted []float64, q float64) float64 {
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	fraction := position - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*fraction
+}
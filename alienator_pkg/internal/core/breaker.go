@@ -0,0 +1,194 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Circuit breaker states
+const (
+	// CircuitClosed runs the analyzer as usual
+	CircuitClosed = "closed"
+	// CircuitOpen leaves the analyzer out of every analysis until the
+	// cooldown has passed
+	CircuitOpen = "open"
+	// CircuitHalfOpen lets a single analysis probe the analyzer: success
+	// closes the circuit, failure opens it again
+	CircuitHalfOpen = "half_open"
+)
+
+// BreakerPolicy controls the per-analyzer circuit breakers. After Failures
+// consecutive errors or timeouts an analyzer's circuit opens and it is left
+// out of analyses, the remaining analyzers' weights filling its place, until
+// Cooldown has passed and a probe finds it working again. A probe that fails
+// doesn't fail its analysis. Failures of 0 disables the breakers.
+type BreakerPolicy struct {
+	Failures int
+	Cooldown time.Duration
+}
+
+// DefaultBreakerPolicy returns a policy opening a circuit after 5
+// consecutive failures and probing it every 30 seconds
+func DefaultBreakerPolicy() BreakerPolicy {
+	return BreakerPolicy{Failures: 5, Cooldown: 30 * time.Second}
+}
+
+// Validate checks that the failure count is not negative and the cooldown
+// is positive
+func (p BreakerPolicy) Validate() error {
+	if p.Failures < 0 {
+		return fmt.Errorf("breaker failure count must not be negative, got %d", p.Failures)
+	}
+	if p.Failures > 0 && p.Cooldown <= 0 {
+		return fmt.Errorf("breaker cooldown must be positive, got %s", p.Cooldown)
+	}
+	return nil
+}
+
+// circuitBreaker tracks one analyzer's recent failures
+type circuitBreaker struct {
+	state    string
+	failures int // consecutive
+	trips    int
+	openedAt time.Time
+	probing  bool // a half-open probe is running
+}
+
+// errAnalyzerTimeout is the failure recorded for an analyzer abandoned when
+// the time budget ran out
+var errAnalyzerTimeout = errors.New("analyzer did not finish within the time budget")
+
+// SetBreakerPolicy replaces the circuit breaker policy. Circuits already
+// open stay open until their cooldown under the new policy has passed.
+func (ad *AnomalyDetector) SetBreakerPolicy(policy BreakerPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	ad.breaker = policy
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// breakerPolicy returns the current circuit breaker policy
+func (ad *AnomalyDetector) breakerPolicy() BreakerPolicy {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.breaker
+}
+
+// admit reports whether the analyzer may run. An open circuit whose
+// cooldown has passed becomes half-open and admits this one caller as its
+// probe.
+func (ad *AnomalyDetector) admit(name string) bool {
+	policy := ad.breakerPolicy()
+	if policy.Failures == 0 {
+		return true
+	}
+
+	ad.breakersMu.Lock()
+	defer ad.breakersMu.Unlock()
+	breaker, ok := ad.breakers[name]
+	if !ok || breaker.state == CircuitClosed {
+		return true
+	}
+	if breaker.probing || time.Since(breaker.openedAt) < policy.Cooldown {
+		return false
+	}
+	breaker.state = CircuitHalfOpen
+	breaker.probing = true
+	ad.updateCircuitMetric(name, breaker.state)
+	return true
+}
+
+// recordOutcome updates the analyzer's circuit with the outcome of a run,
+// reporting whether a failure should be tolerated rather than fail the
+// analysis, as it is for a probe
+func (ad *AnomalyDetector) recordOutcome(name string, err error) bool {
+	policy := ad.breakerPolicy()
+	if policy.Failures == 0 {
+		return false
+	}
+
+	ad.breakersMu.Lock()
+	defer ad.breakersMu.Unlock()
+	breaker, ok := ad.breakers[name]
+	if !ok {
+		breaker = &circuitBreaker{state: CircuitClosed}
+		ad.breakers[name] = breaker
+	}
+
+	if err == nil {
+		if breaker.state != CircuitClosed {
+			ad.logger.Info("Analyzer recovered, circuit closed", zap.String("analyzer", name))
+			ad.updateCircuitMetric(name, CircuitClosed)
+		}
+		breaker.state, breaker.failures, breaker.probing = CircuitClosed, 0, false
+		return false
+	}
+
+	breaker.failures++
+	probe := breaker.state == CircuitHalfOpen
+	if probe || (breaker.state == CircuitClosed && breaker.failures >= policy.Failures) {
+		if !probe {
+			breaker.trips++
+			if ad.metrics != nil {
+				ad.metrics.RecordAnalyzerCircuitTrip(name)
+			}
+		}
+		ad.logger.Warn("Analyzer keeps failing, circuit opened",
+			zap.String("analyzer", name),
+			zap.Int("consecutive_failures", breaker.failures),
+			zap.Duration("cooldown", policy.Cooldown),
+			zap.Error(err))
+		breaker.state, breaker.openedAt, breaker.probing = CircuitOpen, time.Now(), false
+		ad.updateCircuitMetric(name, CircuitOpen)
+	}
+	return probe
+}
+
+// releaseProbe returns an admitted probe that never ran, such as one skipped
+// for lack of time, so that the next analysis probes instead
+func (ad *AnomalyDetector) releaseProbe(name string) {
+	ad.breakersMu.Lock()
+	defer ad.breakersMu.Unlock()
+	if breaker, ok := ad.breakers[name]; ok {
+		breaker.probing = false
+	}
+}
+
+// circuitState returns the state of the analyzer's circuit, nil with the
+// breakers disabled
+func (ad *AnomalyDetector) circuitState(name string) *models.CircuitState {
+	policy := ad.breakerPolicy()
+	if policy.Failures == 0 {
+		return nil
+	}
+
+	ad.breakersMu.Lock()
+	defer ad.breakersMu.Unlock()
+	state := &models.CircuitState{State: CircuitClosed}
+	if breaker, ok := ad.breakers[name]; ok {
+		state.State = breaker.state
+		state.ConsecutiveFailures = breaker.failures
+		state.Trips = breaker.trips
+		if breaker.state != CircuitClosed {
+			retryAt := breaker.openedAt.Add(policy.Cooldown)
+			state.RetryAt = &retryAt
+		}
+	}
+	return state
+}
+
+// updateCircuitMetric publishes a circuit's state
+func (ad *AnomalyDetector) updateCircuitMetric(name, state string) {
+	if ad.metrics == nil {
+		return
+	}
+	value := map[string]float64{CircuitClosed: 0, CircuitHalfOpen: 1, CircuitOpen: 2}[state]
+	ad.metrics.UpdateAnalyzerCircuitState(name, value)
+}
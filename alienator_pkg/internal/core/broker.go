@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -19,6 +20,10 @@ type BrokerConfig struct {
 	RetryDelay       time.Duration
 }
 
+// ErrTopicRateLimited is returned by Broker.Publish when the rate limiter
+// refuses a publish to the topic
+var ErrTopicRateLimited = errors.New("rate limit exceeded")
+
 // DefaultBrokerConfig returns default broker configuration
 func DefaultBrokerConfig() *BrokerConfig {
 	return &BrokerConfig{
@@ -92,7 +97,7 @@ func (b *Broker) Publish(ctx context.Context, topic string, msg *proto.Message)
 	if b.rateLimiter != nil {
 		allowed := b.rateLimiter.Allow(fmt.Sprintf("publish:%s", topic))
 		if !allowed {
-			return fmt.Errorf("rate limit exceeded for topic: %s", topic)
+			return fmt.Errorf("%w for topic: %s", ErrTopicRateLimited, topic)
 		}
 	}
 	
@@ -148,6 +153,12 @@ func (b *Broker) Publish(ctx context.Context, topic string, msg *proto.Message)
 	return nil
 }
 
+// IsTransient reports whether a publish error may succeed if retried: only
+// a refusal by the rate limiter, which lets publishes through again over time
+func (b *Broker) IsTransient(err error) bool {
+	return errors.Is(err, ErrTopicRateLimited)
+}
+
 // Subscribe creates a subscription to a topic
 func (b *Broker) Subscribe(ctx context.Context, topic string, handler MessageHandler) (string, error) {
 	if handler == nil {
@@ -0,0 +1,13 @@
+//go:build !lite
+
+package core
+
+// BuildProfile names the analyzer build: "full" here, "lite" when built with
+// the lite tag for edge deployments
+const BuildProfile = "full"
+
+// buildProfileHooks returns the hooks every detector of this build starts
+// with; the full build needs none
+func buildProfileHooks() []ResultHook {
+	return nil
+}
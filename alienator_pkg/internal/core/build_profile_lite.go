@@ -0,0 +1,21 @@
+//go:build lite
+
+package core
+
+// BuildProfile names the analyzer build: "lite", for edge deployments that
+// leave out the embedding analyzer and neural training. Neither has external
+// dependencies, so the lite build saves their work rather than binary size.
+const BuildProfile = "lite"
+
+// buildProfileNote documents in every result what the lite build gives up
+const buildProfileNote = "lite build: the embedding analyzer and neural retraining are left out, so semantic signals are missing and accuracy is lower than the full build's"
+
+// buildProfileHooks returns the hooks every detector of this build starts
+// with: one marking each result as coming from the lite build. The key isn't
+// "profile", which names the content profile the result was weighted by.
+func buildProfileHooks() []ResultHook {
+	return []ResultHook{MetadataHook(map[string]interface{}{
+		"build_profile":      BuildProfile,
+		"build_profile_note": buildProfileNote,
+	})}
+}
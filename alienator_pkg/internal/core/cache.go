@@ -0,0 +1,222 @@
+package core
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// FeatureCacheConfig selects the analyzers whose results are cached by
+// content hash, so that windowed and batch analyses seeing the same text
+// again skip the work. Only deterministic analyzers, whose result depends on
+// nothing but the text, should be listed, such as the cryptographic and
+// compression analyzers; analyzers that learn or are retrained should not.
+// Size bounds the results kept per analyzer; the least recently used are
+// evicted.
+type FeatureCacheConfig struct {
+	Analyzers []string
+	Size      int
+}
+
+// Validate checks that the size is positive and that no analyzer is listed
+// twice
+func (c FeatureCacheConfig) Validate() error {
+	if len(c.Analyzers) > 0 && c.Size <= 0 {
+		return fmt.Errorf("cache size must be positive, got %d", c.Size)
+	}
+	seen := make(map[string]bool, len(c.Analyzers))
+	for _, name := range c.Analyzers {
+		if name == "" {
+			return errors.New("cached analyzers must not contain empty names")
+		}
+		if seen[name] {
+			return fmt.Errorf("analyzer %q is listed twice in the cache", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// resultCache is a bounded LRU of one analyzer's results, keyed by the
+// content hash of the analyzed text
+type resultCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front is most recently used
+	stats    models.CacheStats
+}
+
+type cacheEntry struct {
+	key    string
+	result models.AnalysisResult
+}
+
+func newResultCache(capacity int) *resultCache {
+	return &resultCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// get returns a copy of the cached result for key. Copies are returned
+// because aggregation clamps each result's confidence in place and hooks
+// edit its metadata.
+func (c *resultCache) get(key string) (*models.AnalysisResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(element)
+	result := cloneResult(&element.Value.(*cacheEntry).result)
+	return &result, true
+}
+
+// put stores a copy of result, evicting the least recently used entry when
+// the cache is full
+func (c *resultCache) put(key string, result *models.AnalysisResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry).result = cloneResult(result)
+		c.order.MoveToFront(element)
+		return
+	}
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: cloneResult(result)})
+}
+
+// cloneResult copies a result with its metadata, so that the cache and the
+// results served from it never share a map or slice
+func cloneResult(result *models.AnalysisResult) models.AnalysisResult {
+	cloned := *result
+	cloned.Metadata = cloneMetadata(result.Metadata)
+	return cloned
+}
+
+func cloneMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	cloned := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		cloned[key] = cloneValue(value)
+	}
+	return cloned
+}
+
+// cloneValue copies maps and slices, those nested in them included; other
+// values are copied by assignment
+func cloneValue(value interface{}) interface{} {
+	if metadata, ok := value.(map[string]interface{}); ok {
+		return cloneMetadata(metadata)
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return value
+		}
+		cloned := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cloned.Index(i).Set(cloneElement(v.Index(i)))
+		}
+		return cloned.Interface()
+	case reflect.Map:
+		if v.IsNil() {
+			return value
+		}
+		cloned := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cloned.SetMapIndex(iter.Key(), cloneElement(iter.Value()))
+		}
+		return cloned.Interface()
+	}
+	return value
+}
+
+// cloneElement copies one element of a slice or map, keeping its type
+func cloneElement(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		return v
+	}
+	cloned := reflect.ValueOf(cloneValue(v.Interface()))
+	if !cloned.IsValid() {
+		return reflect.Zero(v.Type())
+	}
+	return cloned.Convert(v.Type())
+}
+
+// snapshot returns the cache's counters
+func (c *resultCache) snapshot() models.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	stats.Capacity = c.capacity
+	return stats
+}
+
+// SetFeatureCache replaces the result caches, dropping everything cached so
+// far. An empty analyzer list disables caching. Call it again after
+// reconfiguring a cached analyzer.
+func (ad *AnomalyDetector) SetFeatureCache(cfg FeatureCacheConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	caches := make(map[string]*resultCache, len(cfg.Analyzers))
+	for _, name := range cfg.Analyzers {
+		caches[name] = newResultCache(cfg.Size)
+	}
+
+	ad.settingsMu.Lock()
+	ad.caches = caches
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// FeatureCacheStats returns the counters of each cached analyzer by name
+func (ad *AnomalyDetector) FeatureCacheStats() map[string]models.CacheStats {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+
+	stats := make(map[string]models.CacheStats, len(ad.caches))
+	for name, cache := range ad.caches {
+		stats[name] = cache.snapshot()
+	}
+	return stats
+}
+
+// clearFeatureCacheLocked drops every cached result but keeps the
+// configuration. Callers hold settingsMu.
+func (ad *AnomalyDetector) clearFeatureCacheLocked() {
+	for name, cache := range ad.caches {
+		ad.caches[name] = newResultCache(cache.capacity)
+	}
+}
+
+// resultCacheFor returns the cache of the named analyzer, or nil when its
+// results are not cached
+func (ad *AnomalyDetector) resultCacheFor(name string) *resultCache {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.caches[name]
+}
@@ -0,0 +1,283 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Code block handling modes
+const (
+	// CodeBlocksOff analyzes mixed documents as one text (the default)
+	CodeBlocksOff = "off"
+	// CodeBlocksSplit analyzes the prose of a mixed document with the prose
+	// profile and its code blocks with the code profile, then combines them
+	CodeBlocksSplit = "split"
+	// CodeBlocksStrip analyzes only the prose of a mixed document
+	CodeBlocksStrip = "strip"
+)
+
+// CodeBlockPolicy controls how code blocks within prose are handled. Fenced
+// blocks, between lines starting with ``` or ~~~, are always recognized;
+// with Indented, so are runs of lines indented by four spaces or a tab that
+// follow a blank line, as in markdown. The policy only applies to text
+// analyzed as prose or classified automatically that holds both prose and
+// code.
+type CodeBlockPolicy struct {
+	Mode     string
+	Indented bool
+}
+
+// DefaultCodeBlockPolicy returns the policy matching the detector's original
+// behaviour: mixed documents are analyzed as one text
+func DefaultCodeBlockPolicy() CodeBlockPolicy {
+	return CodeBlockPolicy{Mode: CodeBlocksOff, Indented: true}
+}
+
+// Validate checks the mode
+func (p CodeBlockPolicy) Validate() error {
+	switch p.Mode {
+	case CodeBlocksOff, CodeBlocksSplit, CodeBlocksStrip:
+		return nil
+	default:
+		return fmt.Errorf("unknown code block mode %q (expected off, split or strip)", p.Mode)
+	}
+}
+
+// CodeSplit describes how a mixed document was divided, in bytes of prose
+// and code. Fence lines count as neither.
+type CodeSplit struct {
+	Mode         string  `json:"mode"`
+	Blocks       int     `json:"blocks"`
+	ProseChars   int     `json:"prose_chars"`
+	CodeChars    int     `json:"code_chars"`
+	CodeFraction float64 `json:"code_fraction"`
+}
+
+// SplitCodeBlocks separates the code blocks of text from its prose,
+// returning each joined by newlines. An unclosed fence runs to the end of
+// the text.
+func SplitCodeBlocks(text string, indented bool) (prose, code string, split CodeSplit) {
+	parts, split := splitCodeBlocks(text, indented)
+	return parts.prose, parts.code, split
+}
+
+// codeParts is the prose and the code of a mixed document, with the offset
+// maps locating each in the document
+type codeParts struct {
+	prose, code               string
+	proseOffsets, codeOffsets *offsetMap
+}
+
+// splitCodeBlocks splits text as SplitCodeBlocks does
+func splitCodeBlocks(text string, indented bool) (codeParts, CodeSplit) {
+	var split CodeSplit
+	var proseText, codeText derivedText
+	proseLines, codeLines := 0, 0
+	addLine := func(part *derivedText, lines *int, start, end int) {
+		if *lines > 0 {
+			// The newline ending the previous line of the document
+			part.insert("\n", start-1)
+		}
+		part.copy(text, start, end)
+		*lines++
+	}
+
+	fence := ""
+	previousBlank, inIndented := true, false
+	for start := 0; start <= len(text); {
+		end := len(text)
+		if newline := strings.IndexByte(text[start:], '\n'); newline >= 0 {
+			end = start + newline
+		}
+		line, next := text[start:end], end+1
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			} else {
+				addLine(&codeText, &codeLines, start, end)
+			}
+			start = next
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			fence = marker
+			split.Blocks++
+			inIndented, previousBlank = false, false
+			start = next
+			continue
+		}
+
+		blank := trimmed == ""
+		if indented && !blank && isIndentedCode(line) && (previousBlank || inIndented) {
+			if !inIndented {
+				split.Blocks++
+			}
+			inIndented = true
+			addLine(&codeText, &codeLines, start, end)
+			previousBlank = false
+			start = next
+			continue
+		}
+		if !blank {
+			inIndented = false
+		}
+		previousBlank = blank
+		addLine(&proseText, &proseLines, start, end)
+		start = next
+	}
+
+	var parts codeParts
+	joined, joinedOffsets := proseText.result()
+	lead := len(joined) - len(strings.TrimLeftFunc(joined, unicode.IsSpace))
+	parts.prose = strings.TrimSpace(joined)
+	parts.proseOffsets = &offsetMap{pieces: []mappedPiece{{derived: 0, original: lead}}, parent: joinedOffsets}
+	parts.code, parts.codeOffsets = codeText.result()
+
+	split.ProseChars, split.CodeChars = len(parts.prose), len(strings.TrimSpace(parts.code))
+	if total := split.ProseChars + split.CodeChars; total > 0 {
+		split.CodeFraction = float64(split.CodeChars) / float64(total)
+	}
+	return parts, split
+}
+
+// fenceMarker returns the fence a line opens, three or more backticks or
+// tildes, or "" if it opens none
+func fenceMarker(trimmed string) string {
+	for _, char := range []string{"`", "~"} {
+		if strings.HasPrefix(trimmed, strings.Repeat(char, 3)) {
+			return strings.Repeat(char, len(trimmed)-len(strings.TrimLeft(trimmed, char)))
+		}
+	}
+	return ""
+}
+
+// isIndentedCode reports whether a line is indented as markdown code
+func isIndentedCode(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+}
+
+// analyzeMixed analyzes a document holding both prose and code blocks. The
+// prose is analyzed as prose; in split mode the code is analyzed as code and
+// the two results are combined, weighted by length times confidence. Each
+// analyzer's details are combined the same way, scaled by its weight in the
+// profile of each part, so code barely moves the linguistic score and prose
+// barely moves the entropy score. The parts don't add to the normalization
+// baselines.
+func (ad *AnomalyDetector) analyzeMixed(ctx context.Context, parts codeParts, split CodeSplit, detected bool, selection Selection) (*models.AnomalyResult, error) {
+	ctx = withoutRecording(ctx)
+	proseResult, err := ad.analyze(withOffsets(ctx, parts.proseOffsets), parts.prose, ContentTypeProse, selection)
+	if err != nil {
+		return nil, err
+	}
+	proseResult.Metadata["content_type_detected"] = detected
+	proseResult.Metadata["code_blocks"] = split
+	if split.Mode == CodeBlocksStrip {
+		return proseResult, nil
+	}
+
+	codeResult, err := ad.analyze(withOffsets(ctx, parts.codeOffsets), parts.code, ContentTypeCode, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	proseWeight := float64(split.ProseChars) * proseResult.Confidence
+	codeWeight := float64(split.CodeChars) * codeResult.Confidence
+	score, confidence := proseResult.Score, proseResult.Confidence
+	if total := proseWeight + codeWeight; total > 0 {
+		score = (proseResult.Score*proseWeight + codeResult.Score*codeWeight) / total
+		proseShare := float64(split.ProseChars) / float64(split.ProseChars+split.CodeChars)
+		confidence = proseResult.Confidence*proseShare + codeResult.Confidence*(1-proseShare)
+	}
+
+	details := make(map[string]*models.AnalysisResult, len(proseResult.Details)+len(codeResult.Details))
+	proseProfile := selection.apply(ad.profileFor(ContentTypeProse))
+	codeProfile := selection.apply(ad.profileFor(ContentTypeCode))
+	for name, detail := range proseResult.Details {
+		details[name] = detail
+	}
+	for name, detail := range codeResult.Details {
+		other, ok := details[name]
+		if !ok {
+			details[name] = detail
+			continue
+		}
+		otherWeight := float64(split.ProseChars) * proseProfile.Weight(name)
+		weight := float64(split.CodeChars) * codeProfile.Weight(name)
+		if otherWeight+weight <= 0 {
+			continue
+		}
+		merged := *other
+		if weight > otherWeight {
+			merged = *detail
+		}
+		merged.Score = (other.Score*otherWeight + detail.Score*weight) / (otherWeight + weight)
+		merged.Confidence = (other.Confidence*otherWeight + detail.Confidence*weight) / (otherWeight + weight)
+		details[name] = &merged
+	}
+
+	threshold := ad.decisionThresholds().For(ContentTypeProse)
+	result := &models.AnomalyResult{
+		Score:       score,
+		Confidence:  confidence,
+		IsAnomalous: score > threshold,
+		Severity:    ad.severityBands().Classify(score, confidence),
+		Outcome:     models.OutcomeAnalyzed,
+		Details:     details,
+		// The code shares the language of the prose it is embedded in
+		Language:           proseResult.Language,
+		LanguageConfidence: proseResult.LanguageConfidence,
+		Metadata: map[string]interface{}{
+			"content_type":          string(ContentTypeProse),
+			"content_type_detected": detected,
+			"profile":               "prose+code",
+			"threshold":             threshold,
+			"code_blocks":           split,
+			"segment_scores": map[string]float64{
+				"prose": proseResult.Score,
+				"code":  codeResult.Score,
+			},
+		},
+	}
+	// With voting, the parts' anomalous shares are combined like their scores
+	if combination := ad.combinationPolicy(); combination.Mode == CombinationVote {
+		proseShare, _ := proseResult.Metadata["vote_share"].(float64)
+		codeShare, _ := codeResult.Metadata["vote_share"].(float64)
+		share := proseShare
+		if total := proseWeight + codeWeight; total > 0 {
+			share = (proseShare*proseWeight + codeShare*codeWeight) / total
+		}
+		result.IsAnomalous = share > combination.Quorum
+		result.Metadata["combination"] = combination.Mode
+		result.Metadata["vote_share"] = share
+	}
+	for _, key := range []string{"analyzers", "budget_ms"} {
+		if value, ok := proseResult.Metadata[key]; ok {
+			result.Metadata[key] = value
+		}
+	}
+	return result, nil
+}
+
+// SetCodeBlockPolicy replaces the policy for code blocks within prose
+func (ad *AnomalyDetector) SetCodeBlockPolicy(policy CodeBlockPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	ad.codeBlocks = policy
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// codeBlockPolicy returns the current code block policy
+func (ad *AnomalyDetector) codeBlockPolicy() CodeBlockPolicy {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.codeBlocks
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Combination modes
+const (
+	// CombinationFusion flags the confidence-weighted mean of the analyzer
+	// scores when it is above the content type's threshold (the default)
+	CombinationFusion = "fusion"
+	// CombinationVote has every analyzer vote anomalous or not against its
+	// own threshold and flags the result by weighted majority
+	CombinationVote = "vote"
+)
+
+// CombinationPolicy controls how analyzer results are combined into a
+// verdict. In vote mode an analyzer votes anomalous when its score, the
+// normalized one if available, is above its entry in VoteThresholds or, for
+// analyzers without one, the content type's decision threshold. Votes are
+// weighted like scores in fusion mode, by confidence times profile weight,
+// and the result is anomalous when the anomalous share of the weight is
+// above Quorum. The result score is the fused score in both modes, so
+// severity keeps its meaning.
+type CombinationPolicy struct {
+	Mode           string
+	Quorum         float64
+	VoteThresholds map[string]float64
+}
+
+// DefaultCombinationPolicy returns the policy matching the detector's
+// original behaviour: score fusion, with a simple majority should voting be
+// enabled
+func DefaultCombinationPolicy() CombinationPolicy {
+	return CombinationPolicy{
+		Mode:   CombinationFusion,
+		Quorum: 0.5,
+	}
+}
+
+// Validate checks the mode, quorum and per-analyzer thresholds
+func (p CombinationPolicy) Validate() error {
+	switch p.Mode {
+	case CombinationFusion, CombinationVote:
+	default:
+		return fmt.Errorf("unknown combination mode %q (expected fusion or vote)", p.Mode)
+	}
+	if p.Quorum < 0 || p.Quorum >= 1 {
+		return fmt.Errorf("vote quorum must be in [0, 1), got %g", p.Quorum)
+	}
+	names := make([]string, 0, len(p.VoteThresholds))
+	for name := range p.VoteThresholds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if threshold := p.VoteThresholds[name]; threshold <= 0 || threshold >= 1 {
+			return fmt.Errorf("vote threshold of analyzer %s must be between 0 and 1 exclusive, got %g", name, threshold)
+		}
+	}
+	return nil
+}
+
+// vote returns every analyzer's vote and the weighted share of anomalous
+// votes. Analyzers without weight cast no vote.
+func (p CombinationPolicy) vote(results map[string]*models.AnalysisResult, normalized map[string]float64, profile AnalyzerProfile, threshold float64) (map[string]bool, float64) {
+	votes := make(map[string]bool, len(results))
+	anomalous, total := 0.0, 0.0
+	for name, result := range results {
+		weight := result.Confidence * profile.Weight(name)
+		if weight <= 0 {
+			continue
+		}
+		score := result.Score
+		if value, ok := normalized[name]; ok {
+			score = value
+		}
+		limit, ok := p.VoteThresholds[name]
+		if !ok {
+			limit = threshold
+		}
+
+		votes[name] = score > limit
+		if votes[name] {
+			anomalous += weight
+		}
+		total += weight
+	}
+	if total == 0 {
+		return votes, 0
+	}
+	return votes, anomalous / total
+}
+
+// SetCombinationPolicy replaces the policy used to combine analyzer results
+func (ad *AnomalyDetector) SetCombinationPolicy(policy CombinationPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	policy.VoteThresholds = copyPerAnalyzer(policy.VoteThresholds)
+	ad.settingsMu.Lock()
+	ad.combination = policy
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// combinationPolicy returns the current combination policy
+func (ad *AnomalyDetector) combinationPolicy() CombinationPolicy {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.combination
+}
+
+// copyPerAnalyzer copies per-analyzer values, thresholds or weights, so
+// callers can't change them after they are set
+func copyPerAnalyzer(values map[string]float64) map[string]float64 {
+	if values == nil {
+		return nil
+	}
+	copied := make(map[string]float64, len(values))
+	for name, value := range values {
+		copied[name] = value
+	}
+	return copied
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Confidence aggregation strategies
+const (
+	// ConfidenceMean averages analyzer confidences (the default)
+	ConfidenceMean = "mean"
+	// ConfidenceMin takes the least confident analyzer, a conservative
+	// choice for high-stakes decisions
+	ConfidenceMin = "min"
+	// ConfidenceWeightedMean averages confidences by content profile weight,
+	// so analyzers that matter more for the content type count more
+	ConfidenceWeightedMean = "weighted-mean"
+	// ConfidenceHarmonicMean is dominated by the least confident analyzers
+	// without ignoring the others
+	ConfidenceHarmonicMean = "harmonic-mean"
+)
+
+// ConfidencePolicy controls how analyzer confidences are combined. Analyzers
+// whose own confidence is below MinContributing are left out of the ensemble,
+// unless that would leave none. Each remaining analyzer's confidence is
+// clamped to [Floor, Ceiling], then the clamped values are aggregated with
+// the Aggregation strategy.
+type ConfidencePolicy struct {
+	Floor           float64
+	Ceiling         float64
+	Aggregation     string
+	MinContributing float64
+}
+
+// DefaultConfidencePolicy returns the policy matching the detector's original
+// behaviour: no clamping and a plain mean
+func DefaultConfidencePolicy() ConfidencePolicy {
+	return ConfidencePolicy{
+		Floor:       0.0,
+		Ceiling:     1.0,
+		Aggregation: ConfidenceMean,
+	}
+}
+
+// Validate checks the clamping range, contribution minimum and aggregation
+// strategy
+func (p ConfidencePolicy) Validate() error {
+	if p.Floor < 0 || p.Ceiling > 1 || p.Floor > p.Ceiling {
+		return fmt.Errorf("confidence range [%f, %f] must satisfy 0 <= floor <= ceiling <= 1", p.Floor, p.Ceiling)
+	}
+	if p.MinContributing < 0 || p.MinContributing > 1 {
+		return fmt.Errorf("minimum contributing confidence %f is outside [0, 1]", p.MinContributing)
+	}
+	switch p.Aggregation {
+	case ConfidenceMean, ConfidenceMin, ConfidenceWeightedMean, ConfidenceHarmonicMean:
+		return nil
+	default:
+		return fmt.Errorf("unknown confidence aggregation %q (expected mean, min, weighted-mean or harmonic-mean)", p.Aggregation)
+	}
+}
+
+// Clamp limits a single analyzer confidence to [Floor, Ceiling]
+func (p ConfidencePolicy) Clamp(confidence float64) float64 {
+	return math.Max(p.Floor, math.Min(p.Ceiling, confidence))
+}
+
+// contributing splits analyzer results into those confident enough to join
+// the ensemble and the sorted names of those left out. When no analyzer is
+// confident enough, all of them contribute.
+func (p ConfidencePolicy) contributing(results map[string]*models.AnalysisResult) (map[string]*models.AnalysisResult, []string) {
+	if p.MinContributing <= 0 {
+		return results, nil
+	}
+
+	contributing := make(map[string]*models.AnalysisResult, len(results))
+	var excluded []string
+	for name, result := range results {
+		if result.Confidence < p.MinContributing {
+			excluded = append(excluded, name)
+			continue
+		}
+		contributing[name] = result
+	}
+	if len(contributing) == 0 {
+		return results, nil
+	}
+	sort.Strings(excluded)
+	return contributing, excluded
+}
+
+// Aggregate combines the (already clamped) confidences of the analyzer
+// results into the ensemble confidence
+func (p ConfidencePolicy) Aggregate(results map[string]*models.AnalysisResult, profile AnalyzerProfile) float64 {
+	if len(results) == 0 {
+		return 0.0
+	}
+
+	// Sum in a fixed order so the result doesn't depend on map iteration
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch p.Aggregation {
+	case ConfidenceMin:
+		lowest := 1.0
+		for _, name := range names {
+			lowest = math.Min(lowest, results[name].Confidence)
+		}
+		return lowest
+
+	case ConfidenceWeightedMean:
+		total, totalWeight := 0.0, 0.0
+		for _, name := range names {
+			weight := profile.Weight(name)
+			total += results[name].Confidence * weight
+			totalWeight += weight
+		}
+		if totalWeight == 0 {
+			return 0.0
+		}
+		return total / totalWeight
+
+	case ConfidenceHarmonicMean:
+		reciprocals := 0.0
+		for _, name := range names {
+			confidence := results[name].Confidence
+			if confidence <= 0 {
+				return 0.0
+			}
+			reciprocals += 1 / confidence
+		}
+		return float64(len(names)) / reciprocals
+
+	default:
+		total := 0.0
+		for _, name := range names {
+			total += results[name].Confidence
+		}
+		return total / float64(len(names))
+	}
+}
+
+// SetConfidencePolicy replaces the policy used to clamp and aggregate
+// analyzer confidences
+func (ad *AnomalyDetector) SetConfidencePolicy(policy ConfidencePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	ad.confidence = policy
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// SetConfidenceAggregation selects how analyzer confidences are combined into
+// the result confidence: "mean", "min", "weighted-mean" or "harmonic-mean"
+func (ad *AnomalyDetector) SetConfidenceAggregation(strategy string) error {
+	policy := ad.confidencePolicy()
+	policy.Aggregation = strategy
+	return ad.SetConfidencePolicy(policy)
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// ContentType identifies the broad kind of input text
+type ContentType string
+
+// Supported content types
+const (
+	ContentTypeAuto  ContentType = "auto"
+	ContentTypeProse ContentType = "prose"
+	ContentTypeCode  ContentType = "code"
+	ContentTypeData  ContentType = "data"
+	ContentTypeLog   ContentType = "log"
+)
+
+// AnalyzerProfile weights analyzer results for a content type. Analyzers not
+// listed keep a weight of 1; a weight of 0 skips the analyzer entirely.
+type AnalyzerProfile struct {
+	Name    string
+	Weights map[string]float64
+}
+
+// Weight returns the profile weight for the named analyzer
+func (p AnalyzerProfile) Weight(analyzer string) float64 {
+	if weight, ok := p.Weights[analyzer]; ok {
+		return weight
+	}
+	return 1.0
+}
+
+// analyzerProfiles maps each content type to its analyzer profile. Prose leans
+// on linguistic and semantic features; code, data and logs lean on entropy and
+// structure, where prose-tuned features are misleading. Watermarks are only
+// embedded in generated natural language, so data and logs skip that test,
+// and sentence and paragraph lengths only mean something in prose. Repeated
+// phrases are expected in code and routine in data and logs, so long-range
+// repetition counts mostly in prose. Injection phrasing is worth flagging
+// wherever text may reach a model, though code and logs quote it more often.
+// Tone and claim density are only weak signals, and only in prose.
+var analyzerProfiles = map[ContentType]AnalyzerProfile{
+	ContentTypeProse: {
+		Name: "prose",
+		Weights: map[string]float64{
+			"linguistic":    1.5,
+			"embedding":     1.2,
+			"entropy":       1.0,
+			"compression":   1.0,
+			"cryptographic": 0.8,
+			"watermark":     1.0,
+			"uniformity":    1.0,
+			"repetition":    1.2,
+			"sentiment":     0.5,
+			"claims":        0.4,
+			"injection":     1.0,
+		},
+	},
+	ContentTypeCode: {
+		Name: "code",
+		Weights: map[string]float64{
+			"linguistic":    0.2,
+			"embedding":     0.5,
+			"entropy":       1.5,
+			"compression":   1.3,
+			"cryptographic": 1.2,
+			"watermark":     0.3,
+			"uniformity":    0,
+			"repetition":    0.3,
+			"sentiment":     0,
+			"claims":        0,
+			"injection":     0.5,
+		},
+	},
+	ContentTypeData: {
+		Name: "structured-data",
+		Weights: map[string]float64{
+			"linguistic":    0,
+			"embedding":     0.3,
+			"entropy":       1.3,
+			"compression":   1.3,
+			"cryptographic": 1.5,
+			"watermark":     0,
+			"uniformity":    0,
+			"repetition":    0,
+			"sentiment":     0,
+			"claims":        0,
+			"injection":     1.0,
+		},
+	},
+	ContentTypeLog: {
+		Name: "log",
+		Weights: map[string]float64{
+			"linguistic":    0.3,
+			"embedding":     0.5,
+			"entropy":       1.2,
+			"compression":   1.5,
+			"cryptographic": 1.0,
+			"watermark":     0,
+			"uniformity":    0,
+			"repetition":    0,
+			"sentiment":     0,
+			"claims":        0,
+			"injection":     0.5,
+		},
+	},
+}
+
+// ParseContentType validates a content type name. An empty name means auto.
+func ParseContentType(name string) (ContentType, error) {
+	contentType := ContentType(strings.ToLower(strings.TrimSpace(name)))
+	if contentType == "" || contentType == ContentTypeAuto {
+		return ContentTypeAuto, nil
+	}
+	if _, ok := analyzerProfiles[contentType]; !ok {
+		return "", fmt.Errorf("unsupported content type: %s (expected auto, prose, code, data or log)", name)
+	}
+	return contentType, nil
+}
+
+// ProfileFor returns the analyzer profile for a content type, falling back to prose
+func ProfileFor(contentType ContentType) AnalyzerProfile {
+	if profile, ok := analyzerProfiles[contentType]; ok {
+		return profile
+	}
+	return analyzerProfiles[ContentTypeProse]
+}
+
+// Shares of non-empty lines that make text a log or code
+const (
+	logLineShare  = 0.6
+	codeLineShare = 0.3
+)
+
+var (
+	logLinePattern  = regexp.MustCompile(`^(\[?\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}|\[?\d{2}:\d{2}:\d{2}|[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2})|\b(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL)\b`)
+	codeLinePattern = regexp.MustCompile(`^\s*(func|def|class|import|package|return|if|for|while|var|let|const|public|private|#include|using|fn)\b|[;{}]\s*$|=>|:=|==|!=|\)\s*\{`)
+)
+
+// ClassifyContent guesses the content type of text from its line structure,
+// with a confidence in [0.5, 1] that grows with the margin by which the text
+// clears, or falls short of, the line share each type needs
+func ClassifyContent(text string) (ContentType, float64) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return ContentTypeProse, 0.5
+	}
+
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return ContentTypeData, 1.0
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(trimmed, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if isDelimited(lines) {
+		return ContentTypeData, 1.0
+	}
+
+	logLines, codeLines := 0, 0
+	for _, line := range lines {
+		if logLinePattern.MatchString(line) {
+			logLines++
+		}
+		if codeLinePattern.MatchString(line) {
+			codeLines++
+		}
+	}
+
+	total := float64(len(lines))
+	logShare, codeShare := float64(logLines)/total, float64(codeLines)/total
+	switch {
+	case logShare >= logLineShare:
+		return ContentTypeLog, 0.5 + 0.5*(logShare-logLineShare)/(1-logLineShare)
+	case codeShare >= codeLineShare:
+		return ContentTypeCode, 0.5 + 0.5*(codeShare-codeLineShare)/(1-codeLineShare)
+	default:
+		nearest := math.Max(logShare/logLineShare, codeShare/codeLineShare)
+		return ContentTypeProse, 0.5 + 0.5*(1-nearest)
+	}
+}
+
+// isDelimited reports whether lines look like CSV or TSV rows: at least three
+// lines sharing the same delimiter count of at least two
+func isDelimited(lines []string) bool {
+	if len(lines) < 3 {
+		return false
+	}
+
+	for _, delimiter := range []string{",", "\t", "|"} {
+		count := strings.Count(lines[0], delimiter)
+		if count < 2 {
+			continue
+		}
+		consistent := true
+		for _, line := range lines[1:] {
+			if strings.Count(line, delimiter) != count {
+				consistent = false
+				break
+			}
+		}
+		if consistent {
+			return true
+		}
+	}
+	return false
+}
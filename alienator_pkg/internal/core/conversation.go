@@ -0,0 +1,217 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Turn is one message of a conversation
+type Turn struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+// TurnResult is the analysis of a single turn
+type TurnResult struct {
+	Index  int                   `json:"index"`
+	Role   string                `json:"role"`
+	Result *models.AnomalyResult `json:"result"`
+}
+
+// SpeakerResult aggregates the turns of one participant
+type SpeakerResult struct {
+	Role           string          `json:"role"`
+	Turns          int             `json:"turns"`
+	AnomalousTurns int             `json:"anomalous_turns"`
+	Score          float64         `json:"score"`
+	Confidence     float64         `json:"confidence"`
+	IsAnomalous    bool            `json:"is_anomalous"`
+	Severity       models.Severity `json:"severity"`
+}
+
+// ConversationResult holds per-turn scores, per-speaker aggregates and an
+// aggregate over the whole conversation
+type ConversationResult struct {
+	Turns             []TurnResult              `json:"turns"`
+	Speakers          map[string]*SpeakerResult `json:"speakers"`
+	AnomalousSpeakers []string                  `json:"anomalous_speakers"`
+	Aggregate         *models.AnomalyResult     `json:"aggregate"`
+}
+
+// turnPrefix matches the "role: text" line that opens a turn. Roles are short
+// labels of one or two words such as "user", "assistant" or "Agent 2", so
+// that prose like "Here is the plan: ..." continues the current turn.
+var turnPrefix = regexp.MustCompile(`^\s*([A-Za-z][\w.-]{0,31}(?: [\w.-]{1,16})?)\s*:\s?(.*)$`)
+
+// ParseTranscript splits a plain text transcript into turns. Each turn starts
+// with a "role: text" line; following lines without a role prefix continue
+// the current turn.
+func ParseTranscript(transcript string) ([]Turn, error) {
+	turns := make([]Turn, 0)
+	var current *Turn
+
+	scanner := bufio.NewScanner(strings.NewReader(transcript))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(transcript)+1)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		// "https://..." and similar are not turns
+		if match := turnPrefix.FindStringSubmatch(line); match != nil && !strings.HasPrefix(match[2], "//") {
+			turns = append(turns, Turn{Role: strings.TrimSpace(match[1]), Text: match[2]})
+			current = &turns[len(turns)-1]
+			continue
+		}
+		if current == nil {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("transcript line %d does not start with a \"role: text\" turn", lineNo)
+		}
+		current.Text += "\n" + line
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("transcript contains no turns")
+	}
+
+	for i := range turns {
+		turns[i].Text = strings.TrimSpace(turns[i].Text)
+	}
+	return turns, nil
+}
+
+// AnalyzeConversation scores each turn separately and aggregates the scores
+// per speaker and over the conversation, so that anomalies can be attributed
+// to the participants whose messages look machine-generated. Empty turns are
+// skipped. Only the aggregate is published to event subscribers.
+func (ad *AnomalyDetector) AnalyzeConversation(turns []Turn) (*ConversationResult, error) {
+	start := time.Now()
+
+	conversation := &ConversationResult{
+		Turns:             make([]TurnResult, 0, len(turns)),
+		Speakers:          make(map[string]*SpeakerResult),
+		AnomalousSpeakers: make([]string, 0),
+	}
+
+	overall := &turnAggregate{}
+	perSpeaker := make(map[string]*turnAggregate)
+
+	for i, turn := range turns {
+		if strings.TrimSpace(turn.Text) == "" {
+			continue
+		}
+
+		result, err := ad.analyze(recordingBaselines(context.Background()), turn.Text, ContentTypeAuto, Selection{})
+		if err != nil {
+			return nil, fmt.Errorf("analysis of turn %d (%s) failed: %w", i, turn.Role, err)
+		}
+		conversation.Turns = append(conversation.Turns, TurnResult{Index: i, Role: turn.Role, Result: result})
+
+		speaker, ok := perSpeaker[turn.Role]
+		if !ok {
+			speaker = &turnAggregate{}
+			perSpeaker[turn.Role] = speaker
+		}
+		weight := float64(len([]rune(turn.Text)))
+		speaker.add(result, weight)
+		overall.add(result, weight)
+	}
+
+	if len(conversation.Turns) == 0 {
+		return nil, fmt.Errorf("conversation has no non-empty turns")
+	}
+
+	// Turns are dialogue, so speakers and the conversation are judged as prose
+	threshold := ad.decisionThresholds().For(ContentTypeProse)
+	for role, aggregate := range perSpeaker {
+		score, confidence := aggregate.scores()
+		speaker := &SpeakerResult{
+			Role:           role,
+			Turns:          aggregate.turns,
+			AnomalousTurns: aggregate.anomalous,
+			Score:          score,
+			Confidence:     confidence,
+			IsAnomalous:    score > threshold,
+			Severity:       ad.severityBands().Classify(score, confidence),
+		}
+		conversation.Speakers[role] = speaker
+		if speaker.IsAnomalous {
+			conversation.AnomalousSpeakers = append(conversation.AnomalousSpeakers, role)
+		}
+	}
+	sort.Strings(conversation.AnomalousSpeakers)
+
+	score, confidence := overall.scores()
+	conversation.Aggregate = &models.AnomalyResult{
+		Score:       score,
+		Confidence:  confidence,
+		IsAnomalous: score > threshold,
+		Severity:    ad.severityBands().Classify(score, confidence),
+		Outcome:     models.OutcomeAnalyzed,
+		Details:     make(map[string]*models.AnalysisResult),
+		Metadata: map[string]interface{}{
+			"content_type":       "conversation",
+			"threshold":          threshold,
+			"turns":              len(conversation.Turns),
+			"speakers":           len(conversation.Speakers),
+			"anomalous_speakers": conversation.AnomalousSpeakers,
+		},
+		Timestamp: time.Now(),
+	}
+
+	conversation.Aggregate = ad.finishResult(conversationText(turns), conversation.Aggregate, start)
+	return conversation, nil
+}
+
+// conversationText joins the text of the turns into one, a paragraph each
+func conversationText(turns []Turn) string {
+	texts := make([]string, 0, len(turns))
+	for _, turn := range turns {
+		if strings.TrimSpace(turn.Text) != "" {
+			texts = append(texts, turn.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// turnAggregate accumulates turn scores weighted by turn length and
+// confidence, so that long, confidently scored turns dominate and one-word
+// replies barely count
+type turnAggregate struct {
+	turns       int
+	anomalous   int
+	totalScore  float64
+	totalWeight float64
+	totalConf   float64
+	totalLength float64
+}
+
+func (t *turnAggregate) add(result *models.AnomalyResult, length float64) {
+	t.turns++
+	if result.IsAnomalous {
+		t.anomalous++
+	}
+	weight := length * result.Confidence
+	t.totalScore += result.Score * weight
+	t.totalWeight += weight
+	t.totalConf += result.Confidence * length
+	t.totalLength += length
+}
+
+func (t *turnAggregate) scores() (score, confidence float64) {
+	if t.totalWeight > 0 {
+		score = t.totalScore / t.totalWeight
+	}
+	if t.totalLength > 0 {
+		confidence = t.totalConf / t.totalLength
+	}
+	return score, confidence
+}
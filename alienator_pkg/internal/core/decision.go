@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DecisionThresholds holds, per content type, the aggregate score above
+// which a result is flagged as anomalous. Scores mean different things for
+// different content: code and data naturally score higher on the entropy
+// and compression analyzers they lean on, so a cutoff tuned on prose flags
+// too much of them. Content types without an entry use AnomalyThreshold.
+type DecisionThresholds map[ContentType]float64
+
+// DefaultDecisionThresholds returns AnomalyThreshold for every content type
+func DefaultDecisionThresholds() DecisionThresholds {
+	return DecisionThresholds{
+		ContentTypeProse: AnomalyThreshold,
+		ContentTypeCode:  AnomalyThreshold,
+		ContentTypeData:  AnomalyThreshold,
+		ContentTypeLog:   AnomalyThreshold,
+	}
+}
+
+// Validate checks that every threshold lies strictly between 0 and 1 and
+// belongs to a known content type
+func (t DecisionThresholds) Validate() error {
+	for contentType, threshold := range t {
+		if _, ok := analyzerProfiles[contentType]; !ok {
+			return fmt.Errorf("decision threshold for unsupported content type %q", contentType)
+		}
+		if threshold <= 0 || threshold >= 1 {
+			return fmt.Errorf("decision threshold for %s must be between 0 and 1, got %f", contentType, threshold)
+		}
+	}
+	return nil
+}
+
+// For returns the threshold of a content type
+func (t DecisionThresholds) For(contentType ContentType) float64 {
+	if threshold, ok := t[contentType]; ok {
+		return threshold
+	}
+	return AnomalyThreshold
+}
+
+// String lists the thresholds sorted by content type, e.g. "code:0.8,prose:0.7"
+func (t DecisionThresholds) String() string {
+	entries := make([]string, 0, len(t))
+	for contentType, threshold := range t {
+		entries = append(entries, fmt.Sprintf("%s:%g", contentType, threshold))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// SetDecisionThresholds replaces the per content type decision thresholds
+func (ad *AnomalyDetector) SetDecisionThresholds(thresholds DecisionThresholds) error {
+	if err := thresholds.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	ad.thresholds = thresholds
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// DecisionThresholds returns a copy of the current decision thresholds
+func (ad *AnomalyDetector) DecisionThresholds() DecisionThresholds {
+	current := ad.decisionThresholds()
+	thresholds := make(DecisionThresholds, len(current))
+	for contentType, threshold := range current {
+		thresholds[contentType] = threshold
+	}
+	return thresholds
+}
+
+// decisionThresholds returns the current decision thresholds
+func (ad *AnomalyDetector) decisionThresholds() DecisionThresholds {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.thresholds
+}
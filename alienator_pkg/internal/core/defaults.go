@@ -0,0 +1,100 @@
+package core
+
+import (
+	"fmt"
+)
+
+// AnalysisDefaults shape every analysis the caller doesn't direct otherwise,
+// such as under a preset. A ContentType other than auto is used in place of
+// classifying texts whose content type is left to the detector. Analyzers,
+// when set, are the only registered analyzers run, unless a selection weights
+// others in. Weights are laid over the weights of every content profile,
+// beneath a selection's.
+type AnalysisDefaults struct {
+	ContentType ContentType
+	Analyzers   []string
+	Weights     map[string]float64
+}
+
+// Validate checks the content type and that the names are not empty and the
+// weights not negative
+func (d AnalysisDefaults) Validate() error {
+	if _, err := ParseContentType(string(d.ContentType)); err != nil {
+		return err
+	}
+	for _, name := range d.Analyzers {
+		if name == "" {
+			return fmt.Errorf("analyzers must not contain empty names")
+		}
+	}
+	for name, weight := range d.Weights {
+		if weight < 0 {
+			return fmt.Errorf("weight for analyzer %q must not be negative, got %g", name, weight)
+		}
+	}
+	return nil
+}
+
+// SetAnalysisDefaults replaces the analysis defaults
+func (ad *AnomalyDetector) SetAnalysisDefaults(defaults AnalysisDefaults) error {
+	if err := defaults.Validate(); err != nil {
+		return err
+	}
+	defaults.ContentType, _ = ParseContentType(string(defaults.ContentType))
+	defaults.Analyzers = append([]string(nil), defaults.Analyzers...)
+	defaults.Weights = copyPerAnalyzer(defaults.Weights)
+	ad.settingsMu.Lock()
+	ad.defaults = defaults
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// analysisDefaults returns the current analysis defaults
+func (ad *AnomalyDetector) analysisDefaults() AnalysisDefaults {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.defaults
+}
+
+// defaultContentType returns the content type to analyze text as when the
+// caller leaves it to the detector
+func (ad *AnomalyDetector) defaultContentType(contentType ContentType) ContentType {
+	if contentType != ContentTypeAuto {
+		return contentType
+	}
+	if defaults := ad.analysisDefaults(); defaults.ContentType != "" {
+		return defaults.ContentType
+	}
+	return ContentTypeAuto
+}
+
+// profileFor returns the analyzer profile for a content type with the
+// default weights laid over it, weighting the analyzers the defaults leave
+// out at 0
+func (ad *AnomalyDetector) profileFor(contentType ContentType) AnalyzerProfile {
+	profile := ProfileFor(contentType)
+	defaults := ad.analysisDefaults()
+	if len(defaults.Analyzers) == 0 && len(defaults.Weights) == 0 {
+		return profile
+	}
+
+	weights := make(map[string]float64, len(profile.Weights)+len(defaults.Weights))
+	for name, weight := range profile.Weights {
+		weights[name] = weight
+	}
+	for name, weight := range defaults.Weights {
+		weights[name] = weight
+	}
+	if len(defaults.Analyzers) > 0 {
+		enabled := make(map[string]bool, len(defaults.Analyzers))
+		for _, name := range defaults.Analyzers {
+			enabled[name] = true
+		}
+		for _, analyzer := range ad.analyzers {
+			if !enabled[analyzer.Name()] {
+				weights[analyzer.Name()] = 0
+			}
+		}
+	}
+	return AnalyzerProfile{Name: profile.Name, Weights: weights}
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"sort"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Describer is implemented by analyzers that say what they measure and which
+// options their Configure method accepts
+type Describer interface {
+	Description() string
+	Parameters() []models.AnalyzerParameter
+}
+
+// readinessReporter is implemented by analyzers that know whether they have
+// been initialized
+type readinessReporter interface {
+	IsReady() bool
+}
+
+// DescribeAnalyzers lists the registered analyzers, sorted by name, with
+// their content profile weights, readiness and options. An analyzer that
+// reports no readiness of its own is ready once detector warm-up has run if
+// it needs warming, and always otherwise. Cached analyzers include their
+// cache counters, and every analyzer its circuit breaker state unless the
+// breakers are disabled.
+func (ad *AnomalyDetector) DescribeAnalyzers() []models.AnalyzerInfo {
+	cacheStats := ad.FeatureCacheStats()
+	infos := make([]models.AnalyzerInfo, 0, len(ad.analyzers))
+	for _, analyzer := range ad.analyzers {
+		info := models.AnalyzerInfo{
+			Name:       analyzer.Name(),
+			Ready:      true,
+			Weights:    make(map[string]float64, len(analyzerProfiles)),
+			Parameters: make([]models.AnalyzerParameter, 0),
+		}
+		for contentType, profile := range analyzerProfiles {
+			weight := profile.Weight(info.Name)
+			info.Weights[string(contentType)] = weight
+			if weight > 0 {
+				info.Enabled = true
+			}
+		}
+
+		if describer, ok := analyzer.(Describer); ok {
+			info.Description = describer.Description()
+			if parameters := describer.Parameters(); parameters != nil {
+				info.Parameters = parameters
+			}
+		}
+		switch a := analyzer.(type) {
+		case readinessReporter:
+			info.Ready = a.IsReady()
+		case Warmer:
+			info.Ready = ad.Ready()
+		}
+		if stats, ok := cacheStats[info.Name]; ok {
+			info.Cache = &stats
+		}
+		info.Circuit = ad.circuitState(info.Name)
+
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
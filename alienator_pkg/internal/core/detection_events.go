@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/models/proto"
+	"go.uber.org/zap"
+)
+
+// Topics of the typed events the detector publishes on its event bus
+const (
+	TopicAnalysisCompleted = "analysis.completed"
+	TopicAnomalyDetected   = "anomaly.detected"
+)
+
+// detectorEventBuffer is the number of events queued per subscriber of the
+// detector's bus before new events are dropped for it
+const detectorEventBuffer = 64
+
+// Event is a typed detection event delivered to SubscribeEvents handlers.
+// Switch on the concrete type to get at its fields.
+type Event interface {
+	Topic() string
+}
+
+// AnalysisCompletedEvent is published after every successful analysis
+type AnalysisCompletedEvent struct {
+	Result    *models.AnomalyResult
+	Duration  time.Duration
+	Timestamp time.Time
+}
+
+// Topic implements Event
+func (AnalysisCompletedEvent) Topic() string { return TopicAnalysisCompleted }
+
+// AnomalyDetectedEvent is published when an analysis flags the text as anomalous
+type AnomalyDetectedEvent struct {
+	Result    *models.AnomalyResult
+	Timestamp time.Time
+}
+
+// Topic implements Event
+func (AnomalyDetectedEvent) Topic() string { return TopicAnomalyDetected }
+
+// typedEventKey is the key of the typed event in the data of the bus event
+// carrying it
+const typedEventKey = "event"
+
+// detectorEventBusConfig configures the bus the detector publishes on: no
+// history is kept, and each subscriber queues up to detectorEventBuffer
+// events
+func detectorEventBusConfig() *EventBusConfig {
+	config := DefaultEventBusConfig()
+	config.EnableHistory = false
+	config.BufferSize = detectorEventBuffer
+	return config
+}
+
+// SubscribeEvents registers handler for the typed events published on topic
+// with PublishEvent, for library consumers who want to react to detections
+// without running a message broker. Like every subscription, it has its own
+// goroutine and queue, so a slow handler only delays (and, once its queue is
+// full, drops) its own events; a panicking handler is logged. The returned
+// function removes the subscription and is safe to call more than once.
+func (eb *InMemoryEventBus) SubscribeEvents(topic string, handler func(Event)) (unsubscribe func(), err error) {
+	id, err := eb.Subscribe(topic, func(ctx context.Context, event *proto.Event) error {
+		typed, ok := event.Data[typedEventKey].(Event)
+		if !ok {
+			return nil
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				eb.logger.Error("Event handler panicked",
+					zap.String("topic", topic),
+					zap.Any("panic", r))
+			}
+		}()
+		handler(typed)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { eb.Unsubscribe(id) })
+	}, nil
+}
+
+// PublishEvent queues a typed event for every subscriber of topic without
+// waiting for handlers to run
+func (eb *InMemoryEventBus) PublishEvent(topic string, event Event) {
+	eb.Publish(context.Background(), &proto.Event{
+		Id:        fmt.Sprintf("evt_%d", time.Now().UnixNano()),
+		Type:      topic,
+		Timestamp: time.Now().Unix(),
+		Data:      map[string]interface{}{typedEventKey: event},
+	})
+}
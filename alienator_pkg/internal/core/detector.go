@@ -2,19 +2,62 @@ package core
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
 
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/language"
 	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
 	"github.com/ruvnet/alienator/pkg/metrics"
 	"go.uber.org/zap"
 )
 
+// AnomalyThreshold is the aggregate score above which text is flagged as anomalous
+const AnomalyThreshold = 0.7
+
 // AnomalyDetector is the main detector that orchestrates all analyzers
 type AnomalyDetector struct {
-	analyzers []Analyzer
-	logger    *zap.Logger
-	metrics   *metrics.Metrics
+	analyzers   []Analyzer
+	logger      *zap.Logger
+	metrics     *metrics.Metrics
+	ready       int32
+	unhealthy   int32        // set while a required analyzer failed its last health check
+	settingsMu  sync.RWMutex // guards severity, thresholds, confidence, normalization, combination, code blocks, boilerplate, execution, breaker, health, hooks, fallbacks, defaults, tuning costs, language, tokenizer and caches, which may be replaced at runtime
+	severity    SeverityBands
+	thresholds  DecisionThresholds
+	confidence  ConfidencePolicy
+	normalizer  NormalizationPolicy
+	combination CombinationPolicy
+	codeBlocks  CodeBlockPolicy
+	boilerplate BoilerplatePolicy
+	baselinesMu sync.Mutex // guards baselines, the running per-analyzer score baselines
+	baselines   map[string]*scoreBaseline
+	execution   ExecutionPlan
+	breaker     BreakerPolicy
+	health      HealthPolicy
+	hooks       []ResultHook
+	fallbacks   FallbackChains
+	defaults    AnalysisDefaults
+	tuningCosts TuningCosts
+	language    LanguagePolicy
+	breakersMu  sync.Mutex // guards breakers, the per-analyzer circuit breakers
+	breakers    map[string]*circuitBreaker
+	events      *InMemoryEventBus
+	tokenizer   tokenizer.Tokenizer
+	explanation ExplanationConfig
+	caches      map[string]*resultCache // result caches by analyzer name, see SetFeatureCache
+
+	boilerplatePatterns []*regexp.Regexp // the compiled boilerplate patterns
 }
 
 // Analyzer interface for all anomaly detection algorithms
@@ -23,69 +66,681 @@ type Analyzer interface {
 	Analyze(ctx context.Context, text string) (*models.AnalysisResult, error)
 }
 
+// TokenAnalyzer is implemented by analyzers that work from the detector's
+// shared tokenization rather than splitting the text themselves
+type TokenAnalyzer interface {
+	AnalyzeTokens(ctx context.Context, tokens *tokenizer.Tokens) (*models.AnalysisResult, error)
+}
+
+// Warmer is implemented by analyzers with expensive lazy initialization (model
+// loading, training) that should run at startup rather than on first request
+type Warmer interface {
+	Warmup(ctx context.Context) error
+}
+
+// warmupSamples cover each content profile so every analyzer code path is
+// exercised once before real traffic arrives
+var warmupSamples = []string{
+	"The committee reviewed the proposal on Tuesday and asked for a revised budget before the next meeting.",
+	"func add(a, b int) int {\n\treturn a + b\n}\n",
+	`{"id": 42, "name": "sample", "tags": ["a", "b"], "active": true}`,
+	"2024-01-01 12:00:00 INFO server started\n2024-01-01 12:00:01 WARN cache miss\n",
+}
+
 // NewAnomalyDetector creates a new anomaly detector instance
 func NewAnomalyDetector(logger *zap.Logger, metrics *metrics.Metrics) *AnomalyDetector {
+	boilerplate := DefaultBoilerplatePolicy()
+	patterns, _ := boilerplate.compile()
 	return &AnomalyDetector{
-		analyzers: make([]Analyzer, 0),
-		logger:    logger,
-		metrics:   metrics,
+		analyzers:   make([]Analyzer, 0),
+		logger:      logger,
+		metrics:     metrics,
+		severity:    DefaultSeverityBands(),
+		thresholds:  DefaultDecisionThresholds(),
+		confidence:  DefaultConfidencePolicy(),
+		normalizer:  DefaultNormalizationPolicy(),
+		combination: DefaultCombinationPolicy(),
+		codeBlocks:  DefaultCodeBlockPolicy(),
+		boilerplate: boilerplate,
+		baselines:   make(map[string]*scoreBaseline),
+		execution:   DefaultExecutionPlan(),
+		breaker:     DefaultBreakerPolicy(),
+		health:      DefaultHealthPolicy(),
+		breakers:    make(map[string]*circuitBreaker),
+		events:      NewEventBus(detectorEventBusConfig(), logger),
+		tokenizer:   tokenizer.Default,
+		explanation: DefaultExplanationConfig(),
+		tuningCosts: DefaultTuningCosts(),
+		language:    DefaultLanguagePolicy(),
+		hooks:       buildProfileHooks(),
+
+		boilerplatePatterns: patterns,
 	}
 }
 
+// SetTokenizer replaces the tokenizer whose output is shared with analyzers
+// implementing TokenAnalyzer, e.g. for languages that don't delimit words
+// with spaces. Cached results, which may have come from the old
+// tokenization, are dropped.
+func (ad *AnomalyDetector) SetTokenizer(t tokenizer.Tokenizer) {
+	ad.settingsMu.Lock()
+	defer ad.settingsMu.Unlock()
+	ad.tokenizer = t
+	ad.clearFeatureCacheLocked()
+}
+
+// Events returns the bus on which the detector publishes
+// AnalysisCompletedEvent and AnomalyDetectedEvent for in-process subscribers
+// of SubscribeEvents
+func (ad *AnomalyDetector) Events() *InMemoryEventBus {
+	return ad.events
+}
+
+// SetSeverityBands replaces the bands used to classify result severity
+func (ad *AnomalyDetector) SetSeverityBands(bands SeverityBands) error {
+	if err := bands.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	ad.severity = bands
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// ApplyConfig replaces the severity bands, decision thresholds, confidence,
+// normalization, combination, code block, boilerplate, circuit breaker and
+// health check policies, execution plan, fallback chains, analysis defaults,
+// tuning costs and language detection policy from the detector
+// configuration. All are validated first, so on error none is changed; it is
+// safe to call while analyses are running.
+func (ad *AnomalyDetector) ApplyConfig(cfg config.DetectorConfig) error {
+	bands := SeverityBands{
+		Low:           cfg.Severity.Low,
+		Medium:        cfg.Severity.Medium,
+		High:          cfg.Severity.High,
+		Critical:      cfg.Severity.Critical,
+		MinConfidence: cfg.Severity.MinConfidence,
+	}
+	if err := bands.Validate(); err != nil {
+		return fmt.Errorf("invalid severity configuration: %w", err)
+	}
+	thresholds := DecisionThresholds{
+		ContentTypeProse: cfg.Thresholds.Prose,
+		ContentTypeCode:  cfg.Thresholds.Code,
+		ContentTypeData:  cfg.Thresholds.Data,
+		ContentTypeLog:   cfg.Thresholds.Log,
+	}
+	if err := thresholds.Validate(); err != nil {
+		return fmt.Errorf("invalid threshold configuration: %w", err)
+	}
+	policy := ConfidencePolicy{
+		Floor:           cfg.Confidence.Floor,
+		Ceiling:         cfg.Confidence.Ceiling,
+		Aggregation:     cfg.Confidence.Aggregation,
+		MinContributing: cfg.Confidence.MinContributing,
+	}
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid confidence configuration: %w", err)
+	}
+	normalizer := NormalizationPolicy{
+		Strategy:   cfg.Normalization.Strategy,
+		Window:     cfg.Normalization.Window,
+		MinSamples: cfg.Normalization.MinSamples,
+	}
+	if err := normalizer.Validate(); err != nil {
+		return fmt.Errorf("invalid normalization configuration: %w", err)
+	}
+	combination := CombinationPolicy{
+		Mode:           cfg.Combination.Mode,
+		Quorum:         cfg.Combination.Quorum,
+		VoteThresholds: copyPerAnalyzer(cfg.Combination.VoteThresholds),
+	}
+	if err := combination.Validate(); err != nil {
+		return fmt.Errorf("invalid combination configuration: %w", err)
+	}
+	codeBlocks := CodeBlockPolicy{
+		Mode:     cfg.CodeBlocks.Mode,
+		Indented: cfg.CodeBlocks.Indented,
+	}
+	if err := codeBlocks.Validate(); err != nil {
+		return fmt.Errorf("invalid code block configuration: %w", err)
+	}
+	boilerplate := BoilerplatePolicy{
+		Enabled:    cfg.Boilerplate.Enabled,
+		Patterns:   append([]string(nil), cfg.Boilerplate.Patterns...),
+		MinRepeats: cfg.Boilerplate.MinRepeats,
+		MinLength:  cfg.Boilerplate.MinLength,
+	}
+	if err := boilerplate.Validate(); err != nil {
+		return fmt.Errorf("invalid boilerplate configuration: %w", err)
+	}
+	patterns, _ := boilerplate.compile()
+	plan := ExecutionPlan{
+		Order:  append([]string(nil), cfg.Execution.Order...),
+		Budget: cfg.Execution.Budget,
+	}
+	if err := plan.Validate(); err != nil {
+		return fmt.Errorf("invalid execution configuration: %w", err)
+	}
+	breaker := BreakerPolicy{
+		Failures: cfg.CircuitBreaker.Failures,
+		Cooldown: cfg.CircuitBreaker.Cooldown,
+	}
+	if err := breaker.Validate(); err != nil {
+		return fmt.Errorf("invalid circuit breaker configuration: %w", err)
+	}
+	health := HealthPolicy{
+		Required: append([]string(nil), cfg.Health.Required...),
+		Timeout:  cfg.Health.Timeout,
+	}
+	if err := health.Validate(); err != nil {
+		return fmt.Errorf("invalid health check configuration: %w", err)
+	}
+	var fallbacks FallbackChains
+	if len(cfg.Fallbacks) > 0 {
+		fallbacks = make(FallbackChains, len(cfg.Fallbacks))
+		for name, chain := range cfg.Fallbacks {
+			for _, fallback := range chain {
+				fallbacks[name] = append(fallbacks[name], Fallback{Analyzer: fallback.Analyzer, Weight: fallback.Weight})
+			}
+		}
+	}
+	if err := fallbacks.Validate(); err != nil {
+		return fmt.Errorf("invalid fallback configuration: %w", err)
+	}
+	defaults := AnalysisDefaults{
+		ContentType: ContentType(cfg.ContentType),
+		Analyzers:   append([]string(nil), cfg.EnabledAnalyzers...),
+		Weights:     copyPerAnalyzer(cfg.Weights),
+	}
+	if err := defaults.Validate(); err != nil {
+		return fmt.Errorf("invalid analysis defaults: %w", err)
+	}
+	defaults.ContentType, _ = ParseContentType(cfg.ContentType)
+	tuningCosts := TuningCosts{
+		FalsePositive: cfg.Tuning.FalsePositiveCost,
+		FalseNegative: cfg.Tuning.FalseNegativeCost,
+	}
+	if err := tuningCosts.Validate(); err != nil {
+		return fmt.Errorf("invalid tuning configuration: %w", err)
+	}
+
+	ad.settingsMu.Lock()
+	ad.severity = bands
+	ad.thresholds = thresholds
+	ad.confidence = policy
+	ad.setNormalizationLocked(normalizer)
+	ad.combination = combination
+	ad.codeBlocks = codeBlocks
+	ad.boilerplate = boilerplate
+	ad.boilerplatePatterns = patterns
+	ad.execution = plan
+	ad.breaker = breaker
+	ad.health = health
+	ad.fallbacks = fallbacks
+	ad.defaults = defaults
+	ad.tuningCosts = tuningCosts
+	ad.language = LanguagePolicy{Shared: cfg.Language.Shared}
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// severityBands returns the current severity bands
+func (ad *AnomalyDetector) severityBands() SeverityBands {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.severity
+}
+
+// currentTokenizer returns the tokenizer whose output analyzers share
+func (ad *AnomalyDetector) currentTokenizer() tokenizer.Tokenizer {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.tokenizer
+}
+
+// confidencePolicy returns the current confidence policy
+func (ad *AnomalyDetector) confidencePolicy() ConfidencePolicy {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.confidence
+}
+
 // RegisterAnalyzer adds a new analyzer to the detector
 func (ad *AnomalyDetector) RegisterAnalyzer(analyzer Analyzer) {
 	ad.analyzers = append(ad.analyzers, analyzer)
 }
 
-// AnalyzeText performs anomaly detection on the given text
+// Warmup pre-initializes analyzers, checks their health and runs the
+// detector over a small synthetic sample so the first real request doesn't
+// pay for lazy setup. Ready reports true once it completes successfully;
+// unhealthy analyzers don't fail it, but Healthy reports them.
+func (ad *AnomalyDetector) Warmup(ctx context.Context) error {
+	start := time.Now()
+
+	for _, analyzer := range ad.analyzers {
+		warmer, ok := analyzer.(Warmer)
+		if !ok {
+			continue
+		}
+		if err := warmer.Warmup(ctx); err != nil {
+			return fmt.Errorf("warm-up of analyzer %s failed: %w", analyzer.Name(), err)
+		}
+	}
+	ad.CheckHealth(ctx)
+
+	for _, sample := range warmupSamples {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("warm-up interrupted: %w", err)
+		}
+		// Warm-up runs are not real detections, so no events are published
+		if _, err := ad.analyze(ctx, sample, ContentTypeAuto, Selection{}); err != nil {
+			return fmt.Errorf("warm-up analysis failed: %w", err)
+		}
+	}
+
+	atomic.StoreInt32(&ad.ready, 1)
+	ad.logger.Info("Detector warm-up completed",
+		zap.Int("analyzers", len(ad.analyzers)),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+// maxWarmupBackoff caps the delay between warm-up attempts
+const maxWarmupBackoff = time.Minute
+
+// WarmupWithRetry runs Warmup until it succeeds or ctx is done, giving each
+// attempt up to timeout. A failed attempt is logged and retried after a
+// delay doubling from backoff up to a minute, so an analyzer that can't
+// initialize at startup doesn't leave the detector unready for good.
+func (ad *AnomalyDetector) WarmupWithRetry(ctx context.Context, timeout, backoff time.Duration) error {
+	delay := backoff
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := ad.Warmup(attemptCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		ad.logger.Warn("Detector warm-up failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		delay = min(delay*2, maxWarmupBackoff)
+	}
+}
+
+// Ready reports whether warm-up has completed
+func (ad *AnomalyDetector) Ready() bool {
+	return atomic.LoadInt32(&ad.ready) == 1
+}
+
+// Fingerprint returns a short, stable hash of the detector configuration (the
+// registered analyzers and the decision thresholds) so that two deployments
+// can be compared for identical behaviour
+func (ad *AnomalyDetector) Fingerprint() string {
+	names := make([]string, 0, len(ad.analyzers))
+	for _, analyzer := range ad.analyzers {
+		names = append(names, analyzer.Name())
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "analyzers=%s;thresholds=%s", strings.Join(names, ","), ad.decisionThresholds())
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// AnalyzeText performs anomaly detection on the given text, classifying its
+// content type automatically
 func (ad *AnomalyDetector) AnalyzeText(text string) (*models.AnomalyResult, error) {
-	ctx := context.Background()
-	
-	// Run all analyzers in parallel
-	results := make(map[string]*models.AnalysisResult)
+	return ad.AnalyzeTextAs(text, ContentTypeAuto)
+}
+
+// AnalyzeTextAs performs anomaly detection using the analyzer profile for the
+// given content type. ContentTypeAuto classifies the text first.
+func (ad *AnomalyDetector) AnalyzeTextAs(text string, contentType ContentType) (*models.AnomalyResult, error) {
+	start := time.Now()
+	result, err := ad.analyze(recordingBaselines(context.Background()), text, contentType, Selection{})
+	if err != nil {
+		return nil, err
+	}
+	return ad.finishResult(text, result, start), nil
+}
+
+// analyze strips boilerplate from text and runs the selected analyzers
+// without publishing events
+func (ad *AnomalyDetector) analyze(ctx context.Context, text string, contentType ContentType, selection Selection) (*models.AnomalyResult, error) {
+	return ad.stripAndAnalyze(ctx, text, contentType, selection, nil)
+}
+
+// analyzeText runs the selected analyzers over text as it is
+func (ad *AnomalyDetector) analyzeText(ctx context.Context, text string, contentType ContentType, selection Selection) (*models.AnomalyResult, error) {
+	if IsEffectivelyEmpty(text) {
+		return ad.emptyInputResult(contentType), nil
+	}
+	contentType = ad.defaultContentType(contentType)
+
+	// Code blocks within prose are analyzed apart from it, or dropped
+	if contentType == ContentTypeAuto || contentType == ContentTypeProse {
+		if policy := ad.codeBlockPolicy(); policy.Mode != CodeBlocksOff {
+			parts, split := splitCodeBlocks(text, policy.Indented)
+			if !IsEffectivelyEmpty(parts.prose) && !IsEffectivelyEmpty(parts.code) {
+				split.Mode = policy.Mode
+				return ad.analyzeMixed(ctx, parts, split, contentType == ContentTypeAuto, selection)
+			}
+		}
+	}
+
+	detected := contentType == ContentTypeAuto
+	contentConfidence := 1.0
+	if detected {
+		contentType, contentConfidence = ClassifyContent(text)
+	}
+	profile := selection.apply(ad.profileFor(contentType))
+	run, err := ad.runAnalyzers(ctx, text, profile, selection)
+	if err != nil {
+		return nil, err
+	}
+	results := run.results
+	ad.collectSpans(ctx, text, results)
+
+	// Aggregate results on a common scale, flagging them against the content
+	// type's threshold
+	strategy, normalized := ad.normalizeScores(ctx, run.measured())
+	run.substituteNormalized(normalized)
+	threshold := ad.decisionThresholds().For(contentType)
+	confidence := ad.confidencePolicy()
+	contributing, excluded := confidence.contributing(results)
+	result := ad.aggregateWeightedResults(contributing, normalized, profile, threshold)
+	result.Details = results
+	setLanguage(result, run.language)
+	result.Metadata = map[string]interface{}{
+		"content_type":          string(contentType),
+		"content_type_detected": detected,
+		"profile":               profile.Name,
+		"threshold":             threshold,
+	}
+	if detected {
+		result.Metadata["content_type_confidence"] = contentConfidence
+	}
+	if strategy != NormalizationNone {
+		result.Metadata["normalization"] = strategy
+		result.Metadata["normalized_scores"] = normalized
+	}
+	if combination := ad.combinationPolicy(); combination.Mode == CombinationVote {
+		votes, share := combination.vote(contributing, normalized, profile, threshold)
+		result.IsAnomalous = share > combination.Quorum
+		result.Metadata["combination"] = combination.Mode
+		result.Metadata["votes"] = votes
+		result.Metadata["vote_share"] = share
+	}
+	if len(excluded) > 0 {
+		// Their results stay in the details, but did not count
+		result.Metadata["excluded_analyzers"] = excluded
+		result.Metadata["min_contributing_confidence"] = confidence.MinContributing
+	}
+	if len(selection.Analyzers) > 0 {
+		result.Metadata["analyzers"] = selection.Analyzers
+	}
+	if run.budget > 0 {
+		result.Metadata["budget_ms"] = run.budget.Milliseconds()
+	}
+	if len(run.skipped) > 0 {
+		result.Metadata["skipped_analyzers"] = run.skipped
+		ad.logger.Debug("Analyzers skipped for lack of time budget",
+			zap.Strings("analyzers", run.skipped),
+			zap.Duration("budget", run.budget))
+	}
+	if len(run.unavailable) > 0 {
+		result.Metadata["unavailable_analyzers"] = run.unavailable
+	}
+	if len(run.unmet) > 0 {
+		result.Metadata["unmet_preconditions"] = run.unmet
+	}
+	if len(run.fallbacks) > 0 {
+		result.Metadata["fallbacks"] = run.fallbacks
+	}
+	return result, nil
+}
+
+// analyzerRun holds the results of the analyzers run over a text, before
+// they are combined into a score
+type analyzerRun struct {
+	results     map[string]*models.AnalysisResult
+	skipped     []string          // left out for lack of time
+	unavailable []string          // left out with their circuits open, or failing their probe
+	budget      time.Duration     // the time budget that applied, if any
+	unmet       map[string]string // left out with their preconditions not met, and why
+	fallbacks   map[string]string // the fallbacks standing in, by the analyzer they stand in for
+	runs        []metrics.AnalyzerRun
+	language    *language.Detection // the shared language detection, nil when not shared
+	ctx         context.Context     // the context analyzers run in, carrying the language detection
+
+	fallbackOnly map[string]*models.AnalysisResult // fallbacks run only to stand in, nil if they failed
+}
+
+// observe notes how an analyzer's run ended, for the per-analyzer metrics
+func (run *analyzerRun) observe(name string, err error) {
+	observed := metrics.AnalyzerRun{Analyzer: name, Status: metrics.AnalyzerStatusSuccess}
+	switch {
+	case errors.Is(err, errAnalyzerTimeout) || errors.Is(err, context.DeadlineExceeded):
+		observed.Status = metrics.AnalyzerStatusTimeout
+	case err != nil:
+		observed.Status = metrics.AnalyzerStatusError
+	}
+	run.runs = append(run.runs, observed)
+}
+
+// runAnalyzers runs the selected analyzers weighted in profile over text,
+// leaving out those whose circuits are open. It fails when every analyzer
+// it would run is left out, and with ctx's error when ctx is done before
+// they finish.
+func (ad *AnomalyDetector) runAnalyzers(ctx context.Context, text string, profile AnalyzerProfile, selection Selection) (*analyzerRun, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Tokenize once; analyzers share the result
+	tokens := tokenizer.New(ad.currentTokenizer(), text)
+
+	run := &analyzerRun{language: ad.sharedLanguage(tokens)}
+	run.ctx = languageContext(ctx, run.language)
+	runnable := make([]Analyzer, 0, len(ad.analyzers))
+	for _, analyzer := range ad.analyzers {
+		if !selection.includes(analyzer.Name()) || profile.Weight(analyzer.Name()) <= 0 {
+			continue
+		}
+		if err := checkPrecondition(analyzer, tokens); err != nil {
+			if run.unmet == nil {
+				run.unmet = make(map[string]string)
+			}
+			run.unmet[analyzer.Name()] = err.Error()
+			continue
+		}
+		if !ad.admit(analyzer.Name()) {
+			run.unavailable = append(run.unavailable, analyzer.Name())
+			continue
+		}
+		runnable = append(runnable, analyzer)
+	}
+	if len(runnable) == 0 && len(run.unavailable) > 0 {
+		return nil, fmt.Errorf("every analyzer to run has its circuit open: %s", strings.Join(run.unavailable, ", "))
+	}
+
+	plan := ad.executionPlan()
+	if selection.Budget > 0 {
+		plan.Budget = selection.Budget
+	}
+	var err error
+	if plan.Budget > 0 {
+		run.budget = plan.Budget
+		err = ad.runWithinBudget(run, text, tokens, plan.sequence(runnable))
+	} else {
+		err = ad.runAll(run, text, tokens, runnable)
+	}
+	if err == nil {
+		sort.Strings(run.unavailable)
+		ad.applyFallbacks(run, text, tokens)
+	}
+	if ad.metrics != nil {
+		ad.metrics.RecordAnalyzerRuns(run.runs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// runAll runs the analyzers in parallel and waits for all of them, failing
+// with the run context's error if the caller gave up on them meanwhile
+func (ad *AnomalyDetector) runAll(run *analyzerRun, text string, tokens *tokenizer.Tokens, analyzers []Analyzer) error {
+	ctx := run.ctx
+
+	run.results = make(map[string]*models.AnalysisResult)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
-	errChan := make(chan error, len(ad.analyzers))
+	errChan := make(chan error, len(analyzers))
 
-	for _, analyzer := range ad.analyzers {
+	for _, analyzer := range analyzers {
 		wg.Add(1)
 		go func(a Analyzer) {
 			defer wg.Done()
-			
-			result, err := a.Analyze(ctx, text)
-			if err != nil {
-				ad.logger.Error("Analyzer failed", 
-					zap.String("analyzer", a.Name()),
-					zap.Error(err))
-				errChan <- fmt.Errorf("analyzer %s failed: %w", a.Name(), err)
+
+			result, err := ad.runAnalyzer(ctx, a, text, tokens)
+			if err != nil && ctx.Err() != nil {
+				// The caller gave up, which says nothing about the analyzer
+				ad.releaseProbe(a.Name())
 				return
 			}
-
+			tolerated := ad.recordOutcome(a.Name(), err)
 			mu.Lock()
-			results[a.Name()] = result
-			mu.Unlock()
+			defer mu.Unlock()
+			run.observe(a.Name(), err)
+			switch {
+			case err != nil && tolerated:
+				run.unavailable = append(run.unavailable, a.Name())
+			case err != nil:
+				errChan <- ad.analyzerFailed(a, err)
+			default:
+				run.results[a.Name()] = result
+			}
 		}(analyzer)
 	}
 
 	wg.Wait()
 	close(errChan)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// Check for errors
 	if len(errChan) > 0 {
-		return nil, <-errChan
+		return <-errChan
+	}
+	return nil
+}
+
+// IsEffectivelyEmpty reports whether text has nothing to analyze: no letters
+// or digits, only whitespace, punctuation or symbols
+func IsEffectivelyEmpty(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// emptyInputResult is the result for effectively empty text. It is a normal
+// result rather than an error so that callers handle degenerate input uniformly.
+func (ad *AnomalyDetector) emptyInputResult(contentType ContentType) *models.AnomalyResult {
+	if contentType == ContentTypeAuto {
+		contentType = ContentTypeProse
+	}
+	return &models.AnomalyResult{
+		Score:       0.0,
+		Confidence:  0.0,
+		IsAnomalous: false,
+		Severity:    models.SeverityNone,
+		Outcome:     models.OutcomeEmptyInput,
+		Details:     make(map[string]*models.AnalysisResult),
+		Metadata: map[string]interface{}{
+			"content_type": string(contentType),
+			"empty_input":  true,
+		},
+	}
+}
+
+// AnalyzeTextSampled analyzes a sample of a long text rather than all of it,
+// see SampleText. The sample's score is used as the estimate for the whole
+// text, and confidence is reduced in proportion to the share left unanalyzed.
+// Texts too short to sample are analyzed in full.
+func (ad *AnomalyDetector) AnalyzeTextSampled(text string, contentType ContentType, cfg SamplingConfig) (*models.AnomalyResult, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Aggregate results
-	return ad.aggregateResults(results), nil
+	start := time.Now()
+	sample, info := SampleText(text, cfg)
+	result, err := ad.analyze(recordingBaselines(context.Background()), sample, contentType, Selection{})
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Strategy != SamplingStrategyFull {
+		result.Confidence *= 0.75 + 0.25*info.Coverage
+		result.Severity = ad.severityBands().Classify(result.Score, result.Confidence)
+	}
+	result.Metadata["sampling"] = info
+	return ad.finishResult(text, result, start), nil
+}
+
+// publishResult notifies event subscribers of a completed analysis
+func (ad *AnomalyDetector) publishResult(result *models.AnomalyResult, duration time.Duration) {
+	now := time.Now()
+	ad.events.PublishEvent(TopicAnalysisCompleted, AnalysisCompletedEvent{
+		Result:    result,
+		Duration:  duration,
+		Timestamp: now,
+	})
+	if result.IsAnomalous {
+		ad.events.PublishEvent(TopicAnomalyDetected, AnomalyDetectedEvent{
+			Result:    result,
+			Timestamp: now,
+		})
+	}
 }
 
 // aggregateResults combines individual analyzer results into a final score
 func (ad *AnomalyDetector) aggregateResults(results map[string]*models.AnalysisResult) *models.AnomalyResult {
+	return ad.aggregateWeightedResults(results, nil, AnalyzerProfile{}, AnomalyThreshold)
+}
+
+// aggregateWeightedResults combines analyzer results, scaling each analyzer's
+// confidence weight by the content profile, and flags scores above threshold.
+// Analyzers with a normalized score are weighted by it instead of their raw
+// score.
+func (ad *AnomalyDetector) aggregateWeightedResults(results map[string]*models.AnalysisResult, normalized map[string]float64, profile AnalyzerProfile, threshold float64) *models.AnomalyResult {
 	if len(results) == 0 {
 		return &models.AnomalyResult{
 			Score:       0.0,
 			Confidence:  0.0,
 			IsAnomalous: false,
+			Severity:    models.SeverityNone,
+			Outcome:     models.OutcomeAnalyzed,
 			Details:     make(map[string]*models.AnalysisResult),
 		}
 	}
@@ -93,20 +748,31 @@ func (ad *AnomalyDetector) aggregateResults(results map[string]*models.AnalysisR
 	// Simple weighted average for now
 	totalScore := 0.0
 	totalWeight := 0.0
+	policy := ad.confidencePolicy()
 	
-	for _, result := range results {
-		weight := result.Confidence
-		totalScore += result.Score * weight
+	for name, result := range results {
+		result.Confidence = policy.Clamp(result.Confidence)
+		weight := result.Confidence * profile.Weight(name)
+		score := result.Score
+		if value, ok := normalized[name]; ok {
+			score = value
+		}
+		totalScore += score * weight
 		totalWeight += weight
 	}
 
-	finalScore := totalScore / totalWeight
-	finalConfidence := totalWeight / float64(len(results))
+	finalScore := 0.0
+	if totalWeight > 0 {
+		finalScore = totalScore / totalWeight
+	}
+	finalConfidence := policy.Aggregate(results, profile)
 
 	return &models.AnomalyResult{
 		Score:       finalScore,
 		Confidence:  finalConfidence,
-		IsAnomalous: finalScore > 0.7, // Threshold can be configurable
+		IsAnomalous: finalScore > threshold,
+		Severity:    ad.severityBands().Classify(finalScore, finalConfidence),
+		Outcome:     models.OutcomeAnalyzed,
 		Details:     results,
 	}
 }
\ No newline at end of file
@@ -50,6 +50,7 @@ type eventSubscription struct {
 type InMemoryEventBus struct {
 	config        *EventBusConfig
 	subscriptions map[string]*eventSubscription
+	subscribed    uint64 // subscriptions made, numbering their IDs
 	eventTypes    map[string][]*eventSubscription
 	history       map[string][]*proto.Event
 	mu            sync.RWMutex
@@ -82,12 +83,15 @@ func NewEventBus(config *EventBusConfig, logger *zap.Logger) *InMemoryEventBus {
 	return eb
 }
 
-// Publish publishes an event to all subscribers
+// Publish publishes an event to all subscribers. Delivery only queues the
+// event, dropping it for subscribers whose buffer is full, so it is done
+// under the lock, which keeps Unsubscribe from closing a buffer mid-send.
 func (eb *InMemoryEventBus) Publish(ctx context.Context, event *proto.Event) error {
-	eb.mu.RLock()
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
 	subscriptions, exists := eb.eventTypes[event.Type]
 	if !exists {
-		eb.mu.RUnlock()
 		return nil // No subscribers for this event type
 	}
 
@@ -96,13 +100,8 @@ func (eb *InMemoryEventBus) Publish(ctx context.Context, event *proto.Event) err
 		eb.addToHistory(event)
 	}
 
-	// Create a copy of subscriptions to avoid holding the lock
-	subs := make([]*eventSubscription, len(subscriptions))
-	copy(subs, subscriptions)
-	eb.mu.RUnlock()
-
 	// Deliver to subscribers
-	for _, sub := range subs {
+	for _, sub := range subscriptions {
 		if !sub.Active {
 			continue
 		}
@@ -134,7 +133,9 @@ func (eb *InMemoryEventBus) Subscribe(eventType string, handler EventHandler) (s
 		return "", fmt.Errorf("maximum subscriptions limit reached: %d", eb.config.MaxSubscriptions)
 	}
 
-	subID := fmt.Sprintf("sub_%d", time.Now().UnixNano())
+	// Numbered, since subscriptions can be made within the same nanosecond
+	eb.subscribed++
+	subID := fmt.Sprintf("sub_%d", eb.subscribed)
 	
 	sub := &eventSubscription{
 		ID:        subID,
@@ -217,6 +218,9 @@ func (eb *InMemoryEventBus) Close() error {
 		sub.Active = false
 		close(sub.Buffer)
 	}
+	// Closed subscriptions are gone, so unsubscribing later doesn't close them again
+	eb.subscriptions = make(map[string]*eventSubscription)
+	eb.eventTypes = make(map[string][]*eventSubscription)
 	eb.mu.Unlock()
 
 	eb.wg.Wait()
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// SpanReporter is implemented by analyzers whose results locate what they
+// matched in the text
+type SpanReporter interface {
+	Spans(result *models.AnalysisResult) []models.TextSpan
+}
+
+// Evidence analyzes text like AnalyzeText, without publishing events, and
+// returns the per-analyzer results with the spans matched by analyzers that
+// report them, ordered by offset, and the explanation of the verdict. Spans
+// are located in text as given, even when boilerplate was stripped from it
+// or its code blocks analyzed apart.
+func (ad *AnomalyDetector) Evidence(text string) (*models.EvidenceAnalysis, error) {
+	collector := &spanCollector{input: text, spans: make([]models.TextSpan, 0)}
+	ctx := context.WithValue(context.Background(), spanCollectorKey{}, collector)
+	result, err := ad.analyze(ctx, text, ContentTypeAuto, Selection{})
+	if err != nil {
+		return nil, err
+	}
+
+	spans := collector.spans
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Offset < spans[j].Offset })
+
+	return &models.EvidenceAnalysis{
+		Score:       result.Score,
+		Confidence:  result.Confidence,
+		Severity:    result.Severity,
+		Analyzers:   result.Details,
+		Spans:       spans,
+		Explanation: ad.ExplainText(result),
+	}, nil
+}
+
+// spanCollector gathers the spans matched in the texts analyzed for
+// Evidence: the input, or the parts of it left once boilerplate is stripped
+// and code blocks are split off
+type spanCollector struct {
+	input string
+	spans []models.TextSpan
+}
+
+type spanCollectorKey struct{}
+
+type offsetsKey struct{}
+
+// withOffsets returns ctx for analyzing a text derived from the one analyzed
+// in ctx, offsets locating it there. Offsets only matter to Evidence, so
+// without a span collector ctx is returned as it is.
+func withOffsets(ctx context.Context, offsets *offsetMap) context.Context {
+	if offsets == nil || ctx.Value(spanCollectorKey{}) == nil {
+		return ctx
+	}
+	root := offsets
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.parent, _ = ctx.Value(offsetsKey{}).(*offsetMap)
+	return context.WithValue(ctx, offsetsKey{}, offsets)
+}
+
+// collectSpans adds the spans the analyzers matched in text to the span
+// collector in ctx, if there is one, located in the input
+func (ad *AnomalyDetector) collectSpans(ctx context.Context, text string, results map[string]*models.AnalysisResult) {
+	collector, ok := ctx.Value(spanCollectorKey{}).(*spanCollector)
+	if !ok {
+		return
+	}
+	offsets, _ := ctx.Value(offsetsKey{}).(*offsetMap)
+	for _, analyzer := range ad.analyzers {
+		reporter, ok := analyzer.(SpanReporter)
+		if !ok {
+			continue
+		}
+		detail, ok := results[analyzer.Name()]
+		if !ok {
+			continue
+		}
+		for _, span := range reporter.Spans(detail) {
+			if span.Offset >= 0 && span.Length >= 0 && span.Offset+span.Length <= len(text) {
+				span.Offset, span.Length = offsets.span(span.Offset, span.Length)
+				span.Text = collector.input[span.Offset : span.Offset+span.Length]
+			}
+			collector.spans = append(collector.spans, span)
+		}
+	}
+}
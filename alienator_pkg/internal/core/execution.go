@@ -0,0 +1,244 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/hashing"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+	"go.uber.org/zap"
+)
+
+// ExecutionPlan controls the order analyzers run in and how long an analysis
+// may take. Without a budget every analyzer runs at once. With a budget they
+// run one at a time in Order, so the cheap ones listed first always get to
+// run, and an analyzer is only started while budget remains; one still
+// running when the budget runs out is abandoned. Analyzers missing from Order
+// run after the listed ones, in registration order. The first analyzer always
+// runs to completion so that every result has a score.
+type ExecutionPlan struct {
+	Order  []string
+	Budget time.Duration
+}
+
+// DefaultExecutionPlan returns a plan with the cheap statistical analyzers
+// first, the embedding model last and no budget, matching the detector's
+// original behaviour of running everything at once
+func DefaultExecutionPlan() ExecutionPlan {
+	return ExecutionPlan{
+		Order: []string{
+			"linguistic",
+			"cryptographic",
+			"entropy",
+			"compression",
+			"uniformity",
+			"repetition",
+			"sentiment",
+			"claims",
+			"injection",
+			"watermark",
+			"embedding",
+		},
+	}
+}
+
+// Validate checks that the budget is not negative and that no analyzer is
+// listed twice. Names need not be registered, so one plan can serve detectors
+// with different analyzers.
+func (p ExecutionPlan) Validate() error {
+	if p.Budget < 0 {
+		return fmt.Errorf("budget must not be negative, got %s", p.Budget)
+	}
+	seen := make(map[string]bool, len(p.Order))
+	for _, name := range p.Order {
+		if name == "" {
+			return errors.New("analyzer order must not contain empty names")
+		}
+		if seen[name] {
+			return fmt.Errorf("analyzer %q is listed twice in the order", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// sequence returns the analyzers sorted by their position in the order
+func (p ExecutionPlan) sequence(analyzers []Analyzer) []Analyzer {
+	position := make(map[string]int, len(p.Order))
+	for i, name := range p.Order {
+		position[name] = i
+	}
+
+	ordered := make([]Analyzer, 0, len(analyzers))
+	for _, name := range p.Order {
+		for _, analyzer := range analyzers {
+			if analyzer.Name() == name {
+				ordered = append(ordered, analyzer)
+			}
+		}
+	}
+	for _, analyzer := range analyzers {
+		if _, listed := position[analyzer.Name()]; !listed {
+			ordered = append(ordered, analyzer)
+		}
+	}
+	return ordered
+}
+
+// SetExecutionPlan replaces the analyzer order and time budget
+func (ad *AnomalyDetector) SetExecutionPlan(plan ExecutionPlan) error {
+	if err := plan.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	ad.execution = plan
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// executionPlan returns the current execution plan
+func (ad *AnomalyDetector) executionPlan() ExecutionPlan {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.execution
+}
+
+// runAnalyzer runs one analyzer, on the shared tokens if it accepts them.
+// Results of cached analyzers are looked up by content hash first, so texts
+// that only differ in ways the hashing configuration normalizes away share
+// a result.
+func (ad *AnomalyDetector) runAnalyzer(ctx context.Context, a Analyzer, text string, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	cache := ad.resultCacheFor(a.Name())
+	if cache == nil {
+		return analyzeOnce(ctx, a, text, tokens)
+	}
+
+	key := hashing.ContentHash(text)
+	if result, ok := cache.get(key); ok {
+		return result, nil
+	}
+	result, err := analyzeOnce(ctx, a, text, tokens)
+	if err != nil {
+		return nil, err
+	}
+	cache.put(key, result)
+	return result, nil
+}
+
+// analyzeOnce runs one analyzer without the cache
+func analyzeOnce(ctx context.Context, a Analyzer, text string, tokens *tokenizer.Tokens) (*models.AnalysisResult, error) {
+	if ta, ok := a.(TokenAnalyzer); ok {
+		return ta.AnalyzeTokens(ctx, tokens)
+	}
+	return a.Analyze(ctx, text)
+}
+
+// analyzerFailed logs an analyzer error and wraps it with the analyzer name
+func (ad *AnomalyDetector) analyzerFailed(a Analyzer, err error) error {
+	ad.logger.Error("Analyzer failed",
+		zap.String("analyzer", a.Name()),
+		zap.Error(err))
+	return fmt.Errorf("analyzer %s failed: %w", a.Name(), err)
+}
+
+type analyzerOutcome struct {
+	result *models.AnalysisResult
+	err    error
+}
+
+// runWithinBudget runs the analyzers one at a time until the budget is spent,
+// recording the analyzers skipped or abandoned for lack of time. Abandoned
+// analyzers count as failures for their circuit breakers. The run stops
+// with the run context's error once the caller gives up on it.
+func (ad *AnomalyDetector) runWithinBudget(run *analyzerRun, text string, tokens *tokenizer.Tokens, analyzers []Analyzer) error {
+	ctx, cancel := context.WithTimeout(run.ctx, run.budget)
+	defer cancel()
+
+	run.results = make(map[string]*models.AnalysisResult, len(analyzers))
+	for i, a := range analyzers {
+		if err := run.ctx.Err(); err != nil {
+			return ad.cancelWithinBudget(analyzers, i, err)
+		}
+		if i > 0 && ctx.Err() != nil {
+			for _, rest := range analyzers[i:] {
+				run.skipped = append(run.skipped, rest.Name())
+				ad.releaseProbe(rest.Name())
+			}
+			break
+		}
+
+		if i == 0 {
+			result, err := ad.runAnalyzer(run.ctx, a, text, tokens)
+			run.observe(a.Name(), err)
+			if err != nil && run.ctx.Err() != nil {
+				return ad.cancelWithinBudget(analyzers, i, run.ctx.Err())
+			}
+			if ad.recordOutcome(a.Name(), err) {
+				run.unavailable = append(run.unavailable, a.Name())
+				continue
+			}
+			if err != nil {
+				return ad.failWithinBudget(analyzers, i, err)
+			}
+			run.results[a.Name()] = result
+			continue
+		}
+
+		// The outcome channel is buffered so an abandoned analyzer can
+		// still finish and exit
+		done := make(chan analyzerOutcome, 1)
+		go func(a Analyzer) {
+			result, err := ad.runAnalyzer(ctx, a, text, tokens)
+			done <- analyzerOutcome{result: result, err: err}
+		}(a)
+
+		select {
+		case outcome := <-done:
+			run.observe(a.Name(), outcome.err)
+			switch {
+			case outcome.err != nil && run.ctx.Err() != nil:
+				return ad.cancelWithinBudget(analyzers, i, run.ctx.Err())
+			case outcome.err != nil && ctx.Err() != nil && errors.Is(outcome.err, ctx.Err()):
+				ad.recordOutcome(a.Name(), errAnalyzerTimeout)
+				run.skipped = append(run.skipped, a.Name())
+			case outcome.err != nil && ad.recordOutcome(a.Name(), outcome.err):
+				run.unavailable = append(run.unavailable, a.Name())
+			case outcome.err != nil:
+				return ad.failWithinBudget(analyzers, i, outcome.err)
+			default:
+				ad.recordOutcome(a.Name(), nil)
+				run.results[a.Name()] = outcome.result
+			}
+		case <-ctx.Done():
+			if err := run.ctx.Err(); err != nil {
+				return ad.cancelWithinBudget(analyzers, i, err)
+			}
+			run.observe(a.Name(), errAnalyzerTimeout)
+			ad.recordOutcome(a.Name(), errAnalyzerTimeout)
+			run.skipped = append(run.skipped, a.Name())
+		}
+	}
+	return nil
+}
+
+// failWithinBudget fails a budgeted run on the error of analyzers[i],
+// releasing the probes admitted for the analyzers that will not run after it
+func (ad *AnomalyDetector) failWithinBudget(analyzers []Analyzer, i int, err error) error {
+	for _, rest := range analyzers[i+1:] {
+		ad.releaseProbe(rest.Name())
+	}
+	return ad.analyzerFailed(analyzers[i], err)
+}
+
+// cancelWithinBudget stops a budgeted run its caller gave up on at
+// analyzers[i]. That says nothing about the analyzers, so their probes are
+// released rather than failed.
+func (ad *AnomalyDetector) cancelWithinBudget(analyzers []Analyzer, i int, err error) error {
+	for _, rest := range analyzers[i:] {
+		ad.releaseProbe(rest.Name())
+	}
+	return err
+}
@@ -0,0 +1,349 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// ExplanationConfig controls the explanations produced by ExplainText.
+// Phrases maps a finding ID (see explanationRules) to the wording used to
+// describe it, overriding the default. The wording of a finding that carries
+// a value, such as the phrases found or a z-score, is a fmt template taking
+// that value as its single argument; any other wording is used as it is.
+type ExplanationConfig struct {
+	MaxFindings int
+	Phrases     map[string]string
+}
+
+// DefaultExplanationConfig returns a configuration naming up to three findings
+// with the default wording
+func DefaultExplanationConfig() ExplanationConfig {
+	return ExplanationConfig{MaxFindings: 3}
+}
+
+// explanationRule turns one analyzer metadata feature into a finding. match
+// returns the template argument, nil for a phrase without one, and the
+// strength of the finding in [0, 1].
+type explanationRule struct {
+	id       string
+	analyzer string
+	phrase   string
+	match    func(metadata map[string]interface{}) (interface{}, float64, bool)
+}
+
+// explanationRules lists the features worth naming, in priority order for
+// findings of equal strength
+var explanationRules = []explanationRule{
+	{
+		id: "ai_phrases", analyzer: "linguistic", phrase: "presence of the phrase %s",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			phrases, _ := metadata["ai_phrases"].([]string)
+			if len(phrases) == 0 {
+				return nil, 0, false
+			}
+			return quotePhrases(phrases, 2), 0.95, true
+		},
+	},
+	{
+		id: "watermark", analyzer: "watermark", phrase: "a statistical watermark (z = %.1f)",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			detected, _ := metadata["watermark_detected"].(bool)
+			z, _ := metadata["z_score"].(float64)
+			return z, 0.9, detected
+		},
+	},
+	{
+		id: "prompt_injection", analyzer: "injection", phrase: "prompt-injection phrasing (%s)",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			detected, _ := metadata["injection_detected"].(bool)
+			categories, _ := metadata["categories"].([]string)
+			return strings.Join(categories, ", "), 0.9, detected
+		},
+	},
+	{
+		id: "uniform_lengths", analyzer: "uniformity", phrase: "unnaturally uniform sentence or paragraph lengths (p = %.3g)",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			detected, _ := metadata["uniformity_detected"].(bool)
+			p, _ := metadata["p_value"].(float64)
+			return p, 0.7, detected
+		},
+	},
+	{
+		id: "sampling_loop", analyzer: "repetition", phrase: "a degenerate loop repeating %s",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			detected, _ := metadata["loop_detected"].(bool)
+			loop, _ := metadata["loop_span"].(string)
+			score, _ := metadata["loop_score"].(float64)
+			return quotePhrases([]string{loop}, 1), 0.6 + 0.3*score, detected
+		},
+	},
+	{
+		id: "low_perplexity", analyzer: "linguistic", phrase: "low perplexity",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			perplexity, ok := metadata["perplexity"].(float64)
+			return nil, 1 - perplexity/0.1, ok && perplexity > 0 && perplexity < 0.1
+		},
+	},
+	{
+		id: "bot_phrases", analyzer: "linguistic", phrase: "scripted assistant phrasing",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			score, _ := metadata["bot_pattern_score"].(float64)
+			return nil, 0.5 + score, score > 0
+		},
+	},
+	{
+		id: "repetition", analyzer: "linguistic", phrase: "repetitive wording",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			score, _ := metadata["repetition_score"].(float64)
+			return nil, score, score > 0.1
+		},
+	},
+	{
+		id: "repeated_phrase", analyzer: "repetition", phrase: "the phrase %s recurring throughout",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			phrase, _ := metadata["repeated_span"].(string)
+			score, _ := metadata["phrase_score"].(float64)
+			return quotePhrases([]string{phrase}, 1), score, score > 0.5
+		},
+	},
+	{
+		id: "narrow_vocabulary", analyzer: "linguistic", phrase: "a narrow vocabulary",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			richness, ok := metadata["vocabulary_richness"].(float64)
+			return nil, 1 - richness/0.4, ok && richness > 0 && richness < 0.4
+		},
+	},
+	{
+		id: "low_entropy", analyzer: "entropy", phrase: "unusually low character entropy (%.2f bits)",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			entropy, ok := metadata["shannon_entropy"].(float64)
+			return entropy, 1 - entropy/3.5, ok && entropy > 0 && entropy < 3.5
+		},
+	},
+	{
+		id: "compressible", analyzer: "compression", phrase: "highly compressible, repetitive structure",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			ratio, ok := metadata["combined_ratio"].(float64)
+			return nil, 1 - ratio/0.3, ok && ratio > 0 && ratio < 0.3
+		},
+	},
+	{
+		id: "encoded_data", analyzer: "cryptographic", phrase: "embedded hashes or encoded data (%d found)",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			hashes, _ := metadata["detected_hashes"].(int)
+			return hashes, 0.6, hashes > 0
+		},
+	},
+	{
+		id: "semantic_outliers", analyzer: "embedding", phrase: "sentences that stand apart from the rest of the text",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			score, _ := metadata["outlier_score"].(float64)
+			return nil, score, score > 0.3
+		},
+	},
+	{
+		id: "balanced_structure", analyzer: "sentiment", phrase: "an evenly balanced pro/con structure (%d contrast markers)",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			detected, _ := metadata["balanced_structure"].(bool)
+			contrasts, _ := metadata["contrast_markers"].(int)
+			score, _ := metadata["balance_score"].(float64)
+			return contrasts, 0.4 * score, detected
+		},
+	},
+	{
+		id: "neutral_tone", analyzer: "sentiment", phrase: "a conspicuously neutral tone (%.0f%% of sentences neutral)",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			detected, _ := metadata["neutral_tone"].(bool)
+			fraction, _ := metadata["neutral_fraction"].(float64)
+			score, _ := metadata["neutrality_score"].(float64)
+			return fraction * 100, 0.4 * score, detected
+		},
+	},
+	{
+		id: "claim_density", analyzer: "claims", phrase: "a dense run of confident, unhedged claims (%.0f%% of sentences)",
+		match: func(metadata map[string]interface{}) (interface{}, float64, bool) {
+			detected, _ := metadata["claim_dense"].(bool)
+			fraction, _ := metadata["confident_fraction"].(float64)
+			density, _ := metadata["claim_density"].(float64)
+			return fraction * 100, 0.4 * density, detected
+		},
+	},
+}
+
+// explanationFinding is a matched rule ready to be rendered
+type explanationFinding struct {
+	order    int
+	text     string
+	strength float64
+}
+
+// SetExplanationConfig replaces the configuration used by ExplainText
+func (ad *AnomalyDetector) SetExplanationConfig(config ExplanationConfig) {
+	ad.explanation = config
+}
+
+// ExplainText describes a detection result in plain language: the strongest
+// findings behind the verdict, the analyzer that contributed most to the
+// score and any caveats. The output depends only on the result and the
+// configuration, so the same result is always explained the same way.
+func (ad *AnomalyDetector) ExplainText(result *models.AnomalyResult) string {
+	if result == nil {
+		return ""
+	}
+	if result.Outcome == models.OutcomeEmptyInput {
+		return "The text contains no words to analyze."
+	}
+
+	sentences := []string{ad.explainVerdict(result)}
+
+	if name, share := ad.topContributor(result); name != "" && result.Score > 0 {
+		sentences = append(sentences, fmt.Sprintf("The %s analysis accounts for %.0f%% of the score.", name, share*100))
+	}
+
+	if info, ok := result.Metadata["sampling"].(SampleInfo); ok && info.Strategy != SamplingStrategyFull {
+		sentences = append(sentences, fmt.Sprintf("Only %.0f%% of the text was analyzed.", info.Coverage*100))
+	}
+	if result.Confidence < ad.severityBands().MinConfidence {
+		sentences = append(sentences, "Confidence is low, so treat this verdict with caution.")
+	}
+
+	return strings.Join(sentences, " ")
+}
+
+// explainVerdict renders the leading sentence naming the strongest findings
+func (ad *AnomalyDetector) explainVerdict(result *models.AnomalyResult) string {
+	findings := ad.explanationFindings(result)
+	plural := len(findings) > 1
+
+	var verdict string
+	switch {
+	case result.IsAnomalous && (result.Severity == models.SeverityHigh || result.Severity == models.SeverityCritical):
+		verdict = agree(plural, "strongly indicates", "strongly indicate") + " machine generation"
+	case result.IsAnomalous:
+		verdict = agree(plural, "indicates", "indicate") + " machine generation"
+	case result.Score > ad.severityBands().Low:
+		verdict = agree(plural, "suggests", "suggest") + " possible machine generation, below the detection threshold"
+	default:
+		if len(findings) == 0 {
+			return "Nothing in the text suggests machine generation."
+		}
+		verdict = agree(plural, "was", "were") + " noted, but the text as a whole reads as human-written"
+	}
+
+	if len(findings) == 0 {
+		return fmt.Sprintf("The combined analyzer score %s.", verdict)
+	}
+	return fmt.Sprintf("%s %s.", capitalize(joinFindings(findings)), verdict)
+}
+
+// explanationFindings matches the rules against the analyzer details and
+// returns the strongest findings
+func (ad *AnomalyDetector) explanationFindings(result *models.AnomalyResult) []string {
+	matched := make([]explanationFinding, 0)
+	for i, rule := range explanationRules {
+		detail, ok := result.Details[rule.analyzer]
+		if !ok || detail == nil {
+			continue
+		}
+		arg, strength, ok := rule.match(detail.Metadata)
+		if !ok {
+			continue
+		}
+
+		phrase := rule.phrase
+		if custom, ok := ad.explanation.Phrases[rule.id]; ok {
+			phrase = custom
+		}
+		if arg != nil {
+			phrase = fmt.Sprintf(phrase, arg)
+		}
+		matched = append(matched, explanationFinding{
+			order:    i,
+			text:     phrase,
+			strength: strength,
+		})
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].strength != matched[j].strength {
+			return matched[i].strength > matched[j].strength
+		}
+		return matched[i].order < matched[j].order
+	})
+
+	limit := ad.explanation.MaxFindings
+	if limit <= 0 || limit > len(matched) {
+		limit = len(matched)
+	}
+	findings := make([]string, limit)
+	for i := range findings {
+		findings[i] = matched[i].text
+	}
+	return findings
+}
+
+// topContributor returns the analyzer with the largest share of the weighted
+// score, using the same weights as the aggregation
+func (ad *AnomalyDetector) topContributor(result *models.AnomalyResult) (string, float64) {
+	contentType, _ := result.Metadata["content_type"].(string)
+	profile := ad.profileFor(ContentType(contentType))
+
+	names := make([]string, 0, len(result.Details))
+	for name := range result.Details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	total := 0.0
+	best, bestContribution := "", 0.0
+	for _, name := range names {
+		detail := result.Details[name]
+		contribution := detail.Score * detail.Confidence * profile.Weight(name)
+		total += contribution
+		if contribution > bestContribution {
+			best, bestContribution = name, contribution
+		}
+	}
+	if total == 0 {
+		return "", 0
+	}
+	return best, bestContribution / total
+}
+
+// quotePhrases quotes up to limit phrases and joins them for a sentence
+func quotePhrases(phrases []string, limit int) string {
+	if len(phrases) > limit {
+		phrases = phrases[:limit]
+	}
+	quoted := make([]string, len(phrases))
+	for i, phrase := range phrases {
+		quoted[i] = fmt.Sprintf("'%s'", phrase)
+	}
+	return strings.Join(quoted, " and ")
+}
+
+// joinFindings joins findings as an English list: "a", "a and b", "a, b and c"
+func joinFindings(findings []string) string {
+	if len(findings) <= 1 {
+		return strings.Join(findings, "")
+	}
+	return strings.Join(findings[:len(findings)-1], ", ") + " and " + findings[len(findings)-1]
+}
+
+// agree picks the verb form matching the number of findings
+func agree(plural bool, singular, pluralForm string) string {
+	if plural {
+		return pluralForm
+	}
+	return singular
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// Precondition is implemented by analyzers that can't analyze every input,
+// e.g. those needing a minimum number of words or sentences. CanAnalyze
+// returns why the analyzer can't analyze tokens, or nil; an analyzer whose
+// precondition is not met is not run.
+type Precondition interface {
+	CanAnalyze(tokens *tokenizer.Tokens) error
+}
+
+// Fallback is an analyzer whose score stands in for another's, counted at
+// Weight times the other's confidence weight
+type Fallback struct {
+	Analyzer string
+	Weight   float64
+}
+
+// FallbackChains lists, by analyzer, the fallbacks tried in order when that
+// analyzer can't run for an input: its precondition is not met, or its
+// circuit is open. The first fallback with a result stands in for it under
+// its name, so the ensemble keeps the dimension rather than averaging over
+// fewer analyzers. Fallbacks are run when needed, even if the content type
+// profile or the selection leaves them out.
+type FallbackChains map[string][]Fallback
+
+// Validate checks that the names are not empty, that no analyzer falls back
+// to itself or lists a fallback twice, and that the weights are in (0, 1]
+func (c FallbackChains) Validate() error {
+	for name, chain := range c {
+		if name == "" {
+			return errors.New("fallback chains must not be keyed by an empty name")
+		}
+		seen := make(map[string]bool, len(chain))
+		for _, fallback := range chain {
+			switch {
+			case fallback.Analyzer == "":
+				return fmt.Errorf("fallback chain of %q must not contain empty names", name)
+			case fallback.Analyzer == name:
+				return fmt.Errorf("analyzer %q must not fall back to itself", name)
+			case seen[fallback.Analyzer]:
+				return fmt.Errorf("fallback chain of %q lists %q twice", name, fallback.Analyzer)
+			case fallback.Weight <= 0 || fallback.Weight > 1:
+				return fmt.Errorf("fallback weight of %q for %q must be in (0, 1], got %v", fallback.Analyzer, name, fallback.Weight)
+			}
+			seen[fallback.Analyzer] = true
+		}
+	}
+	return nil
+}
+
+// SetFallbackChains replaces the fallback chains; nil disables fallbacks
+func (ad *AnomalyDetector) SetFallbackChains(chains FallbackChains) error {
+	if err := chains.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	ad.fallbacks = chains
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// fallbackChains returns the current fallback chains
+func (ad *AnomalyDetector) fallbackChains() FallbackChains {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.fallbacks
+}
+
+// checkPrecondition returns why the analyzer can't analyze tokens, or nil
+func checkPrecondition(analyzer Analyzer, tokens *tokenizer.Tokens) error {
+	if p, ok := analyzer.(Precondition); ok {
+		return p.CanAnalyze(tokens)
+	}
+	return nil
+}
+
+// applyFallbacks stands fallback results in for the analyzers of the run
+// that couldn't run, following their chains. Fallbacks that fail are passed
+// over.
+func (ad *AnomalyDetector) applyFallbacks(run *analyzerRun, text string, tokens *tokenizer.Tokens) {
+	chains := ad.fallbackChains()
+	if len(chains) == 0 {
+		return
+	}
+
+	missing := make([]string, 0, len(run.unmet)+len(run.unavailable))
+	for name := range run.unmet {
+		missing = append(missing, name)
+	}
+	missing = append(missing, run.unavailable...)
+	sort.Strings(missing)
+
+	for _, name := range missing {
+		for _, fallback := range chains[name] {
+			result := ad.fallbackResult(run, fallback.Analyzer, text, tokens)
+			if result == nil {
+				continue
+			}
+			substitute := *result
+			substitute.Confidence = result.Confidence * fallback.Weight
+			substitute.Metadata = map[string]interface{}{
+				"fallback":        fallback.Analyzer,
+				"fallback_weight": fallback.Weight,
+			}
+			run.results[name] = &substitute
+			if run.fallbacks == nil {
+				run.fallbacks = make(map[string]string)
+			}
+			run.fallbacks[name] = fallback.Analyzer
+			break
+		}
+	}
+}
+
+// fallbackResult returns the named analyzer's result over text, running it
+// if it was not part of the run. It returns nil when the analyzer is not
+// registered or can't run either, and under a time budget, which fallbacks
+// must not overrun, when it did not run.
+func (ad *AnomalyDetector) fallbackResult(run *analyzerRun, name, text string, tokens *tokenizer.Tokens) *models.AnalysisResult {
+	if result, ok := run.results[name]; ok && run.fallbacks[name] == "" {
+		return result
+	}
+	if result, ok := run.fallbackOnly[name]; ok {
+		return result
+	}
+	if _, ok := run.unmet[name]; ok || run.budget > 0 {
+		return nil
+	}
+	if i := sort.SearchStrings(run.unavailable, name); i < len(run.unavailable) && run.unavailable[i] == name {
+		return nil
+	}
+
+	var analyzer Analyzer
+	for _, registered := range ad.analyzers {
+		if registered.Name() == name {
+			analyzer = registered
+		}
+	}
+	if analyzer == nil || checkPrecondition(analyzer, tokens) != nil || !ad.admit(name) {
+		return nil
+	}
+	result, err := ad.runAnalyzer(run.ctx, analyzer, text, tokens)
+	ad.recordOutcome(name, err)
+	run.observe(name, err)
+	if run.fallbackOnly == nil {
+		run.fallbackOnly = make(map[string]*models.AnalysisResult)
+	}
+	if err != nil {
+		result = nil
+	}
+	run.fallbackOnly[name] = result
+	return result
+}
+
+// measured returns the results the analyzers produced themselves, including
+// those of fallbacks run only to stand in, for normalization against their
+// own baselines
+func (run *analyzerRun) measured() map[string]*models.AnalysisResult {
+	if len(run.fallbacks) == 0 && len(run.fallbackOnly) == 0 {
+		return run.results
+	}
+	measured := make(map[string]*models.AnalysisResult, len(run.results)+len(run.fallbackOnly))
+	for name, result := range run.results {
+		if run.fallbacks[name] == "" {
+			measured[name] = result
+		}
+	}
+	for name, result := range run.fallbackOnly {
+		if result != nil {
+			measured[name] = result
+		}
+	}
+	return measured
+}
+
+// substituteNormalized gives each stood-in analyzer its fallback's
+// normalized score, and drops those of fallbacks run only to stand in
+func (run *analyzerRun) substituteNormalized(normalized map[string]float64) {
+	if normalized == nil {
+		return
+	}
+	for name, fallback := range run.fallbacks {
+		if score, ok := normalized[fallback]; ok {
+			normalized[name] = score
+		}
+	}
+	for name := range run.fallbackOnly {
+		if _, ran := run.results[name]; !ran {
+			delete(normalized, name)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// HealthChecker is implemented by analyzers that depend on loaded resources
+// (a calibration, a lexicon, a known-hash dataset, a trained model) and can
+// tell whether those are usable. Analyzers that don't implement it are
+// always healthy.
+type HealthChecker interface {
+	ValidateHealth(ctx context.Context) error
+}
+
+// HealthPolicy names the analyzers whose failed health check makes the
+// detector unhealthy; when Required is empty every registered analyzer is
+// required. A required analyzer that is not registered fails too. Timeout
+// bounds a health check run.
+type HealthPolicy struct {
+	Required []string
+	Timeout  time.Duration
+}
+
+// DefaultHealthPolicy returns a policy requiring every analyzer to be
+// healthy and allowing 10 seconds for the checks
+func DefaultHealthPolicy() HealthPolicy {
+	return HealthPolicy{Timeout: 10 * time.Second}
+}
+
+// Validate checks that the timeout is positive and the required analyzers
+// are named once each
+func (p HealthPolicy) Validate() error {
+	if p.Timeout <= 0 {
+		return fmt.Errorf("health check timeout must be positive, got %s", p.Timeout)
+	}
+	seen := make(map[string]bool, len(p.Required))
+	for _, name := range p.Required {
+		if name == "" {
+			return errors.New("required analyzer names must not be empty")
+		}
+		if seen[name] {
+			return fmt.Errorf("required analyzer %q is listed twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// requires reports whether the named analyzer must be healthy
+func (p HealthPolicy) requires(name string) bool {
+	if len(p.Required) == 0 {
+		return true
+	}
+	for _, required := range p.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetHealthPolicy replaces the health check policy, from the next check on
+func (ad *AnomalyDetector) SetHealthPolicy(policy HealthPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	ad.health = policy
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// healthPolicy returns the current health check policy
+func (ad *AnomalyDetector) healthPolicy() HealthPolicy {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.health
+}
+
+// CheckHealth runs the health check of every registered analyzer, sorted by
+// name, and of the required analyzers missing from the registry. Healthy
+// reports the outcome until the next check, so that a required analyzer
+// whose resources failed to load keeps readiness failing.
+func (ad *AnomalyDetector) CheckHealth(ctx context.Context) models.AnalyzerHealthReport {
+	policy := ad.healthPolicy()
+	ctx, cancel := context.WithTimeout(ctx, policy.Timeout)
+	defer cancel()
+
+	report := models.AnalyzerHealthReport{
+		Healthy:   true,
+		CheckedAt: time.Now(),
+		Analyzers: make([]models.AnalyzerHealth, 0, len(ad.analyzers)),
+	}
+	add := func(name string, required bool, err error) {
+		health := models.AnalyzerHealth{Name: name, Required: required, Healthy: err == nil}
+		if err != nil {
+			health.Error = err.Error()
+			if required {
+				report.Healthy = false
+			}
+		}
+		report.Analyzers = append(report.Analyzers, health)
+	}
+	registered := make(map[string]bool, len(ad.analyzers))
+	for _, analyzer := range ad.analyzers {
+		name := analyzer.Name()
+		registered[name] = true
+		add(name, policy.requires(name), checkAnalyzerHealth(ctx, analyzer))
+	}
+	for _, name := range policy.Required {
+		if !registered[name] {
+			add(name, true, errors.New("analyzer is not registered"))
+		}
+	}
+	sort.Slice(report.Analyzers, func(i, j int) bool { return report.Analyzers[i].Name < report.Analyzers[j].Name })
+
+	for _, health := range report.Analyzers {
+		if !health.Healthy {
+			ad.logger.Warn("Analyzer failed its health check",
+				zap.String("analyzer", health.Name),
+				zap.Bool("required", health.Required),
+				zap.String("error", health.Error),
+			)
+		}
+	}
+
+	var unhealthy int32
+	if !report.Healthy {
+		unhealthy = 1
+	}
+	atomic.StoreInt32(&ad.unhealthy, unhealthy)
+	return report
+}
+
+// Healthy reports whether every required analyzer passed the last health
+// check, and is true until one has run
+func (ad *AnomalyDetector) Healthy() bool {
+	return atomic.LoadInt32(&ad.unhealthy) == 0
+}
+
+// checkAnalyzerHealth runs an analyzer's health check, turning a panic into
+// an error
+func checkAnalyzerHealth(ctx context.Context, analyzer Analyzer) (err error) {
+	checker, ok := analyzer.(HealthChecker)
+	if !ok {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("health check panicked: %v", r)
+		}
+	}()
+	return checker.ValidateHealth(ctx)
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"time"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// ResultHook post-processes a finished result before it is returned:
+// redacting it, adding metadata, reclassifying its severity. It is given the
+// text analyzed and returns the result to pass on, either the one it was
+// given, modified, or a replacement; nil passes the given result on.
+type ResultHook func(result *models.AnomalyResult, input string) *models.AnomalyResult
+
+// Use appends hooks to the chain run, in the order added, over every result
+// the detector returns, before event subscribers are notified of it. Only
+// the aggregate of a windowed or conversation analysis goes through the
+// chain, with the whole text as input.
+func (ad *AnomalyDetector) Use(hooks ...ResultHook) {
+	ad.settingsMu.Lock()
+	defer ad.settingsMu.Unlock()
+	ad.hooks = append(ad.hooks, hooks...)
+}
+
+// resultHooks returns the current hook chain
+func (ad *AnomalyDetector) resultHooks() []ResultHook {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.hooks
+}
+
+// finishResult runs the hook chain over the result of analyzing input, then
+// publishes what it returns
+func (ad *AnomalyDetector) finishResult(input string, result *models.AnomalyResult, start time.Time) *models.AnomalyResult {
+	for _, hook := range ad.resultHooks() {
+		if next := hook(result, input); next != nil {
+			result = next
+		}
+	}
+	ad.publishResult(result, time.Since(start))
+	return result
+}
+
+// MetadataHook adds values to the metadata of every result, replacing any
+// already set under the same keys
+func MetadataHook(values map[string]interface{}) ResultHook {
+	return func(result *models.AnomalyResult, input string) *models.AnomalyResult {
+		if result.Metadata == nil {
+			result.Metadata = make(map[string]interface{}, len(values))
+		}
+		for key, value := range values {
+			result.Metadata[key] = value
+		}
+		return result
+	}
+}
+
+// RedactHook removes the given metadata keys from results and their
+// analyzer details, e.g. text previews that must not leave the service.
+// Metadata maps are replaced rather than edited, since analyzer results can
+// be shared with the feature cache.
+func RedactHook(keys ...string) ResultHook {
+	return func(result *models.AnomalyResult, input string) *models.AnomalyResult {
+		result.Metadata = withoutKeys(result.Metadata, keys)
+		for name, detail := range result.Details {
+			if detail != nil {
+				redacted := *detail
+				redacted.Metadata = withoutKeys(detail.Metadata, keys)
+				result.Details[name] = &redacted
+			}
+		}
+		return result
+	}
+}
+
+// withoutKeys returns a copy of metadata lacking the given keys
+func withoutKeys(metadata map[string]interface{}, keys []string) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	for _, key := range keys {
+		delete(copied, key)
+	}
+	return copied
+}
+
+// SeverityHook reclassifies the severity of results with bands other than
+// the detector's, e.g. stricter ones for one caller. Empty input results
+// keep their severity.
+func SeverityHook(bands SeverityBands) (ResultHook, error) {
+	if err := bands.Validate(); err != nil {
+		return nil, err
+	}
+	return func(result *models.AnomalyResult, input string) *models.AnomalyResult {
+		if result.Outcome == models.OutcomeAnalyzed {
+			result.Severity = bands.Classify(result.Score, result.Confidence)
+		}
+		return result
+	}, nil
+}
@@ -22,6 +22,12 @@ type MessageBroker interface {
 	Close() error
 }
 
+// TransientErrorClassifier is implemented by brokers that can tell which of
+// their publish errors may succeed if retried
+type TransientErrorClassifier interface {
+	IsTransient(err error) bool
+}
+
 // MessageQueue interface for message queuing
 type MessageQueue interface {
 	Enqueue(ctx context.Context, queueName string, message interface{}) error
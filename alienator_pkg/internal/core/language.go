@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+
+	"github.com/ruvnet/alienator/internal/language"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// LanguagePolicy sets whether the language of a text is detected once per
+// analysis and shared: handed to every analyzer through the context, see
+// language.FromContext, and reported at the top level of the result. When
+// it isn't shared, analyzers that need the language detect it themselves
+// and the result doesn't report it.
+type LanguagePolicy struct {
+	Shared bool
+}
+
+// DefaultLanguagePolicy shares the language detection
+func DefaultLanguagePolicy() LanguagePolicy {
+	return LanguagePolicy{Shared: true}
+}
+
+// SetLanguagePolicy replaces the language detection policy
+func (ad *AnomalyDetector) SetLanguagePolicy(policy LanguagePolicy) {
+	ad.settingsMu.Lock()
+	ad.language = policy
+	ad.settingsMu.Unlock()
+}
+
+// languagePolicy returns the current language detection policy
+func (ad *AnomalyDetector) languagePolicy() LanguagePolicy {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.language
+}
+
+// sharedLanguage returns the language detected in the tokenized text when
+// the policy shares it, and nil otherwise
+func (ad *AnomalyDetector) sharedLanguage(tokens *tokenizer.Tokens) *language.Detection {
+	if !ad.languagePolicy().Shared {
+		return nil
+	}
+	return tokens.Language()
+}
+
+// languageContext returns the context analyzers run in, derived from ctx
+// and carrying detection when there is one
+func languageContext(ctx context.Context, detection *language.Detection) context.Context {
+	if detection == nil {
+		return ctx
+	}
+	return language.NewContext(ctx, detection)
+}
+
+// setLanguage reports detection at the top level of result; nothing is
+// reported without a detected language
+func setLanguage(result *models.AnomalyResult, detection *language.Detection) {
+	if detection == nil || detection.Code == "" {
+		return
+	}
+	result.Language = detection.Code
+	result.LanguageConfidence = detection.Confidence
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models/proto"
+	"go.uber.org/zap"
+)
+
+// NATSBroker implements the MessageBroker interface over a NATS connection.
+// Messages travel as JSON, and each subscription hands them to its handler
+// in the order NATS delivers them.
+type NATSBroker struct {
+	conn          *nats.Conn
+	subscriptions map[string]*nats.Subscription
+	published     int64
+	delivered     int64
+	failed        int64
+	mu            sync.Mutex
+	logger        *zap.Logger
+}
+
+// NewNATSBroker connects to the configured NATS server, reconnecting for as
+// long as the broker is open
+func NewNATSBroker(cfg config.NATSConfig, logger *zap.Logger) (*NATSBroker, error) {
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name("alienator"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.Warn("NATS connection lost", zap.Error(err))
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			logger.Info("NATS connection restored", zap.String("url", conn.ConnectedUrl()))
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS at %s: %w", cfg.URL, err)
+	}
+
+	return &NATSBroker{
+		conn:          conn,
+		subscriptions: make(map[string]*nats.Subscription),
+		logger:        logger,
+	}, nil
+}
+
+// Publish publishes a message to a subject
+func (b *NATSBroker) Publish(ctx context.Context, topic string, msg *proto.Message) error {
+	if msg == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+	if topic == "" {
+		return fmt.Errorf("topic cannot be empty")
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if err := b.conn.Publish(topic, data); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.published++
+	b.mu.Unlock()
+	return nil
+}
+
+// IsTransient reports whether a publish error may succeed if retried, such
+// as while the connection is reconnecting
+func (b *NATSBroker) IsTransient(err error) bool {
+	for _, transient := range []error{
+		nats.ErrTimeout,
+		nats.ErrConnectionReconnecting,
+		nats.ErrNoServers,
+		nats.ErrReconnectBufExceeded,
+		nats.ErrSlowConsumer,
+	} {
+		if errors.Is(err, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe subscribes a handler to a subject
+func (b *NATSBroker) Subscribe(ctx context.Context, topic string, handler MessageHandler) (string, error) {
+	if handler == nil {
+		return "", fmt.Errorf("handler cannot be nil")
+	}
+
+	sub, err := b.conn.Subscribe(topic, func(m *nats.Msg) {
+		msg := &proto.Message{}
+		if err := json.Unmarshal(m.Data, msg); err != nil {
+			b.logger.Warn("Dropping undecodable message", zap.String("topic", m.Subject), zap.Error(err))
+			b.countDelivery(false)
+			return
+		}
+		if err := handler(context.Background(), msg); err != nil {
+			b.logger.Error("Message handler failed",
+				zap.String("topic", m.Subject),
+				zap.String("message_id", msg.ID),
+				zap.Error(err))
+			b.countDelivery(false)
+			return
+		}
+		b.countDelivery(true)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+
+	subID := fmt.Sprintf("sub_%s_%d", topic, time.Now().UnixNano())
+	b.mu.Lock()
+	b.subscriptions[subID] = sub
+	b.mu.Unlock()
+	return subID, nil
+}
+
+// Unsubscribe removes a subscription
+func (b *NATSBroker) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	b.mu.Lock()
+	sub, exists := b.subscriptions[subscriptionID]
+	delete(b.subscriptions, subscriptionID)
+	b.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("subscription not found: %s", subscriptionID)
+	}
+	return sub.Unsubscribe()
+}
+
+// GetStats returns the connection's traffic along with delivery counts
+func (b *NATSBroker) GetStats(ctx context.Context) (*proto.BrokerStats, error) {
+	stats := b.conn.Stats()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &proto.BrokerStats{
+		InMsgs:              stats.InMsgs,
+		OutMsgs:             stats.OutMsgs,
+		InBytes:             stats.InBytes,
+		OutBytes:            stats.OutBytes,
+		Reconnects:          stats.Reconnects,
+		MessagesPublished:   b.published,
+		MessagesDelivered:   b.delivered,
+		FailedDeliveries:    b.failed,
+		ActiveSubscriptions: int32(len(b.subscriptions)),
+		Subscriptions:       int64(len(b.subscriptions)),
+		LastUpdated:         time.Now(),
+	}, nil
+}
+
+// Close drains the subscriptions, letting handlers finish the messages they
+// already received, and closes the connection
+func (b *NATSBroker) Close() error {
+	return b.conn.Drain()
+}
+
+// countDelivery counts a message handed to a handler
+func (b *NATSBroker) countDelivery(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		b.delivered++
+	} else {
+		b.failed++
+	}
+}
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Score normalization strategies
+const (
+	// NormalizationNone weighs raw analyzer scores (the default)
+	NormalizationNone = "none"
+	// NormalizationZScore maps a score to the normal CDF of its z-score
+	// against the analyzer's baseline, so 0.5 is an average score
+	NormalizationZScore = "zscore"
+	// NormalizationMinMax rescales a score to the lowest and highest scores
+	// in the analyzer's baseline
+	NormalizationMinMax = "minmax"
+	// NormalizationPercentile maps a score to its percentile rank in the
+	// analyzer's baseline
+	NormalizationPercentile = "percentile"
+)
+
+// NormalizationPolicy controls how analyzer scores are brought onto a common
+// scale before they are weighted. Each analyzer keeps a running baseline of
+// its last Window raw scores; a score is normalized against the baseline
+// before being added to it. Only the texts analyzed at a caller's request
+// add to the baselines, not the sentences, windows or parts of them analyzed
+// apart, nor warm-up or tuning samples. Until an analyzer's baseline holds MinSamples
+// scores, its scores are weighted raw.
+type NormalizationPolicy struct {
+	Strategy   string
+	Window     int
+	MinSamples int
+}
+
+// DefaultNormalizationPolicy returns the policy matching the detector's
+// original behaviour: raw scores
+func DefaultNormalizationPolicy() NormalizationPolicy {
+	return NormalizationPolicy{
+		Strategy:   NormalizationNone,
+		Window:     1000,
+		MinSamples: 30,
+	}
+}
+
+// Validate checks the strategy and baseline sizes
+func (p NormalizationPolicy) Validate() error {
+	switch p.Strategy {
+	case NormalizationNone, NormalizationZScore, NormalizationMinMax, NormalizationPercentile:
+	default:
+		return fmt.Errorf("unknown score normalization %q (expected none, zscore, minmax or percentile)", p.Strategy)
+	}
+	if p.Window <= 0 {
+		return fmt.Errorf("normalization window must be positive, got %d", p.Window)
+	}
+	if p.MinSamples <= 0 || p.MinSamples > p.Window {
+		return fmt.Errorf("normalization min samples must be between 1 and the window (%d), got %d", p.Window, p.MinSamples)
+	}
+	return nil
+}
+
+// scoreBaseline holds the last raw scores of one analyzer in a ring buffer
+type scoreBaseline struct {
+	mu     sync.Mutex
+	scores []float64
+	next   int
+}
+
+// normalize maps score onto the baseline with the given strategy. It reports
+// false, leaving the score as is, while the baseline has fewer than
+// minSamples scores.
+func (b *scoreBaseline) normalize(score float64, policy NormalizationPolicy) (float64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.scores) < policy.MinSamples {
+		return score, false
+	}
+	switch policy.Strategy {
+	case NormalizationZScore:
+		return zScoreCDF(score, b.scores), true
+	case NormalizationMinMax:
+		return minMax(score, b.scores), true
+	case NormalizationPercentile:
+		return percentileRank(score, b.scores), true
+	}
+	return score, true
+}
+
+// record adds score to the baseline, replacing the oldest once it holds
+// window scores
+func (b *scoreBaseline) record(score float64, window int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.scores) < window {
+		b.scores = append(b.scores, score)
+	} else {
+		b.scores[b.next] = score
+		b.next = (b.next + 1) % window
+	}
+}
+
+// zScoreCDF returns the standard normal CDF of score's z-score, or 0.5 when
+// the baseline has no spread
+func zScoreCDF(score float64, baseline []float64) float64 {
+	mean, m2 := 0.0, 0.0
+	for i, value := range baseline {
+		delta := value - mean
+		mean += delta / float64(i+1)
+		m2 += delta * (value - mean)
+	}
+	stddev := math.Sqrt(m2 / float64(len(baseline)))
+	if stddev == 0 {
+		return 0.5
+	}
+	z := (score - mean) / stddev
+	return 0.5 * (1 + math.Erf(z/math.Sqrt2))
+}
+
+// minMax rescales score to the baseline's range, clamped to [0, 1], or
+// returns 0.5 when the baseline has no spread
+func minMax(score float64, baseline []float64) float64 {
+	lowest, highest := baseline[0], baseline[0]
+	for _, value := range baseline[1:] {
+		lowest = math.Min(lowest, value)
+		highest = math.Max(highest, value)
+	}
+	if highest == lowest {
+		return 0.5
+	}
+	return math.Max(0, math.Min(1, (score-lowest)/(highest-lowest)))
+}
+
+// percentileRank returns the share of the baseline below score, counting
+// equal scores as half below
+func percentileRank(score float64, baseline []float64) float64 {
+	below := 0.0
+	for _, value := range baseline {
+		switch {
+		case value < score:
+			below++
+		case value == score:
+			below += 0.5
+		}
+	}
+	return below / float64(len(baseline))
+}
+
+// normalizeScores returns the strategy in effect and the normalized score of
+// every analyzer result whose baseline is established, then records the raw
+// scores in the baselines if ctx is that of a requested analysis. The scores
+// are nil when normalization is off.
+func (ad *AnomalyDetector) normalizeScores(ctx context.Context, results map[string]*models.AnalysisResult) (string, map[string]float64) {
+	policy := ad.normalizationPolicy()
+	if policy.Strategy == NormalizationNone {
+		return policy.Strategy, nil
+	}
+
+	record, _ := ctx.Value(recordBaselinesKey{}).(bool)
+	normalized := make(map[string]float64, len(results))
+	for name, result := range results {
+		baseline := ad.scoreBaseline(name)
+		if score, ok := baseline.normalize(result.Score, policy); ok {
+			normalized[name] = score
+		}
+		if record {
+			baseline.record(result.Score, policy.Window)
+		}
+	}
+	return policy.Strategy, normalized
+}
+
+type recordBaselinesKey struct{}
+
+// recordingBaselines returns ctx for analyzing a text at a caller's request,
+// whose raw scores are added to the baselines
+func recordingBaselines(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recordBaselinesKey{}, true)
+}
+
+// withoutRecording returns ctx for analyzing part of the text analyzed in
+// ctx, which is normalized against the baselines without adding to them
+func withoutRecording(ctx context.Context) context.Context {
+	if record, _ := ctx.Value(recordBaselinesKey{}).(bool); !record {
+		return ctx
+	}
+	return context.WithValue(ctx, recordBaselinesKey{}, false)
+}
+
+// scoreBaseline returns the running baseline of an analyzer, creating it on
+// first use
+func (ad *AnomalyDetector) scoreBaseline(name string) *scoreBaseline {
+	ad.baselinesMu.Lock()
+	defer ad.baselinesMu.Unlock()
+	baseline, ok := ad.baselines[name]
+	if !ok {
+		baseline = &scoreBaseline{}
+		ad.baselines[name] = baseline
+	}
+	return baseline
+}
+
+// SetNormalizationPolicy replaces the policy used to normalize analyzer
+// scores. Changing the window discards the running baselines.
+func (ad *AnomalyDetector) SetNormalizationPolicy(policy NormalizationPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	defer ad.settingsMu.Unlock()
+	ad.setNormalizationLocked(policy)
+	return nil
+}
+
+// setNormalizationLocked replaces the normalization policy, discarding the
+// baselines if their size changes. Callers hold settingsMu.
+func (ad *AnomalyDetector) setNormalizationLocked(policy NormalizationPolicy) {
+	if policy.Window != ad.normalizer.Window {
+		ad.baselinesMu.Lock()
+		ad.baselines = make(map[string]*scoreBaseline)
+		ad.baselinesMu.Unlock()
+	}
+	ad.normalizer = policy
+}
+
+// normalizationPolicy returns the current normalization policy
+func (ad *AnomalyDetector) normalizationPolicy() NormalizationPolicy {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.normalizer
+}
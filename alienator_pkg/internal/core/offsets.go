@@ -0,0 +1,71 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// offsetMap maps byte offsets in a text derived from another, by dropping
+// parts of it and joining what is left, back to offsets in the other text
+type offsetMap struct {
+	pieces []mappedPiece // in order of their offset in the derived text
+	parent *offsetMap    // maps the other text further back, nil if it is the input
+}
+
+// mappedPiece is a run of the derived text starting at derived, copied from
+// the other text at original
+type mappedPiece struct {
+	derived  int
+	original int
+}
+
+// original returns the offset in the input of the byte at offset in the
+// derived text. A nil map leaves offsets as they are.
+func (m *offsetMap) original(offset int) int {
+	if m == nil {
+		return offset
+	}
+	i := sort.Search(len(m.pieces), func(i int) bool { return m.pieces[i].derived > offset }) - 1
+	if i >= 0 {
+		offset = m.pieces[i].original + offset - m.pieces[i].derived
+	}
+	return m.parent.original(offset)
+}
+
+// span returns the offset and length in the input of the bytes at
+// [offset, offset+length) in the derived text. A span crossing a dropped
+// part takes it in.
+func (m *offsetMap) span(offset, length int) (int, int) {
+	start := m.original(offset)
+	if length == 0 {
+		return start, 0
+	}
+	return start, m.original(offset+length-1) + 1 - start
+}
+
+// derivedText builds a text out of parts of another, mapping its offsets
+// back to the other's
+type derivedText struct {
+	text    strings.Builder
+	offsets offsetMap
+}
+
+// copy appends source[start:end]
+func (d *derivedText) copy(source string, start, end int) {
+	if start >= end {
+		return
+	}
+	d.offsets.pieces = append(d.offsets.pieces, mappedPiece{derived: d.text.Len(), original: start})
+	d.text.WriteString(source[start:end])
+}
+
+// insert appends s, which is not in the other text, mapped to original
+func (d *derivedText) insert(s string, original int) {
+	d.offsets.pieces = append(d.offsets.pieces, mappedPiece{derived: d.text.Len(), original: original})
+	d.text.WriteString(s)
+}
+
+// result returns the derived text and its offset map
+func (d *derivedText) result() (string, *offsetMap) {
+	return d.text.String(), &d.offsets
+}
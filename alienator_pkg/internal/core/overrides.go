@@ -0,0 +1,208 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Configurable is implemented by analyzers whose options can be changed with
+// the parameters they describe
+type Configurable interface {
+	Configure(config map[string]interface{}) error
+}
+
+// ParameterOverride sets one analyzer parameter, for a single run
+type ParameterOverride struct {
+	Analyzer  string
+	Parameter string
+	Value     string
+}
+
+// ParseParameterOverride parses an override written "analyzer.param=value"
+func ParseParameterOverride(setting string) (ParameterOverride, error) {
+	key, value, ok := strings.Cut(setting, "=")
+	if !ok {
+		return ParameterOverride{}, fmt.Errorf("invalid parameter override %q (expected analyzer.param=value)", setting)
+	}
+	analyzer, parameter, ok := strings.Cut(strings.TrimSpace(key), ".")
+	if !ok || analyzer == "" || parameter == "" {
+		return ParameterOverride{}, fmt.Errorf("invalid parameter override %q (expected analyzer.param=value)", setting)
+	}
+	return ParameterOverride{Analyzer: analyzer, Parameter: parameter, Value: strings.TrimSpace(value)}, nil
+}
+
+// ApplyParameterOverrides converts every override to the type its analyzer
+// documents for the parameter and passes them to the analyzers' Configure
+// methods, one call per analyzer in name order. All overrides are parsed
+// first, so an unknown analyzer or parameter or a value of the wrong type
+// changes nothing. The update is not atomic, though: a value an analyzer's
+// Configure rejects, e.g. for being out of range, leaves the analyzers
+// configured before it changed.
+func (ad *AnomalyDetector) ApplyParameterOverrides(overrides []ParameterOverride) error {
+	analyzers := make(map[string]Analyzer, len(ad.analyzers))
+	for _, analyzer := range ad.analyzers {
+		analyzers[analyzer.Name()] = analyzer
+	}
+
+	configs := make(map[string]map[string]interface{})
+	for _, override := range overrides {
+		analyzer, ok := analyzers[override.Analyzer]
+		if !ok {
+			return fmt.Errorf("unknown analyzer %q (registered: %s)", override.Analyzer, strings.Join(ad.AnalyzerNames(), ", "))
+		}
+		describer, describes := analyzer.(Describer)
+		if _, ok := analyzer.(Configurable); !ok || !describes {
+			return fmt.Errorf("analyzer %s has no configurable parameters", override.Analyzer)
+		}
+
+		parameters := describer.Parameters()
+		var parameter *models.AnalyzerParameter
+		names := make([]string, 0, len(parameters))
+		for i := range parameters {
+			names = append(names, parameters[i].Name)
+			if parameters[i].Name == override.Parameter {
+				parameter = &parameters[i]
+			}
+		}
+		if parameter == nil {
+			sort.Strings(names)
+			return fmt.Errorf("unknown parameter %s.%s (expected one of %s)", override.Analyzer, override.Parameter, strings.Join(names, ", "))
+		}
+
+		value, err := parseParameterValue(*parameter, override.Value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s.%s: %w", override.Analyzer, override.Parameter, err)
+		}
+		if configs[override.Analyzer] == nil {
+			configs[override.Analyzer] = make(map[string]interface{})
+		}
+		configs[override.Analyzer][override.Parameter] = value
+	}
+
+	for _, name := range sortedKeys(configs) {
+		if err := analyzers[name].(Configurable).Configure(configs[name]); err != nil {
+			return fmt.Errorf("configuration of analyzer %s failed: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ConfigureAnalyzers passes the configured parameters of the registered
+// analyzers to their Configure methods, checked like overrides. Entries for
+// analyzers that aren't registered are skipped, since processes sharing a
+// configuration register different analyzers.
+func (ad *AnomalyDetector) ConfigureAnalyzers(analyzers map[string]config.AnalyzerConfig) error {
+	registered := make(map[string]bool, len(ad.analyzers))
+	for _, analyzer := range ad.analyzers {
+		registered[analyzer.Name()] = true
+	}
+
+	var overrides []ParameterOverride
+	for _, name := range sortedKeys(analyzers) {
+		if !registered[name] {
+			continue
+		}
+		parameters := analyzers[name].Parameters
+		for _, parameter := range sortedKeys(parameters) {
+			overrides = append(overrides, ParameterOverride{
+				Analyzer:  name,
+				Parameter: parameter,
+				Value:     formatParameterValue(parameters[parameter]),
+			})
+		}
+	}
+	return ad.ApplyParameterOverrides(overrides)
+}
+
+// AnalyzerConfigs returns the configuration of every registered analyzer
+// that has parameters: its documented defaults with the configured values
+// laid over them. Configured entries for analyzers that aren't registered,
+// such as disabled ones, are returned as they are.
+func (ad *AnomalyDetector) AnalyzerConfigs(configured map[string]config.AnalyzerConfig) map[string]config.AnalyzerConfig {
+	configs := make(map[string]config.AnalyzerConfig, len(ad.analyzers))
+	for name, analyzer := range configured {
+		configs[name] = analyzer
+	}
+	for _, analyzer := range ad.analyzers {
+		describer, ok := analyzer.(Describer)
+		if !ok {
+			continue
+		}
+		entry := configs[analyzer.Name()]
+		parameters := make(map[string]interface{})
+		for _, parameter := range describer.Parameters() {
+			if parameter.Default != nil {
+				parameters[parameter.Name] = parameter.Default
+			}
+		}
+		for name, value := range entry.Parameters {
+			parameters[name] = value
+		}
+		if len(parameters) == 0 && !entry.Disabled {
+			continue
+		}
+		entry.Parameters = parameters
+		configs[analyzer.Name()] = entry
+	}
+	return configs
+}
+
+// formatParameterValue writes a configured value the way it would be given
+// on the command line
+func formatParameterValue(value interface{}) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// sortedKeys returns the keys of a map, sorted
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseParameterValue converts text to the type of the parameter
+func parseParameterValue(parameter models.AnalyzerParameter, text string) (interface{}, error) {
+	switch parameter.Type {
+	case models.ParameterInteger:
+		value, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", text)
+		}
+		return value, nil
+	case models.ParameterNumber:
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", text)
+		}
+		return value, nil
+	case models.ParameterBoolean:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", text)
+		}
+		return value, nil
+	case models.ParameterString:
+		if len(parameter.Values) > 0 {
+			for _, allowed := range parameter.Values {
+				if text == allowed {
+					return text, nil
+				}
+			}
+			return nil, fmt.Errorf("expected one of %s, got %q", strings.Join(parameter.Values, ", "), text)
+		}
+		return text, nil
+	default:
+		return nil, fmt.Errorf("unsupported parameter type %q", parameter.Type)
+	}
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models/proto"
+	"go.uber.org/zap"
+)
+
+// redisConnectTimeout bounds the ping that checks the server is reachable
+const redisConnectTimeout = 5 * time.Second
+
+// RedisQueue implements the MessageQueue interface on Redis lists shared by
+// every process. Dequeue moves a message atomically from its queue onto the
+// queue's processing list, where it stays until it is acknowledged, so a
+// message taken by a consumer that dies is kept there rather than lost.
+type RedisQueue struct {
+	client      *redis.Client
+	maxAttempts int32
+	inFlight    map[string]redisInFlight // by queue message ID
+	mu          sync.Mutex
+	logger      *zap.Logger
+}
+
+// redisInFlight is a message this process dequeued and has not settled
+type redisInFlight struct {
+	message *proto.QueueMessage
+	raw     string // the entry on the processing list
+}
+
+// NewRedisQueue connects to the configured Redis server
+func NewRedisQueue(cfg config.RedisConfig, logger *zap.Logger) (*RedisQueue, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr(),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", cfg.Addr(), err)
+	}
+
+	return &RedisQueue{
+		client:      client,
+		maxAttempts: int32(DefaultQueueConfig().DeadLetterThreshold),
+		inFlight:    make(map[string]redisInFlight),
+		logger:      logger,
+	}, nil
+}
+
+func redisQueueKey(name string) string      { return "queue:" + name }
+func redisProcessingKey(name string) string { return "queue:" + name + ":processing" }
+func redisDeadLetterKey(name string) string { return "queue:" + name + ":dlq" }
+
+// Enqueue appends a message to the queue
+func (q *RedisQueue) Enqueue(ctx context.Context, queueName string, message interface{}) error {
+	if message == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+	if queueName == "" {
+		return fmt.Errorf("queue name cannot be empty")
+	}
+
+	var queueMsg *proto.QueueMessage
+	switch msg := message.(type) {
+	case *proto.QueueMessage:
+		queueMsg = msg
+	case *proto.Message:
+		queueMsg = &proto.QueueMessage{
+			Id:          "qm_" + uuid.New().String(),
+			QueueName:   queueName,
+			Message:     msg,
+			EnqueuedAt:  time.Now().Unix(),
+			MaxAttempts: q.maxAttempts,
+			Status:      "pending",
+		}
+	default:
+		return fmt.Errorf("unsupported message type: %T", message)
+	}
+
+	encoded, err := json.Marshal(queueMsg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	return q.client.LPush(ctx, redisQueueKey(queueName), encoded).Err()
+}
+
+// Dequeue takes the oldest message from the queue, waiting up to timeout
+// for one to arrive
+func (q *RedisQueue) Dequeue(ctx context.Context, queueName string, timeout time.Duration) (*proto.QueueMessage, error) {
+	if queueName == "" {
+		return nil, fmt.Errorf("queue name cannot be empty")
+	}
+
+	raw, err := q.client.BRPopLPush(ctx, redisQueueKey(queueName), redisProcessingKey(queueName), timeout).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("dequeue timeout")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	queueMsg := &proto.QueueMessage{}
+	if err := json.Unmarshal([]byte(raw), queueMsg); err != nil {
+		// Nothing can process it, so keep it for inspection
+		q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.LRem(ctx, redisProcessingKey(queueName), 1, raw)
+			pipe.LPush(ctx, redisDeadLetterKey(queueName), raw)
+			return nil
+		})
+		return nil, fmt.Errorf("failed to decode message from %s: %w", queueName, err)
+	}
+	queueMsg.QueueName = queueName
+	queueMsg.Attempts++
+	queueMsg.DequeuedAt = time.Now().Unix()
+	queueMsg.Status = "in_flight"
+
+	q.mu.Lock()
+	q.inFlight[queueMsg.Id] = redisInFlight{message: queueMsg, raw: raw}
+	q.mu.Unlock()
+	return queueMsg, nil
+}
+
+// Ack removes a processed message from its processing list
+func (q *RedisQueue) Ack(ctx context.Context, messageID string) error {
+	item, err := q.settle(messageID)
+	if err != nil {
+		return err
+	}
+	return q.client.LRem(ctx, redisProcessingKey(item.message.QueueName), 1, item.raw).Err()
+}
+
+// Nack removes a message from its processing list and, if requeue is set,
+// puts it back at the end of its queue, or on its dead letter list once it
+// has used up its attempts
+func (q *RedisQueue) Nack(ctx context.Context, messageID string, requeue bool) error {
+	item, err := q.settle(messageID)
+	if err != nil {
+		return err
+	}
+	queueName := item.message.QueueName
+	if !requeue {
+		return q.client.LRem(ctx, redisProcessingKey(queueName), 1, item.raw).Err()
+	}
+
+	target := redisQueueKey(queueName)
+	item.message.Status = "pending"
+	item.message.RequeuedAt = time.Now().Unix()
+	maxAttempts := item.message.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = q.maxAttempts
+	}
+	if item.message.Attempts >= maxAttempts {
+		target = redisDeadLetterKey(queueName)
+		item.message.Status = "dead_letter"
+		q.logger.Warn("message moved to dead letter queue",
+			zap.String("message_id", messageID),
+			zap.String("original_queue", queueName),
+			zap.Int32("delivery_count", item.message.Attempts))
+	}
+	encoded, err := json.Marshal(item.message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, redisProcessingKey(queueName), 1, item.raw)
+		pipe.LPush(ctx, target, encoded)
+		return nil
+	})
+	return err
+}
+
+// settle stops tracking a message this process dequeued
+func (q *RedisQueue) settle(messageID string) (redisInFlight, error) {
+	if messageID == "" {
+		return redisInFlight{}, fmt.Errorf("message ID cannot be empty")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, exists := q.inFlight[messageID]
+	if !exists {
+		return redisInFlight{}, fmt.Errorf("message not found in flight: %s", messageID)
+	}
+	delete(q.inFlight, messageID)
+	return item, nil
+}
+
+// PurgeQueue drops every message waiting in a queue
+func (q *RedisQueue) PurgeQueue(ctx context.Context, queueName string) error {
+	if queueName == "" {
+		return fmt.Errorf("queue name cannot be empty")
+	}
+	return q.client.Del(ctx, redisQueueKey(queueName)).Err()
+}
+
+// GetStats returns the lengths of a queue and its processing and dead
+// letter lists
+func (q *RedisQueue) GetStats(queueName string) (*proto.QueueStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+
+	var size, processing, deadLetters *redis.IntCmd
+	_, err := q.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		size = pipe.LLen(ctx, redisQueueKey(queueName))
+		processing = pipe.LLen(ctx, redisProcessingKey(queueName))
+		deadLetters = pipe.LLen(ctx, redisDeadLetterKey(queueName))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.QueueStats{
+		Name:            queueName,
+		Size:            size.Val(),
+		ProcessingCount: processing.Val(),
+		DlqSize:         deadLetters.Val(),
+		Timestamp:       time.Now().Unix(),
+	}, nil
+}
+
+// Close closes the Redis connection
+func (q *RedisQueue) Close() error {
+	return q.client.Close()
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Selection restricts a single analysis to some of the registered analyzers
+// and overrides their weights. The zero Selection runs every analyzer with
+// the content profile's weights.
+type Selection struct {
+	// Analyzers names the analyzers to run; empty runs all of them
+	Analyzers []string
+	// Weights override the content profile's weight for the named analyzers
+	Weights map[string]float64
+	// Scales multiply the weight of the named analyzers after Weights are
+	// applied, down-weighting them whatever the content profile
+	Scales map[string]float64
+	// Budget bounds this analysis in place of the configured budget, running
+	// analyzers in the configured order; 0 keeps the configured budget
+	Budget time.Duration
+	// Exclude names analyzers left out even when selected. Names need not be
+	// registered, so one list can serve detectors with different analyzers.
+	Exclude []string
+}
+
+// IsZero reports whether the selection leaves the analysis unchanged
+func (s Selection) IsZero() bool {
+	return len(s.Analyzers) == 0 && len(s.Weights) == 0 && len(s.Scales) == 0 && s.Budget == 0 && len(s.Exclude) == 0
+}
+
+// includes reports whether the named analyzer is selected
+func (s Selection) includes(analyzer string) bool {
+	for _, name := range s.Exclude {
+		if name == analyzer {
+			return false
+		}
+	}
+	if len(s.Analyzers) == 0 {
+		return true
+	}
+	for _, name := range s.Analyzers {
+		if name == analyzer {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns the profile with the selection's weights laid over it
+func (s Selection) apply(profile AnalyzerProfile) AnalyzerProfile {
+	if len(s.Weights) == 0 && len(s.Scales) == 0 {
+		return profile
+	}
+
+	weights := make(map[string]float64, len(profile.Weights)+len(s.Weights))
+	for name, weight := range profile.Weights {
+		weights[name] = weight
+	}
+	for name, weight := range s.Weights {
+		weights[name] = weight
+	}
+	adjusted := AnalyzerProfile{Name: profile.Name, Weights: weights}
+	for name, scale := range s.Scales {
+		weights[name] = adjusted.Weight(name) * scale
+	}
+	return adjusted
+}
+
+// AnalyzerNames returns the names of the registered analyzers, sorted
+func (ad *AnomalyDetector) AnalyzerNames() []string {
+	names := make([]string, 0, len(ad.analyzers))
+	for _, analyzer := range ad.analyzers {
+		names = append(names, analyzer.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateSelection checks that the selection only names registered analyzers
+// and that its weights, scales and budget are not negative
+func (ad *AnomalyDetector) ValidateSelection(selection Selection) error {
+	registered := make(map[string]bool, len(ad.analyzers))
+	for _, analyzer := range ad.analyzers {
+		registered[analyzer.Name()] = true
+	}
+
+	for _, name := range selection.Analyzers {
+		if !registered[name] {
+			return fmt.Errorf("unknown analyzer %q (registered: %v)", name, ad.AnalyzerNames())
+		}
+	}
+	for name, weight := range selection.Weights {
+		if !registered[name] {
+			return fmt.Errorf("weight given for unknown analyzer %q (registered: %v)", name, ad.AnalyzerNames())
+		}
+		if weight < 0 {
+			return fmt.Errorf("weight for analyzer %q must not be negative, got %g", name, weight)
+		}
+	}
+	for name, scale := range selection.Scales {
+		if !registered[name] {
+			return fmt.Errorf("scale given for unknown analyzer %q (registered: %v)", name, ad.AnalyzerNames())
+		}
+		if scale < 0 {
+			return fmt.Errorf("scale for analyzer %q must not be negative, got %g", name, scale)
+		}
+	}
+	if selection.Budget < 0 {
+		return fmt.Errorf("budget must not be negative, got %s", selection.Budget)
+	}
+	return nil
+}
+
+// AnalyzeTextWith performs anomaly detection like AnalyzeTextAs, running only
+// the selected analyzers with the selection's weights laid over the content
+// profile. An analyzer whose resulting weight is 0 is skipped even when
+// selected. The analyzers run in ctx, and the analysis fails with ctx's
+// error once it is done.
+func (ad *AnomalyDetector) AnalyzeTextWith(ctx context.Context, text string, contentType ContentType, selection Selection) (*models.AnomalyResult, error) {
+	if err := ad.ValidateSelection(selection); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := ad.analyze(recordingBaselines(ctx), text, contentType, selection)
+	if err != nil {
+		return nil, err
+	}
+	return ad.finishResult(text, result, start), nil
+}
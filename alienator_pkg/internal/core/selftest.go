@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruvnet/alienator/pkg/detector"
+)
+
+// SelfTestSample is a labelled text of the self-test corpus
+type SelfTestSample struct {
+	Text      string
+	Anomalous bool
+}
+
+// SelfTestSamples is the built-in labelled corpus the self-test scores: human
+// anecdotes, and formulaic or degenerate texts expected to be anomalous
+var SelfTestSamples = []SelfTestSample{
+	{"Honestly I forgot my keys again, so I sat on the porch and watched the neighbour's cat chase a moth for an hour.", false},
+	{"We missed the bus, ran two blocks in the rain, and still got there before the doors opened. Typical Tuesday.", false},
+	{"my grandmother's soup recipe has no measurements, just 'enough' salt and a handful of whatever is in the garden", false},
+	{"The meeting ran long because Dave kept arguing about the font on slide three. Nobody cared. We ordered pizza.", false},
+	{"I tried fixing the bike chain myself. Grease everywhere, one missing link, and a very judgmental dog watching.", false},
+	{"Saw an old friend at the market today; we talked about nothing in particular and it was the best part of my week.", false},
+	{"In conclusion, it is important to note that there are several key factors to consider. Furthermore, it is important to note that these factors are important.", true},
+	{"As an AI language model, I can provide a comprehensive overview. Additionally, it is worth noting that the overview is comprehensive and provides an overview.", true},
+	{"The system processes the data. The system analyzes the data. The system outputs the data. The system processes the data. The system analyzes the data.", true},
+	{"aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa aaaa", true},
+}
+
+// SelfTest scores the self-test corpus with the detector's current analyzers
+// and evaluates the scores against the labels at threshold. Like warm-up
+// runs, the analyses publish no events and don't add to the normalization
+// baselines.
+func (ad *AnomalyDetector) SelfTest(ctx context.Context, threshold float64) (detector.Metrics, error) {
+	predictions := make([]float64, 0, len(SelfTestSamples))
+	labels := make([]bool, 0, len(SelfTestSamples))
+	for _, sample := range SelfTestSamples {
+		if err := ctx.Err(); err != nil {
+			return detector.Metrics{}, fmt.Errorf("self-test interrupted: %w", err)
+		}
+		result, err := ad.analyze(ctx, sample.Text, ContentTypeAuto, Selection{})
+		if err != nil {
+			return detector.Metrics{}, fmt.Errorf("self-test analysis failed: %w", err)
+		}
+		predictions = append(predictions, result.Score)
+		labels = append(labels, sample.Anomalous)
+	}
+	return detector.EvaluateWithThreshold(predictions, labels, threshold, detector.DefaultCalibrationBins), nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// TopSentences scores every sentence of text on its own and returns the n
+// contributing most to the weighted mean of their scores, highest first.
+// Sentences the embedding analyzer found to be outliers in result, the
+// analysis of the whole text, are marked. All sentences are analyzed with the
+// profile of the content type result was analyzed as, without publishing
+// events, so the cost grows with the number of sentences.
+func (ad *AnomalyDetector) TopSentences(text string, result *models.AnomalyResult, n int) ([]models.SentenceScore, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of sentences must be positive, got %d", n)
+	}
+
+	contentType := ContentTypeAuto
+	if name, ok := result.Metadata["content_type"].(string); ok {
+		contentType = ContentType(name)
+	}
+	if contentType == ContentTypeAuto {
+		contentType, _ = ClassifyContent(text)
+	}
+
+	outliers := make(map[int]bool)
+	if detail, ok := result.Details["embedding"]; ok {
+		if indices, ok := detail.Metadata["outlier_sentences"].([]int); ok {
+			for _, index := range indices {
+				outliers[index] = true
+			}
+		}
+	}
+
+	sentences := ad.currentTokenizer().Sentences(text)
+	scored := make([]models.SentenceScore, 0, len(sentences))
+	totalWeight := 0.0
+	cursor := 0
+	for i, sentence := range sentences {
+		offset := -1
+		if found := strings.Index(text[cursor:], sentence); found >= 0 {
+			offset = cursor + found
+			cursor = offset + len(sentence)
+		}
+		if IsEffectivelyEmpty(sentence) {
+			continue
+		}
+
+		analysis, err := ad.analyze(context.Background(), sentence, contentType, Selection{})
+		if err != nil {
+			return nil, fmt.Errorf("analysis of sentence %d failed: %w", i, err)
+		}
+		scored = append(scored, models.SentenceScore{
+			Index:      i,
+			Offset:     offset,
+			Length:     len(sentence),
+			Text:       sentence,
+			Score:      analysis.Score,
+			Confidence: analysis.Confidence,
+			Outlier:    outliers[i],
+		})
+		totalWeight += float64(len(sentence)) * analysis.Confidence
+	}
+
+	if totalWeight > 0 {
+		for i := range scored {
+			scored[i].Contribution = scored[i].Score * float64(scored[i].Length) * scored[i].Confidence / totalWeight
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].Contribution != scored[j].Contribution {
+			return scored[i].Contribution > scored[j].Contribution
+		}
+		return scored[i].Outlier && !scored[j].Outlier
+	})
+	if len(scored) > n {
+		scored = scored[:n]
+	}
+	return scored, nil
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// SeverityBands holds the score each severity level must exceed, matching how
+// AnomalyThreshold is applied. Scores at or below Low map to SeverityNone.
+// Results whose confidence is below MinConfidence are downgraded one level,
+// since a high score the analyzers are unsure of should not trigger the same
+// response as a confident one.
+type SeverityBands struct {
+	Low           float64
+	Medium        float64
+	High          float64
+	Critical      float64
+	MinConfidence float64
+}
+
+// DefaultSeverityBands returns bands aligned with AnomalyThreshold: results
+// flagged as anomalous start at medium severity
+func DefaultSeverityBands() SeverityBands {
+	return SeverityBands{
+		Low:           0.5,
+		Medium:        AnomalyThreshold,
+		High:          0.85,
+		Critical:      0.95,
+		MinConfidence: 0.3,
+	}
+}
+
+// Validate checks that the bands lie within [0, 1] and are strictly ascending
+func (b SeverityBands) Validate() error {
+	bands := []float64{b.Low, b.Medium, b.High, b.Critical}
+	for i, band := range bands {
+		if band < 0 || band > 1 {
+			return fmt.Errorf("severity band %f is outside [0, 1]", band)
+		}
+		if i > 0 && band <= bands[i-1] {
+			return fmt.Errorf("severity bands must be strictly ascending: %v", bands)
+		}
+	}
+	if b.MinConfidence < 0 || b.MinConfidence > 1 {
+		return fmt.Errorf("severity minimum confidence %f is outside [0, 1]", b.MinConfidence)
+	}
+	return nil
+}
+
+// Classify buckets a score and confidence into a severity level
+func (b SeverityBands) Classify(score, confidence float64) models.Severity {
+	levels := []models.Severity{
+		models.SeverityNone,
+		models.SeverityLow,
+		models.SeverityMedium,
+		models.SeverityHigh,
+		models.SeverityCritical,
+	}
+
+	level := 0
+	for i, band := range []float64{b.Low, b.Medium, b.High, b.Critical} {
+		if score > band {
+			level = i + 1
+		}
+	}
+
+	if level > 0 && confidence < b.MinConfidence {
+		level--
+	}
+
+	return levels[level]
+}
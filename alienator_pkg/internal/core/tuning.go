@@ -0,0 +1,249 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Objectives a decision threshold can be tuned for
+const (
+	ObjectiveF1     = "f1"     // harmonic mean of precision and recall
+	ObjectiveYouden = "youden" // Youden's J: true positive rate + true negative rate - 1
+	ObjectiveCost   = "cost"   // total cost of the errors, see TuningCosts
+)
+
+// LabeledSample is a text whose verdict is known, for tuning. An empty
+// ContentType leaves it to the detector.
+type LabeledSample struct {
+	Text        string      `json:"text"`
+	Anomalous   bool        `json:"anomalous"`
+	ContentType ContentType `json:"content_type,omitempty"`
+}
+
+// ReadLabeledSamples reads labeled samples as JSON lines, one object per
+// line; blank lines are skipped
+func ReadLabeledSamples(r io.Reader) ([]LabeledSample, error) {
+	var samples []LabeledSample
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var sample LabeledSample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// ScoredSample is the detector's score for a sample whose verdict is known
+type ScoredSample struct {
+	Score     float64
+	Anomalous bool
+}
+
+// TuningCosts weigh the errors of ObjectiveCost: a flagged normal text
+// costs FalsePositive, a missed anomalous one FalseNegative
+type TuningCosts struct {
+	FalsePositive float64
+	FalseNegative float64
+}
+
+// DefaultTuningCosts weighs both errors the same
+func DefaultTuningCosts() TuningCosts {
+	return TuningCosts{FalsePositive: 1, FalseNegative: 1}
+}
+
+// Validate checks that both costs are positive
+func (c TuningCosts) Validate() error {
+	if c.FalsePositive <= 0 || c.FalseNegative <= 0 {
+		return fmt.Errorf("tuning costs must be positive, got false positive %g and false negative %g", c.FalsePositive, c.FalseNegative)
+	}
+	return nil
+}
+
+// ThresholdPoint is the confusion matrix of the labeled set at one
+// candidate threshold, and the objectives computed from it
+type ThresholdPoint struct {
+	Threshold float64 `json:"threshold"`
+	TP        int     `json:"true_positives"`
+	FP        int     `json:"false_positives"`
+	TN        int     `json:"true_negatives"`
+	FN        int     `json:"false_negatives"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Youden    float64 `json:"youden"`
+	Cost      float64 `json:"cost"`
+}
+
+// ThresholdTuning is the outcome of a threshold sweep: the best point for
+// the objective, and every candidate swept in ascending threshold order
+type ThresholdTuning struct {
+	Objective string           `json:"objective"`
+	Costs     TuningCosts      `json:"costs"`
+	Samples   int              `json:"samples"`
+	Best      ThresholdPoint   `json:"best"`
+	Curve     []ThresholdPoint `json:"curve"`
+}
+
+// TuneScores sweeps the decision thresholds that separate the scores
+// differently, the midpoints between consecutive distinct scores, and picks
+// the one optimizing the objective. A text is flagged when its score is
+// above the threshold, as in detection. Thresholds stay strictly between 0
+// and 1; ties go to the higher threshold, flagging less. Both verdicts must
+// be present in the set.
+func TuneScores(samples []ScoredSample, objective string, costs TuningCosts) (*ThresholdTuning, error) {
+	switch objective {
+	case ObjectiveF1, ObjectiveYouden:
+	case ObjectiveCost:
+		if err := costs.Validate(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown objective %q (expected %s, %s or %s)", objective, ObjectiveF1, ObjectiveYouden, ObjectiveCost)
+	}
+	positives := 0
+	for _, sample := range samples {
+		if sample.Anomalous {
+			positives++
+		}
+	}
+	if positives == 0 || positives == len(samples) {
+		return nil, errors.New("the labeled set must contain both anomalous and normal samples")
+	}
+
+	tuning := &ThresholdTuning{Objective: objective, Costs: costs, Samples: len(samples)}
+	best := -1
+	for _, threshold := range candidateThresholds(samples) {
+		point := confusionAt(samples, threshold, costs)
+		tuning.Curve = append(tuning.Curve, point)
+		if best < 0 || objectiveValue(point, objective) >= objectiveValue(tuning.Curve[best], objective) {
+			best = len(tuning.Curve) - 1
+		}
+	}
+	tuning.Best = tuning.Curve[best]
+	return tuning, nil
+}
+
+// candidateThresholds returns the midpoints between consecutive distinct
+// scores, and between 0 or 1 and the extreme scores, in ascending order
+func candidateThresholds(samples []ScoredSample) []float64 {
+	scores := make([]float64, 0, len(samples)+2)
+	scores = append(scores, 0)
+	for _, sample := range samples {
+		scores = append(scores, math.Max(0, math.Min(1, sample.Score)))
+	}
+	scores = append(scores, 1)
+	sort.Float64s(scores)
+
+	candidates := make([]float64, 0, len(scores))
+	for i := 1; i < len(scores); i++ {
+		if scores[i] > scores[i-1] {
+			candidates = append(candidates, (scores[i-1]+scores[i])/2)
+		}
+	}
+	return candidates
+}
+
+// confusionAt computes the confusion matrix and objectives at threshold
+func confusionAt(samples []ScoredSample, threshold float64, costs TuningCosts) ThresholdPoint {
+	point := ThresholdPoint{Threshold: threshold}
+	for _, sample := range samples {
+		flagged := sample.Score > threshold
+		switch {
+		case flagged && sample.Anomalous:
+			point.TP++
+		case flagged:
+			point.FP++
+		case sample.Anomalous:
+			point.FN++
+		default:
+			point.TN++
+		}
+	}
+
+	if point.TP+point.FP > 0 {
+		point.Precision = float64(point.TP) / float64(point.TP+point.FP)
+	}
+	point.Recall = float64(point.TP) / float64(point.TP+point.FN)
+	point.F1 = 2 * float64(point.TP) / float64(2*point.TP+point.FP+point.FN)
+	specificity := float64(point.TN) / float64(point.TN+point.FP)
+	point.Youden = point.Recall + specificity - 1
+	point.Cost = float64(point.FP)*costs.FalsePositive + float64(point.FN)*costs.FalseNegative
+	return point
+}
+
+// objectiveValue returns how good point is for the objective, higher being
+// better
+func objectiveValue(point ThresholdPoint, objective string) float64 {
+	switch objective {
+	case ObjectiveYouden:
+		return point.Youden
+	case ObjectiveCost:
+		return -point.Cost
+	default:
+		return point.F1
+	}
+}
+
+// SetTuningCosts replaces the error costs used by ObjectiveCost
+func (ad *AnomalyDetector) SetTuningCosts(costs TuningCosts) error {
+	if err := costs.Validate(); err != nil {
+		return err
+	}
+	ad.settingsMu.Lock()
+	ad.tuningCosts = costs
+	ad.settingsMu.Unlock()
+	return nil
+}
+
+// tuningCostsPolicy returns the current error costs
+func (ad *AnomalyDetector) tuningCostsPolicy() TuningCosts {
+	ad.settingsMu.RLock()
+	defer ad.settingsMu.RUnlock()
+	return ad.tuningCosts
+}
+
+// TuneThreshold scores every labeled sample and sweeps the decision
+// thresholds over the scores, see TuneScores. ObjectiveCost weighs the
+// errors with the configured tuning costs. The samples are not added to the
+// normalization baselines.
+func (ad *AnomalyDetector) TuneThreshold(labeled []LabeledSample, objective string) (*ThresholdTuning, error) {
+	scored := make([]ScoredSample, 0, len(labeled))
+	for i, sample := range labeled {
+		contentType, err := ParseContentType(string(sample.ContentType))
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: %w", i+1, err)
+		}
+		result, err := ad.analyze(context.Background(), sample.Text, contentType, Selection{})
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: analysis failed: %w", i+1, err)
+		}
+		scored = append(scored, ScoredSample{Score: result.Score, Anomalous: sample.Anomalous})
+	}
+	return TuneScores(scored, objective, ad.tuningCostsPolicy())
+}
+
+// AutoTuneThreshold returns the decision threshold that best separates the
+// labeled samples for the objective, see TuneThreshold
+func (ad *AnomalyDetector) AutoTuneThreshold(labeled []LabeledSample, objective string) (float64, error) {
+	tuning, err := ad.TuneThreshold(labeled, objective)
+	if err != nil {
+		return 0, err
+	}
+	return tuning.Best.Threshold, nil
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+)
+
+// WindowConfig controls windowed analysis, which scores consecutive windows
+// of a text separately to find out whether it was written by one author or
+// partly by a person and partly by a model
+type WindowConfig struct {
+	WindowSize int // characters per window
+	// MinWindows is the number of windows needed before the spread of their
+	// scores says anything about co-authorship
+	MinWindows int
+	// MixedSpread is the standard deviation of window scores at which a text
+	// is considered certainly co-authored; smaller spreads scale linearly
+	MixedSpread float64
+	// AIThreshold is the window score above which a window counts as
+	// machine-written in the AI fraction
+	AIThreshold float64
+}
+
+// DefaultWindowConfig returns a configuration scoring 1k character windows
+func DefaultWindowConfig() WindowConfig {
+	return WindowConfig{
+		WindowSize:  1000,
+		MinWindows:  3,
+		MixedSpread: 0.2,
+		AIThreshold: AnomalyThreshold,
+	}
+}
+
+// Validate checks that the window configuration is usable
+func (c WindowConfig) Validate() error {
+	if c.WindowSize <= 0 {
+		return fmt.Errorf("window size must be positive, got %d", c.WindowSize)
+	}
+	if c.MinWindows < 2 {
+		return fmt.Errorf("at least 2 windows are needed to compare scores, got %d", c.MinWindows)
+	}
+	if c.MixedSpread <= 0 {
+		return fmt.Errorf("mixed spread must be positive, got %f", c.MixedSpread)
+	}
+	if c.AIThreshold < 0 || c.AIThreshold > 1 {
+		return fmt.Errorf("AI threshold %f is outside [0, 1]", c.AIThreshold)
+	}
+	return nil
+}
+
+// TextWindow is one window of a text, by character offset
+type TextWindow struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"-"`
+}
+
+// WindowResult is the analysis of a single window
+type WindowResult struct {
+	Index  int                   `json:"index"`
+	Start  int                   `json:"start"`
+	End    int                   `json:"end"`
+	Result *models.AnomalyResult `json:"result"`
+}
+
+// CoAuthorship estimates how much of a text is machine-written. Likelihood
+// grows with the spread of window scores: one author, human or not, writes
+// evenly, while a partly edited text alternates between low and high scoring
+// stretches. AIFraction is the length-weighted share of windows scoring above
+// the AI threshold.
+type CoAuthorship struct {
+	Likelihood  float64 `json:"likelihood"`
+	AIFraction  float64 `json:"ai_fraction"`
+	ScoreStdDev float64 `json:"score_std_dev"`
+	Windows     int     `json:"windows"`
+	// Reliable is false when the text had too few windows for the spread to
+	// be meaningful, in which case Likelihood is zero
+	Reliable bool `json:"reliable"`
+}
+
+// WindowedResult holds per-window scores, the co-authorship estimate and an
+// aggregate over the whole text
+type WindowedResult struct {
+	Windows      []WindowResult        `json:"windows"`
+	CoAuthorship *CoAuthorship         `json:"co_authorship"`
+	Aggregate    *models.AnomalyResult `json:"aggregate"`
+}
+
+// SplitWindows cuts text into consecutive windows of about size characters,
+// moving each cut back to the previous whitespace so that words stay whole.
+// Windows without letters or digits are dropped.
+func SplitWindows(text string, size int) []TextWindow {
+	runes := []rune(text)
+	windows := make([]TextWindow, 0, len(runes)/size+1)
+
+	for start := 0; start < len(runes); {
+		end := start + size
+		if end >= len(runes) {
+			end = len(runes)
+		} else {
+			// Cut at whitespace in the second half of the window, if any
+			for cut := end; cut > start+size/2; cut-- {
+				if unicode.IsSpace(runes[cut-1]) {
+					end = cut
+					break
+				}
+			}
+		}
+
+		window := strings.TrimSpace(string(runes[start:end]))
+		if !IsEffectivelyEmpty(window) {
+			windows = append(windows, TextWindow{Start: start, End: end, Text: window})
+		}
+		start = end
+	}
+	return windows
+}
+
+// AnalyzeTextWindowed scores consecutive windows of text separately and
+// estimates from the spread of their scores whether the text is co-authored.
+// The aggregate weighs windows by length and confidence, like the turns of a
+// conversation, and carries the estimate in its "co_authorship" metadata.
+// Only the aggregate is published to event subscribers.
+func (ad *AnomalyDetector) AnalyzeTextWindowed(text string, contentType ContentType, cfg WindowConfig) (*WindowedResult, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if IsEffectivelyEmpty(text) {
+		empty := ad.emptyInputResult(contentType)
+		return &WindowedResult{Windows: []WindowResult{}, CoAuthorship: &CoAuthorship{}, Aggregate: empty}, nil
+	}
+
+	start := time.Now()
+	if contentType == ContentTypeAuto {
+		// Classify the whole text once so that every window uses one profile
+		contentType, _ = ClassifyContent(text)
+	}
+
+	windows := SplitWindows(text, cfg.WindowSize)
+	windowed := &WindowedResult{Windows: make([]WindowResult, 0, len(windows))}
+	overall := &turnAggregate{}
+	scores := make([]float64, 0, len(windows))
+	weights := make([]float64, 0, len(windows))
+
+	for i, window := range windows {
+		result, err := ad.analyze(context.Background(), window.Text, contentType, Selection{})
+		if err != nil {
+			return nil, fmt.Errorf("analysis of window %d failed: %w", i, err)
+		}
+		windowed.Windows = append(windowed.Windows, WindowResult{Index: i, Start: window.Start, End: window.End, Result: result})
+
+		length := float64(window.End - window.Start)
+		overall.add(result, length)
+		scores = append(scores, result.Score)
+		weights = append(weights, length)
+	}
+
+	windowed.CoAuthorship = estimateCoAuthorship(scores, weights, cfg)
+
+	score, confidence := overall.scores()
+	threshold := ad.decisionThresholds().For(contentType)
+	windowed.Aggregate = &models.AnomalyResult{
+		Score:       score,
+		Confidence:  confidence,
+		IsAnomalous: score > threshold,
+		Severity:    ad.severityBands().Classify(score, confidence),
+		Outcome:     models.OutcomeAnalyzed,
+		Details:     make(map[string]*models.AnalysisResult),
+		Metadata: map[string]interface{}{
+			"content_type":  string(contentType),
+			"threshold":     threshold,
+			"windows":       len(windowed.Windows),
+			"window_size":   cfg.WindowSize,
+			"co_authorship": windowed.CoAuthorship,
+		},
+		Timestamp: time.Now(),
+	}
+	setLanguage(windowed.Aggregate, ad.sharedLanguage(tokenizer.New(ad.currentTokenizer(), text)))
+
+	windowed.Aggregate = ad.finishResult(text, windowed.Aggregate, start)
+	return windowed, nil
+}
+
+// estimateCoAuthorship derives the co-authored likelihood and AI fraction
+// from length-weighted window scores
+func estimateCoAuthorship(scores, weights []float64, cfg WindowConfig) *CoAuthorship {
+	estimate := &CoAuthorship{Windows: len(scores)}
+
+	totalWeight, aiWeight, mean := 0.0, 0.0, 0.0
+	for i, score := range scores {
+		totalWeight += weights[i]
+		mean += score * weights[i]
+		if score > cfg.AIThreshold {
+			aiWeight += weights[i]
+		}
+	}
+	if totalWeight == 0 {
+		return estimate
+	}
+	mean /= totalWeight
+	estimate.AIFraction = aiWeight / totalWeight
+
+	variance := 0.0
+	for i, score := range scores {
+		variance += weights[i] * (score - mean) * (score - mean)
+	}
+	estimate.ScoreStdDev = math.Sqrt(variance / totalWeight)
+
+	estimate.Reliable = len(scores) >= cfg.MinWindows
+	// Scores that vary without ever crossing the threshold, or while staying
+	// above it, are one author's noise rather than two authors
+	if estimate.Reliable && estimate.AIFraction > 0 && estimate.AIFraction < 1 {
+		estimate.Likelihood = math.Min(1, estimate.ScoreStdDev/cfg.MixedSpread)
+	}
+	return estimate
+}
@@ -83,7 +83,7 @@ type AnomalyDetectionRequest struct {
 // TextAnalysisRequest represents text analysis request
 type TextAnalysisRequest struct {
 	BaseRequest
-	Text     string            `json:"text" validate:"required,min=1,max=10000"`
+	Text     string            `json:"text" validate:"max=10000"`
 	Language string            `json:"language,omitempty" validate:"omitempty,len=2"`
 	Options  map[string]string `json:"options,omitempty"`
 	Priority int               `json:"priority,omitempty" validate:"omitempty,min=1,max=10"`
@@ -35,12 +35,9 @@ type ValidationErrorResponse struct {
 	ValidationErrors []ValidationError `json:"validation_errors,omitempty"`
 }
 
-// ValidationError represents a single validation error
-type ValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
-	Value   interface{} `json:"value,omitempty"`
-}
+// ValidationError represents a single validation error. It shares its shape
+// with the field errors carried in models.APIResponse.
+type ValidationError = models.FieldError
 
 // PaginatedResponse represents a paginated response
 type PaginatedResponse struct {
@@ -2,6 +2,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -50,6 +51,40 @@ const (
 	InsufficientData  ErrorCode = "INSUFFICIENT_DATA"
 )
 
+// Error categories. Services wrap these (with %w) so that callers can tell
+// what went wrong with errors.Is rather than by matching messages; CodeOf
+// maps an error back to the code of its category.
+var (
+	ErrNotFound     = stderrors.New("not found")
+	ErrConflict     = stderrors.New("conflict")
+	ErrInvalidInput = stderrors.New("invalid input")
+	ErrUnauthorized = stderrors.New("unauthorized")
+	ErrForbidden    = stderrors.New("forbidden")
+)
+
+// categoryCodes lists the code reported for each error category
+var categoryCodes = []struct {
+	category error
+	code     ErrorCode
+}{
+	{ErrNotFound, NotFound},
+	{ErrConflict, Conflict},
+	{ErrInvalidInput, BadRequest},
+	{ErrUnauthorized, Unauthorized},
+	{ErrForbidden, Forbidden},
+}
+
+// CodeOf returns the code of the category err belongs to, and false for
+// errors outside the categories
+func CodeOf(err error) (ErrorCode, bool) {
+	for _, c := range categoryCodes {
+		if stderrors.Is(err, c.category) {
+			return c.code, true
+		}
+	}
+	return "", false
+}
+
 // APIError represents a structured API error
 type APIError struct {
 	Code       ErrorCode              `json:"code"`
@@ -69,7 +104,12 @@ func (e *APIError) Error() string {
 
 // HTTPStatus returns the appropriate HTTP status code for the error
 func (e *APIError) HTTPStatus() int {
-	switch e.Code {
+	return e.Code.HTTPStatus()
+}
+
+// HTTPStatus returns the appropriate HTTP status code for errors with the code
+func (code ErrorCode) HTTPStatus() int {
+	switch code {
 	case BadRequest, ValidationFailed, InvalidAlgorithm, InsufficientData:
 		return http.StatusBadRequest
 	case Unauthorized, InvalidCredentials, TokenExpired, TokenInvalid, RefreshTokenInvalid:
@@ -10,6 +10,8 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/ruvnet/alienator/proto/vibecast/v1"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
 	"github.com/ruvnet/alienator/internal/services"
 )
 
@@ -97,9 +99,19 @@ func (s *AnomalyServiceServer) DetectAnomaly(ctx context.Context, req *pb.Detect
 func (s *AnomalyServiceServer) AnalyzeText(ctx context.Context, req *pb.AnalyzeTextRequest) (*pb.AnalyzeTextResponse, error) {
 	s.logger.Debug("gRPC AnalyzeText request")
 
-	if req.Text == "" {
+	// Effectively empty text is a zero result, as in the REST API, rather
+	// than an invalid argument
+	if core.IsEffectivelyEmpty(req.Text) {
 		return &pb.AnalyzeTextResponse{
-			Status: createStatus(codes.InvalidArgument, "Text is required"),
+			Status: createSuccessStatus(),
+			Result: &pb.AnomalyResult{
+				Score:       0,
+				Confidence:  0,
+				IsAnomalous: false,
+				Timestamp:   time.Now(),
+				Details:     make(map[string]*pb.AnalysisResult),
+				Outcome:     string(models.OutcomeEmptyInput),
+			},
 		}, nil
 	}
 
@@ -113,6 +125,7 @@ func (s *AnomalyServiceServer) AnalyzeText(ctx context.Context, req *pb.AnalyzeT
 		IsAnomalous:  false,
 		Timestamp:    time.Now(),
 		Details:      make(map[string]*pb.AnalysisResult),
+		Outcome:      string(models.OutcomeAnalyzed),
 	}
 
 	// Add some mock analysis details
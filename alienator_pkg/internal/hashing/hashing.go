@@ -0,0 +1,132 @@
+// Package hashing computes the content hashes that decide whether two inputs
+// are "the same". Analysis cache keys and de-duplication fingerprints both
+// come from here so that they agree with each other.
+package hashing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/ruvnet/alienator/internal/config"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Hash algorithms
+const (
+	SHA256 = "sha256"
+	XXHash = "xxhash"
+)
+
+// Unicode normalization forms applied before hashing text
+const (
+	UnicodeNFC  = "nfc"
+	UnicodeNFKC = "nfkc"
+	UnicodeNone = "none"
+)
+
+// Hasher hashes text after normalizing it, and raw bytes as they are.
+// Digests are prefixed with the algorithm, e.g. "sha256:9f86…", so keys made
+// under different settings never compare equal.
+type Hasher struct {
+	algorithm          string
+	form               norm.Form
+	normalizeUnicode   bool
+	collapseWhitespace bool
+}
+
+// New creates a hasher from the hashing configuration
+func New(cfg config.HashingConfig) (*Hasher, error) {
+	h := &Hasher{collapseWhitespace: cfg.CollapseWhitespace}
+
+	switch cfg.Algorithm {
+	case SHA256, XXHash:
+		h.algorithm = cfg.Algorithm
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm %q (expected %s or %s)", cfg.Algorithm, SHA256, XXHash)
+	}
+
+	switch cfg.Unicode {
+	case UnicodeNFC:
+		h.form, h.normalizeUnicode = norm.NFC, true
+	case UnicodeNFKC:
+		h.form, h.normalizeUnicode = norm.NFKC, true
+	case UnicodeNone:
+	default:
+		return nil, fmt.Errorf("unsupported unicode normalization %q (expected %s, %s or %s)", cfg.Unicode, UnicodeNFC, UnicodeNFKC, UnicodeNone)
+	}
+
+	return h, nil
+}
+
+// Algorithm returns the name of the hash algorithm
+func (h *Hasher) Algorithm() string {
+	return h.algorithm
+}
+
+// Normalize returns text in the form it is hashed: Unicode-normalized and,
+// if configured, with leading and trailing whitespace removed and every other
+// run of whitespace, line breaks included, replaced by a single space
+func (h *Hasher) Normalize(text string) string {
+	if h.normalizeUnicode {
+		text = h.form.String(text)
+	}
+	if h.collapseWhitespace {
+		text = strings.Join(strings.FieldsFunc(text, unicode.IsSpace), " ")
+	}
+	return text
+}
+
+// ContentHash hashes text after normalizing it
+func (h *Hasher) ContentHash(text string) string {
+	return h.Sum([]byte(h.Normalize(text)))
+}
+
+// Sum hashes data as it is
+func (h *Hasher) Sum(data []byte) string {
+	if h.algorithm == XXHash {
+		return fmt.Sprintf("%s:%016x", XXHash, xxhash.Sum64(data))
+	}
+	sum := sha256.Sum256(data)
+	return SHA256 + ":" + hex.EncodeToString(sum[:])
+}
+
+var defaultHasher atomic.Pointer[Hasher]
+
+func init() {
+	h, err := New(config.Defaults().Hashing)
+	if err != nil {
+		panic(err)
+	}
+	defaultHasher.Store(h)
+}
+
+// Configure replaces the hasher used by ContentHash and Sum. Call it once at
+// startup: keys computed before and after a change do not match.
+func Configure(cfg config.HashingConfig) error {
+	h, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	defaultHasher.Store(h)
+	return nil
+}
+
+// Default returns the hasher used by ContentHash and Sum
+func Default() *Hasher {
+	return defaultHasher.Load()
+}
+
+// ContentHash hashes text with the configured hasher after normalizing it
+func ContentHash(text string) string {
+	return Default().ContentHash(text)
+}
+
+// Sum hashes data as it is with the configured hasher
+func Sum(data []byte) string {
+	return Default().Sum(data)
+}
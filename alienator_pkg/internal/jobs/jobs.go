@@ -0,0 +1,277 @@
+// Package jobs runs long operations in the worker. The API queues a job in
+// a Store; a Runner claims it, runs the Handler registered for its kind and
+// records progress and the outcome for the API to report. A job can be
+// cancelled while queued or running.
+//
+// A claimed job is leased to its runner, which renews the lease at every
+// poll interval while the job runs. The pollers of every runner reclaim jobs
+// whose lease lapsed, so the job of a worker that crashed is requeued and run
+// again rather than lost.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Progress reports how far a job has got, from 0 to 1, and what it is doing
+type Progress func(fraction float64, message string)
+
+// Handler runs a job and returns its result, which is stored as JSON
+type Handler func(ctx context.Context, job *models.Job, progress Progress) (interface{}, error)
+
+// Runner polls the store for queued jobs and runs them
+type Runner struct {
+	store  Store
+	cfg    config.JobsConfig
+	logger *zap.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewRunner creates a runner with no handlers registered
+func NewRunner(store Store, cfg config.JobsConfig, logger *zap.Logger) *Runner {
+	return &Runner{
+		store:    store,
+		cfg:      cfg,
+		logger:   logger,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Register sets the handler for a kind of job. Only registered kinds are
+// claimed, so a worker never takes a job it cannot run.
+func (r *Runner) Register(kind string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[kind] = handler
+}
+
+// Kinds returns the registered job kinds, sorted
+func (r *Runner) Kinds() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	kinds := make([]string, 0, len(r.handlers))
+	for kind := range r.handlers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Start runs the configured number of pollers until ctx is cancelled and
+// waits for the jobs they are running to return
+func (r *Runner) Start(ctx context.Context) {
+	workers := r.cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.poll(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (r *Runner) poll(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.reclaim(ctx)
+
+		// Drain the queue before waiting for the next tick
+		for ctx.Err() == nil {
+			ran, err := r.RunNext(ctx)
+			if err != nil {
+				r.logger.Error("Failed to claim job", zap.Error(err))
+				break
+			}
+			if !ran {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reclaim requeues the jobs of the registered kinds left by stopped workers
+func (r *Runner) reclaim(ctx context.Context) {
+	kinds := r.Kinds()
+	if len(kinds) == 0 || ctx.Err() != nil {
+		return
+	}
+	requeued, err := r.store.Reclaim(ctx, kinds)
+	if err != nil {
+		r.logger.Error("Failed to reclaim abandoned jobs", zap.Error(err))
+	}
+	if requeued > 0 {
+		r.logger.Warn("Requeued jobs abandoned by their worker", zap.Int("jobs", requeued))
+	}
+}
+
+// RunNext claims one queued job and runs it to completion, reporting whether
+// there was a job to run
+func (r *Runner) RunNext(ctx context.Context) (bool, error) {
+	kinds := r.Kinds()
+	if len(kinds) == 0 {
+		return false, nil
+	}
+
+	job, err := r.store.Claim(ctx, kinds)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	r.run(ctx, job)
+	return true, nil
+}
+
+func (r *Runner) run(ctx context.Context, job *models.Job) {
+	logger := r.logger.With(zap.String("job_id", job.ID.String()), zap.String("kind", job.Kind))
+	start := time.Now()
+
+	// Cancelled before any worker got to it
+	if requested, err := r.store.CancelRequested(ctx, job.ID); err != nil {
+		logger.Warn("Failed to check for job cancellation", zap.Error(err))
+	} else if requested {
+		job.Status = models.JobCancelled
+		job.Message = "cancelled before it started"
+		logger.Info("Job cancelled")
+		if err := r.store.Finish(context.Background(), job); err != nil {
+			logger.Error("Failed to record job outcome", zap.Error(err))
+		}
+		return
+	}
+	logger.Info("Job started")
+
+	jobCtx, cancelJob := context.WithCancel(ctx)
+	defer cancelJob()
+	var cancelled atomic.Bool
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		r.heartbeat(jobCtx, job, func() {
+			cancelled.Store(true)
+			cancelJob()
+		}, logger)
+	}()
+
+	progress := func(fraction float64, message string) {
+		job.Progress = math.Max(0, math.Min(1, fraction))
+		job.Message = message
+		if err := r.store.Update(ctx, job); err != nil {
+			logger.Warn("Failed to record job progress", zap.Error(err))
+		}
+	}
+
+	result, err := r.invoke(jobCtx, job, progress)
+	cancelJob()
+	<-watched
+	if err == nil && result != nil {
+		encoded, encodeErr := json.Marshal(result)
+		if encodeErr != nil {
+			err = fmt.Errorf("failed to encode job result: %w", encodeErr)
+		} else {
+			job.Result = encoded
+		}
+	}
+
+	switch {
+	case err != nil && cancelled.Load():
+		job.Status = models.JobCancelled
+		job.Message = "cancelled while running"
+		logger.Info("Job cancelled", zap.Duration("duration", time.Since(start)))
+	case err != nil:
+		if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			err = errors.New("interrupted by worker shutdown")
+		}
+		job.Status = models.JobFailed
+		job.Error = err.Error()
+		logger.Error("Job failed", zap.Duration("duration", time.Since(start)), zap.Error(err))
+	default:
+		job.Status = models.JobSucceeded
+		job.Progress = 1
+		logger.Info("Job succeeded", zap.Duration("duration", time.Since(start)))
+	}
+
+	// Record the outcome even when the worker is shutting down
+	if err := r.store.Finish(context.Background(), job); err != nil {
+		logger.Error("Failed to record job outcome", zap.Error(err))
+	}
+}
+
+// heartbeat renews the job's lease and calls cancel once the job is asked to
+// stop, at the poll interval until ctx is done
+func (r *Runner) heartbeat(ctx context.Context, job *models.Job, cancel func(), logger *zap.Logger) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.store.Renew(ctx, job.ID); errors.Is(err, ErrLeaseLost) {
+			logger.Warn("Job lease lapsed; it may be run again by another worker")
+		} else if err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to renew job lease", zap.Error(err))
+		}
+
+		requested, err := r.store.CancelRequested(ctx, job.ID)
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to check for job cancellation", zap.Error(err))
+		}
+		if requested {
+			logger.Info("Job cancellation requested")
+			cancel()
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// invoke runs the job's handler, turning a panic into an error so one bad job
+// cannot take down the worker
+func (r *Runner) invoke(ctx context.Context, job *models.Job, progress Progress) (result interface{}, err error) {
+	r.mu.RLock()
+	handler, ok := r.handlers[job.Kind]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return handler(ctx, job, progress)
+}
@@ -0,0 +1,383 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// listPageSize is how many job IDs List reads from the index at a time
+const listPageSize = 100
+
+// claimScript moves the oldest job ID of a queue to its processing list and
+// leases the job for ARGV[2] milliseconds, in one step so that Reclaim never
+// sees a claimed job without its lease
+var claimScript = redis.NewScript(`
+local id = redis.call('RPOPLPUSH', KEYS[1], KEYS[2])
+if id then
+	redis.call('SET', ARGV[1] .. id, 1, 'PX', ARGV[2])
+end
+return id
+`)
+
+// RedisStore keeps each job as a JSON document, with a list of queued job IDs
+// per kind and a sorted set of every job ID by creation time. Finished jobs
+// expire after the configured retention; the index drops them lazily.
+//
+// Claimed job IDs move to a processing list per kind, leased to the worker
+// running them for the lease timeout. Workers renew the lease while a job
+// runs and remove the ID when it finishes, so an ID left in a processing
+// list past its lease belongs to a worker that stopped, and Reclaim requeues
+// it.
+type RedisStore struct {
+	client    *redis.Client
+	prefix    string
+	retention time.Duration
+	lease     time.Duration
+}
+
+// NewRedisStore creates a job store on a Redis client
+func NewRedisStore(client *redis.Client, cfg config.JobsConfig) *RedisStore {
+	return &RedisStore{
+		client:    client,
+		prefix:    cfg.KeyPrefix,
+		retention: cfg.Retention,
+		lease:     cfg.LeaseTimeout,
+	}
+}
+
+func (s *RedisStore) jobKey(id string) string          { return s.prefix + "job:" + id }
+func (s *RedisStore) cancelKey(id string) string       { return s.prefix + "cancel:" + id }
+func (s *RedisStore) leaseKey(id string) string        { return s.prefix + "lease:" + id }
+func (s *RedisStore) queueKey(kind string) string      { return s.prefix + "queue:" + kind }
+func (s *RedisStore) processingKey(kind string) string { return s.prefix + "processing:" + kind }
+func (s *RedisStore) indexKey() string                 { return s.prefix + "index" }
+
+// Create implements Store
+func (s *RedisStore) Create(ctx context.Context, job *models.Job) error {
+	now := time.Now().UTC()
+	job.ID = uuid.New()
+	job.Status = models.JobQueued
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	id := job.ID.String()
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.jobKey(id), encoded, 0)
+		pipe.ZAdd(ctx, s.indexKey(), &redis.Z{Score: float64(now.UnixNano()), Member: id})
+		pipe.LPush(ctx, s.queueKey(job.Kind), id)
+		return nil
+	})
+	return err
+}
+
+// Get implements Store
+func (s *RedisStore) Get(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	var encoded *redis.StringCmd
+	var cancelled *redis.IntCmd
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		encoded = pipe.Get(ctx, s.jobKey(id.String()))
+		cancelled = pipe.Exists(ctx, s.cancelKey(id.String()))
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := decodeJob([]byte(encoded.Val()))
+	if err != nil {
+		return nil, err
+	}
+	job.CancelRequested = !job.Done() && cancelled.Val() > 0
+	return job, nil
+}
+
+// List implements Store
+func (s *RedisStore) List(ctx context.Context, filter ListFilter) ([]*models.Job, error) {
+	var jobs []*models.Job
+	for start := int64(0); filter.Limit <= 0 || len(jobs) < filter.Limit; start += listPageSize {
+		ids, err := s.client.ZRevRange(ctx, s.indexKey(), start, start+listPageSize-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = s.jobKey(id)
+		}
+		values, err := s.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		var expired []interface{}
+		for i, value := range values {
+			encoded, ok := value.(string)
+			if !ok {
+				expired = append(expired, ids[i])
+				continue
+			}
+			job, err := decodeJob([]byte(encoded))
+			if err != nil {
+				return nil, err
+			}
+			if (filter.Status == "" || job.Status == filter.Status) && (filter.Kind == "" || job.Kind == filter.Kind) {
+				jobs = append(jobs, job)
+				if filter.Limit > 0 && len(jobs) == filter.Limit {
+					break
+				}
+			}
+		}
+		if len(expired) > 0 {
+			if err := s.client.ZRem(ctx, s.indexKey(), expired...).Err(); err != nil {
+				return nil, err
+			}
+			start -= int64(len(expired))
+		}
+	}
+	return jobs, nil
+}
+
+// Claim implements Store
+func (s *RedisStore) Claim(ctx context.Context, kinds []string) (*models.Job, error) {
+	for _, kind := range kinds {
+		for {
+			keys := []string{s.queueKey(kind), s.processingKey(kind)}
+			id, err := claimScript.Run(ctx, s.client, keys, s.leaseKey(""), s.lease.Milliseconds()).Text()
+			if errors.Is(err, redis.Nil) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			job, err := s.claimed(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if job == nil {
+				// Not a job, or one that expired: drop it
+				if err := s.release(ctx, kind, id); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			now := time.Now().UTC()
+			job.Status = models.JobRunning
+			job.StartedAt = &now
+			if err := s.save(ctx, job, 0); err != nil {
+				return nil, err
+			}
+			return job, nil
+		}
+	}
+	return nil, nil
+}
+
+// claimed returns the job with a claimed ID, or nil if there is none
+func (s *RedisStore) claimed(ctx context.Context, id string) (*models.Job, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, nil
+	}
+	job, err := s.Get(ctx, jobID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// release removes a job ID from its processing list along with its lease
+func (s *RedisStore) release(ctx context.Context, kind, id string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, s.processingKey(kind), 0, id)
+		pipe.Del(ctx, s.leaseKey(id))
+		return nil
+	})
+	return err
+}
+
+// Renew implements Store
+func (s *RedisStore) Renew(ctx context.Context, id uuid.UUID) error {
+	renewed, err := s.client.PExpire(ctx, s.leaseKey(id.String()), s.lease).Result()
+	if err != nil {
+		return err
+	}
+	if !renewed {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Reclaim implements Store. Each job is requeued in a transaction watching
+// its document, lease and processing list, so that a worker finishing or
+// renewing it at the same time leaves it alone.
+func (s *RedisStore) Reclaim(ctx context.Context, kinds []string) (int, error) {
+	requeued := 0
+	for _, kind := range kinds {
+		ids, err := s.client.LRange(ctx, s.processingKey(kind), 0, -1).Result()
+		if err != nil {
+			return requeued, err
+		}
+		for _, id := range ids {
+			ok, err := s.reclaim(ctx, kind, id)
+			if err != nil {
+				return requeued, err
+			}
+			if ok {
+				requeued++
+			}
+		}
+	}
+	return requeued, nil
+}
+
+// reclaim requeues one claimed job if its lease has lapsed
+func (s *RedisStore) reclaim(ctx context.Context, kind, id string) (bool, error) {
+	requeued := false
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		leased, err := tx.Exists(ctx, s.leaseKey(id)).Result()
+		if err != nil || leased > 0 {
+			return err
+		}
+		position, err := tx.LPos(ctx, s.processingKey(kind), id, redis.LPosArgs{}).Result()
+		if errors.Is(err, redis.Nil) || position < 0 {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		job, err := s.claimed(ctx, id)
+		if err != nil {
+			return err
+		}
+		var encoded []byte
+		if job != nil && !job.Done() {
+			job.Status = models.JobQueued
+			job.StartedAt = nil
+			job.Progress = 0
+			job.Message = "requeued after its worker stopped"
+			job.UpdatedAt = time.Now().UTC()
+			if encoded, err = json.Marshal(job); err != nil {
+				return fmt.Errorf("failed to encode job: %w", err)
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.LRem(ctx, s.processingKey(kind), 0, id)
+			if encoded != nil {
+				pipe.Set(ctx, s.jobKey(id), encoded, 0)
+				// Next in line, ahead of the jobs queued since
+				pipe.RPush(ctx, s.queueKey(kind), id)
+			}
+			return nil
+		})
+		requeued = err == nil && encoded != nil
+		return err
+	}, s.jobKey(id), s.leaseKey(id), s.processingKey(kind))
+	if errors.Is(err, redis.TxFailedErr) {
+		// Changed meanwhile; the next Reclaim looks again
+		return false, nil
+	}
+	return requeued, err
+}
+
+// Update implements Store
+func (s *RedisStore) Update(ctx context.Context, job *models.Job) error {
+	return s.save(ctx, job, 0)
+}
+
+// Finish implements Store
+func (s *RedisStore) Finish(ctx context.Context, job *models.Job) error {
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	job.CancelRequested = false
+	if err := s.save(ctx, job, s.retention); err != nil {
+		return err
+	}
+	id := job.ID.String()
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, s.processingKey(job.Kind), 0, id)
+		pipe.Del(ctx, s.leaseKey(id), s.cancelKey(id))
+		return nil
+	})
+	return err
+}
+
+// Cancel implements Store. A job removed from its queue cannot have been
+// claimed, so it is cancelled here; otherwise a worker holds it and is asked
+// to stop through a flag it polls.
+func (s *RedisStore) Cancel(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Done() {
+		return nil, ErrFinished
+	}
+
+	removed, err := s.client.LRem(ctx, s.queueKey(job.Kind), 0, id.String()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if removed > 0 {
+		job.Status = models.JobCancelled
+		job.Message = "cancelled before it started"
+		if err := s.Finish(ctx, job); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+
+	if err := s.client.Set(ctx, s.cancelKey(id.String()), 1, s.retention).Err(); err != nil {
+		return nil, err
+	}
+	job.CancelRequested = true
+	return job, nil
+}
+
+// CancelRequested implements Store
+func (s *RedisStore) CancelRequested(ctx context.Context, id uuid.UUID) (bool, error) {
+	n, err := s.client.Exists(ctx, s.cancelKey(id.String())).Result()
+	return n > 0, err
+}
+
+// save writes a job, expiring it after ttl unless ttl is zero. The cancel
+// flag lives in its own key so that saves never overwrite a cancellation.
+func (s *RedisStore) save(ctx context.Context, job *models.Job, ttl time.Duration) error {
+	job.UpdatedAt = time.Now().UTC()
+	stored := *job
+	stored.CancelRequested = false
+	encoded, err := json.Marshal(&stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	return s.client.Set(ctx, s.jobKey(job.ID.String()), encoded, ttl).Err()
+}
+
+func decodeJob(encoded []byte) (*models.Job, error) {
+	job := &models.Job{}
+	if err := json.Unmarshal(encoded, job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+	return job, nil
+}
@@ -0,0 +1,206 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// KindRetrain retrains the neural detector; its params are a models.RetrainRequest
+const KindRetrain = "retrain"
+
+// Series is one named run of consecutive values
+type Series struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+}
+
+// Dataset is the format of the retraining dataset file
+type Dataset struct {
+	Series []Series `json:"series"`
+}
+
+// Trainer is the model a retrain job trains. Train replaces what the model
+// learned before; a zero epochs uses the model's default. It reports the
+// fraction of training done through progress. When ctx's deadline passes,
+// Train may keep the best model trained so far rather than fail.
+type Trainer interface {
+	Train(ctx context.Context, series []Series, epochs int, progress func(fraction float64)) error
+}
+
+// ConvergenceReporter is implemented by trainers that report the mean loss
+// of the model they kept, and the 1-based epoch that produced it
+type ConvergenceReporter interface {
+	Convergence() (loss float64, epoch int)
+}
+
+// TrainingStore reads the stored results used by the "stored" source
+type TrainingStore interface {
+	ListNormalAnomalyData(since time.Time, limit int) ([]*models.AnomalyData, error)
+}
+
+// RetrainResult is the result recorded for a retrain job
+type RetrainResult struct {
+	Source         string  `json:"source"`
+	Records        int     `json:"records,omitempty"` // stored results read
+	Series         int     `json:"series"`
+	Points         int     `json:"points"`
+	Epochs         int     `json:"epochs,omitempty"`
+	Loss           float64 `json:"loss,omitempty"`            // mean loss of the model kept, when the trainer reports it
+	ConvergedEpoch int     `json:"converged_epoch,omitempty"` // epoch that produced the model kept
+	DurationMS     int64   `json:"duration_ms"`
+}
+
+// Progress given to each retraining stage
+const (
+	retrainLoaded  = 0.2
+	retrainTrained = 1.0
+)
+
+// RetrainHandler returns the handler for KindRetrain jobs
+func RetrainHandler(trainer Trainer, store TrainingStore, cfg config.RetrainConfig) Handler {
+	return func(ctx context.Context, job *models.Job, progress Progress) (interface{}, error) {
+		var req models.RetrainRequest
+		if len(job.Params) > 0 {
+			if err := json.Unmarshal(job.Params, &req); err != nil {
+				return nil, fmt.Errorf("invalid retrain parameters: %w", err)
+			}
+		}
+		start := time.Now()
+		result := RetrainResult{Source: req.Source, Epochs: req.Epochs}
+
+		progress(0, "loading training data")
+		var series []Series
+		switch req.Source {
+		case models.RetrainFromDataset:
+			if cfg.Dataset == "" {
+				return nil, errors.New("no retraining dataset is configured")
+			}
+			dataset, err := LoadDataset(cfg.Dataset)
+			if err != nil {
+				return nil, err
+			}
+			series = dataset.Series
+		case models.RetrainFromStored:
+			lookback := cfg.Lookback
+			if req.LookbackHours > 0 {
+				lookback = time.Duration(req.LookbackHours) * time.Hour
+			}
+			limit := cfg.MaxRecords
+			if req.MaxRecords > 0 {
+				limit = req.MaxRecords
+			}
+			records, err := store.ListNormalAnomalyData(time.Now().Add(-lookback), limit)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load stored results: %w", err)
+			}
+			result.Records = len(records)
+			series = StoredSeries(records)
+		default:
+			return nil, fmt.Errorf("unsupported retraining source %q", req.Source)
+		}
+
+		result.Series = len(series)
+		for _, s := range series {
+			result.Points += len(s.Values)
+		}
+		if result.Points == 0 {
+			return nil, errors.New("no training data found")
+		}
+
+		progress(retrainLoaded, fmt.Sprintf("training on %d points from %d series", result.Points, result.Series))
+		if cfg.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+			defer cancel()
+		}
+		err := trainer.Train(ctx, series, req.Epochs, func(fraction float64) {
+			progress(retrainLoaded+(retrainTrained-retrainLoaded)*fraction, "training")
+		})
+		if err != nil {
+			return nil, fmt.Errorf("training failed: %w", err)
+		}
+
+		if reporter, ok := trainer.(ConvergenceReporter); ok {
+			result.Loss, result.ConvergedEpoch = reporter.Convergence()
+		}
+		progress(retrainTrained, "training complete")
+		result.DurationMS = time.Since(start).Milliseconds()
+		return result, nil
+	}
+}
+
+// LoadDataset reads a retraining dataset file
+func LoadDataset(path string) (*Dataset, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retraining dataset: %w", err)
+	}
+	var dataset Dataset
+	if err := json.Unmarshal(encoded, &dataset); err != nil {
+		return nil, fmt.Errorf("failed to parse retraining dataset %s: %w", path, err)
+	}
+	for i, s := range dataset.Series {
+		for _, value := range s.Values {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return nil, fmt.Errorf("retraining dataset %s: series %d has a non-finite value", path, i)
+			}
+		}
+	}
+	return &dataset, nil
+}
+
+// StoredSeries turns stored results into one series per numeric input field,
+// in the order the results are given. Fields holding anything other than a
+// number are skipped.
+func StoredSeries(records []*models.AnomalyData) []Series {
+	values := make(map[string][]float64)
+	for _, record := range records {
+		for field, raw := range record.Data {
+			if value, ok := numericValue(raw); ok {
+				values[field] = append(values[field], value)
+			}
+		}
+	}
+
+	series := make([]Series, 0, len(values))
+	for field, fieldValues := range values {
+		series = append(series, Series{Name: field, Values: fieldValues})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Name < series[j].Name })
+	return series
+}
+
+func numericValue(raw interface{}) (float64, bool) {
+	var value float64
+	switch v := raw.(type) {
+	case float64:
+		value = v
+	case float32:
+		value = float64(v)
+	case int:
+		value = float64(v)
+	case int64:
+		value = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+		value = f
+	default:
+		return 0, false
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, true
+}
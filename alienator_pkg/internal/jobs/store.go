@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+var (
+	// ErrNotFound is returned for a job that does not exist or has expired
+	ErrNotFound = errors.New("job not found")
+	// ErrFinished is returned when cancelling a job that has already finished
+	ErrFinished = errors.New("job has already finished")
+	// ErrLeaseLost is returned when renewing the lease on a job that was
+	// held past its lease timeout
+	ErrLeaseLost = errors.New("job lease lost")
+)
+
+// ListFilter selects the jobs returned by Store.List. Empty fields match
+// every job.
+type ListFilter struct {
+	Status string
+	Kind   string
+	Limit  int
+}
+
+// Store holds jobs for both the API, which queues and inspects them, and the
+// worker, which claims and runs them
+type Store interface {
+	// Create queues a job, assigning its ID and timestamps
+	Create(ctx context.Context, job *models.Job) error
+	Get(ctx context.Context, id uuid.UUID) (*models.Job, error)
+	// List returns jobs matching the filter, newest first
+	List(ctx context.Context, filter ListFilter) ([]*models.Job, error)
+	// Claim marks the oldest queued job of one of the given kinds as running
+	// and returns it, or returns nil when there is none. The job is leased to
+	// the caller, which must Renew the lease until the job is finished.
+	Claim(ctx context.Context, kinds []string) (*models.Job, error)
+	// Renew extends the lease on a running job. It returns ErrLeaseLost if
+	// the lease lapsed and the job may have been requeued.
+	Renew(ctx context.Context, id uuid.UUID) error
+	// Reclaim requeues the running jobs of the given kinds whose lease has
+	// lapsed, their worker having stopped without finishing them, and
+	// returns how many it requeued
+	Reclaim(ctx context.Context, kinds []string) (int, error)
+	// Update records the progress and message of a running job
+	Update(ctx context.Context, job *models.Job) error
+	// Finish records the final state of a job
+	Finish(ctx context.Context, job *models.Job) error
+	// Cancel cancels a queued job at once, or asks the worker running it to
+	// stop. It returns ErrFinished for a job that has already finished.
+	Cancel(ctx context.Context, id uuid.UUID) (*models.Job, error)
+	// CancelRequested reports whether a running job has been asked to stop
+	CancelRequested(ctx context.Context, id uuid.UUID) (bool, error)
+}
@@ -0,0 +1,88 @@
+// Package language detects the natural language of a text. The detector
+// detects it once per analysis and hands the detection to every analyzer
+// through the context, so that none of them has to detect it again.
+package language
+
+import (
+	"context"
+
+	"github.com/abadojack/whatlanggo"
+)
+
+// maxCandidates is the number of ranked languages a detection reports
+const maxCandidates = 3
+
+// Candidate is a ranked language guess for a text
+type Candidate struct {
+	Code       string  `json:"code"`
+	Language   string  `json:"language"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Detection is the language detected in a text. Code is the ISO 639-1 code
+// when the language has one, the ISO 639-3 code otherwise, and empty when no
+// language was detected, e.g. in text without letters. Reliable reports
+// whether the detector is sure of its guess.
+type Detection struct {
+	Lang       whatlanggo.Lang `json:"-"`
+	Code       string          `json:"code"`
+	Name       string          `json:"name"`
+	Confidence float64         `json:"confidence"`
+	Reliable   bool            `json:"reliable"`
+	Candidates []Candidate     `json:"candidates,omitempty"`
+}
+
+// Detect ranks the most likely languages of text. Each candidate is found by
+// excluding the ones ranked above it, so its confidence measures how clearly
+// it beats the languages ranked below it.
+func Detect(text string) Detection {
+	best := whatlanggo.Detect(text)
+	detection := Detection{
+		Lang:       best.Lang,
+		Code:       Code(best.Lang),
+		Confidence: best.Confidence,
+		Reliable:   best.IsReliable(),
+	}
+	if best.Lang != -1 {
+		detection.Name = best.Lang.String()
+	}
+
+	excluded := make(map[whatlanggo.Lang]bool)
+	info := best
+	// Scripts with a single language ignore the blacklist, so stop on repeats
+	for len(detection.Candidates) < maxCandidates && info.Lang != -1 && !excluded[info.Lang] {
+		detection.Candidates = append(detection.Candidates, Candidate{
+			Code:       info.Lang.Iso6391(),
+			Language:   info.Lang.String(),
+			Confidence: info.Confidence,
+		})
+		excluded[info.Lang] = true
+		info = whatlanggo.DetectWithOptions(text, whatlanggo.Options{Blacklist: excluded})
+	}
+	return detection
+}
+
+// Code returns the ISO 639-1 code of lang, or its ISO 639-3 code when it has
+// no two-letter one, and "" for an undetected language
+func Code(lang whatlanggo.Lang) string {
+	if lang == -1 {
+		return ""
+	}
+	if code := lang.Iso6391(); code != "" {
+		return code
+	}
+	return lang.Iso6393()
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying detection
+func NewContext(ctx context.Context, detection *Detection) context.Context {
+	return context.WithValue(ctx, contextKey{}, detection)
+}
+
+// FromContext returns the detection ctx carries, if any
+func FromContext(ctx context.Context) (*Detection, bool) {
+	detection, ok := ctx.Value(contextKey{}).(*Detection)
+	return detection, ok && detection != nil
+}
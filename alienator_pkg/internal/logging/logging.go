@@ -0,0 +1,73 @@
+// Package logging builds zap loggers from configuration
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Supported log output formats
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// NewLogger creates a logger honoring the configured level and format. The
+// returned AtomicLevel controls the logger's level and can be changed at runtime.
+func NewLogger(cfg config.LoggingConfig) (*zap.Logger, zap.AtomicLevel, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, zap.AtomicLevel{}, err
+	}
+	atomicLevel := zap.NewAtomicLevelAt(level)
+
+	var zapConfig zap.Config
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatJSON:
+		zapConfig = zap.NewProductionConfig()
+	case FormatConsole:
+		zapConfig = zap.NewDevelopmentConfig()
+		zapConfig.Development = false
+	default:
+		return nil, zap.AtomicLevel{}, fmt.Errorf("unsupported log format: %s", cfg.Format)
+	}
+	zapConfig.Level = atomicLevel
+
+	logger, err := zapConfig.Build()
+	if err != nil {
+		return nil, zap.AtomicLevel{}, fmt.Errorf("failed to build logger: %w", err)
+	}
+
+	return logger, atomicLevel, nil
+}
+
+// ParseLevel converts a level name such as "debug" or "warn" into a zap level,
+// defaulting to info when the name is empty
+func ParseLevel(name string) (zapcore.Level, error) {
+	if name == "" {
+		return zapcore.InfoLevel, nil
+	}
+
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(name))); err != nil {
+		return zapcore.InfoLevel, fmt.Errorf("invalid log level: %s", name)
+	}
+	return level, nil
+}
+
+// ReloadLevel returns a config.ApplyFunc that switches level to the reloaded
+// configuration's log level
+func ReloadLevel(level zap.AtomicLevel) config.ApplyFunc {
+	return func(cfg *config.Config) error {
+		parsed, err := ParseLevel(cfg.Logging.Level)
+		if err != nil {
+			return err
+		}
+		level.SetLevel(parsed)
+		return nil
+	}
+}
@@ -142,8 +142,10 @@ func isPublicPath(path string) bool {
 		"/health",
 		"/metrics",
 		"/swagger",
+		"/openapi.json",
 		"/api/v1/auth/login",
 		"/api/v1/auth/register",
+		"/api/v1/limits",
 		"/playground",
 	}
 
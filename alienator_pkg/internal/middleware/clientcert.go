@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// RequireClientCert rejects requests that did not present a client
+// certificate verified during the TLS handshake (mutual TLS)
+func RequireClientCert() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "CLIENT_CERT_REQUIRED",
+					Message: "A verified client certificate is required",
+				},
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
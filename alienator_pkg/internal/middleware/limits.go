@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// RequestLimits enforces the configured body size and time limits on each
+// request. The handlers reading NDJSON streams and the limits endpoint take
+// their values from Config, so what is reported is what is enforced.
+type RequestLimits struct {
+	mu      sync.Mutex
+	config  config.LimitsConfig
+	streams map[string]bool // routes exempt from the body size limit
+}
+
+// NewRequestLimits creates a limiter enforcing config
+func NewRequestLimits(config config.LimitsConfig) *RequestLimits {
+	return &RequestLimits{config: config, streams: make(map[string]bool)}
+}
+
+// ExemptRoute leaves the bodies of requests to a route, given as registered
+// with gin, out of the body size limit. It is meant for streaming routes,
+// such as NDJSON bulk detection, whose handlers bound each line and the
+// number of items themselves.
+func (rl *RequestLimits) ExemptRoute(route string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.streams[route] = true
+}
+
+// exempt reports whether a route is left out of the body size limit
+func (rl *RequestLimits) exempt(route string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.streams[route]
+}
+
+// Update applies new limits, from the next request on
+func (rl *RequestLimits) Update(config config.LimitsConfig) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.config = config
+}
+
+// Config returns the limits currently in effect
+func (rl *RequestLimits) Config() config.LimitsConfig {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.config
+}
+
+// Handler returns the middleware enforcing the limits. Bodies declaring a
+// length over the limit are rejected with 413 straight away; others are cut
+// off at the limit, which the JSON binding reports the same way. Exempt
+// routes are told apart by the route matched, not by anything the client
+// sends. The request context gets a deadline after the request timeout.
+func (rl *RequestLimits) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limits := rl.Config()
+
+		if limits.MaxBodyBytes > 0 && c.Request.Body != nil && !rl.exempt(c.FullPath()) {
+			if c.Request.ContentLength > int64(limits.MaxBodyBytes) {
+				Reject(c, http.StatusRequestEntityTooLarge, BodyTooLarge(int64(limits.MaxBodyBytes), c.Request.ContentLength))
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(limits.MaxBodyBytes))
+		}
+
+		if limits.RequestTimeout > 0 {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), limits.RequestTimeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		c.Next()
+	}
+}
+
+// BodyTooLarge is the error reported for a body of size bytes over the
+// limit. A body cut off at the limit is reported as one byte over, the least
+// it can be.
+func BodyTooLarge(limit, size int64) *models.APIError {
+	return Rejected("REQUEST_TOO_LARGE", "Request body is too large",
+		fmt.Sprintf("Limit: %d bytes per request body", limit), 0, limit, max(size, limit+1))
+}
+
+// RequestTimedOut is the error reported for a request that ran out of time.
+// It carries no rejection: telling every client that timed out to retry
+// after the same delay would bring them back at once.
+func RequestTimedOut(timeout time.Duration) *models.APIError {
+	return &models.APIError{
+		Code:    "REQUEST_TIMEOUT",
+		Message: "Request took too long to process",
+		Details: fmt.Sprintf("Limit: %s per request", timeout),
+	}
+}
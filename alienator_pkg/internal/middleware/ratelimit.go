@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,72 +15,186 @@ import (
 	"golang.org/x/time/rate"
 )
 
-// RateLimiter holds rate limiting configuration and state
+// RateLimiter holds rate limiting configuration and state. Each client has
+// a budget per route group, and one for the routes outside any group.
 type RateLimiter struct {
-	limiters map[string]*rate.Limiter
+	mu       sync.Mutex
+	limiters map[string]*groupLimiter
 	config   config.RateLimitConfig
 }
 
+// groupLimiter is a client's budget for the named route group, "" for the
+// routes outside any group. It also counts the requests the client made in
+// the current minute, to report with a rejection.
+type groupLimiter struct {
+	group   string
+	limiter *rate.Limiter
+
+	mu          sync.Mutex
+	windowStart time.Time
+	requests    int
+}
+
+// allow takes a token for a request, reporting whether it is admitted and
+// how many requests the client made in the current minute, this one included
+func (gl *groupLimiter) allow(now time.Time) (bool, int) {
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+	if now.Sub(gl.windowStart) >= time.Minute {
+		gl.windowStart = now
+		gl.requests = 0
+	}
+	gl.requests++
+	return gl.limiter.AllowN(now, 1), gl.requests
+}
+
+// retryAfter returns how long until the budget admits a request again
+func (gl *groupLimiter) retryAfter(now time.Time) time.Duration {
+	perSecond := float64(gl.limiter.Limit())
+	if perSecond <= 0 {
+		return time.Minute
+	}
+	missing := 1 - gl.limiter.TokensAt(now)
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / perSecond * float64(time.Second))
+}
+
+// rateLimited is the error refusing a request over a rate limit
+func rateLimited(code, message string, group config.RateLimitGroup, retryAfter time.Duration, requests int) *models.APIError {
+	return Rejected(code, message, fmt.Sprintf("Limit: %d requests per minute", group.RequestsPerMinute),
+		retryAfter, int64(group.RequestsPerMinute), int64(requests))
+}
+
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(config config.RateLimitConfig) *RateLimiter {
 	return &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
+		limiters: make(map[string]*groupLimiter),
 		config:   config,
 	}
 }
 
-// getLimiter gets or creates a rate limiter for a client
-func (rl *RateLimiter) getLimiter(key string) *rate.Limiter {
+// Update applies new rates and bursts to existing clients and new ones alike
+func (rl *RateLimiter) Update(config config.RateLimitConfig) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.config = config
+	for key, limiter := range rl.limiters {
+		group, ok := rl.group(limiter.group)
+		if !ok {
+			// The group is gone; its routes now start over in another budget
+			delete(rl.limiters, key)
+			continue
+		}
+		limiter.limiter.SetLimit(rate.Limit(group.RequestsPerMinute) / 60)
+		limiter.limiter.SetBurst(group.Burst)
+	}
+}
+
+// Config returns the configuration currently in effect
+func (rl *RateLimiter) Config() config.RateLimitConfig {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.config
+}
+
+// group returns the named route group's limit, the default limit for "";
+// the caller holds mu
+func (rl *RateLimiter) group(name string) (config.RateLimitGroup, bool) {
+	if name == "" {
+		return config.RateLimitGroup{RequestsPerMinute: rl.config.RequestsPerMinute, Burst: rl.config.Burst}, true
+	}
+	for _, group := range rl.config.Groups {
+		if group.Name == name {
+			return group, true
+		}
+	}
+	return config.RateLimitGroup{}, false
+}
+
+// groupFor returns the limit of the first route group path belongs to, or
+// the default limit
+func (rl *RateLimiter) groupFor(path string) config.RateLimitGroup {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	for _, group := range rl.config.Groups {
+		for _, prefix := range group.Paths {
+			if underPath(path, prefix) {
+				return group
+			}
+		}
+	}
+	group, _ := rl.group("")
+	return group
+}
+
+// underPath reports whether path is prefix or lies below it. Whole segments
+// are matched, so that "/health" covers "/health/ready" but not "/healthz".
+func underPath(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
+
+// getLimiter gets or creates a client's limiter for a route group
+func (rl *RateLimiter) getLimiter(key string, group config.RateLimitGroup) *groupLimiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	key = group.Name + "|" + key
 	if limiter, exists := rl.limiters[key]; exists {
 		return limiter
 	}
 
 	// Create new limiter with configured rate and burst
 	limiter := rate.NewLimiter(
-		rate.Limit(rl.config.RequestsPerMinute)/60, // Convert per minute to per second
-		rl.config.Burst,
+		rate.Limit(group.RequestsPerMinute)/60, // Convert per minute to per second
+		group.Burst,
 	)
-	rl.limiters[key] = limiter
+	budget := &groupLimiter{group: group.Name, limiter: limiter}
+	rl.limiters[key] = budget
 
 	// Clean up old limiters periodically (simple approach)
 	go func() {
 		time.Sleep(10 * time.Minute)
+		rl.mu.Lock()
 		delete(rl.limiters, key)
+		rl.mu.Unlock()
 	}()
 
-	return limiter
+	return budget
 }
 
 // RateLimit middleware applies rate limiting per IP address
 func RateLimit(config config.RateLimitConfig) gin.HandlerFunc {
-	rl := NewRateLimiter(config)
+	return NewRateLimiter(config).Handler()
+}
 
+// Handler returns the middleware enforcing the limiter. Limits changed with
+// Update take effect on the next request.
+func (rl *RateLimiter) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get client identifier (IP address)
 		clientIP := c.ClientIP()
 		
-		// Get or create limiter for this client
-		limiter := rl.getLimiter(clientIP)
+		// Get or create limiter for this client and route group
+		config := rl.groupFor(c.Request.URL.Path)
+		limiter := rl.getLimiter(clientIP, config)
 
 		// Check if request is allowed
-		if !limiter.Allow() {
-			// Calculate retry after time
-			retryAfter := time.Second
-			
-			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		now := time.Now()
+		allowed, requests := limiter.allow(now)
+		if !allowed {
+			retryAfter := limiter.retryAfter(now)
 			c.Header("X-Rate-Limit-Limit", strconv.Itoa(config.RequestsPerMinute))
 			c.Header("X-Rate-Limit-Remaining", "0")
-			c.Header("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Add(retryAfter).Unix(), 10))
-
-			c.JSON(http.StatusTooManyRequests, models.APIResponse{
-				Success: false,
-				Error: &models.APIError{
-					Code:    "RATE_LIMIT_EXCEEDED",
-					Message: "Rate limit exceeded. Please try again later.",
-					Details: fmt.Sprintf("Limit: %d requests per minute", config.RequestsPerMinute),
-				},
-			})
-			c.Abort()
+			c.Header("X-Rate-Limit-Reset", strconv.FormatInt(now.Add(retryAfter).Unix(), 10))
+
+			Reject(c, http.StatusTooManyRequests, rateLimited("RATE_LIMIT_EXCEEDED",
+				"Rate limit exceeded. Please try again later.", config, retryAfter, requests))
 			return
 		}
 
@@ -104,20 +220,13 @@ func UserBasedRateLimit(config config.RateLimitConfig) gin.HandlerFunc {
 		}
 
 		key := fmt.Sprintf("user:%v", userID)
-		limiter := rl.getLimiter(key)
-
-		if !limiter.Allow() {
-			retryAfter := time.Second
-
-			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
-			c.JSON(http.StatusTooManyRequests, models.APIResponse{
-				Success: false,
-				Error: &models.APIError{
-					Code:    "USER_RATE_LIMIT_EXCEEDED",
-					Message: "User rate limit exceeded. Please try again later.",
-				},
-			})
-			c.Abort()
+		group := rl.groupFor("")
+		limiter := rl.getLimiter(key, group)
+
+		now := time.Now()
+		if allowed, requests := limiter.allow(now); !allowed {
+			Reject(c, http.StatusTooManyRequests, rateLimited("USER_RATE_LIMIT_EXCEEDED",
+				"User rate limit exceeded. Please try again later.", group, limiter.retryAfter(now), requests))
 			return
 		}
 
@@ -135,20 +244,16 @@ func EndpointRateLimit(requestsPerMinute, burst int) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 		key := fmt.Sprintf("endpoint:%s:%s", c.Request.Method, c.FullPath())
-		limiter := rl.getLimiter(key)
-
-		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, models.APIResponse{
-				Success: false,
-				Error: &models.APIError{
-					Code:    "ENDPOINT_RATE_LIMIT_EXCEEDED",
-					Message: "Endpoint rate limit exceeded. Please try again later.",
-				},
-			})
-			c.Abort()
+		group := rl.groupFor("")
+		limiter := rl.getLimiter(key, group)
+
+		now := time.Now()
+		if allowed, requests := limiter.allow(now); !allowed {
+			Reject(c, http.StatusTooManyRequests, rateLimited("ENDPOINT_RATE_LIMIT_EXCEEDED",
+				"Endpoint rate limit exceeded. Please try again later.", group, limiter.retryAfter(now), requests))
 			return
 		}
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
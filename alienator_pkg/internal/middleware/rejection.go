@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Rejected is the error refusing a request over a limit: the limit, the
+// usage that hit it and how long to wait before retrying, rounded up to the
+// whole seconds Retry-After takes. A zero retryAfter means the same request
+// can never succeed, e.g. a body over the size limit.
+func Rejected(code, message, details string, retryAfter time.Duration, limit, current int64) *models.APIError {
+	return &models.APIError{
+		Code:    code,
+		Message: message,
+		Details: details,
+		Rejection: &models.Rejection{
+			RetryAfter: int(math.Ceil(retryAfter.Seconds())),
+			Limit:      limit,
+			Current:    current,
+		},
+	}
+}
+
+// Reject aborts the request with status and the rejection, in the schema
+// version negotiated for the request, setting the Retry-After header when
+// retrying later can succeed
+func Reject(c *gin.Context, status int, apiErr *models.APIError) {
+	if apiErr.Rejection != nil && apiErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(apiErr.RetryAfter))
+	}
+	Respond(c, status, models.APIResponse{
+		Success: false,
+		Error:   apiErr,
+	})
+	c.Abort()
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// SchemaVersion middleware negotiates the response schema version from the
+// configured request header (Accept-Version by default). Requests without the
+// header are served the configured default version, falling back to the latest.
+func SchemaVersion(config config.VersioningConfig) gin.HandlerFunc {
+	header := config.Header
+	if header == "" {
+		header = "Accept-Version"
+	}
+
+	defaultVersion, ok := normalizeSchemaVersion(config.DefaultVersion)
+	if !ok {
+		defaultVersion = models.LatestSchemaVersion
+	}
+
+	return func(c *gin.Context) {
+		requested := c.GetHeader(header)
+		if requested == "" {
+			c.Set("schema_version", defaultVersion)
+			c.Header("Content-Version", defaultVersion)
+			c.Next()
+			return
+		}
+
+		version, ok := normalizeSchemaVersion(requested)
+		if !ok {
+			c.JSON(http.StatusNotAcceptable, models.APIResponse{
+				Success: false,
+				Error: &models.APIError{
+					Code:    "UNSUPPORTED_VERSION",
+					Message: fmt.Sprintf("Unsupported schema version: %s", requested),
+					Details: fmt.Sprintf("Supported versions: %s", strings.Join(models.SupportedSchemaVersions, ", ")),
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("schema_version", version)
+		c.Header("Content-Version", version)
+		c.Next()
+	}
+}
+
+// GetSchemaVersion extracts the negotiated schema version from context,
+// returning the latest version when no negotiation took place
+func GetSchemaVersion(c *gin.Context) string {
+	version, exists := c.Get("schema_version")
+	if !exists {
+		return models.LatestSchemaVersion
+	}
+
+	v, ok := version.(string)
+	if !ok {
+		return models.LatestSchemaVersion
+	}
+	return v
+}
+
+// Respond serializes an API response in the schema version negotiated for
+// the request, for handlers and middleware alike
+func Respond(c *gin.Context, status int, response models.APIResponse) {
+	switch GetSchemaVersion(c) {
+	case models.SchemaVersionV1:
+		c.JSON(status, toV1Response(response))
+	default:
+		c.JSON(status, toV2Response(response))
+	}
+}
+
+// toV1Response is the shim for the original response shape. The v1 envelope is
+// the APIResponse itself, so it is passed through unchanged.
+func toV1Response(response models.APIResponse) models.APIResponse {
+	return response
+}
+
+// toV2Response wraps an APIResponse in the v2 envelope
+func toV2Response(response models.APIResponse) models.APIResponseV2 {
+	return models.APIResponseV2{
+		Success:          response.Success,
+		Version:          models.SchemaVersionV2,
+		Data:             response.Data,
+		Error:            response.Error,
+		ValidationErrors: response.ValidationErrors,
+		Meta:             response.Meta,
+		Timestamp:        time.Now().UTC(),
+	}
+}
+
+// normalizeSchemaVersion accepts "v1", "V1" and "1" style values
+func normalizeSchemaVersion(version string) (string, bool) {
+	version = strings.ToLower(strings.TrimSpace(version))
+	if version == "" {
+		return "", false
+	}
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	for _, supported := range models.SupportedSchemaVersions {
+		if version == supported {
+			return version, true
+		}
+	}
+	return "", false
+}
@@ -2,6 +2,8 @@
 package models
 
 import (
+	"encoding/json"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,21 +21,125 @@ type AnomalyResult struct {
 	Score       float64                      `json:"score"`        // Final anomaly score (0-1)
 	Confidence  float64                      `json:"confidence"`   // Overall confidence (0-1)
 	IsAnomalous bool                         `json:"is_anomalous"` // Binary classification
+	Severity    Severity                     `json:"severity"`     // Coarse bucket derived from score and confidence
+	Outcome     Outcome                      `json:"outcome"`      // Whether the text could be analyzed at all
 	Details     map[string]*AnalysisResult   `json:"details"`      // Individual analyzer results
+	Metadata    map[string]interface{}       `json:"metadata"`     // Aggregation details such as content type and profile
 	Timestamp   time.Time                    `json:"timestamp"`    // When the analysis was performed
+	// Language is the ISO 639 code of the language detected in the text, when
+	// the detector shares its language detection
+	Language string `json:"language,omitempty"`
+	// LanguageConfidence is how sure the language detection is
+	LanguageConfidence float64 `json:"language_confidence,omitempty"`
+	// TopSentences lists the sentences contributing most to the score, when requested
+	TopSentences []SentenceScore `json:"top_sentences,omitempty"`
 }
 
+// SentenceScore is the anomaly score of one sentence of a longer text.
+// Contribution is the sentence's share of the length- and
+// confidence-weighted mean of all sentence scores, so the contributions of
+// a text add up to that mean. Outlier marks sentences the embedding analyzer
+// found semantically out of place in the whole text.
+type SentenceScore struct {
+	Index        int     `json:"index"`
+	Offset       int     `json:"offset"`
+	Length       int     `json:"length"`
+	Text         string  `json:"text"`
+	Score        float64 `json:"score"`
+	Confidence   float64 `json:"confidence"`
+	Contribution float64 `json:"contribution"`
+	Outlier      bool    `json:"outlier"`
+}
+
+// DefaultPrecision is the number of decimals scores and confidences are
+// rounded to when they are output
+const DefaultPrecision = 4
+
+// MaxPrecision is the largest supported precision. Scores lie between 0 and
+// 1, so 15 decimals is all a float64 holds and leaves them unchanged.
+const MaxPrecision = 15
+
+// Round rounds value to precision decimals, half away from zero
+func Round(value float64, precision int) float64 {
+	if precision >= MaxPrecision || math.IsNaN(value) || math.IsInf(value, 0) {
+		return value
+	}
+	scale := math.Pow(10, float64(precision))
+	return math.Round(value*scale) / scale
+}
+
+// Rounded returns a copy of the result for output, with the scores and
+// confidences of the result, its analyzers and its sentences rounded to
+// precision decimals. The result itself is left as computed.
+func (r *AnomalyResult) Rounded(precision int) *AnomalyResult {
+	rounded := *r
+	rounded.Score = Round(r.Score, precision)
+	rounded.Confidence = Round(r.Confidence, precision)
+	rounded.LanguageConfidence = Round(r.LanguageConfidence, precision)
+	if r.Details != nil {
+		rounded.Details = make(map[string]*AnalysisResult, len(r.Details))
+		for name, detail := range r.Details {
+			if detail == nil {
+				rounded.Details[name] = nil
+				continue
+			}
+			roundedDetail := *detail
+			roundedDetail.Score = Round(detail.Score, precision)
+			roundedDetail.Confidence = Round(detail.Confidence, precision)
+			rounded.Details[name] = &roundedDetail
+		}
+	}
+	if r.TopSentences != nil {
+		rounded.TopSentences = make([]SentenceScore, len(r.TopSentences))
+		for i, sentence := range r.TopSentences {
+			sentence.Score = Round(sentence.Score, precision)
+			sentence.Confidence = Round(sentence.Confidence, precision)
+			sentence.Contribution = Round(sentence.Contribution, precision)
+			rounded.TopSentences[i] = sentence
+		}
+	}
+	return &rounded
+}
+
+// Severity is a coarse classification of an anomaly result, using the same
+// levels as the time-series analyzers plus none for unremarkable results
+type Severity string
+
+// Severity levels, from least to most severe
+const (
+	SeverityNone     Severity = "none"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Outcome distinguishes results of a real analysis from degenerate input
+// that had nothing to analyze
+type Outcome string
+
+// Analysis outcomes
+const (
+	// OutcomeAnalyzed is the outcome of a normal analysis
+	OutcomeAnalyzed Outcome = "analyzed"
+	// OutcomeEmptyInput marks text without any letters or digits ("", "   ",
+	// "..."). Such results have zero score and zero confidence.
+	OutcomeEmptyInput Outcome = "empty_input"
+)
+
 // AnalysisRequest represents a request for text analysis
 type AnalysisRequest struct {
 	ID       string            `json:"id"`
 	Text     string            `json:"text"`
 	Options  map[string]string `json:"options,omitempty"`
 	Priority int               `json:"priority,omitempty"`
+	Source   string            `json:"source,omitempty"` // where the text came from, e.g. "support-tickets"
 }
 
 // AnalysisResponse represents the response from the analysis API
 type AnalysisResponse struct {
 	ID       string         `json:"id"`
+	Source   string         `json:"source,omitempty"`
 	Result   *AnomalyResult `json:"result"`
 	Error    string         `json:"error,omitempty"`
 	Duration time.Duration  `json:"duration"`
@@ -86,41 +192,410 @@ type AnomalyData struct {
 	Algorithm   string                 `json:"algorithm" db:"algorithm"`
 	ProcessedAt time.Time              `json:"processed_at" db:"processed_at"`
 	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+	Signature   *Signature             `json:"signature,omitempty" db:"signature" gorm:"type:jsonb"`
+	Source      string                 `json:"source,omitempty" db:"source"` // where the data came from, e.g. "support-tickets"
 	User        *User                  `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// AnalysisRecord is a text analysis result stored by the worker. ID is the
+// analysis request's, so storing the same record twice keeps the first.
+type AnalysisRecord struct {
+	ID         string         `json:"id"`
+	Source     string         `json:"source"`
+	Result     *AnomalyResult `json:"result"`
+	AnalyzedAt time.Time      `json:"analyzed_at"`
+}
+
 // DetectionResult represents the result of anomaly detection
 type DetectionResult struct {
-	ID             uuid.UUID `json:"id"`
-	IsAnomaly      bool      `json:"is_anomaly"`
-	Score          float64   `json:"score"`
-	Confidence     float64   `json:"confidence"`
-	Threshold      float64   `json:"threshold"`
-	Algorithm      string    `json:"algorithm"`
-	ProcessingTime int64     `json:"processing_time_ms"`
-	Metadata       Metadata  `json:"metadata"`
+	ID             uuid.UUID  `json:"id"`
+	IsAnomaly      bool       `json:"is_anomaly"`
+	Score          float64    `json:"score"`
+	Confidence     float64    `json:"confidence"`
+	Threshold      float64    `json:"threshold"`
+	Algorithm      string     `json:"algorithm"`
+	Profile        string     `json:"profile"`
+	Preset         string     `json:"preset,omitempty"`
+	ProcessingTime int64      `json:"processing_time_ms"`
+	Metadata       Metadata   `json:"metadata"`
+	Signature      *Signature `json:"signature,omitempty"`
+	// Language is the ISO 639 code of the language detected in the text
+	Language string `json:"language,omitempty"`
+	// LanguageConfidence is how sure the language detection is
+	LanguageConfidence float64 `json:"language_confidence,omitempty"`
+	// ExplainOnly marks a quick result from the cheap analyzers only, neither
+	// stored nor signed; its ID is the zero UUID
+	ExplainOnly bool `json:"explain_only,omitempty"`
+	// Source is where the data came from, as stored with the result
+	Source string `json:"source,omitempty"`
+}
+
+// Signature makes a detection verdict tamper-evident. Value signs the
+// verdict (score, is_anomaly, threshold and algorithm) together with
+// InputHash, the SHA-256 of the analyzed data, and SignedAt. No other field
+// of the result is covered; see signing.Verdict.
+type Signature struct {
+	Algorithm string    `json:"algorithm"`
+	KeyID     string    `json:"key_id,omitempty"`
+	InputHash string    `json:"input_hash"`
+	SignedAt  time.Time `json:"signed_at"`
+	Value     string    `json:"value"`
+}
+
+// VerificationRequest asks whether a signed verdict is intact. Either ID names
+// a stored detection or Result carries one as returned by the API; Data, if
+// given, is also checked against the signed input hash.
+type VerificationRequest struct {
+	ID     *uuid.UUID             `json:"id,omitempty"`
+	Result *DetectionResult       `json:"result,omitempty"`
+	Data   map[string]interface{} `json:"data,omitempty" validate:"omitempty,max=1000"`
+}
+
+// VerificationResult reports the outcome of a verification
+type VerificationResult struct {
+	Valid     bool       `json:"valid"`
+	Reason    string     `json:"reason,omitempty"`
+	KeyID     string     `json:"key_id,omitempty"`
+	SignedAt  *time.Time `json:"signed_at,omitempty"`
+	InputHash string     `json:"input_hash,omitempty"`
+}
+
+// TextSpan locates something an analyzer matched in the analyzed text, by
+// byte offset and length
+type TextSpan struct {
+	Analyzer string `json:"analyzer"`
+	Rule     string `json:"rule"`
+	Category string `json:"category,omitempty"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	Text     string `json:"text,omitempty"`
+}
+
+// EvidenceBundle packages everything known about one stored detection for
+// reporting: the input, or only its hash when the input is withheld, the
+// stored verdict and its signature, and the analyzer evidence recomputed
+// from the stored text by the running detector
+type EvidenceBundle struct {
+	ID           uuid.UUID              `json:"id"`
+	GeneratedAt  time.Time              `json:"generated_at"`
+	Detector     EvidenceDetector       `json:"detector"`
+	Verdict      EvidenceVerdict        `json:"verdict"`
+	Input        map[string]interface{} `json:"input,omitempty"`
+	InputHash    string                 `json:"input_hash"`
+	Analysis     *EvidenceAnalysis      `json:"analysis,omitempty"`
+	Signature    *Signature             `json:"signature,omitempty"`
+	Verification *VerificationResult    `json:"verification,omitempty"`
+}
+
+// EvidenceDetector identifies the build and detector configuration that
+// produced an evidence bundle
+type EvidenceDetector struct {
+	Version     string `json:"version"`
+	Commit      string `json:"commit"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// EvidenceVerdict is the verdict as stored with the detection
+type EvidenceVerdict struct {
+	Score       float64   `json:"score"`
+	IsAnomaly   bool      `json:"is_anomaly"`
+	Threshold   float64   `json:"threshold"`
+	Algorithm   string    `json:"algorithm"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// EvidenceAnalysis is the text analysis behind a verdict: per-analyzer
+// scores and features, the spans they matched and a plain-language
+// explanation
+type EvidenceAnalysis struct {
+	Score       float64                    `json:"score"`
+	Confidence  float64                    `json:"confidence"`
+	Severity    Severity                   `json:"severity"`
+	Analyzers   map[string]*AnalysisResult `json:"analyzers"`
+	Spans       []TextSpan                 `json:"spans"`
+	Explanation string                     `json:"explanation"`
 }
 
 // Metadata represents additional detection metadata
 type Metadata struct {
-	Features    map[string]float64 `json:"features"`
-	Explanations []string          `json:"explanations"`
-	Suggestions []string          `json:"suggestions"`
+	Features     map[string]float64 `json:"features,omitempty"`
+	Explanations []string           `json:"explanations"`
+	Suggestions  []string           `json:"suggestions"`
+	// Analyzers holds each text analyzer's score when a "text" field was analyzed
+	Analyzers map[string]float64 `json:"analyzers,omitempty"`
+	// SkippedAnalyzers lists the text analyzers left out for lack of time budget
+	SkippedAnalyzers []string `json:"skipped_analyzers,omitempty"`
+	// Suppressions lists the allowlist entries that matched the input
+	Suppressions []Suppression `json:"suppressions,omitempty"`
+	// Review is set when the detection was queued for a human label
+	Review *ReviewItem `json:"review,omitempty"`
+}
+
+// Suppression records an allowlist entry applied to a detection. Factor
+// scaled the weight of the named analyzers or, without analyzers, the score.
+type Suppression struct {
+	EntryID   uuid.UUID `json:"entry_id"`
+	Name      string    `json:"name"`
+	MatchType string    `json:"match_type"`
+	Analyzers []string  `json:"analyzers,omitempty"`
+	Factor    float64   `json:"factor"`
+}
+
+// MetadataLevel sets how much metadata a result carries when it is output
+type MetadataLevel string
+
+// Metadata levels, from least to most verbose. Summary leaves out the
+// per-request feature detail: the features of a detection and the metadata
+// of each analyzer of an analysis. None also leaves out the rest of the
+// metadata. Keys allowed by a MetadataFilter are kept at every level.
+const (
+	MetadataNone    MetadataLevel = "none"
+	MetadataSummary MetadataLevel = "summary"
+	MetadataFull    MetadataLevel = "full"
+)
+
+// MetadataLevels lists the supported metadata levels
+var MetadataLevels = []MetadataLevel{MetadataNone, MetadataSummary, MetadataFull}
+
+// Valid reports whether the level is supported
+func (l MetadataLevel) Valid() bool {
+	for _, level := range MetadataLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// MetadataFilter selects the metadata a result carries when it is output.
+// Keys names metadata kept whatever the level: a field of Metadata by its
+// JSON name, a feature, or a key of an analyzer's or the aggregate metadata.
+type MetadataFilter struct {
+	Level MetadataLevel
+	Keys  []string
+}
+
+// allows reports whether key is kept whatever the level
+func (f MetadataFilter) allows(key string) bool {
+	for _, allowed := range f.Keys {
+		if key == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the entries of metadata the filter allows, nil if none
+func (f MetadataFilter) filter(metadata map[string]interface{}) map[string]interface{} {
+	var kept map[string]interface{}
+	for key, value := range metadata {
+		if f.allows(key) {
+			if kept == nil {
+				kept = make(map[string]interface{})
+			}
+			kept[key] = value
+		}
+	}
+	return kept
+}
+
+// Filtered returns a copy of the result for output with its metadata
+// reduced to what filter selects. The result itself is left as computed.
+func (r *DetectionResult) Filtered(filter MetadataFilter) *DetectionResult {
+	if filter.Level == MetadataFull {
+		return r
+	}
+	filtered := *r
+	metadata := r.Metadata
+	if !filter.allows("features") {
+		metadata.Features = nil
+		for name, value := range r.Metadata.Features {
+			if filter.allows(name) {
+				if metadata.Features == nil {
+					metadata.Features = make(map[string]float64)
+				}
+				metadata.Features[name] = value
+			}
+		}
+	}
+	if filter.Level == MetadataNone {
+		if !filter.allows("explanations") {
+			metadata.Explanations = nil
+		}
+		if !filter.allows("suggestions") {
+			metadata.Suggestions = nil
+		}
+		if !filter.allows("analyzers") {
+			metadata.Analyzers = nil
+		}
+		if !filter.allows("skipped_analyzers") {
+			metadata.SkippedAnalyzers = nil
+		}
+		if !filter.allows("suppressions") {
+			metadata.Suppressions = nil
+		}
+		if !filter.allows("review") {
+			metadata.Review = nil
+		}
+	}
+	filtered.Metadata = metadata
+	return &filtered
+}
+
+// Filtered returns a copy of the result for output with its metadata and
+// that of its analyzers reduced to what filter selects. The result itself
+// is left as computed.
+func (r *AnomalyResult) Filtered(filter MetadataFilter) *AnomalyResult {
+	if filter.Level == MetadataFull {
+		return r
+	}
+	filtered := *r
+	if filter.Level == MetadataNone {
+		filtered.Metadata = filter.filter(r.Metadata)
+	}
+	if r.Details != nil {
+		filtered.Details = make(map[string]*AnalysisResult, len(r.Details))
+		for name, detail := range r.Details {
+			if detail == nil {
+				filtered.Details[name] = nil
+				continue
+			}
+			filteredDetail := *detail
+			filteredDetail.Metadata = filter.filter(detail.Metadata)
+			filtered.Details[name] = &filteredDetail
+		}
+	}
+	return &filtered
+}
+
+// Analyzer parameter types
+const (
+	ParameterString  = "string"
+	ParameterInteger = "integer"
+	ParameterNumber  = "number"
+	ParameterBoolean = "boolean"
+)
+
+// AnalyzerParameter describes one option accepted by a text analyzer's
+// Configure method. Values lists the accepted strings when only some are
+// allowed; a nil Default means the option is unset unless configured.
+type AnalyzerParameter struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Description string      `json:"description"`
+	Default     interface{} `json:"default"`
+	Values      []string    `json:"values,omitempty"`
+}
+
+// AnalyzerInfo describes a registered text analyzer. Weights are its content
+// profile weights by content type; it is enabled when any of them is
+// positive, and ready once the initialization it needs has run. Cache is
+// set for analyzers whose results are cached.
+type AnalyzerInfo struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Enabled     bool                `json:"enabled"`
+	Ready       bool                `json:"ready"`
+	Weights     map[string]float64  `json:"weights"`
+	Parameters  []AnalyzerParameter `json:"parameters"`
+	Cache       *CacheStats         `json:"cache,omitempty"`
+	Circuit     *CircuitState       `json:"circuit,omitempty"`
+}
+
+// CircuitState reports an analyzer's circuit breaker: "closed" while it
+// runs as usual, "open" while it is left out after repeated failures and
+// "half_open" while a probe runs. RetryAt is when an open circuit is next
+// probed. Trips counts the times the circuit has opened.
+type CircuitState struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Trips               int        `json:"trips"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// CacheStats counts the lookups of an analyzer's result cache. Entries is
+// the number of results held, at most Capacity.
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+	Capacity  int    `json:"capacity"`
+}
+
+// AnalyzerHealthReport is the outcome of a health check of the analyzers:
+// Healthy is false when any required analyzer failed, which also fails
+// readiness
+type AnalyzerHealthReport struct {
+	Healthy   bool             `json:"healthy"`
+	CheckedAt time.Time        `json:"checked_at"`
+	Analyzers []AnalyzerHealth `json:"analyzers"`
+}
+
+// AnalyzerHealth reports whether an analyzer's resources loaded correctly,
+// with the reason in Error when they didn't
+type AnalyzerHealth struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Healthy  bool   `json:"healthy"`
+	Error    string `json:"error,omitempty"`
 }
 
 // APIResponse represents a standard API response
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   *APIError   `json:"error,omitempty"`
-	Meta    *Meta       `json:"meta,omitempty"`
+	Success          bool         `json:"success"`
+	Data             interface{}  `json:"data,omitempty"`
+	Error            *APIError    `json:"error,omitempty"`
+	ValidationErrors []FieldError `json:"validation_errors,omitempty"`
+	Meta             *Meta        `json:"meta,omitempty"`
 }
 
-// APIError represents an API error
+// Response schema versions negotiated via the Accept-Version header
+const (
+	SchemaVersionV1     = "v1"
+	SchemaVersionV2     = "v2"
+	LatestSchemaVersion = SchemaVersionV2
+)
+
+// SupportedSchemaVersions lists every response schema version the API can serialize
+var SupportedSchemaVersions = []string{SchemaVersionV1, SchemaVersionV2}
+
+// APIResponseV2 represents the v2 response envelope.
+// It extends the v1 APIResponse with the schema version and a server timestamp.
+type APIResponseV2 struct {
+	Success          bool         `json:"success"`
+	Version          string       `json:"version"`
+	Data             interface{}  `json:"data,omitempty"`
+	Error            *APIError    `json:"error,omitempty"`
+	ValidationErrors []FieldError `json:"validation_errors,omitempty"`
+	Meta             *Meta        `json:"meta,omitempty"`
+	Timestamp        time.Time    `json:"timestamp"`
+}
+
+// APIError represents an API error. An error refusing a request over a
+// rate, concurrency or size limit also carries the Rejection details.
 type APIError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	*Rejection
+}
+
+// Rejection tells a client refused by a limit how to back off, in the same
+// shape whichever limit refused it. Limit and Current are in the unit of
+// the limit: requests per minute, requests in flight, bytes or items.
+type Rejection struct {
+	RetryAfter int   `json:"retry_after"` // seconds to wait, as in the Retry-After header; 0 when the same request can never succeed
+	Limit      int64 `json:"limit"`
+	Current    int64 `json:"current"` // the usage that hit the limit, this request included
+}
+
+// FieldError describes a single failed validation rule on a request field
+type FieldError struct {
+	Field   string      `json:"field"`
+	Rule    string      `json:"rule"`
+	Message string      `json:"message"`
+	Value   interface{} `json:"value,omitempty"`
 }
 
 // Meta represents response metadata
@@ -133,8 +608,8 @@ type Meta struct {
 
 // LoginRequest represents login request payload
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required,max=128"`
 }
 
 // LoginResponse represents login response
@@ -146,11 +621,11 @@ type LoginResponse struct {
 
 // RegisterRequest represents user registration request
 type RegisterRequest struct {
-	Email     string `json:"email" validate:"required,email"`
-	Username  string `json:"username" validate:"required,min=3,max=50"`
-	FirstName string `json:"first_name" validate:"required"`
-	LastName  string `json:"last_name" validate:"required"`
-	Password  string `json:"password" validate:"required,min=6"`
+	Email     string `json:"email" validate:"required,email,max=254"`
+	Username  string `json:"username" validate:"required,min=3,max=50,alphanum_underscore"`
+	FirstName string `json:"first_name" validate:"required,max=100"`
+	LastName  string `json:"last_name" validate:"required,max=100"`
+	Password  string `json:"password" validate:"required,max=1024"`
 }
 
 // UpdateUserRequest represents user update request
@@ -161,11 +636,292 @@ type UpdateUserRequest struct {
 	Username  *string `json:"username,omitempty" validate:"omitempty,min=3,max=50"`
 }
 
-// DetectionRequest represents anomaly detection request
+// DetectionRequest represents anomaly detection request. A "text" string in
+// Data is run through the text analyzers; Analyzers restricts that run to the
+// named analyzers and Weights overrides their weights for this request only.
+// BudgetMS bounds the text analysis in milliseconds, skipping the analyzers
+// late in the configured order once it is spent. Preset analyzes the text as
+// the named detector preset describes, deciding it against the preset's
+// threshold unless the request gives one.
 type DetectionRequest struct {
-	Data      map[string]interface{} `json:"data" validate:"required"`
-	Algorithm string                 `json:"algorithm,omitempty"`
-	Threshold float64                `json:"threshold,omitempty"`
+	Data      map[string]interface{} `json:"data" validate:"required,min=1,max=1000"`
+	Algorithm string                 `json:"algorithm,omitempty" validate:"omitempty,max=64"`
+	Threshold float64                `json:"threshold,omitempty" validate:"gte=0,lte=1"`
+	Analyzers []string               `json:"analyzers,omitempty" validate:"omitempty,max=32,dive,min=1,max=64"`
+	Weights   map[string]float64     `json:"weights,omitempty" validate:"omitempty,max=32,dive,gte=0"`
+	BudgetMS  int                    `json:"budget_ms,omitempty" validate:"gte=0,lte=60000"`
+	Preset    string                 `json:"preset,omitempty" validate:"omitempty,max=64"`
+	// Source tags the detection with where the data came from, e.g.
+	// "support-tickets", to filter and compare detections by
+	Source string `json:"source,omitempty" validate:"omitempty,max=64"`
+}
+
+// BulkDetectionItem is one line of an NDJSON bulk detection request
+type BulkDetectionItem struct {
+	ID string `json:"id,omitempty" validate:"max=128"`
+	DetectionRequest
+}
+
+// BulkDetectionResult is one line of an NDJSON bulk detection response. Line
+// is the 1-based input line, so items without an ID can still be matched up.
+type BulkDetectionResult struct {
+	ID               string           `json:"id,omitempty"`
+	Line             int              `json:"line"`
+	Result           *DetectionResult `json:"result,omitempty"`
+	Error            *APIError        `json:"error,omitempty"`
+	ValidationErrors []FieldError     `json:"validation_errors,omitempty"`
+}
+
+// StatsBucket holds aggregate detection counts for one time bucket
+type StatsBucket struct {
+	Start        time.Time `json:"start" db:"bucket"`
+	Source       string    `json:"source,omitempty" db:"source"` // set when grouped by source
+	Total        int       `json:"total"`
+	AnomalyCount int       `json:"anomaly_count"`
+	MeanScore    float64   `json:"mean_score"`
+}
+
+// AnomalyStatsTimeSeries holds bucketed detection statistics over a time range.
+// Buckets with no detections are omitted. Grouped by source, each interval
+// has a bucket per source, ordered by source.
+type AnomalyStatsTimeSeries struct {
+	Interval string         `json:"interval"`
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Source   string         `json:"source,omitempty"`
+	GroupBy  string         `json:"group_by,omitempty"`
+	Buckets  []*StatsBucket `json:"buckets"`
+}
+
+// StatsQuery selects the detections a stats time series is computed over
+// and how they are bucketed. A nil UserID aggregates across all users and an
+// empty Source across all sources; BySource gives each source its own
+// buckets.
+type StatsQuery struct {
+	UserID   *uuid.UUID
+	Source   string
+	Unit     string // a PostgreSQL date_trunc field such as "hour"
+	From     time.Time
+	To       time.Time
+	BySource bool
+}
+
+// AnomalySearch filters a full-text search of stored detections. A nil
+// UserID searches every user's detections and an empty Source those from
+// every source; nil scores and zero times leave that side of the range open.
+type AnomalySearch struct {
+	Query    string
+	UserID   *uuid.UUID
+	Source   string
+	MinScore *float64
+	MaxScore *float64
+	From     time.Time
+	To       time.Time
+}
+
+// AnomalySearchResult is a stored detection matching a search, with its
+// relevance to the query
+type AnomalySearchResult struct {
+	AnomalyData
+	Rank float64 `json:"rank"`
+}
+
+// DetectionProfile is a named detection tuning that API keys can be bound to.
+// Weights scale the contribution of individual data features to the score.
+type DetectionProfile struct {
+	ID          uuid.UUID          `json:"id" db:"id"`
+	Name        string             `json:"name" db:"name"`
+	Description string             `json:"description,omitempty" db:"description"`
+	Algorithm   string             `json:"algorithm,omitempty" db:"algorithm"`
+	Threshold   float64            `json:"threshold" db:"threshold"`
+	Weights     map[string]float64 `json:"weights,omitempty" db:"weights"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// DetectionProfileRequest represents a create or update request for a detection profile
+type DetectionProfileRequest struct {
+	Name        string             `json:"name" validate:"required,min=1,max=50,alphanum_underscore"`
+	Description string             `json:"description,omitempty" validate:"max=255"`
+	Algorithm   string             `json:"algorithm,omitempty" validate:"omitempty,max=64"`
+	Threshold   float64            `json:"threshold" validate:"gt=0,lte=1"`
+	Weights     map[string]float64 `json:"weights,omitempty" validate:"omitempty,max=100,dive,gte=0"`
+}
+
+// AssignProfileRequest binds an API key to a detection profile
+type AssignProfileRequest struct {
+	APIKey string `json:"api_key" validate:"required,min=16,max=256"`
+}
+
+// Allowlist match types
+const (
+	AllowlistPhrase    = "phrase"
+	AllowlistRegex     = "regex"
+	AllowlistInputHash = "input_hash"
+)
+
+// AllowlistEntry marks inputs known to be benign. A phrase or regex entry
+// matches the "text" field of a detection request; an input hash entry
+// matches the SHA-256 of the request data, as recorded in result signatures.
+// A match scales the weight of the listed analyzers by Factor, or the whole
+// score when no analyzers are listed; a factor of 0 suppresses them.
+type AllowlistEntry struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description,omitempty" db:"description"`
+	MatchType   string    `json:"match_type" db:"match_type"`
+	Pattern     string    `json:"pattern" db:"pattern"`
+	Analyzers   []string  `json:"analyzers,omitempty" db:"analyzers"`
+	Factor      float64   `json:"factor" db:"factor"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// AllowlistEntryRequest represents a create or update request for an allowlist entry
+type AllowlistEntryRequest struct {
+	Name        string   `json:"name" validate:"required,min=1,max=100"`
+	Description string   `json:"description,omitempty" validate:"max=255"`
+	MatchType   string   `json:"match_type" validate:"required,oneof=phrase regex input_hash"`
+	Pattern     string   `json:"pattern" validate:"required,max=1024"`
+	Analyzers   []string `json:"analyzers,omitempty" validate:"omitempty,max=32,dive,min=1,max=64"`
+	Factor      float64  `json:"factor" validate:"gte=0,lt=1"`
+}
+
+// Review queue statuses and labels
+const (
+	ReviewPending  = "pending"
+	ReviewLabeled  = "labeled"
+	LabelAnomalous = "anomalous"
+	LabelNormal    = "normal"
+)
+
+// ReviewItem queues a stored detection whose score fell within the review
+// band around its threshold for a human label. Once labeled, the label
+// overrides the detector's verdict when the detection is used for retraining.
+type ReviewItem struct {
+	ID            uuid.UUID  `json:"id"`
+	AnomalyDataID uuid.UUID  `json:"anomaly_data_id"`
+	Score         float64    `json:"score"`
+	Threshold     float64    `json:"threshold"`
+	IsAnomaly     bool       `json:"is_anomaly"`
+	Status        string     `json:"status"`
+	Label         string     `json:"label,omitempty"`
+	Note          string     `json:"note,omitempty"`
+	LabeledBy     *uuid.UUID `json:"labeled_by,omitempty"`
+	LabeledAt     *time.Time `json:"labeled_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ReviewLabelRequest labels a queued detection
+type ReviewLabelRequest struct {
+	Label string `json:"label" validate:"required,oneof=anomalous normal"`
+	Note  string `json:"note,omitempty" validate:"max=1024"`
+}
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a long operation queued through the API and run by a worker.
+// Progress runs from 0 to 1 and Message describes the current stage; Result
+// holds the handler's output once the job succeeds and Error why it failed.
+// CancelRequested is set while a running job is being asked to stop.
+type Job struct {
+	ID              uuid.UUID       `json:"id"`
+	Kind            string          `json:"kind"`
+	Status          string          `json:"status"`
+	Progress        float64         `json:"progress"`
+	Message         string          `json:"message,omitempty"`
+	Params          json.RawMessage `json:"params,omitempty"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	CancelRequested bool            `json:"cancel_requested,omitempty"`
+	CreatedBy       uuid.UUID       `json:"created_by"`
+	CreatedAt       time.Time       `json:"created_at"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// Done reports whether the job has finished, whether it succeeded, failed or
+// was cancelled
+func (j *Job) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCancelled
+}
+
+// Retraining data sources
+const (
+	RetrainFromDataset = "dataset"
+	RetrainFromStored  = "stored"
+)
+
+// RetrainRequest asks for the neural detector to be retrained, either from the
+// dataset file configured on the worker or from recent stored results the
+// detector judged normal or a reviewer labeled normal. Zero values use the
+// worker's configured defaults.
+type RetrainRequest struct {
+	Source        string `json:"source" validate:"required,oneof=dataset stored"`
+	LookbackHours int    `json:"lookback_hours,omitempty" validate:"omitempty,min=1,max=8760"`
+	MaxRecords    int    `json:"max_records,omitempty" validate:"omitempty,min=1,max=1000000"`
+	Epochs        int    `json:"epochs,omitempty" validate:"omitempty,min=1,max=10000"`
+}
+
+// LogLevelRequest represents a runtime log level change request
+type LogLevelRequest struct {
+	Level string `json:"level" validate:"required"`
+}
+
+// LogLevelResponse represents the active log level
+type LogLevelResponse struct {
+	Level    string `json:"level"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// PasswordPolicy describes the password rules enforced on registration
+type PasswordPolicy struct {
+	MinLength             int  `json:"min_length"`
+	MaxLength             int  `json:"max_length"`
+	RequireUpper          bool `json:"require_upper"`
+	RequireLower          bool `json:"require_lower"`
+	RequireDigit          bool `json:"require_digit"`
+	RequireSpecial        bool `json:"require_special"`
+	RejectCommonPasswords bool `json:"reject_common_passwords"`
+}
+
+// RequestLimits describes the limits enforced on each API request, so that
+// clients can size their requests up front. Zero means no limit.
+type RequestLimits struct {
+	MaxBodyBytes     int             `json:"max_body_bytes"`
+	MaxBatchItems    int             `json:"max_batch_items"`
+	MaxLineBytes     int             `json:"max_line_bytes"`
+	RequestTimeoutMS int64           `json:"request_timeout_ms"`
+	RateLimit        RateLimitPolicy `json:"rate_limit"`
+}
+
+// RateLimitPolicy describes the rate limit applied to each client IP, and
+// the route groups limited separately
+type RateLimitPolicy struct {
+	RequestsPerMinute int                    `json:"requests_per_minute"`
+	Burst             int                    `json:"burst"`
+	Groups            []RateLimitGroupPolicy `json:"groups,omitempty"`
+}
+
+// RateLimitGroupPolicy describes the rate limit of a route group
+type RateLimitGroupPolicy struct {
+	// Name identifies the group
+	Name string `json:"name"`
+	// Paths are the paths the group's routes are at or below, matched by
+	// whole path segments
+	Paths []string `json:"paths"`
+	// RequestsPerMinute is each client IP's sustained rate on the group's routes
+	RequestsPerMinute int `json:"requests_per_minute"`
+	// Burst is the number of requests a client IP may make at once
+	Burst int `json:"burst"`
 }
 
 // WebSocketMessage represents WebSocket message structure
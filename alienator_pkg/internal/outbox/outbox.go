@@ -0,0 +1,424 @@
+// Package outbox persists the worker's results to the database without
+// losing them to a database outage. Results are first appended to a local
+// file and synced to disk, then a flusher commits them to the database one
+// at a time, in the order they were written, retrying each until it is
+// stored. Results still in the file when the worker stops are committed
+// once it starts again.
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/sink"
+	"github.com/ruvnet/alienator/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Files kept in the outbox directory: the results as JSON lines, and the
+// number of bytes of them already committed
+const (
+	entriesFile = "outbox.jsonl"
+	offsetFile  = "outbox.offset"
+)
+
+// errClosed is returned by an outbox that has been closed
+var errClosed = errors.New("outbox is closed")
+
+// Store commits results to the database. Saving a record already stored
+// must leave it as it is: a result is committed again when the worker stops
+// between committing it and recording that it was.
+type Store interface {
+	SaveAnalysisRecord(record *models.AnalysisRecord) error
+}
+
+// Outbox is a result sink holding results until they are committed to its
+// store by Run. Write is safe for concurrent use.
+type Outbox struct {
+	dir            string
+	store          Store
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	metrics        *metrics.Metrics
+	logger         *zap.Logger
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64 // bytes in the entries file
+	offset int64 // bytes of the entries file committed
+	depth  int   // entries written but not committed
+	closed bool
+
+	written chan struct{} // signals Run that entries were written
+}
+
+// Open opens the outbox in cfg.Dir, creating it if needed, with the
+// entries left uncommitted by a previous run pending. An entry only partly
+// written when the worker stopped was never acknowledged and is dropped.
+// metrics may be nil.
+func Open(cfg config.OutboxConfig, store Store, metrics *metrics.Metrics, logger *zap.Logger) (*Outbox, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	ob := &Outbox{
+		dir:            cfg.Dir,
+		store:          store,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		metrics:        metrics,
+		logger:         logger,
+		written:        make(chan struct{}, 1),
+	}
+
+	offset, err := ob.readOffset()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(cfg.Dir, entriesFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox: %w", err)
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	complete := int64(bytes.LastIndexByte(content, '\n') + 1)
+	if offset > complete {
+		file.Close()
+		return nil, fmt.Errorf("outbox offset %d is past the end of its %d bytes of entries", offset, complete)
+	}
+	if complete < int64(len(content)) {
+		if err := file.Truncate(complete); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to drop a partly written outbox entry: %w", err)
+		}
+		logger.Warn("Dropped a partly written outbox entry", zap.Int64("bytes", int64(len(content))-complete))
+	}
+
+	ob.file = file
+	ob.size = complete
+	ob.offset = offset
+	ob.depth = bytes.Count(content[offset:complete], []byte{'\n'})
+	ob.updateDepth()
+	return ob, nil
+}
+
+// Name implements sink.ResultSink
+func (ob *Outbox) Name() string {
+	return "outbox"
+}
+
+// Write implements sink.ResultSink, returning once the record is on disk
+func (ob *Outbox) Write(ctx context.Context, record *sink.Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	ob.mu.Lock()
+	if ob.closed {
+		ob.mu.Unlock()
+		return fmt.Errorf("%w: %s", errClosed, ob.dir)
+	}
+	_, err = ob.file.Write(line)
+	if err == nil {
+		err = ob.file.Sync()
+	}
+	if err != nil {
+		// Drop what was written so that the next entry starts on its own line
+		ob.file.Truncate(ob.size)
+		ob.mu.Unlock()
+		return fmt.Errorf("failed to write to outbox: %w", err)
+	}
+	ob.size += int64(len(line))
+	ob.depth++
+	ob.updateDepth()
+	ob.mu.Unlock()
+
+	select {
+	case ob.written <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Close implements sink.ResultSink. Uncommitted entries stay in the outbox
+// for the next run.
+func (ob *Outbox) Close() error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	if ob.closed {
+		return nil
+	}
+	ob.closed = true
+	return ob.file.Close()
+}
+
+// Depth returns the number of results waiting to be committed
+func (ob *Outbox) Depth() int {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	return ob.depth
+}
+
+// Run commits the outbox's results to its store in order until ctx is
+// done or the outbox is closed, waiting for more once they all are. A failed
+// commit is retried after a delay doubling from the initial backoff up to
+// the maximum, for as long as it takes, and so is a failure to read the
+// outbox or to record the committed offset; an entry that can't be decoded
+// is logged and skipped. Only one Run may be active at a time.
+func (ob *Outbox) Run(ctx context.Context) error {
+	for {
+		var line []byte
+		var next int64
+		err := ob.retry(ctx, "Failed to read outbox, retrying", func() (err error) {
+			line, next, err = ob.next()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if line == nil {
+			select {
+			case <-ob.written:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		var record sink.Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			ob.logger.Error("Skipping an outbox entry that can't be decoded", zap.Error(err))
+		} else if err := ob.commit(ctx, &record); err != nil {
+			return err
+		}
+		ob.advance(next)
+		if err := ob.retry(ctx, "Failed to record outbox offset, retrying", ob.persist); err != nil {
+			return err
+		}
+	}
+}
+
+// retry calls attempt until it succeeds, backing off after each failure as
+// commit does. It gives up when ctx is done or the outbox is closed.
+func (ob *Outbox) retry(ctx context.Context, message string, attempt func() error) error {
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || errors.Is(err, errClosed) {
+			return err
+		}
+		delay := ob.backoff(n)
+		ob.logger.Warn(message,
+			zap.Int("attempt", n),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		if err := wait(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// commit stores one record, retrying until it is stored or ctx is done
+func (ob *Outbox) commit(ctx context.Context, record *sink.Record) error {
+	stored := &models.AnalysisRecord{
+		ID:         record.ID,
+		Source:     record.Source,
+		Result:     record.Result,
+		AnalyzedAt: record.Timestamp,
+	}
+	for attempt := 1; ; attempt++ {
+		err := ob.store.SaveAnalysisRecord(stored)
+		if err == nil {
+			return nil
+		}
+		delay := ob.backoff(attempt)
+		ob.logger.Warn("Failed to commit outbox entry, retrying",
+			zap.String("id", record.ID),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		if ob.metrics != nil {
+			ob.metrics.RecordOutboxRetry()
+		}
+		if err := wait(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// wait waits for delay to pass, returning ctx's error if it is done first
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// backoff returns the delay after the given failed attempt (starting at 1)
+func (ob *Outbox) backoff(attempt int) time.Duration {
+	delay := ob.maxBackoff
+	if shift := attempt - 1; shift < 32 {
+		if exp := ob.initialBackoff << uint(shift); exp > 0 && exp < delay {
+			delay = exp
+		}
+	}
+	return delay
+}
+
+// next returns the first uncommitted entry and the offset just past it, or
+// a nil entry when every entry is committed
+func (ob *Outbox) next() ([]byte, int64, error) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	if ob.closed {
+		return nil, 0, fmt.Errorf("%w: %s", errClosed, ob.dir)
+	}
+
+	line := []byte{}
+	chunk := make([]byte, 4096)
+	for position := ob.offset; position < ob.size; {
+		n, err := ob.file.ReadAt(chunk[:min(int64(len(chunk)), ob.size-position)], position)
+		if n == 0 && err != nil {
+			return nil, 0, fmt.Errorf("failed to read outbox: %w", err)
+		}
+		if end := bytes.IndexByte(chunk[:n], '\n'); end >= 0 {
+			line = append(line, chunk[:end]...)
+			return line, position + int64(end) + 1, nil
+		}
+		line = append(line, chunk[:n]...)
+		position += int64(n)
+	}
+	return nil, 0, nil
+}
+
+// advance records the entries before offset as committed
+func (ob *Outbox) advance(offset int64) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ob.depth--
+	ob.updateDepth()
+	ob.offset = offset
+}
+
+// persist records the committed offset on disk. Once the committed entries
+// take up at least as much of the file as the pending ones, the file is
+// compacted down to the pending entries instead, so that it stays within
+// twice the size of the backlog however long the backlog lasts.
+func (ob *Outbox) persist() error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if ob.closed || ob.offset < ob.size-ob.offset {
+		return ob.writeOffset(ob.offset)
+	}
+	return ob.compact()
+}
+
+// compact drops the committed entries from the entries file. Offset 0 is
+// recorded before the compacted file replaces the old one, so that a crash
+// in between commits the old file's entries again rather than skipping
+// pending ones. The caller holds mu.
+func (ob *Outbox) compact() error {
+	path := filepath.Join(ob.dir, entriesFile)
+	temp, err := os.CreateTemp(ob.dir, entriesFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to compact outbox: %w", err)
+	}
+	_, err = io.Copy(temp, io.NewSectionReader(ob.file, ob.offset, ob.size-ob.offset))
+	if err == nil {
+		err = temp.Sync()
+	}
+	if err == nil {
+		err = ob.writeOffset(0)
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("failed to compact outbox: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen outbox: %w", err)
+	}
+	ob.file.Close()
+	ob.file = file
+	ob.size -= ob.offset
+	ob.offset = 0
+	return nil
+}
+
+// readOffset returns the committed offset recorded in the outbox directory
+func (ob *Outbox) readOffset() (int64, error) {
+	content, err := os.ReadFile(filepath.Join(ob.dir, offsetFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read outbox offset: %w", err)
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid outbox offset %q", content)
+	}
+	return offset, nil
+}
+
+// writeOffset records the committed offset, replacing the previous one
+// atomically
+func (ob *Outbox) writeOffset(offset int64) error {
+	path := filepath.Join(ob.dir, offsetFile)
+	temp, err := os.CreateTemp(ob.dir, offsetFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to record outbox offset: %w", err)
+	}
+	_, err = temp.WriteString(strconv.FormatInt(offset, 10))
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("failed to record outbox offset: %w", err)
+	}
+	return nil
+}
+
+// updateDepth publishes the outbox depth; the caller holds mu
+func (ob *Outbox) updateDepth() {
+	if ob.metrics != nil {
+		ob.metrics.UpdateOutboxDepth(float64(ob.depth))
+	}
+}
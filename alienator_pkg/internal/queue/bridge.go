@@ -0,0 +1,261 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/sink"
+	"github.com/ruvnet/alienator/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// BridgeTransport carries analysis requests to the bridge and its results
+// back out
+type BridgeTransport interface {
+	// Subscribe delivers the payloads published on channel until ctx is done,
+	// then closes the returned channel
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+	// Publish sends payload to the subscribers of channel
+	Publish(ctx context.Context, channel string, payload []byte) error
+	// Append adds payload to the durable stream under the request ID
+	Append(ctx context.Context, stream, id string, payload []byte) error
+}
+
+// BridgeConsumer connects the simple API's pub/sub entry point to the
+// detection pipeline. Each payload on the request channel is either a JSON
+// models.AnalysisRequest or plain text; it is analyzed and the
+// models.AnalysisResponse is written to the result channel and stream. The
+// result goes to the sink tagged with the request's source, "worker" if it
+// names none and there is no default.
+type BridgeConsumer struct {
+	detector   *core.AnomalyDetector
+	transport  BridgeTransport
+	config     config.BridgeConfig
+	pool       *workerPool
+	sink       sink.ResultSink
+	provenance config.ProvenanceConfig
+	metrics    *metrics.Metrics
+	logger     *zap.Logger
+
+	// Consumer management
+	running bool
+	mu      sync.RWMutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewBridgeConsumer creates a new bridge consumer; metrics may be nil
+func NewBridgeConsumer(detector *core.AnomalyDetector, transport BridgeTransport, cfg config.BridgeConfig, metrics *metrics.Metrics, logger *zap.Logger) *BridgeConsumer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &BridgeConsumer{
+		detector:  detector,
+		transport: transport,
+		config:    cfg,
+		pool:      newWorkerPool(config.ConsumerConfig{Workers: cfg.Workers, MaxInFlight: cfg.MaxInFlight}),
+		metrics:   metrics,
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// SetSink additionally writes every successful result to s
+func (bc *BridgeConsumer) SetSink(s sink.ResultSink) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.sink = s
+}
+
+// SetProvenance sets the sources requests are tagged with: the default for
+// requests naming none, and the only ones accepted if listed
+func (bc *BridgeConsumer) SetProvenance(cfg config.ProvenanceConfig) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.provenance = cfg
+}
+
+// Start subscribes to the request channel and starts analyzing requests
+func (bc *BridgeConsumer) Start(ctx context.Context) error {
+	bc.mu.Lock()
+	if bc.running {
+		bc.mu.Unlock()
+		return fmt.Errorf("bridge consumer is already running")
+	}
+	bc.running = true
+	bc.mu.Unlock()
+
+	bc.logger.Info("Starting bridge consumer",
+		zap.String("request_channel", bc.config.RequestChannel),
+		zap.String("result_channel", bc.config.ResultChannel),
+		zap.String("result_stream", bc.config.ResultStream),
+	)
+
+	requests, err := bc.transport.Subscribe(bc.ctx, bc.config.RequestChannel)
+	if err != nil {
+		bc.mu.Lock()
+		bc.running = false
+		bc.mu.Unlock()
+		return fmt.Errorf("failed to subscribe to %s: %w", bc.config.RequestChannel, err)
+	}
+
+	bc.pool.start(bc.ctx, &bc.wg)
+	bc.wg.Add(1)
+	go bc.dispatch(requests)
+
+	bc.logger.Info("Bridge consumer started successfully")
+	return nil
+}
+
+// Stop stops the bridge consumer
+func (bc *BridgeConsumer) Stop() {
+	bc.mu.Lock()
+	if !bc.running {
+		bc.mu.Unlock()
+		return
+	}
+	bc.running = false
+	bc.mu.Unlock()
+
+	bc.logger.Info("Stopping bridge consumer")
+
+	// Cancel context to stop workers
+	bc.cancel()
+
+	// Wait for all workers to finish
+	bc.wg.Wait()
+
+	bc.logger.Info("Bridge consumer stopped")
+}
+
+// dispatch hands each request to the worker pool, waiting for a free slot
+// so that a burst of requests is buffered by the subscription rather than
+// dropped
+func (bc *BridgeConsumer) dispatch(requests <-chan []byte) {
+	defer bc.wg.Done()
+
+	for {
+		select {
+		case payload, ok := <-requests:
+			if !ok {
+				return
+			}
+			if !bc.pool.submit(bc.ctx, func(ctx context.Context) { bc.handle(ctx, payload) }) {
+				return
+			}
+			if bc.metrics != nil {
+				bc.metrics.UpdateConsumerInFlight("bridge", float64(bc.pool.inFlight()))
+			}
+		case <-bc.ctx.Done():
+			return
+		}
+	}
+}
+
+// handle analyzes one request and publishes the response
+func (bc *BridgeConsumer) handle(ctx context.Context, payload []byte) {
+	start := time.Now()
+	request := DecodeBridgeRequest(payload)
+	response := &models.AnalysisResponse{ID: request.ID}
+
+	source, err := bc.resolveSource(request.Source)
+	if err == nil {
+		response.Source = source
+		response.Result, err = bc.analyze(request)
+	}
+	if err != nil {
+		response.Error = err.Error()
+		bc.logger.Warn("Bridge analysis failed", zap.String("id", request.ID), zap.Error(err))
+	} else {
+		bc.writeToSink(ctx, request, source, response.Result)
+	}
+	response.Duration = time.Since(start)
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		bc.logger.Error("Failed to encode bridge result", zap.String("id", request.ID), zap.Error(err))
+		return
+	}
+
+	if bc.config.ResultChannel != "" {
+		if err := bc.transport.Publish(ctx, bc.config.ResultChannel, encoded); err != nil {
+			bc.logger.Error("Failed to publish bridge result",
+				zap.String("id", request.ID),
+				zap.String("channel", bc.config.ResultChannel),
+				zap.Error(err),
+			)
+		}
+	}
+	if bc.config.ResultStream != "" {
+		if err := bc.transport.Append(ctx, bc.config.ResultStream, request.ID, encoded); err != nil {
+			bc.logger.Error("Failed to append bridge result",
+				zap.String("id", request.ID),
+				zap.String("stream", bc.config.ResultStream),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// resolveSource returns the source to tag a request naming source with
+func (bc *BridgeConsumer) resolveSource(source string) (string, error) {
+	bc.mu.RLock()
+	provenance := bc.provenance
+	bc.mu.RUnlock()
+
+	resolved, err := provenance.Resolve(source)
+	if err != nil {
+		return "", err
+	}
+	if resolved == "" {
+		resolved = "worker"
+	}
+	return resolved, nil
+}
+
+// writeToSink passes the result for a request on to the configured sink, if
+// any
+func (bc *BridgeConsumer) writeToSink(ctx context.Context, request *models.AnalysisRequest, source string, result *models.AnomalyResult) {
+	bc.mu.RLock()
+	s := bc.sink
+	bc.mu.RUnlock()
+	if s == nil {
+		return
+	}
+
+	if err := s.Write(ctx, sink.NewRecord(request.ID, source, result).WithInput(request.Text)); err != nil {
+		bc.logger.Error("Failed to write bridge result to sink", zap.String("id", request.ID), zap.Error(err))
+	}
+}
+
+// analyze runs detection on the request, honouring a "content_type" option
+func (bc *BridgeConsumer) analyze(request *models.AnalysisRequest) (*models.AnomalyResult, error) {
+	contentType, err := core.ParseContentType(request.Options["content_type"])
+	if err != nil {
+		return nil, err
+	}
+	return bc.detector.AnalyzeTextAs(request.Text, contentType)
+}
+
+// DecodeBridgeRequest reads a request published on the bridge channel. JSON
+// objects are analysis requests; anything else is taken as the text itself.
+// Requests without an ID are given one so that their results can be told
+// apart.
+func DecodeBridgeRequest(payload []byte) *models.AnalysisRequest {
+	var request models.AnalysisRequest
+	if err := json.Unmarshal(payload, &request); err != nil {
+		request = models.AnalysisRequest{Text: string(payload)}
+	}
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	}
+	return &request
+}
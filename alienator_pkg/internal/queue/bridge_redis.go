@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisBridgeTransport carries bridge traffic over Redis pub/sub, the
+// transport the simple API publishes on, with results optionally appended
+// to a Redis stream for consumers that were not subscribed at the time
+type RedisBridgeTransport struct {
+	client *redis.Client
+}
+
+// NewRedisBridgeTransport creates a bridge transport on a Redis client
+func NewRedisBridgeTransport(client *redis.Client) *RedisBridgeTransport {
+	return &RedisBridgeTransport{client: client}
+}
+
+// Subscribe implements BridgeTransport
+func (t *RedisBridgeTransport) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	pubsub := t.client.Subscribe(ctx, channel)
+	// Wait for the subscription to be confirmed so that connection errors
+	// surface here rather than as a silent, empty channel
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to Redis channel %s: %w", channel, err)
+	}
+
+	payloads := make(chan []byte)
+	go func() {
+		defer close(payloads)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case payloads <- []byte(message.Payload):
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return payloads, nil
+}
+
+// Publish implements BridgeTransport
+func (t *RedisBridgeTransport) Publish(ctx context.Context, channel string, payload []byte) error {
+	return t.client.Publish(ctx, channel, payload).Err()
+}
+
+// Append implements BridgeTransport
+func (t *RedisBridgeTransport) Append(ctx context.Context, stream, id string, payload []byte) error {
+	return t.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		Values: map[string]interface{}{"id": id, "response": payload},
+	}).Err()
+}
@@ -6,15 +6,28 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ruvnet/alienator/internal/config"
 	"github.com/ruvnet/alienator/internal/core"
 	"github.com/ruvnet/alienator/internal/services"
+	"github.com/ruvnet/alienator/internal/sink"
+	"github.com/ruvnet/alienator/pkg/metrics"
 	"go.uber.org/zap"
 )
 
+// metricsReportInterval is how often consumers publish in-flight and queue depth gauges
+const metricsReportInterval = 10 * time.Second
+
 // MessageConsumer consumes messages from queues for processing
 type MessageConsumer struct {
-	detector          core.AnomalyDetector
+	detector          *core.AnomalyDetector
 	processingService *services.ProcessingService
+	config            config.ConsumerConfig
+	sink              sink.ResultSink
+	occurrences       services.OccurrenceStore
+	provenance        config.ProvenanceConfig
+	alerts            AlertBroadcaster
+	alertChannels     []string
+	metrics           *metrics.Metrics
 	logger            *zap.Logger
 	
 	// Consumer management
@@ -26,18 +39,57 @@ type MessageConsumer struct {
 }
 
 // NewMessageConsumer creates a new message consumer
-func NewMessageConsumer(detector core.AnomalyDetector, processingService *services.ProcessingService, logger *zap.Logger) *MessageConsumer {
+func NewMessageConsumer(detector *core.AnomalyDetector, processingService *services.ProcessingService, cfg config.ConsumerConfig, metrics *metrics.Metrics, logger *zap.Logger) *MessageConsumer {
 	ctx, cancel := context.WithCancel(context.Background())
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
 	
 	return &MessageConsumer{
 		detector:          detector,
 		processingService: processingService,
+		config:            cfg,
+		metrics:           metrics,
 		logger:            logger,
 		ctx:               ctx,
 		cancel:            cancel,
 	}
 }
 
+// SetSink writes the result of every analysis request taken off the queues
+// to s before the request is acknowledged. It must be called before Start.
+func (mc *MessageConsumer) SetSink(s sink.ResultSink) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.sink = s
+}
+
+// SetOccurrenceStore persists the occurrence counts of the anomalies kept
+// by de-duplication to s. It must be called before Start.
+func (mc *MessageConsumer) SetOccurrenceStore(s services.OccurrenceStore) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.occurrences = s
+}
+
+// SetProvenance sets the sources analysis requests are tagged with: the
+// default for requests naming none, and the only ones accepted if listed.
+// It must be called before Start.
+func (mc *MessageConsumer) SetProvenance(cfg config.ProvenanceConfig) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.provenance = cfg
+}
+
+// SetAlerts broadcasts an alert through alerts to each of channels for every
+// anomalous result. It must be called before Start.
+func (mc *MessageConsumer) SetAlerts(alerts AlertBroadcaster, channels []string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.alerts = alerts
+	mc.alertChannels = channels
+}
+
 // Start starts the message consumer
 func (mc *MessageConsumer) Start(ctx context.Context) error {
 	mc.mu.Lock()
@@ -46,13 +98,28 @@ func (mc *MessageConsumer) Start(ctx context.Context) error {
 		return fmt.Errorf("message consumer is already running")
 	}
 	mc.running = true
+	resultSink := mc.sink
+	occurrences := mc.occurrences
+	provenance := mc.provenance
+	alerts, alertChannels := mc.alerts, mc.alertChannels
 	mc.mu.Unlock()
 
-	mc.logger.Info("Starting message consumer")
+	mc.logger.Info("Starting message consumer",
+		zap.Int("workers_per_queue", mc.config.Workers),
+		zap.Int("max_in_flight", mc.config.MaxInFlight),
+		zap.Duration("suppression_window", mc.config.SuppressionWindow),
+	)
+
+	// Bound concurrent processing across all queue workers
+	mc.processingService.SetMaxInFlight(mc.config.MaxInFlight)
+
+	// Count near-identical anomalies instead of passing each one on
+	mc.processingService.SetSuppressionWindow(mc.config.SuppressionWindow)
+	mc.processingService.SetOccurrenceStore(occurrences)
 
 	// Register processors
 	mc.processingService.RegisterProcessor(services.NewValidationProcessor())
-	mc.processingService.RegisterProcessor(services.NewBasicMessageProcessor("anomaly_detection"))
+	mc.processingService.RegisterProcessor(newDetectionProcessor(mc.detector, resultSink, provenance, alerts, alertChannels, mc.logger))
 	mc.processingService.RegisterProcessor(services.NewEnrichmentProcessor())
 
 	// Start processing workers for different queues
@@ -64,10 +131,15 @@ func (mc *MessageConsumer) Start(ctx context.Context) error {
 	}
 
 	for _, queueName := range queues {
-		mc.wg.Add(1)
-		go mc.processQueueWorker(queueName)
+		for i := 0; i < mc.config.Workers; i++ {
+			mc.wg.Add(1)
+			go mc.processQueueWorker(queueName)
+		}
 	}
 
+	mc.wg.Add(1)
+	go mc.reportMetrics(queues)
+
 	mc.logger.Info("Message consumer started successfully")
 	return nil
 }
@@ -112,9 +184,36 @@ func (mc *MessageConsumer) processQueueWorker(queueName string) {
 	mc.logger.Info("Queue worker stopped", zap.String("queue", queueName))
 }
 
+// reportMetrics periodically publishes in-flight and queue depth gauges
+func (mc *MessageConsumer) reportMetrics(queues []string) {
+	defer mc.wg.Done()
+
+	ticker := time.NewTicker(metricsReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			mc.metrics.UpdateConsumerInFlight("message", float64(mc.processingService.GetMetrics().InFlight))
+			for _, queueName := range queues {
+				depth, err := mc.processingService.GetQueueDepth(queueName)
+				if err != nil {
+					mc.logger.Debug("Failed to get queue depth", zap.String("queue", queueName), zap.Error(err))
+					continue
+				}
+				mc.metrics.UpdateQueueDepth(queueName, float64(depth))
+			}
+		case <-mc.ctx.Done():
+			return
+		}
+	}
+}
+
 // BroadcastConsumer consumes broadcast messages
 type BroadcastConsumer struct {
 	broadcastService *services.BroadcastService
+	pool             *workerPool
+	metrics          *metrics.Metrics
 	logger           *zap.Logger
 	
 	// Consumer management
@@ -126,11 +225,13 @@ type BroadcastConsumer struct {
 }
 
 // NewBroadcastConsumer creates a new broadcast consumer
-func NewBroadcastConsumer(broadcastService *services.BroadcastService, logger *zap.Logger) *BroadcastConsumer {
+func NewBroadcastConsumer(broadcastService *services.BroadcastService, cfg config.ConsumerConfig, metrics *metrics.Metrics, logger *zap.Logger) *BroadcastConsumer {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	return &BroadcastConsumer{
 		broadcastService: broadcastService,
+		pool:             newWorkerPool(cfg),
+		metrics:          metrics,
 		logger:           logger,
 		ctx:              ctx,
 		cancel:           cancel,
@@ -150,6 +251,7 @@ func (bc *BroadcastConsumer) Start(ctx context.Context) error {
 	bc.logger.Info("Starting broadcast consumer")
 
 	// Start broadcast monitoring
+	bc.pool.start(bc.ctx, &bc.wg)
 	bc.wg.Add(1)
 	go bc.monitorBroadcasts()
 
@@ -188,22 +290,33 @@ func (bc *BroadcastConsumer) monitorBroadcasts() {
 	for {
 		select {
 		case <-ticker.C:
-			metrics := bc.broadcastService.GetMetrics()
-			bc.logger.Debug("Broadcast metrics",
-				zap.Int64("total_channels", metrics.TotalChannels),
-				zap.Int64("total_subscriptions", metrics.TotalSubscriptions),
-				zap.Int64("messages_broadcast", metrics.MessagesBroadcast),
-				zap.Int64("messages_delivered", metrics.MessagesDelivered),
-			)
+			if !bc.pool.trySubmit(bc.logBroadcastMetrics) {
+				bc.logger.Debug("Broadcast consumer at max in-flight, skipping metrics snapshot")
+			}
+			bc.metrics.UpdateConsumerInFlight("broadcast", float64(bc.pool.inFlight()))
+			bc.metrics.UpdateQueueDepth("broadcast", float64(bc.pool.queued()))
 		case <-bc.ctx.Done():
 			return
 		}
 	}
 }
 
+// logBroadcastMetrics logs a snapshot of broadcast service metrics
+func (bc *BroadcastConsumer) logBroadcastMetrics(ctx context.Context) {
+	metrics := bc.broadcastService.GetMetrics()
+	bc.logger.Debug("Broadcast metrics",
+		zap.Int64("total_channels", metrics.TotalChannels),
+		zap.Int64("total_subscriptions", metrics.TotalSubscriptions),
+		zap.Int64("messages_broadcast", metrics.MessagesBroadcast),
+		zap.Int64("messages_delivered", metrics.MessagesDelivered),
+	)
+}
+
 // StreamConsumer consumes stream messages
 type StreamConsumer struct {
 	streamService *services.StreamService
+	pool          *workerPool
+	metrics       *metrics.Metrics
 	logger        *zap.Logger
 	
 	// Consumer management
@@ -215,11 +328,13 @@ type StreamConsumer struct {
 }
 
 // NewStreamConsumer creates a new stream consumer
-func NewStreamConsumer(streamService *services.StreamService, logger *zap.Logger) *StreamConsumer {
+func NewStreamConsumer(streamService *services.StreamService, cfg config.ConsumerConfig, metrics *metrics.Metrics, logger *zap.Logger) *StreamConsumer {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	return &StreamConsumer{
 		streamService: streamService,
+		pool:          newWorkerPool(cfg),
+		metrics:       metrics,
 		logger:        logger,
 		ctx:           ctx,
 		cancel:        cancel,
@@ -239,6 +354,7 @@ func (sc *StreamConsumer) Start(ctx context.Context) error {
 	sc.logger.Info("Starting stream consumer")
 
 	// Start stream monitoring and metrics calculation
+	sc.pool.start(sc.ctx, &sc.wg)
 	sc.wg.Add(1)
 	go sc.monitorStreams()
 
@@ -287,6 +403,8 @@ func (sc *StreamConsumer) monitorStreams() {
 				zap.Int64("messages_streamed", metrics.MessagesStreamed),
 				zap.Int64("bytes_streamed", metrics.BytesStreamed),
 			)
+			sc.metrics.UpdateConsumerInFlight("stream", float64(sc.pool.inFlight()))
+			sc.metrics.UpdateQueueDepth("stream", float64(sc.pool.queued()))
 		case <-sc.ctx.Done():
 			return
 		}
@@ -303,7 +421,10 @@ func (sc *StreamConsumer) calculateMetrics() {
 	for {
 		select {
 		case <-ticker.C:
-			sc.streamService.CalculateMetrics(sc.ctx)
+			// Skip the tick rather than pile up calculations when they run long
+			if !sc.pool.trySubmit(sc.streamService.CalculateMetrics) {
+				sc.logger.Debug("Stream consumer at max in-flight, skipping metrics calculation")
+			}
 		case <-sc.ctx.Done():
 			return
 		}
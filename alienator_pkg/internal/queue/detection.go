@@ -0,0 +1,118 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ruvnet/alienator/internal/alert"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/models/proto"
+	"github.com/ruvnet/alienator/internal/sink"
+	"go.uber.org/zap"
+)
+
+// AlertBroadcaster renders anomaly alerts with the channel's template and
+// broadcasts them, as services.BroadcastService does
+type AlertBroadcaster interface {
+	NewAlert(id string, result *models.AnomalyResult, text string) *alert.Alert
+	BroadcastAlert(ctx context.Context, channelID string, a *alert.Alert) error
+}
+
+// detectionProcessor analyzes queued messages carrying a JSON
+// models.AnalysisRequest and writes each result to a sink. A message is
+// only acknowledged once the sink has taken its result; when the write
+// fails the message is requeued instead. Other messages are passed on
+// untouched. Results are tagged with the request's source as resolved by
+// the provenance configuration, "worker" if it names none and there is no
+// default; a request naming a source that isn't accepted fails. Anomalous
+// results are also broadcast as alerts to the alert channels; a failed
+// broadcast is logged without failing the message.
+type detectionProcessor struct {
+	detector      *core.AnomalyDetector
+	sink          sink.ResultSink
+	provenance    config.ProvenanceConfig
+	alerts        AlertBroadcaster
+	alertChannels []string
+	logger        *zap.Logger
+}
+
+// newDetectionProcessor creates a detection processor; s may be nil to
+// analyze without keeping the results, and alerts nil to send no alerts
+func newDetectionProcessor(detector *core.AnomalyDetector, s sink.ResultSink, provenance config.ProvenanceConfig, alerts AlertBroadcaster, alertChannels []string, logger *zap.Logger) *detectionProcessor {
+	return &detectionProcessor{
+		detector:      detector,
+		sink:          s,
+		provenance:    provenance,
+		alerts:        alerts,
+		alertChannels: alertChannels,
+		logger:        logger,
+	}
+}
+
+// Name implements services.MessageProcessor
+func (dp *detectionProcessor) Name() string {
+	return "anomaly_detection"
+}
+
+// Process implements services.MessageProcessor
+func (dp *detectionProcessor) Process(ctx context.Context, msg *proto.Message) (*proto.Message, error) {
+	var request models.AnalysisRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil || request.Text == "" {
+		return msg, nil
+	}
+	if request.ID == "" {
+		request.ID = msg.ID
+	}
+	source, err := dp.provenance.Resolve(request.Source)
+	if err != nil {
+		return nil, err
+	}
+	if source == "" {
+		source = "worker"
+	}
+
+	contentType, err := core.ParseContentType(request.Options["content_type"])
+	if err != nil {
+		return nil, err
+	}
+	result, err := dp.detector.AnalyzeTextAs(request.Text, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if dp.sink != nil {
+		if err := dp.sink.Write(ctx, sink.NewRecord(request.ID, source, result).WithInput(request.Text)); err != nil {
+			return nil, fmt.Errorf("failed to write result to %s: %w", dp.sink.Name(), err)
+		}
+	}
+
+	if result.IsAnomalous {
+		dp.broadcastAlerts(ctx, &request, result)
+	}
+
+	if msg.Headers == nil {
+		msg.Headers = make(map[string]string)
+	}
+	msg.Headers["anomaly_score"] = fmt.Sprintf("%g", result.Score)
+	return msg, nil
+}
+
+// broadcastAlerts renders an alert for an anomalous result and broadcasts it
+// to every alert channel
+func (dp *detectionProcessor) broadcastAlerts(ctx context.Context, request *models.AnalysisRequest, result *models.AnomalyResult) {
+	if dp.alerts == nil || len(dp.alertChannels) == 0 {
+		return
+	}
+	a := dp.alerts.NewAlert(request.ID, result, request.Text)
+	for _, channel := range dp.alertChannels {
+		if err := dp.alerts.BroadcastAlert(ctx, channel, a); err != nil {
+			dp.logger.Warn("Failed to broadcast anomaly alert",
+				zap.String("id", request.ID),
+				zap.String("channel", channel),
+				zap.Error(err),
+			)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/ruvnet/alienator/internal/services"
+)
+
+// saveOccurrenceScript replaces the stored occurrence unless it has a higher
+// count, then sets the key to expire ARGV[3] milliseconds later, or never
+// when that is zero
+var saveOccurrenceScript = redis.NewScript(`
+local stored = redis.call('GET', KEYS[1])
+if stored and cjson.decode(stored).occurrence_count > tonumber(ARGV[2]) then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// RedisOccurrenceStore keeps each occurrence as a JSON document under the ID
+// of the anomaly kept for it, expiring retention after the last save
+type RedisOccurrenceStore struct {
+	client    *redis.Client
+	prefix    string
+	retention time.Duration
+}
+
+// NewRedisOccurrenceStore creates an occurrence store on a Redis client
+func NewRedisOccurrenceStore(client *redis.Client, prefix string, retention time.Duration) *RedisOccurrenceStore {
+	return &RedisOccurrenceStore{client: client, prefix: prefix, retention: retention}
+}
+
+// SaveOccurrence implements services.OccurrenceStore
+func (s *RedisOccurrenceStore) SaveOccurrence(ctx context.Context, occurrence services.Occurrence) error {
+	encoded, err := json.Marshal(occurrence)
+	if err != nil {
+		return fmt.Errorf("failed to encode occurrence: %w", err)
+	}
+	keys := []string{s.prefix + occurrence.FirstID}
+	return saveOccurrenceScript.Run(ctx, s.client, keys, encoded, occurrence.Count, s.retention.Milliseconds()).Err()
+}
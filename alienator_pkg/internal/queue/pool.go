@@ -0,0 +1,87 @@
+package queue
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ruvnet/alienator/internal/config"
+)
+
+// workerPool runs submitted tasks on a fixed number of goroutines and bounds
+// how many tasks may be queued or running at once
+type workerPool struct {
+	workers int
+	slots   chan struct{}
+	tasks   chan func(context.Context)
+}
+
+// newWorkerPool creates a worker pool from consumer configuration
+func newWorkerPool(cfg config.ConsumerConfig) *workerPool {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	maxInFlight := cfg.MaxInFlight
+	if maxInFlight < workers {
+		maxInFlight = workers
+	}
+
+	return &workerPool{
+		workers: workers,
+		slots:   make(chan struct{}, maxInFlight),
+		tasks:   make(chan func(context.Context), maxInFlight),
+	}
+}
+
+// start launches the pool's workers, which exit when the context is done
+func (p *workerPool) start(ctx context.Context, wg *sync.WaitGroup) {
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case task := <-p.tasks:
+					task(ctx)
+					<-p.slots
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// trySubmit queues a task if the in-flight limit allows it and reports
+// whether the task was accepted
+func (p *workerPool) trySubmit(task func(context.Context)) bool {
+	select {
+	case p.slots <- struct{}{}:
+		p.tasks <- task
+		return true
+	default:
+		return false
+	}
+}
+
+// submit queues a task, waiting for the in-flight limit to allow it, and
+// reports false if the context is done first
+func (p *workerPool) submit(ctx context.Context, task func(context.Context)) bool {
+	select {
+	case p.slots <- struct{}{}:
+		p.tasks <- task
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// inFlight returns the number of tasks queued or running
+func (p *workerPool) inFlight() int {
+	return len(p.slots)
+}
+
+// queued returns the number of tasks waiting for a worker
+func (p *workerPool) queued() int {
+	return len(p.tasks)
+}
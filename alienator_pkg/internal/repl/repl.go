@@ -0,0 +1,406 @@
+// Package repl implements the interactive analysis session of the CLI: text
+// typed or pasted at the prompt is scored immediately, and commands starting
+// with ':' change which analyzers run, the decision threshold and the content
+// type, explain results and list the recent ones.
+package repl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// DefaultScrollback is how many recent results a session keeps
+const DefaultScrollback = 20
+
+// excerptLength is how many characters of each input the history shows
+const excerptLength = 40
+
+// pasteEnd ends a multi-line paste
+const pasteEnd = "."
+
+// Entry is one analyzed input kept in the scrollback
+type Entry struct {
+	// Number counts the inputs of the session, starting at 1
+	Number int
+	Text   string
+	Result *models.AnomalyResult
+}
+
+// Session is an interactive analysis session over a detector
+type Session struct {
+	detector    *core.AnomalyDetector
+	out         io.Writer
+	contentType core.ContentType
+	disabled    map[string]bool
+	scrollback  int
+	history     []Entry
+	count       int
+}
+
+// NewSession starts a session writing to out, with every analyzer enabled
+// and the detector's own thresholds
+func NewSession(detector *core.AnomalyDetector, out io.Writer) *Session {
+	return &Session{
+		detector:    detector,
+		out:         out,
+		contentType: core.ContentTypeAuto,
+		disabled:    make(map[string]bool),
+		scrollback:  DefaultScrollback,
+	}
+}
+
+// SetScrollback changes how many recent results are kept
+func (s *Session) SetScrollback(size int) {
+	if size < 1 {
+		size = 1
+	}
+	s.scrollback = size
+	s.trimHistory()
+}
+
+// History returns the recent results, oldest first
+func (s *Session) History() []Entry {
+	return append([]Entry(nil), s.history...)
+}
+
+// Run reads lines from in until it ends or the user quits, analyzing text
+// and running commands as they come, with a prompt before each line if
+// prompt is set. Between ":paste" and a line holding only "." lines are
+// collected and analyzed as one text.
+func (s *Session) Run(in io.Reader, prompt bool) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var pasted []string
+	pasting := false
+	for {
+		if prompt {
+			if pasting {
+				fmt.Fprint(s.out, "... ")
+			} else {
+				fmt.Fprint(s.out, "alienator> ")
+			}
+		}
+		if !scanner.Scan() {
+			break
+		}
+		line := scanner.Text()
+
+		if pasting {
+			if strings.TrimSpace(line) != pasteEnd {
+				pasted = append(pasted, line)
+				continue
+			}
+			pasting = false
+			s.analyze(strings.Join(pasted, "\n"))
+			pasted = nil
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case trimmed == ":paste":
+			pasting = true
+			fmt.Fprintf(s.out, "Pasting; end with a line holding only %q\n", pasteEnd)
+		case strings.HasPrefix(trimmed, ":"):
+			if quit := s.Command(trimmed); quit {
+				return nil
+			}
+		default:
+			s.analyze(line)
+		}
+	}
+	if pasting && len(pasted) > 0 {
+		s.analyze(strings.Join(pasted, "\n"))
+	}
+	return scanner.Err()
+}
+
+// Command runs a ':' command and reports whether the session should end
+func (s *Session) Command(line string) bool {
+	fields := strings.Fields(strings.TrimPrefix(line, ":"))
+	if len(fields) == 0 {
+		s.help()
+		return false
+	}
+	name, args := fields[0], fields[1:]
+
+	var err error
+	switch name {
+	case "quit", "exit", "q":
+		return true
+	case "help", "h", "?":
+		s.help()
+	case "enable":
+		err = s.setEnabled(args, true)
+	case "disable":
+		err = s.setEnabled(args, false)
+	case "analyzers":
+		s.listAnalyzers()
+	case "threshold":
+		err = s.setThreshold(args)
+	case "type":
+		err = s.setContentType(args)
+	case "explain":
+		err = s.explain(args)
+	case "history":
+		s.showHistory()
+	default:
+		err = fmt.Errorf("unknown command :%s (type :help for the list)", name)
+	}
+	if err != nil {
+		fmt.Fprintf(s.out, "error: %v\n", err)
+	}
+	return false
+}
+
+// analyze scores text with the enabled analyzers and adds it to the history
+func (s *Session) analyze(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	result, err := s.detector.AnalyzeTextWith(context.Background(), text, s.contentType, s.selection())
+	if err != nil {
+		fmt.Fprintf(s.out, "error: analysis failed: %v\n", err)
+		return
+	}
+
+	s.count++
+	s.history = append(s.history, Entry{Number: s.count, Text: text, Result: result})
+	s.trimHistory()
+
+	verdict := "human"
+	if result.IsAnomalous {
+		verdict = "ANOMALOUS"
+	}
+	fmt.Fprintf(s.out, "#%d score %.3f  confidence %.2f  %s  severity %s  (%v, threshold %v)\n",
+		s.count, result.Score, result.Confidence, verdict, result.Severity,
+		result.Metadata["content_type"], result.Metadata["threshold"])
+
+	names := make([]string, 0, len(result.Details))
+	for name := range result.Details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(s.out, "    %-14s %.3f\n", name, result.Details[name].Score)
+	}
+}
+
+// selection returns the selection running the enabled analyzers
+func (s *Session) selection() core.Selection {
+	if len(s.disabled) == 0 {
+		return core.Selection{}
+	}
+	var enabled []string
+	for _, name := range s.detector.AnalyzerNames() {
+		if !s.disabled[name] {
+			enabled = append(enabled, name)
+		}
+	}
+	return core.Selection{Analyzers: enabled}
+}
+
+// setEnabled enables or disables the named analyzers. A name may be
+// abbreviated to any unambiguous prefix, e.g. "crypto" for "cryptographic".
+func (s *Session) setEnabled(args []string, enabled bool) error {
+	if len(args) == 0 {
+		return fmt.Errorf("name the analyzers, one of %s", strings.Join(s.detector.AnalyzerNames(), ", "))
+	}
+
+	names := make([]string, 0, len(args))
+	for _, arg := range args {
+		name, err := s.resolveAnalyzer(arg)
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+
+	disabled := make(map[string]bool, len(s.disabled))
+	for name := range s.disabled {
+		disabled[name] = true
+	}
+	for _, name := range names {
+		if enabled {
+			delete(disabled, name)
+		} else {
+			disabled[name] = true
+		}
+	}
+	if len(disabled) == len(s.detector.AnalyzerNames()) {
+		return fmt.Errorf("at least one analyzer must stay enabled")
+	}
+	s.disabled = disabled
+	s.listAnalyzers()
+	return nil
+}
+
+// resolveAnalyzer expands an analyzer name or unambiguous prefix
+func (s *Session) resolveAnalyzer(prefix string) (string, error) {
+	var matches []string
+	for _, name := range s.detector.AnalyzerNames() {
+		if name == prefix {
+			return name, nil
+		}
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return "", fmt.Errorf("unknown analyzer %q (registered: %s)", prefix, strings.Join(s.detector.AnalyzerNames(), ", "))
+	default:
+		return "", fmt.Errorf("%q is ambiguous: %s", prefix, strings.Join(matches, ", "))
+	}
+}
+
+// listAnalyzers prints the analyzers and whether each is enabled
+func (s *Session) listAnalyzers() {
+	for _, name := range s.detector.AnalyzerNames() {
+		state := "on"
+		if s.disabled[name] {
+			state = "off"
+		}
+		fmt.Fprintf(s.out, "    %-14s %s\n", name, state)
+	}
+}
+
+// setThreshold sets the decision threshold of every content type, or with
+// two arguments of one
+func (s *Session) setThreshold(args []string) error {
+	thresholds := s.detector.DecisionThresholds()
+	if len(args) == 0 {
+		fmt.Fprintf(s.out, "    %s\n", thresholds)
+		return nil
+	}
+	if len(args) > 2 {
+		return fmt.Errorf("usage: :threshold [content type] <value>")
+	}
+
+	value, err := strconv.ParseFloat(args[len(args)-1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid threshold %q", args[len(args)-1])
+	}
+	if len(args) == 2 {
+		contentType, err := core.ParseContentType(args[0])
+		if err != nil {
+			return err
+		}
+		if contentType == core.ContentTypeAuto {
+			return fmt.Errorf("name a content type: prose, code, data or log")
+		}
+		thresholds[contentType] = value
+	} else {
+		for contentType := range thresholds {
+			thresholds[contentType] = value
+		}
+	}
+	if err := s.detector.SetDecisionThresholds(thresholds); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "    %s\n", thresholds)
+	return nil
+}
+
+// setContentType sets the content type inputs are analyzed as
+func (s *Session) setContentType(args []string) error {
+	if len(args) != 1 {
+		fmt.Fprintf(s.out, "    %s\n", s.contentType)
+		return nil
+	}
+	contentType, err := core.ParseContentType(args[0])
+	if err != nil {
+		return err
+	}
+	s.contentType = contentType
+	return nil
+}
+
+// explain explains the last result, or the numbered one
+func (s *Session) explain(args []string) error {
+	entry, err := s.entry(args)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "#%d %s\n", entry.Number, s.detector.ExplainText(entry.Result))
+	return nil
+}
+
+// entry returns the last entry, or the one numbered by the argument
+func (s *Session) entry(args []string) (Entry, error) {
+	if len(s.history) == 0 {
+		return Entry{}, fmt.Errorf("nothing analyzed yet")
+	}
+	if len(args) == 0 {
+		return s.history[len(s.history)-1], nil
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid result number %q", args[0])
+	}
+	for _, entry := range s.history {
+		if entry.Number == number {
+			return entry, nil
+		}
+	}
+	return Entry{}, fmt.Errorf("result #%d is not in the scrollback", number)
+}
+
+// showHistory prints the recent results side by side
+func (s *Session) showHistory() {
+	if len(s.history) == 0 {
+		fmt.Fprintln(s.out, "    nothing analyzed yet")
+		return
+	}
+	for _, entry := range s.history {
+		mark := " "
+		if entry.Result.IsAnomalous {
+			mark = "!"
+		}
+		fmt.Fprintf(s.out, "  %s #%-3d %.3f  %-8s  %s\n", mark, entry.Number, entry.Result.Score, entry.Result.Severity, excerpt(entry.Text))
+	}
+}
+
+// trimHistory drops the oldest entries beyond the scrollback
+func (s *Session) trimHistory() {
+	if extra := len(s.history) - s.scrollback; extra > 0 {
+		s.history = append([]Entry(nil), s.history[extra:]...)
+	}
+}
+
+// help lists the commands
+func (s *Session) help() {
+	fmt.Fprint(s.out, `Type or paste text to score it. Commands:
+    :paste                      analyze the following lines as one text, up to a line holding "."
+    :enable <analyzer>...       run these analyzers (names may be abbreviated)
+    :disable <analyzer>...      skip these analyzers
+    :analyzers                  list the analyzers and whether each is enabled
+    :threshold [type] <value>   set the decision threshold, for every content type or one
+    :type <content type>        analyze as auto, prose, code, data or log
+    :explain [n]                explain the last result, or result #n
+    :history                    list the recent results
+    :quit                       end the session
+`)
+}
+
+// excerpt returns the start of text on one line
+func excerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= excerptLength {
+		return text
+	}
+	return string([]rune(text)[:excerptLength]) + "…"
+}
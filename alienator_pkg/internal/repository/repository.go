@@ -3,11 +3,14 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 	"github.com/ruvnet/alienator/internal/config"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
 	"github.com/ruvnet/alienator/internal/models"
 	"go.uber.org/zap"
 )
@@ -26,9 +29,37 @@ type Repository interface {
 	// AnomalyData methods
 	CreateAnomalyData(data *models.AnomalyData) error
 	GetAnomalyDataByID(id uuid.UUID) (*models.AnomalyData, error)
-	GetAnomalyDataByUserID(userID uuid.UUID, page, limit int) ([]*models.AnomalyData, int, error)
-	ListAnomalyData(page, limit int) ([]*models.AnomalyData, int, error)
+	GetAnomalyDataByUserID(userID uuid.UUID, source string, page, limit int) ([]*models.AnomalyData, int, error)
+	ListAnomalyData(source string, page, limit int) ([]*models.AnomalyData, int, error)
 	DeleteAnomalyData(id uuid.UUID) error
+	GetAnomalyStatsTimeSeries(query models.StatsQuery) ([]*models.StatsBucket, error)
+	ListNormalAnomalyData(since time.Time, limit int) ([]*models.AnomalyData, error)
+	SearchAnomalyData(search models.AnomalySearch, page, limit int) ([]*models.AnomalySearchResult, int, error)
+
+	// DetectionProfile methods
+	CreateDetectionProfile(profile *models.DetectionProfile) error
+	GetDetectionProfileByName(name string) (*models.DetectionProfile, error)
+	GetDetectionProfileByAPIKey(keyHash string) (*models.DetectionProfile, error)
+	ListDetectionProfiles() ([]*models.DetectionProfile, error)
+	UpdateDetectionProfile(profile *models.DetectionProfile) error
+	DeleteDetectionProfile(name string) error
+	AssignAPIKeyProfile(keyHash string, profileID uuid.UUID) error
+
+	// AllowlistEntry methods
+	CreateAllowlistEntry(entry *models.AllowlistEntry) error
+	GetAllowlistEntry(id uuid.UUID) (*models.AllowlistEntry, error)
+	ListAllowlistEntries() ([]*models.AllowlistEntry, error)
+	UpdateAllowlistEntry(entry *models.AllowlistEntry) error
+	DeleteAllowlistEntry(id uuid.UUID) error
+
+	// ReviewItem methods
+	CreateReviewItem(item *models.ReviewItem) error
+	GetReviewItem(id uuid.UUID) (*models.ReviewItem, error)
+	ListReviewItems(status string, page, limit int) ([]*models.ReviewItem, int, error)
+	LabelReviewItem(item *models.ReviewItem) error
+
+	// Analysis record methods
+	SaveAnalysisRecord(record *models.AnalysisRecord) error
 
 	// Health check
 	HealthCheck() error
@@ -97,9 +128,69 @@ func (r *postgresRepository) createTables() error {
 			processed_at TIMESTAMP,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);`,
+		`ALTER TABLE anomaly_data ADD COLUMN IF NOT EXISTS signature JSONB;`,
 		`CREATE INDEX IF NOT EXISTS idx_anomaly_data_user_id ON anomaly_data(user_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_anomaly_data_is_anomaly ON anomaly_data(is_anomaly);`,
 		`CREATE INDEX IF NOT EXISTS idx_anomaly_data_created_at ON anomaly_data(created_at);`,
+		// Where the detection's data came from, to filter and group by
+		`ALTER TABLE anomaly_data ADD COLUMN IF NOT EXISTS source VARCHAR(64) NOT NULL DEFAULT '';`,
+		`CREATE INDEX IF NOT EXISTS idx_anomaly_data_source ON anomaly_data(source, created_at);`,
+		// Every string in the detection's data, and its algorithm, is searchable
+		`ALTER TABLE anomaly_data ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+			GENERATED ALWAYS AS (
+				jsonb_to_tsvector('english', data, '["string"]') || to_tsvector('english', COALESCE(algorithm, ''))
+			) STORED;`,
+		`CREATE INDEX IF NOT EXISTS idx_anomaly_data_search ON anomaly_data USING GIN (search_vector);`,
+		`CREATE TABLE IF NOT EXISTS detection_profiles (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			name VARCHAR(50) UNIQUE NOT NULL,
+			description VARCHAR(255) DEFAULT '',
+			algorithm VARCHAR(64) DEFAULT '',
+			threshold DECIMAL(10,8) NOT NULL,
+			weights JSONB NOT NULL DEFAULT '{}',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS api_key_profiles (
+			key_hash CHAR(64) PRIMARY KEY,
+			profile_id UUID NOT NULL REFERENCES detection_profiles(id) ON DELETE CASCADE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS allowlist_entries (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			name VARCHAR(100) NOT NULL,
+			description VARCHAR(255) DEFAULT '',
+			match_type VARCHAR(16) NOT NULL,
+			pattern VARCHAR(1024) NOT NULL,
+			analyzers JSONB NOT NULL DEFAULT '[]',
+			factor DECIMAL(10,8) NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS review_queue (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			anomaly_data_id UUID UNIQUE NOT NULL REFERENCES anomaly_data(id) ON DELETE CASCADE,
+			score DECIMAL(10,8) NOT NULL,
+			threshold DECIMAL(10,8) NOT NULL,
+			is_anomaly BOOLEAN NOT NULL,
+			status VARCHAR(16) NOT NULL DEFAULT 'pending',
+			label VARCHAR(16),
+			note VARCHAR(1024) NOT NULL DEFAULT '',
+			labeled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			labeled_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_review_queue_status ON review_queue(status, created_at);`,
+		`CREATE TABLE IF NOT EXISTS analysis_records (
+			id VARCHAR(255) PRIMARY KEY,
+			source VARCHAR(50) NOT NULL,
+			score DECIMAL(10,8),
+			is_anomalous BOOLEAN DEFAULT false,
+			result JSONB NOT NULL,
+			analyzed_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_analysis_records_analyzed_at ON analysis_records(analyzed_at);`,
 	}
 
 	for _, query := range queries {
@@ -136,7 +227,7 @@ func (r *postgresRepository) GetUserByID(id uuid.UUID) (*models.User, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user %w", apperrors.ErrNotFound)
 		}
 		return nil, err
 	}
@@ -157,7 +248,7 @@ func (r *postgresRepository) GetUserByEmail(email string) (*models.User, error)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user %w", apperrors.ErrNotFound)
 		}
 		return nil, err
 	}
@@ -177,7 +268,7 @@ func (r *postgresRepository) GetUserByUsername(username string) (*models.User, e
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user %w", apperrors.ErrNotFound)
 		}
 		return nil, err
 	}
@@ -246,55 +337,73 @@ func (r *postgresRepository) ListUsers(page, limit int) ([]*models.User, int, er
 // AnomalyData methods implementation
 
 func (r *postgresRepository) CreateAnomalyData(data *models.AnomalyData) error {
+	var signature []byte
+	if data.Signature != nil {
+		var err error
+		if signature, err = json.Marshal(data.Signature); err != nil {
+			return fmt.Errorf("failed to encode result signature: %w", err)
+		}
+	}
+
 	query := `
-		INSERT INTO anomaly_data (user_id, data, score, is_anomaly, threshold, algorithm, processed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO anomaly_data (user_id, data, score, is_anomaly, threshold, algorithm, processed_at, signature, source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at`
 
 	return r.db.QueryRow(query, data.UserID, data.Data, data.Score,
-		data.IsAnomaly, data.Threshold, data.Algorithm, data.ProcessedAt).Scan(
+		data.IsAnomaly, data.Threshold, data.Algorithm, data.ProcessedAt, signature, data.Source).Scan(
 		&data.ID, &data.CreatedAt)
 }
 
 func (r *postgresRepository) GetAnomalyDataByID(id uuid.UUID) (*models.AnomalyData, error) {
 	data := &models.AnomalyData{}
+	var signature []byte
 	query := `
-		SELECT id, user_id, data, score, is_anomaly, threshold, algorithm, processed_at, created_at
+		SELECT id, user_id, data, score, is_anomaly, threshold, algorithm, processed_at, created_at, signature, source
 		FROM anomaly_data WHERE id = $1`
 
 	err := r.db.QueryRow(query, id).Scan(
 		&data.ID, &data.UserID, &data.Data, &data.Score, &data.IsAnomaly,
-		&data.Threshold, &data.Algorithm, &data.ProcessedAt, &data.CreatedAt)
+		&data.Threshold, &data.Algorithm, &data.ProcessedAt, &data.CreatedAt, &signature, &data.Source)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("anomaly data not found")
+			return nil, fmt.Errorf("anomaly data %w", apperrors.ErrNotFound)
 		}
 		return nil, err
 	}
 
+	if signature != nil {
+		data.Signature = &models.Signature{}
+		if err := json.Unmarshal(signature, data.Signature); err != nil {
+			return nil, fmt.Errorf("failed to decode result signature: %w", err)
+		}
+	}
+
 	return data, nil
 }
 
-func (r *postgresRepository) GetAnomalyDataByUserID(userID uuid.UUID, page, limit int) ([]*models.AnomalyData, int, error) {
+// GetAnomalyDataByUserID returns a page of the user's detections, newest
+// first; a non-empty source keeps those from that source only
+func (r *postgresRepository) GetAnomalyDataByUserID(userID uuid.UUID, source string, page, limit int) ([]*models.AnomalyData, int, error) {
 	offset := (page - 1) * limit
 
 	// Get total count
 	var total int
-	countQuery := `SELECT COUNT(*) FROM anomaly_data WHERE user_id = $1`
-	if err := r.db.QueryRow(countQuery, userID).Scan(&total); err != nil {
+	countQuery := `SELECT COUNT(*) FROM anomaly_data WHERE user_id = $1 AND ($2 = '' OR source = $2)`
+	if err := r.db.QueryRow(countQuery, userID, source).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
 	// Get data
 	query := `
-		SELECT id, user_id, data, score, is_anomaly, threshold, algorithm, processed_at, created_at
+		SELECT id, user_id, data, score, is_anomaly, threshold, algorithm, processed_at, created_at, source
 		FROM anomaly_data
-		WHERE user_id = $1
+		WHERE user_id = $1 AND ($2 = '' OR source = $2)
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3`
+		LIMIT $3 OFFSET $4`
 
-	rows, err := r.db.Query(query, userID, limit, offset)
+	rows, err := r.db.Query(query, userID, source, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -304,7 +413,7 @@ func (r *postgresRepository) GetAnomalyDataByUserID(userID uuid.UUID, page, limi
 	for rows.Next() {
 		data := &models.AnomalyData{}
 		err := rows.Scan(&data.ID, &data.UserID, &data.Data, &data.Score,
-			&data.IsAnomaly, &data.Threshold, &data.Algorithm, &data.ProcessedAt, &data.CreatedAt)
+			&data.IsAnomaly, &data.Threshold, &data.Algorithm, &data.ProcessedAt, &data.CreatedAt, &data.Source)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -314,27 +423,30 @@ func (r *postgresRepository) GetAnomalyDataByUserID(userID uuid.UUID, page, limi
 	return anomalyData, total, nil
 }
 
-func (r *postgresRepository) ListAnomalyData(page, limit int) ([]*models.AnomalyData, int, error) {
+// ListAnomalyData returns a page of every user's detections, newest first;
+// a non-empty source keeps those from that source only
+func (r *postgresRepository) ListAnomalyData(source string, page, limit int) ([]*models.AnomalyData, int, error) {
 	offset := (page - 1) * limit
 
 	// Get total count
 	var total int
-	countQuery := `SELECT COUNT(*) FROM anomaly_data`
-	if err := r.db.QueryRow(countQuery).Scan(&total); err != nil {
+	countQuery := `SELECT COUNT(*) FROM anomaly_data WHERE $1 = '' OR source = $1`
+	if err := r.db.QueryRow(countQuery, source).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
 	// Get data
 	query := `
 		SELECT ad.id, ad.user_id, ad.data, ad.score, ad.is_anomaly, ad.threshold, 
-			   ad.algorithm, ad.processed_at, ad.created_at,
+			   ad.algorithm, ad.processed_at, ad.created_at, ad.source,
 			   u.email, u.username, u.first_name, u.last_name
 		FROM anomaly_data ad
 		LEFT JOIN users u ON ad.user_id = u.id
+		WHERE $1 = '' OR ad.source = $1
 		ORDER BY ad.created_at DESC
-		LIMIT $1 OFFSET $2`
+		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.Query(query, limit, offset)
+	rows, err := r.db.Query(query, source, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -345,7 +457,7 @@ func (r *postgresRepository) ListAnomalyData(page, limit int) ([]*models.Anomaly
 		data := &models.AnomalyData{User: &models.User{}}
 		err := rows.Scan(&data.ID, &data.UserID, &data.Data, &data.Score,
 			&data.IsAnomaly, &data.Threshold, &data.Algorithm, &data.ProcessedAt,
-			&data.CreatedAt, &data.User.Email, &data.User.Username,
+			&data.CreatedAt, &data.Source, &data.User.Email, &data.User.Username,
 			&data.User.FirstName, &data.User.LastName)
 		if err != nil {
 			return nil, 0, err
@@ -362,6 +474,506 @@ func (r *postgresRepository) DeleteAnomalyData(id uuid.UUID) error {
 	return err
 }
 
+// GetAnomalyStatsTimeSeries groups the query's detections between From and
+// To into buckets truncated to its Unit, and by source if asked
+func (r *postgresRepository) GetAnomalyStatsTimeSeries(q models.StatsQuery) ([]*models.StatsBucket, error) {
+	source := `''`
+	if q.BySource {
+		source = `source`
+	}
+	query := `
+		SELECT date_trunc($1, created_at) AS bucket,
+			` + source + ` AS bucket_source,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE is_anomaly),
+			COALESCE(AVG(score), 0)
+		FROM anomaly_data
+		WHERE created_at >= $2 AND created_at < $3`
+	args := []interface{}{q.Unit, q.From, q.To}
+
+	filter := func(condition string, value interface{}) {
+		args = append(args, value)
+		query += fmt.Sprintf(" AND %s $%d", condition, len(args))
+	}
+	if q.UserID != nil {
+		filter("user_id =", *q.UserID)
+	}
+	if q.Source != "" {
+		filter("source =", q.Source)
+	}
+	query += `
+		GROUP BY bucket, bucket_source
+		ORDER BY bucket, bucket_source`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]*models.StatsBucket, 0)
+	for rows.Next() {
+		bucket := &models.StatsBucket{}
+		if err := rows.Scan(&bucket.Start, &bucket.Source, &bucket.Total, &bucket.AnomalyCount, &bucket.MeanScore); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// DetectionProfile methods implementation
+
+const detectionProfileColumns = `id, name, description, algorithm, threshold, weights, created_at, updated_at`
+
+func (r *postgresRepository) CreateDetectionProfile(profile *models.DetectionProfile) error {
+	weights, err := json.Marshal(profile.Weights)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile weights: %w", err)
+	}
+
+	query := `
+		INSERT INTO detection_profiles (name, description, algorithm, threshold, weights)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(query, profile.Name, profile.Description, profile.Algorithm,
+		profile.Threshold, weights).Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt)
+}
+
+func (r *postgresRepository) GetDetectionProfileByName(name string) (*models.DetectionProfile, error) {
+	query := `SELECT ` + detectionProfileColumns + ` FROM detection_profiles WHERE name = $1`
+	return r.scanDetectionProfile(r.db.QueryRow(query, name))
+}
+
+func (r *postgresRepository) GetDetectionProfileByAPIKey(keyHash string) (*models.DetectionProfile, error) {
+	query := `
+		SELECT p.id, p.name, p.description, p.algorithm, p.threshold, p.weights, p.created_at, p.updated_at
+		FROM detection_profiles p
+		JOIN api_key_profiles k ON k.profile_id = p.id
+		WHERE k.key_hash = $1`
+	return r.scanDetectionProfile(r.db.QueryRow(query, keyHash))
+}
+
+func (r *postgresRepository) ListDetectionProfiles() ([]*models.DetectionProfile, error) {
+	query := `SELECT ` + detectionProfileColumns + ` FROM detection_profiles ORDER BY name`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*models.DetectionProfile
+	for rows.Next() {
+		profile, err := r.scanDetectionProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+func (r *postgresRepository) UpdateDetectionProfile(profile *models.DetectionProfile) error {
+	weights, err := json.Marshal(profile.Weights)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile weights: %w", err)
+	}
+
+	query := `
+		UPDATE detection_profiles
+		SET description = $2, algorithm = $3, threshold = $4, weights = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE name = $1
+		RETURNING id, created_at, updated_at`
+
+	err = r.db.QueryRow(query, profile.Name, profile.Description, profile.Algorithm,
+		profile.Threshold, weights).Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("detection profile %w", apperrors.ErrNotFound)
+	}
+	return err
+}
+
+func (r *postgresRepository) DeleteDetectionProfile(name string) error {
+	result, err := r.db.Exec(`DELETE FROM detection_profiles WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("detection profile %w", apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *postgresRepository) AssignAPIKeyProfile(keyHash string, profileID uuid.UUID) error {
+	query := `
+		INSERT INTO api_key_profiles (key_hash, profile_id)
+		VALUES ($1, $2)
+		ON CONFLICT (key_hash) DO UPDATE SET profile_id = EXCLUDED.profile_id`
+
+	_, err := r.db.Exec(query, keyHash, profileID)
+	return err
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *postgresRepository) scanDetectionProfile(row rowScanner) (*models.DetectionProfile, error) {
+	profile := &models.DetectionProfile{}
+	var weights []byte
+
+	err := row.Scan(&profile.ID, &profile.Name, &profile.Description, &profile.Algorithm,
+		&profile.Threshold, &weights, &profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("detection profile %w", apperrors.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(weights, &profile.Weights); err != nil {
+		return nil, fmt.Errorf("failed to decode profile weights: %w", err)
+	}
+
+	return profile, nil
+}
+
+// AllowlistEntry methods implementation
+
+const allowlistEntryColumns = `id, name, description, match_type, pattern, analyzers, factor, created_at, updated_at`
+
+func (r *postgresRepository) CreateAllowlistEntry(entry *models.AllowlistEntry) error {
+	analyzers, err := encodeAnalyzers(entry.Analyzers)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO allowlist_entries (name, description, match_type, pattern, analyzers, factor)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(query, entry.Name, entry.Description, entry.MatchType, entry.Pattern,
+		analyzers, entry.Factor).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+}
+
+func (r *postgresRepository) GetAllowlistEntry(id uuid.UUID) (*models.AllowlistEntry, error) {
+	query := `SELECT ` + allowlistEntryColumns + ` FROM allowlist_entries WHERE id = $1`
+	return r.scanAllowlistEntry(r.db.QueryRow(query, id))
+}
+
+func (r *postgresRepository) ListAllowlistEntries() ([]*models.AllowlistEntry, error) {
+	query := `SELECT ` + allowlistEntryColumns + ` FROM allowlist_entries ORDER BY created_at`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AllowlistEntry
+	for rows.Next() {
+		entry, err := r.scanAllowlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *postgresRepository) UpdateAllowlistEntry(entry *models.AllowlistEntry) error {
+	analyzers, err := encodeAnalyzers(entry.Analyzers)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE allowlist_entries
+		SET name = $2, description = $3, match_type = $4, pattern = $5, analyzers = $6, factor = $7,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING created_at, updated_at`
+
+	err = r.db.QueryRow(query, entry.ID, entry.Name, entry.Description, entry.MatchType, entry.Pattern,
+		analyzers, entry.Factor).Scan(&entry.CreatedAt, &entry.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("allowlist entry %w", apperrors.ErrNotFound)
+	}
+	return err
+}
+
+func (r *postgresRepository) DeleteAllowlistEntry(id uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM allowlist_entries WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("allowlist entry %w", apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+// encodeAnalyzers encodes analyzer names for a JSONB column, writing an empty
+// array rather than null for none
+func encodeAnalyzers(analyzers []string) ([]byte, error) {
+	if analyzers == nil {
+		analyzers = []string{}
+	}
+	encoded, err := json.Marshal(analyzers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode allowlist analyzers: %w", err)
+	}
+	return encoded, nil
+}
+
+func (r *postgresRepository) scanAllowlistEntry(row rowScanner) (*models.AllowlistEntry, error) {
+	entry := &models.AllowlistEntry{}
+	var analyzers []byte
+
+	err := row.Scan(&entry.ID, &entry.Name, &entry.Description, &entry.MatchType, &entry.Pattern,
+		&analyzers, &entry.Factor, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("allowlist entry %w", apperrors.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(analyzers, &entry.Analyzers); err != nil {
+		return nil, fmt.Errorf("failed to decode allowlist analyzers: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ReviewItem methods implementation
+
+const reviewItemColumns = `id, anomaly_data_id, score, threshold, is_anomaly, status, label, note, labeled_by, labeled_at, created_at`
+
+func (r *postgresRepository) CreateReviewItem(item *models.ReviewItem) error {
+	query := `
+		INSERT INTO review_queue (anomaly_data_id, score, threshold, is_anomaly)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, status, created_at`
+
+	return r.db.QueryRow(query, item.AnomalyDataID, item.Score, item.Threshold, item.IsAnomaly).Scan(
+		&item.ID, &item.Status, &item.CreatedAt)
+}
+
+func (r *postgresRepository) GetReviewItem(id uuid.UUID) (*models.ReviewItem, error) {
+	query := `SELECT ` + reviewItemColumns + ` FROM review_queue WHERE id = $1`
+	return r.scanReviewItem(r.db.QueryRow(query, id))
+}
+
+// ListReviewItems returns a page of queued detections with the given status,
+// or any status when it is empty, oldest first, and the total count
+func (r *postgresRepository) ListReviewItems(status string, page, limit int) ([]*models.ReviewItem, int, error) {
+	offset := (page - 1) * limit
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM review_queue WHERE $1 = '' OR status = $1`
+	if err := r.db.QueryRow(countQuery, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + reviewItemColumns + ` FROM review_queue
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at
+		LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(query, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var items []*models.ReviewItem
+	for rows.Next() {
+		item, err := r.scanReviewItem(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+
+	return items, total, rows.Err()
+}
+
+// LabelReviewItem records the item's label, note and labeler, marking it
+// labeled; a labeled item may be labeled again
+func (r *postgresRepository) LabelReviewItem(item *models.ReviewItem) error {
+	query := `
+		UPDATE review_queue
+		SET status = $2, label = $3, note = $4, labeled_by = $5, labeled_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING ` + reviewItemColumns
+
+	labeled, err := r.scanReviewItem(r.db.QueryRow(query, item.ID, models.ReviewLabeled, item.Label,
+		item.Note, item.LabeledBy))
+	if err != nil {
+		return err
+	}
+	*item = *labeled
+	return nil
+}
+
+func (r *postgresRepository) scanReviewItem(row rowScanner) (*models.ReviewItem, error) {
+	item := &models.ReviewItem{}
+	var label sql.NullString
+
+	err := row.Scan(&item.ID, &item.AnomalyDataID, &item.Score, &item.Threshold, &item.IsAnomaly,
+		&item.Status, &label, &item.Note, &item.LabeledBy, &item.LabeledAt, &item.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("review item %w", apperrors.ErrNotFound)
+		}
+		return nil, err
+	}
+	item.Label = label.String
+
+	return item, nil
+}
+
+// Analysis record methods implementation
+
+// SaveAnalysisRecord stores a worker's analysis result. A record whose ID is
+// already stored is left as it is, so that saving is safe to repeat.
+func (r *postgresRepository) SaveAnalysisRecord(record *models.AnalysisRecord) error {
+	if record.Result == nil {
+		return fmt.Errorf("analysis record %s has no result", record.ID)
+	}
+	result, err := json.Marshal(record.Result)
+	if err != nil {
+		return fmt.Errorf("failed to encode analysis record %s: %w", record.ID, err)
+	}
+
+	query := `
+		INSERT INTO analysis_records (id, source, score, is_anomalous, result, analyzed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO NOTHING`
+
+	_, err = r.db.Exec(query, record.ID, record.Source, record.Result.Score, record.Result.IsAnomalous,
+		result, record.AnalyzedAt)
+	return err
+}
+
+// ListNormalAnomalyData returns up to limit stored results created since the
+// given time that are labeled normal in the review queue or, unlabeled, were
+// not judged anomalous, oldest first
+func (r *postgresRepository) ListNormalAnomalyData(since time.Time, limit int) ([]*models.AnomalyData, error) {
+	query := `
+		SELECT ad.id, ad.user_id, ad.data, ad.score, ad.is_anomaly, ad.threshold, ad.algorithm,
+			ad.processed_at, ad.created_at, ad.source
+		FROM anomaly_data ad
+		LEFT JOIN review_queue rq ON rq.anomaly_data_id = ad.id
+		WHERE COALESCE(rq.label = 'normal', ad.is_anomaly = false) AND ad.created_at >= $1
+		ORDER BY ad.created_at
+		LIMIT $2`
+
+	rows, err := r.db.Query(query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalyData []*models.AnomalyData
+	for rows.Next() {
+		data := &models.AnomalyData{}
+		var encoded []byte
+		err := rows.Scan(&data.ID, &data.UserID, &encoded, &data.Score,
+			&data.IsAnomaly, &data.Threshold, &data.Algorithm, &data.ProcessedAt, &data.CreatedAt, &data.Source)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(encoded, &data.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode anomaly data %s: %w", data.ID, err)
+		}
+		anomalyData = append(anomalyData, data)
+	}
+
+	return anomalyData, rows.Err()
+}
+
+// SearchAnomalyData returns the detections matching a web-search style
+// query ("quoted phrases", -excluded words, or) within the search's filters,
+// most relevant first
+func (r *postgresRepository) SearchAnomalyData(search models.AnomalySearch, page, limit int) ([]*models.AnomalySearchResult, int, error) {
+	where := `
+		FROM anomaly_data ad, websearch_to_tsquery('english', $1) q
+		WHERE ad.search_vector @@ q`
+	args := []interface{}{search.Query}
+
+	filter := func(condition string, value interface{}) {
+		args = append(args, value)
+		where += fmt.Sprintf(" AND %s $%d", condition, len(args))
+	}
+	if search.UserID != nil {
+		filter("ad.user_id =", *search.UserID)
+	}
+	if search.Source != "" {
+		filter("ad.source =", search.Source)
+	}
+	if search.MinScore != nil {
+		filter("ad.score >=", *search.MinScore)
+	}
+	if search.MaxScore != nil {
+		filter("ad.score <=", *search.MaxScore)
+	}
+	if !search.From.IsZero() {
+		filter("ad.created_at >=", search.From)
+	}
+	if !search.To.IsZero() {
+		filter("ad.created_at <", search.To)
+	}
+
+	// Get total count
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*)`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get data
+	args = append(args, limit, (page-1)*limit)
+	query := `
+		SELECT ad.id, ad.user_id, ad.data, ad.score, ad.is_anomaly, ad.threshold, ad.algorithm,
+			ad.processed_at, ad.created_at, ad.signature, ad.source, ts_rank(ad.search_vector, q) AS rank` + where + fmt.Sprintf(`
+		ORDER BY rank DESC, ad.created_at DESC
+		LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := make([]*models.AnomalySearchResult, 0)
+	for rows.Next() {
+		result := &models.AnomalySearchResult{}
+		var encoded, signature []byte
+		err := rows.Scan(&result.ID, &result.UserID, &encoded, &result.Score, &result.IsAnomaly,
+			&result.Threshold, &result.Algorithm, &result.ProcessedAt, &result.CreatedAt, &signature, &result.Source, &result.Rank)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(encoded, &result.Data); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode anomaly data %s: %w", result.ID, err)
+		}
+		if signature != nil {
+			result.Signature = &models.Signature{}
+			if err := json.Unmarshal(signature, result.Signature); err != nil {
+				return nil, 0, fmt.Errorf("failed to decode result signature: %w", err)
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, total, rows.Err()
+}
+
 // HealthCheck checks database connectivity
 func (r *postgresRepository) HealthCheck() error {
 	return r.db.Ping()
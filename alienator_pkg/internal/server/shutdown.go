@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/middleware"
+	"go.uber.org/zap"
+)
+
+// Drainer shuts the HTTP servers down gracefully. Readiness checks should
+// fail as soon as it starts draining, so that load balancers stop routing
+// traffic while requests already admitted finish.
+type Drainer struct {
+	draining atomic.Bool
+	limiters []*middleware.ConcurrencyLimiter
+	logger   *zap.Logger
+}
+
+// NewDrainer creates a drainer reporting the requests still in flight on the
+// given limiters when the shutdown deadline passes
+func NewDrainer(logger *zap.Logger, limiters ...*middleware.ConcurrencyLimiter) *Drainer {
+	return &Drainer{limiters: limiters, logger: logger}
+}
+
+// Draining reports whether shutdown has begun
+func (d *Drainer) Draining() bool {
+	return d.draining.Load()
+}
+
+// Shutdown marks the servers as draining, waits drainDelay for load balancers
+// to notice the failing readiness check, then stops accepting connections and
+// waits up to timeout for in-flight requests. Requests still running at the
+// deadline are logged and their connections closed.
+func (d *Drainer) Shutdown(drainDelay, timeout time.Duration, servers ...*http.Server) error {
+	d.draining.Store(true)
+	if drainDelay > 0 {
+		d.logger.Info("Draining traffic before shutdown", zap.Duration("drain_delay", drainDelay))
+		time.Sleep(drainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if ctx.Err() == nil {
+		return errors.Join(errs...)
+	}
+
+	fields := []zap.Field{zap.Duration("timeout", timeout)}
+	for _, limiter := range d.limiters {
+		fields = append(fields, zap.Int(limiter.Name()+"_in_flight", limiter.InFlight()))
+	}
+	d.logger.Warn("Shutdown timed out, closing connections of in-flight requests", fields...)
+
+	for _, srv := range servers {
+		srv.Close()
+	}
+	return errors.Join(errs...)
+}
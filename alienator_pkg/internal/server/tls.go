@@ -0,0 +1,198 @@
+// Package server provides the transport security shared by the HTTP servers
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"go.uber.org/zap"
+)
+
+// certCheckInterval is how often handshakes look for rotated certificate files
+const certCheckInterval = 10 * time.Second
+
+// CertReloader serves the certificate and client CAs from the configured
+// files, picking up rotated files without a restart. Handshakes check the
+// files' modification times at most every certCheckInterval; Reload forces a
+// check, e.g. on SIGHUP. A rotation that fails to load keeps the previous
+// certificate in service.
+type CertReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	logger       *zap.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+// NewCertReloader loads the certificate, key and optional client CA bundle
+func NewCertReloader(cfg config.TLSConfig, logger *zap.Logger) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile:     cfg.CertFile,
+		keyFile:      cfg.KeyFile,
+		clientCAFile: cfg.ClientCAFile,
+		logger:       logger,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate files again
+func (r *CertReloader) Reload() error {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA file %s contains no certificates", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.lastCheck = time.Now()
+	return nil
+}
+
+// Certificate returns the certificate currently served
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// TLSConfig returns a server TLS configuration serving the reloaded
+// certificate. With a client CA bundle, client certificates signed by it are
+// verified when presented; RequireClientCert decides which routes need one.
+func (r *CertReloader) TLSConfig(minVersion string) (*tls.Config, error) {
+	version, err := parseTLSVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion: version,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.checkRotation()
+			return r.Certificate(), nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.checkRotation()
+
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			cfg := &tls.Config{
+				MinVersion:   version,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if r.clientCAs != nil {
+				cfg.ClientCAs = r.clientCAs
+				cfg.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+			return cfg, nil
+		},
+	}, nil
+}
+
+// checkRotation reloads the files if they changed since the last load
+func (r *CertReloader) checkRotation() {
+	r.mu.Lock()
+	if time.Since(r.lastCheck) < certCheckInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastCheck = time.Now()
+	previous := r.modTimes
+	r.mu.Unlock()
+
+	current, err := r.statFiles()
+	if err != nil {
+		r.logger.Warn("Failed to check TLS certificate files", zap.Error(err))
+		return
+	}
+	for path, modTime := range current {
+		if !modTime.Equal(previous[path]) {
+			if err := r.Reload(); err != nil {
+				r.logger.Error("Failed to reload rotated TLS certificate, keeping the previous one", zap.Error(err))
+				return
+			}
+			r.logger.Info("Reloaded rotated TLS certificate", zap.String("cert_file", r.certFile))
+			return
+		}
+	}
+}
+
+// statFiles returns the modification time of each certificate file
+func (r *CertReloader) statFiles() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time, 3)
+	for _, path := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat TLS file: %w", err)
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version: %s (expected 1.2 or 1.3)", version)
+	}
+}
+
+// RedirectHandler redirects plain HTTP requests to the same URL over HTTPS on
+// httpsPort, keeping the method and body with a permanent redirect
+func RedirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+
+		target := "https://" + host + req.URL.RequestURI()
+		http.Redirect(w, req, target, http.StatusPermanentRedirect)
+	})
+}
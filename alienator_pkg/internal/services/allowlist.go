@@ -0,0 +1,324 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/core"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/signing"
+	"go.uber.org/zap"
+)
+
+// allowlistMatch is the combined effect of the allowlist entries matching
+// one detection request
+type allowlistMatch struct {
+	suppressions []models.Suppression
+	scales       map[string]float64 // analyzer weight factors
+	scoreFactor  float64            // factor for the final score
+}
+
+// allowlistRefresh is how long a loaded allowlist is used before it is read
+// again, so that entries changed through another instance apply
+const allowlistRefresh = 30 * time.Second
+
+// allowlistCache holds the allowlist entries with their compiled patterns.
+// It is loaded when first needed, and again once allowlistRefresh has passed
+// or after this service changed an entry.
+type allowlistCache struct {
+	mu       sync.RWMutex
+	entries  []*models.AllowlistEntry
+	patterns map[string]*regexp.Regexp // by match type and pattern
+	loaded   time.Time
+}
+
+// allowlistKey identifies a compiled pattern
+func allowlistKey(matchType, pattern string) string {
+	return matchType + "\x00" + pattern
+}
+
+// loadAllowlist returns the allowlist entries and their compiled patterns,
+// reading them from the repository if the cache is empty or stale. Patterns
+// of entries no longer listed are dropped; invalid ones are left out, to be
+// reported when matched.
+func (s *AnomalyService) loadAllowlist() ([]*models.AllowlistEntry, map[string]*regexp.Regexp, error) {
+	cache := &s.allowlist
+	cache.mu.RLock()
+	if !cache.loaded.IsZero() && time.Since(cache.loaded) < allowlistRefresh {
+		defer cache.mu.RUnlock()
+		return cache.entries, cache.patterns, nil
+	}
+	cache.mu.RUnlock()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if !cache.loaded.IsZero() && time.Since(cache.loaded) < allowlistRefresh {
+		return cache.entries, cache.patterns, nil
+	}
+	entries, err := s.repo.ListAllowlistEntries()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patterns := make(map[string]*regexp.Regexp)
+	for _, entry := range entries {
+		if entry.MatchType == models.AllowlistInputHash {
+			continue
+		}
+		key := allowlistKey(entry.MatchType, entry.Pattern)
+		if compiled, ok := cache.patterns[key]; ok {
+			patterns[key] = compiled
+		} else if compiled, err := compileAllowlistPattern(entry.MatchType, entry.Pattern); err == nil {
+			patterns[key] = compiled
+		}
+	}
+	cache.entries, cache.patterns, cache.loaded = entries, patterns, time.Now()
+	return entries, patterns, nil
+}
+
+// invalidateAllowlist makes the next detection read the allowlist again
+func (s *AnomalyService) invalidateAllowlist() {
+	s.allowlist.mu.Lock()
+	s.allowlist.loaded = time.Time{}
+	s.allowlist.mu.Unlock()
+}
+
+// matchAllowlist checks the request against every allowlist entry. Entries
+// naming analyzers only apply when text is analyzed by a detector running
+// them. Lookup failures are logged rather than failing the detection.
+func (s *AnomalyService) matchAllowlist(text string, hasText bool, data map[string]interface{}) allowlistMatch {
+	match := allowlistMatch{scoreFactor: 1.0}
+
+	entries, patterns, err := s.loadAllowlist()
+	if err != nil {
+		s.logger.Warn("Failed to load allowlist, detecting without it", zap.Error(err))
+		return match
+	}
+	if len(entries) == 0 {
+		return match
+	}
+
+	registered := make(map[string]bool)
+	if hasText && s.detector != nil {
+		for _, name := range s.detector.AnalyzerNames() {
+			registered[name] = true
+		}
+	}
+
+	var inputHash string
+	for _, entry := range entries {
+		matched, err := allowlistEntryMatches(entry, patterns, text, hasText, data, &inputHash)
+		if err != nil {
+			s.logger.Warn("Skipping invalid allowlist entry", zap.String("entry_id", entry.ID.String()), zap.Error(err))
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		var analyzers []string
+		for _, name := range entry.Analyzers {
+			if registered[name] {
+				analyzers = append(analyzers, name)
+			}
+		}
+		if len(entry.Analyzers) > 0 && len(analyzers) == 0 {
+			continue
+		}
+
+		if len(analyzers) == 0 {
+			match.scoreFactor *= entry.Factor
+		} else {
+			if match.scales == nil {
+				match.scales = make(map[string]float64)
+			}
+			for _, name := range analyzers {
+				if scale, ok := match.scales[name]; ok {
+					match.scales[name] = scale * entry.Factor
+				} else {
+					match.scales[name] = entry.Factor
+				}
+			}
+		}
+		match.suppressions = append(match.suppressions, models.Suppression{
+			EntryID:   entry.ID,
+			Name:      entry.Name,
+			MatchType: entry.MatchType,
+			Analyzers: analyzers,
+			Factor:    entry.Factor,
+		})
+	}
+
+	return match
+}
+
+// allowlistEntryMatches reports whether entry matches the request. The input
+// hash is computed on first use and kept in inputHash.
+func allowlistEntryMatches(entry *models.AllowlistEntry, patterns map[string]*regexp.Regexp, text string, hasText bool, data map[string]interface{}, inputHash *string) (bool, error) {
+	if entry.MatchType == models.AllowlistInputHash {
+		if *inputHash == "" {
+			hash, err := signing.HashInput(data)
+			if err != nil {
+				return false, err
+			}
+			*inputHash = hash
+		}
+		return entry.Pattern == *inputHash, nil
+	}
+
+	if !hasText {
+		return false, nil
+	}
+	pattern, ok := patterns[allowlistKey(entry.MatchType, entry.Pattern)]
+	if !ok {
+		// Not compiled when loaded; compiling again gives the reason
+		_, err := compileAllowlistPattern(entry.MatchType, entry.Pattern)
+		return false, err
+	}
+	return pattern.MatchString(text), nil
+}
+
+// compileAllowlistPattern compiles a phrase or regex entry
+func compileAllowlistPattern(matchType, pattern string) (*regexp.Regexp, error) {
+	var expr string
+	switch matchType {
+	case models.AllowlistPhrase:
+		// Phrases ignore case and the amount of whitespace between words
+		words := strings.Fields(pattern)
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		expr = `(?i)` + strings.Join(words, `\s+`)
+	case models.AllowlistRegex:
+		expr = pattern
+	default:
+		return nil, fmt.Errorf("unsupported allowlist match type %q", matchType)
+	}
+
+	return regexp.Compile(expr)
+}
+
+// newAllowlistEntry validates a request and builds the entry it describes
+func (s *AnomalyService) newAllowlistEntry(req *models.AllowlistEntryRequest) (*models.AllowlistEntry, error) {
+	pattern := req.Pattern
+	switch req.MatchType {
+	case models.AllowlistPhrase:
+		pattern = strings.Join(strings.Fields(pattern), " ")
+		if pattern == "" {
+			return nil, &InputError{Reason: "phrase must contain a word"}
+		}
+	case models.AllowlistRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, &InputError{Reason: fmt.Sprintf("invalid regex: %v", err)}
+		}
+	case models.AllowlistInputHash:
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if decoded, err := hex.DecodeString(pattern); err != nil || len(decoded) != 32 {
+			return nil, &InputError{Reason: "input_hash must be a hex SHA-256 digest"}
+		}
+	default:
+		return nil, &InputError{Reason: fmt.Sprintf("unsupported match type %q", req.MatchType)}
+	}
+
+	var analyzers []string
+	seen := make(map[string]bool)
+	for _, name := range req.Analyzers {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		analyzers = append(analyzers, name)
+	}
+	if s.detector != nil && len(analyzers) > 0 {
+		if err := s.detector.ValidateSelection(core.Selection{Analyzers: analyzers}); err != nil {
+			return nil, &InputError{Reason: err.Error()}
+		}
+	}
+
+	return &models.AllowlistEntry{
+		Name:        req.Name,
+		Description: req.Description,
+		MatchType:   req.MatchType,
+		Pattern:     pattern,
+		Analyzers:   analyzers,
+		Factor:      req.Factor,
+	}, nil
+}
+
+// CreateAllowlistEntry stores a new allowlist entry
+func (s *AnomalyService) CreateAllowlistEntry(req *models.AllowlistEntryRequest) (*models.AllowlistEntry, error) {
+	entry, err := s.newAllowlistEntry(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateAllowlistEntry(entry); err != nil {
+		return nil, fmt.Errorf("failed to create allowlist entry: %w", err)
+	}
+	s.invalidateAllowlist()
+
+	s.logger.Info("Allowlist entry created",
+		zap.String("entry_id", entry.ID.String()),
+		zap.String("match_type", entry.MatchType),
+	)
+	return entry, nil
+}
+
+// GetAllowlistEntry retrieves an allowlist entry by ID
+func (s *AnomalyService) GetAllowlistEntry(id uuid.UUID) (*models.AllowlistEntry, error) {
+	entry, err := s.repo.GetAllowlistEntry(id)
+	if err != nil {
+		return nil, allowlistLookupError(err)
+	}
+	return entry, nil
+}
+
+// ListAllowlistEntries retrieves all allowlist entries
+func (s *AnomalyService) ListAllowlistEntries() ([]*models.AllowlistEntry, error) {
+	entries, err := s.repo.ListAllowlistEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list allowlist entries: %w", err)
+	}
+	return entries, nil
+}
+
+// UpdateAllowlistEntry replaces an existing allowlist entry
+func (s *AnomalyService) UpdateAllowlistEntry(id uuid.UUID, req *models.AllowlistEntryRequest) (*models.AllowlistEntry, error) {
+	entry, err := s.newAllowlistEntry(req)
+	if err != nil {
+		return nil, err
+	}
+	entry.ID = id
+	if err := s.repo.UpdateAllowlistEntry(entry); err != nil {
+		return nil, allowlistLookupError(err)
+	}
+	s.invalidateAllowlist()
+
+	s.logger.Info("Allowlist entry updated", zap.String("entry_id", id.String()))
+	return entry, nil
+}
+
+// DeleteAllowlistEntry removes an allowlist entry
+func (s *AnomalyService) DeleteAllowlistEntry(id uuid.UUID) error {
+	if err := s.repo.DeleteAllowlistEntry(id); err != nil {
+		return allowlistLookupError(err)
+	}
+	s.invalidateAllowlist()
+
+	s.logger.Info("Allowlist entry deleted", zap.String("entry_id", id.String()))
+	return nil
+}
+
+// allowlistLookupError translates repository not-found errors into ErrAllowlistNotFound
+func allowlistLookupError(err error) error {
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return ErrAllowlistNotFound
+	}
+	return fmt.Errorf("failed to access allowlist entry: %w", err)
+}
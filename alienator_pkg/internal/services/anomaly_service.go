@@ -2,56 +2,196 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
 	"github.com/ruvnet/alienator/internal/models"
 	"github.com/ruvnet/alienator/internal/repository"
+	"github.com/ruvnet/alienator/internal/signing"
 	"go.uber.org/zap"
 )
 
 // AnomalyService handles anomaly detection business logic
 type AnomalyService struct {
-	repo   repository.Repository
-	logger *zap.Logger
+	repo        repository.Repository
+	detector    *core.AnomalyDetector
+	presets     map[string]*core.AnomalyDetector // detectors by preset name, see SetPresets
+	signer      *signing.Signer
+	precision   int
+	reviewBand  float64
+	explainOnly config.ExplainOnlyConfig
+	provenance  config.ProvenanceConfig
+	allowlist   allowlistCache
+	logger      *zap.Logger
 }
 
 // NewAnomalyService creates a new anomaly service
 func NewAnomalyService(repo repository.Repository, logger *zap.Logger) *AnomalyService {
 	return &AnomalyService{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		precision: models.DefaultPrecision,
+		logger:    logger,
 	}
 }
 
-// ProcessDetection processes anomaly detection request
-func (s *AnomalyService) ProcessDetection(userID uuid.UUID, req *models.DetectionRequest) (*models.DetectionResult, error) {
+// SetDetector runs a "text" field in detection requests through the
+// detector's text analyzers, and enables per-request analyzer selection
+func (s *AnomalyService) SetDetector(detector *core.AnomalyDetector) {
+	s.detector = detector
+}
+
+// SetSigner signs every detection result, making stored and returned
+// verdicts tamper-evident
+func (s *AnomalyService) SetSigner(signer *signing.Signer) {
+	s.signer = signer
+}
+
+// SetProvenance sets the sources detections are tagged with: the default for
+// requests naming none, and the only ones accepted if listed. Unless set,
+// requests may name any source and are untagged otherwise.
+func (s *AnomalyService) SetProvenance(cfg config.ProvenanceConfig) {
+	s.provenance = cfg
+}
+
+// SetPrecision sets the number of decimals scores and confidences are
+// rounded to in stored and returned results, models.DefaultPrecision unless
+// set. The verdict is decided on the unrounded score.
+func (s *AnomalyService) SetPrecision(precision int) {
+	s.precision = precision
+}
+
+// ProcessDetection processes anomaly detection request. The detection profile
+// bound to apiKey supplies the algorithm, threshold and feature weights unless
+// the request overrides them; callers without a profile get the default one.
+// With a detector set, a "text" field is scored by the text analyzers the
+// request selects, weighted as it asks and within its time budget, by the
+// detector of the preset it names; unknown analyzers and presets are an
+// InputError.
+// Matching allowlist entries then down-weight analyzers or the score, and are
+// listed in the result metadata. The result is stored tagged with the
+// request's source (see SetProvenance); one within the review band of its
+// threshold is queued for a human label. The text analyzers run in ctx, and
+// the detection fails with its error once it is done.
+func (s *AnomalyService) ProcessDetection(ctx context.Context, userID uuid.UUID, apiKey string, req *models.DetectionRequest) (*models.DetectionResult, error) {
+	return s.detect(ctx, userID, apiKey, req, false)
+}
+
+// detect runs a detection for ProcessDetection, or for ExplainDetection when
+// explainOnly is set
+func (s *AnomalyService) detect(ctx context.Context, userID uuid.UUID, apiKey string, req *models.DetectionRequest, explainOnly bool) (*models.DetectionResult, error) {
 	startTime := time.Now()
 
+	text, hasText := req.Data["text"].(string)
+	detector, err := s.textDetector(req.Preset)
+	if err != nil {
+		return nil, err
+	}
+	if req.Preset != "" && !hasText {
+		return nil, &InputError{Reason: `presets require a "text" string in data`}
+	}
+	source, err := s.provenance.Resolve(req.Source)
+	if err != nil {
+		return nil, &InputError{Reason: err.Error()}
+	}
+	selection := core.Selection{
+		Analyzers: req.Analyzers,
+		Weights:   req.Weights,
+		Budget:    time.Duration(req.BudgetMS) * time.Millisecond,
+	}
+	if !selection.IsZero() {
+		if detector == nil {
+			return nil, &InputError{Reason: "analyzer selection and budgets are not available on this server"}
+		}
+		if !hasText {
+			return nil, &InputError{Reason: `analyzer selection and budgets require a "text" string in data`}
+		}
+		if err := detector.ValidateSelection(selection); err != nil {
+			return nil, &InputError{Reason: err.Error()}
+		}
+	}
+
+	profile := s.resolveProfile(apiKey)
+	allowlist := s.matchAllowlist(text, hasText, req.Data)
+	selection.Scales = allowlist.scales
+	if explainOnly {
+		selection.Exclude = s.explainOnly.Exclude
+	}
+
 	// Set default algorithm if not provided
 	algorithm := req.Algorithm
+	if algorithm == "" {
+		algorithm = profile.Algorithm
+	}
 	if algorithm == "" {
 		algorithm = "isolation_forest"
 	}
 
 	// Set default threshold if not provided
 	threshold := req.Threshold
+	if threshold == 0 {
+		threshold = profile.Threshold
+	}
 	if threshold == 0 {
 		threshold = 0.5
 	}
 
 	// Simulate anomaly detection processing
 	// In a real implementation, this would call your actual anomaly detection algorithms
-	score := s.calculateAnomalyScore(req.Data, algorithm)
+	score := s.calculateAnomalyScore(req.Data, algorithm, profile.Weights)
+
+	// Text is scored by the text analyzers instead
+	var analyzerScores map[string]float64
+	var skipped []string
+	var language string
+	var languageConfidence float64
+	var explanation string
+	if hasText && detector != nil {
+		textResult, err := detector.AnalyzeTextWith(ctx, text, core.ContentTypeAuto, selection)
+		if err != nil {
+			return nil, fmt.Errorf("text analysis failed: %w", err)
+		}
+		score = textResult.Score
+		if presetThreshold, ok := textResult.Metadata["threshold"].(float64); ok && req.Preset != "" && req.Threshold == 0 {
+			threshold = presetThreshold
+		}
+		analyzerScores = make(map[string]float64, len(textResult.Details))
+		for name, detail := range textResult.Details {
+			analyzerScores[name] = detail.Score
+		}
+		skipped, _ = textResult.Metadata["skipped_analyzers"].([]string)
+		language, languageConfidence = textResult.Language, textResult.LanguageConfidence
+		if explainOnly {
+			explanation = detector.ExplainText(textResult)
+		}
+	}
+	score *= allowlist.scoreFactor
+
 	isAnomaly := score > threshold
 	confidence := s.calculateConfidence(score, threshold)
+	score = models.Round(score, s.precision)
+	confidence = models.Round(confidence, s.precision)
+	for name, analyzerScore := range analyzerScores {
+		analyzerScores[name] = models.Round(analyzerScore, s.precision)
+	}
 	
 	// Generate metadata
 	metadata := models.Metadata{
 		Features:     s.extractFeatures(req.Data),
 		Explanations: s.generateExplanations(req.Data, score, isAnomaly),
 		Suggestions:  s.generateSuggestions(isAnomaly, score),
+		Analyzers:        analyzerScores,
+		SkippedAnalyzers: skipped,
+		Suppressions:     allowlist.suppressions,
+	}
+	if explanation != "" {
+		metadata.Explanations = []string{explanation}
 	}
 
 	// Create anomaly data record
@@ -63,11 +203,24 @@ func (s *AnomalyService) ProcessDetection(userID uuid.UUID, req *models.Detectio
 		Threshold:   threshold,
 		Algorithm:   algorithm,
 		ProcessedAt: time.Now(),
+		Source:      source,
+	}
+	if s.signer != nil && !explainOnly {
+		signature, err := s.signer.Sign(signing.StoredVerdictOf(anomalyData), req.Data, anomalyData.ProcessedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign detection result: %w", err)
+		}
+		anomalyData.Signature = signature
 	}
 
-	if err := s.repo.CreateAnomalyData(anomalyData); err != nil {
-		s.logger.Error("Failed to save anomaly data", zap.Error(err), zap.String("user_id", userID.String()))
-		// Continue with response even if saving fails
+	// Explain-only results leave no trace; the full detection is authoritative
+	if !explainOnly {
+		if err := s.repo.CreateAnomalyData(anomalyData); err != nil {
+			s.logger.Error("Failed to save anomaly data", zap.Error(err), zap.String("user_id", userID.String()))
+			// Continue with response even if saving fails
+		} else {
+			metadata.Review = s.enqueueReview(anomalyData)
+		}
 	}
 
 	processingTime := time.Since(startTime).Milliseconds()
@@ -79,13 +232,24 @@ func (s *AnomalyService) ProcessDetection(userID uuid.UUID, req *models.Detectio
 		Confidence:     confidence,
 		Threshold:      threshold,
 		Algorithm:      algorithm,
+		Profile:        profile.Name,
+		Preset:         req.Preset,
 		ProcessingTime: processingTime,
 		Metadata:       metadata,
+		Signature:      anomalyData.Signature,
+		ExplainOnly:    explainOnly,
+		Source:         source,
+
+		Language:           language,
+		LanguageConfidence: models.Round(languageConfidence, s.precision),
 	}
 
 	s.logger.Info("Anomaly detection completed",
 		zap.String("user_id", userID.String()),
+		zap.Bool("explain_only", explainOnly),
 		zap.String("algorithm", algorithm),
+		zap.String("profile", profile.Name),
+		zap.String("source", source),
 		zap.Float64("score", score),
 		zap.Bool("is_anomaly", isAnomaly),
 		zap.Int64("processing_time_ms", processingTime),
@@ -94,18 +258,61 @@ func (s *AnomalyService) ProcessDetection(userID uuid.UUID, req *models.Detectio
 	return result, nil
 }
 
+// VerifyDetection checks the signature of a detection result as returned by
+// ProcessDetection and, if data is given, that data is the signed input. A
+// failed check is reported in the result rather than as an error.
+func (s *AnomalyService) VerifyDetection(result *models.DetectionResult, data map[string]interface{}) (*models.VerificationResult, error) {
+	return s.verify(signing.VerdictOf(result), result.Signature, data)
+}
+
+// VerifyStoredDetection checks the signature of a stored detection, including
+// that its stored data is still the signed input
+func (s *AnomalyService) VerifyStoredDetection(stored *models.AnomalyData) (*models.VerificationResult, error) {
+	return s.verify(signing.StoredVerdictOf(stored), stored.Signature, stored.Data)
+}
+
+func (s *AnomalyService) verify(verdict signing.Verdict, signature *models.Signature, data map[string]interface{}) (*models.VerificationResult, error) {
+	if s.signer == nil {
+		return nil, &InputError{Reason: "result signing is not enabled on this server"}
+	}
+	if signature == nil {
+		return &models.VerificationResult{Valid: false, Reason: "result is not signed"}, nil
+	}
+
+	verification := &models.VerificationResult{
+		Valid:     true,
+		KeyID:     signature.KeyID,
+		SignedAt:  &signature.SignedAt,
+		InputHash: signature.InputHash,
+	}
+	if err := s.signer.Verify(verdict, signature); err != nil {
+		verification.Valid = false
+		verification.Reason = err.Error()
+	} else if data != nil {
+		if err := signing.VerifyInput(data, signature); err != nil {
+			verification.Valid = false
+			verification.Reason = err.Error()
+		}
+	}
+	return verification, nil
+}
+
 // GetAnomalyData retrieves anomaly data by ID
 func (s *AnomalyService) GetAnomalyData(id uuid.UUID) (*models.AnomalyData, error) {
 	data, err := s.repo.GetAnomalyDataByID(id)
 	if err != nil {
-		return nil, fmt.Errorf("anomaly data not found")
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, ErrAnomalyNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve anomaly data: %w", err)
 	}
 
 	return data, nil
 }
 
-// GetUserAnomalyData retrieves anomaly data for a specific user
-func (s *AnomalyService) GetUserAnomalyData(userID uuid.UUID, page, limit int) ([]*models.AnomalyData, *models.Meta, error) {
+// GetUserAnomalyData retrieves anomaly data for a specific user, from source
+// only unless it is empty
+func (s *AnomalyService) GetUserAnomalyData(userID uuid.UUID, source string, page, limit int) ([]*models.AnomalyData, *models.Meta, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -113,10 +320,10 @@ func (s *AnomalyService) GetUserAnomalyData(userID uuid.UUID, page, limit int) (
 		limit = 20
 	}
 
-	data, total, err := s.repo.GetAnomalyDataByUserID(userID, page, limit)
+	data, total, err := s.repo.GetAnomalyDataByUserID(userID, source, page, limit)
 	if err != nil {
 		s.logger.Error("Failed to get user anomaly data", zap.Error(err), zap.String("user_id", userID.String()))
-		return nil, nil, fmt.Errorf("failed to retrieve anomaly data: %v", err)
+		return nil, nil, fmt.Errorf("failed to retrieve anomaly data: %w", err)
 	}
 
 	totalPages := (total + limit - 1) / limit
@@ -130,8 +337,9 @@ func (s *AnomalyService) GetUserAnomalyData(userID uuid.UUID, page, limit int) (
 	return data, meta, nil
 }
 
-// ListAnomalyData retrieves paginated anomaly data
-func (s *AnomalyService) ListAnomalyData(page, limit int) ([]*models.AnomalyData, *models.Meta, error) {
+// ListAnomalyData retrieves paginated anomaly data, from source only unless
+// it is empty
+func (s *AnomalyService) ListAnomalyData(source string, page, limit int) ([]*models.AnomalyData, *models.Meta, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -139,10 +347,10 @@ func (s *AnomalyService) ListAnomalyData(page, limit int) ([]*models.AnomalyData
 		limit = 20
 	}
 
-	data, total, err := s.repo.ListAnomalyData(page, limit)
+	data, total, err := s.repo.ListAnomalyData(source, page, limit)
 	if err != nil {
 		s.logger.Error("Failed to list anomaly data", zap.Error(err))
-		return nil, nil, fmt.Errorf("failed to retrieve anomaly data: %v", err)
+		return nil, nil, fmt.Errorf("failed to retrieve anomaly data: %w", err)
 	}
 
 	totalPages := (total + limit - 1) / limit
@@ -156,11 +364,50 @@ func (s *AnomalyService) ListAnomalyData(page, limit int) ([]*models.AnomalyData
 	return data, meta, nil
 }
 
+// maxSearchQueryLength bounds the text of a detection search
+const maxSearchQueryLength = 256
+
+// SearchAnomalyData searches stored detections by content, most relevant
+// first, within the search's user, score and date filters
+func (s *AnomalyService) SearchAnomalyData(search models.AnomalySearch, page, limit int) ([]*models.AnomalySearchResult, *models.Meta, error) {
+	search.Query = strings.TrimSpace(search.Query)
+	switch {
+	case search.Query == "":
+		return nil, nil, &InputError{Reason: "a search query is required"}
+	case len(search.Query) > maxSearchQueryLength:
+		return nil, nil, &InputError{Reason: fmt.Sprintf("search query is longer than %d characters", maxSearchQueryLength)}
+	case search.MinScore != nil && search.MaxScore != nil && *search.MinScore > *search.MaxScore:
+		return nil, nil, &InputError{Reason: "min_score must not exceed max_score"}
+	case !search.From.IsZero() && !search.To.IsZero() && !search.From.Before(search.To):
+		return nil, nil, &InputError{Reason: "from must be before to"}
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	results, total, err := s.repo.SearchAnomalyData(search, page, limit)
+	if err != nil {
+		s.logger.Error("Failed to search anomaly data", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to search anomaly data: %w", err)
+	}
+
+	meta := &models.Meta{
+		Page:       page,
+		PerPage:    limit,
+		Total:      total,
+		TotalPages: (total + limit - 1) / limit,
+	}
+	return results, meta, nil
+}
+
 // DeleteAnomalyData deletes anomaly data by ID
 func (s *AnomalyService) DeleteAnomalyData(id uuid.UUID) error {
 	if err := s.repo.DeleteAnomalyData(id); err != nil {
 		s.logger.Error("Failed to delete anomaly data", zap.Error(err), zap.String("id", id.String()))
-		return fmt.Errorf("failed to delete anomaly data: %v", err)
+		return fmt.Errorf("failed to delete anomaly data: %w", err)
 	}
 
 	s.logger.Info("Anomaly data deleted", zap.String("id", id.String()))
@@ -177,7 +424,7 @@ func (s *AnomalyService) GetAnomalyStats(userID *uuid.UUID) (map[string]interfac
 	
 	if userID != nil {
 		// Get stats for specific user
-		data, totalCount, err := s.repo.GetAnomalyDataByUserID(*userID, 1, 1000)
+		data, totalCount, err := s.repo.GetAnomalyDataByUserID(*userID, "", 1, 1000)
 		if err != nil {
 			s.logger.Warn("Failed to get user anomaly stats", zap.Error(err))
 			return nil, err
@@ -190,7 +437,7 @@ func (s *AnomalyService) GetAnomalyStats(userID *uuid.UUID) (map[string]interfac
 		}
 	} else {
 		// Get global stats
-		data, totalCount, err := s.repo.ListAnomalyData(1, 1000)
+		data, totalCount, err := s.repo.ListAnomalyData("", 1, 1000)
 		if err != nil {
 			s.logger.Warn("Failed to get global anomaly stats", zap.Error(err))
 			return nil, err
@@ -223,33 +470,114 @@ func (s *AnomalyService) GetAnomalyStats(userID *uuid.UUID) (map[string]interfac
 	return stats, nil
 }
 
+// statsIntervals maps accepted interval names to date_trunc units and their
+// approximate length, used to bound the number of buckets per request
+var statsIntervals = map[string]struct {
+	unit   string
+	length time.Duration
+}{
+	"1m":  {"minute", time.Minute},
+	"1h":  {"hour", time.Hour},
+	"1d":  {"day", 24 * time.Hour},
+	"1w":  {"week", 7 * 24 * time.Hour},
+	"1mo": {"month", 30 * 24 * time.Hour},
+}
+
+// maxStatsBuckets bounds the size of a single time-series response
+const maxStatsBuckets = 1000
+
+// statsGroupings are the accepted group_by values of a stats time series
+var statsGroupings = map[string]bool{"": true, "source": true}
+
+// GetAnomalyStatsTimeSeries returns detection counts and mean scores bucketed
+// by interval between from and to. Zero times default to the last 24 hours.
+// A nil userID aggregates across all users and an empty source across all
+// sources. Grouped by "source", each source has buckets of its own.
+func (s *AnomalyService) GetAnomalyStatsTimeSeries(userID *uuid.UUID, source, interval, groupBy string, from, to time.Time) (*models.AnomalyStatsTimeSeries, error) {
+	if interval == "" {
+		interval = "1h"
+	}
+	bucketing, ok := statsIntervals[interval]
+	if !ok {
+		return nil, &InputError{Reason: fmt.Sprintf("unsupported interval %q (expected 1m, 1h, 1d, 1w or 1mo)", interval)}
+	}
+
+	if !statsGroupings[groupBy] {
+		return nil, &InputError{Reason: fmt.Sprintf("unsupported group_by %q (expected source)", groupBy)}
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-24 * time.Hour)
+	}
+	if !from.Before(to) {
+		return nil, &InputError{Reason: "from must be before to"}
+	}
+	if to.Sub(from)/bucketing.length > maxStatsBuckets {
+		return nil, &InputError{Reason: fmt.Sprintf("time range spans more than %d %s buckets", maxStatsBuckets, bucketing.unit)}
+	}
+
+	buckets, err := s.repo.GetAnomalyStatsTimeSeries(models.StatsQuery{
+		UserID:   userID,
+		Source:   source,
+		Unit:     bucketing.unit,
+		From:     from,
+		To:       to,
+		BySource: groupBy == "source",
+	})
+	if err != nil {
+		s.logger.Error("Failed to get anomaly stats time series", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve anomaly stats: %w", err)
+	}
+
+	return &models.AnomalyStatsTimeSeries{
+		Interval: interval,
+		From:     from,
+		To:       to,
+		Source:   source,
+		GroupBy:  groupBy,
+		Buckets:  buckets,
+	}, nil
+}
+
 // Helper methods for anomaly detection simulation
 
-func (s *AnomalyService) calculateAnomalyScore(data map[string]interface{}, algorithm string) float64 {
+func (s *AnomalyService) calculateAnomalyScore(data map[string]interface{}, algorithm string, weights map[string]float64) float64 {
 	// Simplified anomaly score calculation
 	// In a real implementation, this would use actual ML algorithms
 	
 	score := 0.0
-	count := 0
+	totalWeight := 0.0
 	
-	for _, value := range data {
+	for key, value := range data {
 		if v, ok := value.(float64); ok {
+			// Features without a profile weight count once; a weight of 0 ignores them
+			weight := 1.0
+			if w, ok := weights[key]; ok {
+				weight = w
+			}
+			if weight <= 0 {
+				continue
+			}
+
 			// Simple statistical approach - values far from mean get higher scores
 			if v > 100 || v < -100 {
-				score += 0.8
+				score += 0.8 * weight
 			} else if v > 50 || v < -50 {
-				score += 0.6
+				score += 0.6 * weight
 			} else if v > 25 || v < -25 {
-				score += 0.4
+				score += 0.4 * weight
 			} else {
-				score += 0.2
+				score += 0.2 * weight
 			}
-			count++
+			totalWeight += weight
 		}
 	}
 	
-	if count > 0 {
-		score = score / float64(count)
+	if totalWeight > 0 {
+		score = score / totalWeight
 	}
 	
 	// Add some randomness to simulate real anomaly detection
@@ -2,9 +2,12 @@
 package services
 
 import (
-	"errors"
+	"bufio"
 	"fmt"
+	"os"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
@@ -17,23 +20,67 @@ import (
 
 // AuthService handles authentication and authorization
 type AuthService struct {
-	config *config.Config
-	logger *zap.Logger
+	config          *config.Config
+	logger          *zap.Logger
+	commonPasswords map[string]struct{}
 }
 
 // NewAuthService creates a new authentication service
 func NewAuthService(config *config.Config, logger *zap.Logger) *AuthService {
-	return &AuthService{
+	s := &AuthService{
 		config: config,
 		logger: logger,
 	}
+
+	if path := config.Auth.PasswordPolicy.CommonPasswordsFile; path != "" {
+		passwords, err := loadCommonPasswords(path)
+		if err != nil {
+			logger.Warn("Failed to load common passwords list, breach check disabled",
+				zap.String("path", path),
+				zap.Error(err),
+			)
+		} else {
+			s.commonPasswords = passwords
+			logger.Info("Loaded common passwords list",
+				zap.String("path", path),
+				zap.Int("count", len(passwords)),
+			)
+		}
+	}
+
+	return s
+}
+
+// loadCommonPasswords reads a newline-separated password list, skipping blank
+// lines and # comments. Entries are lowercased so the check is case-insensitive.
+func loadCommonPasswords(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	passwords := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return passwords, nil
 }
 
 // HashPassword hashes a password using bcrypt
 func (s *AuthService) HashPassword(password string) (string, error) {
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return "", fmt.Errorf("failed to hash password: %v", err)
+		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 	return string(hashedBytes), nil
 }
@@ -64,7 +111,7 @@ func (s *AuthService) GenerateToken(user *models.User) (string, time.Time, error
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(s.config.JWT.Secret))
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to generate token: %v", err)
+		return "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	return tokenString, expirationTime, nil
@@ -81,22 +128,22 @@ func (s *AuthService) ValidateToken(tokenString string) (*middleware.Claims, err
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %v", err)
+		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	claims, ok := token.Claims.(*middleware.Claims)
 	if !ok || !token.Valid {
-		return nil, errors.New("invalid token")
+		return nil, ErrInvalidToken
 	}
 
 	// Check if token is expired
 	if time.Unix(claims.ExpiresAt, 0).Before(time.Now()) {
-		return nil, errors.New("token has expired")
+		return nil, ErrTokenExpired
 	}
 
 	// Check if token is used before valid time
 	if time.Unix(claims.NotBefore, 0).After(time.Now()) {
-		return nil, errors.New("token not valid yet")
+		return nil, fmt.Errorf("token not valid yet: %w", ErrInvalidToken)
 	}
 
 	return claims, nil
@@ -106,13 +153,13 @@ func (s *AuthService) ValidateToken(tokenString string) (*middleware.Claims, err
 func (s *AuthService) RefreshToken(tokenString string) (string, time.Time, error) {
 	claims, err := s.ValidateToken(tokenString)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("cannot refresh invalid token: %v", err)
+		return "", time.Time{}, fmt.Errorf("cannot refresh invalid token: %w", err)
 	}
 
 	// Check if token is close to expiration (within 1 hour)
 	expirationTime := time.Unix(claims.ExpiresAt, 0)
 	if time.Until(expirationTime) > time.Hour {
-		return "", time.Time{}, errors.New("token is not close to expiration")
+		return "", time.Time{}, &InputError{Reason: "token is not close to expiration"}
 	}
 
 	// Generate new token with updated expiration
@@ -123,7 +170,7 @@ func (s *AuthService) RefreshToken(tokenString string) (string, time.Time, error
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	newTokenString, err := token.SignedString([]byte(s.config.JWT.Secret))
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to generate refresh token: %v", err)
+		return "", time.Time{}, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
 	return newTokenString, newExpirationTime, nil
@@ -132,11 +179,11 @@ func (s *AuthService) RefreshToken(tokenString string) (string, time.Time, error
 // ValidateUserCredentials validates user login credentials
 func (s *AuthService) ValidateUserCredentials(email, password string, user *models.User) error {
 	if user == nil {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 
 	if !user.IsActive {
-		return errors.New("user account is disabled")
+		return ErrUserDisabled
 	}
 
 	if err := s.CheckPassword(password, user.Password); err != nil {
@@ -144,7 +191,7 @@ func (s *AuthService) ValidateUserCredentials(email, password string, user *mode
 			zap.String("email", email),
 			zap.String("user_id", user.ID.String()),
 		)
-		return errors.New("invalid credentials")
+		return ErrInvalidCredentials
 	}
 
 	return nil
@@ -167,10 +214,31 @@ func (s *AuthService) GetUserFromToken(tokenString string) (*models.User, error)
 	return user, nil
 }
 
-// ValidatePasswordStrength validates password strength requirements
+// PasswordPolicy returns the active password policy
+func (s *AuthService) PasswordPolicy() models.PasswordPolicy {
+	policy := s.config.Auth.PasswordPolicy
+	return models.PasswordPolicy{
+		MinLength:             policy.MinLength,
+		MaxLength:             policy.MaxLength,
+		RequireUpper:          policy.RequireUpper,
+		RequireLower:          policy.RequireLower,
+		RequireDigit:          policy.RequireDigit,
+		RequireSpecial:        policy.RequireSpecial,
+		RejectCommonPasswords: len(s.commonPasswords) > 0,
+	}
+}
+
+// ValidatePasswordStrength validates a password against the configured policy,
+// reporting the first rule it fails
 func (s *AuthService) ValidatePasswordStrength(password string) error {
-	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters long")
+	policy := s.config.Auth.PasswordPolicy
+
+	if policy.MinLength > 0 && len(password) < policy.MinLength {
+		return &InputError{Reason: fmt.Sprintf("password must be at least %d characters long", policy.MinLength)}
+	}
+	// bcrypt only considers the first 72 bytes, so the default maximum matches it
+	if policy.MaxLength > 0 && len(password) > policy.MaxLength {
+		return &InputError{Reason: fmt.Sprintf("password must be at most %d characters long", policy.MaxLength)}
 	}
 
 	hasUpper := false
@@ -180,28 +248,32 @@ func (s *AuthService) ValidatePasswordStrength(password string) error {
 
 	for _, char := range password {
 		switch {
-		case 'a' <= char && char <= 'z':
+		case unicode.IsLower(char):
 			hasLower = true
-		case 'A' <= char && char <= 'Z':
+		case unicode.IsUpper(char):
 			hasUpper = true
-		case '0' <= char && char <= '9':
+		case unicode.IsDigit(char):
 			hasDigit = true
 		default:
 			hasSpecial = true
 		}
 	}
 
-	if !hasLower {
-		return errors.New("password must contain at least one lowercase letter")
+	if policy.RequireLower && !hasLower {
+		return &InputError{Reason: "password must contain at least one lowercase letter"}
 	}
-	if !hasUpper {
-		return errors.New("password must contain at least one uppercase letter")
+	if policy.RequireUpper && !hasUpper {
+		return &InputError{Reason: "password must contain at least one uppercase letter"}
 	}
-	if !hasDigit {
-		return errors.New("password must contain at least one digit")
+	if policy.RequireDigit && !hasDigit {
+		return &InputError{Reason: "password must contain at least one digit"}
 	}
-	if !hasSpecial {
-		return errors.New("password must contain at least one special character")
+	if policy.RequireSpecial && !hasSpecial {
+		return &InputError{Reason: "password must contain at least one special character"}
+	}
+
+	if _, found := s.commonPasswords[strings.ToLower(password)]; found {
+		return &InputError{Reason: "password is too common and appears in known breach lists"}
 	}
 
 	return nil
@@ -222,7 +294,7 @@ func (s *AuthService) GeneratePasswordResetToken(userID uuid.UUID) (string, time
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(s.config.JWT.Secret + "reset"))
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to generate password reset token: %v", err)
+		return "", time.Time{}, fmt.Errorf("failed to generate password reset token: %w", err)
 	}
 
 	return tokenString, expirationTime, nil
@@ -238,21 +310,21 @@ func (s *AuthService) ValidatePasswordResetToken(tokenString string) (uuid.UUID,
 	})
 
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to parse reset token: %v", err)
+		return uuid.Nil, fmt.Errorf("failed to parse reset token: %w", err)
 	}
 
 	claims, ok := token.Claims.(*jwt.StandardClaims)
 	if !ok || !token.Valid {
-		return uuid.Nil, errors.New("invalid reset token")
+		return uuid.Nil, fmt.Errorf("invalid reset token: %w", ErrInvalidToken)
 	}
 
 	if time.Unix(claims.ExpiresAt, 0).Before(time.Now()) {
-		return uuid.Nil, errors.New("reset token has expired")
+		return uuid.Nil, fmt.Errorf("reset token has expired: %w", ErrTokenExpired)
 	}
 
 	userID, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid user ID in token: %v", err)
+		return uuid.Nil, fmt.Errorf("invalid user ID in token: %w", err)
 	}
 
 	return userID, nil
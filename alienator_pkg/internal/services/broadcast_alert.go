@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/alert"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/models/proto"
+)
+
+// Headers set on broadcast alert messages
+const (
+	AlertContentTypeHeader = "content-type"
+	AlertFormatHeader      = "alert-format"
+)
+
+// SetAlertTemplates replaces the templates used to render anomaly alerts
+func (bs *BroadcastService) SetAlertTemplates(templates *alert.Templates) {
+	bs.alerts = templates
+}
+
+// NewAlert builds the alert for a detection result, with an excerpt of text
+// as long as the alert templates allow
+func (bs *BroadcastService) NewAlert(id string, result *models.AnomalyResult, text string) *alert.Alert {
+	return alert.New(id, result, text, bs.alerts.ExcerptLength())
+}
+
+// BroadcastAlert renders an anomaly alert with the channel's template and
+// broadcasts it. The message headers name the payload's content type and
+// alert format so consumers know how to forward it.
+func (bs *BroadcastService) BroadcastAlert(ctx context.Context, channelID string, a *alert.Alert) error {
+	tmpl := bs.alerts.For(channelID)
+	payload, err := tmpl.Render(a)
+	if err != nil {
+		return fmt.Errorf("failed to render alert for channel %s: %w", channelID, err)
+	}
+
+	now := time.Now()
+	return bs.Broadcast(ctx, channelID, &proto.Message{
+		ID:        fmt.Sprintf("alert_%s_%d", a.ID, now.UnixNano()),
+		Data:      payload,
+		Timestamp: &now,
+		Headers: map[string]string{
+			AlertContentTypeHeader: tmpl.ContentType(),
+			AlertFormatHeader:      tmpl.Format(),
+		},
+	})
+}
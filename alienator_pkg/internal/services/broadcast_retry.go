@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models/proto"
+	"go.uber.org/zap"
+)
+
+// Broadcast failure reasons recorded in metrics
+const (
+	broadcastFailureExhausted = "retries_exhausted"
+	broadcastFailurePermanent = "permanent_error"
+	broadcastFailureCancelled = "cancelled"
+)
+
+// RetryPolicy controls how Broadcast retries publishes that fail with a
+// transient broker error. Delays double from InitialBackoff up to MaxBackoff
+// and are jittered so that many publishers don't retry in lockstep.
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// OnRetry, if set, is called before each retry with the number of the
+	// attempt that failed, the delay before the next one and the error
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// DefaultRetryPolicy returns the policy used by new broadcast services
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// backoff returns the delay before the given retry (starting at 1): half the
+// exponential delay plus a random share of the other half
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.MaxBackoff
+	if shift := retry - 1; shift < 32 {
+		if exp := p.InitialBackoff << uint(shift); exp > 0 && exp < delay {
+			delay = exp
+		}
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// BroadcastError is returned by Broadcast when a message could not be
+// published, either because the error was not transient or because every
+// retry failed
+type BroadcastError struct {
+	ChannelID string
+	Attempts  int
+	Err       error
+}
+
+// Error implements the error interface
+func (e *BroadcastError) Error() string {
+	return fmt.Sprintf("failed to publish message to channel %s after %d attempt(s): %v", e.ChannelID, e.Attempts, e.Err)
+}
+
+// Unwrap exposes the last publish error
+func (e *BroadcastError) Unwrap() error {
+	return e.Err
+}
+
+// isTransientPublishError reports whether a publish to broker may succeed if
+// retried. Brokers that classify their own errors decide; for the others,
+// only errors reporting themselves temporary are retried.
+func isTransientPublishError(broker core.MessageBroker, err error) bool {
+	if classifier, ok := broker.(core.TransientErrorClassifier); ok {
+		return classifier.IsTransient(err)
+	}
+
+	var temporary interface{ Temporary() bool }
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
+// SetRetryPolicy replaces the policy used to retry failed publishes
+func (bs *BroadcastService) SetRetryPolicy(policy RetryPolicy) {
+	bs.retryPolicy = policy
+}
+
+// publishWithRetry publishes message to topic, retrying transient errors
+// according to the retry policy
+func (bs *BroadcastService) publishWithRetry(ctx context.Context, channelID, topic string, message *proto.Message) error {
+	policy := bs.retryPolicy
+
+	for attempt := 1; ; attempt++ {
+		err := bs.messageBroker.Publish(ctx, topic, message)
+		if err == nil {
+			return nil
+		}
+
+		transient := isTransientPublishError(bs.messageBroker, err)
+		if !transient || attempt > policy.MaxRetries {
+			reason := broadcastFailurePermanent
+			if transient {
+				reason = broadcastFailureExhausted
+			}
+			bs.recordBroadcastFailure(reason)
+			return &BroadcastError{ChannelID: channelID, Attempts: attempt, Err: err}
+		}
+
+		delay := policy.backoff(attempt)
+		bs.logger.Warn("Transient broadcast publish error, retrying",
+			zap.String("channel_id", channelID),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, delay, err)
+		}
+		bs.recordBroadcastRetry()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			bs.recordBroadcastFailure(broadcastFailureCancelled)
+			return &BroadcastError{ChannelID: channelID, Attempts: attempt, Err: errors.Join(ctx.Err(), err)}
+		case <-timer.C:
+		}
+	}
+}
+
+func (bs *BroadcastService) recordBroadcastRetry() {
+	bs.metricsMu.Lock()
+	bs.metrics.PublishRetries++
+	bs.metricsMu.Unlock()
+
+	if bs.collector != nil {
+		bs.collector.RecordBroadcastRetry()
+	}
+}
+
+func (bs *BroadcastService) recordBroadcastFailure(reason string) {
+	bs.metricsMu.Lock()
+	bs.metrics.FailedBroadcasts++
+	bs.metricsMu.Unlock()
+
+	if bs.collector != nil {
+		bs.collector.RecordBroadcastFailure(reason)
+	}
+}
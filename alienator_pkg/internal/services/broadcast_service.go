@@ -6,8 +6,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ruvnet/alienator/internal/alert"
 	"github.com/ruvnet/alienator/internal/core"
 	"github.com/ruvnet/alienator/internal/models/proto"
+	"github.com/ruvnet/alienator/pkg/metrics"
 	"go.uber.org/zap"
 )
 
@@ -28,6 +30,10 @@ type BroadcastService struct {
 	// Metrics
 	metrics *BroadcastMetrics
 	metricsMu sync.RWMutex
+	collector *metrics.Metrics
+
+	retryPolicy RetryPolicy
+	alerts      *alert.Templates
 }
 
 // BroadcastMetrics holds broadcasting metrics
@@ -36,6 +42,8 @@ type BroadcastMetrics struct {
 	TotalSubscriptions int64
 	MessagesBroadcast  int64
 	MessagesDelivered  int64
+	PublishRetries     int64
+	FailedBroadcasts   int64
 	LastActivity       time.Time
 }
 
@@ -48,9 +56,16 @@ func NewBroadcastService(broker core.MessageBroker, eventBus core.EventBus, logg
 		channels:        make(map[string]*proto.Channel),
 		subscriptions:   make(map[string]*proto.Subscription),
 		metrics:         &BroadcastMetrics{},
+		retryPolicy:     DefaultRetryPolicy(),
+		alerts:          alert.DefaultTemplates(),
 	}
 }
 
+// SetMetrics enables Prometheus metrics for broadcast retries and failures
+func (bs *BroadcastService) SetMetrics(m *metrics.Metrics) {
+	bs.collector = m
+}
+
 // CreateChannel creates a new broadcast channel
 func (bs *BroadcastService) CreateChannel(ctx context.Context, channelID, name, description string) (*proto.Channel, error) {
 	bs.channelsMu.Lock()
@@ -257,7 +272,9 @@ func (bs *BroadcastService) Unsubscribe(ctx context.Context, subscriptionID stri
 	return nil
 }
 
-// Broadcast broadcasts a message to a channel
+// Broadcast broadcasts a message to a channel. Transient broker errors are
+// retried according to the retry policy; a *BroadcastError is returned if the
+// message could not be published.
 func (bs *BroadcastService) Broadcast(ctx context.Context, channelID string, message *proto.Message) error {
 	// Check if channel exists and is active
 	bs.channelsMu.RLock()
@@ -277,10 +294,10 @@ func (bs *BroadcastService) Broadcast(ctx context.Context, channelID string, mes
 		message.Timestamp = &now
 	}
 
-	// Publish message to channel topic
+	// Publish message to channel topic, retrying transient broker errors
 	topic := fmt.Sprintf("channel.%s", channelID)
-	if err := bs.messageBroker.Publish(ctx, topic, message); err != nil {
-		return fmt.Errorf("failed to publish message to channel %s: %w", channelID, err)
+	if err := bs.publishWithRetry(ctx, channelID, topic, message); err != nil {
+		return err
 	}
 
 	bs.metricsMu.Lock()
@@ -354,6 +371,8 @@ func (bs *BroadcastService) GetMetrics() *BroadcastMetrics {
 		TotalSubscriptions: bs.metrics.TotalSubscriptions,
 		MessagesBroadcast:  bs.metrics.MessagesBroadcast,
 		MessagesDelivered:  bs.metrics.MessagesDelivered,
+		PublishRetries:     bs.metrics.PublishRetries,
+		FailedBroadcasts:   bs.metrics.FailedBroadcasts,
 		LastActivity:       bs.metrics.LastActivity,
 	}
 }
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/hashing"
+)
+
+// AnomalyFingerprint identifies near-identical anomalies: the same type,
+// severity and source with scores equal to two decimal places
+func AnomalyFingerprint(anomalyType, severity string, score float64, source string) string {
+	rounded := math.Round(score*100) / 100
+	return hashing.Sum([]byte(fmt.Sprintf("%s|%s|%.2f|%s", anomalyType, severity, rounded, source)))
+}
+
+// Occurrence counts the anomalies sharing a fingerprint within one
+// suppression window
+type Occurrence struct {
+	Fingerprint string    `json:"fingerprint"`
+	FirstID     string    `json:"first_id"` // ID of the anomaly that was kept
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	Count       int       `json:"occurrence_count"`
+}
+
+// OccurrenceStore persists the occurrences counted by a Deduplicator under
+// the ID of the anomaly kept for each, so that the count of a kept anomaly
+// outlives its suppression window and the process
+type OccurrenceStore interface {
+	// SaveOccurrence stores occurrence, unless a copy with a higher count is
+	// already stored: saves of one occurrence may arrive out of order
+	SaveOccurrence(ctx context.Context, occurrence Occurrence) error
+}
+
+// Deduplicator suppresses anomalies whose fingerprint was already seen within
+// the window. The window starts at the first occurrence, so a storm lasting
+// longer than the window yields one kept anomaly per window.
+type Deduplicator struct {
+	window    time.Duration
+	mu        sync.Mutex
+	seen      map[string]*Occurrence
+	lastSweep time.Time
+}
+
+// NewDeduplicator creates a deduplicator with the given suppression window
+func NewDeduplicator(window time.Duration) *Deduplicator {
+	return &Deduplicator{
+		window: window,
+		seen:   make(map[string]*Occurrence),
+	}
+}
+
+// Observe records an anomaly and reports whether it duplicates one seen
+// within the window. The returned occurrence holds the count so far.
+func (d *Deduplicator) Observe(fingerprint, id string, now time.Time) (Occurrence, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sweep(now)
+
+	if occurrence, ok := d.seen[fingerprint]; ok && now.Sub(occurrence.FirstSeen) < d.window {
+		occurrence.Count++
+		occurrence.LastSeen = now
+		return *occurrence, true
+	}
+
+	occurrence := &Occurrence{
+		Fingerprint: fingerprint,
+		FirstID:     id,
+		FirstSeen:   now,
+		LastSeen:    now,
+		Count:       1,
+	}
+	d.seen[fingerprint] = occurrence
+	return *occurrence, false
+}
+
+// Occurrences returns the fingerprints seen within the current windows, most
+// frequent first
+func (d *Deduplicator) Occurrences() []Occurrence {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	occurrences := make([]Occurrence, 0, len(d.seen))
+	for _, occurrence := range d.seen {
+		occurrences = append(occurrences, *occurrence)
+	}
+	sort.Slice(occurrences, func(i, j int) bool {
+		if occurrences[i].Count != occurrences[j].Count {
+			return occurrences[i].Count > occurrences[j].Count
+		}
+		return occurrences[i].Fingerprint < occurrences[j].Fingerprint
+	})
+	return occurrences
+}
+
+// sweep drops expired windows, at most once per window. Callers must hold d.mu.
+func (d *Deduplicator) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.window {
+		return
+	}
+	for fingerprint, occurrence := range d.seen {
+		if now.Sub(occurrence.FirstSeen) >= d.window {
+			delete(d.seen, fingerprint)
+		}
+	}
+	d.lastSweep = now
+}
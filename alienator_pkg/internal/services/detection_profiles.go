@@ -0,0 +1,153 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// DefaultProfileName names the profile applied to callers whose API key is not
+// bound to one. Storing a profile under this name overrides the built-in default.
+const DefaultProfileName = "default"
+
+// defaultProfile returns the built-in detection profile
+func defaultProfile() *models.DetectionProfile {
+	return &models.DetectionProfile{
+		Name:      DefaultProfileName,
+		Algorithm: "isolation_forest",
+		Threshold: 0.5,
+	}
+}
+
+// hashAPIKey derives the lookup key for an API key so raw keys are never stored
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveProfile returns the profile bound to apiKey, falling back to the stored
+// default profile and then the built-in one. Lookup failures are logged rather
+// than failing the detection.
+func (s *AnomalyService) resolveProfile(apiKey string) *models.DetectionProfile {
+	if apiKey != "" {
+		profile, err := s.repo.GetDetectionProfileByAPIKey(hashAPIKey(apiKey))
+		if err == nil {
+			return profile
+		}
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			s.logger.Warn("Failed to resolve API key profile, using default", zap.Error(err))
+		}
+	}
+
+	profile, err := s.repo.GetDetectionProfileByName(DefaultProfileName)
+	if err == nil {
+		return profile
+	}
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		s.logger.Warn("Failed to load default profile, using built-in", zap.Error(err))
+	}
+
+	return defaultProfile()
+}
+
+// CreateDetectionProfile stores a new named detection profile
+func (s *AnomalyService) CreateDetectionProfile(req *models.DetectionProfileRequest) (*models.DetectionProfile, error) {
+	if _, err := s.repo.GetDetectionProfileByName(req.Name); err == nil {
+		return nil, ErrProfileExists
+	} else if !errors.Is(err, apperrors.ErrNotFound) {
+		return nil, fmt.Errorf("failed to check detection profile: %w", err)
+	}
+
+	profile := &models.DetectionProfile{
+		Name:        req.Name,
+		Description: req.Description,
+		Algorithm:   req.Algorithm,
+		Threshold:   req.Threshold,
+		Weights:     req.Weights,
+	}
+	if err := s.repo.CreateDetectionProfile(profile); err != nil {
+		return nil, fmt.Errorf("failed to create detection profile: %w", err)
+	}
+
+	s.logger.Info("Detection profile created", zap.String("profile", profile.Name))
+	return profile, nil
+}
+
+// GetDetectionProfile retrieves a detection profile by name
+func (s *AnomalyService) GetDetectionProfile(name string) (*models.DetectionProfile, error) {
+	profile, err := s.repo.GetDetectionProfileByName(name)
+	if err != nil {
+		return nil, profileLookupError(err)
+	}
+	return profile, nil
+}
+
+// ListDetectionProfiles retrieves all detection profiles
+func (s *AnomalyService) ListDetectionProfiles() ([]*models.DetectionProfile, error) {
+	profiles, err := s.repo.ListDetectionProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list detection profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// UpdateDetectionProfile replaces the tuning of an existing profile. Profiles
+// cannot be renamed since API key bindings refer to them by identity.
+func (s *AnomalyService) UpdateDetectionProfile(name string, req *models.DetectionProfileRequest) (*models.DetectionProfile, error) {
+	if req.Name != name {
+		return nil, &InputError{Reason: "profile name cannot be changed"}
+	}
+
+	profile := &models.DetectionProfile{
+		Name:        name,
+		Description: req.Description,
+		Algorithm:   req.Algorithm,
+		Threshold:   req.Threshold,
+		Weights:     req.Weights,
+	}
+	if err := s.repo.UpdateDetectionProfile(profile); err != nil {
+		return nil, profileLookupError(err)
+	}
+
+	s.logger.Info("Detection profile updated", zap.String("profile", name))
+	return profile, nil
+}
+
+// DeleteDetectionProfile removes a profile; API keys bound to it fall back to the default
+func (s *AnomalyService) DeleteDetectionProfile(name string) error {
+	if err := s.repo.DeleteDetectionProfile(name); err != nil {
+		return profileLookupError(err)
+	}
+
+	s.logger.Info("Detection profile deleted", zap.String("profile", name))
+	return nil
+}
+
+// AssignDetectionProfile binds an API key to the named profile, replacing any
+// previous binding for that key
+func (s *AnomalyService) AssignDetectionProfile(name, apiKey string) error {
+	profile, err := s.repo.GetDetectionProfileByName(name)
+	if err != nil {
+		return profileLookupError(err)
+	}
+
+	if err := s.repo.AssignAPIKeyProfile(hashAPIKey(apiKey), profile.ID); err != nil {
+		return fmt.Errorf("failed to assign detection profile: %w", err)
+	}
+
+	s.logger.Info("API key bound to detection profile", zap.String("profile", name))
+	return nil
+}
+
+// profileLookupError translates repository not-found errors into ErrProfileNotFound
+func profileLookupError(err error) error {
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return ErrProfileNotFound
+	}
+	return fmt.Errorf("failed to access detection profile: %w", err)
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"fmt"
+
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+)
+
+// Specific service errors, each wrapping one of the error categories of the
+// errors package. Handlers map the categories to HTTP status codes with
+// errors.Is, so services must wrap them (with %w) rather than return bare
+// strings.
+var (
+	ErrPermissionDenied   = fmt.Errorf("insufficient permissions: %w", apperrors.ErrForbidden)
+	ErrUserNotFound       = fmt.Errorf("user %w", apperrors.ErrNotFound)
+	ErrAnomalyNotFound    = fmt.Errorf("anomaly data %w", apperrors.ErrNotFound)
+	ErrEmailTaken         = fmt.Errorf("%w: email already in use", apperrors.ErrConflict)
+	ErrUsernameTaken      = fmt.Errorf("%w: username already in use", apperrors.ErrConflict)
+	ErrUserDisabled       = fmt.Errorf("user account is disabled: %w", apperrors.ErrForbidden)
+	ErrInvalidCredentials = fmt.Errorf("invalid credentials: %w", apperrors.ErrUnauthorized)
+	ErrInvalidToken       = fmt.Errorf("invalid token: %w", apperrors.ErrUnauthorized)
+	ErrTokenExpired       = fmt.Errorf("token has expired: %w", apperrors.ErrUnauthorized)
+	ErrProfileNotFound    = fmt.Errorf("detection profile %w", apperrors.ErrNotFound)
+	ErrProfileExists      = fmt.Errorf("%w: detection profile already exists", apperrors.ErrConflict)
+	ErrAllowlistNotFound  = fmt.Errorf("allowlist entry %w", apperrors.ErrNotFound)
+	ErrJobNotFound        = fmt.Errorf("job %w", apperrors.ErrNotFound)
+	ErrJobFinished        = fmt.Errorf("%w: job has already finished", apperrors.ErrConflict)
+	ErrReviewItemNotFound = fmt.Errorf("review item %w", apperrors.ErrNotFound)
+)
+
+// InputError describes why a caller-supplied value was rejected. Its message is
+// safe to show to clients and it matches apperrors.ErrInvalidInput with
+// errors.Is.
+type InputError struct {
+	Reason string
+}
+
+// Error implements the error interface
+func (e *InputError) Error() string {
+	return e.Reason
+}
+
+// Unwrap places InputError in the invalid input category
+func (e *InputError) Unwrap() error {
+	return apperrors.ErrInvalidInput
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/signing"
+	"github.com/ruvnet/alienator/internal/version"
+)
+
+// EvidenceBundle packages a stored detection for reporting. The input is
+// included unless withheld, in which case only its hash identifies it and
+// nothing derived from its text is kept either: the text of matched spans,
+// the explanation, which quotes phrases, and all analyzer metadata but the
+// numeric and boolean features are left out. When the input has a
+// "text" field and a detector is set, the analyzer scores, features, matched
+// spans and explanation are recomputed from it; they reflect the running
+// detector, whose fingerprint is recorded, and may differ from the stored
+// verdict if the configuration changed since. With signing enabled the
+// stored signature is verified too.
+func (s *AnomalyService) EvidenceBundle(stored *models.AnomalyData, includeInput bool) (*models.EvidenceBundle, error) {
+	build := version.Get()
+	bundle := &models.EvidenceBundle{
+		ID:          stored.ID,
+		GeneratedAt: time.Now().UTC(),
+		Detector: models.EvidenceDetector{
+			Version: build.Version,
+			Commit:  build.Commit,
+		},
+		Verdict: models.EvidenceVerdict{
+			Score:       stored.Score,
+			IsAnomaly:   stored.IsAnomaly,
+			Threshold:   stored.Threshold,
+			Algorithm:   stored.Algorithm,
+			ProcessedAt: stored.ProcessedAt,
+		},
+		Signature: stored.Signature,
+	}
+	if includeInput {
+		bundle.Input = stored.Data
+	}
+
+	if stored.Signature != nil {
+		bundle.InputHash = stored.Signature.InputHash
+	} else {
+		hash, err := signing.HashInput(stored.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash detection input: %w", err)
+		}
+		bundle.InputHash = hash
+	}
+
+	if text, ok := stored.Data["text"].(string); ok && s.detector != nil {
+		analysis, err := s.detector.Evidence(text)
+		if err != nil {
+			return nil, fmt.Errorf("text analysis failed: %w", err)
+		}
+		if !includeInput {
+			withholdText(analysis)
+		}
+		bundle.Analysis = analysis
+		bundle.Detector.Fingerprint = s.detector.Fingerprint()
+	}
+
+	if s.signer != nil {
+		verification, err := s.VerifyStoredDetection(stored)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Verification = verification
+	}
+	return bundle, nil
+}
+
+// withholdText leaves out of analysis whatever may reproduce the analyzed
+// text. The analyzer results may be shared with the detector's caches, so
+// they are copied rather than changed.
+func withholdText(analysis *models.EvidenceAnalysis) {
+	for i := range analysis.Spans {
+		analysis.Spans[i].Text = ""
+	}
+	analysis.Explanation = ""
+
+	analyzers := make(map[string]*models.AnalysisResult, len(analysis.Analyzers))
+	for name, result := range analysis.Analyzers {
+		redacted := &models.AnalysisResult{Score: result.Score, Confidence: result.Confidence}
+		for key, value := range result.Metadata {
+			switch value.(type) {
+			case bool, int, int32, int64, float32, float64:
+				if redacted.Metadata == nil {
+					redacted.Metadata = make(map[string]interface{})
+				}
+				redacted.Metadata[key] = value
+			}
+		}
+		analyzers[name] = redacted
+	}
+	analysis.Analyzers = analyzers
+}
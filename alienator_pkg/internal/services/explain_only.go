@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// SetExplainOnly enables explain-only detections, leaving out the analyzers
+// cfg excludes; they are disabled unless set
+func (s *AnomalyService) SetExplainOnly(cfg config.ExplainOnlyConfig) {
+	s.explainOnly = cfg
+}
+
+// ExplainOnlyEnabled reports whether ExplainDetection is available
+func (s *AnomalyService) ExplainOnlyEnabled() bool {
+	return s.explainOnly.Enabled
+}
+
+// ExplainDetection scores a detection request like ProcessDetection without
+// the expensive analyzers, explaining the verdict in plain language. The
+// result is neither stored, signed nor queued for review; its ID is the
+// zero UUID.
+func (s *AnomalyService) ExplainDetection(ctx context.Context, userID uuid.UUID, apiKey string, req *models.DetectionRequest) (*models.DetectionResult, error) {
+	if !s.explainOnly.Enabled {
+		return nil, &InputError{Reason: "explain-only detections are not enabled on this server"}
+	}
+	return s.detect(ctx, userID, apiKey, req, true)
+}
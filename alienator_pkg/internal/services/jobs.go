@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/jobs"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// Limits on the number of jobs returned by ListJobs
+const (
+	defaultJobListLimit = 50
+	maxJobListLimit     = 500
+)
+
+// JobService queues long operations for the worker and reports on them
+type JobService struct {
+	store  jobs.Store
+	cfg    config.JobsConfig
+	logger *zap.Logger
+}
+
+// NewJobService creates a new job service
+func NewJobService(store jobs.Store, cfg config.JobsConfig, logger *zap.Logger) *JobService {
+	return &JobService{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// SubmitJob queues a job of the given kind; params are stored as JSON for the
+// worker's handler to decode
+func (s *JobService) SubmitJob(ctx context.Context, kind string, params interface{}, createdBy uuid.UUID) (*models.Job, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job parameters: %w", err)
+	}
+
+	job := &models.Job{
+		Kind:      kind,
+		Params:    encoded,
+		CreatedBy: createdBy,
+	}
+	if err := s.store.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to queue job: %w", err)
+	}
+
+	s.logger.Info("Job queued",
+		zap.String("job_id", job.ID.String()),
+		zap.String("kind", kind),
+		zap.String("created_by", createdBy.String()),
+	)
+	return job, nil
+}
+
+// GetJob retrieves a job by ID
+func (s *JobService) GetJob(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	job, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, jobLookupError(err)
+	}
+	return job, nil
+}
+
+// ListJobs returns the most recent jobs, newest first, optionally only those
+// with the given status or kind
+func (s *JobService) ListJobs(ctx context.Context, status, kind string, limit int) ([]*models.Job, error) {
+	switch status {
+	case "", models.JobQueued, models.JobRunning, models.JobSucceeded, models.JobFailed, models.JobCancelled:
+	default:
+		return nil, &InputError{Reason: fmt.Sprintf("unknown job status %q", status)}
+	}
+	if limit <= 0 {
+		limit = defaultJobListLimit
+	}
+	if limit > maxJobListLimit {
+		limit = maxJobListLimit
+	}
+
+	list, err := s.store.List(ctx, jobs.ListFilter{Status: status, Kind: kind, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	if list == nil {
+		list = []*models.Job{}
+	}
+	return list, nil
+}
+
+// CancelJob cancels a queued job, or asks the worker running it to stop
+func (s *JobService) CancelJob(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	job, err := s.store.Cancel(ctx, id)
+	if err != nil {
+		return nil, jobLookupError(err)
+	}
+
+	s.logger.Info("Job cancellation requested",
+		zap.String("job_id", id.String()),
+		zap.String("status", job.Status),
+	)
+	return job, nil
+}
+
+// RetrainDetector queues retraining of the neural detector
+func (s *JobService) RetrainDetector(ctx context.Context, req *models.RetrainRequest, createdBy uuid.UUID) (*models.Job, error) {
+	switch req.Source {
+	case models.RetrainFromDataset:
+		if s.cfg.Retrain.Dataset == "" {
+			return nil, &InputError{Reason: "no retraining dataset is configured"}
+		}
+	case models.RetrainFromStored:
+	default:
+		return nil, &InputError{Reason: fmt.Sprintf("unsupported retraining source %q", req.Source)}
+	}
+	return s.SubmitJob(ctx, jobs.KindRetrain, req, createdBy)
+}
+
+// jobLookupError translates job store errors into service errors
+func jobLookupError(err error) error {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return ErrJobNotFound
+	case errors.Is(err, jobs.ErrFinished):
+		return ErrJobFinished
+	}
+	return fmt.Errorf("failed to access job: %w", err)
+}
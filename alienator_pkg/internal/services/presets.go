@@ -0,0 +1,41 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ruvnet/alienator/internal/core"
+)
+
+// SetPresets makes the detectors, each built from a preset resolved into the
+// detector configuration, selectable by preset name in detection requests
+func (s *AnomalyService) SetPresets(detectors map[string]*core.AnomalyDetector) {
+	s.presets = detectors
+}
+
+// PresetNames returns the names of the selectable presets, sorted
+func (s *AnomalyService) PresetNames() []string {
+	names := make([]string, 0, len(s.presets))
+	for name := range s.presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// textDetector returns the detector for the named preset, or the default
+// detector when preset is empty; an unknown preset is an InputError
+func (s *AnomalyService) textDetector(preset string) (*core.AnomalyDetector, error) {
+	if preset == "" {
+		return s.detector, nil
+	}
+	detector, ok := s.presets[preset]
+	if !ok {
+		if len(s.presets) == 0 {
+			return nil, &InputError{Reason: "presets are not available on this server"}
+		}
+		return nil, &InputError{Reason: fmt.Sprintf("unknown preset %q (expected one of %s)", preset, strings.Join(s.PresetNames(), ", "))}
+	}
+	return detector, nil
+}
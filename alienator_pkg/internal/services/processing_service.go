@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -27,6 +29,14 @@ type ProcessingService struct {
 	// Worker management
 	workers   map[string]*ProcessingWorker
 	workersMu sync.RWMutex
+
+	// Concurrency limit shared by all queue workers; nil means unbounded
+	inFlight chan struct{}
+
+	// De-duplication of near-identical anomalies; nil means disabled
+	dedup *Deduplicator
+	// Where occurrence counts are persisted; nil keeps them in memory only
+	occurrences OccurrenceStore
 }
 
 // ProcessingMetrics holds processing metrics
@@ -35,7 +45,9 @@ type ProcessingMetrics struct {
 	TotalFailed      int64
 	AverageLatency   float64
 	ActiveWorkers    int64
+	InFlight         int64
 	QueuedMessages   int64
+	TotalSuppressed  int64 // duplicate anomalies counted instead of processed
 	LastActivity     time.Time
 }
 
@@ -73,12 +85,27 @@ func (ps *ProcessingService) RegisterProcessor(processor MessageProcessor) {
 	ps.logger.Info("Message processor registered", zap.String("name", processor.Name()))
 }
 
-// ProcessMessage processes a single message through the pipeline
+// anomalyMessage is the part of an anomaly message used for fingerprinting.
+// Messages whose data doesn't decode to one are never de-duplicated.
+type anomalyMessage struct {
+	Type     string  `json:"type"`
+	Severity string  `json:"severity"`
+	Score    float64 `json:"score"`
+	Source   string  `json:"source"`
+}
+
+// ProcessMessage processes a single message through the pipeline. When a
+// suppression window is set, a near-identical anomaly already seen within
+// the window is counted and dropped: the result is then a nil message.
 func (ps *ProcessingService) ProcessMessage(ctx context.Context, msg *proto.Message) (*proto.Message, error) {
 	startTime := time.Now()
 	processedMsg := msg
 	var err error
 
+	if ps.suppressDuplicate(ctx, msg) {
+		return nil, nil
+	}
+
 	// Process through each registered processor
 	for _, processor := range ps.processors {
 		processedMsg, err = processor.Process(ctx, processedMsg)
@@ -139,68 +166,226 @@ func (ps *ProcessingService) ProcessQueue(ctx context.Context, queueName string,
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			// Dequeue message
-			queueMsg, err := ps.messageQueue.Dequeue(ctx, queueName, timeout)
-			if err != nil {
-				ps.logger.Error("Failed to dequeue message",
-					zap.String("queue", queueName),
-					zap.Error(err),
-				)
-				continue
-			}
+		}
 
-			if queueMsg == nil {
-				continue // No messages available
-			}
+		// Wait for an in-flight slot before taking a message off the queue
+		if !ps.acquireSlot(ctx) {
+			return ctx.Err()
+		}
+		ps.processNext(ctx, queueName, timeout, workerID)
+		ps.releaseSlot()
+	}
+}
 
-			// Process message
-			processedMsg, err := ps.ProcessMessage(ctx, queueMsg.Message)
-			if err != nil {
-				ps.logger.Error("Failed to process message",
-					zap.String("queue_message_id", queueMsg.Id),
-					zap.String("message_id", queueMsg.Message.ID),
-					zap.Error(err),
-				)
-
-				// Negative acknowledge for requeuing
-				if nackErr := ps.messageQueue.Nack(ctx, queueMsg.Id, true); nackErr != nil {
-					ps.logger.Error("Failed to nack message",
-						zap.String("queue_message_id", queueMsg.Id),
-						zap.Error(nackErr),
-					)
-				}
-				continue
-			}
+// processNext dequeues and processes a single message from the queue
+func (ps *ProcessingService) processNext(ctx context.Context, queueName string, timeout time.Duration, workerID string) {
+	// Dequeue message
+	queueMsg, err := ps.messageQueue.Dequeue(ctx, queueName, timeout)
+	if err != nil {
+		ps.logger.Error("Failed to dequeue message",
+			zap.String("queue", queueName),
+			zap.Error(err),
+		)
+		return
+	}
 
-			// Handle processed message (could be different from original)
-			if processedMsg != nil {
-				// If message was transformed, emit transformed event
-				if processedMsg.ID != queueMsg.Message.ID {
-					if err := ps.eventBus.Emit(ctx, &proto.Event{
-						Type:   "message.transformed",
-						Source: "processing_service",
-						Data: map[string]interface{}{
-							"original_id":   queueMsg.Message.ID,
-							"transformed_id": processedMsg.ID,
-						},
-					}); err != nil {
-						ps.logger.Error("Failed to emit transformation event", zap.Error(err))
-					}
-				}
-			}
+	if queueMsg == nil {
+		return // No messages available
+	}
+
+	// Process message
+	processedMsg, err := ps.ProcessMessage(ctx, queueMsg.Message)
+	if err != nil {
+		ps.logger.Error("Failed to process message",
+			zap.String("queue_message_id", queueMsg.Id),
+			zap.String("message_id", queueMsg.Message.ID),
+			zap.Error(err),
+		)
+
+		// Negative acknowledge for requeuing
+		if nackErr := ps.messageQueue.Nack(ctx, queueMsg.Id, true); nackErr != nil {
+			ps.logger.Error("Failed to nack message",
+				zap.String("queue_message_id", queueMsg.Id),
+				zap.Error(nackErr),
+			)
+		}
+		return
+	}
 
-			// Acknowledge successful processing
-			if err := ps.messageQueue.Ack(ctx, queueMsg.Id); err != nil {
-				ps.logger.Error("Failed to acknowledge message",
-					zap.String("queue_message_id", queueMsg.Id),
-					zap.Error(err),
-				)
+	// Handle processed message (could be different from original)
+	if processedMsg != nil {
+		// If message was transformed, emit transformed event
+		if processedMsg.ID != queueMsg.Message.ID {
+			if err := ps.eventBus.Emit(ctx, &proto.Event{
+				Type:   "message.transformed",
+				Source: "processing_service",
+				Data: map[string]interface{}{
+					"original_id":    queueMsg.Message.ID,
+					"transformed_id": processedMsg.ID,
+				},
+			}); err != nil {
+				ps.logger.Error("Failed to emit transformation event", zap.Error(err))
 			}
+		}
+	}
+
+	// Acknowledge successful processing
+	if err := ps.messageQueue.Ack(ctx, queueMsg.Id); err != nil {
+		ps.logger.Error("Failed to acknowledge message",
+			zap.String("queue_message_id", queueMsg.Id),
+			zap.Error(err),
+		)
+	}
+
+	// Update worker activity
+	ps.updateWorkerActivity(workerID)
+}
+
+// SetMaxInFlight bounds how many messages are processed concurrently across
+// all queue workers. A value of zero or less removes the bound. It must be
+// called before any queue worker is started.
+func (ps *ProcessingService) SetMaxInFlight(maxInFlight int) {
+	if maxInFlight <= 0 {
+		ps.inFlight = nil
+		return
+	}
+	ps.inFlight = make(chan struct{}, maxInFlight)
+}
+
+// SetSuppressionWindow enables de-duplication of anomaly messages: within
+// window of the first occurrence, anomalies with the same fingerprint (type,
+// severity, rounded score and source) are counted instead of processed, and
+// an "anomaly.suppressed" event reports the running occurrence_count. The
+// kept message carries "fingerprint" and "occurrence_count" headers, the
+// latter 1 as it is the first; the occurrence store, if set, is updated as
+// duplicates are counted. A window of zero or less disables de-duplication.
+// It must be called before any queue worker is started.
+func (ps *ProcessingService) SetSuppressionWindow(window time.Duration) {
+	if window <= 0 {
+		ps.dedup = nil
+		return
+	}
+	ps.dedup = NewDeduplicator(window)
+}
+
+// SetOccurrenceStore persists the occurrence count of every kept anomaly,
+// saving it when the anomaly is kept and again on each duplicate. A nil
+// store keeps the counts in memory only. It must be called before any queue
+// worker is started.
+func (ps *ProcessingService) SetOccurrenceStore(store OccurrenceStore) {
+	ps.occurrences = store
+}
+
+// GetOccurrences returns the anomaly fingerprints seen within the current
+// suppression windows with their occurrence counts
+func (ps *ProcessingService) GetOccurrences() []Occurrence {
+	if ps.dedup == nil {
+		return []Occurrence{}
+	}
+	return ps.dedup.Occurrences()
+}
+
+// suppressDuplicate fingerprints an anomaly message and reports whether it
+// duplicates one already seen within the suppression window
+func (ps *ProcessingService) suppressDuplicate(ctx context.Context, msg *proto.Message) bool {
+	if ps.dedup == nil {
+		return false
+	}
+
+	var anomaly anomalyMessage
+	if err := json.Unmarshal(msg.Data, &anomaly); err != nil || anomaly.Type == "" || anomaly.Severity == "" {
+		return false
+	}
+
+	fingerprint := AnomalyFingerprint(anomaly.Type, anomaly.Severity, anomaly.Score, anomaly.Source)
+	occurrence, duplicate := ps.dedup.Observe(fingerprint, msg.ID, time.Now())
+	ps.saveOccurrence(ctx, occurrence)
+	if !duplicate {
+		if msg.Headers == nil {
+			msg.Headers = make(map[string]string)
+		}
+		msg.Headers["fingerprint"] = fingerprint
+		msg.Headers["occurrence_count"] = strconv.Itoa(occurrence.Count)
+		return false
+	}
+
+	ps.metricsMu.Lock()
+	ps.metrics.TotalSuppressed++
+	ps.metricsMu.Unlock()
+
+	if err := ps.eventBus.Emit(ctx, &proto.Event{
+		Type:   "anomaly.suppressed",
+		Source: "processing_service",
+		Data: map[string]interface{}{
+			"message_id":       msg.ID,
+			"first_id":         occurrence.FirstID,
+			"fingerprint":      fingerprint,
+			"occurrence_count": occurrence.Count,
+			"first_seen":       occurrence.FirstSeen,
+			"last_seen":        occurrence.LastSeen,
+		},
+	}); err != nil {
+		ps.logger.Error("Failed to emit anomaly suppressed event", zap.Error(err))
+	}
+
+	ps.logger.Debug("Duplicate anomaly suppressed",
+		zap.String("message_id", msg.ID),
+		zap.String("fingerprint", fingerprint),
+		zap.Int("occurrence_count", occurrence.Count),
+	)
+	return true
+}
 
-			// Update worker activity
-			ps.updateWorkerActivity(workerID)
+// saveOccurrence persists an occurrence count. A failure is only logged:
+// the message is still kept or suppressed, and the next duplicate saves the
+// count again.
+func (ps *ProcessingService) saveOccurrence(ctx context.Context, occurrence Occurrence) {
+	if ps.occurrences == nil {
+		return
+	}
+	if err := ps.occurrences.SaveOccurrence(ctx, occurrence); err != nil {
+		ps.logger.Error("Failed to save anomaly occurrence",
+			zap.String("first_id", occurrence.FirstID),
+			zap.Int("occurrence_count", occurrence.Count),
+			zap.Error(err),
+		)
+	}
+}
+
+// acquireSlot blocks until an in-flight slot is free or the context is done
+func (ps *ProcessingService) acquireSlot(ctx context.Context) bool {
+	if ps.inFlight != nil {
+		select {
+		case ps.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return false
 		}
 	}
+
+	ps.metricsMu.Lock()
+	ps.metrics.InFlight++
+	ps.metricsMu.Unlock()
+	return true
+}
+
+// releaseSlot frees an in-flight slot
+func (ps *ProcessingService) releaseSlot() {
+	ps.metricsMu.Lock()
+	ps.metrics.InFlight--
+	ps.metricsMu.Unlock()
+
+	if ps.inFlight != nil {
+		<-ps.inFlight
+	}
+}
+
+// GetQueueDepth returns the number of messages waiting in the named queue
+func (ps *ProcessingService) GetQueueDepth(queueName string) (int64, error) {
+	stats, err := ps.messageQueue.GetStats(queueName)
+	if err != nil {
+		return 0, err
+	}
+	return stats.Size, nil
 }
 
 // registerWorker registers a new processing worker
@@ -299,7 +484,9 @@ func (ps *ProcessingService) GetMetrics() *ProcessingMetrics {
 		TotalFailed:     ps.metrics.TotalFailed,
 		AverageLatency:  ps.metrics.AverageLatency,
 		ActiveWorkers:   ps.metrics.ActiveWorkers,
+		InFlight:        ps.metrics.InFlight,
 		QueuedMessages:  ps.metrics.QueuedMessages,
+		TotalSuppressed: ps.metrics.TotalSuppressed,
 		LastActivity:    ps.metrics.LastActivity,
 	}
 }
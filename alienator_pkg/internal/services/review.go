@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// SetReviewBand queues stored detections whose score is within band of their
+// threshold for a human label. A band of 0 disables the review queue, as it
+// is until a band is set; the API server sets review.band, 0.05 by default.
+func (s *AnomalyService) SetReviewBand(band float64) {
+	s.reviewBand = band
+}
+
+// enqueueReview queues a stored detection for review when its score is within
+// the review band of its threshold, and returns the queued item. Failures are
+// logged rather than failing the detection.
+func (s *AnomalyService) enqueueReview(data *models.AnomalyData) *models.ReviewItem {
+	if s.reviewBand <= 0 || math.Abs(data.Score-data.Threshold) > s.reviewBand {
+		return nil
+	}
+
+	item := &models.ReviewItem{
+		AnomalyDataID: data.ID,
+		Score:         data.Score,
+		Threshold:     data.Threshold,
+		IsAnomaly:     data.IsAnomaly,
+	}
+	if err := s.repo.CreateReviewItem(item); err != nil {
+		s.logger.Warn("Failed to queue detection for review", zap.String("anomaly_id", data.ID.String()), zap.Error(err))
+		return nil
+	}
+	return item
+}
+
+// ListReviewQueue retrieves a page of queued detections with the given
+// status, or any status when it is empty, oldest first
+func (s *AnomalyService) ListReviewQueue(status string, page, limit int) ([]*models.ReviewItem, *models.Meta, error) {
+	switch status {
+	case "", models.ReviewPending, models.ReviewLabeled:
+	default:
+		return nil, nil, &InputError{Reason: fmt.Sprintf("unknown review status %q, expected %s or %s", status, models.ReviewPending, models.ReviewLabeled)}
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	items, total, err := s.repo.ListReviewItems(status, page, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list review queue: %w", err)
+	}
+
+	meta := &models.Meta{
+		Page:       page,
+		PerPage:    limit,
+		Total:      total,
+		TotalPages: (total + limit - 1) / limit,
+	}
+	return items, meta, nil
+}
+
+// GetReviewItem retrieves a queued detection by ID
+func (s *AnomalyService) GetReviewItem(id uuid.UUID) (*models.ReviewItem, error) {
+	item, err := s.repo.GetReviewItem(id)
+	if err != nil {
+		return nil, reviewLookupError(err)
+	}
+	return item, nil
+}
+
+// LabelReviewItem records a human label for a queued detection. Detections
+// labeled normal are used to retrain the detector from stored results, and
+// those labeled anomalous are left out, whatever the detector judged.
+func (s *AnomalyService) LabelReviewItem(id, labeledBy uuid.UUID, req *models.ReviewLabelRequest) (*models.ReviewItem, error) {
+	item := &models.ReviewItem{
+		ID:        id,
+		Label:     req.Label,
+		Note:      req.Note,
+		LabeledBy: &labeledBy,
+	}
+	if err := s.repo.LabelReviewItem(item); err != nil {
+		return nil, reviewLookupError(err)
+	}
+
+	s.logger.Info("Review item labeled",
+		zap.String("review_id", id.String()),
+		zap.String("label", item.Label),
+		zap.Bool("detector_agreed", item.IsAnomaly == (item.Label == models.LabelAnomalous)),
+	)
+	return item, nil
+}
+
+// reviewLookupError translates repository not-found errors into ErrReviewItemNotFound
+func reviewLookupError(err error) error {
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return ErrReviewItemNotFound
+	}
+	return fmt.Errorf("failed to access review item: %w", err)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
 	"github.com/ruvnet/alienator/internal/models"
 	"github.com/ruvnet/alienator/internal/repository"
 	"go.uber.org/zap"
@@ -30,12 +31,12 @@ func NewUserService(repo repository.Repository, logger *zap.Logger) *UserService
 func (s *UserService) CreateUser(req *models.RegisterRequest, hashedPassword string) (*models.User, error) {
 	// Check if user with email already exists
 	if _, err := s.repo.GetUserByEmail(req.Email); err == nil {
-		return nil, errors.New("user with this email already exists")
+		return nil, fmt.Errorf("user with this email already exists: %w", ErrEmailTaken)
 	}
 
 	// Check if user with username already exists
 	if _, err := s.repo.GetUserByUsername(req.Username); err == nil {
-		return nil, errors.New("user with this username already exists")
+		return nil, fmt.Errorf("user with this username already exists: %w", ErrUsernameTaken)
 	}
 
 	user := &models.User{
@@ -52,7 +53,7 @@ func (s *UserService) CreateUser(req *models.RegisterRequest, hashedPassword str
 
 	if err := s.repo.CreateUser(user); err != nil {
 		s.logger.Error("Failed to create user", zap.Error(err), zap.String("email", req.Email))
-		return nil, fmt.Errorf("failed to create user: %v", err)
+		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	s.logger.Info("User created successfully", 
@@ -69,7 +70,7 @@ func (s *UserService) GetUserByID(id uuid.UUID) (*models.User, error) {
 	user, err := s.repo.GetUserByID(id)
 	if err != nil {
 		s.logger.Debug("User not found", zap.String("user_id", id.String()))
-		return nil, fmt.Errorf("user not found")
+		return nil, userLookupError(err)
 	}
 
 	return user, nil
@@ -80,7 +81,7 @@ func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
 	user, err := s.repo.GetUserByEmail(email)
 	if err != nil {
 		s.logger.Debug("User not found", zap.String("email", email))
-		return nil, fmt.Errorf("user not found")
+		return nil, userLookupError(err)
 	}
 
 	return user, nil
@@ -91,7 +92,7 @@ func (s *UserService) GetUserByUsername(username string) (*models.User, error) {
 	user, err := s.repo.GetUserByUsername(username)
 	if err != nil {
 		s.logger.Debug("User not found", zap.String("username", username))
-		return nil, fmt.Errorf("user not found")
+		return nil, userLookupError(err)
 	}
 
 	return user, nil
@@ -102,32 +103,32 @@ func (s *UserService) UpdateUser(id uuid.UUID, updates *models.UpdateUserRequest
 	// Verify user exists
 	user, err := s.repo.GetUserByID(id)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, userLookupError(err)
 	}
 
 	// Check for email uniqueness if email is being updated
 	if updates.Email != nil && *updates.Email != user.Email {
 		if existingUser, err := s.repo.GetUserByEmail(*updates.Email); err == nil && existingUser.ID != id {
-			return nil, errors.New("email already in use by another user")
+			return nil, ErrEmailTaken
 		}
 	}
 
 	// Check for username uniqueness if username is being updated
 	if updates.Username != nil && *updates.Username != user.Username {
 		if existingUser, err := s.repo.GetUserByUsername(*updates.Username); err == nil && existingUser.ID != id {
-			return nil, errors.New("username already in use by another user")
+			return nil, ErrUsernameTaken
 		}
 	}
 
 	if err := s.repo.UpdateUser(id, updates); err != nil {
 		s.logger.Error("Failed to update user", zap.Error(err), zap.String("user_id", id.String()))
-		return nil, fmt.Errorf("failed to update user: %v", err)
+		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
 	// Fetch updated user
 	updatedUser, err := s.repo.GetUserByID(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch updated user: %v", err)
+		return nil, fmt.Errorf("failed to fetch updated user: %w", err)
 	}
 
 	s.logger.Info("User updated successfully", 
@@ -143,12 +144,12 @@ func (s *UserService) DeleteUser(id uuid.UUID) error {
 	// Verify user exists
 	_, err := s.repo.GetUserByID(id)
 	if err != nil {
-		return fmt.Errorf("user not found")
+		return userLookupError(err)
 	}
 
 	if err := s.repo.DeleteUser(id); err != nil {
 		s.logger.Error("Failed to delete user", zap.Error(err), zap.String("user_id", id.String()))
-		return fmt.Errorf("failed to delete user: %v", err)
+		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
 	s.logger.Info("User deleted successfully", zap.String("user_id", id.String()))
@@ -167,7 +168,7 @@ func (s *UserService) ListUsers(page, limit int) ([]*models.User, *models.Meta,
 	users, total, err := s.repo.ListUsers(page, limit)
 	if err != nil {
 		s.logger.Error("Failed to list users", zap.Error(err))
-		return nil, nil, fmt.Errorf("failed to list users: %v", err)
+		return nil, nil, fmt.Errorf("failed to list users: %w", err)
 	}
 
 	totalPages := (total + limit - 1) / limit
@@ -185,11 +186,11 @@ func (s *UserService) ListUsers(page, limit int) ([]*models.User, *models.Meta,
 func (s *UserService) ValidateUserPermissions(userID uuid.UUID, action string, resourceOwnerID uuid.UUID) error {
 	user, err := s.repo.GetUserByID(userID)
 	if err != nil {
-		return fmt.Errorf("user not found")
+		return userLookupError(err)
 	}
 
 	if !user.IsActive {
-		return errors.New("user account is disabled")
+		return ErrUserDisabled
 	}
 
 	// Admin can do anything
@@ -201,10 +202,10 @@ func (s *UserService) ValidateUserPermissions(userID uuid.UUID, action string, r
 	switch action {
 	case "read_own", "update_own", "delete_own":
 		if userID != resourceOwnerID {
-			return errors.New("insufficient permissions")
+			return ErrPermissionDenied
 		}
 	case "read_all", "update_all", "delete_all", "create_admin":
-		return errors.New("admin access required")
+		return fmt.Errorf("admin access required: %w", apperrors.ErrForbidden)
 	}
 
 	return nil
@@ -217,11 +218,11 @@ func (s *UserService) ChangePassword(userID uuid.UUID, oldPassword, newHashedPas
 		// Get user by ID first to get email
 		userFromID, err := s.repo.GetUserByID(userID)
 		if err != nil {
-			return fmt.Errorf("user not found")
+			return userLookupError(err)
 		}
 		_, err = s.repo.GetUserByEmail(userFromID.Email)
 		if err != nil {
-			return fmt.Errorf("user not found")
+			return userLookupError(err)
 		}
 	}
 
@@ -236,7 +237,7 @@ func (s *UserService) ChangePassword(userID uuid.UUID, oldPassword, newHashedPas
 
 	if err := s.repo.UpdateUser(userID, updates); err != nil {
 		s.logger.Error("Failed to change password", zap.Error(err), zap.String("user_id", userID.String()))
-		return fmt.Errorf("failed to change password: %v", err)
+		return fmt.Errorf("failed to change password: %w", err)
 	}
 
 	s.logger.Info("Password changed successfully", zap.String("user_id", userID.String()))
@@ -247,11 +248,11 @@ func (s *UserService) ChangePassword(userID uuid.UUID, oldPassword, newHashedPas
 func (s *UserService) GetUserStats(userID uuid.UUID) (map[string]interface{}, error) {
 	user, err := s.repo.GetUserByID(userID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, userLookupError(err)
 	}
 
 	// Get user's anomaly data count
-	_, total, err := s.repo.GetAnomalyDataByUserID(userID, 1, 1)
+	_, total, err := s.repo.GetAnomalyDataByUserID(userID, "", 1, 1)
 	if err != nil {
 		s.logger.Warn("Failed to get user anomaly data count", zap.Error(err))
 		total = 0
@@ -269,4 +270,13 @@ func (s *UserService) GetUserStats(userID uuid.UUID) (map[string]interface{}, er
 	}
 
 	return stats, nil
-}
\ No newline at end of file
+}
+
+// userLookupError maps a repository lookup failure to ErrUserNotFound,
+// preserving any other cause
+func userLookupError(err error) error {
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return ErrUserNotFound
+	}
+	return fmt.Errorf("failed to look up user: %w", err)
+}
@@ -0,0 +1,274 @@
+// Package signing makes detection verdicts tamper-evident. A Signer signs the
+// verdict together with a hash of the analyzed input and the signing time, so
+// that a stored or forwarded result can later be shown to be unaltered.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Supported signature algorithms
+const (
+	AlgorithmHMACSHA256 = "hmac-sha256"
+	AlgorithmEd25519    = "ed25519"
+)
+
+// ErrInvalidSignature is returned when a signature does not match its verdict
+var ErrInvalidSignature = errors.New("signature does not match the verdict")
+
+// Verdict is the part of a detection result covered by a signature: the
+// fields stored with every detection, from which it can be verified again.
+// The rest of a DetectionResult is not covered, and can change without
+// invalidating the signature: its ID, assigned when the detection is stored
+// after signing; Confidence, which is derived from the score and threshold;
+// and Profile, Preset, Source, Language and Metadata.
+type Verdict struct {
+	Score     float64
+	IsAnomaly bool
+	Threshold float64
+	Algorithm string
+}
+
+// VerdictOf returns the signed part of a detection result
+func VerdictOf(result *models.DetectionResult) Verdict {
+	return Verdict{
+		Score:     result.Score,
+		IsAnomaly: result.IsAnomaly,
+		Threshold: result.Threshold,
+		Algorithm: result.Algorithm,
+	}
+}
+
+// StoredVerdictOf returns the signed part of a stored detection
+func StoredVerdictOf(data *models.AnomalyData) Verdict {
+	return Verdict{
+		Score:     data.Score,
+		IsAnomaly: data.IsAnomaly,
+		Threshold: data.Threshold,
+		Algorithm: data.Algorithm,
+	}
+}
+
+// Signer signs and verifies verdicts with one key
+type Signer struct {
+	algorithm  string
+	keyID      string
+	secret     []byte
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewSigner creates the signer described by cfg, reading the Ed25519 private
+// key file if one is configured
+func NewSigner(cfg config.SigningConfig) (*Signer, error) {
+	switch cfg.Algorithm {
+	case AlgorithmHMACSHA256:
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("signing secret is required for %s", AlgorithmHMACSHA256)
+		}
+		return NewHMACSigner(cfg.KeyID, []byte(cfg.Secret)), nil
+	case AlgorithmEd25519:
+		key, err := LoadEd25519PrivateKey(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewEd25519Signer(cfg.KeyID, key), nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s (expected %s or %s)", cfg.Algorithm, AlgorithmHMACSHA256, AlgorithmEd25519)
+	}
+}
+
+// NewHMACSigner creates a signer using HMAC-SHA256 with secret. Only holders
+// of the secret can verify its signatures.
+func NewHMACSigner(keyID string, secret []byte) *Signer {
+	return &Signer{algorithm: AlgorithmHMACSHA256, keyID: keyID, secret: secret}
+}
+
+// NewEd25519Signer creates a signer using Ed25519. Anyone with the public key
+// can verify its signatures.
+func NewEd25519Signer(keyID string, key ed25519.PrivateKey) *Signer {
+	return &Signer{
+		algorithm:  AlgorithmEd25519,
+		keyID:      keyID,
+		privateKey: key,
+		publicKey:  key.Public().(ed25519.PublicKey),
+	}
+}
+
+// LoadEd25519PrivateKey reads a PEM-encoded PKCS#8 Ed25519 private key, as
+// written by `openssl genpkey -algorithm ed25519`
+func LoadEd25519PrivateKey(path string) (ed25519.PrivateKey, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(encoded)
+	if block == nil {
+		return nil, fmt.Errorf("signing key file %s contains no PEM block", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key file %s does not hold an Ed25519 key", path)
+	}
+	return ed, nil
+}
+
+// Algorithm returns the signature algorithm
+func (s *Signer) Algorithm() string {
+	return s.algorithm
+}
+
+// KeyID returns the identifier recorded with each signature
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey returns the Ed25519 public key, or nil for HMAC signers
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.publicKey
+}
+
+// Sign signs the verdict reached on data at signedAt
+func (s *Signer) Sign(verdict Verdict, data map[string]interface{}, signedAt time.Time) (*models.Signature, error) {
+	inputHash, err := HashInput(data)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := &models.Signature{
+		Algorithm: s.algorithm,
+		KeyID:     s.keyID,
+		InputHash: inputHash,
+		SignedAt:  signedAt.UTC(),
+	}
+	payload, err := canonicalPayload(verdict, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	if s.algorithm == AlgorithmEd25519 {
+		value = ed25519.Sign(s.privateKey, payload)
+	} else {
+		value = s.mac(payload)
+	}
+	signature.Value = base64.StdEncoding.EncodeToString(value)
+	return signature, nil
+}
+
+// Verify checks that signature was made by this signer over verdict. It
+// returns ErrInvalidSignature if the verdict, input hash or signing time was
+// altered.
+func (s *Signer) Verify(verdict Verdict, signature *models.Signature) error {
+	if signature == nil {
+		return fmt.Errorf("result is not signed")
+	}
+	if signature.Algorithm != s.algorithm {
+		return fmt.Errorf("signature uses %s, this server verifies %s", signature.Algorithm, s.algorithm)
+	}
+	if signature.KeyID != s.keyID {
+		return fmt.Errorf("signature was made with key %q, this server verifies key %q", signature.KeyID, s.keyID)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(signature.Value)
+	if err != nil {
+		return fmt.Errorf("signature value is not valid base64: %w", err)
+	}
+	payload, err := canonicalPayload(verdict, signature)
+	if err != nil {
+		return err
+	}
+
+	var ok bool
+	if s.algorithm == AlgorithmEd25519 {
+		ok = ed25519.Verify(s.publicKey, payload, value)
+	} else {
+		ok = subtle.ConstantTimeCompare(s.mac(payload), value) == 1
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyInput checks that data is the input the signature was made over
+func VerifyInput(data map[string]interface{}, signature *models.Signature) error {
+	inputHash, err := HashInput(data)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(inputHash), []byte(signature.InputHash)) {
+		return fmt.Errorf("data does not match the signed input hash")
+	}
+	return nil
+}
+
+func (s *Signer) mac(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// HashInput returns the hex SHA-256 of data's canonical JSON encoding, in
+// which object keys are sorted
+func HashInput(data map[string]interface{}) (string, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode input for hashing: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// signedPayload is the canonical form that is signed. Scores and thresholds
+// are fixed at 8 decimal places, the precision they are stored with, so that
+// a verdict read back from the database still verifies.
+type signedPayload struct {
+	Version   int    `json:"v"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Score     string `json:"score"`
+	IsAnomaly bool   `json:"is_anomaly"`
+	Threshold string `json:"threshold"`
+	Detector  string `json:"detector"`
+	InputHash string `json:"input_hash"`
+	SignedAt  string `json:"signed_at"`
+}
+
+func canonicalPayload(verdict Verdict, signature *models.Signature) ([]byte, error) {
+	payload, err := json.Marshal(signedPayload{
+		Version:   1,
+		Algorithm: signature.Algorithm,
+		KeyID:     signature.KeyID,
+		Score:     strconv.FormatFloat(verdict.Score, 'f', 8, 64),
+		IsAnomaly: verdict.IsAnomaly,
+		Threshold: strconv.FormatFloat(verdict.Threshold, 'f', 8, 64),
+		Detector:  verdict.Algorithm,
+		InputHash: signature.InputHash,
+		SignedAt:  signature.SignedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signed payload: %w", err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,127 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ruvnet/alienator/internal/config"
+)
+
+// FileSink appends records as JSON lines to a file, rotating it once it
+// reaches its size limit: path becomes path.1, path.1 becomes path.2 and so
+// on, dropping the oldest beyond the configured number of backups
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens the file for appending, creating it if needed
+func NewFileSink(cfg config.FileSinkConfig) (*FileSink, error) {
+	fs := &FileSink{
+		path:       cfg.Path,
+		maxSize:    int64(cfg.MaxSizeMB) * 1024 * 1024,
+		maxBackups: cfg.MaxBackups,
+	}
+	if err := fs.open(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// Name implements ResultSink
+func (fs *FileSink) Name() string {
+	return "file"
+}
+
+// Write implements ResultSink
+func (fs *FileSink) Write(ctx context.Context, record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.file == nil {
+		return fmt.Errorf("file sink %s is closed", fs.path)
+	}
+	if fs.size > 0 && fs.size+int64(len(line)) > fs.maxSize {
+		if err := fs.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := fs.file.Write(line)
+	fs.size += int64(n)
+	return err
+}
+
+// Close implements ResultSink
+func (fs *FileSink) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.file == nil {
+		return nil
+	}
+	err := fs.file.Close()
+	fs.file = nil
+	return err
+}
+
+// open opens the file for appending. Callers must hold fs.mu or own fs.
+func (fs *FileSink) open() error {
+	file, err := os.OpenFile(fs.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open result file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat result file: %w", err)
+	}
+	fs.file = file
+	fs.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups along and starts a new file. Callers must hold fs.mu.
+func (fs *FileSink) rotate() error {
+	if err := fs.file.Close(); err != nil {
+		return fmt.Errorf("failed to close result file: %w", err)
+	}
+	fs.file = nil
+
+	if fs.maxBackups == 0 {
+		if err := os.Remove(fs.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove result file: %w", err)
+		}
+	} else {
+		// Renaming over the oldest backup drops it
+		for i := fs.maxBackups - 1; i >= 1; i-- {
+			if err := os.Rename(fs.backup(i), fs.backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to rotate result file: %w", err)
+			}
+		}
+		if err := os.Rename(fs.path, fs.backup(1)); err != nil {
+			return fmt.Errorf("failed to rotate result file: %w", err)
+		}
+	}
+
+	return fs.open()
+}
+
+// backup returns the path of the nth most recent backup
+func (fs *FileSink) backup(n int) string {
+	return fmt.Sprintf("%s.%d", fs.path, n)
+}
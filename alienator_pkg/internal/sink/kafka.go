@@ -0,0 +1,67 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ruvnet/alienator/internal/config"
+)
+
+// kafkaContentType is the REST proxy's media type for JSON-encoded records
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaSink produces each record to a Kafka topic through a REST proxy
+// speaking the Confluent REST API v2, keyed by the record ID so that results
+// for the same request land on the same partition
+type KafkaSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewKafkaSink creates a Kafka sink
+func NewKafkaSink(cfg config.KafkaSinkConfig) *KafkaSink {
+	return &KafkaSink{
+		endpoint: strings.TrimRight(cfg.RESTURL, "/") + "/topics/" + url.PathEscape(cfg.Topic),
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// kafkaRecord is one record of a REST proxy produce request
+type kafkaRecord struct {
+	Key   string  `json:"key"`
+	Value *Record `json:"value"`
+}
+
+// Name implements ResultSink
+func (ks *KafkaSink) Name() string {
+	return "kafka"
+}
+
+// Write implements ResultSink
+func (ks *KafkaSink) Write(ctx context.Context, record *Record) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: record.ID, Value: record}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ks.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	return send(ks.client, req)
+}
+
+// Close implements ResultSink
+func (ks *KafkaSink) Close() error {
+	ks.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,81 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// notifyBackoff is the delay before the first retry of a rate-limited or
+// failed notification when the service doesn't say how long to wait
+const notifyBackoff = 500 * time.Millisecond
+
+// maxRetryAfter caps how long a Retry-After header can hold up a notification
+const maxRetryAfter = time.Minute
+
+// statusError is returned for responses other than 2xx
+type statusError struct {
+	host   string
+	status int
+	detail string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned %d %s: %s", e.host, e.status, http.StatusText(e.status), e.detail)
+}
+
+// postWithBackoff POSTs a JSON body and returns the response body. Rate
+// limited (429) and server error responses are retried up to maxRetries
+// times, waiting as long as the Retry-After header asks or else doubling
+// the delay each time.
+func postWithBackoff(ctx context.Context, client *http.Client, url string, header http.Header, body []byte, maxRetries int) ([]byte, error) {
+	delay := notifyBackoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		content, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return content, nil
+		}
+		err = &statusError{host: req.URL.Host, status: resp.StatusCode, detail: string(bytes.TrimSpace(content))}
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retryable || attempt >= maxRetries {
+			return nil, err
+		}
+
+		wait := delay
+		if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		if wait > maxRetryAfter {
+			wait = maxRetryAfter
+		}
+		delay *= 2
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ruvnet/alienator/internal/alert"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty event actions
+const (
+	pagerDutyTrigger = "trigger"
+	pagerDutyResolve = "resolve"
+)
+
+// pagerDutyMaxSummary is the longest summary PagerDuty accepts, in bytes
+const pagerDutyMaxSummary = 1024
+
+// PagerDutySink triggers a PagerDuty incident for each result scoring at
+// least the configured minimum. Events are deduplicated by the fingerprint
+// of the input, so repeated detections for the same input update one
+// incident, and with resolve enabled a later result for that input below the
+// minimum resolves it, as long as it arrives within the resolve window.
+type PagerDutySink struct {
+	url        string
+	routingKey string
+	source     string
+	minScore   float64
+	resolve    bool
+	maxRetries int
+	client     *http.Client
+	template   *alert.Template
+
+	// open holds the dedup keys of incidents this sink has triggered, with
+	// when each was last triggered; entries are dropped on resolve or once
+	// older than the resolve window
+	open          map[string]time.Time
+	openMu        sync.Mutex
+	resolveWindow time.Duration
+}
+
+// pagerDutyEvent is an Events API v2 request
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string       `json:"summary"`
+	Source        string       `json:"source"`
+	Severity      string       `json:"severity"`
+	Timestamp     string       `json:"timestamp,omitempty"`
+	Component     string       `json:"component,omitempty"`
+	CustomDetails *alert.Alert `json:"custom_details,omitempty"`
+}
+
+// NewPagerDutySink creates a PagerDuty sink
+func NewPagerDutySink(cfg config.PagerDutySinkConfig) (*PagerDutySink, error) {
+	tmpl, err := alert.NewTemplate(alert.FormatJSON, cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("pagerduty sink: %w", err)
+	}
+
+	url := cfg.URL
+	if url == "" {
+		url = PagerDutyEventsURL
+	}
+	return &PagerDutySink{
+		url:           url,
+		routingKey:    cfg.RoutingKey,
+		source:        cfg.Source,
+		minScore:      cfg.MinScore,
+		resolve:       cfg.Resolve,
+		maxRetries:    cfg.MaxRetries,
+		client:        &http.Client{Timeout: cfg.Timeout},
+		template:      tmpl,
+		open:          make(map[string]time.Time),
+		resolveWindow: cfg.ResolveWindow,
+	}, nil
+}
+
+// Name implements ResultSink
+func (ps *PagerDutySink) Name() string {
+	return "pagerduty"
+}
+
+// DedupKey returns the PagerDuty dedup key used for a record: its input
+// fingerprint, or its ID when it has none
+func DedupKey(record *Record) string {
+	if record.Fingerprint != "" {
+		return "alienator/" + record.Fingerprint
+	}
+	return "alienator/" + record.ID
+}
+
+// Write implements ResultSink. Results below the minimum score are skipped,
+// or resolve the record's incident if this sink triggered one.
+func (ps *PagerDutySink) Write(ctx context.Context, record *Record) error {
+	if record.Result == nil {
+		return nil
+	}
+
+	key := DedupKey(record)
+	if record.Result.Score < ps.minScore {
+		if !ps.resolve || !ps.isOpen(key) {
+			return nil
+		}
+		if err := ps.send(ctx, &pagerDutyEvent{RoutingKey: ps.routingKey, EventAction: pagerDutyResolve, DedupKey: key}); err != nil {
+			return err
+		}
+		ps.setOpen(key, false)
+		return nil
+	}
+
+	a := alert.New(record.ID, record.Result, "", 0)
+	summary, err := ps.template.Message(a)
+	if err != nil {
+		return err
+	}
+	summary = truncateSummary(summary)
+
+	err = ps.send(ctx, &pagerDutyEvent{
+		RoutingKey:  ps.routingKey,
+		EventAction: pagerDutyTrigger,
+		DedupKey:    key,
+		Payload: &pagerDutyPayload{
+			Summary:       summary,
+			Source:        ps.source,
+			Severity:      pagerDutySeverity(record.Result.Severity),
+			Timestamp:     a.Timestamp.UTC().Format(time.RFC3339),
+			Component:     record.Source,
+			CustomDetails: a,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if ps.resolve {
+		ps.setOpen(key, true)
+	}
+	return nil
+}
+
+// send enqueues one event
+func (ps *PagerDutySink) send(ctx context.Context, event *pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = postWithBackoff(ctx, ps.client, ps.url, nil, body, ps.maxRetries)
+	return err
+}
+
+func (ps *PagerDutySink) isOpen(key string) bool {
+	ps.openMu.Lock()
+	defer ps.openMu.Unlock()
+	triggered, ok := ps.open[key]
+	return ok && time.Since(triggered) < ps.resolveWindow
+}
+
+func (ps *PagerDutySink) setOpen(key string, open bool) {
+	ps.openMu.Lock()
+	defer ps.openMu.Unlock()
+	if !open {
+		delete(ps.open, key)
+		return
+	}
+	now := time.Now()
+	ps.open[key] = now
+	for other, triggered := range ps.open {
+		if now.Sub(triggered) >= ps.resolveWindow {
+			delete(ps.open, other)
+		}
+	}
+}
+
+// OpenIncidents returns how many triggered incidents the sink would resolve
+func (ps *PagerDutySink) OpenIncidents() int {
+	ps.openMu.Lock()
+	defer ps.openMu.Unlock()
+	return len(ps.open)
+}
+
+// truncateSummary cuts a summary to the length PagerDuty accepts without
+// splitting a character
+func truncateSummary(summary string) string {
+	if len(summary) <= pagerDutyMaxSummary {
+		return summary
+	}
+	end := pagerDutyMaxSummary
+	for end > 0 && !utf8.RuneStart(summary[end]) {
+		end--
+	}
+	return summary[:end]
+}
+
+// pagerDutySeverity maps a result severity onto PagerDuty's levels
+func pagerDutySeverity(severity models.Severity) string {
+	switch severity {
+	case models.SeverityCritical:
+		return "critical"
+	case models.SeverityHigh:
+		return "error"
+	case models.SeverityMedium:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// Close implements ResultSink
+func (ps *PagerDutySink) Close() error {
+	ps.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package sink routes detection results to downstream systems: standard
+// output, rotating JSONL files, webhooks, Kafka, Slack and PagerDuty
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/hashing"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// Record is a detection result as written to a sink
+type Record struct {
+	ID        string                `json:"id"`
+	Source    string                `json:"source"` // component that produced the result, e.g. cli or worker
+	Timestamp time.Time             `json:"timestamp"`
+	Result    *models.AnomalyResult `json:"result"`
+	// Fingerprint is the content hash of the analyzed input, shared by every
+	// result for the same input
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// NewRecord wraps a result for the sinks, stamped with the current time
+func NewRecord(id, source string, result *models.AnomalyResult) *Record {
+	return &Record{
+		ID:        id,
+		Source:    source,
+		Timestamp: time.Now().UTC(),
+		Result:    result,
+	}
+}
+
+// WithInput fingerprints the record by the input it was analyzed from
+func (r *Record) WithInput(text string) *Record {
+	r.Fingerprint = hashing.ContentHash(text)
+	return r
+}
+
+// ResultSink delivers detection results to a downstream system. Write must
+// be safe for concurrent use.
+type ResultSink interface {
+	// Name identifies the sink in logs and errors
+	Name() string
+	// Write delivers one record
+	Write(ctx context.Context, record *Record) error
+	// Close flushes and releases the sink's resources
+	Close() error
+}
+
+// Multi writes each record to every one of its sinks
+type Multi struct {
+	sinks []ResultSink
+}
+
+// NewMulti creates a sink fanning out to sinks
+func NewMulti(sinks ...ResultSink) *Multi {
+	return &Multi{sinks: sinks}
+}
+
+// New creates the sinks enabled in cfg. With none enabled the returned sink
+// discards every record.
+func New(cfg config.SinksConfig) (*Multi, error) {
+	multi := NewMulti()
+	if cfg.Stdout.Enabled {
+		multi.sinks = append(multi.sinks, NewStdoutSink())
+	}
+	if cfg.File.Enabled {
+		file, err := NewFileSink(cfg.File)
+		if err != nil {
+			multi.Close()
+			return nil, err
+		}
+		multi.sinks = append(multi.sinks, file)
+	}
+	if cfg.Webhook.Enabled {
+		multi.sinks = append(multi.sinks, NewWebhookSink(cfg.Webhook))
+	}
+	if cfg.Kafka.Enabled {
+		multi.sinks = append(multi.sinks, NewKafkaSink(cfg.Kafka))
+	}
+	if cfg.Slack.Enabled {
+		slack, err := NewSlackSink(cfg.Slack)
+		if err != nil {
+			multi.Close()
+			return nil, err
+		}
+		multi.sinks = append(multi.sinks, slack)
+	}
+	if cfg.PagerDuty.Enabled {
+		pagerDuty, err := NewPagerDutySink(cfg.PagerDuty)
+		if err != nil {
+			multi.Close()
+			return nil, err
+		}
+		multi.sinks = append(multi.sinks, pagerDuty)
+	}
+	return multi, nil
+}
+
+// Name implements ResultSink
+func (m *Multi) Name() string {
+	return "multi"
+}
+
+// Len returns the number of sinks written to
+func (m *Multi) Len() int {
+	return len(m.sinks)
+}
+
+// Write writes the record to every sink, even when earlier ones fail, and
+// returns their errors joined
+func (m *Multi) Write(ctx context.Context, record *Record) error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.Write(ctx, record); err != nil {
+			errs = append(errs, fmt.Errorf("%s sink: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink
+func (m *Multi) Close() error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s sink: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
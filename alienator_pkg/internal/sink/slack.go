@@ -0,0 +1,107 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ruvnet/alienator/internal/alert"
+	"github.com/ruvnet/alienator/internal/config"
+)
+
+// SlackAPIURL is the Web API method used when the Slack sink has a bot token
+const SlackAPIURL = "https://slack.com/api/chat.postMessage"
+
+// SlackSink posts a Block Kit alert for each result scoring at least the
+// configured minimum, either to an incoming webhook or, with a bot token, to
+// a channel through chat.postMessage
+type SlackSink struct {
+	url        string
+	token      string
+	channel    string
+	template   *alert.Template
+	minScore   float64
+	maxRetries int
+	client     *http.Client
+}
+
+// NewSlackSink creates a Slack sink
+func NewSlackSink(cfg config.SlackSinkConfig) (*SlackSink, error) {
+	tmpl, err := alert.NewTemplate(alert.FormatSlack, cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("slack sink: %w", err)
+	}
+
+	s := &SlackSink{
+		url:        cfg.WebhookURL,
+		token:      cfg.Token,
+		channel:    cfg.Channel,
+		template:   tmpl,
+		minScore:   cfg.MinScore,
+		maxRetries: cfg.MaxRetries,
+		client:     &http.Client{Timeout: cfg.Timeout},
+	}
+	if s.token != "" {
+		s.url = cfg.APIURL
+		if s.url == "" {
+			s.url = SlackAPIURL
+		}
+	}
+	return s, nil
+}
+
+// Name implements ResultSink
+func (ss *SlackSink) Name() string {
+	return "slack"
+}
+
+// Write implements ResultSink. Results below the minimum score are skipped.
+func (ss *SlackSink) Write(ctx context.Context, record *Record) error {
+	if record.Result == nil || record.Result.Score < ss.minScore {
+		return nil
+	}
+
+	payload, err := ss.template.Render(alert.New(record.ID, record.Result, "", 0))
+	if err != nil {
+		return err
+	}
+
+	header := make(http.Header)
+	if ss.token != "" {
+		// chat.postMessage takes the same blocks plus the target channel
+		var message map[string]interface{}
+		if err := json.Unmarshal(payload, &message); err != nil {
+			return err
+		}
+		message["channel"] = ss.channel
+		if payload, err = json.Marshal(message); err != nil {
+			return err
+		}
+		header.Set("Authorization", "Bearer "+ss.token)
+	}
+
+	body, err := postWithBackoff(ctx, ss.client, ss.url, header, payload, ss.maxRetries)
+	if err != nil || ss.token == "" {
+		return err
+	}
+
+	// The Web API answers 200 even when it rejects a message
+	var response struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("unexpected chat.postMessage response: %w", err)
+	}
+	if !response.OK {
+		return fmt.Errorf("chat.postMessage failed: %s", response.Error)
+	}
+	return nil
+}
+
+// Close implements ResultSink
+func (ss *SlackSink) Close() error {
+	ss.client.CloseIdleConnections()
+	return nil
+}
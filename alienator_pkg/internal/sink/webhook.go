@@ -0,0 +1,83 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ruvnet/alienator/internal/config"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of a webhook body, hex encoded and
+// prefixed with "sha256=", when the webhook sink has a secret
+const SignatureHeader = "X-Alienator-Signature"
+
+// WebhookSink POSTs each record as JSON to a URL
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookSink creates a webhook sink
+func NewWebhookSink(cfg config.WebhookSinkConfig) *WebhookSink {
+	return &WebhookSink{
+		url:    cfg.URL,
+		secret: []byte(cfg.Secret),
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Name implements ResultSink
+func (ws *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Write implements ResultSink. Any response other than 2xx is an error.
+func (ws *WebhookSink) Write(ctx context.Context, record *Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ws.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(ws.secret) > 0 {
+		mac := hmac.New(sha256.New, ws.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	return send(ws.client, req)
+}
+
+// Close implements ResultSink
+func (ws *WebhookSink) Close() error {
+	ws.client.CloseIdleConnections()
+	return nil
+}
+
+// send performs the request, treating any response other than 2xx as an error
+func send(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, bytes.TrimSpace(detail))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
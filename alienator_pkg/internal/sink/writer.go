@@ -0,0 +1,49 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterSink writes each record as a JSON line to an io.Writer
+type WriterSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+// NewWriterSink creates a sink writing JSON lines to w
+func NewWriterSink(name string, w io.Writer) *WriterSink {
+	return &WriterSink{name: name, w: w}
+}
+
+// NewStdoutSink creates a sink writing JSON lines to standard output
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink("stdout", os.Stdout)
+}
+
+// Name implements ResultSink
+func (ws *WriterSink) Name() string {
+	return ws.name
+}
+
+// Write implements ResultSink
+func (ws *WriterSink) Write(ctx context.Context, record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	_, err = ws.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close implements ResultSink. The writer is left open for its owner.
+func (ws *WriterSink) Close() error {
+	return nil
+}
@@ -0,0 +1,241 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/abadojack/whatlanggo"
+)
+
+// Word splitting modes
+const (
+	// WordsAuto segments the words of texts the language detector finds
+	// written in a script without spaces between words
+	WordsAuto = "auto"
+	// WordsWhitespace splits words on whitespace only, whatever the script
+	WordsWhitespace = "whitespace"
+)
+
+// NaiveWordsPenalty scales the confidence of analyzers built on words when
+// Tokens.NaiveWords reports the words were split on whitespace alone
+const NaiveWordsPenalty = 0.5
+
+// unsegmentedScripts are the scripts written without spaces between words
+var unsegmentedScripts = []*unicode.RangeTable{
+	unicode.Han, unicode.Hiragana, unicode.Katakana,
+	unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar,
+}
+
+// Unsegmented reports whether the language detector finds text written
+// mostly in a script without spaces between words: Chinese, Japanese, Thai,
+// Lao, Khmer or Burmese
+func Unsegmented(text string) bool {
+	script := whatlanggo.DetectScript(text)
+	for _, unsegmented := range unsegmentedScripts {
+		if script == unsegmented {
+			return true
+		}
+	}
+	return false
+}
+
+// Selector is implemented by tokenizers that pick the tokenizer for each
+// text, e.g. by its script. New tokenizes with the one picked.
+type Selector interface {
+	Tokenizer
+	Select(text string) Tokenizer
+}
+
+// LanguageAwareTokenizer tokenizes with a ScriptTokenizer over Base the
+// texts the language detector finds written in a script without spaces
+// between words, and with Base the others
+type LanguageAwareTokenizer struct {
+	Base Tokenizer
+}
+
+// Select implements Selector
+func (t LanguageAwareTokenizer) Select(text string) Tokenizer {
+	if Unsegmented(text) {
+		return ScriptTokenizer{Base: t.Base}
+	}
+	return t.base()
+}
+
+// Words implements Tokenizer
+func (t LanguageAwareTokenizer) Words(text string) []string {
+	return t.Select(text).Words(text)
+}
+
+// Sentences implements Tokenizer
+func (t LanguageAwareTokenizer) Sentences(text string) []string {
+	return t.Select(text).Sentences(text)
+}
+
+// Characters implements Tokenizer
+func (t LanguageAwareTokenizer) Characters(text string) []rune {
+	return t.base().Characters(text)
+}
+
+func (t LanguageAwareTokenizer) base() Tokenizer {
+	if t.Base == nil {
+		return DefaultTokenizer{}
+	}
+	return t.Base
+}
+
+// ScriptTokenizer splits the words of Base further where they run together
+// in a script without spaces between words. Without a dictionary the
+// segments approximate words: each Han character, each run of hiragana or of
+// katakana, and each character cluster of Thai, Lao, Khmer or Burmese is a
+// word, and runs of other letters or digits stay whole. Punctuation stays
+// attached as Base leaves it. Sentences also end at the full-width
+// terminators '。', '！' and '？'.
+type ScriptTokenizer struct {
+	// Base splits words on whitespace and sentences first; nil uses
+	// DefaultTokenizer
+	Base Tokenizer
+}
+
+// Words implements Tokenizer
+func (t ScriptTokenizer) Words(text string) []string {
+	fields := t.base().Words(text)
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		words = appendSegments(words, field)
+	}
+	return words
+}
+
+// Sentences implements Tokenizer
+func (t ScriptTokenizer) Sentences(text string) []string {
+	var sentences []string
+	for _, sentence := range t.base().Sentences(text) {
+		start := 0
+		runes := []rune(sentence)
+		for i := 0; i < len(runes); i++ {
+			if !isFullWidthTerminator(runes[i]) {
+				continue
+			}
+			end := i + 1
+			for end < len(runes) && (isFullWidthTerminator(runes[end]) || isCloser(runes[end]) || isFullWidthCloser(runes[end])) {
+				end++
+			}
+			if s := trimSentence(runes[start:end]); s != "" {
+				sentences = append(sentences, strings.TrimRightFunc(s, isFullWidthTerminator))
+			}
+			start, i = end, end-1
+		}
+		if s := trimSentence(runes[start:]); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+	return sentences
+}
+
+// Characters implements Tokenizer
+func (t ScriptTokenizer) Characters(text string) []rune {
+	return t.base().Characters(text)
+}
+
+func (t ScriptTokenizer) base() Tokenizer {
+	if t.Base == nil {
+		return DefaultTokenizer{}
+	}
+	return t.Base
+}
+
+// segmentKind classes the runes of a word by how they are segmented
+type segmentKind int
+
+const (
+	segmentOther segmentKind = iota
+	segmentPunct
+	segmentMark
+	segmentHan
+	segmentHiragana
+	segmentKatakana
+	segmentCluster
+)
+
+func kindOf(r rune) segmentKind {
+	switch {
+	case unicode.Is(unicode.Han, r):
+		return segmentHan
+	case unicode.Is(unicode.Hiragana, r):
+		return segmentHiragana
+	case unicode.Is(unicode.Katakana, r) || r == 'ー':
+		return segmentKatakana
+	case unicode.In(r, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar) && !unicode.IsMark(r):
+		return segmentCluster
+	case unicode.IsMark(r):
+		return segmentMark
+	case unicode.IsLetter(r) || unicode.IsNumber(r):
+		return segmentOther
+	default:
+		return segmentPunct
+	}
+}
+
+// appendSegments appends the segments of one whitespace-separated field to
+// words. Punctuation ends the segment before it, joining it, except that
+// opening brackets and quotes, like punctuation leading the field, join the
+// segment after them. Marks join the character they follow.
+func appendSegments(words []string, field string) []string {
+	var current strings.Builder
+	var prefix string
+	kind, punctuated := segmentPunct, false
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, prefix+current.String())
+			prefix, punctuated = "", false
+			current.Reset()
+		}
+	}
+	for _, r := range field {
+		next := kindOf(r)
+		switch {
+		case next == segmentPunct && (isOpener(r) || isFullWidthOpener(r)):
+			flush()
+			prefix += string(r)
+			continue
+		case next == segmentPunct || next == segmentMark:
+			if current.Len() == 0 {
+				prefix += string(r)
+			} else {
+				current.WriteRune(r)
+				punctuated = punctuated || next == segmentPunct
+			}
+			continue
+		case current.Len() == 0:
+		case next == segmentHan || next == segmentCluster || next != kind || (punctuated && kind != segmentOther):
+			flush()
+		}
+		kind = next
+		current.WriteRune(r)
+	}
+	flush()
+	if prefix != "" {
+		words = append(words, prefix)
+	}
+	return words
+}
+
+func isFullWidthTerminator(r rune) bool {
+	return r == '。' || r == '！' || r == '？' || r == '｡'
+}
+
+func isFullWidthOpener(r rune) bool {
+	switch r {
+	case '「', '『', '（', '【', '〈', '《':
+		return true
+	}
+	return false
+}
+
+func isFullWidthCloser(r rune) bool {
+	switch r {
+	case '」', '』', '）', '】', '〉', '》':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package tokenizer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Sentence segmentation modes
+const (
+	// SegmentRules splits at terminal punctuation unless it belongs to an
+	// abbreviation, initial, decimal or mid-sentence ellipsis
+	SegmentRules = "rules"
+	// SegmentPunctuation splits at every run of terminal punctuation, as
+	// tokenizers did before rule-based segmentation
+	SegmentPunctuation = "punctuation"
+)
+
+// DefaultAbbreviations are words whose trailing period never ends a
+// sentence: titles that precede a name and Latin shorthand that is followed
+// by more of the sentence. Abbreviations that often end a sentence, such as
+// "etc." and "Inc.", are deliberately left out.
+var DefaultAbbreviations = []string{
+	"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st", "mt", "rev", "hon",
+	"gen", "col", "capt", "lt", "sgt", "gov", "sen", "rep", "pres",
+	"vs", "e.g", "i.e", "cf", "viz", "al", "approx", "fig", "figs", "vol", "pp",
+}
+
+// Segmenter splits text into sentences. Implementations must be safe for
+// concurrent use.
+type Segmenter interface {
+	// Sentences returns the non-empty, trimmed sentences of text
+	Sentences(text string) []string
+}
+
+// sentenceBoundary matches the punctuation that ends a sentence
+var sentenceBoundary = regexp.MustCompile(`[.!?]+`)
+
+// PunctuationSegmenter splits at every run of terminal punctuation
+type PunctuationSegmenter struct{}
+
+// Sentences implements Segmenter
+func (PunctuationSegmenter) Sentences(text string) []string {
+	parts := sentenceBoundary.Split(text, -1)
+	sentences := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if sentence := strings.TrimSpace(part); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+	return sentences
+}
+
+// RuleSegmenter splits text into sentences with the rules of SegmentRules
+type RuleSegmenter struct {
+	abbreviations map[string]bool
+}
+
+// NewRuleSegmenter creates a segmenter that knows DefaultAbbreviations and
+// the extra ones given, case-insensitively and with or without trailing
+// periods
+func NewRuleSegmenter(extra ...string) *RuleSegmenter {
+	s := &RuleSegmenter{abbreviations: make(map[string]bool, len(DefaultAbbreviations)+len(extra))}
+	for _, list := range [][]string{DefaultAbbreviations, extra} {
+		for _, abbreviation := range list {
+			if abbreviation = strings.ToLower(strings.TrimRight(strings.TrimSpace(abbreviation), ".")); abbreviation != "" {
+				s.abbreviations[abbreviation] = true
+			}
+		}
+	}
+	return s
+}
+
+// defaultSegmenter is used by a DefaultTokenizer without a Segmenter
+var defaultSegmenter = NewRuleSegmenter()
+
+// Sentences implements Segmenter, returning sentences without the
+// punctuation that ends them. A sentence ends at a run of '.', '!', '?' or
+// '…', with any closing quotes or brackets, followed by whitespace or the end
+// of the text, except:
+//   - a period after a known abbreviation ("Dr.") or a capital initial
+//   - a period after a dotted acronym ("U.S.") or an ellipsis, unless the
+//     next word is capitalized
+//   - punctuation inside closing quotes followed by a lowercase word, as in
+//     "Stop!" she said
+//
+// Punctuation not followed by whitespace, as in "3.14" or "example.com",
+// never ends a sentence.
+func (s *RuleSegmenter) Sentences(text string) []string {
+	runes := []rune(text)
+	sentences := make([]string, 0)
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		if !isTerminator(runes[i]) {
+			continue
+		}
+
+		// The terminator run, then any closing quotes and brackets
+		end := i
+		for end < len(runes) && isTerminator(runes[end]) {
+			end++
+		}
+		runEnd := end
+		for end < len(runes) && isCloser(runes[end]) {
+			end++
+		}
+		if end < len(runes) && !unicode.IsSpace(runes[end]) {
+			i = end - 1
+			continue
+		}
+
+		if s.isBoundary(runes, start, i, runEnd, end) {
+			if sentence := trimSentence(runes[start:end]); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			start = end
+		}
+		i = end - 1
+	}
+
+	if sentence := trimSentence(runes[start:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// isBoundary decides whether the terminators at runes[from:to], followed by
+// closers up to end, end the sentence that began at start
+func (s *RuleSegmenter) isBoundary(runes []rune, start, from, to, end int) bool {
+	next := nextWordStart(runes, end)
+	if next < 0 {
+		return true
+	}
+	capitalized := unicode.IsUpper(next) || unicode.IsDigit(next) || isOpener(next)
+
+	if end > to && !capitalized {
+		return false
+	}
+
+	terminators := runes[from:to]
+	if len(terminators) > 1 || terminators[0] == '…' {
+		// An ellipsis ("...", "…") trails off unless a new sentence follows
+		allPeriods := true
+		for _, r := range terminators {
+			if r != '.' && r != '…' {
+				allPeriods = false
+			}
+		}
+		return !allPeriods || capitalized
+	}
+	if terminators[0] != '.' {
+		return true
+	}
+
+	word := precedingWord(runes, start, from)
+	switch {
+	case word == "":
+		return true
+	case s.abbreviations[strings.ToLower(word)]:
+		return false
+	case len([]rune(word)) == 1 && unicode.IsUpper([]rune(word)[0]):
+		// An initial, as in "J. R. R. Tolkien"
+		return false
+	case strings.Contains(word, "."):
+		// A dotted acronym, as in "the U.S. government"
+		return capitalized
+	}
+	return true
+}
+
+// precedingWord returns the word before the period at runes[at], without any
+// opening quotes or brackets
+func precedingWord(runes []rune, start, at int) string {
+	begin := at
+	for begin > start && !unicode.IsSpace(runes[begin-1]) {
+		begin--
+	}
+	for begin < at && isOpener(runes[begin]) {
+		begin++
+	}
+	return string(runes[begin:at])
+}
+
+// nextWordStart returns the first non-space rune from i, or -1 at the end
+func nextWordStart(runes []rune, i int) rune {
+	for ; i < len(runes); i++ {
+		if !unicode.IsSpace(runes[i]) {
+			return runes[i]
+		}
+	}
+	return -1
+}
+
+// trimSentence trims whitespace and the terminal punctuation ending the
+// sentence, keeping punctuation inside closing quotes. Fragments without a
+// letter or digit are not sentences and come back empty.
+func trimSentence(runes []rune) string {
+	sentence := strings.TrimSpace(strings.TrimRightFunc(strings.TrimSpace(string(runes)), isTerminator))
+	if strings.IndexFunc(sentence, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return ""
+	}
+	return sentence
+}
+
+func isTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '}', '”', '’', '»':
+		return true
+	}
+	return false
+}
+
+func isOpener(r rune) bool {
+	switch r {
+	case '"', '\'', '(', '[', '{', '“', '‘', '«':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+// Package tokenizer provides the text tokenization shared by analyzers
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/language"
+)
+
+// Tokenizer splits text into tokens. Implementations must be safe for
+// concurrent use.
+type Tokenizer interface {
+	// Words returns the words of text, keeping attached punctuation so
+	// analyzers can decide how to clean them
+	Words(text string) []string
+	// Sentences returns the non-empty, trimmed sentences of text
+	Sentences(text string) []string
+	// Characters returns the characters of text
+	Characters(text string) []rune
+}
+
+// DefaultTokenizer splits words on whitespace and sentences on terminal
+// punctuation, which suits English and most other space-delimited languages
+type DefaultTokenizer struct {
+	// Segmenter splits sentences; nil uses a RuleSegmenter with the default
+	// abbreviations
+	Segmenter Segmenter
+}
+
+// Default is the tokenizer used unless another one is injected
+var Default Tokenizer = DefaultTokenizer{}
+
+// FromConfig creates the default tokenizer with the configured sentence
+// segmentation, segmenting the words of scripts without spaces between them
+// unless words are split on whitespace only. Words left empty means auto.
+func FromConfig(cfg config.SegmentationConfig) (Tokenizer, error) {
+	var base DefaultTokenizer
+	switch cfg.Mode {
+	case SegmentRules:
+		base = DefaultTokenizer{Segmenter: NewRuleSegmenter(cfg.Abbreviations...)}
+	case SegmentPunctuation:
+		base = DefaultTokenizer{Segmenter: PunctuationSegmenter{}}
+	default:
+		return nil, fmt.Errorf("unsupported sentence segmentation %q (expected %s or %s)", cfg.Mode, SegmentRules, SegmentPunctuation)
+	}
+
+	switch cfg.Words {
+	case WordsAuto, "":
+		return LanguageAwareTokenizer{Base: base}, nil
+	case WordsWhitespace:
+		return base, nil
+	default:
+		return nil, fmt.Errorf("unsupported word splitting %q (expected %s or %s)", cfg.Words, WordsAuto, WordsWhitespace)
+	}
+}
+
+// Words implements Tokenizer
+func (DefaultTokenizer) Words(text string) []string {
+	return strings.Fields(text)
+}
+
+// Sentences implements Tokenizer
+func (t DefaultTokenizer) Sentences(text string) []string {
+	if t.Segmenter == nil {
+		return defaultSegmenter.Sentences(text)
+	}
+	return t.Segmenter.Sentences(text)
+}
+
+// Characters implements Tokenizer
+func (DefaultTokenizer) Characters(text string) []rune {
+	return []rune(text)
+}
+
+// Tokens holds the tokenization of one text. Each kind of token is computed
+// on first use and then shared, so analyzers running in parallel on the same
+// Tokens split the text at most once.
+type Tokens struct {
+	text      string
+	tokenizer Tokenizer
+
+	wordsOnce      sync.Once
+	words          []string
+	lowerWordsOnce sync.Once
+	lowerWords     []string
+	sentencesOnce  sync.Once
+	sentences      []string
+	sentWordsOnce  sync.Once
+	sentWords      [][]string
+	cleanWordsOnce sync.Once
+	cleanWords     [][]string
+	normalOnce     sync.Once
+	normalWords    []string
+	normalOriginal []string
+	charsOnce      sync.Once
+	chars          []rune
+	naiveOnce      sync.Once
+	naive          bool
+	languageOnce   sync.Once
+	language       *language.Detection
+}
+
+// New prepares text for tokenization with t, falling back to Default when t
+// is nil. A Selector picks the tokenizer for text once, here.
+func New(t Tokenizer, text string) *Tokens {
+	if t == nil {
+		t = Default
+	}
+	if s, ok := t.(Selector); ok {
+		t = s.Select(text)
+	}
+	return &Tokens{text: text, tokenizer: t}
+}
+
+// Text returns the original text
+func (t *Tokens) Text() string {
+	return t.text
+}
+
+// Words returns the words of the text. Callers must not modify the slice.
+func (t *Tokens) Words() []string {
+	t.wordsOnce.Do(func() {
+		t.words = t.tokenizer.Words(t.text)
+	})
+	return t.words
+}
+
+// LowerWords returns the words of the text in lower case. Callers must not
+// modify the slice.
+func (t *Tokens) LowerWords() []string {
+	t.lowerWordsOnce.Do(func() {
+		words := t.Words()
+		t.lowerWords = make([]string, len(words))
+		for i, word := range words {
+			t.lowerWords[i] = strings.ToLower(word)
+		}
+	})
+	return t.lowerWords
+}
+
+// NormalizedWords returns the lower-cased words stripped of surrounding
+// punctuation, for comparison, alongside the original words they came from.
+// Words that are only punctuation are dropped from both. Callers must not
+// modify the slices.
+func (t *Tokens) NormalizedWords() (words, original []string) {
+	t.normalOnce.Do(func() {
+		lower := t.LowerWords()
+		t.normalWords = make([]string, 0, len(lower))
+		t.normalOriginal = make([]string, 0, len(lower))
+		for i, word := range lower {
+			word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+			if word != "" {
+				t.normalWords = append(t.normalWords, word)
+				t.normalOriginal = append(t.normalOriginal, t.Words()[i])
+			}
+		}
+	})
+	return t.normalWords, t.normalOriginal
+}
+
+// Sentences returns the sentences of the text. Callers must not modify the slice.
+func (t *Tokens) Sentences() []string {
+	t.sentencesOnce.Do(func() {
+		t.sentences = t.tokenizer.Sentences(t.text)
+	})
+	return t.sentences
+}
+
+// SentenceWords returns the words of each sentence, in the same order as
+// Sentences. Callers must not modify the slices.
+func (t *Tokens) SentenceWords() [][]string {
+	t.sentWordsOnce.Do(func() {
+		sentences := t.Sentences()
+		t.sentWords = make([][]string, len(sentences))
+		for i, sentence := range sentences {
+			t.sentWords[i] = t.tokenizer.Words(sentence)
+		}
+	})
+	return t.sentWords
+}
+
+// CleanSentenceWords returns the words of each sentence cleaned by
+// CleanWords, in the same order as Sentences. Callers must not modify the
+// slices.
+func (t *Tokens) CleanSentenceWords() [][]string {
+	t.cleanWordsOnce.Do(func() {
+		sentences := t.SentenceWords()
+		t.cleanWords = make([][]string, len(sentences))
+		for i, words := range sentences {
+			t.cleanWords[i] = CleanWords(words)
+		}
+	})
+	return t.cleanWords
+}
+
+// CleanWords lower-cases words and strips the punctuation around them,
+// keeping inner apostrophes so that contractions match word lists, and
+// drops the words left empty
+func CleanWords(words []string) []string {
+	cleaned := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.ReplaceAll(strings.ToLower(word), "’", "'")
+		word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if word != "" {
+			cleaned = append(cleaned, word)
+		}
+	}
+	return cleaned
+}
+
+// Characters returns the characters of the text. Callers must not modify the slice.
+func (t *Tokens) Characters() []rune {
+	t.charsOnce.Do(func() {
+		t.chars = t.tokenizer.Characters(t.text)
+	})
+	return t.chars
+}
+
+// NaiveWords reports whether the text is written mostly in a script without
+// spaces between words, yet its words were split on whitespace alone, so
+// that word-based features mean little
+func (t *Tokens) NaiveWords() bool {
+	t.naiveOnce.Do(func() {
+		_, segmented := t.tokenizer.(ScriptTokenizer)
+		t.naive = !segmented && Unsegmented(t.text)
+	})
+	return t.naive
+}
+
+// Language returns the language detected in the text, detecting it on the
+// first call. Callers must not modify the detection.
+func (t *Tokens) Language() *language.Detection {
+	t.languageOnce.Do(func() {
+		detection := language.Detect(t.text)
+		t.language = &detection
+	})
+	return t.language
+}
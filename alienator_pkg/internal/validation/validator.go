@@ -49,13 +49,24 @@ func (v *Validator) ValidateStruct(s interface{}) error {
 	}
 
 	// Convert validation errors to our format
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
 	var validationErrors []dto.ValidationError
-	for _, err := range err.(validator.ValidationErrors) {
-		validationErrors = append(validationErrors, dto.ValidationError{
+	for _, err := range fieldErrors {
+		validationError := dto.ValidationError{
 			Field:   err.Field(),
+			Rule:    err.Tag(),
 			Message: getErrorMessage(err),
 			Value:   err.Value(),
-		})
+		}
+		// Never echo secrets back to the client
+		if strings.Contains(strings.ToLower(err.Field()), "password") {
+			validationError.Value = nil
+		}
+		validationErrors = append(validationErrors, validationError)
 	}
 
 	return &ValidationError{
@@ -99,6 +110,16 @@ func getErrorMessage(fe validator.FieldError) string {
 		return fmt.Sprintf("Maximum length is %s", fe.Param())
 	case "len":
 		return fmt.Sprintf("Length must be %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("Must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("Must be greater than or equal to %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("Must be less than %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("Must be less than or equal to %s", fe.Param())
+	case "alphanum_underscore":
+		return "Only letters, digits and underscores are allowed"
 	case "alphanum":
 		return "Only alphanumeric characters are allowed"
 	case "oneof":
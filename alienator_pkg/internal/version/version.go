@@ -0,0 +1,43 @@
+// Package version exposes build metadata injected at link time
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Build metadata, set via ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/ruvnet/alienator/internal/version.Version=v1.2.0 \
+//	  -X github.com/ruvnet/alienator/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/ruvnet/alienator/internal/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the build metadata of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+}
+
+// String returns a single-line human readable version description
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s %s)", i.Version, i.Commit, i.BuildDate, i.GoVersion, i.Platform)
+}
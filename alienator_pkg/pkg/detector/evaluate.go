@@ -0,0 +1,126 @@
+package detector
+
+import (
+	"math"
+
+	"github.com/ruvnet/alienator/pkg/models"
+)
+
+// DefaultEvaluationThreshold is the score above which a prediction is counted
+// as positive. It matches the anomaly threshold used by the core detector.
+const DefaultEvaluationThreshold = 0.7
+
+// DefaultCalibrationBins is the number of equal-width bins in the reliability curve
+const DefaultCalibrationBins = 10
+
+// Metrics holds classification quality metrics for a scored, labelled
+// dataset: the model metrics of its confusion matrix, with the MCC and the
+// calibration of the scores
+type Metrics struct {
+	models.ModelMetrics
+	Threshold float64 `json:"threshold"`
+	// MCC is the Matthews correlation coefficient in [-1, 1]. Unlike accuracy
+	// and F1 it stays informative when the classes are heavily imbalanced.
+	MCC float64 `json:"mcc"`
+	// Calibration is the reliability curve: for each score bin, the mean
+	// predicted score against the observed fraction of positives.
+	Calibration []CalibrationBin `json:"calibration"`
+	// ExpectedCalibrationError is the sample-weighted mean gap between
+	// predicted score and observed positive rate across bins.
+	ExpectedCalibrationError float64 `json:"expected_calibration_error"`
+}
+
+// CalibrationBin is a single point on the reliability curve
+type CalibrationBin struct {
+	Lower         float64 `json:"lower"`
+	Upper         float64 `json:"upper"`
+	Count         int     `json:"count"`
+	MeanPredicted float64 `json:"mean_predicted"`
+	FractionTrue  float64 `json:"fraction_true"`
+}
+
+// Evaluate scores predictions against ground-truth labels using the default
+// threshold and calibration bins. Predictions are scores in [0, 1] where higher
+// means more likely positive; labels are true for positive samples. Extra
+// entries in the longer slice are ignored.
+func Evaluate(predictions []float64, labels []bool) Metrics {
+	return EvaluateWithThreshold(predictions, labels, DefaultEvaluationThreshold, DefaultCalibrationBins)
+}
+
+// EvaluateWithThreshold is Evaluate with an explicit decision threshold and
+// number of calibration bins
+func EvaluateWithThreshold(predictions []float64, labels []bool, threshold float64, bins int) Metrics {
+	n := len(predictions)
+	if len(labels) < n {
+		n = len(labels)
+	}
+	if bins <= 0 {
+		bins = DefaultCalibrationBins
+	}
+
+	m := Metrics{Threshold: threshold}
+	for i := 0; i < n; i++ {
+		m.Record(predictions[i] > threshold, labels[i])
+	}
+
+	tp := float64(m.TruePositives)
+	fp := float64(m.FalsePositives)
+	tn := float64(m.TrueNegatives)
+	fn := float64(m.FalseNegatives)
+
+	// MCC is defined as 0 when any marginal is empty
+	denominator := math.Sqrt((tp + fp) * (tp + fn) * (tn + fp) * (tn + fn))
+	if denominator > 0 {
+		m.MCC = (tp*tn - fp*fn) / denominator
+	}
+
+	m.Calibration, m.ExpectedCalibrationError = calibrationCurve(predictions[:n], labels[:n], bins)
+
+	return m
+}
+
+// calibrationCurve buckets predictions into equal-width bins over [0, 1] and
+// returns the non-empty bins together with the expected calibration error
+func calibrationCurve(predictions []float64, labels []bool, bins int) ([]CalibrationBin, float64) {
+	counts := make([]int, bins)
+	sums := make([]float64, bins)
+	positives := make([]int, bins)
+
+	for i, p := range predictions {
+		clamped := math.Max(0, math.Min(1, p))
+		idx := int(clamped * float64(bins))
+		if idx == bins {
+			idx = bins - 1
+		}
+		counts[idx]++
+		sums[idx] += clamped
+		if labels[i] {
+			positives[idx]++
+		}
+	}
+
+	curve := make([]CalibrationBin, 0, bins)
+	ece := 0.0
+	width := 1.0 / float64(bins)
+
+	for i := 0; i < bins; i++ {
+		if counts[i] == 0 {
+			continue
+		}
+		bin := CalibrationBin{
+			Lower:         float64(i) * width,
+			Upper:         float64(i+1) * width,
+			Count:         counts[i],
+			MeanPredicted: sums[i] / float64(counts[i]),
+			FractionTrue:  float64(positives[i]) / float64(counts[i]),
+		}
+		curve = append(curve, bin)
+
+		if len(predictions) > 0 {
+			weight := float64(counts[i]) / float64(len(predictions))
+			ece += weight * math.Abs(bin.MeanPredicted-bin.FractionTrue)
+		}
+	}
+
+	return curve, ece
+}
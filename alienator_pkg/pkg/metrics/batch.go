@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Analyzer run statuses
+const (
+	AnalyzerStatusSuccess = "success"
+	AnalyzerStatusError   = "error"
+	AnalyzerStatusTimeout = "timeout"
+)
+
+// AnalyzerRun is how one analyzer's run over a text ended
+type AnalyzerRun struct {
+	Analyzer string
+	Status   string
+}
+
+// BatchConfig controls batched recording of the per-analyzer metrics. Runs
+// are held in Shards buffers, so that concurrent analyses rarely wait for
+// one another, and written to Prometheus every FlushInterval, or as soon as
+// MaxPending runs are held. Scrapes lag by at most FlushInterval; the counts
+// are the same as recorded directly.
+type BatchConfig struct {
+	FlushInterval time.Duration
+	MaxPending    int
+	Shards        int
+}
+
+// DefaultBatchConfig returns a config flushing every second or 4096 runs,
+// with a buffer per CPU
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		FlushInterval: time.Second,
+		MaxPending:    4096,
+		Shards:        runtime.GOMAXPROCS(0),
+	}
+}
+
+// Validate checks that the interval, size and shard count are positive
+func (c BatchConfig) Validate() error {
+	if c.FlushInterval <= 0 {
+		return fmt.Errorf("flush interval must be positive, got %s", c.FlushInterval)
+	}
+	if c.MaxPending <= 0 {
+		return fmt.Errorf("max pending runs must be positive, got %d", c.MaxPending)
+	}
+	if c.Shards <= 0 {
+		return fmt.Errorf("shard count must be positive, got %d", c.Shards)
+	}
+	return nil
+}
+
+// batcher holds analyzer runs until they are flushed to the metrics
+type batcher struct {
+	metrics    *Metrics
+	shards     []batchShard
+	next       uint32
+	shardLimit int
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// batchShard is one buffer of pending runs, padded to its own cache line
+type batchShard struct {
+	mu      sync.Mutex
+	pending []AnalyzerRun
+	_       [32]byte
+}
+
+// EnableBatching holds per-analyzer metric updates and writes them in
+// batches from a background goroutine, rather than on the analysis path.
+// Call Close on shutdown to flush what is held.
+func (m *Metrics) EnableBatching(cfg BatchConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	b := &batcher{
+		metrics:    m,
+		shards:     make([]batchShard, cfg.Shards),
+		shardLimit: (cfg.MaxPending + cfg.Shards - 1) / cfg.Shards,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if !m.batching.CompareAndSwap(nil, b) {
+		return errors.New("metrics batching is already enabled")
+	}
+	go b.run(cfg.FlushInterval)
+	return nil
+}
+
+// Flush writes the batched updates held so far
+func (m *Metrics) Flush() {
+	if b := m.batching.Load(); b != nil {
+		b.flush()
+	}
+}
+
+// Close stops batching and flushes what is held; later updates are recorded
+// directly. Call it once analyses have stopped, as on shutdown.
+func (m *Metrics) Close() {
+	if b := m.batching.Swap(nil); b != nil {
+		close(b.stop)
+		<-b.done
+		b.flush()
+	}
+}
+
+// add holds runs in the next shard, flushing the shard when it is full
+func (b *batcher) add(runs []AnalyzerRun) {
+	shard := &b.shards[atomic.AddUint32(&b.next, 1)%uint32(len(b.shards))]
+	shard.mu.Lock()
+	shard.pending = append(shard.pending, runs...)
+	var full []AnalyzerRun
+	if len(shard.pending) >= b.shardLimit {
+		full = shard.pending
+		shard.pending = nil
+	}
+	shard.mu.Unlock()
+
+	if full != nil {
+		b.metrics.recordAnalyzerRuns(full)
+	}
+}
+
+// flush writes every shard's pending runs
+func (b *batcher) flush() {
+	var pending []AnalyzerRun
+	for i := range b.shards {
+		shard := &b.shards[i]
+		shard.mu.Lock()
+		pending = append(pending, shard.pending...)
+		shard.pending = shard.pending[:0]
+		shard.mu.Unlock()
+	}
+	b.metrics.recordAnalyzerRuns(pending)
+}
+
+// run flushes every interval until stopped
+func (b *batcher) run(interval time.Duration) {
+	defer close(b.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.flush()
+		}
+	}
+}
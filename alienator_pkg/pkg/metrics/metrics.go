@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,39 +15,91 @@ type Metrics struct {
 	requestsTotal    prometheus.Counter
 	requestDuration  prometheus.Histogram
 	requestsInFlight prometheus.Gauge
+	limiterInFlight  *prometheus.GaugeVec
+	requestsShed     *prometheus.CounterVec
 
 	// Analysis metrics
 	analysisTotal  *prometheus.CounterVec
 	analysisErrors *prometheus.CounterVec
 	analysisScores prometheus.Histogram
 
+	// Batched analyzer run updates, see EnableBatching
+	batching atomic.Pointer[batcher] // nil when recorded directly
+
+	// Analyzer circuit breaker metrics
+	analyzerCircuitState *prometheus.GaugeVec
+	analyzerCircuitTrips *prometheus.CounterVec
+
 	// System metrics
 	systemMemory prometheus.Gauge
 	systemCPU    prometheus.Gauge
 
-	mu sync.RWMutex
+	// Consumer metrics
+	consumerInFlight *prometheus.GaugeVec
+	queueDepth       *prometheus.GaugeVec
+
+	// Broadcast metrics
+	broadcastRetries  prometheus.Counter
+	broadcastFailures *prometheus.CounterVec
+
+	// Pattern registry metrics
+	patternRegistrySize prometheus.Gauge
+	patternEvictions    *prometheus.CounterVec
+
+	// Outbox metrics
+	outboxDepth   prometheus.Gauge
+	outboxRetries prometheus.Counter
+
+	gatherer prometheus.Gatherer
+	mu       sync.RWMutex
 }
 
-// NewMetrics creates a new metrics instance
+// NewMetrics creates a new metrics instance registered with the default
+// Prometheus registry
 func NewMetrics() *Metrics {
+	return NewMetricsWith(prometheus.DefaultRegisterer, prometheus.DefaultGatherer)
+}
+
+// NewMetricsWith creates a new metrics instance registered with registerer
+// and exposed by gatherer, e.g. a prometheus.NewRegistry() for both
+func NewMetricsWith(registerer prometheus.Registerer, gatherer prometheus.Gatherer) *Metrics {
+	factory := promauto.With(registerer)
 	return &Metrics{
-		requestsTotal: promauto.NewCounter(prometheus.CounterOpts{
+		gatherer: gatherer,
+
+		requestsTotal: factory.NewCounter(prometheus.CounterOpts{
 			Name: "http_requests_total",
 			Help: "Total number of HTTP requests",
 		}),
 
-		requestDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+		requestDuration: factory.NewHistogram(prometheus.HistogramOpts{
 			Name:    "http_request_duration_seconds",
 			Help:    "HTTP request duration in seconds",
 			Buckets: prometheus.DefBuckets,
 		}),
 
-		requestsInFlight: promauto.NewGauge(prometheus.GaugeOpts{
+		requestsInFlight: factory.NewGauge(prometheus.GaugeOpts{
 			Name: "http_requests_in_flight",
 			Help: "Current number of HTTP requests being processed",
 		}),
 
-		analysisTotal: promauto.NewCounterVec(
+		limiterInFlight: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "http_limiter_in_flight",
+				Help: "Current number of HTTP requests admitted by a concurrency limiter",
+			},
+			[]string{"limiter"},
+		),
+
+		requestsShed: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_requests_shed_total",
+				Help: "Total number of HTTP requests rejected because a concurrency limit was reached",
+			},
+			[]string{"limiter"},
+		),
+
+		analysisTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "analysis_requests_total",
 				Help: "Total number of analysis requests",
@@ -54,7 +107,7 @@ func NewMetrics() *Metrics {
 			[]string{"analyzer", "status"},
 		),
 
-		analysisErrors: promauto.NewCounterVec(
+		analysisErrors: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "analysis_errors_total",
 				Help: "Total number of analysis errors",
@@ -62,21 +115,89 @@ func NewMetrics() *Metrics {
 			[]string{"analyzer", "error_type"},
 		),
 
-		analysisScores: promauto.NewHistogram(prometheus.HistogramOpts{
+		analysisScores: factory.NewHistogram(prometheus.HistogramOpts{
 			Name:    "analysis_scores",
 			Help:    "Distribution of anomaly scores",
 			Buckets: []float64{0.0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1.0},
 		}),
 
-		systemMemory: promauto.NewGauge(prometheus.GaugeOpts{
+		analyzerCircuitState: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "analyzer_circuit_state",
+				Help: "State of an analyzer's circuit breaker: 0 closed, 1 half-open, 2 open",
+			},
+			[]string{"analyzer"},
+		),
+
+		analyzerCircuitTrips: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "analyzer_circuit_trips_total",
+				Help: "Total number of times an analyzer's circuit breaker opened after repeated failures",
+			},
+			[]string{"analyzer"},
+		),
+
+		systemMemory: factory.NewGauge(prometheus.GaugeOpts{
 			Name: "system_memory_usage_bytes",
 			Help: "Current memory usage in bytes",
 		}),
 
-		systemCPU: promauto.NewGauge(prometheus.GaugeOpts{
+		systemCPU: factory.NewGauge(prometheus.GaugeOpts{
 			Name: "system_cpu_usage_percent",
 			Help: "Current CPU usage percentage",
 		}),
+
+		consumerInFlight: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "consumer_in_flight",
+				Help: "Current number of messages being processed by a queue consumer",
+			},
+			[]string{"consumer"},
+		),
+
+		queueDepth: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "queue_depth",
+				Help: "Current number of messages waiting in a queue",
+			},
+			[]string{"queue"},
+		),
+
+		broadcastRetries: factory.NewCounter(prometheus.CounterOpts{
+			Name: "broadcast_retries_total",
+			Help: "Total number of broadcast publish attempts retried after a transient error",
+		}),
+
+		broadcastFailures: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "broadcast_failures_total",
+				Help: "Total number of broadcasts that failed after all attempts",
+			},
+			[]string{"reason"},
+		),
+
+		patternRegistrySize: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "pattern_registry_size",
+			Help: "Current number of patterns held by the pattern matcher",
+		}),
+
+		patternEvictions: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "pattern_evictions_total",
+				Help: "Total number of patterns removed from the pattern registry",
+			},
+			[]string{"reason"},
+		),
+
+		outboxDepth: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_depth",
+			Help: "Current number of results in the outbox waiting to be committed to the database",
+		}),
+
+		outboxRetries: factory.NewCounter(prometheus.CounterOpts{
+			Name: "outbox_retries_total",
+			Help: "Total number of outbox commits retried after a database error",
+		}),
 	}
 }
 
@@ -100,6 +221,16 @@ func (m *Metrics) DecRequestsInFlight() {
 	m.requestsInFlight.Dec()
 }
 
+// UpdateLimiterInFlight updates the number of requests admitted by a concurrency limiter
+func (m *Metrics) UpdateLimiterInFlight(limiter string, count float64) {
+	m.limiterInFlight.WithLabelValues(limiter).Set(count)
+}
+
+// RecordRequestShed records a request rejected by a concurrency limiter
+func (m *Metrics) RecordRequestShed(limiter string) {
+	m.requestsShed.WithLabelValues(limiter).Inc()
+}
+
 // RecordAnalysis records an analysis request
 func (m *Metrics) RecordAnalysis(analyzer, status string) {
 	m.analysisTotal.WithLabelValues(analyzer, status).Inc()
@@ -115,6 +246,47 @@ func (m *Metrics) RecordAnalysisScore(score float64) {
 	m.analysisScores.Observe(score)
 }
 
+// RecordAnalyzerRuns records the analyzer runs of one analysis: each in
+// analysis_requests_total by analyzer and status, and those that failed in
+// analysis_errors_total with their status as the error type. With batching
+// enabled they are held until the next flush.
+func (m *Metrics) RecordAnalyzerRuns(runs []AnalyzerRun) {
+	if len(runs) == 0 {
+		return
+	}
+	if b := m.batching.Load(); b != nil {
+		b.add(runs)
+		return
+	}
+	m.recordAnalyzerRuns(runs)
+}
+
+// recordAnalyzerRuns adds runs to the analysis counters, updating each
+// series once
+func (m *Metrics) recordAnalyzerRuns(runs []AnalyzerRun) {
+	counts := make(map[AnalyzerRun]float64)
+	for _, run := range runs {
+		counts[run]++
+	}
+	for run, count := range counts {
+		m.analysisTotal.WithLabelValues(run.Analyzer, run.Status).Add(count)
+		if run.Status != AnalyzerStatusSuccess {
+			m.analysisErrors.WithLabelValues(run.Analyzer, run.Status).Add(count)
+		}
+	}
+}
+
+// UpdateAnalyzerCircuitState updates the state of an analyzer's circuit
+// breaker: 0 closed, 1 half-open, 2 open
+func (m *Metrics) UpdateAnalyzerCircuitState(analyzer string, state float64) {
+	m.analyzerCircuitState.WithLabelValues(analyzer).Set(state)
+}
+
+// RecordAnalyzerCircuitTrip records an analyzer's circuit breaker opening
+func (m *Metrics) RecordAnalyzerCircuitTrip(analyzer string) {
+	m.analyzerCircuitTrips.WithLabelValues(analyzer).Inc()
+}
+
 // UpdateSystemMemory updates the system memory usage metric
 func (m *Metrics) UpdateSystemMemory(bytes float64) {
 	m.systemMemory.Set(bytes)
@@ -125,7 +297,48 @@ func (m *Metrics) UpdateSystemCPU(percent float64) {
 	m.systemCPU.Set(percent)
 }
 
+// UpdateConsumerInFlight updates the in-flight message count for a consumer
+func (m *Metrics) UpdateConsumerInFlight(consumer string, count float64) {
+	m.consumerInFlight.WithLabelValues(consumer).Set(count)
+}
+
+// UpdateQueueDepth updates the number of messages waiting in a queue
+func (m *Metrics) UpdateQueueDepth(queue string, depth float64) {
+	m.queueDepth.WithLabelValues(queue).Set(depth)
+}
+
+// RecordBroadcastRetry records a retried broadcast publish attempt
+func (m *Metrics) RecordBroadcastRetry() {
+	m.broadcastRetries.Inc()
+}
+
+// RecordBroadcastFailure records a broadcast that could not be delivered
+func (m *Metrics) RecordBroadcastFailure(reason string) {
+	m.broadcastFailures.WithLabelValues(reason).Inc()
+}
+
+// UpdatePatternRegistrySize updates the number of patterns in the registry
+func (m *Metrics) UpdatePatternRegistrySize(size float64) {
+	m.patternRegistrySize.Set(size)
+}
+
+// RecordPatternEvictions records patterns removed from the registry, either
+// "expired" or "capacity"
+func (m *Metrics) RecordPatternEvictions(reason string, count int) {
+	m.patternEvictions.WithLabelValues(reason).Add(float64(count))
+}
+
+// UpdateOutboxDepth updates the number of results waiting in the outbox
+func (m *Metrics) UpdateOutboxDepth(depth float64) {
+	m.outboxDepth.Set(depth)
+}
+
+// RecordOutboxRetry records an outbox commit retried after a database error
+func (m *Metrics) RecordOutboxRetry() {
+	m.outboxRetries.Inc()
+}
+
 // GetRegistry returns the prometheus registry
 func (m *Metrics) GetRegistry() prometheus.Gatherer {
-	return prometheus.DefaultGatherer
+	return m.gatherer
 }
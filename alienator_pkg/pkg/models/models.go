@@ -344,3 +344,31 @@ func GetSeverityFromScore(score float64) Severity {
 		return SeverityLow
 	}
 }
+
+// Record adds one prediction to the confusion counts and recomputes the
+// accuracy, precision, recall and F1 score from them
+func (m *ModelMetrics) Record(predicted, actual bool) {
+	switch {
+	case predicted && actual:
+		m.TruePositives++
+	case predicted:
+		m.FalsePositives++
+	case actual:
+		m.FalseNegatives++
+	default:
+		m.TrueNegatives++
+	}
+	m.TotalPredictions++
+
+	m.Accuracy = float64(m.TruePositives+m.TrueNegatives) / float64(m.TotalPredictions)
+	m.Precision, m.Recall, m.F1Score = 0, 0, 0
+	if m.TruePositives+m.FalsePositives > 0 {
+		m.Precision = float64(m.TruePositives) / float64(m.TruePositives+m.FalsePositives)
+	}
+	if m.TruePositives+m.FalseNegatives > 0 {
+		m.Recall = float64(m.TruePositives) / float64(m.TruePositives+m.FalseNegatives)
+	}
+	if m.Precision+m.Recall > 0 {
+		m.F1Score = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+	}
+}
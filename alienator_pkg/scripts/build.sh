@@ -17,7 +17,8 @@ BUILD_TIME=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
 GIT_COMMIT=${GIT_COMMIT:-$(git rev-parse --short HEAD 2>/dev/null || echo "unknown")}
 
 # Build flags
-LDFLAGS="-X main.Version=${VERSION} -X main.BuildTime=${BUILD_TIME} -X main.GitCommit=${GIT_COMMIT}"
+VERSION_PKG="github.com/ruvnet/alienator/internal/version"
+LDFLAGS="-X ${VERSION_PKG}.Version=${VERSION} -X ${VERSION_PKG}.BuildDate=${BUILD_TIME} -X ${VERSION_PKG}.Commit=${GIT_COMMIT}"
 
 echo -e "${BLUE}🚀 Building VibeCast ${VERSION}${NC}"
 echo -e "${BLUE}Build Time: ${BUILD_TIME}${NC}"
@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/alert"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/models/proto"
+	"github.com/ruvnet/alienator/internal/queue"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+// recordingBroker keeps every published message
+type recordingBroker struct {
+	flakyBroker
+	published []*proto.Message
+}
+
+func (b *recordingBroker) Publish(ctx context.Context, topic string, message *proto.Message) error {
+	b.published = append(b.published, message)
+	return nil
+}
+
+func anomalousResult() *models.AnomalyResult {
+	return &models.AnomalyResult{
+		Score:       0.91,
+		Confidence:  0.8,
+		IsAnomalous: true,
+		Severity:    models.SeverityHigh,
+		Details: map[string]*models.AnalysisResult{
+			"entropy":    {Score: 0.4},
+			"linguistic": {Score: 0.95},
+		},
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestAlertFields(t *testing.T) {
+	a := alert.New("r1", anomalousResult(), "As an AI language model,   I cannot   help with that request today.", 30)
+
+	if a.TopAnalyzer != "linguistic" || a.TopScore != 0.95 {
+		t.Errorf("Expected linguistic as the top analyzer, got %s (%g)", a.TopAnalyzer, a.TopScore)
+	}
+	if a.Excerpt != "As an AI language model, I…" {
+		t.Errorf("Expected the excerpt to be cut at a word boundary, got %q", a.Excerpt)
+	}
+	if a.Severity != "high" {
+		t.Errorf("Expected severity high, got %q", a.Severity)
+	}
+}
+
+func TestAlertTemplateJSON(t *testing.T) {
+	tmpl, err := alert.NewTemplate(alert.FormatJSON, `{{.TopAnalyzer}} flagged {{percent .Score}}`)
+	if err != nil {
+		t.Fatalf("NewTemplate failed: %v", err)
+	}
+
+	payload, err := tmpl.Render(alert.New("r1", anomalousResult(), "", 0))
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("Expected a JSON payload, got %s", payload)
+	}
+	if decoded["message"] != "linguistic flagged 91%" {
+		t.Errorf("Unexpected message %v", decoded["message"])
+	}
+	if decoded["id"] != "r1" || decoded["score"] != 0.91 {
+		t.Errorf("Expected the alert fields alongside the message, got %v", decoded)
+	}
+}
+
+func TestAlertTemplateSlack(t *testing.T) {
+	tmpl, err := alert.NewTemplate(alert.FormatSlack, "")
+	if err != nil {
+		t.Fatalf("NewTemplate failed: %v", err)
+	}
+
+	payload, err := tmpl.Render(alert.New("r1", anomalousResult(), "Some suspicious text", 100))
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	var decoded struct {
+		Text   string                   `json:"text"`
+		Blocks []map[string]interface{} `json:"blocks"`
+	}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("Expected a JSON payload, got %s", payload)
+	}
+	if !strings.HasPrefix(decoded.Text, "HIGH anomaly (score 0.91") {
+		t.Errorf("Expected the default template as fallback text, got %q", decoded.Text)
+	}
+	if len(decoded.Blocks) != 4 || decoded.Blocks[0]["type"] != "section" || decoded.Blocks[3]["type"] != "context" {
+		t.Errorf("Unexpected Slack blocks: %v", decoded.Blocks)
+	}
+	if !strings.Contains(string(payload), "Some suspicious text") {
+		t.Errorf("Expected the excerpt in the Slack message, got %s", payload)
+	}
+}
+
+func TestAlertTemplateErrors(t *testing.T) {
+	if _, err := alert.NewTemplate("xml", ""); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+	if _, err := alert.NewTemplate(alert.FormatJSON, "{{.Score"); err == nil {
+		t.Error("Expected a malformed template to be rejected")
+	}
+
+	tmpl, err := alert.NewTemplate(alert.FormatJSON, "{{.Missing}}")
+	if err != nil {
+		t.Fatalf("NewTemplate failed: %v", err)
+	}
+	if _, err := tmpl.Render(alert.New("r1", anomalousResult(), "", 0)); err == nil {
+		t.Error("Expected an unknown field to fail rendering")
+	}
+}
+
+func TestBroadcastAlertUsesChannelTemplate(t *testing.T) {
+	broker := &recordingBroker{}
+	service := newBroadcastService(t, broker)
+	if _, err := service.CreateChannel(context.Background(), "ops", "Ops", ""); err != nil {
+		t.Fatalf("Failed to create channel: %v", err)
+	}
+
+	templates, err := alert.FromConfig(config.AlertsConfig{
+		ExcerptLength: 50,
+		Default:       config.AlertTemplateConfig{Format: "json", Template: "score {{.Score}}"},
+		Channels: map[string]config.AlertTemplateConfig{
+			"alerts": {Format: "slack"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	service.SetAlertTemplates(templates)
+
+	a := service.NewAlert("r1", anomalousResult(), "text")
+	for _, channel := range []string{"alerts", "ops"} {
+		if err := service.BroadcastAlert(context.Background(), channel, a); err != nil {
+			t.Fatalf("BroadcastAlert to %s failed: %v", channel, err)
+		}
+	}
+
+	if len(broker.published) != 2 {
+		t.Fatalf("Expected 2 published alerts, got %d", len(broker.published))
+	}
+	slack, plain := broker.published[0], broker.published[1]
+	if slack.Headers["alert-format"] != "slack" || plain.Headers["alert-format"] != "json" {
+		t.Errorf("Expected slack then json formats, got %v and %v", slack.Headers, plain.Headers)
+	}
+	if !strings.Contains(string(slack.Data), `"text":"score 0.91"`) {
+		t.Errorf("Expected the channel to keep the default wording, got %s", slack.Data)
+	}
+	if !strings.Contains(string(plain.Data), `"message":"score 0.91"`) {
+		t.Errorf("Expected the default template on other channels, got %s", plain.Data)
+	}
+}
+
+func TestMessageConsumerBroadcastsAlerts(t *testing.T) {
+	broker := &recordingBroker{}
+	broadcasts := newBroadcastService(t, broker)
+	if _, err := broadcasts.CreateChannel(context.Background(), "ops", "Ops", ""); err != nil {
+		t.Fatalf("Failed to create channel: %v", err)
+	}
+	templates, err := alert.FromConfig(config.AlertsConfig{
+		ExcerptLength: 20,
+		Default:       config.AlertTemplateConfig{Format: "json", Template: "{{.TopAnalyzer}} {{.Score}}"},
+		Channels:      map[string]config.AlertTemplateConfig{"ops": {Format: "slack"}},
+	})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	broadcasts.SetAlertTemplates(templates)
+
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.95, confidence: 0.9})
+	eventBus := core.NewEventBus(core.DefaultEventBusConfig(), zap.NewNop())
+	t.Cleanup(func() { eventBus.Close() })
+	messages := &memoryMessageQueue{}
+	consumer := queue.NewMessageConsumer(detector, services.NewProcessingService(messages, eventBus, zap.NewNop()), config.ConsumerConfig{Workers: 1}, nil, zap.NewNop())
+	consumer.SetAlerts(broadcasts, []string{"alerts", "ops"})
+
+	messages.push("m1", models.AnalysisRequest{ID: "r1", Text: "a text the detector finds anomalous"})
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer consumer.Stop()
+	if acked, _ := waitSettled(t, messages, 1); len(acked) != 1 {
+		t.Fatalf("Expected the message acknowledged, got %v", acked)
+	}
+
+	if len(broker.published) != 2 {
+		t.Fatalf("Expected an alert on each target channel, got %d", len(broker.published))
+	}
+	plain, slack := broker.published[0], broker.published[1]
+	if plain.Headers["alert-format"] != "json" || !strings.Contains(string(plain.Data), `"message":"linguistic 0.95"`) {
+		t.Errorf("Expected the default template rendered, got %v %s", plain.Headers, plain.Data)
+	}
+	if slack.Headers["alert-format"] != "slack" || !strings.Contains(string(slack.Data), `"text":"linguistic 0.95"`) {
+		t.Errorf("Expected the channel's template rendered, got %v %s", slack.Headers, slack.Data)
+	}
+}
+
+func TestLoadFileAlertChannels(t *testing.T) {
+	path := writeConfigFile(t, "alienator.yaml", `
+broadcast:
+  alerts:
+    channels:
+      pager:
+        format: slack
+        template: "{{.Severity}}"
+      bad:
+        format: xml
+`)
+
+	_, err := config.LoadFile(path)
+	if err == nil || !strings.Contains(err.Error(), "broadcast.alerts.channels.bad.format") {
+		t.Fatalf("Expected the bad channel format to be reported, got %v", err)
+	}
+
+	path = writeConfigFile(t, "alienator.yaml", `
+broadcast:
+  alerts:
+    channels:
+      pager:
+        format: slack
+        template: "{{.Severity}}"
+`)
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := cfg.Broadcast.Alerts.Channels["pager"]; got.Format != "slack" || got.Template != "{{.Severity}}" {
+		t.Errorf("Unexpected pager channel settings %+v", got)
+	}
+}
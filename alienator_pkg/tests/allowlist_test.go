@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/core"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/services"
+	"github.com/ruvnet/alienator/internal/signing"
+	"go.uber.org/zap"
+)
+
+func (r *profileRepository) CreateAllowlistEntry(entry *models.AllowlistEntry) error {
+	entry.ID = uuid.New()
+	r.allowlist = append(r.allowlist, entry)
+	return nil
+}
+
+func (r *profileRepository) GetAllowlistEntry(id uuid.UUID) (*models.AllowlistEntry, error) {
+	for _, entry := range r.allowlist {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (r *profileRepository) ListAllowlistEntries() ([]*models.AllowlistEntry, error) {
+	return r.allowlist, nil
+}
+
+func (r *profileRepository) UpdateAllowlistEntry(entry *models.AllowlistEntry) error {
+	for i, existing := range r.allowlist {
+		if existing.ID == entry.ID {
+			r.allowlist[i] = entry
+			return nil
+		}
+	}
+	return apperrors.ErrNotFound
+}
+
+func (r *profileRepository) DeleteAllowlistEntry(id uuid.UUID) error {
+	for i, entry := range r.allowlist {
+		if entry.ID == id {
+			r.allowlist = append(r.allowlist[:i], r.allowlist[i+1:]...)
+			return nil
+		}
+	}
+	return apperrors.ErrNotFound
+}
+
+func TestAllowlistSuppression(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.9, confidence: 0.9})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.2, confidence: 0.9})
+
+	service := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	service.SetDetector(detector)
+
+	boilerplate := map[string]interface{}{"text": "We remain  committed to delivering value to our stakeholders."}
+	other := map[string]interface{}{"text": "The committee met on Tuesday to review the budget."}
+
+	result, err := service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{Data: boilerplate})
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if unsuppressed := (0.9*1.5 + 0.2) / 2.5; math.Abs(result.Score-unsuppressed) > 1e-9 || len(result.Metadata.Suppressions) != 0 {
+		t.Fatalf("Expected an unsuppressed score of %f, got %f with %v", unsuppressed, result.Score, result.Metadata.Suppressions)
+	}
+
+	entry, err := service.CreateAllowlistEntry(&models.AllowlistEntryRequest{
+		Name:      "corporate boilerplate",
+		MatchType: models.AllowlistPhrase,
+		Pattern:   "committed to delivering VALUE",
+		Analyzers: []string{"linguistic"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create allowlist entry: %v", err)
+	}
+
+	result, _ = service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{Data: boilerplate})
+	if math.Abs(result.Score-0.2) > 1e-9 {
+		t.Errorf("Expected the suppressed analyzer to be skipped, got score %f", result.Score)
+	}
+	if len(result.Metadata.Suppressions) != 1 || result.Metadata.Suppressions[0].EntryID != entry.ID {
+		t.Errorf("Expected the suppression to be recorded, got %v", result.Metadata.Suppressions)
+	}
+
+	result, _ = service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{Data: other})
+	if len(result.Metadata.Suppressions) != 0 {
+		t.Errorf("Expected unmatched text to be scored normally, got %v", result.Metadata.Suppressions)
+	}
+
+	// Down-weighting rather than suppressing keeps the analyzer in the mix
+	if _, err := service.UpdateAllowlistEntry(entry.ID, &models.AllowlistEntryRequest{
+		Name:      "corporate boilerplate",
+		MatchType: models.AllowlistRegex,
+		Pattern:   `(?i)stakeholders\.$`,
+		Analyzers: []string{"linguistic"},
+		Factor:    0.5,
+	}); err != nil {
+		t.Fatalf("Failed to update allowlist entry: %v", err)
+	}
+	result, _ = service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{Data: boilerplate})
+	if expected := (0.9*0.75 + 0.2) / 1.75; math.Abs(result.Score-expected) > 1e-9 {
+		t.Errorf("Expected score %f with the analyzer down-weighted, got %f", expected, result.Score)
+	}
+
+	// Input hash entries match exact inputs and, without analyzers, scale the score
+	hash, _ := signing.HashInput(other)
+	if _, err := service.CreateAllowlistEntry(&models.AllowlistEntryRequest{
+		Name:      "reviewed sample",
+		MatchType: models.AllowlistInputHash,
+		Pattern:   hash,
+	}); err != nil {
+		t.Fatalf("Failed to create allowlist entry: %v", err)
+	}
+	result, _ = service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{Data: other})
+	if result.Score != 0 || result.IsAnomaly || len(result.Metadata.Suppressions) != 1 {
+		t.Errorf("Expected the reviewed input to be suppressed, got score %f with %v", result.Score, result.Metadata.Suppressions)
+	}
+
+	if err := service.DeleteAllowlistEntry(entry.ID); err != nil {
+		t.Fatalf("Failed to delete allowlist entry: %v", err)
+	}
+	if _, err := service.GetAllowlistEntry(entry.ID); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("Expected deleted entry to be not found, got %v", err)
+	}
+}
+
+func TestAllowlistEntryValidation(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.9, confidence: 0.9})
+
+	service := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	service.SetDetector(detector)
+
+	invalid := []struct {
+		name    string
+		request models.AllowlistEntryRequest
+	}{
+		{"invalid regex", models.AllowlistEntryRequest{Name: "bad", MatchType: models.AllowlistRegex, Pattern: "("}},
+		{"short input hash", models.AllowlistEntryRequest{Name: "bad", MatchType: models.AllowlistInputHash, Pattern: "abc123"}},
+		{"blank phrase", models.AllowlistEntryRequest{Name: "bad", MatchType: models.AllowlistPhrase, Pattern: "   "}},
+		{"unknown analyzer", models.AllowlistEntryRequest{Name: "bad", MatchType: models.AllowlistPhrase, Pattern: "hello", Analyzers: []string{"astrology"}}},
+	}
+	for _, tt := range invalid {
+		if _, err := service.CreateAllowlistEntry(&tt.request); !errors.Is(err, apperrors.ErrInvalidInput) {
+			t.Errorf("%s: expected invalid input, got %v", tt.name, err)
+		}
+	}
+
+	if _, err := service.UpdateAllowlistEntry(uuid.New(), &models.AllowlistEntryRequest{Name: "missing", MatchType: models.AllowlistPhrase, Pattern: "hello"}); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("Expected updating a missing entry to be not found, got %v", err)
+	}
+}
+
+// countingAllowlistRepository counts the reads of the allowlist
+type countingAllowlistRepository struct {
+	*profileRepository
+	lists int
+}
+
+func (r *countingAllowlistRepository) ListAllowlistEntries() ([]*models.AllowlistEntry, error) {
+	r.lists++
+	return r.profileRepository.ListAllowlistEntries()
+}
+
+func TestAllowlistCache(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.9, confidence: 0.9})
+	repo := &countingAllowlistRepository{profileRepository: newProfileRepository()}
+	service := services.NewAnomalyService(repo, zap.NewNop())
+	service.SetDetector(detector)
+
+	request := &models.DetectionRequest{Data: map[string]interface{}{"text": "Thanks for reaching out to our support team."}}
+	detect := func() *models.DetectionResult {
+		t.Helper()
+		result, err := service.ProcessDetection(context.Background(), uuid.New(), "", request)
+		if err != nil {
+			t.Fatalf("Detection failed: %v", err)
+		}
+		return result
+	}
+
+	entry, err := service.CreateAllowlistEntry(&models.AllowlistEntryRequest{Name: "support", MatchType: models.AllowlistPhrase, Pattern: "support team"})
+	if err != nil {
+		t.Fatalf("Failed to create allowlist entry: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if result := detect(); len(result.Metadata.Suppressions) != 1 {
+			t.Fatalf("Expected the entry to apply, got %v", result.Metadata.Suppressions)
+		}
+	}
+	if repo.lists != 1 {
+		t.Errorf("Expected the allowlist read once for 3 detections, got %d reads", repo.lists)
+	}
+
+	if err := service.DeleteAllowlistEntry(entry.ID); err != nil {
+		t.Fatalf("Failed to delete allowlist entry: %v", err)
+	}
+	if result := detect(); len(result.Metadata.Suppressions) != 0 {
+		t.Errorf("Expected the deleted entry to stop applying at once, got %v", result.Metadata.Suppressions)
+	}
+	if repo.lists != 2 {
+		t.Errorf("Expected the allowlist read again after the change, got %d reads", repo.lists)
+	}
+}
@@ -0,0 +1,91 @@
+//go:build analyzertest
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/analyzers/analyzertest"
+	"github.com/ruvnet/alienator/internal/analyzers/claims"
+	"github.com/ruvnet/alienator/internal/analyzers/compression"
+	"github.com/ruvnet/alienator/internal/analyzers/cryptographic"
+	"github.com/ruvnet/alienator/internal/analyzers/embedding"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/analyzers/injection"
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/analyzers/repetition"
+	"github.com/ruvnet/alienator/internal/analyzers/sentiment"
+	"github.com/ruvnet/alienator/internal/analyzers/uniformity"
+	"github.com/ruvnet/alienator/internal/analyzers/watermark"
+)
+
+// goldenAnalyzers are replayed against testdata/golden/<name>.json
+var goldenAnalyzers = []struct {
+	name     string
+	analyzer analyzertest.Analyzer
+}{
+	{"entropy", entropy.NewEntropyAnalyzer()},
+	{"compression", compression.NewCompressionAnalyzer()},
+	{"linguistic", linguistic.NewLinguisticAnalyzer()},
+	{"embedding", embedding.NewEmbeddingAnalyzer()},
+	{"cryptographic", cryptographic.NewCryptographicAnalyzer()},
+	{"watermark", watermark.NewWatermarkAnalyzer()},
+	{"uniformity", uniformity.NewUniformityAnalyzer()},
+	{"repetition", repetition.NewRepetitionAnalyzer()},
+	{"sentiment", sentiment.NewSentimentAnalyzer()},
+	{"claims", claims.NewClaimsAnalyzer()},
+	{"injection", injection.NewInjectionAnalyzer()},
+}
+
+func TestAnalyzerGoldenFiles(t *testing.T) {
+	for _, tc := range goldenAnalyzers {
+		t.Run(tc.name, func(t *testing.T) {
+			analyzertest.AssertGolden(t, tc.analyzer, "testdata/golden/"+tc.name+".json")
+		})
+	}
+}
+
+func TestAnalyzerFixtureScores(t *testing.T) {
+	loop := repetition.NewRepetitionAnalyzer()
+	for _, sample := range analyzertest.SamplesLabeled(analyzertest.LabelHuman) {
+		analyzertest.AssertScore(t, loop, sample.Text, analyzertest.Range{Min: 0, Max: 0.2})
+	}
+	for _, sample := range analyzertest.Samples() {
+		if sample.Name == "ai/loop" {
+			analyzertest.AssertScore(t, loop, sample.Text, analyzertest.Range{Min: 1, Max: 1})
+		}
+	}
+
+	linguisticAnalyzer := linguistic.NewLinguisticAnalyzer()
+	for _, sample := range analyzertest.Samples() {
+		analyzertest.AssertScore(t, linguisticAnalyzer, sample.Text, analyzertest.Any)
+	}
+}
+
+func FuzzClaimsAnalyzer(f *testing.F) {
+	analyzertest.Fuzz(f, claims.NewClaimsAnalyzer())
+}
+
+func FuzzEntropyAnalyzer(f *testing.F) {
+	analyzertest.Fuzz(f, entropy.NewEntropyAnalyzer())
+}
+
+func FuzzInjectionAnalyzer(f *testing.F) {
+	analyzertest.Fuzz(f, injection.NewInjectionAnalyzer())
+}
+
+func FuzzLinguisticAnalyzer(f *testing.F) {
+	analyzertest.Fuzz(f, linguistic.NewLinguisticAnalyzer())
+}
+
+func FuzzRepetitionAnalyzer(f *testing.F) {
+	analyzertest.Fuzz(f, repetition.NewRepetitionAnalyzer())
+}
+
+func FuzzSentimentAnalyzer(f *testing.F) {
+	analyzertest.Fuzz(f, sentiment.NewSentimentAnalyzer())
+}
+
+func FuzzUniformityAnalyzer(f *testing.F) {
+	analyzertest.Fuzz(f, uniformity.NewUniformityAnalyzer())
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/batch"
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+// writeFiles writes each file's content under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBatchCollect(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"b.txt":          "b",
+		"a/one.txt":      "one",
+		"a/deep/two.txt": "two",
+		"a/.hidden.txt":  "hidden",
+		".git/config":    "hidden",
+	})
+	single := filepath.Join(dir, "b.txt")
+
+	files, err := batch.Collect([]string{single, dir})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	expected := []string{single, filepath.Join(dir, "a/deep/two.txt"), filepath.Join(dir, "a/one.txt"), single}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+	if _, err := batch.Collect([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("Expected a missing path to be an error")
+	}
+}
+
+func TestBatchRun(t *testing.T) {
+	dir := t.TempDir()
+	contents := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		text := "An ordinary note about the weekly meeting."
+		if i%4 == 0 {
+			text = "As an AI language model, I cannot attend the meeting."
+		}
+		contents[fmt.Sprintf("note%02d.txt", i)] = text
+	}
+	writeFiles(t, dir, contents)
+	files, err := batch.Collect([]string{dir})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	files = append(files, filepath.Join(dir, "missing.txt"))
+
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "As an AI"})
+	seen := make(map[string]bool)
+	summary := batch.Run(context.Background(), detector, files, batch.Options{Concurrency: 4, ContentType: core.ContentTypeProse}, func(outcome batch.Outcome) {
+		if seen[outcome.Path] {
+			t.Errorf("Expected %s to be reported once", outcome.Path)
+		}
+		seen[outcome.Path] = true
+		if (outcome.Err != nil) != (filepath.Base(outcome.Path) == "missing.txt") {
+			t.Errorf("Unexpected outcome for %s: %v", outcome.Path, outcome.Err)
+		}
+	})
+	if len(seen) != len(files) {
+		t.Errorf("Expected an outcome for each of the %d files, got %d", len(files), len(seen))
+	}
+	if summary.Files != 21 || summary.Analyzed != 20 || summary.Anomalous != 5 || summary.Failed != 1 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+}
+
+func TestBatchRunSharedBoilerplate(t *testing.T) {
+	dir := t.TempDir()
+	footer := "\n\nThis reply was drafted As an AI assistant would, reference #%d."
+	writeFiles(t, dir, map[string]string{
+		"1.txt": "The quarterly numbers came in below what we forecast." + fmt.Sprintf(footer, 1),
+		"2.txt": "Could you send me the slides from yesterday's meeting?" + fmt.Sprintf(footer, 2),
+		"3.txt": "Lunch is on me on Friday, the place around the corner." + fmt.Sprintf(footer, 3),
+	})
+	files, err := batch.Collect([]string{dir})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "As an AI"})
+	policy := core.DefaultBoilerplatePolicy()
+	policy.Enabled = true
+	if err := detector.SetBoilerplatePolicy(policy); err != nil {
+		t.Fatalf("Failed to enable boilerplate stripping: %v", err)
+	}
+	summary := batch.Run(context.Background(), detector, files, batch.Options{Concurrency: 2, ContentType: core.ContentTypeProse}, func(outcome batch.Outcome) {
+		if outcome.Err != nil {
+			t.Fatalf("Analysis of %s failed: %v", outcome.Path, outcome.Err)
+		}
+		removed, _ := outcome.Result.Metadata["boilerplate_removed"].([]core.BoilerplateRemoval)
+		if len(removed) != 1 || removed[0].Source != core.BoilerplateRepeated {
+			t.Errorf("Expected the footer shared by the files stripped from %s, got %+v", outcome.Path, removed)
+		}
+	})
+	if summary.Analyzed != 3 || summary.Anomalous != 0 {
+		t.Errorf("Expected 3 files analyzed with the footer stripped, got %+v", summary)
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestBoilerplateStripping(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "As an AI"})
+
+	// The same footer, with a different reference number, ends every text
+	bodies := []string{
+		"The quarterly numbers came in below what we forecast in March.",
+		"Could you send me the slides from yesterday's meeting?",
+		"Lunch is on me on Friday, the place around the corner.",
+	}
+	texts := make([]string, len(bodies))
+	for i, body := range bodies {
+		texts[i] = fmt.Sprintf("%s\n\nThis reply was drafted As an AI assistant would, reference #%d-%d.", body, 1000+i, i)
+	}
+
+	results, err := detector.AnalyzeBatch(texts, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Batch analysis failed: %v", err)
+	}
+	for i, result := range results {
+		if !result.IsAnomalous || result.Metadata["boilerplate_removed"] != nil {
+			t.Errorf("Text %d: expected the footer to be analyzed while stripping is disabled, got %+v", i, result)
+		}
+	}
+
+	policy := core.DefaultBoilerplatePolicy()
+	policy.Enabled = true
+	if err := detector.SetBoilerplatePolicy(policy); err != nil {
+		t.Fatalf("Failed to enable boilerplate stripping: %v", err)
+	}
+	results, err = detector.AnalyzeBatch(texts, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Batch analysis failed: %v", err)
+	}
+	for i, result := range results {
+		removed, _ := result.Metadata["boilerplate_removed"].([]core.BoilerplateRemoval)
+		if result.IsAnomalous || len(removed) != 1 || removed[0].Source != core.BoilerplateRepeated {
+			t.Errorf("Text %d: expected the repeated footer to be stripped, got %+v (removed %+v)", i, result, removed)
+		}
+	}
+
+	// A footer found in fewer texts than MinRepeats is kept
+	results, err = detector.AnalyzeBatch(texts[:2], core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Batch analysis failed: %v", err)
+	}
+	if !results[0].IsAnomalous {
+		t.Error("Expected a footer shared by two texts to be kept")
+	}
+
+	signed := "See you at the meeting tomorrow.\n-- \nAs an AI, Jane Doe\nHead of Operations"
+	result, err := detector.AnalyzeTextAs(signed, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	removed, _ := result.Metadata["boilerplate_removed"].([]core.BoilerplateRemoval)
+	if result.IsAnomalous || len(removed) != 1 || removed[0].Source != core.BoilerplatePattern || removed[0].Pattern != core.DefaultBoilerplatePatterns[0] {
+		t.Errorf("Expected the signature to be stripped by pattern, got %+v (removed %+v)", result, removed)
+	}
+
+	policy.Patterns = []string{"("}
+	if err := detector.SetBoilerplatePolicy(policy); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
+
+func TestBoilerplateConfig(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Detector.Boilerplate.Enabled = true
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	if err := detector.ApplyConfig(cfg.Detector); err != nil {
+		t.Fatalf("Expected the default boilerplate configuration to apply: %v", err)
+	}
+	if len(cfg.Detector.Boilerplate.Patterns) != len(core.DefaultBoilerplatePatterns) {
+		t.Errorf("Expected the default patterns in the configuration, got %v", cfg.Detector.Boilerplate.Patterns)
+	}
+
+	cfg.Detector.Boilerplate.Patterns = []string{"[a-"}
+	cfg.Detector.Boilerplate.MinRepeats = 1
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected invalid boilerplate settings to be rejected")
+	}
+	problems := err.(*config.ValidationError).Problems
+	if len(problems) != 2 {
+		t.Errorf("Expected the pattern and min_repeats to be reported, got %v", problems)
+	}
+	if err := detector.ApplyConfig(cfg.Detector); err == nil {
+		t.Error("Expected ApplyConfig to reject an invalid pattern")
+	}
+}
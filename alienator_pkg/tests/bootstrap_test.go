@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestAnalyzeTextBootstrapped(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "As an AI"})
+	cfg := core.DefaultBootstrapConfig()
+
+	mixed := "The weather was pleasant today. As an AI, I cannot go outside. " +
+		"We walked to the park after lunch. As an AI language model, I must politely decline. " +
+		"The kids fed the ducks by the pond."
+	result, err := detector.AnalyzeTextBootstrapped(mixed, core.ContentTypeProse, cfg)
+	if err != nil {
+		t.Fatalf("Bootstrapped analysis failed: %v", err)
+	}
+	estimate, ok := result.Metadata["bootstrap"].(core.BootstrapEstimate)
+	if !ok {
+		t.Fatalf("Expected a bootstrap estimate in the metadata, got %v", result.Metadata)
+	}
+	if estimate.Replicates != cfg.Replicates || estimate.Sentences != 5 || estimate.Level != cfg.Level {
+		t.Errorf("Unexpected estimate: %+v", estimate)
+	}
+	if estimate.StdErr <= 0 || estimate.Lower >= estimate.Upper {
+		t.Errorf("Expected resampling mixed sentences to spread the score, got %+v", estimate)
+	}
+	if estimate.Lower > result.Score || estimate.Upper < result.Score {
+		t.Errorf("Expected the interval [%.3f, %.3f] to contain the score %.3f", estimate.Lower, estimate.Upper, result.Score)
+	}
+	if result.Confidence != estimate.Support || estimate.Support < 0 || estimate.Support > 1 {
+		t.Errorf("Expected the support %.3f as the confidence, got %.3f", estimate.Support, result.Confidence)
+	}
+	if _, ok := result.Metadata["heuristic_confidence"].(float64); !ok {
+		t.Error("Expected the heuristic confidence to be kept")
+	}
+
+	// The seed makes the estimate reproducible
+	again, err := detector.AnalyzeTextBootstrapped(mixed, core.ContentTypeProse, cfg)
+	if err != nil {
+		t.Fatalf("Bootstrapped analysis failed: %v", err)
+	}
+	if !reflect.DeepEqual(again.Metadata["bootstrap"], result.Metadata["bootstrap"]) {
+		t.Errorf("Expected the same estimate from the same seed, got %+v and %+v", again.Metadata["bootstrap"], estimate)
+	}
+
+	uniform, err := detector.AnalyzeTextBootstrapped("As an AI, I cannot help. As an AI, I must decline. As an AI, I apologize.", core.ContentTypeProse, cfg)
+	if err != nil {
+		t.Fatalf("Bootstrapped analysis failed: %v", err)
+	}
+	if estimate := uniform.Metadata["bootstrap"].(core.BootstrapEstimate); estimate.StdErr > 1e-9 || estimate.Support != 1 {
+		t.Errorf("Expected identical sentences to give a certain verdict, got %+v", estimate)
+	}
+
+	single, err := detector.AnalyzeTextBootstrapped("As an AI, I cannot help with that.", core.ContentTypeProse, cfg)
+	if err != nil {
+		t.Fatalf("Bootstrapped analysis failed: %v", err)
+	}
+	if estimate := single.Metadata["bootstrap"].(core.BootstrapEstimate); estimate.Replicates != 0 || single.Confidence != 0.9 {
+		t.Errorf("Expected a single sentence to be left unresampled, got %+v with confidence %.2f", estimate, single.Confidence)
+	}
+
+	for _, invalid := range []core.BootstrapConfig{{Replicates: 1, Level: 0.95}, {Replicates: 50, Level: 1}} {
+		if _, err := detector.AnalyzeTextBootstrapped(mixed, core.ContentTypeProse, invalid); err == nil {
+			t.Errorf("Expected %+v to be rejected", invalid)
+		}
+	}
+}
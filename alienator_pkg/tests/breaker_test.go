@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// flakyAnalyzer fails while broken is set, counting its runs
+type flakyAnalyzer struct {
+	broken atomic.Bool
+	runs   atomic.Int32
+}
+
+func (a *flakyAnalyzer) Name() string { return "embedding" }
+
+func (a *flakyAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	a.runs.Add(1)
+	if a.broken.Load() {
+		return nil, errors.New("model failed to load")
+	}
+	return &models.AnalysisResult{Score: 0.9, Confidence: 0.9}, nil
+}
+
+func circuitOf(t *testing.T, detector *core.AnomalyDetector, name string) *models.CircuitState {
+	t.Helper()
+	for _, info := range detector.DescribeAnalyzers() {
+		if info.Name == name {
+			return info.Circuit
+		}
+	}
+	t.Fatalf("Analyzer %s is not registered", name)
+	return nil
+}
+
+func TestAnalyzerCircuitBreaker(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.2, confidence: 0.9})
+	flaky := &flakyAnalyzer{}
+	flaky.broken.Store(true)
+	detector.RegisterAnalyzer(flaky)
+	if err := detector.SetBreakerPolicy(core.BreakerPolicy{Failures: 2, Cooldown: 50 * time.Millisecond}); err != nil {
+		t.Fatalf("SetBreakerPolicy failed: %v", err)
+	}
+	analyze := func() (*models.AnomalyResult, error) {
+		return detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	}
+
+	// Failures fail the analysis until the circuit opens
+	for i := 0; i < 2; i++ {
+		if _, err := analyze(); err == nil {
+			t.Fatalf("Expected failure %d to fail the analysis", i+1)
+		}
+	}
+	circuit := circuitOf(t, detector, "embedding")
+	if circuit.State != core.CircuitOpen || circuit.Trips != 1 || circuit.RetryAt == nil {
+		t.Fatalf("Expected the circuit to open after 2 failures, got %+v", circuit)
+	}
+
+	result, err := analyze()
+	if err != nil {
+		t.Fatalf("Expected the analysis to go on without the failing analyzer: %v", err)
+	}
+	if flaky.runs.Load() != 2 || !reflect.DeepEqual(result.Metadata["unavailable_analyzers"], []string{"embedding"}) {
+		t.Errorf("Expected the open analyzer to be left out and reported, ran %d times, metadata %v", flaky.runs.Load(), result.Metadata)
+	}
+	if result.Score != 0.2 {
+		t.Errorf("Expected the remaining analyzer to carry the whole weight, got score %.2f", result.Score)
+	}
+
+	// A failed probe opens the circuit again without failing its analysis
+	time.Sleep(60 * time.Millisecond)
+	if result, err = analyze(); err != nil || result.Metadata["unavailable_analyzers"] == nil {
+		t.Fatalf("Expected a failed probe to be tolerated, got %v, %v", result, err)
+	}
+	if circuit := circuitOf(t, detector, "embedding"); circuit.State != core.CircuitOpen || flaky.runs.Load() != 3 {
+		t.Errorf("Expected the failed probe to reopen the circuit, got %+v after %d runs", circuit, flaky.runs.Load())
+	}
+
+	// A successful probe closes it
+	flaky.broken.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if result, err = analyze(); err != nil {
+		t.Fatalf("Probe analysis failed: %v", err)
+	}
+	if _, ok := result.Details["embedding"]; !ok || result.Metadata["unavailable_analyzers"] != nil {
+		t.Errorf("Expected the recovered analyzer to be scored, got %+v", result)
+	}
+	if circuit := circuitOf(t, detector, "embedding"); circuit.State != core.CircuitClosed || circuit.ConsecutiveFailures != 0 {
+		t.Errorf("Expected the circuit to close, got %+v", circuit)
+	}
+
+	// With every analyzer left out there is nothing to score
+	alone := core.NewAnomalyDetector(zap.NewNop(), nil)
+	alone.RegisterAnalyzer(flaky)
+	flaky.broken.Store(true)
+	alone.SetBreakerPolicy(core.BreakerPolicy{Failures: 1, Cooldown: time.Hour})
+	alone.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	if _, err := alone.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse); err == nil {
+		t.Error("Expected an analysis with every circuit open to fail")
+	}
+
+	if err := detector.SetBreakerPolicy(core.BreakerPolicy{}); err != nil {
+		t.Fatalf("Expected a zero policy to disable the breakers: %v", err)
+	}
+	if circuit := circuitOf(t, detector, "embedding"); circuit != nil {
+		t.Errorf("Expected no circuit state with the breakers disabled, got %+v", circuit)
+	}
+	if err := detector.SetBreakerPolicy(core.BreakerPolicy{Failures: 3}); err == nil {
+		t.Error("Expected a breaker without a cooldown to be rejected")
+	}
+}
+
+// switchAnalyzer fails while failing is set
+type switchAnalyzer struct {
+	name    string
+	failing atomic.Bool
+}
+
+func (a *switchAnalyzer) Name() string { return a.name }
+
+func (a *switchAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	if a.failing.Load() {
+		return nil, errors.New("analyzer failed")
+	}
+	return &models.AnalysisResult{Score: 0.3, Confidence: 0.9}, nil
+}
+
+func TestFailedBudgetedRunReleasesProbes(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	first := &switchAnalyzer{name: "linguistic"}
+	detector.RegisterAnalyzer(first)
+	flaky := &flakyAnalyzer{}
+	flaky.broken.Store(true)
+	detector.RegisterAnalyzer(flaky)
+	if err := detector.SetBreakerPolicy(core.BreakerPolicy{Failures: 1, Cooldown: 50 * time.Millisecond}); err != nil {
+		t.Fatalf("SetBreakerPolicy failed: %v", err)
+	}
+	if err := detector.SetExecutionPlan(core.ExecutionPlan{Order: []string{"linguistic", "embedding"}, Budget: time.Second}); err != nil {
+		t.Fatalf("SetExecutionPlan failed: %v", err)
+	}
+	analyze := func() (*models.AnomalyResult, error) {
+		return detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	}
+
+	analyze()
+	if circuit := circuitOf(t, detector, "embedding"); circuit.State != core.CircuitOpen {
+		t.Fatalf("Expected the failure to open the circuit, got %+v", circuit)
+	}
+
+	// The embedding analyzer is admitted as a probe, but the analysis fails
+	// on the analyzer ahead of it before the probe runs
+	time.Sleep(60 * time.Millisecond)
+	flaky.broken.Store(false)
+	first.failing.Store(true)
+	if _, err := analyze(); err == nil {
+		t.Fatal("Expected the failing analyzer to fail the analysis")
+	}
+	if flaky.runs.Load() != 1 {
+		t.Fatalf("Expected the probe not to run after the failure, ran %d times", flaky.runs.Load())
+	}
+
+	// The unrun probe was released, so the next analysis probes again
+	result, err := analyze()
+	if err != nil {
+		t.Fatalf("Expected the released probe to be admitted again: %v", err)
+	}
+	if _, ok := result.Details["embedding"]; !ok {
+		t.Errorf("Expected the probe to be scored, got %+v", result)
+	}
+	if circuit := circuitOf(t, detector, "embedding"); circuit.State != core.CircuitClosed {
+		t.Errorf("Expected the successful probe to close the circuit, got %+v", circuit)
+	}
+}
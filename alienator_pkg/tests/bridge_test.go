@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/queue"
+	"github.com/ruvnet/alienator/internal/sink"
+	"go.uber.org/zap"
+)
+
+// memoryBridgeTransport is an in-process pub/sub for exercising the bridge
+type memoryBridgeTransport struct {
+	mu        sync.Mutex
+	requests  chan []byte
+	published map[string][][]byte
+	streamed  map[string][]string
+}
+
+func newMemoryBridgeTransport() *memoryBridgeTransport {
+	return &memoryBridgeTransport{
+		requests:  make(chan []byte, 10),
+		published: make(map[string][][]byte),
+		streamed:  make(map[string][]string),
+	}
+}
+
+func (m *memoryBridgeTransport) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	return m.requests, nil
+}
+
+func (m *memoryBridgeTransport) Publish(ctx context.Context, channel string, payload []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.published[channel] = append(m.published[channel], payload)
+	return nil
+}
+
+func (m *memoryBridgeTransport) Append(ctx context.Context, stream, id string, payload []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streamed[stream] = append(m.streamed[stream], id)
+	return nil
+}
+
+// waitForResults waits until n results have been published on channel
+func (m *memoryBridgeTransport) waitForResults(t *testing.T, channel string, n int) []models.AnalysisResponse {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		m.mu.Lock()
+		payloads := m.published[channel]
+		m.mu.Unlock()
+		if len(payloads) >= n {
+			responses := make([]models.AnalysisResponse, len(payloads))
+			for i, payload := range payloads {
+				if err := json.Unmarshal(payload, &responses[i]); err != nil {
+					t.Fatalf("Failed to decode result: %v", err)
+				}
+			}
+			return responses
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d results on %s", n, channel)
+	return nil
+}
+
+func TestDecodeBridgeRequest(t *testing.T) {
+	request := queue.DecodeBridgeRequest([]byte(`{"id":"req-1","text":"hello there","options":{"content_type":"prose"}}`))
+	if request.ID != "req-1" || request.Text != "hello there" || request.Options["content_type"] != "prose" {
+		t.Errorf("Expected the JSON request to be decoded, got %+v", request)
+	}
+
+	request = queue.DecodeBridgeRequest([]byte("just some plain text"))
+	if request.Text != "just some plain text" {
+		t.Errorf("Expected plain text to be analyzed as is, got %q", request.Text)
+	}
+	if request.ID == "" {
+		t.Error("Expected a generated ID for a request without one")
+	}
+}
+
+func TestBridgeConsumerPublishesResults(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+
+	transport := newMemoryBridgeTransport()
+	cfg := config.BridgeConfig{
+		Enabled:        true,
+		RequestChannel: "analyze",
+		ResultChannel:  "results",
+		ResultStream:   "results-stream",
+		Workers:        2,
+		MaxInFlight:    4,
+	}
+	consumer := queue.NewBridgeConsumer(detector, transport, cfg, nil, zap.NewNop())
+	var sunk bytes.Buffer
+	consumer.SetSink(sink.NewWriterSink("buffer", &sunk))
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start bridge: %v", err)
+	}
+	defer consumer.Stop()
+
+	transport.requests <- []byte(`{"id":"req-1","text":"The quick brown fox jumps over the lazy dog."}`)
+	transport.requests <- []byte(`{"id":"req-2","text":"Some text","options":{"content_type":"poetry"}}`)
+
+	responses := transport.waitForResults(t, "results", 2)
+	byID := make(map[string]models.AnalysisResponse)
+	for _, response := range responses {
+		byID[response.ID] = response
+	}
+
+	if response := byID["req-1"]; response.Result == nil || response.Error != "" {
+		t.Errorf("Expected a result for req-1, got %+v", response)
+	}
+	if response := byID["req-2"]; response.Result != nil || response.Error == "" {
+		t.Errorf("Expected an error for an unknown content type, got %+v", response)
+	}
+
+	transport.mu.Lock()
+	streamed := len(transport.streamed["results-stream"])
+	transport.mu.Unlock()
+	if streamed != 2 {
+		t.Errorf("Expected both results appended to the stream, got %d", streamed)
+	}
+
+	// Results are written to the sink before they are published
+	if lines := strings.Count(sunk.String(), "\n"); lines != 1 || !strings.Contains(sunk.String(), `"id":"req-1"`) {
+		t.Errorf("Expected only the successful result in the sink, got %q", sunk.String())
+	}
+
+	if err := consumer.Start(context.Background()); err == nil {
+		t.Error("Expected starting a running bridge to fail")
+	}
+}
+
+func TestBridgeConfigValidation(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Worker.Bridge.ResultChannel = cfg.Worker.Bridge.RequestChannel
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a result channel equal to the request channel to be rejected")
+	}
+
+	cfg = config.Defaults()
+	cfg.Worker.Bridge.ResultChannel = ""
+	cfg.Worker.Bridge.ResultStream = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a bridge without any result destination to be rejected")
+	}
+
+	cfg.Worker.Bridge.Enabled = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a disabled bridge not to be validated, got %v", err)
+	}
+}
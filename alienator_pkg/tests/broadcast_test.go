@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models/proto"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+// flakyBroker fails the first publishes with the queued errors, classifying
+// them as a NATS broker does
+type flakyBroker struct {
+	*core.NATSBroker
+	failures []error
+	attempts int
+}
+
+func (b *flakyBroker) Publish(ctx context.Context, topic string, message *proto.Message) error {
+	b.attempts++
+	if len(b.failures) > 0 {
+		err := b.failures[0]
+		b.failures = b.failures[1:]
+		return err
+	}
+	return nil
+}
+
+func newBroadcastService(t *testing.T, broker core.MessageBroker) *services.BroadcastService {
+	eventBus := core.NewEventBus(core.DefaultEventBusConfig(), zap.NewNop())
+	t.Cleanup(func() { eventBus.Close() })
+
+	service := services.NewBroadcastService(broker, eventBus, zap.NewNop())
+	if _, err := service.CreateChannel(context.Background(), "alerts", "Alerts", ""); err != nil {
+		t.Fatalf("Failed to create channel: %v", err)
+	}
+	return service
+}
+
+func TestBroadcastRetriesTransientErrors(t *testing.T) {
+	broker := &flakyBroker{failures: []error{nats.ErrTimeout, nats.ErrConnectionReconnecting}}
+	service := newBroadcastService(t, broker)
+
+	var retries []int
+	service.SetRetryPolicy(services.RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		OnRetry:        func(attempt int, delay time.Duration, err error) { retries = append(retries, attempt) },
+	})
+
+	if err := service.Broadcast(context.Background(), "alerts", &proto.Message{ID: "m1"}); err != nil {
+		t.Fatalf("Expected broadcast to succeed after retries, got %v", err)
+	}
+	if broker.attempts != 3 || len(retries) != 2 {
+		t.Errorf("Expected 3 attempts and 2 retries, got %d attempts and retries %v", broker.attempts, retries)
+	}
+	if metrics := service.GetMetrics(); metrics.PublishRetries != 2 || metrics.MessagesBroadcast != 1 {
+		t.Errorf("Unexpected broadcast metrics: %+v", metrics)
+	}
+}
+
+func TestBroadcastRetriesExhausted(t *testing.T) {
+	broker := &flakyBroker{failures: []error{nats.ErrTimeout, nats.ErrTimeout, nats.ErrTimeout}}
+	service := newBroadcastService(t, broker)
+	service.SetRetryPolicy(services.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	err := service.Broadcast(context.Background(), "alerts", &proto.Message{ID: "m1"})
+	var broadcastErr *services.BroadcastError
+	if !errors.As(err, &broadcastErr) || broadcastErr.Attempts != 3 {
+		t.Fatalf("Expected BroadcastError after 3 attempts, got %v", err)
+	}
+	if !errors.Is(err, nats.ErrTimeout) {
+		t.Errorf("Expected the last publish error to be wrapped, got %v", err)
+	}
+	if service.GetMetrics().FailedBroadcasts != 1 {
+		t.Errorf("Expected one failed broadcast, got %+v", service.GetMetrics())
+	}
+}
+
+func TestBroadcastDoesNotRetryPermanentErrors(t *testing.T) {
+	broker := &flakyBroker{failures: []error{nats.ErrBadSubject}}
+	service := newBroadcastService(t, broker)
+
+	err := service.Broadcast(context.Background(), "alerts", &proto.Message{ID: "m1"})
+	var broadcastErr *services.BroadcastError
+	if !errors.As(err, &broadcastErr) || broadcastErr.Attempts != 1 || broker.attempts != 1 {
+		t.Errorf("Expected a single attempt for a permanent error, got %v after %d attempts", err, broker.attempts)
+	}
+}
+
+func TestBroadcastClassifiesErrorsPerBroker(t *testing.T) {
+	broker := core.NewBroker(nil, nil, zap.NewNop())
+	defer broker.Close()
+
+	// The in-memory broker can't fail like a NATS connection
+	if broker.IsTransient(nats.ErrTimeout) {
+		t.Error("Expected a NATS timeout not to be transient for the in-memory broker")
+	}
+	if !broker.IsTransient(fmt.Errorf("%w for topic: alerts", core.ErrTopicRateLimited)) {
+		t.Error("Expected a rate limited publish to be transient for the in-memory broker")
+	}
+	if natsBroker := (*core.NATSBroker)(nil); natsBroker.IsTransient(core.ErrTopicRateLimited) {
+		t.Error("Expected the in-memory broker's rate limit not to be transient for the NATS broker")
+	}
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestVoteCombination(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	// One miscalibrated analyzer drags the fused score over the threshold
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "cryptographic", score: 1.0, confidence: 0.9})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.6, confidence: 0.9})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.65, confidence: 0.9})
+
+	text := "The committee will meet again next week to review the budget."
+	fused, err := detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if !fused.IsAnomalous || fused.Metadata["combination"] != nil {
+		t.Errorf("Expected fusion to flag score %.3f without vote metadata, got %v", fused.Score, fused.Metadata)
+	}
+
+	policy := core.DefaultCombinationPolicy()
+	policy.Mode = core.CombinationVote
+	if err := detector.SetCombinationPolicy(policy); err != nil {
+		t.Fatalf("Expected the policy to be accepted: %v", err)
+	}
+	voted, err := detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	votes, ok := voted.Metadata["votes"].(map[string]bool)
+	if !ok || len(votes) != 3 || !votes["cryptographic"] || votes["linguistic"] || votes["entropy"] {
+		t.Fatalf("Expected only the cryptographic analyzer to vote anomalous, got %v", voted.Metadata["votes"])
+	}
+	if voted.IsAnomalous || voted.Metadata["combination"] != core.CombinationVote {
+		t.Errorf("Expected the majority to outvote one analyzer, share %v", voted.Metadata["vote_share"])
+	}
+	if voted.Score != fused.Score {
+		t.Errorf("Expected the fused score to be kept, got %.3f and %.3f", fused.Score, voted.Score)
+	}
+
+	// Per-analyzer thresholds change the votes
+	policy.VoteThresholds = map[string]float64{"linguistic": 0.5, "entropy": 0.5}
+	if err := detector.SetCombinationPolicy(policy); err != nil {
+		t.Fatalf("Expected the policy to be accepted: %v", err)
+	}
+	voted, err = detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if !voted.IsAnomalous || voted.Metadata["vote_share"] != 1.0 {
+		t.Errorf("Expected a unanimous anomalous vote, got %v", voted.Metadata["votes"])
+	}
+
+	invalid := []core.CombinationPolicy{
+		{Mode: "median", Quorum: 0.5},
+		{Mode: core.CombinationVote, Quorum: 1},
+		{Mode: core.CombinationVote, Quorum: 0.5, VoteThresholds: map[string]float64{"entropy": 0}},
+	}
+	for _, policy := range invalid {
+		if err := detector.SetCombinationPolicy(policy); err == nil {
+			t.Errorf("Expected %+v to be rejected", policy)
+		}
+	}
+}
+
+func TestCombinationConfig(t *testing.T) {
+	t.Setenv("DETECTOR_COMBINATION", "vote")
+	t.Setenv("DETECTOR_VOTE_QUORUM", "0.66")
+
+	cfg, err := config.LoadFile("")
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Detector.Combination.Mode != "vote" || cfg.Detector.Combination.Quorum != 0.66 {
+		t.Errorf("Expected the environment to select voting, got %+v", cfg.Detector.Combination)
+	}
+	if err := core.NewAnomalyDetector(zap.NewNop(), nil).ApplyConfig(cfg.Detector); err != nil {
+		t.Errorf("ApplyConfig failed: %v", err)
+	}
+
+	cfg.Detector.Combination.Mode = "median"
+	cfg.Detector.Combination.VoteThresholds = map[string]float64{"entropy": 1.5}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "detector.combination.mode:") ||
+		!strings.Contains(err.Error(), "detector.combination.vote_thresholds.entropy:") {
+		t.Errorf("Expected the mode and vote threshold to be reported, got %v", err)
+	}
+}
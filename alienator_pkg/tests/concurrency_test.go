@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+func TestConcurrencyLimiterShedsLoad(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := middleware.NewConcurrencyLimiter("analysis", 1, 1500*time.Millisecond, nil)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	router := gin.New()
+	router.GET("/slow", limiter.Handler(), func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fast", limiter.Handler(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		done <- rec.Code
+	}()
+	<-entered
+
+	if inFlight := limiter.InFlight(); inFlight != 1 {
+		t.Errorf("Expected one request in flight, got %d", inFlight)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 over the limit, got %d", rec.Code)
+	}
+	if retryAfter := rec.Header().Get("Retry-After"); retryAfter != "2" {
+		t.Errorf("Expected Retry-After rounded up to 2 seconds, got %q", retryAfter)
+	}
+	var response models.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response.Error == nil || response.Error.Rejection == nil || *response.Error.Rejection != (models.Rejection{RetryAfter: 2, Limit: 1, Current: 2}) {
+		t.Errorf("Expected the limit and requests in flight in the rejection, got %+v", response.Error)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("Expected the admitted request to complete, got %d", code)
+	}
+	if inFlight := limiter.InFlight(); inFlight != 0 {
+		t.Errorf("Expected no requests in flight after completion, got %d", inFlight)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected a request to be admitted once capacity frees up, got %d", rec.Code)
+	}
+
+	// Limits can be changed while serving
+	limiter.Update(0, time.Second)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected every request shed at a zero limit, got %d", rec.Code)
+	}
+}
+
+func TestConcurrencyConfigValidation(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Concurrency.AnalysisMaxInFlight = cfg.Concurrency.MaxInFlight + 1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an analysis limit above the overall limit to be rejected")
+	}
+
+	cfg = config.Defaults()
+	cfg.Concurrency.RetryAfter = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a zero Retry-After to be rejected")
+	}
+}
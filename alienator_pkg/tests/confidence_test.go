@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// fixedAnalyzer returns a fixed score and confidence under the given name
+type fixedAnalyzer struct {
+	name       string
+	score      float64
+	confidence float64
+}
+
+func (a *fixedAnalyzer) Name() string { return a.name }
+
+func (a *fixedAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return &models.AnalysisResult{Score: a.score, Confidence: a.confidence}, nil
+}
+
+func newConfidenceDetector() *core.AnomalyDetector {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.5, confidence: 0.2})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.5, confidence: 0.8})
+	return detector
+}
+
+func TestConfidenceAggregation(t *testing.T) {
+	tests := []struct {
+		strategy string
+		expected float64
+	}{
+		{core.ConfidenceMean, 0.5},
+		{core.ConfidenceMin, 0.2},
+		// Prose weights linguistic 1.5 and entropy 1.0
+		{core.ConfidenceWeightedMean, (0.2*1.5 + 0.8*1.0) / (1.5 + 1.0)},
+		{core.ConfidenceHarmonicMean, 2 / (1/0.2 + 1/0.8)},
+	}
+
+	for _, tt := range tests {
+		detector := newConfidenceDetector()
+		if err := detector.SetConfidenceAggregation(tt.strategy); err != nil {
+			t.Fatalf("SetConfidenceAggregation(%q) failed: %v", tt.strategy, err)
+		}
+
+		result, err := detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+		if err != nil {
+			t.Fatalf("Analysis failed: %v", err)
+		}
+		if math.Abs(result.Confidence-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected confidence %f, got %f", tt.strategy, tt.expected, result.Confidence)
+		}
+	}
+
+	if err := newConfidenceDetector().SetConfidenceAggregation("median"); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
+
+func TestConfidenceClamping(t *testing.T) {
+	detector := newConfidenceDetector()
+	if err := detector.SetConfidencePolicy(core.ConfidencePolicy{
+		Floor:       0.4,
+		Ceiling:     0.7,
+		Aggregation: core.ConfidenceMin,
+	}); err != nil {
+		t.Fatalf("SetConfidencePolicy failed: %v", err)
+	}
+
+	result, err := detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if result.Confidence != 0.4 {
+		t.Errorf("Expected the clamped minimum 0.4, got %f", result.Confidence)
+	}
+	if detail := result.Details["entropy"]; detail.Confidence != 0.7 {
+		t.Errorf("Expected the entropy confidence clamped to 0.7, got %f", detail.Confidence)
+	}
+
+	if err := detector.SetConfidencePolicy(core.ConfidencePolicy{Floor: 0.8, Ceiling: 0.2, Aggregation: core.ConfidenceMean}); err == nil {
+		t.Error("Expected an error for a floor above the ceiling")
+	}
+}
+
+func TestMinContributingConfidence(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "cryptographic", score: 0.05, confidence: 0.1})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.8, confidence: 0.9})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.8, confidence: 0.9})
+
+	diluted, err := detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if diluted.Metadata["excluded_analyzers"] != nil {
+		t.Error("Expected every analyzer to contribute by default")
+	}
+
+	policy := core.DefaultConfidencePolicy()
+	policy.MinContributing = 0.3
+	if err := detector.SetConfidencePolicy(policy); err != nil {
+		t.Fatalf("SetConfidencePolicy failed: %v", err)
+	}
+	result, err := detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if math.Abs(result.Score-0.8) > 1e-9 || result.Score <= diluted.Score {
+		t.Errorf("Expected the weights to be renormalized over the confident analyzers, got %.3f", result.Score)
+	}
+	if math.Abs(result.Confidence-0.9) > 1e-9 {
+		t.Errorf("Expected the excluded confidence to be left out, got %.3f", result.Confidence)
+	}
+	excluded, ok := result.Metadata["excluded_analyzers"].([]string)
+	if !ok || len(excluded) != 1 || excluded[0] != "cryptographic" || result.Details["cryptographic"] == nil {
+		t.Errorf("Expected the excluded analyzer in metadata and details, got %v", result.Metadata["excluded_analyzers"])
+	}
+
+	// With no confident analyzer, all of them still count
+	policy.MinContributing = 0.95
+	if err := detector.SetConfidencePolicy(policy); err != nil {
+		t.Fatalf("SetConfidencePolicy failed: %v", err)
+	}
+	result, err = detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if result.Score != diluted.Score || result.Metadata["excluded_analyzers"] != nil {
+		t.Errorf("Expected the full ensemble, got %.3f", result.Score)
+	}
+
+	policy.MinContributing = 1.5
+	if err := detector.SetConfidencePolicy(policy); err == nil {
+		t.Error("Expected a minimum above 1 to be rejected")
+	}
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/analyzers/embedding"
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestConfigExportImport(t *testing.T) {
+	tuned := config.Defaults().Detector
+	tuned.Thresholds.Code = 0.85
+	tuned.Combination = config.CombinationConfig{Mode: "vote", Quorum: 0.6, VoteThresholds: map[string]float64{"entropy": 0.55}}
+	tuned.Analyzers = map[string]config.AnalyzerConfig{
+		"embedding":  {Parameters: map[string]interface{}{"kmeans_seed": 7}},
+		"watermark":  {Disabled: true},
+		"linguistic": {Parameters: map[string]interface{}{"language": "es"}},
+	}
+
+	raw, err := json.Marshal(map[string]interface{}{"detector": config.Export(tuned)})
+	if err != nil {
+		t.Fatalf("Failed to encode the export: %v", err)
+	}
+	source := writeConfigFile(t, "detector.json", string(raw))
+
+	// The export reads back unchanged
+	loaded, err := config.LoadFile(source)
+	if err != nil {
+		t.Fatalf("Expected the export to load: %v", err)
+	}
+	if loaded.Detector.WarmupTimeout != tuned.WarmupTimeout || loaded.Detector.Combination.VoteThresholds["entropy"] != 0.55 ||
+		!loaded.Detector.Analyzers["watermark"].Disabled || loaded.Detector.Analyzers["embedding"].Parameters["kmeans_seed"] != 7.0 {
+		t.Errorf("Expected the detector section to round-trip, got %+v", loaded.Detector)
+	}
+
+	target := writeConfigFile(t, "alienator.yaml", "server:\n  port: 9090\n")
+	imported, err := config.PrepareImport(source, target)
+	if err != nil {
+		t.Fatalf("PrepareImport failed: %v", err)
+	}
+	for _, expected := range []string{"detector.thresholds.code", "detector.combination.mode", "detector.analyzers"} {
+		found := false
+		for _, path := range imported.Changes {
+			found = found || path == expected
+		}
+		if !found {
+			t.Errorf("Expected %s among the changes, got %v", expected, imported.Changes)
+		}
+	}
+	if before, _ := os.ReadFile(target); string(before) != "server:\n  port: 9090\n" {
+		t.Error("Expected nothing to be written before Write")
+	}
+	if err := imported.Write(); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	installed, err := config.LoadFile(target)
+	if err != nil {
+		t.Fatalf("Expected the imported file to load: %v", err)
+	}
+	if installed.Server.Port != 9090 || installed.Detector.Thresholds.Code != 0.85 || installed.Detector.Combination.Mode != "vote" {
+		t.Errorf("Expected the detector section imported and the rest kept, got %+v", installed)
+	}
+	again, err := config.PrepareImport(source, target)
+	if err != nil || len(again.Changes) != 0 {
+		t.Errorf("Expected a repeated import to change nothing, got %v (%v)", again, err)
+	}
+
+	invalid := map[string]string{
+		`{"detector": {"thresholds": {"code": 1.5}}}`:          "detector.thresholds.code:",
+		`{"detector": {}, "server": {"port": 1}}`:              "detector section and nothing else",
+		`{"detector": {"analyzers": {"embedding": {"x": 1}}}}`: "detector.analyzers.embedding.x: unknown key",
+	}
+	for content, expected := range invalid {
+		_, err := config.PrepareImport(writeConfigFile(t, "invalid.json", content), target)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", content, expected, err)
+		}
+	}
+}
+
+func TestConfigureAnalyzers(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(embedding.NewEmbeddingAnalyzer())
+	detector.RegisterAnalyzer(linguistic.NewLinguisticAnalyzer())
+
+	configured := map[string]config.AnalyzerConfig{
+		// Numbers decoded from JSON are float64
+		"embedding": {Parameters: map[string]interface{}{"kmeans_seed": 7.0, "include_embeddings": true}},
+		"watermark": {Disabled: true},
+	}
+	if err := detector.ConfigureAnalyzers(configured); err != nil {
+		t.Fatalf("Expected the parameters to apply: %v", err)
+	}
+
+	configs := detector.AnalyzerConfigs(configured)
+	if !configs["watermark"].Disabled {
+		t.Error("Expected the disabled analyzer to be kept")
+	}
+	parameters := configs["embedding"].Parameters
+	if parameters["kmeans_seed"] != 7.0 || parameters["kmeans_algorithm"] != embedding.KMeansFull {
+		t.Errorf("Expected the configured values over the defaults, got %v", parameters)
+	}
+	if !reflect.DeepEqual(configs["linguistic"].Parameters, map[string]interface{}{"language": "auto"}) {
+		t.Errorf("Expected the linguistic defaults, got %v", configs["linguistic"].Parameters)
+	}
+
+	err := detector.ConfigureAnalyzers(map[string]config.AnalyzerConfig{
+		"embedding": {Parameters: map[string]interface{}{"kmeans_seed": 7.5}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "embedding.kmeans_seed") {
+		t.Errorf("Expected a fractional seed to be rejected, got %v", err)
+	}
+}
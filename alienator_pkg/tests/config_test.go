@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestConfigDefaultsAreValid(t *testing.T) {
+	if err := config.Defaults().Validate(); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+}
+
+func TestLoadFileLayers(t *testing.T) {
+	path := writeConfigFile(t, "alienator.yaml", `
+server:
+  port: 9090
+  read_timeout: 15s
+detector:
+  confidence:
+    aggregation: min
+logging:
+  level: debug
+`)
+	t.Setenv("PORT", "9191")
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	if cfg.Server.Port != 9191 {
+		t.Errorf("Expected the environment to override the file port, got %d", cfg.Server.Port)
+	}
+	if cfg.Server.ReadTimeout != 15*time.Second {
+		t.Errorf("Expected read timeout 15s from the file, got %s", cfg.Server.ReadTimeout)
+	}
+	if cfg.Detector.Confidence.Aggregation != "min" {
+		t.Errorf("Expected aggregation min from the file, got %q", cfg.Detector.Confidence.Aggregation)
+	}
+	if cfg.Logging.Level != "debug" {
+		t.Errorf("Expected log level debug from the file, got %q", cfg.Logging.Level)
+	}
+	if cfg.Server.WriteTimeout != config.Defaults().Server.WriteTimeout {
+		t.Errorf("Expected the default write timeout, got %s", cfg.Server.WriteTimeout)
+	}
+}
+
+func TestLoadFileJSON(t *testing.T) {
+	path := writeConfigFile(t, "alienator.json", `{"redis": {"port": 6380, "db": 2}}`)
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Redis.Port != 6380 || cfg.Redis.DB != 2 {
+		t.Errorf("Expected redis port 6380 and db 2, got %d and %d", cfg.Redis.Port, cfg.Redis.DB)
+	}
+}
+
+func TestLoadFileReportsEveryProblem(t *testing.T) {
+	path := writeConfigFile(t, "alienator.yaml", `
+server:
+  port: 70000
+  idle_timeout: 60
+detector:
+  severity:
+    low: 0.8
+telemetry: true
+`)
+	t.Setenv("REDIS_PORT", "six")
+
+	_, err := config.LoadFile(path)
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a *config.ValidationError, got %v", err)
+	}
+
+	expected := []string{
+		"server.port:",
+		"server.idle_timeout:",
+		"detector.severity.medium:",
+		"telemetry: unknown key",
+		"REDIS_PORT:",
+	}
+	for _, prefix := range expected {
+		found := false
+		for _, problem := range validationErr.Problems {
+			if strings.HasPrefix(problem, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected a problem starting with %q, got %v", prefix, validationErr.Problems)
+		}
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	_, err := config.LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil {
+		t.Fatal("Expected an error for a missing file")
+	}
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		t.Errorf("Expected a read error rather than a validation error, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestClassifyContent(t *testing.T) {
+	cases := map[string]core.ContentType{
+		"The quick brown fox jumps over the lazy dog. It was a sunny day and everyone was happy.":                               core.ContentTypeProse,
+		"package main\n\nfunc main() {\n\tx := 1\n\tif x == 1 {\n\t\treturn\n\t}\n}\n":                                          core.ContentTypeCode,
+		`{"id": 1, "name": "alienator", "tags": ["a", "b"]}`:                                                                    core.ContentTypeData,
+		"id,name,score\n1,alpha,0.5\n2,beta,0.7\n3,gamma,0.9\n":                                                                 core.ContentTypeData,
+		"2024-01-01T10:00:00Z INFO server started\n2024-01-01T10:00:01Z WARN slow request\n2024-01-01T10:00:02Z ERROR failed\n": core.ContentTypeLog,
+	}
+
+	for text, expected := range cases {
+		got, confidence := core.ClassifyContent(text)
+		if got != expected {
+			t.Errorf("ClassifyContent(%q) = %s, expected %s", text, got, expected)
+		}
+		if confidence < 0.5 || confidence > 1 {
+			t.Errorf("ClassifyContent(%q) confidence %f outside [0.5, 1]", text, confidence)
+		}
+	}
+}
+
+func TestClassifyContentConfidence(t *testing.T) {
+	if contentType, confidence := core.ClassifyContent("   "); contentType != core.ContentTypeProse || confidence != 0.5 {
+		t.Errorf("Expected empty text to be prose at 0.5, got %s at %f", contentType, confidence)
+	}
+
+	// One code-like line in four sits just below the code share, so the
+	// prose guess is barely better than a coin flip
+	borderline := "The build failed again this morning.\nWe checked the logs and found nothing.\nThe fix was a single line:\nreturn nil\n"
+	_, clearProse := core.ClassifyContent("The quick brown fox jumps over the lazy dog. It was a sunny day and everyone was happy.")
+	contentType, borderlineProse := core.ClassifyContent(borderline)
+	if contentType != core.ContentTypeProse || borderlineProse >= clearProse {
+		t.Errorf("Expected borderline prose (%s, %f) below clear prose (%f)", contentType, borderlineProse, clearProse)
+	}
+
+	code := "package main\n\nfunc main() {\n\tx := 1\n\tif x == 1 {\n\t\treturn\n\t}\n}\n"
+	if contentType, confidence := core.ClassifyContent(code); contentType != core.ContentTypeCode || confidence <= 0.75 {
+		t.Errorf("Expected code with a code-like line on almost every line to be confident, got %s at %f", contentType, confidence)
+	}
+}
+
+func TestContentTypeConfidenceMetadata(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&lengthAnalyzer{name: "length", scale: 1})
+	code := "package main\n\nfunc main() {\n\tx := 1\n\tif x == 1 {\n\t\treturn\n\t}\n}\n"
+
+	result, err := detector.AnalyzeTextAs(code, core.ContentTypeAuto)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	_, expected := core.ClassifyContent(code)
+	if result.Metadata["content_type"] != "code" || result.Metadata["content_type_confidence"] != expected {
+		t.Errorf("Expected code detected at %f, got %v", expected, result.Metadata)
+	}
+
+	// A content type given by the caller isn't a guess
+	result, err = detector.AnalyzeTextAs(code, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if _, ok := result.Metadata["content_type_confidence"]; ok {
+		t.Errorf("Expected no classification confidence for a forced content type, got %v", result.Metadata)
+	}
+}
+
+func TestParseContentType(t *testing.T) {
+	if ct, err := core.ParseContentType(""); err != nil || ct != core.ContentTypeAuto {
+		t.Errorf("Expected empty content type to mean auto, got %s (%v)", ct, err)
+	}
+	if ct, err := core.ParseContentType("Code"); err != nil || ct != core.ContentTypeCode {
+		t.Errorf("Expected code content type, got %s (%v)", ct, err)
+	}
+	if _, err := core.ParseContentType("spreadsheet"); err == nil {
+		t.Error("Expected an error for an unsupported content type")
+	}
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// markerAnalyzer flags text containing a marker phrase, so tests control which
+// turns look machine-generated
+type markerAnalyzer struct {
+	marker string
+}
+
+func (a *markerAnalyzer) Name() string { return "marker" }
+
+func (a *markerAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	score := 0.1
+	if strings.Contains(text, a.marker) {
+		score = 0.95
+	}
+	return &models.AnalysisResult{Score: score, Confidence: 0.9}, nil
+}
+
+func TestParseTranscript(t *testing.T) {
+	transcript := "user: Hi there, can you help?\nassistant: Of course.\nHere is a link: https://example.com\n\nAgent 2: Thanks!\n"
+
+	turns, err := core.ParseTranscript(transcript)
+	if err != nil {
+		t.Fatalf("Failed to parse transcript: %v", err)
+	}
+
+	expected := []core.Turn{
+		{Role: "user", Text: "Hi there, can you help?"},
+		{Role: "assistant", Text: "Of course.\nHere is a link: https://example.com"},
+		{Role: "Agent 2", Text: "Thanks!"},
+	}
+	if !reflect.DeepEqual(turns, expected) {
+		t.Errorf("Unexpected turns: %+v", turns)
+	}
+
+	if _, err := core.ParseTranscript("no speaker on this line"); err == nil {
+		t.Error("Expected an error for a transcript without turns")
+	}
+}
+
+func TestAnalyzeConversationAttributesSpeakers(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "As an AI"})
+
+	turns := []core.Turn{
+		{Role: "alice", Text: "Did you see the game last night? Unbelievable finish."},
+		{Role: "bob", Text: "As an AI, I do not watch games, but I can summarize the result for you."},
+		{Role: "alice", Text: "Ha, never mind then."},
+		{Role: "bob", Text: ""},
+		{Role: "bob", Text: "As an AI, I am happy to help with anything else you need today."},
+	}
+
+	conversation, err := detector.AnalyzeConversation(turns)
+	if err != nil {
+		t.Fatalf("Conversation analysis failed: %v", err)
+	}
+
+	if len(conversation.Turns) != 4 {
+		t.Errorf("Expected the empty turn to be skipped, got %d turns", len(conversation.Turns))
+	}
+	if last := conversation.Turns[len(conversation.Turns)-1]; last.Index != 4 || !last.Result.IsAnomalous {
+		t.Errorf("Expected turn 4 to be flagged, got index %d anomalous %v", last.Index, last.Result.IsAnomalous)
+	}
+
+	bob := conversation.Speakers["bob"]
+	if bob == nil || bob.Turns != 2 || bob.AnomalousTurns != 2 || !bob.IsAnomalous {
+		t.Errorf("Expected bob to be flagged on both turns, got %+v", bob)
+	}
+	if alice := conversation.Speakers["alice"]; alice == nil || alice.IsAnomalous {
+		t.Errorf("Expected alice not to be flagged, got %+v", alice)
+	}
+	if !reflect.DeepEqual(conversation.AnomalousSpeakers, []string{"bob"}) {
+		t.Errorf("Expected only bob to be reported, got %v", conversation.AnomalousSpeakers)
+	}
+
+	aggregate := conversation.Aggregate
+	if aggregate.Score <= conversation.Speakers["alice"].Score || aggregate.Score >= bob.Score {
+		t.Errorf("Expected the aggregate score to lie between the speakers, got %f", aggregate.Score)
+	}
+}
+
+func TestAnalyzeConversationUsesProseThreshold(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "As an AI"})
+	thresholds := core.DefaultDecisionThresholds()
+	thresholds[core.ContentTypeProse] = 0.97
+	if err := detector.SetDecisionThresholds(thresholds); err != nil {
+		t.Fatalf("SetDecisionThresholds failed: %v", err)
+	}
+
+	conversation, err := detector.AnalyzeConversation([]core.Turn{
+		{Role: "alice", Text: "Did you see the game last night? Unbelievable finish."},
+		{Role: "bob", Text: "As an AI, I do not watch games, but I can summarize the result for you."},
+	})
+	if err != nil {
+		t.Fatalf("Conversation analysis failed: %v", err)
+	}
+	if bob := conversation.Speakers["bob"]; bob.IsAnomalous || len(conversation.AnomalousSpeakers) != 0 {
+		t.Errorf("Expected bob's score %.2f to pass the prose threshold of 0.97, got %+v", bob.Score, bob)
+	}
+	if aggregate := conversation.Aggregate; aggregate.IsAnomalous || aggregate.Metadata["threshold"] != 0.97 {
+		t.Errorf("Expected the aggregate judged at 0.97, got %v at %v", aggregate.IsAnomalous, aggregate.Metadata["threshold"])
+	}
+}
+
+func TestAnalyzeConversationRejectsEmpty(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "As an AI"})
+
+	if _, err := detector.AnalyzeConversation([]core.Turn{{Role: "user", Text: "  "}}); err == nil {
+		t.Error("Expected an error for a conversation without text")
+	}
+}
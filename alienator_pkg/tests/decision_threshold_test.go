@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestDecisionThresholdsPerContentType(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.75, confidence: 0.9})
+
+	thresholds := core.DefaultDecisionThresholds()
+	thresholds[core.ContentTypeCode] = 0.8
+	if err := detector.SetDecisionThresholds(thresholds); err != nil {
+		t.Fatalf("Expected the thresholds to be accepted: %v", err)
+	}
+
+	prose, err := detector.AnalyzeTextAs("some text", core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if !prose.IsAnomalous || prose.Metadata["threshold"] != 0.7 {
+		t.Errorf("Expected score %.2f to be anomalous as prose at 0.7, got %v at %v", prose.Score, prose.IsAnomalous, prose.Metadata["threshold"])
+	}
+
+	code, err := detector.AnalyzeTextAs("some text", core.ContentTypeCode)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if code.IsAnomalous || code.Metadata["threshold"] != 0.8 {
+		t.Errorf("Expected score %.2f to pass as code at 0.8, got %v at %v", code.Score, code.IsAnomalous, code.Metadata["threshold"])
+	}
+
+	thresholds[core.ContentTypeLog] = 1
+	if err := detector.SetDecisionThresholds(thresholds); err == nil {
+		t.Error("Expected a threshold of 1 to be rejected")
+	}
+	if err := detector.SetDecisionThresholds(core.DecisionThresholds{"binary": 0.5}); err == nil {
+		t.Error("Expected an unknown content type to be rejected")
+	}
+}
+
+func TestDecisionThresholdsConfig(t *testing.T) {
+	path := writeConfigFile(t, "alienator.yaml", `
+detector:
+  thresholds:
+    code: 0.9
+`)
+	t.Setenv("DETECTOR_THRESHOLD_DATA", "0.85")
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	expected := config.ThresholdsConfig{Prose: 0.7, Code: 0.9, Data: 0.85, Log: 0.7}
+	if cfg.Detector.Thresholds != expected {
+		t.Errorf("Expected thresholds %+v, got %+v", expected, cfg.Detector.Thresholds)
+	}
+
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	before := detector.Fingerprint()
+	if err := detector.ApplyConfig(cfg.Detector); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	if detector.Fingerprint() == before {
+		t.Error("Expected the thresholds to change the detector fingerprint")
+	}
+
+	cfg.Detector.Thresholds.Log = 0
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "detector.thresholds.log:") {
+		t.Errorf("Expected a zero log threshold to be reported, got %v", err)
+	}
+	if err := detector.ApplyConfig(cfg.Detector); err == nil {
+		t.Error("Expected ApplyConfig to reject a zero threshold")
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models/proto"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+func anomalyMessageData(t *testing.T, severity string, score float64) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{
+		"type":     "threshold",
+		"severity": severity,
+		"score":    score,
+		"source":   "threshold-monitor",
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode anomaly: %v", err)
+	}
+	return data
+}
+
+func TestAnomalyFingerprint(t *testing.T) {
+	base := services.AnomalyFingerprint("threshold", "high", 0.912, "monitor")
+	if services.AnomalyFingerprint("threshold", "high", 0.908, "monitor") != base {
+		t.Error("Expected scores equal to two decimals to share a fingerprint")
+	}
+	if services.AnomalyFingerprint("threshold", "high", 0.95, "monitor") == base {
+		t.Error("Expected a different score to change the fingerprint")
+	}
+	if services.AnomalyFingerprint("threshold", "critical", 0.912, "monitor") == base {
+		t.Error("Expected a different severity to change the fingerprint")
+	}
+}
+
+func TestDeduplicatorWindow(t *testing.T) {
+	dedup := services.NewDeduplicator(time.Minute)
+	start := time.Now()
+
+	if _, duplicate := dedup.Observe("fp", "a", start); duplicate {
+		t.Fatal("Expected the first occurrence to be kept")
+	}
+	occurrence, duplicate := dedup.Observe("fp", "b", start.Add(30*time.Second))
+	if !duplicate || occurrence.Count != 2 || occurrence.FirstID != "a" {
+		t.Errorf("Expected a duplicate of a counted twice, got %+v (duplicate %v)", occurrence, duplicate)
+	}
+	if _, duplicate := dedup.Observe("fp", "c", start.Add(2*time.Minute)); duplicate {
+		t.Error("Expected an occurrence after the window to be kept")
+	}
+}
+
+func TestProcessingServiceSuppressesDuplicates(t *testing.T) {
+	eventBus := core.NewEventBus(core.DefaultEventBusConfig(), zap.NewNop())
+	t.Cleanup(func() { eventBus.Close() })
+
+	// The bus only keeps history for event types with a subscriber
+	if _, err := eventBus.Subscribe("anomaly.suppressed", func(ctx context.Context, event *proto.Event) error { return nil }); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	store := &occurrenceStore{saved: make(map[string]services.Occurrence)}
+	service := services.NewProcessingService(nil, eventBus, zap.NewNop())
+	service.SetSuppressionWindow(time.Minute)
+	service.SetOccurrenceStore(store)
+
+	ctx := context.Background()
+	kept := 0
+	for i := 0; i < 5; i++ {
+		msg := &proto.Message{ID: fmt.Sprintf("m%d", i), Data: anomalyMessageData(t, "high", 0.9)}
+		processed, err := service.ProcessMessage(ctx, msg)
+		if err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+		if processed != nil {
+			kept++
+			if processed.Headers["occurrence_count"] != "1" || processed.Headers["fingerprint"] == "" {
+				t.Errorf("Expected fingerprint headers on the kept message, got %v", processed.Headers)
+			}
+		}
+	}
+	if kept != 1 {
+		t.Errorf("Expected 1 kept message, got %d", kept)
+	}
+
+	// A different severity is a different anomaly, and non-anomaly messages
+	// are never de-duplicated
+	for _, data := range [][]byte{anomalyMessageData(t, "critical", 0.9), []byte(`{"text": "hello"}`), []byte(`{"text": "hello"}`)} {
+		processed, err := service.ProcessMessage(ctx, &proto.Message{ID: "other", Data: data})
+		if err != nil || processed == nil {
+			t.Errorf("Expected %s to be processed, got %v (err %v)", data, processed, err)
+		}
+	}
+
+	if suppressed := service.GetMetrics().TotalSuppressed; suppressed != 4 {
+		t.Errorf("Expected 4 suppressed duplicates, got %d", suppressed)
+	}
+	occurrences := service.GetOccurrences()
+	if len(occurrences) != 2 || occurrences[0].Count != 5 || occurrences[0].FirstID != "m0" {
+		t.Errorf("Expected the first fingerprint counted 5 times, got %+v", occurrences)
+	}
+
+	events, err := eventBus.GetHistory("anomaly.suppressed", 10)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 suppression events, got %d", len(events))
+	}
+	if count := events[len(events)-1].Data["occurrence_count"]; count != 5 {
+		t.Errorf("Expected the last event to report 5 occurrences, got %v", count)
+	}
+
+	// The kept anomaly's stored record counts its duplicates
+	if stored := store.get("m0"); stored.Count != 5 || stored.Fingerprint != occurrences[0].Fingerprint {
+		t.Errorf("Expected the kept anomaly stored with 5 occurrences, got %+v", stored)
+	}
+	if stored := store.get("other"); stored.Count != 1 {
+		t.Errorf("Expected the other anomaly stored once, got %+v", stored)
+	}
+	if store.saves != 6 {
+		t.Errorf("Expected a save for each of the 6 anomalies, got %d", store.saves)
+	}
+}
+
+// occurrenceStore keeps saved occurrences in memory, by the ID of the kept
+// anomaly, as a services.OccurrenceStore
+type occurrenceStore struct {
+	mu    sync.Mutex
+	saved map[string]services.Occurrence
+	saves int
+}
+
+func (s *occurrenceStore) SaveOccurrence(ctx context.Context, occurrence services.Occurrence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saves++
+	if stored, ok := s.saved[occurrence.FirstID]; !ok || stored.Count <= occurrence.Count {
+		s.saved[occurrence.FirstID] = occurrence
+	}
+	return nil
+}
+
+func (s *occurrenceStore) get(id string) services.Occurrence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saved[id]
+}
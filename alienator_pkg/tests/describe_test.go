@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/analyzers/claims"
+	"github.com/ruvnet/alienator/internal/analyzers/compression"
+	"github.com/ruvnet/alienator/internal/analyzers/cryptographic"
+	"github.com/ruvnet/alienator/internal/analyzers/embedding"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/analyzers/injection"
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/analyzers/repetition"
+	"github.com/ruvnet/alienator/internal/analyzers/sentiment"
+	"github.com/ruvnet/alienator/internal/analyzers/uniformity"
+	"github.com/ruvnet/alienator/internal/analyzers/watermark"
+	"github.com/ruvnet/alienator/internal/api/rest"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// configurable is the Configure contract the parameter schema describes
+type configurable interface {
+	Configure(config map[string]interface{}) error
+}
+
+func TestAnalyzerParametersMatchConfigure(t *testing.T) {
+	analyzers := []core.Analyzer{
+		linguistic.NewLinguisticAnalyzer(),
+		cryptographic.NewCryptographicAnalyzer(),
+		entropy.NewEntropyAnalyzer(),
+		compression.NewCompressionAnalyzer(),
+		uniformity.NewUniformityAnalyzer(),
+		repetition.NewRepetitionAnalyzer(),
+		sentiment.NewSentimentAnalyzer(),
+		claims.NewClaimsAnalyzer(),
+		embedding.NewEmbeddingAnalyzer(),
+		watermark.NewWatermarkAnalyzer(),
+		injection.NewInjectionAnalyzer(),
+	}
+
+	for _, analyzer := range analyzers {
+		describer, ok := analyzer.(core.Describer)
+		if !ok {
+			t.Errorf("Expected %s to describe itself", analyzer.Name())
+			continue
+		}
+		if describer.Description() == "" {
+			t.Errorf("Expected %s to have a description", analyzer.Name())
+		}
+
+		defaults := make(map[string]interface{})
+		for _, parameter := range describer.Parameters() {
+			switch parameter.Type {
+			case models.ParameterString, models.ParameterInteger, models.ParameterNumber, models.ParameterBoolean:
+			default:
+				t.Errorf("%s.%s: unknown parameter type %q", analyzer.Name(), parameter.Name, parameter.Type)
+			}
+			if parameter.Default != nil {
+				defaults[parameter.Name] = parameter.Default
+			}
+		}
+
+		configurable, ok := analyzer.(configurable)
+		if !ok {
+			if len(defaults) > 0 {
+				t.Errorf("Expected %s to list no parameters without a Configure method", analyzer.Name())
+			}
+			continue
+		}
+		// Every documented default must be accepted as it is
+		if err := configurable.Configure(defaults); err != nil {
+			t.Errorf("Expected %s to accept its documented defaults, got %v", analyzer.Name(), err)
+		}
+	}
+}
+
+func TestListAnalyzers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(uniformity.NewUniformityAnalyzer())
+	detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+	detector.RegisterAnalyzer(&warmingAnalyzer{})
+	handler := rest.NewHandler(detector, nil, nil, nil, zap.NewNop(), zap.NewAtomicLevel())
+
+	router := gin.New()
+	router.GET("/analyzers", handler.ListAnalyzers)
+
+	list := func() map[string]models.AnalyzerInfo {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/analyzers", nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", recorder.Code)
+		}
+		var response struct {
+			Data []models.AnalyzerInfo `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		infos := make(map[string]models.AnalyzerInfo, len(response.Data))
+		for _, info := range response.Data {
+			infos[info.Name] = info
+		}
+		if len(response.Data) != 3 || response.Data[0].Name != "entropy" {
+			t.Errorf("Expected the registered analyzers sorted by name, got %+v", response.Data)
+		}
+		return infos
+	}
+
+	infos := list()
+	uniform := infos["uniformity"]
+	if !uniform.Enabled || uniform.Weights["prose"] != 1.0 || uniform.Weights["code"] != 0 {
+		t.Errorf("Expected uniformity weights from the content profiles, got %+v", uniform)
+	}
+	if !uniform.Ready || uniform.Description == "" || len(uniform.Parameters) == 0 {
+		t.Errorf("Expected uniformity to be ready and described, got %+v", uniform)
+	}
+	if infos["entropy"].Parameters == nil || len(infos["entropy"].Parameters) != 0 {
+		t.Errorf("Expected entropy to list no parameters, got %v", infos["entropy"].Parameters)
+	}
+	if warming := infos["warming"]; warming.Ready || warming.Description != "" {
+		t.Errorf("Expected an undescribed analyzer that needs warming to be listed as not ready, got %+v", warming)
+	}
+
+	if err := detector.Warmup(context.Background()); err != nil {
+		t.Fatalf("Warm-up failed: %v", err)
+	}
+	if !list()["warming"].Ready {
+		t.Error("Expected the analyzer to be ready after warm-up")
+	}
+}
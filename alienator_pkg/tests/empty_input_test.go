@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/analyzers/compression"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/api"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+var emptyInputs = []string{"", "   ", "...", "\n\t", "?! -- !?"}
+
+func newEmptyInputDetector() *core.AnomalyDetector {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+	detector.RegisterAnalyzer(linguistic.NewLinguisticAnalyzer())
+	detector.RegisterAnalyzer(compression.NewCompressionAnalyzer())
+	return detector
+}
+
+func TestDetectorEmptyInput(t *testing.T) {
+	detector := newEmptyInputDetector()
+
+	for _, text := range emptyInputs {
+		result, err := detector.AnalyzeText(text)
+		if err != nil {
+			t.Errorf("Expected no error for %q, got %v", text, err)
+			continue
+		}
+		if result.Outcome != models.OutcomeEmptyInput {
+			t.Errorf("Expected empty input outcome for %q, got %q", text, result.Outcome)
+		}
+		if result.Score != 0 || result.Confidence != 0 || result.IsAnomalous || result.Severity != models.SeverityNone {
+			t.Errorf("Expected a zero result for %q, got %+v", text, result)
+		}
+		if flagged, _ := result.Metadata["empty_input"].(bool); !flagged {
+			t.Errorf("Expected the empty_input metadata flag for %q", text)
+		}
+	}
+
+	result, err := detector.AnalyzeText("Hello world.")
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if result.Outcome != models.OutcomeAnalyzed {
+		t.Errorf("Expected analyzed outcome for real text, got %q", result.Outcome)
+	}
+}
+
+func TestAnalyzeEndpointEmptyInput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.NewHandler(newEmptyInputDetector(), zap.NewNop()).SetupRoutes(router)
+
+	for _, text := range emptyInputs {
+		body, _ := json.Marshal(map[string]string{"text": text})
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/analyze", strings.NewReader(string(body))))
+
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for %q, got %d", text, recorder.Code)
+			continue
+		}
+		var response models.AnalysisResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		if response.Result == nil || response.Result.Outcome != models.OutcomeEmptyInput || response.Result.Score != 0 {
+			t.Errorf("Expected a zero empty input result for %q, got %+v", text, response.Result)
+		}
+	}
+}
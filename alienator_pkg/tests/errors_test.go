@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/services"
+)
+
+func TestServiceErrorTaxonomy(t *testing.T) {
+	cases := []struct {
+		err      error
+		category error
+	}{
+		{services.ErrUserNotFound, apperrors.ErrNotFound},
+		{services.ErrAnomalyNotFound, apperrors.ErrNotFound},
+		{services.ErrEmailTaken, apperrors.ErrConflict},
+		{services.ErrUserDisabled, apperrors.ErrForbidden},
+		{services.ErrInvalidCredentials, apperrors.ErrUnauthorized},
+		{services.ErrTokenExpired, apperrors.ErrUnauthorized},
+		{&services.InputError{Reason: "too short"}, apperrors.ErrInvalidInput},
+	}
+
+	for _, tc := range cases {
+		wrapped := fmt.Errorf("handler context: %w", tc.err)
+		if !errors.Is(wrapped, tc.category) {
+			t.Errorf("Expected %q to match category %q", tc.err, tc.category)
+		}
+		if !errors.Is(wrapped, tc.err) {
+			t.Errorf("Expected wrapped error to preserve cause %q", tc.err)
+		}
+	}
+
+	if errors.Is(services.ErrUserNotFound, apperrors.ErrConflict) {
+		t.Error("ErrUserNotFound must not match ErrConflict")
+	}
+	if msg := (&services.InputError{Reason: "password too short"}).Error(); msg != "password too short" {
+		t.Errorf("InputError message should be the bare reason, got %q", msg)
+	}
+}
+
+func TestErrorCategoryCodes(t *testing.T) {
+	cases := []struct {
+		err    error
+		code   apperrors.ErrorCode
+		status int
+	}{
+		{services.ErrUserNotFound, apperrors.NotFound, http.StatusNotFound},
+		{services.ErrProfileExists, apperrors.Conflict, http.StatusConflict},
+		{&services.InputError{Reason: "too short"}, apperrors.BadRequest, http.StatusBadRequest},
+		{services.ErrInvalidToken, apperrors.Unauthorized, http.StatusUnauthorized},
+		{services.ErrPermissionDenied, apperrors.Forbidden, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		code, ok := apperrors.CodeOf(fmt.Errorf("handler context: %w", tc.err))
+		if !ok || code != tc.code {
+			t.Errorf("Expected %q to have code %s, got %s", tc.err, tc.code, code)
+		}
+		if status := code.HTTPStatus(); status != tc.status {
+			t.Errorf("Expected %q to map to status %d, got %d", tc.err, tc.status, status)
+		}
+	}
+
+	if code, ok := apperrors.CodeOf(errors.New("connection reset")); ok {
+		t.Errorf("Expected an uncategorized error to have no code, got %s", code)
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/analyzers/compression"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/api/rest"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/pkg/detector"
+	"go.uber.org/zap"
+)
+
+func TestEvaluateMetrics(t *testing.T) {
+	predictions := []float64{0.9, 0.8, 0.75, 0.2, 0.1, 0.95, 0.3, 0.6}
+	labels := []bool{true, true, false, false, false, true, true, false}
+
+	m := detector.Evaluate(predictions, labels)
+
+	if m.TotalPredictions != 8 || m.TruePositives != 3 || m.FalsePositives != 1 || m.TrueNegatives != 3 || m.FalseNegatives != 1 {
+		t.Fatalf("Unexpected confusion matrix: %+v", m)
+	}
+	if math.Abs(m.Precision-0.75) > 1e-9 || math.Abs(m.Recall-0.75) > 1e-9 {
+		t.Errorf("Expected precision and recall of 0.75, got %f and %f", m.Precision, m.Recall)
+	}
+	if math.Abs(m.MCC-0.5) > 1e-9 {
+		t.Errorf("Expected MCC of 0.5, got %f", m.MCC)
+	}
+
+	total := 0
+	for _, bin := range m.Calibration {
+		total += bin.Count
+	}
+	if total != len(predictions) {
+		t.Errorf("Calibration bins cover %d samples, expected %d", total, len(predictions))
+	}
+}
+
+func TestEvaluateImbalancedMCC(t *testing.T) {
+	// Always predicting the majority class looks accurate but carries no signal
+	predictions := make([]float64, 100)
+	labels := make([]bool, 100)
+	for i := 0; i < 5; i++ {
+		labels[i] = true
+	}
+
+	m := detector.Evaluate(predictions, labels)
+
+	if m.Accuracy != 0.95 {
+		t.Errorf("Expected accuracy of 0.95, got %f", m.Accuracy)
+	}
+	if m.MCC != 0 {
+		t.Errorf("Expected MCC of 0 for a constant predictor, got %f", m.MCC)
+	}
+}
+
+func TestDetectorSelfTest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ad := core.NewAnomalyDetector(zap.NewNop(), nil)
+	ad.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+	ad.RegisterAnalyzer(compression.NewCompressionAnalyzer())
+	handler := rest.NewHandler(ad, nil, nil, nil, zap.NewNop(), zap.NewAtomicLevel())
+
+	router := gin.New()
+	router.GET("/selftest", handler.DetectorSelfTest)
+
+	selftest := func(query string) (int, detector.Metrics) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/selftest"+query, nil))
+		var response struct {
+			Data detector.Metrics `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		return recorder.Code, response.Data
+	}
+
+	code, m := selftest("")
+	if code != http.StatusOK || m.Threshold != detector.DefaultEvaluationThreshold {
+		t.Fatalf("Expected the self-test at the default threshold, got %d with %+v", code, m)
+	}
+	if m.TotalPredictions != int64(len(core.SelfTestSamples)) || m.TruePositives+m.FalseNegatives != 4 {
+		t.Errorf("Expected every sample of the corpus scored, 4 of them anomalous, got %+v", m.ModelMetrics)
+	}
+
+	// Scores above a threshold of 0 are all anomalous
+	code, m = selftest("?threshold=0")
+	if code != http.StatusOK || m.TruePositives != 4 || m.FalsePositives != 6 || m.Recall != 1 {
+		t.Errorf("Expected every sample flagged at a threshold of 0, got %d with %+v", code, m.ModelMetrics)
+	}
+
+	if code, _ := selftest("?threshold=high"); code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid threshold to be rejected, got %d", code)
+	}
+}
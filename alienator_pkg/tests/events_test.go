@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// scoringAnalyzer returns a fixed score so tests control whether text is anomalous
+type scoringAnalyzer struct {
+	score float64
+}
+
+func (a *scoringAnalyzer) Name() string { return "scoring" }
+
+func (a *scoringAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return &models.AnalysisResult{Score: a.score, Confidence: 0.9}, nil
+}
+
+// subscribe subscribes handler to the typed events of topic on bus
+func subscribe(t *testing.T, bus *core.InMemoryEventBus, topic string, handler func(core.Event)) (unsubscribe func()) {
+	t.Helper()
+	unsubscribe, err := bus.SubscribeEvents(topic, handler)
+	if err != nil {
+		t.Fatalf("SubscribeEvents failed: %v", err)
+	}
+	return unsubscribe
+}
+
+func TestDetectorPublishesEvents(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&scoringAnalyzer{score: 0.9})
+
+	completed := make(chan core.Event, 1)
+	detected := make(chan core.Event, 1)
+	defer subscribe(t, detector.Events(), core.TopicAnalysisCompleted, func(e core.Event) { completed <- e })()
+	defer subscribe(t, detector.Events(), core.TopicAnomalyDetected, func(e core.Event) { detected <- e })()
+
+	result, err := detector.AnalyzeText("An unusual text.")
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+
+	select {
+	case e := <-completed:
+		event, ok := e.(core.AnalysisCompletedEvent)
+		if !ok || event.Result != result {
+			t.Errorf("Expected completion event carrying the result, got %#v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for analysis completed event")
+	}
+
+	select {
+	case e := <-detected:
+		if _, ok := e.(core.AnomalyDetectedEvent); !ok {
+			t.Errorf("Expected anomaly detected event, got %#v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for anomaly detected event")
+	}
+}
+
+func TestEventBusSlowSubscriber(t *testing.T) {
+	config := core.DefaultEventBusConfig()
+	config.EnableHistory = false
+	bus := core.NewEventBus(config, zap.NewNop())
+
+	release := make(chan struct{})
+	subscribe(t, bus, core.TopicAnalysisCompleted, func(core.Event) { <-release })
+
+	received := make(chan core.Event, 10)
+	unsubscribe := subscribe(t, bus, core.TopicAnalysisCompleted, func(e core.Event) { received <- e })
+	// A panicking subscriber is contained
+	subscribe(t, bus, core.TopicAnalysisCompleted, func(core.Event) { panic("faulty subscriber") })
+
+	for i := 0; i < 3; i++ {
+		bus.PublishEvent(core.TopicAnalysisCompleted, core.AnalysisCompletedEvent{})
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case e := <-received:
+			if _, ok := e.(core.AnalysisCompletedEvent); !ok {
+				t.Errorf("Expected the typed event published, got %#v", e)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("A blocked subscriber stalled delivery to other subscribers")
+		}
+	}
+
+	unsubscribe()
+	unsubscribe()
+	bus.PublishEvent(core.TopicAnalysisCompleted, core.AnalysisCompletedEvent{})
+	select {
+	case e := <-received:
+		t.Errorf("Received event after unsubscribing: %#v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Unsubscribing once the bus is closed does nothing
+	late := subscribe(t, bus, core.TopicAnomalyDetected, func(core.Event) {})
+	close(release)
+	bus.Close()
+	late()
+}
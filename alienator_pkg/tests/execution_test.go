@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// slowAnalyzer takes delay to score, giving up early if ctx is done, and
+// records the order analyzers start in
+type slowAnalyzer struct {
+	name    string
+	delay   time.Duration
+	mu      *sync.Mutex
+	started *[]string
+}
+
+func (a *slowAnalyzer) Name() string { return a.name }
+
+func (a *slowAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	a.mu.Lock()
+	*a.started = append(*a.started, a.name)
+	a.mu.Unlock()
+
+	select {
+	case <-time.After(a.delay):
+		return &models.AnalysisResult{Score: 0.5, Confidence: 0.5}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newBudgetDetector registers the analyzers in the given order with the given
+// delays and returns the detector and the record of starts
+func newBudgetDetector(delays map[string]time.Duration, names ...string) (*core.AnomalyDetector, func() []string) {
+	var mu sync.Mutex
+	started := make([]string, 0)
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	for _, name := range names {
+		detector.RegisterAnalyzer(&slowAnalyzer{name: name, delay: delays[name], mu: &mu, started: &started})
+	}
+	return detector, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), started...)
+	}
+}
+
+func TestExecutionBudget(t *testing.T) {
+	delays := map[string]time.Duration{"embedding": time.Second}
+	detector, started := newBudgetDetector(delays, "embedding", "entropy", "linguistic", "cryptographic")
+	if err := detector.SetExecutionPlan(core.ExecutionPlan{
+		Order:  []string{"linguistic", "cryptographic", "entropy", "embedding"},
+		Budget: 100 * time.Millisecond,
+	}); err != nil {
+		t.Fatalf("SetExecutionPlan failed: %v", err)
+	}
+
+	start := time.Now()
+	result, err := detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the budget to bound the analysis, took %s", elapsed)
+	}
+
+	if got := started(); !reflect.DeepEqual(got, []string{"linguistic", "cryptographic", "entropy", "embedding"}) {
+		t.Errorf("Expected analyzers to start in the configured order, got %v", got)
+	}
+	if skipped := result.Metadata["skipped_analyzers"]; !reflect.DeepEqual(skipped, []string{"embedding"}) {
+		t.Errorf("Expected the slow analyzer to be reported as skipped, got %v", skipped)
+	}
+	if _, ok := result.Details["embedding"]; ok || len(result.Details) != 3 {
+		t.Errorf("Expected only the analyzers that finished in time to be scored, got %v", result.Details)
+	}
+	if result.Metadata["budget_ms"] != int64(100) {
+		t.Errorf("Expected the budget in the metadata, got %v", result.Metadata["budget_ms"])
+	}
+}
+
+func TestExecutionBudgetSkipsAfterExhaustion(t *testing.T) {
+	delays := map[string]time.Duration{"linguistic": 50 * time.Millisecond}
+	detector, started := newBudgetDetector(delays, "linguistic", "entropy", "embedding")
+	if err := detector.SetExecutionPlan(core.ExecutionPlan{Order: []string{"linguistic"}, Budget: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("SetExecutionPlan failed: %v", err)
+	}
+
+	result, err := detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	// The first analyzer always finishes, even past the budget, so there is
+	// a score; unlisted analyzers follow in registration order
+	if _, ok := result.Details["linguistic"]; !ok {
+		t.Error("Expected the first analyzer to run to completion")
+	}
+	if got := started(); !reflect.DeepEqual(got, []string{"linguistic"}) {
+		t.Errorf("Expected no analyzer to start once the budget was spent, got %v", got)
+	}
+	if skipped := result.Metadata["skipped_analyzers"]; !reflect.DeepEqual(skipped, []string{"entropy", "embedding"}) {
+		t.Errorf("Expected the remaining analyzers to be skipped in order, got %v", skipped)
+	}
+}
+
+func TestExecutionWithoutBudget(t *testing.T) {
+	delays := map[string]time.Duration{"linguistic": 50 * time.Millisecond, "entropy": 50 * time.Millisecond, "embedding": 50 * time.Millisecond}
+	detector, _ := newBudgetDetector(delays, "linguistic", "entropy", "embedding")
+
+	start := time.Now()
+	result, err := detector.AnalyzeTextAs("Some ordinary text.", core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 140*time.Millisecond {
+		t.Errorf("Expected analyzers to run in parallel without a budget, took %s", elapsed)
+	}
+	if _, ok := result.Metadata["skipped_analyzers"]; ok || len(result.Details) != 3 {
+		t.Errorf("Expected every analyzer to run, got %v skipped and %d scored", result.Metadata["skipped_analyzers"], len(result.Details))
+	}
+
+	// A per-request budget applies even when none is configured
+	result, err = detector.AnalyzeTextWith(context.Background(), "Some ordinary text.", core.ContentTypeProse, core.Selection{Budget: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if skipped := result.Metadata["skipped_analyzers"]; !reflect.DeepEqual(skipped, []string{"entropy", "embedding"}) {
+		t.Errorf("Expected the request budget to skip the later analyzers, got %v", skipped)
+	}
+	if _, err := detector.AnalyzeTextWith(context.Background(), "text", core.ContentTypeProse, core.Selection{Budget: -time.Second}); err == nil {
+		t.Error("Expected a negative request budget to be rejected")
+	}
+}
+
+func TestExecutionConfig(t *testing.T) {
+	if err := (core.ExecutionPlan{Order: []string{"entropy", "entropy"}}).Validate(); err == nil {
+		t.Error("Expected a repeated analyzer to be rejected")
+	}
+
+	path := writeConfigFile(t, "alienator.yaml", `
+detector:
+  execution:
+    order: [entropy, linguistic]
+    budget: 250ms
+`)
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Detector.Execution.Order, []string{"entropy", "linguistic"}) || cfg.Detector.Execution.Budget != 250*time.Millisecond {
+		t.Errorf("Expected the execution settings from the file, got %+v", cfg.Detector.Execution)
+	}
+
+	t.Setenv("DETECTOR_ANALYZER_ORDER", " crypto , linguistic,,embedding")
+	t.Setenv("DETECTOR_BUDGET_MS", "40")
+	cfg, err = config.LoadFile("")
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Detector.Execution.Order, []string{"crypto", "linguistic", "embedding"}) || cfg.Detector.Execution.Budget != 40*time.Millisecond {
+		t.Errorf("Expected the execution settings from the environment, got %+v", cfg.Detector.Execution)
+	}
+
+	cfg.Detector.Execution.Order = []string{"entropy", "entropy"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "detector.execution.order") {
+		t.Errorf("Expected config validation to reject the repeated analyzer, got %v", err)
+	}
+
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	if err := detector.ApplyConfig(cfg.Detector); err == nil {
+		t.Error("Expected ApplyConfig to reject an invalid execution plan")
+	}
+}
+
+func TestAnalysisStopsWithItsContext(t *testing.T) {
+	delays := map[string]time.Duration{"linguistic": time.Second, "entropy": time.Second}
+	detector, _ := newBudgetDetector(delays, "linguistic", "entropy")
+	if err := detector.SetBreakerPolicy(core.BreakerPolicy{Failures: 1, Cooldown: time.Hour}); err != nil {
+		t.Fatalf("SetBreakerPolicy failed: %v", err)
+	}
+
+	for _, selection := range []core.Selection{{}, {Budget: time.Minute}} {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		start := time.Now()
+		_, err := detector.AnalyzeTextWith(ctx, "Some ordinary text.", core.ContentTypeProse, selection)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the analysis with budget %s to fail with its context, got %v", selection.Budget, err)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Expected the analyzers stopped with the context, took %s", elapsed)
+		}
+	}
+
+	// The caller giving up is not the analyzers failing
+	for _, info := range detector.DescribeAnalyzers() {
+		if info.Circuit != nil && info.Circuit.State != core.CircuitClosed {
+			t.Errorf("Expected the %s circuit to stay closed, got %+v", info.Name, info.Circuit)
+		}
+	}
+}
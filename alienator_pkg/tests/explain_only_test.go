@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/api/rest"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+// storeCountingRepository counts the detections stored
+type storeCountingRepository struct {
+	*profileRepository
+	stored int
+}
+
+func (r *storeCountingRepository) CreateAnomalyData(data *models.AnomalyData) error {
+	r.stored++
+	return r.profileRepository.CreateAnomalyData(data)
+}
+
+func TestDetectAnomalyExplainOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.9, confidence: 0.9})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "embedding", score: 0.9, confidence: 0.9})
+
+	repo := &storeCountingRepository{profileRepository: newProfileRepository()}
+	anomalyService := services.NewAnomalyService(repo, zap.NewNop())
+	anomalyService.SetDetector(detector)
+	anomalyService.SetExplainOnly(config.Defaults().ExplainOnly)
+	handler := rest.NewHandler(nil, anomalyService, nil, nil, zap.NewNop(), zap.NewAtomicLevel())
+
+	router := gin.New()
+	router.POST("/detect", func(c *gin.Context) { c.Set("user_id", uuid.New()) }, handler.DetectAnomaly)
+	detect := func(query string) (int, models.DetectionResult) {
+		body := `{"data": {"text": "The committee met on Tuesday to review the budget."}}`
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/detect"+query, strings.NewReader(body)))
+		var response struct {
+			Data models.DetectionResult `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		return recorder.Code, response.Data
+	}
+
+	code, result := detect("?explain_only=true")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if !result.ExplainOnly || result.ID != uuid.Nil || repo.stored != 0 {
+		t.Errorf("Expected an explain-only result that isn't stored, got %+v after %d stored", result, repo.stored)
+	}
+	if _, ok := result.Metadata.Analyzers["embedding"]; ok || len(result.Metadata.Analyzers) != 1 {
+		t.Errorf("Expected only the cheap analyzers to run, got %v", result.Metadata.Analyzers)
+	}
+	if len(result.Metadata.Explanations) != 1 || !strings.Contains(result.Metadata.Explanations[0], "linguistic") {
+		t.Errorf("Expected the verdict explained, got %v", result.Metadata.Explanations)
+	}
+
+	code, result = detect("")
+	if code != http.StatusOK || result.ExplainOnly || repo.stored != 1 || len(result.Metadata.Analyzers) != 2 {
+		t.Errorf("Expected a full detection to run every analyzer and be stored, got %d %+v", code, result)
+	}
+
+	// The explanation survives even the least metadata
+	if _, result = detect("?explain_only=1&metadata=none"); len(result.Metadata.Explanations) != 1 || len(result.Metadata.Analyzers) != 1 {
+		t.Errorf("Expected the explanation kept at level none, got %+v", result.Metadata)
+	}
+
+	if code, _ = detect("?explain_only=maybe"); code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid flag rejected, got %d", code)
+	}
+	anomalyService.SetExplainOnly(config.ExplainOnlyConfig{})
+	if code, _ = detect("?explain_only=true"); code != http.StatusBadRequest {
+		t.Errorf("Expected explain-only detections rejected when disabled, got %d", code)
+	}
+	if code, _ = detect("?explain_only=false"); code != http.StatusOK {
+		t.Errorf("Expected a full detection while explain-only is disabled, got %d", code)
+	}
+}
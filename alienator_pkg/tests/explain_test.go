@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+func explainedResult(score float64, severity models.Severity, details map[string]*models.AnalysisResult) *models.AnomalyResult {
+	return &models.AnomalyResult{
+		Score:       score,
+		Confidence:  0.9,
+		IsAnomalous: score > core.AnomalyThreshold,
+		Severity:    severity,
+		Details:     details,
+		Metadata:    map[string]interface{}{"content_type": string(core.ContentTypeProse)},
+	}
+}
+
+func TestExplainTextNamesStrongestFindings(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	result := explainedResult(0.9, models.SeverityHigh, map[string]*models.AnalysisResult{
+		"linguistic": {
+			Score:      0.9,
+			Confidence: 0.9,
+			Metadata: map[string]interface{}{
+				"ai_phrases": []string{"as an ai"},
+				"perplexity": 0.02,
+			},
+		},
+		"entropy": {Score: 0.2, Confidence: 0.8, Metadata: map[string]interface{}{"shannon_entropy": 4.1}},
+	})
+
+	explanation := detector.ExplainText(result)
+	want := "Presence of the phrase 'as an ai' and low perplexity strongly indicate machine generation."
+	if !strings.HasPrefix(explanation, want) {
+		t.Errorf("Expected explanation to start with %q, got %q", want, explanation)
+	}
+	if !strings.Contains(explanation, "The linguistic analysis accounts for") {
+		t.Errorf("Expected the dominant analyzer to be named, got %q", explanation)
+	}
+	if again := detector.ExplainText(result); again != explanation {
+		t.Errorf("Expected a deterministic explanation, got %q and %q", explanation, again)
+	}
+}
+
+func TestExplainTextWithoutFindings(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	result := explainedResult(0.1, models.SeverityNone, map[string]*models.AnalysisResult{
+		"entropy": {Score: 0.1, Confidence: 0.9, Metadata: map[string]interface{}{"shannon_entropy": 4.2}},
+	})
+
+	explanation := detector.ExplainText(result)
+	if !strings.HasPrefix(explanation, "Nothing in the text suggests machine generation.") {
+		t.Errorf("Unexpected explanation: %q", explanation)
+	}
+}
+
+func TestExplainTextConfiguration(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.SetExplanationConfig(core.ExplanationConfig{
+		MaxFindings: 1,
+		// Wording without a value is used as it is, percent signs included
+		Phrases: map[string]string{"low_perplexity": "very predictable wording (99% sure)"},
+	})
+	result := explainedResult(0.75, models.SeverityMedium, map[string]*models.AnalysisResult{
+		"linguistic": {
+			Score:      0.8,
+			Confidence: 0.9,
+			Metadata: map[string]interface{}{
+				"perplexity":       0.01,
+				"repetition_score": 0.2,
+			},
+		},
+	})
+
+	explanation := detector.ExplainText(result)
+	want := "Very predictable wording (99% sure) indicates machine generation."
+	if !strings.HasPrefix(explanation, want) {
+		t.Errorf("Expected explanation to start with %q, got %q", want, explanation)
+	}
+}
+
+func TestExplainTextRendersEveryFinding(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.SetExplanationConfig(core.ExplanationConfig{MaxFindings: 20})
+	result := explainedResult(0.9, models.SeverityHigh, map[string]*models.AnalysisResult{
+		"linguistic": {Score: 0.9, Confidence: 0.9, Metadata: map[string]interface{}{
+			"ai_phrases":          []string{"as an ai"},
+			"perplexity":          0.02,
+			"bot_pattern_score":   0.3,
+			"repetition_score":    0.4,
+			"vocabulary_richness": 0.2,
+		}},
+		"entropy":     {Score: 0.6, Confidence: 0.9, Metadata: map[string]interface{}{"shannon_entropy": 2.5}},
+		"compression": {Score: 0.6, Confidence: 0.9, Metadata: map[string]interface{}{"combined_ratio": 0.1}},
+		"embedding":   {Score: 0.6, Confidence: 0.9, Metadata: map[string]interface{}{"outlier_score": 0.5}},
+	})
+
+	explanation := detector.ExplainText(result)
+	if strings.Contains(explanation, "%!") {
+		t.Errorf("Expected every finding rendered without formatting errors, got %q", explanation)
+	}
+	for _, finding := range []string{"low perplexity", "scripted assistant phrasing", "repetitive wording", "a narrow vocabulary", "(2.50 bits)", "repetitive structure", "stand apart"} {
+		if !strings.Contains(explanation, finding) {
+			t.Errorf("Expected %q in the explanation, got %q", finding, explanation)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/analyzers/repetition"
+	"github.com/ruvnet/alienator/internal/analyzers/sentiment"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+	"go.uber.org/zap"
+)
+
+// shortTextAnalyzer is an analyzer that needs more words than a short text has
+type shortTextAnalyzer struct {
+	fixedAnalyzer
+	minWords int
+}
+
+func (a *shortTextAnalyzer) CanAnalyze(tokens *tokenizer.Tokens) error {
+	if len(tokens.Words()) < a.minWords {
+		return errors.New("text is too short")
+	}
+	return nil
+}
+
+func TestFallbackChains(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.2, confidence: 0.8})
+	detector.RegisterAnalyzer(&shortTextAnalyzer{fixedAnalyzer{name: "linguistic", score: 0.6, confidence: 0.8}, 50})
+	detector.RegisterAnalyzer(&shortTextAnalyzer{fixedAnalyzer{name: "embedding", score: 0.4, confidence: 0.8}, 50})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "backup", score: 0.9, confidence: 0.8})
+	selection := core.Selection{Analyzers: []string{"entropy", "linguistic"}}
+
+	result, err := detector.AnalyzeTextWith(context.Background(), "A short text.", core.ContentTypeProse, selection)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if _, ok := result.Details["linguistic"]; ok {
+		t.Error("Expected an analyzer whose precondition is not met not to run")
+	}
+	if unmet, ok := result.Metadata["unmet_preconditions"].(map[string]string); !ok || unmet["linguistic"] != "text is too short" {
+		t.Errorf("Expected the unmet precondition recorded, got %v", result.Metadata["unmet_preconditions"])
+	}
+	if math.Abs(result.Score-0.2) > 1e-9 {
+		t.Errorf("Expected only entropy to count without fallbacks, got %v", result.Score)
+	}
+
+	if err := detector.SetFallbackChains(core.FallbackChains{
+		"linguistic": {{Analyzer: "embedding", Weight: 1}, {Analyzer: "backup", Weight: 0.5}},
+	}); err != nil {
+		t.Fatalf("SetFallbackChains failed: %v", err)
+	}
+	result, err = detector.AnalyzeTextWith(context.Background(), "A short text.", core.ContentTypeProse, selection)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	substitute := result.Details["linguistic"]
+	if substitute == nil || substitute.Score != 0.9 || math.Abs(substitute.Confidence-0.4) > 1e-9 || substitute.Metadata["fallback"] != "backup" {
+		t.Fatalf("Expected backup to stand in for linguistic at half weight, got %+v", substitute)
+	}
+	if _, ok := result.Details["backup"]; ok {
+		t.Error("Expected a fallback run only to stand in to be left out of the details")
+	}
+	if fallbacks, ok := result.Metadata["fallbacks"].(map[string]string); !ok || fallbacks["linguistic"] != "backup" {
+		t.Errorf("Expected the fallback recorded, got %v", result.Metadata["fallbacks"])
+	}
+	// entropy at 0.8 * 1.0, backup at 0.4 * linguistic's 1.5
+	expected := (0.2*0.8 + 0.9*0.4*1.5) / (0.8 + 0.4*1.5)
+	if math.Abs(result.Score-expected) > 1e-9 {
+		t.Errorf("Expected score %v with the fallback, got %v", expected, result.Score)
+	}
+
+	for _, chains := range []core.FallbackChains{
+		{"linguistic": {{Analyzer: "linguistic", Weight: 1}}},
+		{"linguistic": {{Analyzer: "backup", Weight: 0}}},
+		{"linguistic": {{Analyzer: "backup", Weight: 1}, {Analyzer: "backup", Weight: 0.5}}},
+		{"linguistic": {{Analyzer: "", Weight: 1}}},
+	} {
+		if err := detector.SetFallbackChains(chains); err == nil {
+			t.Errorf("Expected chains %v to be rejected", chains)
+		}
+	}
+}
+
+func TestFallbackConfig(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Detector.Fallbacks = map[string][]config.FallbackConfig{
+		"sentiment": {{Analyzer: "linguistic", Weight: 0.3}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected fallbacks to validate, got %v", err)
+	}
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	if err := detector.ApplyConfig(cfg.Detector); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	detector.RegisterAnalyzer(sentiment.NewSentimentAnalyzer())
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.5, confidence: 1})
+	result, err := detector.AnalyzeTextAs("Only one sentence here.", core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if substitute := result.Details["sentiment"]; substitute == nil || substitute.Metadata["fallback"] != "linguistic" {
+		t.Errorf("Expected linguistic to stand in for sentiment on a single sentence, got %+v", substitute)
+	}
+
+	cfg.Detector.Fallbacks["sentiment"] = append(cfg.Detector.Fallbacks["sentiment"], config.FallbackConfig{Analyzer: "sentiment", Weight: 1.5})
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a self-referencing fallback with a weight over 1 to be rejected")
+	}
+}
+
+func TestAnalyzerPreconditions(t *testing.T) {
+	short := tokenizer.New(tokenizer.Default, "Too short.")
+	long := tokenizer.New(tokenizer.Default, "One sentence. Two sentences. Three sentences here. Four of them now. Five sentences, with enough words to find repeated phrases.")
+	for _, analyzer := range []core.Precondition{repetition.NewRepetitionAnalyzer(), sentiment.NewSentimentAnalyzer()} {
+		if err := analyzer.CanAnalyze(short); err == nil {
+			t.Errorf("Expected %T to need more than two words", analyzer)
+		}
+		if err := analyzer.CanAnalyze(long); err != nil {
+			t.Errorf("Expected %T to analyze five sentences, got %v", analyzer, err)
+		}
+	}
+}
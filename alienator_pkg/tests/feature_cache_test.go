@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// countingAnalyzer scores by text length and counts how often it runs
+type countingAnalyzer struct {
+	name  string
+	calls int32
+}
+
+func (a *countingAnalyzer) Name() string { return a.name }
+
+func (a *countingAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	atomic.AddInt32(&a.calls, 1)
+	return &models.AnalysisResult{Score: float64(len(text)%10) / 10, Confidence: 2}, nil
+}
+
+func TestFeatureCacheSkipsRepeatedText(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	cached := &countingAnalyzer{name: "cryptographic"}
+	uncached := &countingAnalyzer{name: "entropy"}
+	detector.RegisterAnalyzer(cached)
+	detector.RegisterAnalyzer(uncached)
+	if err := detector.SetFeatureCache(core.FeatureCacheConfig{Analyzers: []string{"cryptographic"}, Size: 2}); err != nil {
+		t.Fatalf("SetFeatureCache failed: %v", err)
+	}
+
+	// Four identical windows are scored once by the cached analyzer
+	paragraph := strings.Repeat("the same paragraph repeated. ", 7)
+	cfg := core.DefaultWindowConfig()
+	cfg.WindowSize = len(paragraph)
+	if _, err := detector.AnalyzeTextWindowed(strings.Repeat(paragraph, 4), core.ContentTypeProse, cfg); err != nil {
+		t.Fatalf("AnalyzeTextWindowed failed: %v", err)
+	}
+	if cached.calls != 1 || uncached.calls != 4 {
+		t.Errorf("Expected 1 cached and 4 uncached runs, got %d and %d", cached.calls, uncached.calls)
+	}
+
+	// The paragraph is a window's text up to whitespace, which is collapsed
+	// before hashing, so it is served from the window's entry
+	first, err := detector.AnalyzeTextAs(paragraph, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("AnalyzeTextAs failed: %v", err)
+	}
+	// Results stored in the cache are still clamped by aggregation
+	if first.Details["cryptographic"].Confidence != 1 {
+		t.Errorf("Expected the clamped confidence, got %g", first.Details["cryptographic"].Confidence)
+	}
+
+	// The paragraph, used least recently, is evicted first
+	for _, text := range []string{"another text", "a third text", paragraph} {
+		if _, err := detector.AnalyzeTextAs(text, core.ContentTypeProse); err != nil {
+			t.Fatalf("AnalyzeTextAs failed: %v", err)
+		}
+	}
+
+	stats := detector.FeatureCacheStats()
+	expected := models.CacheStats{Hits: 4, Misses: 4, Evictions: 2, Entries: 2, Capacity: 2}
+	if !reflect.DeepEqual(stats["cryptographic"], expected) {
+		t.Errorf("Expected %+v, got %+v", expected, stats["cryptographic"])
+	}
+	if _, ok := stats["entropy"]; ok {
+		t.Error("Expected no stats for an uncached analyzer")
+	}
+
+	for _, info := range detector.DescribeAnalyzers() {
+		if (info.Cache != nil) != (info.Name == "cryptographic") {
+			t.Errorf("Unexpected cache stats for %s: %+v", info.Name, info.Cache)
+		}
+	}
+}
+
+// previewAnalyzer reports a text preview and the words it saw
+type previewAnalyzer struct{ name string }
+
+func (a *previewAnalyzer) Name() string { return a.name }
+
+func (a *previewAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return &models.AnalysisResult{
+		Score:      0.5,
+		Confidence: 0.5,
+		Metadata: map[string]interface{}{
+			"preview": text[:4],
+			"words":   strings.Fields(text),
+			"counts":  map[string]interface{}{"words": len(strings.Fields(text))},
+		},
+	}, nil
+}
+
+func TestFeatureCacheDoesNotShareMetadata(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&previewAnalyzer{name: "cryptographic"})
+	if err := detector.SetFeatureCache(core.FeatureCacheConfig{Analyzers: []string{"cryptographic"}, Size: 2}); err != nil {
+		t.Fatalf("SetFeatureCache failed: %v", err)
+	}
+
+	text := "some text seen twice"
+	first, err := detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("AnalyzeTextAs failed: %v", err)
+	}
+	second, err := detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("AnalyzeTextAs failed: %v", err)
+	}
+	if hits := detector.FeatureCacheStats()["cryptographic"].Hits; hits != 1 {
+		t.Fatalf("Expected the second analysis served from the cache, got %d hits", hits)
+	}
+
+	// Redacting or editing one result leaves the other and the cache alone
+	kept := second.Details["cryptographic"].Metadata
+	core.RedactHook("preview")(first, "")
+	first.Details["cryptographic"].Metadata["words"].([]string)[0] = "edited"
+	first.Details["cryptographic"].Metadata["counts"].(map[string]interface{})["words"] = 0
+
+	third, err := detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("AnalyzeTextAs failed: %v", err)
+	}
+	for name, metadata := range map[string]map[string]interface{}{"second": kept, "third": third.Details["cryptographic"].Metadata} {
+		if metadata["preview"] != "some" {
+			t.Errorf("Expected the %s result to keep its preview, got %v", name, metadata)
+		}
+		if words := metadata["words"].([]string); words[0] != "some" {
+			t.Errorf("Expected the %s result's words unchanged, got %v", name, words)
+		}
+		if counts := metadata["counts"].(map[string]interface{}); counts["words"] != 4 {
+			t.Errorf("Expected the %s result's counts unchanged, got %v", name, counts)
+		}
+	}
+}
+
+func TestFeatureCacheConfig(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	if err := detector.SetFeatureCache(core.FeatureCacheConfig{Analyzers: []string{"entropy"}}); err == nil {
+		t.Error("Expected a zero cache size to be rejected")
+	}
+	if err := detector.SetFeatureCache(core.FeatureCacheConfig{Analyzers: []string{"entropy", "entropy"}, Size: 8}); err == nil {
+		t.Error("Expected a repeated analyzer to be rejected")
+	}
+
+	t.Setenv("DETECTOR_CACHE_ANALYZERS", "cryptographic, compression")
+	t.Setenv("DETECTOR_CACHE_SIZE", "64")
+	cfg, err := config.LoadFile("")
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Detector.Cache.Analyzers, []string{"cryptographic", "compression"}) || cfg.Detector.Cache.Size != 64 {
+		t.Errorf("Expected the cache settings from the environment, got %+v", cfg.Detector.Cache)
+	}
+
+	cfg.Detector.Cache.Size = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "detector.cache.size") {
+		t.Errorf("Expected config validation to reject the cache size, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/hashing"
+)
+
+func newHasher(t *testing.T, algorithm, form string, collapse bool) *hashing.Hasher {
+	t.Helper()
+	h, err := hashing.New(config.HashingConfig{Algorithm: algorithm, Unicode: form, CollapseWhitespace: collapse})
+	if err != nil {
+		t.Fatalf("New(%s, %s) failed: %v", algorithm, form, err)
+	}
+	return h
+}
+
+func TestContentHashNormalization(t *testing.T) {
+	h := newHasher(t, hashing.SHA256, hashing.UnicodeNFC, true)
+
+	base := h.ContentHash("Café au lait\nis served.")
+	equivalent := []string{
+		"Cafe\u0301 au lait\nis served.",     // decomposed é
+		"  Café   au\tlait\r\n is served.  ", // whitespace runs and CRLF
+		"Café\u00a0au lait is served.",       // no-break space
+	}
+	for _, text := range equivalent {
+		if got := h.ContentHash(text); got != base {
+			t.Errorf("Expected %q to hash like the base text, got %s vs %s", text, got, base)
+		}
+	}
+
+	different := []string{
+		"café au lait is served.", // case is significant
+		"Café au lait is served!",
+		"\uff23\uff41\uff46é au lait is served.", // fullwidth letters are only folded by NFKC
+	}
+	for _, text := range different {
+		if h.ContentHash(text) == base {
+			t.Errorf("Expected %q to hash differently from the base text", text)
+		}
+	}
+
+	nfkc := newHasher(t, hashing.SHA256, hashing.UnicodeNFKC, true)
+	if nfkc.ContentHash("\uff23\uff41\uff46é au lait is served.") != nfkc.ContentHash("Café au lait is served.") {
+		t.Error("Expected NFKC to fold fullwidth letters")
+	}
+
+	raw := newHasher(t, hashing.SHA256, hashing.UnicodeNone, false)
+	if raw.ContentHash("a  b") == raw.ContentHash("a b") || raw.ContentHash("Cafe\u0301") == raw.ContentHash("Café") {
+		t.Error("Expected no normalization when it is turned off")
+	}
+	if raw.Normalize(" a \n b ") != " a \n b " {
+		t.Error("Expected Normalize to leave text alone when normalization is turned off")
+	}
+}
+
+func TestContentHashAlgorithms(t *testing.T) {
+	sha := newHasher(t, hashing.SHA256, hashing.UnicodeNFC, true)
+	xx := newHasher(t, hashing.XXHash, hashing.UnicodeNFC, true)
+
+	shaHash := sha.ContentHash("hello world")
+	if shaHash != "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9" {
+		t.Errorf("Unexpected sha256 content hash %s", shaHash)
+	}
+	xxHash := xx.ContentHash("hello world")
+	if !strings.HasPrefix(xxHash, "xxhash:") || len(xxHash) != len("xxhash:")+16 {
+		t.Errorf("Expected a prefixed 64-bit xxhash digest, got %s", xxHash)
+	}
+	if xx.ContentHash(" hello   world ") != xxHash {
+		t.Error("Expected xxhash content hashes to use the same normalization")
+	}
+	if sha.Sum([]byte(" hello world")) == shaHash {
+		t.Error("Expected Sum to hash bytes without normalizing them")
+	}
+
+	if _, err := hashing.New(config.HashingConfig{Algorithm: "md5", Unicode: hashing.UnicodeNFC}); err == nil {
+		t.Error("Expected an unsupported algorithm to be rejected")
+	}
+	if _, err := hashing.New(config.HashingConfig{Algorithm: hashing.SHA256, Unicode: "nfd"}); err == nil {
+		t.Error("Expected an unsupported normalization to be rejected")
+	}
+
+	cfg := config.Defaults()
+	cfg.Hashing.Algorithm = "md5"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "hashing.algorithm") {
+		t.Errorf("Expected config validation to reject the algorithm, got %v", err)
+	}
+}
+
+func TestDefaultContentHash(t *testing.T) {
+	defer hashing.Configure(config.Defaults().Hashing)
+
+	if got := hashing.ContentHash("hello  world"); got != newHasher(t, hashing.SHA256, hashing.UnicodeNFC, true).ContentHash("hello world") {
+		t.Errorf("Expected the default hasher to follow the default config, got %s", got)
+	}
+
+	if err := hashing.Configure(config.HashingConfig{Algorithm: hashing.XXHash, Unicode: hashing.UnicodeNFC}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	if hashing.Default().Algorithm() != hashing.XXHash || !strings.HasPrefix(hashing.ContentHash("hello"), "xxhash:") {
+		t.Error("Expected Configure to switch the default hasher")
+	}
+	if err := hashing.Configure(config.HashingConfig{Algorithm: "crc32"}); err == nil || hashing.Default().Algorithm() != hashing.XXHash {
+		t.Errorf("Expected an invalid config to be rejected and the hasher kept, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/analyzers/cryptographic"
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/analyzers/sentiment"
+	"github.com/ruvnet/alienator/internal/analyzers/uniformity"
+	"github.com/ruvnet/alienator/internal/api/rest"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// unhealthyAnalyzer is an analyzer whose resources failed to load
+type unhealthyAnalyzer struct {
+	fixedAnalyzer
+}
+
+func (a *unhealthyAnalyzer) ValidateHealth(ctx context.Context) error {
+	return errors.New("reference corpus failed to load")
+}
+
+func newHealthDetector() *core.AnomalyDetector {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.5, confidence: 0.8})
+	detector.RegisterAnalyzer(&unhealthyAnalyzer{fixedAnalyzer{name: "embedding", score: 0.5, confidence: 0.8}})
+	return detector
+}
+
+func TestCheckHealth(t *testing.T) {
+	detector := newHealthDetector()
+	if !detector.Healthy() {
+		t.Fatal("Expected the detector to be healthy before any check")
+	}
+
+	report := detector.CheckHealth(context.Background())
+	if report.Healthy || detector.Healthy() {
+		t.Error("Expected an unhealthy analyzer to fail the check when every analyzer is required")
+	}
+	expected := []models.AnalyzerHealth{
+		{Name: "embedding", Required: true, Healthy: false, Error: "reference corpus failed to load"},
+		{Name: "entropy", Required: true, Healthy: true},
+	}
+	if len(report.Analyzers) != len(expected) {
+		t.Fatalf("Expected %d analyzers, got %+v", len(expected), report.Analyzers)
+	}
+	for i, health := range report.Analyzers {
+		if health != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], health)
+		}
+	}
+
+	if err := detector.SetHealthPolicy(core.HealthPolicy{Required: []string{"entropy"}, Timeout: time.Second}); err != nil {
+		t.Fatalf("SetHealthPolicy failed: %v", err)
+	}
+	report = detector.CheckHealth(context.Background())
+	if !report.Healthy || !detector.Healthy() {
+		t.Error("Expected an unhealthy optional analyzer not to fail the check")
+	}
+	if report.Analyzers[0].Required || report.Analyzers[0].Healthy {
+		t.Errorf("Expected the optional analyzer still reported unhealthy, got %+v", report.Analyzers[0])
+	}
+
+	if err := detector.SetHealthPolicy(core.HealthPolicy{Required: []string{"entropy", "watermark"}, Timeout: time.Second}); err != nil {
+		t.Fatalf("SetHealthPolicy failed: %v", err)
+	}
+	if report = detector.CheckHealth(context.Background()); report.Healthy {
+		t.Error("Expected a required analyzer that is not registered to fail the check")
+	}
+
+	for _, policy := range []core.HealthPolicy{
+		{Timeout: 0},
+		{Required: []string{""}, Timeout: time.Second},
+		{Required: []string{"entropy", "entropy"}, Timeout: time.Second},
+	} {
+		if err := detector.SetHealthPolicy(policy); err == nil {
+			t.Errorf("Expected policy %+v to be rejected", policy)
+		}
+	}
+}
+
+func TestWarmupChecksHealth(t *testing.T) {
+	detector := newHealthDetector()
+	if err := detector.Warmup(context.Background()); err != nil {
+		t.Fatalf("Warmup failed: %v", err)
+	}
+	if !detector.Ready() {
+		t.Error("Expected warm-up to complete despite an unhealthy analyzer")
+	}
+	if detector.Healthy() {
+		t.Error("Expected warm-up to report the unhealthy analyzer")
+	}
+}
+
+func TestAnalyzerHealthEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	detector := newHealthDetector()
+	handler := rest.NewHandler(detector, nil, nil, nil, zap.NewNop(), zap.NewAtomicLevel())
+	router := gin.New()
+	router.GET("/analyzers/health", handler.AnalyzerHealth)
+
+	var response struct {
+		Success bool                        `json:"success"`
+		Data    models.AnalyzerHealthReport `json:"data"`
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analyzers/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503, got %d", rec.Code)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response.Success || response.Data.Healthy || len(response.Data.Analyzers) != 2 {
+		t.Errorf("Expected the unhealthy report, got %+v", response)
+	}
+
+	if err := detector.SetHealthPolicy(core.HealthPolicy{Required: []string{"entropy"}, Timeout: time.Second}); err != nil {
+		t.Fatalf("SetHealthPolicy failed: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analyzers/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 with only optional analyzers unhealthy, got %d", rec.Code)
+	}
+}
+
+func TestAnalyzersValidateHealth(t *testing.T) {
+	for _, analyzer := range []core.HealthChecker{
+		linguistic.NewLinguisticAnalyzer(),
+		cryptographic.NewCryptographicAnalyzer(),
+		uniformity.NewUniformityAnalyzer(),
+		sentiment.NewSentimentAnalyzer(),
+	} {
+		if err := analyzer.ValidateHealth(context.Background()); err != nil {
+			t.Errorf("Expected %T to be healthy by default, got %v", analyzer, err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "known.txt")
+	if err := os.WriteFile(path, []byte("5d41402abc4b2a76b9719d911017c592\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write dataset: %v", err)
+	}
+	analyzer := cryptographic.NewCryptographicAnalyzer()
+	if err := analyzer.LoadKnownHashBloom(path); err != nil {
+		t.Fatalf("LoadKnownHashBloom failed: %v", err)
+	}
+	if err := analyzer.ValidateHealth(context.Background()); err != nil {
+		t.Errorf("Expected a loaded dataset to be healthy, got %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove dataset: %v", err)
+	}
+	if err := analyzer.ValidateHealth(context.Background()); err == nil {
+		t.Error("Expected a dataset that can no longer confirm matches to be unhealthy")
+	}
+}
+
+func TestHealthConfigValidation(t *testing.T) {
+	cfg := config.Defaults()
+	if cfg.Detector.Health.Timeout <= 0 || len(cfg.Detector.Health.Required) != 0 {
+		t.Errorf("Expected every analyzer required with a positive timeout by default, got %+v", cfg.Detector.Health)
+	}
+	cfg.Detector.Health.Required = []string{"linguistic", "linguistic"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a duplicate required analyzer to be rejected")
+	}
+	cfg = config.Defaults()
+	cfg.Detector.Health.Timeout = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a zero health check timeout to be rejected")
+	}
+}
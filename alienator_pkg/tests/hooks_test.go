@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestResultHooks(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&scoringAnalyzer{score: 0.9})
+
+	var order []string
+	var seenInput string
+	detector.Use(
+		func(result *models.AnomalyResult, input string) *models.AnomalyResult {
+			order = append(order, "first")
+			seenInput = input
+			result.Metadata["first"] = true
+			return result
+		},
+		func(result *models.AnomalyResult, input string) *models.AnomalyResult {
+			order = append(order, "second")
+			return nil
+		},
+	)
+	detector.Use(func(result *models.AnomalyResult, input string) *models.AnomalyResult {
+		order = append(order, "third")
+		replacement := *result
+		replacement.IsAnomalous = false
+		return &replacement
+	})
+
+	completed := make(chan core.Event, 1)
+	defer subscribe(t, detector.Events(), core.TopicAnalysisCompleted, func(e core.Event) { completed <- e })()
+
+	result, err := detector.AnalyzeText("An unusual text.")
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if len(order) != 3 || order[0] != "first" || order[1] != "second" || order[2] != "third" {
+		t.Errorf("Expected the hooks to run in the order added, got %v", order)
+	}
+	if seenInput != "An unusual text." {
+		t.Errorf("Expected the hooks to be given the text, got %q", seenInput)
+	}
+	if result.IsAnomalous || result.Metadata["first"] != true {
+		t.Errorf("Expected the result returned by the last hook, got %+v", result)
+	}
+
+	select {
+	case e := <-completed:
+		if event, ok := e.(core.AnalysisCompletedEvent); !ok || event.Result != result {
+			t.Errorf("Expected subscribers to be given the hooked result, got %#v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for analysis completed event")
+	}
+
+	order = nil
+	windowed, err := detector.AnalyzeTextWindowed("An unusual text. Another one follows it.", core.ContentTypeProse, core.DefaultWindowConfig())
+	if err != nil {
+		t.Fatalf("Windowed analysis failed: %v", err)
+	}
+	if len(order) != 3 || windowed.Aggregate.IsAnomalous {
+		t.Errorf("Expected the hooks to run once over the aggregate, got %v", order)
+	}
+}
+
+func TestBuiltInResultHooks(t *testing.T) {
+	result := &models.AnomalyResult{
+		Score:      0.9,
+		Confidence: 0.9,
+		Severity:   models.SeverityHigh,
+		Outcome:    models.OutcomeAnalyzed,
+		Details: map[string]*models.AnalysisResult{
+			"injection": {Metadata: map[string]interface{}{"preview": "secret", "count": 1}},
+			"entropy":   nil,
+		},
+		Metadata: map[string]interface{}{"preview": "secret", "profile": "prose"},
+	}
+
+	result = core.RedactHook("preview")(result, "")
+	if _, ok := result.Metadata["preview"]; ok {
+		t.Error("Expected the result metadata key to be redacted")
+	}
+	if _, ok := result.Details["injection"].Metadata["preview"]; ok {
+		t.Error("Expected the analyzer metadata key to be redacted")
+	}
+	if result.Metadata["profile"] != "prose" || result.Details["injection"].Metadata["count"] != 1 {
+		t.Error("Expected other metadata to be kept")
+	}
+
+	result = core.MetadataHook(map[string]interface{}{"tenant": "acme", "profile": "custom"})(result, "")
+	if result.Metadata["tenant"] != "acme" || result.Metadata["profile"] != "custom" {
+		t.Errorf("Expected metadata to be added, got %v", result.Metadata)
+	}
+
+	strict := core.DefaultSeverityBands()
+	strict.High, strict.Critical = 0.8, 0.85
+	hook, err := core.SeverityHook(strict)
+	if err != nil {
+		t.Fatalf("SeverityHook failed: %v", err)
+	}
+	if result = hook(result, ""); result.Severity != models.SeverityCritical {
+		t.Errorf("Expected severity reclassified as critical, got %s", result.Severity)
+	}
+	empty := &models.AnomalyResult{Score: 0.9, Confidence: 0.9, Severity: models.SeverityNone, Outcome: models.OutcomeEmptyInput}
+	if empty = hook(empty, ""); empty.Severity != models.SeverityNone {
+		t.Errorf("Expected an empty input result to keep its severity, got %s", empty.Severity)
+	}
+
+	strict.Critical = 0.5
+	if _, err := core.SeverityHook(strict); err == nil {
+		t.Error("Expected invalid bands to be rejected")
+	}
+}
+
+func TestBuildProfileMetadata(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&scoringAnalyzer{score: 0.9})
+
+	result, err := detector.AnalyzeText("An unusual text.")
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	// Only the lite build marks its results, with the accuracy it gives up,
+	// leaving the content profile alone
+	if result.Metadata["profile"] != "prose" {
+		t.Errorf("Expected the content profile kept, got %v", result.Metadata["profile"])
+	}
+	profile, marked := result.Metadata["build_profile"]
+	switch core.BuildProfile {
+	case "lite":
+		if profile != "lite" || result.Metadata["build_profile_note"] == nil {
+			t.Errorf("Expected lite results marked with their build profile, got %v", result.Metadata)
+		}
+	case "full":
+		if marked {
+			t.Errorf("Expected full results unmarked, got build profile %v", profile)
+		}
+	default:
+		t.Errorf("Unexpected build profile %q", core.BuildProfile)
+	}
+}
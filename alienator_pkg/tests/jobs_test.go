@@ -0,0 +1,534 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/jobs"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/repository"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+// memoryJobStore is an in-memory jobs.Store
+type memoryJobStore struct {
+	mu       sync.Mutex
+	jobs     []*models.Job
+	cancels  map[uuid.UUID]bool
+	leases   map[uuid.UUID]time.Time
+	lease    time.Duration
+	progress []float64
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{
+		cancels: make(map[uuid.UUID]bool),
+		leases:  make(map[uuid.UUID]time.Time),
+		lease:   testJobsConfig().LeaseTimeout,
+	}
+}
+
+func (s *memoryJobStore) find(id uuid.UUID) (int, bool) {
+	for i, job := range s.jobs {
+		if job.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (s *memoryJobStore) Create(ctx context.Context, job *models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.ID = uuid.New()
+	job.Status = models.JobQueued
+	job.CreatedAt = time.Now()
+	copied := *job
+	s.jobs = append(s.jobs, &copied)
+	return nil
+}
+
+func (s *memoryJobStore) Get(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.find(id)
+	if !ok {
+		return nil, jobs.ErrNotFound
+	}
+	copied := *s.jobs[i]
+	copied.CancelRequested = !copied.Done() && s.cancels[id]
+	return &copied, nil
+}
+
+func (s *memoryJobStore) List(ctx context.Context, filter jobs.ListFilter) ([]*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []*models.Job
+	for i := len(s.jobs) - 1; i >= 0 && (filter.Limit <= 0 || len(list) < filter.Limit); i-- {
+		job := s.jobs[i]
+		if (filter.Status == "" || job.Status == filter.Status) && (filter.Kind == "" || job.Kind == filter.Kind) {
+			copied := *job
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+
+func (s *memoryJobStore) Claim(ctx context.Context, kinds []string) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.Status != models.JobQueued {
+			continue
+		}
+		for _, kind := range kinds {
+			if job.Kind == kind {
+				now := time.Now()
+				job.Status = models.JobRunning
+				job.StartedAt = &now
+				s.leases[job.ID] = now.Add(s.lease)
+				copied := *job
+				return &copied, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryJobStore) Renew(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expires, ok := s.leases[id]; !ok || time.Now().After(expires) {
+		return jobs.ErrLeaseLost
+	}
+	s.leases[id] = time.Now().Add(s.lease)
+	return nil
+}
+
+func (s *memoryJobStore) Reclaim(ctx context.Context, kinds []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requeued := 0
+	for _, job := range s.jobs {
+		expires, ok := s.leases[job.ID]
+		if !ok || job.Status != models.JobRunning || time.Now().Before(expires) {
+			continue
+		}
+		for _, kind := range kinds {
+			if job.Kind == kind {
+				job.Status = models.JobQueued
+				job.StartedAt = nil
+				delete(s.leases, job.ID)
+				requeued++
+			}
+		}
+	}
+	return requeued, nil
+}
+
+func (s *memoryJobStore) Update(ctx context.Context, job *models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.find(job.ID)
+	if !ok {
+		return jobs.ErrNotFound
+	}
+	copied := *job
+	s.jobs[i] = &copied
+	s.progress = append(s.progress, job.Progress)
+	return nil
+}
+
+func (s *memoryJobStore) Finish(ctx context.Context, job *models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.find(job.ID)
+	if !ok {
+		return jobs.ErrNotFound
+	}
+	now := time.Now()
+	job.FinishedAt = &now
+	copied := *job
+	s.jobs[i] = &copied
+	delete(s.cancels, job.ID)
+	delete(s.leases, job.ID)
+	return nil
+}
+
+func (s *memoryJobStore) Cancel(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.find(id)
+	if !ok {
+		return nil, jobs.ErrNotFound
+	}
+	job := s.jobs[i]
+	switch {
+	case job.Done():
+		return nil, jobs.ErrFinished
+	case job.Status == models.JobQueued:
+		now := time.Now()
+		job.Status = models.JobCancelled
+		job.FinishedAt = &now
+	default:
+		s.cancels[id] = true
+	}
+	copied := *job
+	copied.CancelRequested = s.cancels[id]
+	return &copied, nil
+}
+
+func (s *memoryJobStore) CancelRequested(ctx context.Context, id uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancels[id], nil
+}
+
+// trainingRepository serves stored results for retraining
+type trainingRepository struct {
+	repository.Repository
+	records []*models.AnomalyData
+}
+
+func (r *trainingRepository) ListNormalAnomalyData(since time.Time, limit int) ([]*models.AnomalyData, error) {
+	var records []*models.AnomalyData
+	for _, record := range r.records {
+		if !record.IsAnomaly && !record.CreatedAt.Before(since) && len(records) < limit {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func testJobsConfig() config.JobsConfig {
+	cfg := config.Defaults().Worker.Jobs
+	cfg.PollInterval = 5 * time.Millisecond
+	cfg.LeaseTimeout = 50 * time.Millisecond
+	return cfg
+}
+
+func TestJobRunner(t *testing.T) {
+	store := newMemoryJobStore()
+	service := services.NewJobService(store, testJobsConfig(), zap.NewNop())
+	runner := jobs.NewRunner(store, testJobsConfig(), zap.NewNop())
+	ctx := context.Background()
+
+	runner.Register("count", func(ctx context.Context, job *models.Job, progress jobs.Progress) (interface{}, error) {
+		var params struct{ To int }
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, err
+		}
+		for i := 1; i <= params.To; i++ {
+			progress(float64(i)/float64(params.To), "counting")
+		}
+		return map[string]int{"counted": params.To}, nil
+	})
+	runner.Register("fail", func(ctx context.Context, job *models.Job, progress jobs.Progress) (interface{}, error) {
+		progress(0.5, "halfway")
+		return nil, errors.New("dataset unavailable")
+	})
+	runner.Register("panic", func(ctx context.Context, job *models.Job, progress jobs.Progress) (interface{}, error) {
+		panic("bad state")
+	})
+
+	adminID := uuid.New()
+	counted, _ := service.SubmitJob(ctx, "count", map[string]int{"To": 4}, adminID)
+	failed, _ := service.SubmitJob(ctx, "fail", nil, adminID)
+	panicked, _ := service.SubmitJob(ctx, "panic", nil, adminID)
+	unknown, _ := service.SubmitJob(ctx, "export", nil, adminID)
+	if counted.Status != models.JobQueued || counted.CreatedBy != adminID {
+		t.Fatalf("Expected a queued job created by the admin, got %+v", counted)
+	}
+
+	for i := 0; i < 3; i++ {
+		if ran, err := runner.RunNext(ctx); err != nil || !ran {
+			t.Fatalf("Expected job %d to run, got ran=%v err=%v", i, ran, err)
+		}
+	}
+	if ran, err := runner.RunNext(ctx); err != nil || ran {
+		t.Fatalf("Expected no job of a registered kind to be left, got ran=%v err=%v", ran, err)
+	}
+
+	job, err := service.GetJob(ctx, counted.ID)
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if job.Status != models.JobSucceeded || job.Progress != 1 || job.FinishedAt == nil {
+		t.Errorf("Expected a finished, successful job, got %+v", job)
+	}
+	if string(job.Result) != `{"counted":4}` {
+		t.Errorf("Expected the handler's result, got %s", job.Result)
+	}
+	if want := []float64{0.25, 0.5, 0.75, 1}; len(store.progress) < len(want) || store.progress[0] != want[0] || store.progress[3] != want[3] {
+		t.Errorf("Expected progress %v to be recorded, got %v", want, store.progress)
+	}
+
+	job, _ = service.GetJob(ctx, failed.ID)
+	if job.Status != models.JobFailed || job.Error != "dataset unavailable" || job.Progress != 0.5 || len(job.Result) != 0 {
+		t.Errorf("Expected a failed job keeping its last progress, got %+v", job)
+	}
+
+	job, _ = service.GetJob(ctx, panicked.ID)
+	if job.Status != models.JobFailed || !strings.Contains(job.Error, "bad state") {
+		t.Errorf("Expected a panicking handler to fail its job, got %+v", job)
+	}
+
+	job, _ = service.GetJob(ctx, unknown.ID)
+	if job.Status != models.JobQueued {
+		t.Errorf("Expected a job of an unregistered kind to stay queued, got %s", job.Status)
+	}
+
+	if _, err := service.GetJob(ctx, uuid.New()); !errors.Is(err, services.ErrJobNotFound) || !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("Expected ErrJobNotFound for an unknown job, got %v", err)
+	}
+}
+
+// recordingTrainer records what it was trained on, and until when
+type recordingTrainer struct {
+	series   []jobs.Series
+	epochs   int
+	deadline time.Time
+}
+
+func (t *recordingTrainer) Train(ctx context.Context, series []jobs.Series, epochs int, progress func(fraction float64)) error {
+	t.series = series
+	t.epochs = epochs
+	t.deadline, _ = ctx.Deadline()
+	progress(0.5)
+	progress(1)
+	return nil
+}
+
+func (t *recordingTrainer) Convergence() (float64, int) {
+	return 0.01, t.epochs - 1
+}
+
+func TestRetrainJob(t *testing.T) {
+	dataset := filepath.Join(t.TempDir(), "dataset.json")
+	if err := os.WriteFile(dataset, []byte(`{"series":[{"name":"latency","values":[1,2,3,4]},{"name":"errors","values":[0,1]}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	repo := &trainingRepository{records: []*models.AnomalyData{
+		{Data: map[string]interface{}{"cpu": 0.4, "host": "a"}, CreatedAt: now.Add(-3 * time.Hour)},
+		{Data: map[string]interface{}{"cpu": 0.5, "mem": 0.7}, CreatedAt: now.Add(-2 * time.Hour)},
+		{Data: map[string]interface{}{"cpu": 0.99}, IsAnomaly: true, CreatedAt: now.Add(-time.Hour)},
+		{Data: map[string]interface{}{"cpu": 0.6, "mem": 0.8}, CreatedAt: now.Add(-30 * 24 * time.Hour)},
+	}}
+
+	cfg := testJobsConfig()
+	cfg.Retrain.Dataset = dataset
+	store := newMemoryJobStore()
+	service := services.NewJobService(store, cfg, zap.NewNop())
+	trainer := &recordingTrainer{}
+	runner := jobs.NewRunner(store, cfg, zap.NewNop())
+	ctx := context.Background()
+	runner.Register(jobs.KindRetrain, jobs.RetrainHandler(trainer, repo, cfg.Retrain))
+
+	retrain := func(req models.RetrainRequest) (*models.Job, jobs.RetrainResult) {
+		t.Helper()
+		queued, err := service.RetrainDetector(ctx, &req, uuid.New())
+		if err != nil {
+			t.Fatalf("RetrainDetector failed: %v", err)
+		}
+		if queued.Kind != jobs.KindRetrain {
+			t.Fatalf("Expected a %s job, got %s", jobs.KindRetrain, queued.Kind)
+		}
+		if _, err := runner.RunNext(ctx); err != nil {
+			t.Fatalf("RunNext failed: %v", err)
+		}
+		job, _ := service.GetJob(ctx, queued.ID)
+		var result jobs.RetrainResult
+		if job.Status == models.JobSucceeded {
+			if err := json.Unmarshal(job.Result, &result); err != nil {
+				t.Fatalf("Failed to decode retrain result: %v", err)
+			}
+		}
+		return job, result
+	}
+
+	job, result := retrain(models.RetrainRequest{Source: models.RetrainFromDataset, Epochs: 5})
+	if job.Status != models.JobSucceeded || job.Message != "training complete" {
+		t.Fatalf("Expected the dataset retrain to succeed, got %+v", job)
+	}
+	if result.Source != models.RetrainFromDataset || result.Series != 2 || result.Points != 6 || trainer.epochs != 5 {
+		t.Errorf("Unexpected dataset retrain result %+v (epochs %d)", result, trainer.epochs)
+	}
+	if result.Loss != 0.01 || result.ConvergedEpoch != 4 {
+		t.Errorf("Expected the trainer's loss and convergence epoch reported, got %+v", result)
+	}
+	if trainer.deadline.IsZero() || time.Until(trainer.deadline) > cfg.Retrain.Timeout {
+		t.Errorf("Expected training bounded by the %s retrain timeout, got deadline %v", cfg.Retrain.Timeout, trainer.deadline)
+	}
+
+	job, result = retrain(models.RetrainRequest{Source: models.RetrainFromStored, LookbackHours: 24})
+	if job.Status != models.JobSucceeded {
+		t.Fatalf("Expected the stored retrain to succeed, got %+v", job)
+	}
+	if result.Records != 2 || result.Series != 2 || result.Points != 3 {
+		t.Errorf("Expected 2 normal recent records giving cpu and mem series, got %+v", result)
+	}
+	if trainer.series[0].Name != "cpu" || len(trainer.series[0].Values) != 2 || trainer.series[0].Values[1] != 0.5 {
+		t.Errorf("Expected the cpu series in record order, got %+v", trainer.series)
+	}
+
+	job, _ = retrain(models.RetrainRequest{Source: models.RetrainFromStored, LookbackHours: 1})
+	if job.Status != models.JobFailed || job.Error != "no training data found" {
+		t.Errorf("Expected a retrain without data to fail, got %+v", job)
+	}
+
+	unconfigured := services.NewJobService(store, testJobsConfig(), zap.NewNop())
+	if _, err := unconfigured.RetrainDetector(ctx, &models.RetrainRequest{Source: models.RetrainFromDataset}, uuid.New()); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput without a configured dataset, got %v", err)
+	}
+}
+
+func TestJobListAndCancel(t *testing.T) {
+	store := newMemoryJobStore()
+	service := services.NewJobService(store, testJobsConfig(), zap.NewNop())
+	runner := jobs.NewRunner(store, testJobsConfig(), zap.NewNop())
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	runner.Register("replay", func(ctx context.Context, job *models.Job, progress jobs.Progress) (interface{}, error) {
+		progress(0.1, "replaying")
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	running, _ := service.SubmitJob(ctx, "replay", nil, uuid.New())
+	queued, _ := service.SubmitJob(ctx, "purge", nil, uuid.New())
+
+	list, err := service.ListJobs(ctx, "", "", 0)
+	if err != nil || len(list) != 2 || list[0].ID != queued.ID {
+		t.Fatalf("Expected both jobs, newest first, got %v (%v)", list, err)
+	}
+	if list, _ := service.ListJobs(ctx, "", "replay", 0); len(list) != 1 || list[0].ID != running.ID {
+		t.Errorf("Expected only the replay job when filtering by kind, got %v", list)
+	}
+	if _, err := service.ListJobs(ctx, "paused", "", 0); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an unknown status, got %v", err)
+	}
+
+	// A queued job is cancelled at once
+	job, err := service.CancelJob(ctx, queued.ID)
+	if err != nil || job.Status != models.JobCancelled {
+		t.Fatalf("Expected the queued job to be cancelled, got %+v (%v)", job, err)
+	}
+	if _, err := service.CancelJob(ctx, queued.ID); !errors.Is(err, services.ErrJobFinished) || !errors.Is(err, apperrors.ErrConflict) {
+		t.Errorf("Expected ErrJobFinished cancelling a finished job, got %v", err)
+	}
+
+	// A running job is asked to stop and finishes as cancelled
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := runner.RunNext(ctx); err != nil {
+			t.Errorf("RunNext failed: %v", err)
+		}
+	}()
+	<-started
+
+	job, err = service.CancelJob(ctx, running.ID)
+	if err != nil || job.Status != models.JobRunning || !job.CancelRequested {
+		t.Fatalf("Expected cancellation of the running job to be requested, got %+v (%v)", job, err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the running job to stop after cancellation")
+	}
+
+	job, _ = service.GetJob(ctx, running.ID)
+	if job.Status != models.JobCancelled || job.CancelRequested || job.Error != "" || job.FinishedAt == nil {
+		t.Errorf("Expected the running job to finish as cancelled, got %+v", job)
+	}
+	if list, _ := service.ListJobs(ctx, models.JobCancelled, "", 1); len(list) != 1 {
+		t.Errorf("Expected the limit to apply to the cancelled jobs, got %d", len(list))
+	}
+	if _, err := service.CancelJob(ctx, uuid.New()); !errors.Is(err, services.ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound cancelling an unknown job, got %v", err)
+	}
+}
+
+func TestJobLeases(t *testing.T) {
+	store := newMemoryJobStore()
+	service := services.NewJobService(store, testJobsConfig(), zap.NewNop())
+	cfg := testJobsConfig()
+	cfg.Workers = 2
+	runner := jobs.NewRunner(store, cfg, zap.NewNop())
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	runs := make(map[uuid.UUID]int)
+	run := func(ctx context.Context, job *models.Job, progress jobs.Progress) (interface{}, error) {
+		mu.Lock()
+		runs[job.ID]++
+		mu.Unlock()
+		if job.Kind == "slow" {
+			// Several leases long, renewed by the runner meanwhile
+			time.Sleep(4 * cfg.LeaseTimeout)
+		}
+		return nil, nil
+	}
+	runner.Register("quick", run)
+	runner.Register("slow", run)
+
+	// Claimed by a worker that stopped before running them
+	abandoned, _ := service.SubmitJob(ctx, "quick", nil, uuid.New())
+	cancelled, _ := service.SubmitJob(ctx, "quick", nil, uuid.New())
+	for i := 0; i < 2; i++ {
+		if job, err := store.Claim(ctx, []string{"quick"}); err != nil || job == nil {
+			t.Fatalf("Expected a job to claim, got %v (%v)", job, err)
+		}
+	}
+	if job, err := service.CancelJob(ctx, cancelled.ID); err != nil || !job.CancelRequested {
+		t.Fatalf("Expected cancellation of the claimed job to be requested, got %+v (%v)", job, err)
+	}
+	slow, _ := service.SubmitJob(ctx, "slow", nil, uuid.New())
+
+	runCtx, stop := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		runner.Start(runCtx)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		done := true
+		for _, id := range []uuid.UUID{abandoned.ID, cancelled.ID, slow.ID} {
+			if job, _ := store.Get(ctx, id); !job.Done() {
+				done = false
+			}
+		}
+		if done {
+			break
+		}
+		time.Sleep(cfg.PollInterval)
+	}
+	stop()
+	<-stopped
+
+	if job, _ := store.Get(ctx, abandoned.ID); job.Status != models.JobSucceeded || runs[abandoned.ID] != 1 {
+		t.Errorf("Expected the abandoned job to be reclaimed and run once, got %+v after %d runs", job, runs[abandoned.ID])
+	}
+	if job, _ := store.Get(ctx, cancelled.ID); job.Status != models.JobCancelled || runs[cancelled.ID] != 0 {
+		t.Errorf("Expected the job cancelled before it ran to finish without running, got %+v after %d runs", job, runs[cancelled.ID])
+	}
+	if job, _ := store.Get(ctx, slow.ID); job.Status != models.JobSucceeded || runs[slow.ID] != 1 {
+		t.Errorf("Expected the renewed job to run once, got %+v after %d runs", job, runs[slow.ID])
+	}
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/analyzers/cryptographic"
+)
+
+func TestBloomFilter(t *testing.T) {
+	bloom, err := cryptographic.NewBloomFilter(10000, 0.01)
+	if err != nil {
+		t.Fatalf("NewBloomFilter failed: %v", err)
+	}
+	for i := 0; i < 10000; i++ {
+		bloom.Add(fmt.Sprintf("%032x", i))
+	}
+
+	for i := 0; i < 10000; i++ {
+		if !bloom.MayContain(fmt.Sprintf("%032x", i)) {
+			t.Fatalf("Expected no false negatives, missed item %d", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 10000; i < 20000; i++ {
+		if bloom.MayContain(fmt.Sprintf("%032x", i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / 10000; rate > 0.02 {
+		t.Errorf("Expected a false positive rate near 1%%, got %.2f%%", rate*100)
+	}
+	if bloom.SizeBytes() > 16*1024 {
+		t.Errorf("Expected about 12 KB for 10000 items at 1%%, got %d bytes", bloom.SizeBytes())
+	}
+
+	if _, err := cryptographic.NewBloomFilter(10, 1.5); err == nil {
+		t.Error("Expected an error for a false positive rate above 1")
+	}
+}
+
+func TestKnownHashSetMatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known.txt")
+	dataset := "# leaked hashes\r\n  5D41402ABC4B2A76B9719D911017C592:42\r\n\r\n098f6bcd4621d373cade4e832627b4f6"
+	if err := os.WriteFile(path, []byte(dataset), 0o600); err != nil {
+		t.Fatalf("Failed to write dataset: %v", err)
+	}
+
+	set, err := cryptographic.LoadKnownHashSet(path, 0.01)
+	if err != nil {
+		t.Fatalf("LoadKnownHashSet failed: %v", err)
+	}
+	if set.Len() != 2 {
+		t.Errorf("Expected 2 hashes, got %d", set.Len())
+	}
+
+	hashes := []string{
+		"5d41402abc4b2a76b9719d911017c592",
+		"098F6BCD4621D373CADE4E832627B4F6",
+		"e99a18c428cb38d5f260853678922e03",
+	}
+	known, failed := set.Match(hashes)
+	if failed != 0 {
+		t.Errorf("Expected every lookup to succeed, %d failed", failed)
+	}
+	if !known["5d41402abc4b2a76b9719d911017c592"] || !known["098F6BCD4621D373CADE4E832627B4F6"] {
+		t.Errorf("Expected both dataset hashes to match, got %v", known)
+	}
+	if known["e99a18c428cb38d5f260853678922e03"] {
+		t.Error("Expected a hash outside the dataset not to match")
+	}
+
+	if _, err := cryptographic.LoadKnownHashSet(filepath.Join(t.TempDir(), "missing.txt"), 0.01); err == nil {
+		t.Error("Expected an error for a missing dataset")
+	}
+
+	// Matches are confirmed against the dataset as it was loaded: a hash now
+	// at the same offset is not taken for the one indexed there
+	if err := os.WriteFile(path, []byte(strings.Replace(dataset, "5D41402ABC", "0000000000", 1)), 0o600); err != nil {
+		t.Fatalf("Failed to rewrite dataset: %v", err)
+	}
+	if known, failed := set.Match(hashes); known["5d41402abc4b2a76b9719d911017c592"] || !known["098F6BCD4621D373CADE4E832627B4F6"] || failed != 0 {
+		t.Errorf("Expected only the unchanged hash to match, got %v with %d failures", known, failed)
+	}
+
+	// A dataset that can no longer be read leaves the hashes unknown
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove dataset: %v", err)
+	}
+	if known, failed := set.Match(hashes); len(known) != 0 || failed != 2 {
+		t.Errorf("Expected the 2 dataset hashes to fail their lookup, got %v with %d failures", known, failed)
+	}
+}
+
+func TestCryptographicAnalyzerKnownHashes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known.txt")
+	var dataset strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&dataset, "%032x\n", i*104729)
+	}
+	dataset.WriteString("5d41402abc4b2a76b9719d911017c592\n")
+	if err := os.WriteFile(path, []byte(dataset.String()), 0o600); err != nil {
+		t.Fatalf("Failed to write dataset: %v", err)
+	}
+
+	analyzer := cryptographic.NewCryptographicAnalyzer()
+	if err := analyzer.Configure(map[string]interface{}{
+		"known_hash_false_positive_rate": 0.01,
+		"known_hash_file":                path,
+	}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	text := "Stored hashes: 5d41402abc4b2a76b9719d911017c592 and e99a18c428cb38d5f260853678922e03"
+	result, err := analyzer.Analyze(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Cryptographic analysis failed: %v", err)
+	}
+	if known, _ := result.Metadata["known_hashes"].(int); known != 1 {
+		t.Errorf("Expected 1 known hash, got %v", result.Metadata["known_hashes"])
+	}
+
+	// Losing the dataset leaves the hashes unknown rather than failing the
+	// analysis
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove dataset: %v", err)
+	}
+	result, err = analyzer.Analyze(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Expected the analysis to succeed without the dataset, got %v", err)
+	}
+	if result.Metadata["known_hashes"] != 0 || result.Metadata["known_hash_failures"] != 1 {
+		t.Errorf("Expected the known hash counted as a failed lookup, got %v known and %v failed", result.Metadata["known_hashes"], result.Metadata["known_hash_failures"])
+	}
+
+	if err := analyzer.LoadKnownHashBloom(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected an error for a missing dataset")
+	}
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/language"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+	"go.uber.org/zap"
+)
+
+// languageProbe records the language detection its context carries
+type languageProbe struct {
+	name      string
+	mu        sync.Mutex
+	detection *language.Detection
+	shared    bool
+}
+
+func (a *languageProbe) Name() string { return a.name }
+
+func (a *languageProbe) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	a.mu.Lock()
+	a.detection, a.shared = language.FromContext(ctx)
+	a.mu.Unlock()
+	return &models.AnalysisResult{Score: 0.3, Confidence: 0.8}, nil
+}
+
+func TestSharedLanguageDetection(t *testing.T) {
+	text := "The committee met on Thursday. Everyone agreed that the plan needed more detail before a vote."
+	first := &languageProbe{name: "entropy"}
+	second := &languageProbe{name: "linguistic"}
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(first)
+	detector.RegisterAnalyzer(second)
+
+	result, err := detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if !first.shared || first.detection != second.detection {
+		t.Fatalf("Expected one detection handed to every analyzer, got %+v and %+v", first.detection, second.detection)
+	}
+	if result.Language != "en" || result.Language != first.detection.Code || result.LanguageConfidence != first.detection.Confidence {
+		t.Errorf("Expected the detected language at the top level, got %q (%g)", result.Language, result.LanguageConfidence)
+	}
+
+	cfg := config.Defaults().Detector
+	cfg.Language.Shared = false
+	if err := detector.ApplyConfig(cfg); err != nil {
+		t.Fatalf("Applying the configuration failed: %v", err)
+	}
+	result, err = detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if first.shared || second.shared || result.Language != "" || result.LanguageConfidence != 0 {
+		t.Errorf("Expected no shared detection with sharing off, got %+v and %q", first.detection, result.Language)
+	}
+}
+
+func TestLinguisticUsesSharedLanguage(t *testing.T) {
+	text := "Hola, me llamo Juan y vivo en Madrid. Me gusta mucho leer libros y pasear por el parque con mi perro."
+	detection := &language.Detection{
+		Code:       "de",
+		Name:       "German",
+		Confidence: 0.95,
+		Reliable:   true,
+		Candidates: []language.Candidate{{Code: "de", Language: "German", Confidence: 0.95}},
+	}
+
+	result, err := linguistic.NewLinguisticAnalyzer().Analyze(language.NewContext(context.Background(), detection), text)
+	if err != nil {
+		t.Fatalf("Linguistic analysis failed: %v", err)
+	}
+	if result.Metadata["detected_language"] != "German" || result.Metadata["language_confidence"] != 0.95 {
+		t.Errorf("Expected the shared detection used instead of detecting again, got %v", result.Metadata["detected_language"])
+	}
+
+	tokens := tokenizer.New(nil, text)
+	if tokens.Language() != tokens.Language() {
+		t.Error("Expected the tokens to detect the language once")
+	}
+}
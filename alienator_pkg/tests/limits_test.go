@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/api/rest"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+func newLimitsRouter(limits config.LimitsConfig) (*gin.Engine, *middleware.RequestLimits) {
+	gin.SetMode(gin.TestMode)
+	anomalyService := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	handler := rest.NewHandler(nil, anomalyService, nil, nil, zap.NewNop(), zap.NewAtomicLevel())
+	requestLimits := middleware.NewRequestLimits(limits)
+	handler.SetLimits(requestLimits, middleware.NewRateLimiter(config.RateLimitConfig{
+		RequestsPerMinute: 120,
+		Burst:             20,
+		Groups:            []config.RateLimitGroup{{Name: "health", Paths: []string{"/health"}, RequestsPerMinute: 600, Burst: 60}},
+	}))
+
+	router := gin.New()
+	router.Use(requestLimits.Handler())
+	setUser := func(c *gin.Context) { c.Set("user_id", uuid.New()) }
+	router.GET("/limits", handler.GetLimits)
+	router.POST("/detect", setUser, handler.DetectAnomaly)
+	router.POST("/detect/ndjson", setUser, handler.DetectAnomalyNDJSON)
+	requestLimits.ExemptRoute("/detect/ndjson")
+	return router, requestLimits
+}
+
+func readNDJSONResults(t *testing.T, body string) []models.BulkDetectionResult {
+	t.Helper()
+	var results []models.BulkDetectionResult
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		var result models.BulkDetectionResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("Invalid output line %q: %v", scanner.Text(), err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestGetLimitsReportsConfig(t *testing.T) {
+	router, requestLimits := newLimitsRouter(config.Defaults().Limits)
+
+	var response struct {
+		Data models.RequestLimits `json:"data"`
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/limits", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	expected := models.RequestLimits{
+		MaxBodyBytes:     1 << 20,
+		MaxBatchItems:    1000,
+		MaxLineBytes:     1 << 20,
+		RequestTimeoutMS: 30000,
+		RateLimit: models.RateLimitPolicy{
+			RequestsPerMinute: 120,
+			Burst:             20,
+			Groups:            []models.RateLimitGroupPolicy{{Name: "health", Paths: []string{"/health"}, RequestsPerMinute: 600, Burst: 60}},
+		},
+	}
+	if !reflect.DeepEqual(response.Data, expected) {
+		t.Errorf("Expected limits %+v, got %+v", expected, response.Data)
+	}
+
+	requestLimits.Update(config.LimitsConfig{MaxBodyBytes: 512, MaxLineBytes: 256})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/limits", nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response.Data.MaxBodyBytes != 512 || response.Data.MaxLineBytes != 256 || response.Data.MaxBatchItems != 0 || response.Data.RequestTimeoutMS != 0 {
+		t.Errorf("Expected updated limits to be reported, got %+v", response.Data)
+	}
+}
+
+func TestRequestLimitsRejectLargeBody(t *testing.T) {
+	router, _ := newLimitsRouter(config.LimitsConfig{MaxBodyBytes: 64, MaxLineBytes: 1024})
+	body := `{"data": {"x": 150}, "text": "` + strings.Repeat("a", 100) + `"}`
+
+	// Declared length over the limit
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/detect", strings.NewReader(body)))
+	assertBodyTooLarge(t, rec, int64(len(body)))
+
+	// Unknown length, cut off while decoding
+	req := httptest.NewRequest(http.MethodPost, "/detect", strings.NewReader(body))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assertBodyTooLarge(t, rec, 65)
+
+	// The NDJSON content type does not lift the limit off other routes
+	for _, length := range []int64{int64(len(body)), -1} {
+		req = httptest.NewRequest(http.MethodPost, "/detect", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		req.ContentLength = length
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assertBodyTooLarge(t, rec, max(length, 65))
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/detect", strings.NewReader(`{"data": {"x": 150}}`)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected a body within the limit to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func assertBodyTooLarge(t *testing.T, rec *httptest.ResponseRecorder, size int64) {
+	t.Helper()
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected status 413, got %d", rec.Code)
+	}
+	var response models.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != "REQUEST_TOO_LARGE" || response.Error.Details != "Limit: 64 bytes per request body" {
+		t.Errorf("Expected the body limit in the error, got %+v", response.Error)
+	}
+	// Retrying the same body can't succeed
+	if rejection := response.Error.Rejection; rejection == nil || *rejection != (models.Rejection{RetryAfter: 0, Limit: 64, Current: size}) {
+		t.Errorf("Expected a rejection of %d bytes over a 64 byte limit, got %+v", size, rejection)
+	}
+	if retryAfter := rec.Header().Get("Retry-After"); retryAfter != "" {
+		t.Errorf("Expected no Retry-After for a body too large, got %q", retryAfter)
+	}
+}
+
+func TestRequestLimitsReportTimeout(t *testing.T) {
+	router, _ := newLimitsRouter(config.LimitsConfig{MaxLineBytes: 1024, RequestTimeout: time.Nanosecond})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/detect", strings.NewReader(`{"data": {"x": 150}}`)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503, got %d", rec.Code)
+	}
+	var response models.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != "REQUEST_TIMEOUT" || response.Error.Details != "Limit: 1ns per request" {
+		t.Fatalf("Expected the timeout reported, got %+v", response.Error)
+	}
+	// Clients told to retry after the timeout would all come back at once
+	if response.Error.Rejection != nil {
+		t.Errorf("Expected no rejection for a timeout, got %+v", response.Error.Rejection)
+	}
+	if retryAfter := rec.Header().Get("Retry-After"); retryAfter != "" {
+		t.Errorf("Expected no Retry-After, got %q", retryAfter)
+	}
+}
+
+func TestNDJSONLimits(t *testing.T) {
+	item := `{"data": {"x": 1}}`
+
+	router, _ := newLimitsRouter(config.LimitsConfig{MaxBodyBytes: 16, MaxBatchItems: 2, MaxLineBytes: 1024})
+	req := httptest.NewRequest(http.MethodPost, "/detect/ndjson", strings.NewReader(strings.Repeat(item+"\n", 3)))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	results := readNDJSONResults(t, rec.Body.String())
+	if len(results) != 3 || results[0].Result == nil || results[1].Result == nil {
+		t.Fatalf("Expected two results and an error line despite the body limit, got %+v", results)
+	}
+	if err := results[2].Error; err == nil || err.Code != "BATCH_TOO_LARGE" || err.Details != "Limit: 2 items per request" || results[2].Line != 3 {
+		t.Errorf("Expected the batch limit reported on line 3, got %+v", results[2])
+	}
+
+	router, _ = newLimitsRouter(config.LimitsConfig{MaxLineBytes: 32})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/detect/ndjson",
+		strings.NewReader(item+"\n"+`{"data": {"x": 1}, "text": "`+strings.Repeat("a", 64)+`"}`+"\n")))
+	results = readNDJSONResults(t, rec.Body.String())
+	if len(results) != 2 || results[0].Result == nil {
+		t.Fatalf("Expected a result and an error line, got %+v", results)
+	}
+	if err := results[1].Error; err == nil || err.Code != "LINE_TOO_LARGE" || err.Details != "Limit: 32 bytes per line" {
+		t.Errorf("Expected the line limit reported, got %+v", results[1])
+	}
+
+	router, _ = newLimitsRouter(config.LimitsConfig{MaxLineBytes: 1024, RequestTimeout: time.Nanosecond})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/detect/ndjson", strings.NewReader(item+"\n")))
+	results = readNDJSONResults(t, rec.Body.String())
+	if len(results) != 1 || results[0].Error == nil || results[0].Error.Code != "REQUEST_TIMEOUT" || results[0].Error.Details != "Limit: 1ns per request" {
+		t.Errorf("Expected the timeout reported, got %+v", results)
+	}
+}
+
+func TestLimitsConfigValidation(t *testing.T) {
+	for name, mutate := range map[string]func(*config.LimitsConfig){
+		"negative body":    func(l *config.LimitsConfig) { l.MaxBodyBytes = -1 },
+		"negative batch":   func(l *config.LimitsConfig) { l.MaxBatchItems = -1 },
+		"zero line":        func(l *config.LimitsConfig) { l.MaxLineBytes = 0 },
+		"negative timeout": func(l *config.LimitsConfig) { l.RequestTimeout = -time.Second },
+	} {
+		cfg := config.Defaults()
+		mutate(&cfg.Limits)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected %s limit to be rejected", name)
+		}
+	}
+
+	cfg := config.Defaults()
+	cfg.Limits = config.LimitsConfig{MaxLineBytes: 1}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected zero limits to be accepted as disabled, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/logging"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLevel(t *testing.T) {
+	for name, expected := range map[string]zapcore.Level{
+		"":      zapcore.InfoLevel,
+		"debug": zapcore.DebugLevel,
+		"INFO":  zapcore.InfoLevel,
+		"Warn":  zapcore.WarnLevel,
+		"error": zapcore.ErrorLevel,
+	} {
+		level, err := logging.ParseLevel(name)
+		if err != nil || level != expected {
+			t.Errorf("ParseLevel(%q) = %s, %v; expected %s", name, level, err, expected)
+		}
+	}
+
+	for _, name := range []string{"verbose", "warning!", "5"} {
+		if _, err := logging.ParseLevel(name); err == nil {
+			t.Errorf("Expected ParseLevel(%q) to fail", name)
+		}
+	}
+}
+
+// newCapturedLogger builds a logger with NewLogger whose standard error
+// output goes to a file, returned with a function reading what was logged
+func newCapturedLogger(t *testing.T, cfg config.LoggingConfig) (*zap.Logger, zap.AtomicLevel, func() string) {
+	t.Helper()
+	output, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatalf("Failed to create log output: %v", err)
+	}
+	t.Cleanup(func() { output.Close() })
+
+	stderr := os.Stderr
+	os.Stderr = output
+	logger, level, err := logging.NewLogger(cfg)
+	os.Stderr = stderr
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+
+	return logger, level, func() string {
+		logger.Sync()
+		content, err := os.ReadFile(output.Name())
+		if err != nil {
+			t.Fatalf("Failed to read log output: %v", err)
+		}
+		return string(content)
+	}
+}
+
+func TestNewLoggerWritesFields(t *testing.T) {
+	logger, _, logged := newCapturedLogger(t, config.LoggingConfig{Level: "info", Format: "json"})
+	logger.Debug("Filtered out")
+	logger.Info("Analysis finished", zap.String("request_id", "r1"), zap.Float64("score", 0.5))
+
+	lines := strings.Split(strings.TrimSpace(logged()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected one entry at info level, got %q", lines)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Expected a JSON entry, got %q: %v", lines[0], err)
+	}
+	if entry["level"] != "info" || entry["msg"] != "Analysis finished" || entry["request_id"] != "r1" || entry["score"] != 0.5 {
+		t.Errorf("Expected the level, message and fields in the entry, got %v", entry)
+	}
+
+	logger, _, logged = newCapturedLogger(t, config.LoggingConfig{Level: "debug", Format: "console"})
+	logger.Debug("Cache miss", zap.String("analyzer", "entropy"))
+	if output := logged(); !strings.Contains(output, "DEBUG") || !strings.Contains(output, "Cache miss") || !strings.Contains(output, `{"analyzer": "entropy"}`) {
+		t.Errorf("Expected the console entry with its fields, got %q", output)
+	}
+}
+
+func TestNewLoggerRejectsInvalidConfig(t *testing.T) {
+	if _, _, err := logging.NewLogger(config.LoggingConfig{Level: "loud"}); err == nil {
+		t.Error("Expected an invalid level to be rejected")
+	}
+	if _, _, err := logging.NewLogger(config.LoggingConfig{Format: "xml"}); err == nil {
+		t.Error("Expected an invalid format to be rejected")
+	}
+}
+
+func TestReloadLevel(t *testing.T) {
+	logger, level, logged := newCapturedLogger(t, config.LoggingConfig{Level: "warn"})
+	apply := logging.ReloadLevel(level)
+
+	cfg := config.Defaults()
+	cfg.Logging.Level = "debug"
+	if err := apply(cfg); err != nil {
+		t.Fatalf("ReloadLevel failed: %v", err)
+	}
+	logger.Debug("Now visible")
+	if output := logged(); !strings.Contains(output, "Now visible") {
+		t.Errorf("Expected debug entries after reloading the level, got %q", output)
+	}
+
+	cfg.Logging.Level = "chatty"
+	if err := apply(cfg); err == nil {
+		t.Error("Expected an invalid level to be rejected")
+	}
+	if level.Level() != zapcore.DebugLevel {
+		t.Errorf("Expected a rejected level to leave debug in effect, got %s", level.Level())
+	}
+}
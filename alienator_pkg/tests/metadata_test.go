@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/api/rest"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+// detectMetadata runs a detection through the REST handler and returns the
+// metadata of its result, nil if the request failed
+func detectMetadata(t *testing.T, handler *rest.Handler, query string) map[string]interface{} {
+	t.Helper()
+	router := gin.New()
+	router.POST("/detect", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+	}, handler.DetectAnomaly)
+
+	body := `{"data": {"x": 150, "y": 2}}`
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/detect"+query, strings.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		return nil
+	}
+	var response struct {
+		Data struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	return response.Data.Metadata
+}
+
+func TestDetectAnomalyMetadataLevels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	anomalyService := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	handler := rest.NewHandler(nil, anomalyService, nil, nil, zap.NewNop(), zap.NewAtomicLevel())
+
+	summary := detectMetadata(t, handler, "")
+	if _, ok := summary["features"]; ok || summary["explanations"] == nil {
+		t.Errorf("Expected the summary to leave out only the features by default, got %v", summary)
+	}
+	full := detectMetadata(t, handler, "?metadata=full")
+	if features, _ := full["features"].(map[string]interface{}); len(features) != 3 {
+		t.Errorf("Expected every feature with full metadata, got %v", full)
+	}
+	// Explanations and suggestions are always in the output, if only as null
+	if none := detectMetadata(t, handler, "?metadata=none"); len(none) != 2 || none["explanations"] != nil || none["suggestions"] != nil {
+		t.Errorf("Expected no metadata at level none, got %v", none)
+	}
+	if invalid := detectMetadata(t, handler, "?metadata=verbose"); invalid != nil {
+		t.Errorf("Expected an unsupported level rejected, got %v", invalid)
+	}
+
+	handler.SetMetadataFilter(models.MetadataFilter{Level: models.MetadataNone, Keys: []string{"x", "suggestions"}})
+	allowed := detectMetadata(t, handler, "")
+	if features, _ := allowed["features"].(map[string]interface{}); len(features) != 1 || features["x"] != 150.0 {
+		t.Errorf("Expected only the allowed feature kept, got %v", allowed)
+	}
+	if allowed["explanations"] != nil || allowed["suggestions"] == nil {
+		t.Errorf("Expected only the allowed fields kept, got %v", allowed)
+	}
+}
+
+func TestAnomalyResultFiltered(t *testing.T) {
+	result := &models.AnomalyResult{
+		Score:    0.7,
+		Metadata: map[string]interface{}{"content_type": "prose", "votes": 3},
+		Details: map[string]*models.AnalysisResult{
+			"linguistic": {Score: 0.6, Metadata: map[string]interface{}{"detected_language": "English", "avg_word_length": 4.2}},
+			"entropy":    {Score: 0.8, Metadata: map[string]interface{}{"entropy": 4.1}},
+		},
+	}
+
+	if full := result.Filtered(models.MetadataFilter{Level: models.MetadataFull}); full != result {
+		t.Error("Expected the full result left as it is")
+	}
+	summary := result.Filtered(models.MetadataFilter{Level: models.MetadataSummary, Keys: []string{"detected_language"}})
+	if len(summary.Metadata) != 2 || summary.Details["entropy"].Metadata != nil || summary.Details["entropy"].Score != 0.8 {
+		t.Errorf("Expected the aggregate metadata kept and the analyzers' left out, got %+v", summary)
+	}
+	if linguistic := summary.Details["linguistic"].Metadata; len(linguistic) != 1 || linguistic["detected_language"] != "English" {
+		t.Errorf("Expected the allowed analyzer key kept, got %v", linguistic)
+	}
+	none := result.Filtered(models.MetadataFilter{Level: models.MetadataNone, Keys: []string{"content_type"}})
+	if len(none.Metadata) != 1 || none.Metadata["content_type"] != "prose" {
+		t.Errorf("Expected only the allowed aggregate key kept, got %v", none.Metadata)
+	}
+	if len(result.Metadata) != 2 || len(result.Details["linguistic"].Metadata) != 2 {
+		t.Error("Expected the result itself left as computed")
+	}
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// newTestMetrics returns metrics on a registry of their own
+func newTestMetrics() (*metrics.Metrics, *prometheus.Registry) {
+	registry := prometheus.NewRegistry()
+	return metrics.NewMetricsWith(registry, registry), registry
+}
+
+// gatherAnalyzerMetrics returns the counter values, and the sample counts and
+// sums of the histograms, keyed by metric and labels
+func gatherAnalyzerMetrics(t testing.TB, registry *prometheus.Registry) map[string]float64 {
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range metric.GetLabel() {
+				key += fmt.Sprintf(",%s=%s", label.GetName(), label.GetValue())
+			}
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				values[key] = metric.GetCounter().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				values[key+",count"] = float64(metric.GetHistogram().GetSampleCount())
+				values[key+",sum"] = metric.GetHistogram().GetSampleSum()
+			}
+		}
+	}
+	return values
+}
+
+func testAnalyzerRuns(i int) []metrics.AnalyzerRun {
+	return []metrics.AnalyzerRun{
+		{Analyzer: "entropy", Status: metrics.AnalyzerStatusSuccess},
+		{Analyzer: "linguistic", Status: metrics.AnalyzerStatusSuccess},
+		{Analyzer: "embedding", Status: []string{metrics.AnalyzerStatusError, metrics.AnalyzerStatusTimeout}[i%2]},
+	}
+}
+
+func TestBatchedAnalyzerMetrics(t *testing.T) {
+	direct, directRegistry := newTestMetrics()
+	batched, batchedRegistry := newTestMetrics()
+	if err := batched.EnableBatching(metrics.BatchConfig{FlushInterval: time.Hour, MaxPending: 64, Shards: 4}); err != nil {
+		t.Fatalf("EnableBatching failed: %v", err)
+	}
+	if err := batched.EnableBatching(metrics.DefaultBatchConfig()); err == nil {
+		t.Error("Expected batching to be enabled only once")
+	}
+
+	for i := 0; i < 10; i++ {
+		direct.RecordAnalyzerRuns(testAnalyzerRuns(i))
+		batched.RecordAnalyzerRuns(testAnalyzerRuns(i))
+	}
+	if held := gatherAnalyzerMetrics(t, batchedRegistry); held["analysis_requests_total,analyzer=entropy,status=success"] != 0 {
+		t.Errorf("Expected updates held until flushed, got %v", held)
+	}
+	batched.Flush()
+	expected := gatherAnalyzerMetrics(t, directRegistry)
+	if expected["analysis_requests_total,analyzer=embedding,status=timeout"] != 5 || expected["analysis_errors_total,analyzer=embedding,error_type=timeout"] != 5 ||
+		expected["analysis_requests_total,analyzer=linguistic,status=success"] != 10 {
+		t.Fatalf("Unexpected direct metrics: %v", expected)
+	}
+	compareMetrics(t, expected, gatherAnalyzerMetrics(t, batchedRegistry))
+
+	// A full shard is written without waiting for the interval
+	for i := 0; i < 100; i++ {
+		batched.RecordAnalyzerRuns(testAnalyzerRuns(i))
+		direct.RecordAnalyzerRuns(testAnalyzerRuns(i))
+	}
+	if got := gatherAnalyzerMetrics(t, batchedRegistry)["analysis_requests_total,analyzer=entropy,status=success"]; got <= 10 {
+		t.Errorf("Expected full shards to be flushed, got %v entropy runs", got)
+	}
+
+	batched.Close()
+	compareMetrics(t, gatherAnalyzerMetrics(t, directRegistry), gatherAnalyzerMetrics(t, batchedRegistry))
+	batched.RecordAnalyzerRuns(testAnalyzerRuns(0))
+	if got := gatherAnalyzerMetrics(t, batchedRegistry)["analysis_requests_total,analyzer=entropy,status=success"]; got != 111 {
+		t.Errorf("Expected updates recorded directly once closed, got %v entropy runs", got)
+	}
+
+	if err := direct.EnableBatching(metrics.BatchConfig{FlushInterval: time.Second}); err == nil {
+		t.Error("Expected a batch config without a size to be rejected")
+	}
+}
+
+func compareMetrics(t *testing.T, expected, got map[string]float64) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d series, got %d: %v", len(expected), len(got), got)
+	}
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("Expected %s to be %v, got %v", key, value, got[key])
+		}
+	}
+}
+
+func TestDetectorRecordsAnalyzerMetrics(t *testing.T) {
+	m, registry := newTestMetrics()
+	detector := core.NewAnomalyDetector(zap.NewNop(), m)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.5, confidence: 0.8})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.9, confidence: 0.8})
+
+	for i := 0; i < 3; i++ {
+		if _, err := detector.AnalyzeText("An unusual text."); err != nil {
+			t.Fatalf("Analysis failed: %v", err)
+		}
+	}
+	values := gatherAnalyzerMetrics(t, registry)
+	for _, name := range []string{"entropy", "linguistic"} {
+		if values["analysis_requests_total,analyzer="+name+",status=success"] != 3 {
+			t.Errorf("Expected three successful %s runs recorded, got %v", name, values)
+		}
+	}
+	// The runs are counted in the existing series only
+	for key := range values {
+		if strings.HasPrefix(key, "analyzer_duration_seconds") || strings.HasPrefix(key, "analyzer_scores") {
+			t.Errorf("Expected no per-analyzer histograms, got %s", key)
+		}
+	}
+}
+
+// The batched benchmark should scale with parallelism where the direct one
+// contends on the shared series
+func benchmarkRecordAnalyzerRuns(b *testing.B, m *metrics.Metrics) {
+	runs := testAnalyzerRuns(0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			m.RecordAnalyzerRuns(runs)
+		}
+	})
+	b.StopTimer()
+	m.Close()
+}
+
+func BenchmarkRecordAnalyzerRunsDirect(b *testing.B) {
+	m, _ := newTestMetrics()
+	benchmarkRecordAnalyzerRuns(b, m)
+}
+
+func BenchmarkRecordAnalyzerRunsBatched(b *testing.B) {
+	m, _ := newTestMetrics()
+	if err := m.EnableBatching(metrics.DefaultBatchConfig()); err != nil {
+		b.Fatalf("EnableBatching failed: %v", err)
+	}
+	benchmarkRecordAnalyzerRuns(b, m)
+}
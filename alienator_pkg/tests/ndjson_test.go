@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/api/rest"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestDetectAnomalyNDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	anomalyService := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	handler := rest.NewHandler(nil, anomalyService, nil, nil, zap.NewNop(), zap.NewAtomicLevel())
+
+	router := gin.New()
+	router.POST("/detect/ndjson", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+	}, handler.DetectAnomalyNDJSON)
+
+	body := strings.Join([]string{
+		`{"id": "a", "data": {"x": 150}}`,
+		``,
+		`{"id": "b", "data": {}}`,
+		`not json`,
+		`{"id": "c", "data": {"x": 1}, "threshold": 0.9}`,
+	}, "\n")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/detect/ndjson", strings.NewReader(body)))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	if ct := recorder.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON content type, got %q", ct)
+	}
+
+	var results []models.BulkDetectionResult
+	scanner := bufio.NewScanner(recorder.Body)
+	for scanner.Scan() {
+		var result models.BulkDetectionResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("Invalid output line %q: %v", scanner.Text(), err)
+		}
+		results = append(results, result)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("Expected one output line per non-blank input line, got %d", len(results))
+	}
+	if results[0].ID != "a" || results[0].Result == nil || !results[0].Result.IsAnomaly {
+		t.Errorf("Expected item a to be scored as anomalous, got %+v", results[0])
+	}
+	if results[1].ID != "b" || results[1].Line != 3 || len(results[1].ValidationErrors) == 0 {
+		t.Errorf("Expected item b to fail validation on line 3, got %+v", results[1])
+	}
+	if results[2].Line != 4 || results[2].Error == nil || results[2].Error.Code != "INVALID_REQUEST" {
+		t.Errorf("Expected malformed line 4 to report an error, got %+v", results[2])
+	}
+	if results[3].ID != "c" || results[3].Result == nil || results[3].Result.Threshold != 0.9 {
+		t.Errorf("Expected item c to be scored with its own threshold, got %+v", results[3])
+	}
+}
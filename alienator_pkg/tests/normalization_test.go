@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// lengthAnalyzer scores text by its length, times scale, so two instances
+// with different scales rank texts alike on different ranges
+type lengthAnalyzer struct {
+	name  string
+	scale float64
+}
+
+func (a *lengthAnalyzer) Name() string { return a.name }
+
+func (a *lengthAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return &models.AnalysisResult{Score: a.scale * float64(len(text)) / 100, Confidence: 0.9}, nil
+}
+
+func newNormalizedDetector(t *testing.T, strategy string) *core.AnomalyDetector {
+	t.Helper()
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&lengthAnalyzer{name: "wide", scale: 1})
+	detector.RegisterAnalyzer(&lengthAnalyzer{name: "narrow", scale: 0.1})
+	policy := core.NormalizationPolicy{Strategy: strategy, Window: 20, MinSamples: 5}
+	if err := detector.SetNormalizationPolicy(policy); err != nil {
+		t.Fatalf("Expected the policy to be accepted: %v", err)
+	}
+	for length := 10; length <= 80; length += 10 {
+		if _, err := detector.AnalyzeTextAs(strings.Repeat("a", length), core.ContentTypeProse); err != nil {
+			t.Fatalf("Analysis failed: %v", err)
+		}
+	}
+	return detector
+}
+
+func TestScoreNormalizationPutsAnalyzersOnOneScale(t *testing.T) {
+	longest := strings.Repeat("a", 80)
+
+	raw, err := newNormalizedDetector(t, core.NormalizationNone).AnalyzeTextAs(longest, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if raw.IsAnomalous || raw.Metadata["normalization"] != nil {
+		t.Errorf("Expected the narrow analyzer to drag the raw score down, got %.2f", raw.Score)
+	}
+
+	cases := []struct {
+		strategy string
+		expected float64
+	}{
+		{core.NormalizationMinMax, 1},
+		{core.NormalizationPercentile, 15.0 / 16},
+	}
+	for _, tc := range cases {
+		result, err := newNormalizedDetector(t, tc.strategy).AnalyzeTextAs(longest, core.ContentTypeProse)
+		if err != nil {
+			t.Fatalf("Analysis failed: %v", err)
+		}
+		if math.Abs(result.Score-tc.expected) > 1e-9 || !result.IsAnomalous {
+			t.Errorf("%s: expected the top text of both analyzers to score %.3f, got %.3f", tc.strategy, tc.expected, result.Score)
+		}
+		scores, ok := result.Metadata["normalized_scores"].(map[string]float64)
+		if result.Metadata["normalization"] != tc.strategy || !ok || len(scores) != 2 {
+			t.Errorf("%s: expected the normalization in metadata, got %v", tc.strategy, result.Metadata)
+		}
+		if result.Details["narrow"].Score != 0.08 {
+			t.Errorf("%s: expected analyzer details to keep raw scores, got %.3f", tc.strategy, result.Details["narrow"].Score)
+		}
+	}
+
+	// At the baseline mean the z-score is 0, which the normal CDF maps to 0.5
+	result, err := newNormalizedDetector(t, core.NormalizationZScore).AnalyzeTextAs(strings.Repeat("a", 45), core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if math.Abs(result.Score-0.5) > 1e-9 {
+		t.Errorf("Expected an average text to score 0.5, got %.3f", result.Score)
+	}
+}
+
+func TestScoreNormalizationWaitsForBaseline(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&lengthAnalyzer{name: "wide", scale: 1})
+	if err := detector.SetNormalizationPolicy(core.NormalizationPolicy{Strategy: core.NormalizationMinMax, Window: 20, MinSamples: 5}); err != nil {
+		t.Fatalf("Expected the policy to be accepted: %v", err)
+	}
+
+	result, err := detector.AnalyzeTextAs(strings.Repeat("a", 30), core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if scores := result.Metadata["normalized_scores"].(map[string]float64); len(scores) != 0 || result.Score != 0.3 {
+		t.Errorf("Expected raw scores until the baseline is established, got %.2f and %v", result.Score, scores)
+	}
+
+	invalid := []core.NormalizationPolicy{
+		{Strategy: "softmax", Window: 20, MinSamples: 5},
+		{Strategy: core.NormalizationZScore, Window: 0, MinSamples: 1},
+		{Strategy: core.NormalizationZScore, Window: 20, MinSamples: 21},
+	}
+	for _, policy := range invalid {
+		if err := detector.SetNormalizationPolicy(policy); err == nil {
+			t.Errorf("Expected %+v to be rejected", policy)
+		}
+	}
+}
+
+func TestScoreNormalizationConfig(t *testing.T) {
+	t.Setenv("DETECTOR_NORMALIZATION", "percentile")
+	t.Setenv("DETECTOR_NORMALIZATION_WINDOW", "200")
+
+	cfg, err := config.LoadFile("")
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	expected := config.NormalizationConfig{Strategy: "percentile", Window: 200, MinSamples: 30}
+	if cfg.Detector.Normalization != expected {
+		t.Errorf("Expected normalization %+v, got %+v", expected, cfg.Detector.Normalization)
+	}
+	if err := core.NewAnomalyDetector(zap.NewNop(), nil).ApplyConfig(cfg.Detector); err != nil {
+		t.Errorf("ApplyConfig failed: %v", err)
+	}
+
+	cfg.Detector.Normalization.Strategy = "softmax"
+	cfg.Detector.Normalization.MinSamples = 500
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "detector.normalization.strategy:") || !strings.Contains(err.Error(), "detector.normalization.min_samples:") {
+		t.Errorf("Expected the strategy and min samples to be reported, got %v", err)
+	}
+}
+
+func TestScoreBaselinesOnlyRecordRequestedAnalyses(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&lengthAnalyzer{name: "wide", scale: 1})
+	if err := detector.SetNormalizationPolicy(core.NormalizationPolicy{Strategy: core.NormalizationMinMax, Window: 20, MinSamples: 2}); err != nil {
+		t.Fatalf("Expected the policy to be accepted: %v", err)
+	}
+	if err := detector.SetCodeBlockPolicy(core.CodeBlockPolicy{Mode: core.CodeBlocksSplit}); err != nil {
+		t.Fatalf("SetCodeBlockPolicy failed: %v", err)
+	}
+	if err := detector.Warmup(context.Background()); err != nil {
+		t.Fatalf("Warmup failed: %v", err)
+	}
+
+	text := "The first sentence is short. The second one runs a little longer. The third ends it."
+	// The bootstrap resamples analyze every sentence, but only the text counts
+	result, err := detector.AnalyzeTextBootstrapped(text, core.ContentTypeProse, core.DefaultBootstrapConfig())
+	if err != nil {
+		t.Fatalf("Bootstrapped analysis failed: %v", err)
+	}
+	if _, err := detector.Evidence(text); err != nil {
+		t.Fatalf("Evidence failed: %v", err)
+	}
+	if _, err := detector.TopSentences(text, result, 2); err != nil {
+		t.Fatalf("TopSentences failed: %v", err)
+	}
+	windowing := core.DefaultWindowConfig()
+	windowing.WindowSize = 30
+	if _, err := detector.AnalyzeTextWindowed(text, core.ContentTypeProse, windowing); err != nil {
+		t.Fatalf("Windowed analysis failed: %v", err)
+	}
+	labeled := []core.LabeledSample{{Text: text, Anomalous: true}, {Text: "Short.", Anomalous: false}}
+	if _, err := detector.TuneThreshold(labeled, core.ObjectiveF1); err != nil {
+		t.Fatalf("TuneThreshold failed: %v", err)
+	}
+	if _, err := detector.AnalyzeTextAs("Some prose.\n\n```\nx := 1\n```\n", core.ContentTypeAuto); err != nil {
+		t.Fatalf("Mixed analysis failed: %v", err)
+	}
+
+	// One score recorded, by the bootstrapped analysis, is short of the two needed
+	result, err = detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if scores := result.Metadata["normalized_scores"].(map[string]float64); len(scores) != 0 {
+		t.Errorf("Expected only the requested analysis in the baseline, got %v", scores)
+	}
+	result, err = detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if scores := result.Metadata["normalized_scores"].(map[string]float64); len(scores) != 1 {
+		t.Errorf("Expected the requested analyses to establish the baseline, got %v", scores)
+	}
+}
@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/sink"
+)
+
+func scoredRecord(id string, score float64, severity models.Severity) *sink.Record {
+	return sink.NewRecord(id, "test", &models.AnomalyResult{
+		Score:      score,
+		Confidence: 0.9,
+		Severity:   severity,
+		Details:    map[string]*models.AnalysisResult{"linguistic": {Score: score}},
+	})
+}
+
+func TestSlackSinkRetriesRateLimits(t *testing.T) {
+	var attempts int32
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	s, err := sink.NewSlackSink(config.SlackSinkConfig{WebhookURL: server.URL, MinScore: 0.5, MaxRetries: 2, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewSlackSink failed: %v", err)
+	}
+	if err := s.Write(context.Background(), scoredRecord("low", 0.2, models.SeverityNone)); err != nil || attempts != 0 {
+		t.Fatalf("Expected results below the minimum score to be skipped, got %v after %d posts", err, attempts)
+	}
+	if err := s.Write(context.Background(), scoredRecord("s-1", 0.9, models.SeverityHigh)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected the rate-limited post to be retried once, got %d attempts", attempts)
+	}
+	if text, _ := payload["text"].(string); !strings.HasPrefix(text, "HIGH anomaly") {
+		t.Errorf("Expected the rendered alert as text, got %v", payload["text"])
+	}
+	if blocks, _ := payload["blocks"].([]interface{}); len(blocks) == 0 {
+		t.Errorf("Expected Slack blocks, got %v", payload)
+	}
+}
+
+func TestSlackSinkPostsWithBotToken(t *testing.T) {
+	var auth string
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["channel"] == "#missing" {
+			w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	cfg := config.SlackSinkConfig{Token: "xoxb-1", Channel: "#alerts", APIURL: server.URL, Template: "{{.TopAnalyzer}}", MinScore: 0.5, Timeout: time.Second}
+	s, err := sink.NewSlackSink(cfg)
+	if err != nil {
+		t.Fatalf("NewSlackSink failed: %v", err)
+	}
+	if err := s.Write(context.Background(), scoredRecord("s-1", 0.9, models.SeverityHigh)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if auth != "Bearer xoxb-1" || payload["channel"] != "#alerts" || payload["text"] != "linguistic" {
+		t.Errorf("Unexpected chat.postMessage request: %s %v", auth, payload)
+	}
+
+	cfg.Channel = "#missing"
+	s, _ = sink.NewSlackSink(cfg)
+	if err := s.Write(context.Background(), scoredRecord("s-2", 0.9, models.SeverityHigh)); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("Expected the API error to be reported, got %v", err)
+	}
+}
+
+func TestPagerDutySinkTriggersAndResolves(t *testing.T) {
+	var events []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&event)
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":"success","dedup_key":"x"}`))
+	}))
+	defer server.Close()
+
+	s, err := sink.NewPagerDutySink(config.PagerDutySinkConfig{
+		RoutingKey: "R0UT1NG", URL: server.URL, Source: "alienator-test", MinScore: 0.8, Resolve: true, Timeout: time.Second, ResolveWindow: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewPagerDutySink failed: %v", err)
+	}
+
+	// Each detection of the same input has its own request ID
+	text := "The same input, submitted twice"
+	for _, record := range []*sink.Record{
+		scoredRecord("other", 0.1, models.SeverityNone), // never triggered, nothing to resolve
+		scoredRecord("req-1", 0.97, models.SeverityCritical).WithInput(text),
+		scoredRecord("req-2", 0.3, models.SeverityNone).WithInput(text),
+		scoredRecord("req-3", 0.3, models.SeverityNone).WithInput(text), // already resolved
+	} {
+		if err := s.Write(context.Background(), record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected a trigger and a resolve event, got %v", events)
+	}
+	trigger, resolve := events[0], events[1]
+	payload, _ := trigger["payload"].(map[string]interface{})
+	if trigger["event_action"] != "trigger" || trigger["routing_key"] != "R0UT1NG" || payload["severity"] != "critical" || payload["source"] != "alienator-test" {
+		t.Errorf("Unexpected trigger event: %v", trigger)
+	}
+	if resolve["event_action"] != "resolve" || resolve["dedup_key"] != trigger["dedup_key"] || resolve["payload"] != nil {
+		t.Errorf("Expected a resolve for the same dedup key, got %v", resolve)
+	}
+	if s.OpenIncidents() != 0 {
+		t.Errorf("Expected the resolved incident forgotten, got %d open", s.OpenIncidents())
+	}
+}
+
+func TestPagerDutySinkForgetsIncidentsPastTheResolveWindow(t *testing.T) {
+	var events []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&event)
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	window := 20 * time.Millisecond
+	s, err := sink.NewPagerDutySink(config.PagerDutySinkConfig{
+		RoutingKey: "k", URL: server.URL, MinScore: 0.8, Resolve: true, Timeout: time.Second, ResolveWindow: window,
+		// A summary of 1023 bytes followed by a character of two
+		Template: strings.Repeat("a", 1023) + "é",
+	})
+	if err != nil {
+		t.Fatalf("NewPagerDutySink failed: %v", err)
+	}
+
+	ctx := context.Background()
+	s.Write(ctx, scoredRecord("req-1", 0.9, models.SeverityHigh).WithInput("first"))
+	time.Sleep(2 * window)
+	s.Write(ctx, scoredRecord("req-2", 0.9, models.SeverityHigh).WithInput("second"))
+	if s.OpenIncidents() != 1 {
+		t.Errorf("Expected the incident older than the window dropped, got %d open", s.OpenIncidents())
+	}
+	s.Write(ctx, scoredRecord("req-3", 0.1, models.SeverityNone).WithInput("first"))
+	if len(events) != 2 {
+		t.Fatalf("Expected no resolve for the forgotten incident, got %v", events)
+	}
+
+	payload, _ := events[0]["payload"].(map[string]interface{})
+	summary, _ := payload["summary"].(string)
+	if summary != strings.Repeat("a", 1023) {
+		t.Errorf("Expected the summary cut before the split character, got %d bytes", len(summary))
+	}
+	if events[0]["dedup_key"] == events[1]["dedup_key"] {
+		t.Errorf("Expected different inputs to get different dedup keys, got %v", events[0]["dedup_key"])
+	}
+}
+
+func TestPagerDutySinkReportsRejectedEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"status":"invalid event"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	s, _ := sink.NewPagerDutySink(config.PagerDutySinkConfig{RoutingKey: "k", URL: server.URL, MinScore: 0.5, MaxRetries: 3, Timeout: time.Second})
+	err := s.Write(context.Background(), scoredRecord("doc", 0.9, models.SeverityHigh))
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected the 400 to be reported without retrying, got %v", err)
+	}
+}
+
+func TestNotifierConfigValidation(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Sinks.Slack.Enabled = true
+	cfg.Sinks.Slack.Token = "xoxb-1"
+	cfg.Sinks.PagerDuty.Enabled = true
+	cfg.Sinks.PagerDuty.MinScore = 1.5
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected invalid notifier settings to be rejected")
+	}
+	for _, want := range []string{"sinks.slack.channel", "sinks.pagerduty.routing_key", "sinks.pagerduty.min_score"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected a problem for %s, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/api/openapi"
+	"github.com/ruvnet/alienator/internal/api/rest"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestOpenAPISpecIsUpToDate(t *testing.T) {
+	generated, err := openapi.Generate(openapi.DefaultSources(".."))
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !bytes.Equal(generated, rest.OpenAPISpec()) {
+		t.Error("internal/api/rest/openapi.json is stale; run go generate ./internal/api/rest")
+	}
+}
+
+func TestOpenAPISpecMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := rest.NewHandler(core.NewAnomalyDetector(zap.NewNop(), nil), nil, nil, nil, zap.NewNop(), zap.NewAtomicLevel())
+	handler.SetJobService(&services.JobService{})
+
+	router := gin.New()
+	handler.SetupRoutes(router.Group("/api/v1"))
+	router.GET("/health", func(c *gin.Context) {})
+
+	if err := openapi.Check(rest.OpenAPISpec(), router.Routes()); err != nil {
+		t.Error(err)
+	}
+
+	router.GET("/api/v1/undocumented/:id", func(c *gin.Context) {})
+	var drift *openapi.DriftError
+	if err := openapi.Check(rest.OpenAPISpec(), router.Routes()); !errors.As(err, &drift) {
+		t.Fatalf("Expected a *openapi.DriftError, got %v", err)
+	}
+	if len(drift.Undocumented) != 1 || drift.Undocumented[0] != "GET /undocumented/{id}" || len(drift.Unserved) != 0 {
+		t.Errorf("Expected only the new route to be reported, got %+v", drift)
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/openapi.json", rest.ServeOpenAPI)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid document: %v", err)
+	}
+	if doc.OpenAPI != openapi.Version || doc.Paths["/anomalies/{id}/evidence"] == nil {
+		t.Errorf("Expected an OpenAPI %s document with the evidence route, got %s with %d paths", openapi.Version, doc.OpenAPI, len(doc.Paths))
+	}
+}
@@ -0,0 +1,434 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/models/proto"
+	"github.com/ruvnet/alienator/internal/outbox"
+	"github.com/ruvnet/alienator/internal/queue"
+	"github.com/ruvnet/alienator/internal/services"
+	"github.com/ruvnet/alienator/internal/sink"
+	"go.uber.org/zap"
+)
+
+// flakyStore fails the first failures commits, then stores records in
+// order, failing again once it holds limit of them if limit is set
+type flakyStore struct {
+	mu       sync.Mutex
+	failures int
+	limit    int
+	attempts int
+	ids      []string
+}
+
+func (s *flakyStore) SaveAnalysisRecord(record *models.AnalysisRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("connection refused")
+	}
+	if s.limit > 0 && len(s.ids) == s.limit {
+		return errors.New("connection refused")
+	}
+	s.ids = append(s.ids, record.ID)
+	return nil
+}
+
+func (s *flakyStore) stored() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ids...)
+}
+
+func outboxConfig(dir string) config.OutboxConfig {
+	return config.OutboxConfig{Enabled: true, Dir: dir, InitialBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+}
+
+func writeOutbox(t *testing.T, ob *outbox.Outbox, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		if err := ob.Write(context.Background(), sink.NewRecord(id, "worker", &models.AnomalyResult{Score: 0.5})); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+}
+
+// waitDrained waits for the outbox to commit every entry
+func waitDrained(t *testing.T, ob *outbox.Outbox) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for ob.Depth() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the outbox drained, %d entries left", ob.Depth())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOutboxCommitsInOrderWithRetries(t *testing.T) {
+	dir := t.TempDir()
+	store := &flakyStore{failures: 3}
+	ob, err := outbox.Open(outboxConfig(dir), store, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer ob.Close()
+
+	writeOutbox(t, ob, "a", "b")
+	if ob.Depth() != 2 {
+		t.Errorf("Expected 2 results waiting, got %d", ob.Depth())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ob.Run(ctx) }()
+	writeOutbox(t, ob, "c")
+	waitDrained(t, ob)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Run to stop with the context, got %v", err)
+	}
+
+	if ids := store.stored(); len(ids) != 3 || ids[0] != "a" || ids[1] != "b" || ids[2] != "c" {
+		t.Errorf("Expected every result committed in order, got %v", ids)
+	}
+	if store.attempts != 6 {
+		t.Errorf("Expected the 3 failed commits retried, got %d attempts", store.attempts)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "outbox.jsonl")); err != nil || info.Size() != 0 {
+		t.Errorf("Expected the outbox emptied once drained, got %v", info)
+	}
+}
+
+func TestOutboxKeepsUncommittedResultsAcrossRestarts(t *testing.T) {
+	dir := t.TempDir()
+	store := &flakyStore{}
+	ob, err := outbox.Open(outboxConfig(dir), store, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	writeOutbox(t, ob, "a", "b", "c")
+	ob.Close()
+
+	// A crash while writing leaves a partial entry, which is dropped
+	entries, err := os.OpenFile(filepath.Join(dir, "outbox.jsonl"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("Failed to open the entries: %v", err)
+	}
+	entries.WriteString(`{"id":"d","sour`)
+	entries.Close()
+
+	ob, err = outbox.Open(outboxConfig(dir), store, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer ob.Close()
+	if ob.Depth() != 3 {
+		t.Fatalf("Expected the 3 complete results still waiting, got %d", ob.Depth())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ob.Run(ctx) }()
+	writeOutbox(t, ob, "e")
+	waitDrained(t, ob)
+	cancel()
+	<-done
+
+	if ids := store.stored(); len(ids) != 4 || ids[0] != "a" || ids[3] != "e" {
+		t.Errorf("Expected the earlier results committed before the new one, got %v", ids)
+	}
+}
+
+// readOutboxIDs returns the IDs of the entries in the outbox file
+func readOutboxIDs(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.Open(filepath.Join(dir, "outbox.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to open the entries: %v", err)
+	}
+	defer entries.Close()
+	ids := []string{}
+	scanner := bufio.NewScanner(entries)
+	for scanner.Scan() {
+		var record sink.Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid entry %q: %v", scanner.Text(), err)
+		}
+		ids = append(ids, record.ID)
+	}
+	return ids
+}
+
+func TestOutboxCompactsCommittedEntries(t *testing.T) {
+	dir := t.TempDir()
+	store := &flakyStore{limit: 3}
+	ob, err := outbox.Open(outboxConfig(dir), store, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	writeOutbox(t, ob, "a", "b", "c", "d", "e")
+
+	// The store goes down after three commits, so the outbox never drains
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ob.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for ob.Depth() > 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 3 results committed, %d entries left", ob.Depth())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if ids := readOutboxIDs(t, dir); len(ids) != 2 || ids[0] != "d" || ids[1] != "e" {
+		t.Errorf("Expected the committed entries compacted away, got %v", ids)
+	}
+	writeOutbox(t, ob, "f")
+	ob.Close()
+
+	// The compacted outbox picks up where it left off
+	store.limit = 0
+	ob, err = outbox.Open(outboxConfig(dir), store, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer ob.Close()
+	if ob.Depth() != 3 {
+		t.Fatalf("Expected 3 results still waiting, got %d", ob.Depth())
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() { done <- ob.Run(ctx) }()
+	waitDrained(t, ob)
+	cancel()
+	<-done
+
+	if ids := store.stored(); len(ids) != 6 || ids[3] != "d" || ids[5] != "f" {
+		t.Errorf("Expected every result committed once and in order, got %v", ids)
+	}
+}
+
+// memoryMessageQueue hands out queued messages and records how each one was
+// settled
+type memoryMessageQueue struct {
+	core.MessageQueue
+
+	mu       sync.Mutex
+	messages []*proto.QueueMessage
+	acked    []string
+	nacked   []string
+}
+
+func (q *memoryMessageQueue) Dequeue(ctx context.Context, queueName string, timeout time.Duration) (*proto.QueueMessage, error) {
+	q.mu.Lock()
+	for i, msg := range q.messages {
+		if msg.QueueName == queueName {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			q.mu.Unlock()
+			return msg, nil
+		}
+	}
+	q.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	return nil, nil
+}
+
+func (q *memoryMessageQueue) Ack(ctx context.Context, messageID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.acked = append(q.acked, messageID)
+	return nil
+}
+
+func (q *memoryMessageQueue) Nack(ctx context.Context, messageID string, requeue bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nacked = append(q.nacked, messageID)
+	return nil
+}
+
+func (q *memoryMessageQueue) GetStats(queueName string) (*proto.QueueStats, error) {
+	return &proto.QueueStats{}, nil
+}
+
+// settled returns the IDs of the messages acknowledged and rejected so far
+func (q *memoryMessageQueue) settled() ([]string, []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string(nil), q.acked...), append([]string(nil), q.nacked...)
+}
+
+func (q *memoryMessageQueue) push(id string, data interface{}) {
+	encoded, _ := json.Marshal(data)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.messages = append(q.messages, &proto.QueueMessage{
+		Id:        id,
+		QueueName: "messages",
+		Message:   &proto.Message{ID: id, Data: encoded},
+	})
+}
+
+// waitSettled waits for count messages to be settled, returning the IDs of
+// those acknowledged and those rejected
+func waitSettled(t *testing.T, messages *memoryMessageQueue, count int) ([]string, []string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		acked, nacked := messages.settled()
+		if len(acked)+len(nacked) >= count {
+			return acked, nacked
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d messages settled, got %v acked and %v rejected", count, acked, nacked)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMessageConsumerWritesResultsThroughOutbox(t *testing.T) {
+	dir := t.TempDir()
+	ob, err := outbox.Open(outboxConfig(dir), &flakyStore{}, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer ob.Close()
+
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&countingAnalyzer{name: "entropy"})
+	eventBus := core.NewEventBus(core.DefaultEventBusConfig(), zap.NewNop())
+	t.Cleanup(func() { eventBus.Close() })
+	messages := &memoryMessageQueue{}
+	consumer := queue.NewMessageConsumer(detector, services.NewProcessingService(messages, eventBus, zap.NewNop()), config.ConsumerConfig{Workers: 1}, nil, zap.NewNop())
+	consumer.SetSink(ob)
+
+	// Only analysis requests have a result to keep
+	messages.push("m1", models.AnalysisRequest{ID: "r1", Text: "some text to analyze", Source: "tickets"})
+	messages.push("m2", map[string]string{"type": "spike", "severity": "high"})
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer consumer.Stop()
+
+	if acked, _ := waitSettled(t, messages, 2); len(acked) != 2 {
+		t.Fatalf("Expected both messages acknowledged, got %v", acked)
+	}
+	if ob.Depth() != 1 {
+		t.Fatalf("Expected the request's result in the outbox, got %d entries", ob.Depth())
+	}
+	entries, err := os.ReadFile(filepath.Join(dir, "outbox.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read the entries: %v", err)
+	}
+	var record sink.Record
+	if err := json.Unmarshal(entries, &record); err != nil || record.ID != "r1" || record.Source != "tickets" || record.Result == nil {
+		t.Errorf("Expected the result tagged with the request, got %s (%v)", entries, err)
+	}
+
+	// A result the outbox can't take leaves its message to be redelivered
+	ob.Close()
+	messages.push("m3", models.AnalysisRequest{Text: "more text to analyze"})
+	if acked, nacked := waitSettled(t, messages, 3); len(acked) != 2 || len(nacked) != 1 || nacked[0] != "m3" {
+		t.Errorf("Expected the message rejected for requeuing, got %v acked and %v rejected", acked, nacked)
+	}
+}
+
+func TestMessageConsumerResolvesSources(t *testing.T) {
+	dir := t.TempDir()
+	ob, err := outbox.Open(outboxConfig(dir), &flakyStore{}, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer ob.Close()
+
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&countingAnalyzer{name: "entropy"})
+	eventBus := core.NewEventBus(core.DefaultEventBusConfig(), zap.NewNop())
+	t.Cleanup(func() { eventBus.Close() })
+	messages := &memoryMessageQueue{}
+	consumer := queue.NewMessageConsumer(detector, services.NewProcessingService(messages, eventBus, zap.NewNop()), config.ConsumerConfig{Workers: 1}, nil, zap.NewNop())
+	consumer.SetSink(ob)
+	consumer.SetProvenance(config.ProvenanceConfig{Default: "queue", Sources: []string{"queue", "tickets"}})
+
+	messages.push("m1", models.AnalysisRequest{ID: "r1", Text: "some text to analyze"})
+	messages.push("m2", models.AnalysisRequest{ID: "r2", Text: "more text to analyze", Source: "billing"})
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer consumer.Stop()
+
+	acked, nacked := waitSettled(t, messages, 2)
+	if len(acked) != 1 || acked[0] != "m1" || len(nacked) != 1 || nacked[0] != "m2" {
+		t.Fatalf("Expected the request from an unknown source rejected, got %v acked and %v rejected", acked, nacked)
+	}
+	entries, err := os.ReadFile(filepath.Join(dir, "outbox.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read the entries: %v", err)
+	}
+	var record sink.Record
+	if err := json.Unmarshal(entries, &record); err != nil || record.ID != "r1" || record.Source != "queue" {
+		t.Errorf("Expected the result tagged with the default source, got %s (%v)", entries, err)
+	}
+}
+
+func TestOutboxRetriesRecordingItsOffset(t *testing.T) {
+	dir := t.TempDir()
+	store := &flakyStore{}
+	ob, err := outbox.Open(outboxConfig(dir), store, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer ob.Close()
+
+	// A directory in the way of the offset file makes recording it fail
+	blocker := filepath.Join(dir, "outbox.offset")
+	if err := os.MkdirAll(filepath.Join(blocker, "blocker"), 0o755); err != nil {
+		t.Fatalf("Failed to block the offset file: %v", err)
+	}
+	writeOutbox(t, ob, "a", "b")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ob.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(store.stored()) < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the first result committed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("Expected Run to keep retrying, it stopped with %v", err)
+	default:
+	}
+
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatalf("Failed to unblock the offset file: %v", err)
+	}
+	waitDrained(t, ob)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Run to stop with the context, got %v", err)
+	}
+	if ids := store.stored(); len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Errorf("Expected each result committed once, in order, got %v", ids)
+	}
+	if ids := readOutboxIDs(t, dir); len(ids) != 0 {
+		t.Errorf("Expected the committed entries compacted away, got %v", ids)
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/analyzers/embedding"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestParameterOverrides(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+	detector.RegisterAnalyzer(embedding.NewEmbeddingAnalyzer())
+
+	parse := func(settings ...string) []core.ParameterOverride {
+		t.Helper()
+		overrides := make([]core.ParameterOverride, 0, len(settings))
+		for _, setting := range settings {
+			override, err := core.ParseParameterOverride(setting)
+			if err != nil {
+				t.Fatalf("Expected %q to parse: %v", setting, err)
+			}
+			overrides = append(overrides, override)
+		}
+		return overrides
+	}
+
+	valid := parse("embedding.kmeans_seed=7", "embedding.include_embeddings = true", "embedding.kmeans_algorithm=mini_batch")
+	if valid[1] != (core.ParameterOverride{Analyzer: "embedding", Parameter: "include_embeddings", Value: "true"}) {
+		t.Errorf("Expected the override to be trimmed, got %+v", valid[1])
+	}
+	if err := detector.ApplyParameterOverrides(valid); err != nil {
+		t.Fatalf("Expected the overrides to apply: %v", err)
+	}
+	result, err := detector.AnalyzeText("The first sentence is here. A second one follows it. Then a third closes the text.")
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if _, ok := result.Details["embedding"].Metadata["embeddings"]; !ok {
+		t.Error("Expected the boolean override to include the embeddings")
+	}
+
+	for _, setting := range []string{"embedding", "embedding=3", ".kmeans_seed=3"} {
+		if _, err := core.ParseParameterOverride(setting); err == nil {
+			t.Errorf("Expected %q to be rejected", setting)
+		}
+	}
+
+	invalid := map[string]string{
+		"watermark.gamma=0.5":                "unknown analyzer",
+		"entropy.window=3":                   "no configurable parameters",
+		"embedding.num_clusters=8":           "unknown parameter embedding.num_clusters",
+		"embedding.kmeans_seed=seven":        "expected an integer",
+		"embedding.include_embeddings=maybe": "expected true or false",
+		"embedding.kmeans_algorithm=random":  "expected one of",
+	}
+	for setting, expected := range invalid {
+		err := detector.ApplyParameterOverrides(parse(setting))
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", setting, expected, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/config"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	listPath := filepath.Join(t.TempDir(), "common.txt")
+	if err := os.WriteFile(listPath, []byte("# top passwords\nPassword1!\nletmein\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write common passwords list: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Auth.PasswordPolicy = config.PasswordPolicyConfig{
+		MinLength:           10,
+		MaxLength:           20,
+		RequireUpper:        true,
+		RequireLower:        true,
+		RequireDigit:        true,
+		RequireSpecial:      false,
+		CommonPasswordsFile: listPath,
+	}
+	auth := services.NewAuthService(cfg, zap.NewNop())
+
+	cases := []struct {
+		password string
+		reason   string
+	}{
+		{"Short1", "at least 10 characters"},
+		{"Averyveryverylongpassword1", "at most 20 characters"},
+		{"alllowercase1", "uppercase letter"},
+		{"NoDigitsHere", "digit"},
+		{"password1!", "uppercase letter"},
+		{"PASSWORD1!", "lowercase letter"},
+		{"pASSWORD1!", "too common"},
+	}
+
+	for _, tc := range cases {
+		err := auth.ValidatePasswordStrength(tc.password)
+		if !errors.Is(err, apperrors.ErrInvalidInput) {
+			t.Errorf("Expected %q to be rejected as invalid input, got %v", tc.password, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.reason) {
+			t.Errorf("Expected %q to fail on %q, got %q", tc.password, tc.reason, err)
+		}
+	}
+
+	if err := auth.ValidatePasswordStrength("Correct1Horse"); err != nil {
+		t.Errorf("Expected password without special characters to pass, got %v", err)
+	}
+
+	policy := auth.PasswordPolicy()
+	if policy.MinLength != 10 || policy.RequireSpecial || !policy.RejectCommonPasswords {
+		t.Errorf("Unexpected active policy: %+v", policy)
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/analyzers"
+	"github.com/ruvnet/alienator/internal/analyzers/pattern"
+)
+
+// patternSeries returns a time series of the values, a second apart
+func patternSeries(values ...float64) *analyzers.TimeSeries {
+	series := &analyzers.TimeSeries{Name: "pattern"}
+	start := time.Now()
+	for i, value := range values {
+		series.DataPoints = append(series.DataPoints, analyzers.DataPoint{Timestamp: start.Add(time.Duration(i) * time.Second), Value: value})
+	}
+	return series
+}
+
+var (
+	risingValues = []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	spikeValues  = []float64{5, 5, 5, 5, 5, 50, 5, 5, 5, 5, 5, 5}
+)
+
+func newPatternMatcher(t *testing.T, config map[string]interface{}) *pattern.Matcher {
+	t.Helper()
+	matcher, err := pattern.NewMatcher(nil)
+	if err != nil {
+		t.Fatalf("NewMatcher failed: %v", err)
+	}
+	if err := matcher.Configure(config); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	return matcher
+}
+
+func TestPatternDeltaReportsRemovals(t *testing.T) {
+	cases := []struct {
+		reason string
+		config map[string]interface{}
+	}{
+		{pattern.RemovedEvicted, map[string]interface{}{"max_patterns": 50}},
+		// The short window drops the rising values, so their patterns are not
+		// detected again and expire
+		{pattern.RemovedExpired, map[string]interface{}{"pattern_ttl": "1ms", "window_size": 12}},
+	}
+	for _, tc := range cases {
+		matcher := newPatternMatcher(t, tc.config)
+		if _, err := matcher.Analyze(context.Background(), patternSeries(risingValues...)); err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+		first := matcher.GetPatternsDelta(0)
+		if !first.Reset || len(first.Patterns) == 0 || len(first.Removed) != 0 {
+			t.Fatalf("%s: expected the whole registry from cursor 0, got %d patterns and %d removals", tc.reason, len(first.Patterns), len(first.Removed))
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		since := time.Now()
+		if _, err := matcher.Analyze(context.Background(), patternSeries(spikeValues...)); err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+		delta := matcher.GetPatternsDelta(first.Cursor)
+		if delta.Reset || len(delta.Removed) == 0 || delta.Cursor <= first.Cursor {
+			t.Fatalf("%s: expected removals after the cursor, got %+v", tc.reason, delta)
+		}
+		for _, removal := range delta.Removed {
+			if removal.Reason != tc.reason || removal.Revision <= first.Cursor || removal.RemovedAt.Before(since) {
+				t.Errorf("%s: unexpected removal %+v", tc.reason, removal)
+			}
+		}
+
+		// Applying the delta brings a client's copy up to date
+		client := first.Patterns
+		for _, removal := range delta.Removed {
+			delete(client, removal.ID)
+		}
+		for id, changed := range delta.Patterns {
+			client[id] = changed
+		}
+		current := matcher.GetPatterns()
+		if len(client) != len(current) {
+			t.Errorf("%s: expected the client's %d patterns to match the registry's %d", tc.reason, len(client), len(current))
+		}
+		for id := range current {
+			if client[id] == nil {
+				t.Errorf("%s: expected the client to hold pattern %s", tc.reason, id)
+			}
+		}
+
+		if removed := matcher.GetRemovedSince(since); len(removed) != len(delta.Removed) {
+			t.Errorf("%s: expected the %d removals since the second analysis, got %d", tc.reason, len(delta.Removed), len(removed))
+		}
+		if removed := matcher.GetRemovedSince(time.Now()); len(removed) != 0 {
+			t.Errorf("%s: expected no removals after the last analysis, got %d", tc.reason, len(removed))
+		}
+	}
+}
+
+func TestPatternDeltaResetsAfterDroppedRemovals(t *testing.T) {
+	// Only the latest 3 removals are kept, so those after the first cursor
+	// are no longer all known
+	matcher := newPatternMatcher(t, map[string]interface{}{"max_patterns": 3})
+	if _, err := matcher.Analyze(context.Background(), patternSeries(risingValues...)); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	cursor := matcher.GetPatternsDelta(0).Cursor
+	if _, err := matcher.Analyze(context.Background(), patternSeries(spikeValues...)); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	delta := matcher.GetPatternsDelta(cursor)
+	if !delta.Reset || len(delta.Removed) != 0 || len(delta.Patterns) != len(matcher.GetPatterns()) {
+		t.Errorf("Expected the whole registry in place of the lost removals, got %+v", delta)
+	}
+	if latest := matcher.GetPatternsDelta(delta.Cursor); latest.Reset || len(latest.Patterns) != 0 || len(latest.Removed) != 0 {
+		t.Errorf("Expected nothing new after the latest cursor, got %+v", latest)
+	}
+}
+
+func TestPatternRegistryConcurrentAccess(t *testing.T) {
+	matcher := newPatternMatcher(t, map[string]interface{}{"max_patterns": 20, "pattern_ttl": "1ms", "window_size": 12})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			values := risingValues
+			if i%2 == 1 {
+				values = spikeValues
+			}
+			for j := 0; j < 20; j++ {
+				result, err := matcher.Analyze(context.Background(), patternSeries(values...))
+				if err != nil {
+					t.Errorf("Analyze failed: %v", err)
+					return
+				}
+				if total, _ := result.Metadata["patterns_total"].(int); total > 20 {
+					t.Errorf("Expected at most 20 patterns, got %d", total)
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			cursor := uint64(0)
+			for j := 0; j < 20; j++ {
+				delta := matcher.GetPatternsDelta(cursor)
+				if delta.Cursor < cursor {
+					t.Errorf("Expected the cursor to only move forward, went from %d to %d", cursor, delta.Cursor)
+				}
+				cursor = delta.Cursor
+				matcher.GetRemovedSince(time.Time{})
+				matcher.GetPatternsSince(time.Time{})
+				matcher.RegistrySize()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestRoundedResult(t *testing.T) {
+	if got := models.Round(0.4833333333, models.DefaultPrecision); got != 0.4833 {
+		t.Errorf("Expected 0.4833, got %v", got)
+	}
+	if got := models.Round(0.12345, 0); got != 0 {
+		t.Errorf("Expected 0 decimals to round to a whole number, got %v", got)
+	}
+	if got := models.Round(0.4833333333, models.MaxPrecision); got != 0.4833333333 {
+		t.Errorf("Expected the maximum precision to leave the value unchanged, got %v", got)
+	}
+
+	result := &models.AnomalyResult{
+		Score:        2.0 / 3,
+		Confidence:   0.123456,
+		Details:      map[string]*models.AnalysisResult{"entropy": {Score: 1.0 / 7, Confidence: 0.99999}},
+		TopSentences: []models.SentenceScore{{Score: 0.55555, Contribution: 0.011111}},
+	}
+	rounded := result.Rounded(2)
+	if rounded.Score != 0.67 || rounded.Confidence != 0.12 || rounded.Details["entropy"].Score != 0.14 ||
+		rounded.Details["entropy"].Confidence != 1 || rounded.TopSentences[0].Score != 0.56 || rounded.TopSentences[0].Contribution != 0.01 {
+		t.Errorf("Unexpected rounded result %+v", rounded)
+	}
+	if result.Score != 2.0/3 || result.Details["entropy"].Score != 1.0/7 || result.TopSentences[0].Score != 0.55555 {
+		t.Error("Expected rounding to leave the original result unchanged")
+	}
+}
+
+func TestDetectionPrecision(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 1.0 / 3, confidence: 0.9})
+	repo := &anomalyRepository{profileRepository: newProfileRepository(), anomalies: make(map[uuid.UUID]*models.AnomalyData)}
+	service := services.NewAnomalyService(repo, zap.NewNop())
+	service.SetDetector(detector)
+	service.SetPrecision(2)
+
+	result, err := service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{Data: map[string]interface{}{"text": "Some ordinary text."}})
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if result.Score != models.Round(result.Score, 2) || result.Confidence != models.Round(result.Confidence, 2) {
+		t.Errorf("Expected the score and confidence rounded to 2 decimals, got %v and %v", result.Score, result.Confidence)
+	}
+	if score := result.Metadata.Analyzers["linguistic"]; score != 0.33 {
+		t.Errorf("Expected the analyzer score rounded to 0.33, got %v", score)
+	}
+	if stored := repo.anomalies[result.ID]; stored.Score != result.Score {
+		t.Errorf("Expected the stored score %v to match the returned %v", stored.Score, result.Score)
+	}
+
+	cfg := config.Defaults()
+	if cfg.Output.Precision != models.DefaultPrecision {
+		t.Errorf("Expected a default precision of %d, got %d", models.DefaultPrecision, cfg.Output.Precision)
+	}
+	for _, precision := range []int{-1, models.MaxPrecision + 1} {
+		cfg.Output.Precision = precision
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected precision %d to be rejected", precision)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/analyzers/catalog"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+func TestPresetResolution(t *testing.T) {
+	cfg := config.Defaults().Detector
+	cfg.Analyzers = map[string]config.AnalyzerConfig{
+		"cryptographic": {Disabled: true, Parameters: map[string]interface{}{"max_regex_matches": 50}},
+	}
+
+	resolved, err := cfg.WithPreset("leak-scan")
+	if err != nil {
+		t.Fatalf("Resolving a built-in preset failed: %v", err)
+	}
+	if !reflect.DeepEqual(resolved.EnabledAnalyzers, []string{"cryptographic", "entropy", "compression"}) {
+		t.Errorf("Expected the preset's analyzers enabled, got %v", resolved.EnabledAnalyzers)
+	}
+	crypto := resolved.Analyzers["cryptographic"]
+	if crypto.Disabled || crypto.Parameters["max_hashes"] != 5000 || crypto.Parameters["max_regex_matches"] != 50 {
+		t.Errorf("Expected the preset's parameters laid over the configured ones, got %+v", crypto)
+	}
+	if resolved.Thresholds.Prose != 0.6 || resolved.Thresholds.Log != 0.6 || resolved.ContentType != "data" || resolved.Weights["cryptographic"] != 2 {
+		t.Errorf("Expected the preset's threshold, content type and weights, got %+v, %q, %v", resolved.Thresholds, resolved.ContentType, resolved.Weights)
+	}
+	if !resolved.AnalyzerEnabled("entropy") || resolved.AnalyzerEnabled("linguistic") {
+		t.Error("Expected only the preset's analyzers to be enabled")
+	}
+	if !cfg.Analyzers["cryptographic"].Disabled || cfg.Analyzers["cryptographic"].Parameters["max_hashes"] != nil || cfg.ContentType != "auto" {
+		t.Error("Expected resolving a preset to leave the configuration unchanged")
+	}
+
+	cfg.Presets = map[string]config.PresetConfig{
+		"leak-scan": {Analyzers: []string{"entropy"}},
+		"tickets":   {ContentType: "prose", Threshold: 0.8},
+	}
+	if resolved, err := cfg.WithPreset("leak-scan"); err != nil || !reflect.DeepEqual(resolved.EnabledAnalyzers, []string{"entropy"}) {
+		t.Errorf("Expected a configured preset to replace the built-in one, got %v (%v)", resolved.EnabledAnalyzers, err)
+	}
+	names := cfg.PresetNames()
+	if !reflect.DeepEqual(names, []string{"academic-prose", "chat-logs", "leak-scan", "source-code", "tickets"}) {
+		t.Errorf("Expected built-in and configured presets listed, got %v", names)
+	}
+	if _, err := cfg.WithPreset("poetry"); err == nil {
+		t.Error("Expected an unknown preset to be rejected")
+	}
+}
+
+func TestPresetConfigValidation(t *testing.T) {
+	cfg := config.Defaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected the defaults to be valid, got %v", err)
+	}
+	for _, preset := range config.BuiltinPresets() {
+		cfg.Detector.Presets = map[string]config.PresetConfig{"copy": preset}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected built-in preset %q to be valid, got %v", preset.Description, err)
+		}
+	}
+
+	preset := func(name string, preset config.PresetConfig) func(*config.Config) {
+		return func(c *config.Config) { c.Detector.Presets = map[string]config.PresetConfig{name: preset} }
+	}
+	invalid := map[string]func(*config.Config){
+		"content type": func(c *config.Config) { c.Detector.ContentType = "poetry" },
+		"weight":       func(c *config.Config) { c.Detector.Weights = map[string]float64{"entropy": -1} },
+		"enabled":      func(c *config.Config) { c.Detector.EnabledAnalyzers = []string{"entropy", "entropy"} },
+		"preset name":  preset("Chat Logs", config.PresetConfig{}),
+		"preset type":  preset("chat", config.PresetConfig{ContentType: "poetry"}),
+		"threshold":    preset("chat", config.PresetConfig{Threshold: 1.5}),
+		"weights":      preset("chat", config.PresetConfig{Weights: map[string]float64{"entropy": -1}}),
+	}
+	for name, mutate := range invalid {
+		cfg := config.Defaults()
+		mutate(cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected an invalid %s to be rejected", name)
+		}
+	}
+}
+
+func TestPresetDetector(t *testing.T) {
+	cfg := config.Defaults().Detector
+	cfg.Presets = map[string]config.PresetConfig{
+		"structure": {ContentType: "code", Analyzers: []string{"entropy"}, Threshold: 0.4},
+	}
+	resolved, err := cfg.WithPreset("structure")
+	if err != nil {
+		t.Fatalf("Resolving the preset failed: %v", err)
+	}
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.5, confidence: 0.9})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.9, confidence: 0.9})
+	if err := detector.ApplyConfig(resolved); err != nil {
+		t.Fatalf("Applying the preset failed: %v", err)
+	}
+
+	result, err := detector.AnalyzeTextAs("The committee met on Tuesday.", core.ContentTypeAuto)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if result.Metadata["content_type"] != "code" || result.Metadata["content_type_detected"] != false {
+		t.Errorf("Expected the preset's content type used instead of classifying, got %v", result.Metadata)
+	}
+	if _, ran := result.Details["linguistic"]; ran || len(result.Details) != 1 || !result.IsAnomalous {
+		t.Errorf("Expected only entropy run and flagged against the preset's threshold, got %+v", result)
+	}
+	if result, _ := detector.AnalyzeTextAs("The committee met on Tuesday.", core.ContentTypeProse); result.Metadata["content_type"] != "prose" {
+		t.Errorf("Expected an explicit content type to win over the preset's, got %v", result.Metadata["content_type"])
+	}
+
+	if err := detector.ApplyConfig(cfg); err != nil {
+		t.Fatalf("Applying the configuration failed: %v", err)
+	}
+	if result, _ := detector.AnalyzeTextAs("The committee met on Tuesday.", core.ContentTypeAuto); len(result.Details) != 2 {
+		t.Errorf("Expected every analyzer to run without the preset, got %v", result.Details)
+	}
+}
+
+func TestPresetDetection(t *testing.T) {
+	cfg := config.Defaults().Detector
+	cfg.Presets = map[string]config.PresetConfig{"strict": {Threshold: 0.3}}
+	resolved, err := cfg.WithPreset("strict")
+	if err != nil {
+		t.Fatalf("Resolving the preset failed: %v", err)
+	}
+	strict := core.NewAnomalyDetector(zap.NewNop(), nil)
+	strict.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.4, confidence: 0.9})
+	if err := strict.ApplyConfig(resolved); err != nil {
+		t.Fatalf("Applying the preset failed: %v", err)
+	}
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.4, confidence: 0.9})
+
+	service := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	service.SetDetector(detector)
+	request := &models.DetectionRequest{Data: map[string]interface{}{"text": "Some ordinary text."}, Preset: "strict"}
+	if _, err := service.ProcessDetection(context.Background(), uuid.New(), "", request); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected a preset to be rejected without preset detectors, got %v", err)
+	}
+
+	service.SetPresets(map[string]*core.AnomalyDetector{"strict": strict})
+	result, err := service.ProcessDetection(context.Background(), uuid.New(), "", request)
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if result.Preset != "strict" || result.Threshold != 0.3 || !result.IsAnomaly {
+		t.Errorf("Expected the text decided against the preset's threshold, got %+v", result)
+	}
+	request.Threshold = 0.5
+	if result, err := service.ProcessDetection(context.Background(), uuid.New(), "", request); err != nil || result.Threshold != 0.5 || result.IsAnomaly {
+		t.Errorf("Expected the request's threshold to win over the preset's, got %+v (%v)", result, err)
+	}
+
+	request.Preset = "lenient"
+	if _, err := service.ProcessDetection(context.Background(), uuid.New(), "", request); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected an unknown preset to be rejected, got %v", err)
+	}
+	request = &models.DetectionRequest{Data: map[string]interface{}{"value": 3.0}, Preset: "strict"}
+	if _, err := service.ProcessDetection(context.Background(), uuid.New(), "", request); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected a preset without text to be rejected, got %v", err)
+	}
+}
+
+func TestPresetDetectorsFromConfiguration(t *testing.T) {
+	cfg := config.Defaults().Detector
+	cfg.Presets = map[string]config.PresetConfig{
+		"loops": {
+			Analyzers:  []string{"repetition"},
+			Parameters: map[string]map[string]interface{}{"repetition": {"phrase_coverage_threshold": 1.0}},
+		},
+	}
+	detector, err := catalog.NewDetector(cfg, zap.NewNop(), nil)
+	if err != nil {
+		t.Fatalf("Building the detector failed: %v", err)
+	}
+	presets, err := catalog.NewPresetDetectors(cfg, zap.NewNop(), nil)
+	if err != nil {
+		t.Fatalf("Building the preset detectors failed: %v", err)
+	}
+	if !reflect.DeepEqual(presets["leak-scan"].AnalyzerNames(), []string{"compression", "cryptographic", "entropy"}) {
+		t.Errorf("Expected the built-in preset's analyzers registered, got %v", presets["leak-scan"].AnalyzerNames())
+	}
+
+	service := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	service.SetDetector(detector)
+	service.SetPresets(presets)
+	text := "The report is ready for review today. The report is ready for review today. Please read it before the meeting."
+	request := &models.DetectionRequest{Data: map[string]interface{}{"text": text}, Preset: "loops"}
+	result, err := service.ProcessDetection(context.Background(), uuid.New(), "", request)
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if _, ok := result.Metadata.Analyzers["repetition"]; !ok || len(result.Metadata.Analyzers) != 1 {
+		t.Errorf("Expected only the preset's analyzer run, got %v", result.Metadata.Analyzers)
+	}
+
+	// The preset's parameters reach the analyzer: repeated phrases only
+	// score fully once they cover the whole text
+	analysis, err := presets["loops"].AnalyzeText(text)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	repetition := analysis.Details["repetition"].Metadata
+	if coverage := repetition["repeated_coverage"].(float64); coverage == 0 || repetition["phrase_score"] != coverage {
+		t.Errorf("Expected the preset's phrase coverage threshold applied, got %v", repetition)
+	}
+	analysis, err = detector.AnalyzeText(text)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if len(analysis.Details) < 2 || analysis.Details["repetition"].Metadata["phrase_score"] == repetition["phrase_score"] {
+		t.Errorf("Expected the default detector to run every analyzer with its own parameters, got %v", analysis.Details["repetition"].Metadata)
+	}
+
+	cfg.Presets["loops"].Parameters["repetition"]["min_phrase_length"] = 1
+	if _, err := catalog.NewPresetDetectors(cfg, zap.NewNop(), nil); err == nil || !strings.Contains(err.Error(), "loops") {
+		t.Errorf("Expected an invalid preset parameter to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/core"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/repository"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+// profileRepository is an in-memory stand-in for the profile, allowlist and
+// anomaly data parts of the repository; other methods panic if called
+type profileRepository struct {
+	repository.Repository
+	profiles  map[string]*models.DetectionProfile
+	keys      map[string]uuid.UUID
+	allowlist []*models.AllowlistEntry
+}
+
+func newProfileRepository() *profileRepository {
+	return &profileRepository{
+		profiles: make(map[string]*models.DetectionProfile),
+		keys:     make(map[string]uuid.UUID),
+	}
+}
+
+func (r *profileRepository) CreateAnomalyData(data *models.AnomalyData) error {
+	data.ID = uuid.New()
+	return nil
+}
+
+func (r *profileRepository) CreateDetectionProfile(profile *models.DetectionProfile) error {
+	profile.ID = uuid.New()
+	r.profiles[profile.Name] = profile
+	return nil
+}
+
+func (r *profileRepository) GetDetectionProfileByName(name string) (*models.DetectionProfile, error) {
+	if profile, ok := r.profiles[name]; ok {
+		return profile, nil
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (r *profileRepository) GetDetectionProfileByAPIKey(keyHash string) (*models.DetectionProfile, error) {
+	id, ok := r.keys[keyHash]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	for _, profile := range r.profiles {
+		if profile.ID == id {
+			return profile, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (r *profileRepository) AssignAPIKeyProfile(keyHash string, profileID uuid.UUID) error {
+	r.keys[keyHash] = profileID
+	return nil
+}
+
+func TestDetectionProfiles(t *testing.T) {
+	repo := newProfileRepository()
+	service := services.NewAnomalyService(repo, zap.NewNop())
+	data := map[string]interface{}{"latency": 30.0, "errors": 150.0}
+
+	// Without any stored profile the built-in default applies
+	result, err := service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{Data: data})
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if result.Profile != services.DefaultProfileName || result.Threshold != 0.5 {
+		t.Errorf("Expected built-in default profile, got %q with threshold %f", result.Profile, result.Threshold)
+	}
+	defaultScore := result.Score
+
+	_, err = service.CreateDetectionProfile(&models.DetectionProfileRequest{
+		Name:      "strict",
+		Threshold: 0.3,
+		Weights:   map[string]float64{"errors": 0},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+	if _, err := service.CreateDetectionProfile(&models.DetectionProfileRequest{Name: "strict", Threshold: 0.4}); !errors.Is(err, apperrors.ErrConflict) {
+		t.Errorf("Expected duplicate profile to conflict, got %v", err)
+	}
+
+	const apiKey = "tenant-a-0123456789abcdef"
+	if err := service.AssignDetectionProfile("strict", apiKey); err != nil {
+		t.Fatalf("Failed to assign profile: %v", err)
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	if _, ok := repo.keys[hex.EncodeToString(sum[:])]; !ok {
+		t.Error("Expected API key to be stored as a hash")
+	}
+
+	result, err = service.ProcessDetection(context.Background(), uuid.New(), apiKey, &models.DetectionRequest{Data: data})
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if result.Profile != "strict" || result.Threshold != 0.3 {
+		t.Errorf("Expected strict profile, got %q with threshold %f", result.Profile, result.Threshold)
+	}
+	if result.Score >= defaultScore {
+		t.Errorf("Expected zero-weighted feature to lower the score, got %f (default %f)", result.Score, defaultScore)
+	}
+
+	// An explicit request threshold still wins over the profile
+	result, _ = service.ProcessDetection(context.Background(), uuid.New(), apiKey, &models.DetectionRequest{Data: data, Threshold: 0.9})
+	if result.Threshold != 0.9 {
+		t.Errorf("Expected request threshold to override profile, got %f", result.Threshold)
+	}
+
+	if err := service.AssignDetectionProfile("missing", apiKey); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("Expected unknown profile to be not found, got %v", err)
+	}
+}
+
+func TestDetectionAnalyzerSelection(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.2, confidence: 0.9})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "entropy", score: 0.8, confidence: 0.9})
+
+	service := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	service.SetDetector(detector)
+	data := map[string]interface{}{"text": "The committee met on Tuesday to review the budget."}
+
+	tests := []struct {
+		name      string
+		analyzers []string
+		weights   map[string]float64
+		score     float64
+		ran       []string
+	}{
+		{"all analyzers with prose weights", nil, nil, (0.2*1.5 + 0.8) / 2.5, []string{"linguistic", "entropy"}},
+		{"selected analyzer only", []string{"entropy"}, nil, 0.8, []string{"entropy"}},
+		{"weight overrides profile", nil, map[string]float64{"entropy": 3}, (0.2*1.5 + 0.8*3) / 4.5, []string{"linguistic", "entropy"}},
+		{"zero weight skips analyzer", nil, map[string]float64{"linguistic": 0}, 0.8, []string{"entropy"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{
+				Data:      data,
+				Analyzers: tt.analyzers,
+				Weights:   tt.weights,
+			})
+			if err != nil {
+				t.Fatalf("Detection failed: %v", err)
+			}
+			if math.Abs(result.Score-tt.score) > 1e-9 {
+				t.Errorf("Expected score %f, got %f", tt.score, result.Score)
+			}
+			if len(result.Metadata.Analyzers) != len(tt.ran) {
+				t.Errorf("Expected analyzers %v to run, got %v", tt.ran, result.Metadata.Analyzers)
+			}
+			for _, name := range tt.ran {
+				if _, ok := result.Metadata.Analyzers[name]; !ok {
+					t.Errorf("Expected analyzer %s to run, got %v", name, result.Metadata.Analyzers)
+				}
+			}
+		})
+	}
+
+	invalid := []struct {
+		name    string
+		service *services.AnomalyService
+		request *models.DetectionRequest
+	}{
+		{"unknown analyzer", service, &models.DetectionRequest{Data: data, Analyzers: []string{"entropy", "astrology"}}},
+		{"weight for unknown analyzer", service, &models.DetectionRequest{Data: data, Weights: map[string]float64{"astrology": 1}}},
+		{"negative weight", service, &models.DetectionRequest{Data: data, Weights: map[string]float64{"entropy": -1}}},
+		{"selection without text", service, &models.DetectionRequest{Data: map[string]interface{}{"latency": 30.0}, Analyzers: []string{"entropy"}}},
+		{"selection without detector", services.NewAnomalyService(newProfileRepository(), zap.NewNop()), &models.DetectionRequest{Data: data, Analyzers: []string{"entropy"}}},
+	}
+	for _, tt := range invalid {
+		if _, err := tt.service.ProcessDetection(context.Background(), uuid.New(), "", tt.request); !errors.Is(err, apperrors.ErrInvalidInput) {
+			t.Errorf("%s: expected invalid input, got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/queue"
+	"github.com/ruvnet/alienator/internal/services"
+	"github.com/ruvnet/alienator/internal/sink"
+	"go.uber.org/zap"
+)
+
+// sourceRecordingRepository keeps the last detection stored
+type sourceRecordingRepository struct {
+	*profileRepository
+	stored *models.AnomalyData
+}
+
+func (r *sourceRecordingRepository) CreateAnomalyData(data *models.AnomalyData) error {
+	r.stored = data
+	return r.profileRepository.CreateAnomalyData(data)
+}
+
+func TestProvenanceResolve(t *testing.T) {
+	open := config.ProvenanceConfig{}
+	if source, err := open.Resolve("  forum-posts "); err != nil || source != "forum-posts" {
+		t.Errorf("Expected any source accepted without a list, got %q, %v", source, err)
+	}
+	if source, err := open.Resolve(""); err != nil || source != "" {
+		t.Errorf("Expected no source without a default, got %q, %v", source, err)
+	}
+	if _, err := open.Resolve(strings.Repeat("x", config.MaxSourceLength+1)); err == nil {
+		t.Error("Expected an overlong source to be rejected")
+	}
+
+	listed := config.ProvenanceConfig{Default: "api", Sources: []string{"api", "support-tickets"}}
+	if source, err := listed.Resolve(""); err != nil || source != "api" {
+		t.Errorf("Expected the default source, got %q, %v", source, err)
+	}
+	if source, err := listed.Resolve("support-tickets"); err != nil || source != "support-tickets" {
+		t.Errorf("Expected a listed source accepted, got %q, %v", source, err)
+	}
+	if _, err := listed.Resolve("forum-posts"); err == nil {
+		t.Error("Expected an unlisted source to be rejected")
+	}
+}
+
+func TestProvenanceValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		provenance config.ProvenanceConfig
+		reason     string
+	}{
+		{"empty source", config.ProvenanceConfig{Sources: []string{""}}, "provenance.sources"},
+		{"duplicate source", config.ProvenanceConfig{Sources: []string{"api", "api"}}, "provenance.sources"},
+		{"unlisted default", config.ProvenanceConfig{Default: "cli", Sources: []string{"api"}}, "provenance.default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Defaults()
+			cfg.Provenance = tt.provenance
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("Expected an error about %s, got %v", tt.reason, err)
+			}
+		})
+	}
+
+	cfg := config.Defaults()
+	cfg.Provenance = config.ProvenanceConfig{Default: "api", Sources: []string{"api", "forum-posts"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a listed default to be valid: %v", err)
+	}
+}
+
+func TestDetectionProvenance(t *testing.T) {
+	repo := &sourceRecordingRepository{profileRepository: newProfileRepository()}
+	service := services.NewAnomalyService(repo, zap.NewNop())
+	service.SetProvenance(config.ProvenanceConfig{Default: "api", Sources: []string{"api", "support-tickets"}})
+	userID := uuid.New()
+
+	result, err := service.ProcessDetection(context.Background(), userID, "", &models.DetectionRequest{
+		Data:   map[string]interface{}{"x": 0.2},
+		Source: "support-tickets",
+	})
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if result.Source != "support-tickets" || repo.stored.Source != "support-tickets" {
+		t.Errorf("Expected the source returned and stored, got %q and %q", result.Source, repo.stored.Source)
+	}
+
+	if result, err = service.ProcessDetection(context.Background(), userID, "", &models.DetectionRequest{Data: map[string]interface{}{"x": 0.2}}); err != nil || result.Source != "api" {
+		t.Errorf("Expected the default source for a request naming none, got %+v, %v", result, err)
+	}
+
+	_, err = service.ProcessDetection(context.Background(), userID, "", &models.DetectionRequest{
+		Data:   map[string]interface{}{"x": 0.2},
+		Source: "forum-posts",
+	})
+	if !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected an unlisted source to be rejected, got %v", err)
+	}
+}
+
+func TestBridgeConsumerProvenance(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+
+	transport := newMemoryBridgeTransport()
+	consumer := queue.NewBridgeConsumer(detector, transport, config.BridgeConfig{
+		Enabled:        true,
+		RequestChannel: "analyze",
+		ResultChannel:  "results",
+		Workers:        1,
+		MaxInFlight:    1,
+	}, nil, zap.NewNop())
+	var sunk bytes.Buffer
+	consumer.SetSink(sink.NewWriterSink("buffer", &sunk))
+	consumer.SetProvenance(config.ProvenanceConfig{Sources: []string{"forum-posts"}})
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start bridge: %v", err)
+	}
+	defer consumer.Stop()
+
+	transport.requests <- []byte(`{"id":"req-1","text":"The quick brown fox jumps over the lazy dog.","source":"forum-posts"}`)
+	transport.waitForResults(t, "results", 1)
+	transport.requests <- []byte(`{"id":"req-2","text":"The quick brown fox jumps over the lazy dog.","source":"email"}`)
+	responses := transport.waitForResults(t, "results", 2)
+
+	if responses[0].Source != "forum-posts" || responses[0].Result == nil {
+		t.Errorf("Expected the tagged result, got %+v", responses[0])
+	}
+	if responses[1].Result != nil || !strings.Contains(responses[1].Error, "unknown source") {
+		t.Errorf("Expected an unlisted source to be rejected, got %+v", responses[1])
+	}
+
+	var record sink.Record
+	if err := json.Unmarshal(sunk.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one record in the sink: %v", err)
+	}
+	if record.ID != "req-1" || record.Source != "forum-posts" {
+		t.Errorf("Expected the record tagged with its source, got %+v", record)
+	}
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+func newGroupedRateLimitRouter(limiter *middleware.RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(limiter.Handler())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/health", ok)
+	router.GET("/healthz", ok)
+	router.POST("/api/v1/anomalies/detect", ok)
+	router.POST("/api/v1/auth/login", ok)
+	router.GET("/api/v1/anomalies", ok)
+	return router
+}
+
+func TestRateLimiterGroups(t *testing.T) {
+	limiter := middleware.NewRateLimiter(config.RateLimitConfig{
+		RequestsPerMinute: 60,
+		Burst:             2,
+		Groups: []config.RateLimitGroup{
+			{Name: "health", Paths: []string{"/health"}, RequestsPerMinute: 6000, Burst: 100},
+			{Name: "analysis", Paths: []string{"/api/v1/anomalies/detect"}, RequestsPerMinute: 60, Burst: 1},
+			{Name: "auth", Paths: []string{"/api/v1/auth/"}, RequestsPerMinute: 60, Burst: 1},
+		},
+	})
+	router := newGroupedRateLimitRouter(limiter)
+	request := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	if w := request(http.MethodPost, "/api/v1/anomalies/detect"); w.Code != http.StatusOK {
+		t.Fatalf("Expected the first analysis request to pass, got %d", w.Code)
+	}
+	w := request(http.MethodPost, "/api/v1/anomalies/detect")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected the second analysis request to be limited, got %d", w.Code)
+	}
+	if limit := w.Header().Get("X-Rate-Limit-Limit"); limit != "60" {
+		t.Errorf("Expected the analysis limit 60 in the headers, got %q", limit)
+	}
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter != "1" {
+		t.Errorf("Expected to retry once a token is back in a second, got %q", retryAfter)
+	}
+	var response models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != "RATE_LIMIT_EXCEEDED" || response.Error.Rejection == nil ||
+		*response.Error.Rejection != (models.Rejection{RetryAfter: 1, Limit: 60, Current: 2}) {
+		t.Errorf("Expected the limit and requests this minute in the rejection, got %+v", response.Error)
+	}
+
+	// The other groups and the default keep budgets of their own
+	for i := 0; i < 50; i++ {
+		if w := request(http.MethodGet, "/health"); w.Code != http.StatusOK {
+			t.Fatalf("Expected health request %d to pass, got %d", i+1, w.Code)
+		}
+	}
+	if limit := request(http.MethodGet, "/health").Header().Get("X-Rate-Limit-Limit"); limit != "6000" {
+		t.Errorf("Expected the health limit 6000 in the headers, got %q", limit)
+	}
+	if w := request(http.MethodPost, "/api/v1/auth/login"); w.Code != http.StatusOK {
+		t.Errorf("Expected the first auth request to pass, got %d", w.Code)
+	}
+	for i := 0; i < 2; i++ {
+		if w := request(http.MethodGet, "/api/v1/anomalies"); w.Code != http.StatusOK {
+			t.Errorf("Expected default request %d to pass, got %d", i+1, w.Code)
+		}
+	}
+	if w := request(http.MethodGet, "/api/v1/anomalies"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the default budget to run out, got %d", w.Code)
+	}
+	// Groups match whole path segments, so /healthz is not in the health group
+	if w := request(http.MethodGet, "/healthz"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected /healthz to share the spent default budget, got %d", w.Code)
+	}
+}
+
+func TestRejectionsUseNegotiatedSchema(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.SchemaVersion(config.VersioningConfig{}))
+	router.Use(middleware.NewRateLimiter(config.RateLimitConfig{RequestsPerMinute: 60, Burst: 1}).Handler())
+	router.GET("/api/v1/anomalies", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/anomalies", nil))
+	for _, version := range []string{"v1", "v2"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/anomalies", nil)
+		req.Header.Set("Accept-Version", version)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("Expected the %s request to be limited, got %d", version, w.Code)
+		}
+
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		expected := `"` + version + `"`
+		if version == "v1" {
+			expected = "" // the v1 envelope carries no version
+		}
+		if got := string(envelope["version"]); got != expected {
+			t.Errorf("Expected the rejection in the %s envelope, got %s", version, w.Body.String())
+		}
+		if _, ok := envelope["error"]; !ok {
+			t.Errorf("Expected the rejection in the %s envelope's error, got %s", version, w.Body.String())
+		}
+	}
+}
+
+func TestRateLimiterUpdateGroups(t *testing.T) {
+	limiter := middleware.NewRateLimiter(config.RateLimitConfig{
+		RequestsPerMinute: 60,
+		Burst:             1,
+		Groups:            []config.RateLimitGroup{{Name: "analysis", Paths: []string{"/api/v1/anomalies/detect"}, RequestsPerMinute: 60, Burst: 1}},
+	})
+	router := newGroupedRateLimitRouter(limiter)
+	detect := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/anomalies/detect", nil))
+		return w
+	}
+
+	detect()
+	if w := detect(); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected the second request to be limited, got %d", w.Code)
+	}
+
+	// Removing the group moves its routes to the default budget
+	limiter.Update(config.RateLimitConfig{RequestsPerMinute: 6000, Burst: 10})
+	w := detect()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected the request to pass under the default limit, got %d", w.Code)
+	}
+	if limit := w.Header().Get("X-Rate-Limit-Limit"); limit != "6000" {
+		t.Errorf("Expected the default limit 6000 in the headers, got %q", limit)
+	}
+}
+
+func TestRateLimitGroupsValidation(t *testing.T) {
+	if err := config.Defaults().Validate(); err != nil {
+		t.Fatalf("Expected the default rate limit groups to be valid: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		group  config.RateLimitGroup
+		reason string
+	}{
+		{"no name", config.RateLimitGroup{Paths: []string{"/x"}, RequestsPerMinute: 1, Burst: 1}, "rate_limit.groups[3].name"},
+		{"duplicate name", config.RateLimitGroup{Name: "auth", Paths: []string{"/x"}, RequestsPerMinute: 1, Burst: 1}, "rate_limit.groups[3].name"},
+		{"no paths", config.RateLimitGroup{Name: "x", RequestsPerMinute: 1, Burst: 1}, "rate_limit.groups[3].paths"},
+		{"relative path", config.RateLimitGroup{Name: "x", Paths: []string{"x"}, RequestsPerMinute: 1, Burst: 1}, "rate_limit.groups[3].paths"},
+		{"no rate", config.RateLimitGroup{Name: "x", Paths: []string{"/x"}, Burst: 1}, "rate_limit.groups[3].requests_per_minute"},
+		{"no burst", config.RateLimitGroup{Name: "x", Paths: []string{"/x"}, RequestsPerMinute: 1}, "rate_limit.groups[3].burst"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Defaults()
+			cfg.RateLimit.Groups = append(cfg.RateLimit.Groups, tt.group)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("Expected an error about %s, got %v", tt.reason, err)
+			}
+		})
+	}
+}
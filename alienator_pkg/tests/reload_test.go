@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"go.uber.org/zap"
+)
+
+func newTestReloader(t *testing.T, content string) (*config.Reloader, string) {
+	t.Helper()
+	path := writeConfigFile(t, "alienator.yaml", content)
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	return config.NewReloader(path, cfg, zap.NewNop()), path
+}
+
+func TestReloaderAppliesReloadableSettings(t *testing.T) {
+	reloader, path := newTestReloader(t, "server:\n  port: 9090\n")
+
+	var applied *config.Config
+	reloader.OnReload(func(cfg *config.Config) error {
+		applied = cfg
+		return nil
+	})
+
+	if err := os.WriteFile(path, []byte(`
+server:
+  port: 9999
+logging:
+  level: debug
+rate_limit:
+  burst: 7
+detector:
+  confidence:
+    aggregation: min
+  tuning:
+    false_positive_cost: 3
+`), 0o600); err != nil {
+		t.Fatalf("Failed to rewrite config file: %v", err)
+	}
+
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if applied == nil {
+		t.Fatal("Expected the reloaded configuration to be applied")
+	}
+
+	current := reloader.Current()
+	if current.Logging.Level != "debug" || current.RateLimit.Burst != 7 || current.Detector.Confidence.Aggregation != "min" {
+		t.Errorf("Expected reloadable settings to change, got level %q, burst %d, aggregation %q",
+			current.Logging.Level, current.RateLimit.Burst, current.Detector.Confidence.Aggregation)
+	}
+	if current.Detector.Tuning.FalsePositiveCost != 3 {
+		t.Errorf("Expected the tuning costs to be reloaded, got %+v", current.Detector.Tuning)
+	}
+	if current.Server.Port != 9090 {
+		t.Errorf("Expected the listen port to need a restart and stay 9090, got %d", current.Server.Port)
+	}
+}
+
+func TestReloaderKeepsConfigOnInvalidFile(t *testing.T) {
+	reloader, path := newTestReloader(t, "logging:\n  level: warn\n")
+
+	calls := 0
+	reloader.OnReload(func(cfg *config.Config) error {
+		calls++
+		return nil
+	})
+
+	if err := os.WriteFile(path, []byte("logging:\n  level: loud\n"), 0o600); err != nil {
+		t.Fatalf("Failed to rewrite config file: %v", err)
+	}
+
+	var validationErr *config.ValidationError
+	if err := reloader.Reload(); !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a *config.ValidationError, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected nothing to be applied, got %d calls", calls)
+	}
+	if level := reloader.Current().Logging.Level; level != "warn" {
+		t.Errorf("Expected the previous log level warn, got %q", level)
+	}
+}
+
+func TestReloaderRevertsOnApplyFailure(t *testing.T) {
+	reloader, path := newTestReloader(t, "rate_limit:\n  burst: 3\n")
+
+	var bursts []int
+	reloader.OnReload(func(cfg *config.Config) error {
+		bursts = append(bursts, cfg.RateLimit.Burst)
+		return nil
+	})
+	reloader.OnReload(func(cfg *config.Config) error {
+		return errors.New("component rejected the configuration")
+	})
+
+	if err := os.WriteFile(path, []byte("rate_limit:\n  burst: 9\n"), 0o600); err != nil {
+		t.Fatalf("Failed to rewrite config file: %v", err)
+	}
+
+	if err := reloader.Reload(); err == nil {
+		t.Fatal("Expected the failing applier to fail the reload")
+	}
+	if len(bursts) != 2 || bursts[0] != 9 || bursts[1] != 3 {
+		t.Errorf("Expected burst 9 to be applied then reverted to 3, got %v", bursts)
+	}
+	if burst := reloader.Current().RateLimit.Burst; burst != 3 {
+		t.Errorf("Expected the previous burst 3, got %d", burst)
+	}
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := middleware.NewRateLimiter(config.RateLimitConfig{RequestsPerMinute: 60, Burst: 1})
+
+	router := gin.New()
+	router.Use(limiter.Handler())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w
+	}
+
+	get()
+	if w := get(); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected the second request to be limited, got %d", w.Code)
+	}
+
+	limiter.Update(config.RateLimitConfig{RequestsPerMinute: 6000, Burst: 10})
+	if limit := get().Header().Get("X-Rate-Limit-Limit"); limit != "6000" {
+		t.Errorf("Expected the updated limit 6000 in the headers, got %q", limit)
+	}
+}
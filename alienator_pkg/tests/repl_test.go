@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/repl"
+	"go.uber.org/zap"
+)
+
+func TestREPLSession(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "cryptographic", score: 0.9, confidence: 0.9})
+	detector.RegisterAnalyzer(&fixedAnalyzer{name: "linguistic", score: 0.5, confidence: 0.9})
+
+	var out bytes.Buffer
+	session := repl.NewSession(detector, &out)
+	session.SetScrollback(2)
+
+	input := strings.Join([]string{
+		"first text",
+		":disable crypto",
+		"second text",
+		":enable crypto",
+		":threshold 0.6",
+		":paste",
+		"third text,",
+		"pasted over two lines",
+		".",
+		":threshold 2",
+		":explain",
+		":explain 1",
+		":frobnicate",
+		":history",
+		":quit",
+		"never analyzed",
+	}, "\n")
+	if err := session.Run(strings.NewReader(input), false); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	output := out.String()
+
+	history := session.History()
+	if len(history) != 2 || history[0].Number != 2 || history[1].Number != 3 {
+		t.Fatalf("Expected the scrollback to keep results #2 and #3, got %+v", history)
+	}
+	if _, ok := history[0].Result.Details["cryptographic"]; ok || history[0].Result.Score != 0.5 {
+		t.Errorf("Expected the disabled analyzer to be skipped, got %+v", history[0].Result.Details)
+	}
+	if history[1].Text != "third text,\npasted over two lines" {
+		t.Errorf("Expected the pasted lines as one text, got %q", history[1].Text)
+	}
+	if !history[1].Result.IsAnomalous || history[1].Result.Metadata["threshold"] != 0.6 {
+		t.Errorf("Expected score %.2f to be flagged at threshold 0.6, got %v", history[1].Result.Score, history[1].Result.Metadata["threshold"])
+	}
+
+	for _, expected := range []string{
+		"#1 score 0.639",
+		"cryptographic  off",
+		"error: decision threshold",
+		"#3 The combined analyzer score indicates machine generation",
+		"error: result #1 is not in the scrollback",
+		"error: unknown command :frobnicate",
+		"  ! #3   0.639",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "#4") {
+		t.Error("Expected the session to end at :quit")
+	}
+}
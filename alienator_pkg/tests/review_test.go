@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+// reviewRepository keeps stored detections and the review queue in memory
+type reviewRepository struct {
+	*anomalyRepository
+	review []*models.ReviewItem
+}
+
+func newReviewRepository() *reviewRepository {
+	return &reviewRepository{anomalyRepository: &anomalyRepository{
+		profileRepository: newProfileRepository(),
+		anomalies:         make(map[uuid.UUID]*models.AnomalyData),
+	}}
+}
+
+func (r *reviewRepository) CreateReviewItem(item *models.ReviewItem) error {
+	item.ID = uuid.New()
+	item.Status = models.ReviewPending
+	item.CreatedAt = time.Now()
+	r.review = append(r.review, item)
+	return nil
+}
+
+func (r *reviewRepository) GetReviewItem(id uuid.UUID) (*models.ReviewItem, error) {
+	for _, item := range r.review {
+		if item.ID == id {
+			copied := *item
+			return &copied, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (r *reviewRepository) ListReviewItems(status string, page, limit int) ([]*models.ReviewItem, int, error) {
+	var items []*models.ReviewItem
+	for _, item := range r.review {
+		if status == "" || item.Status == status {
+			items = append(items, item)
+		}
+	}
+	return items, len(items), nil
+}
+
+func (r *reviewRepository) LabelReviewItem(item *models.ReviewItem) error {
+	for _, queued := range r.review {
+		if queued.ID == item.ID {
+			now := time.Now()
+			queued.Status = models.ReviewLabeled
+			queued.Label, queued.Note, queued.LabeledBy, queued.LabeledAt = item.Label, item.Note, item.LabeledBy, &now
+			*item = *queued
+			return nil
+		}
+	}
+	return apperrors.ErrNotFound
+}
+
+func TestReviewQueue(t *testing.T) {
+	borderline := &fixedAnalyzer{name: "linguistic", score: 0.53, confidence: 0.9}
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(borderline)
+	repo := newReviewRepository()
+	service := services.NewAnomalyService(repo, zap.NewNop())
+	service.SetDetector(detector)
+	request := &models.DetectionRequest{Data: map[string]interface{}{"text": "Some ordinary text."}}
+
+	result, err := service.ProcessDetection(context.Background(), uuid.New(), "", request)
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if result.Metadata.Review != nil || len(repo.review) != 0 {
+		t.Fatal("Expected no detection to be queued for review without a review band")
+	}
+
+	service.SetReviewBand(0.05)
+	result, err = service.ProcessDetection(context.Background(), uuid.New(), "", request)
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	review := result.Metadata.Review
+	if review == nil || review.AnomalyDataID != result.ID || review.Status != models.ReviewPending || !review.IsAnomaly {
+		t.Fatalf("Expected a detection 0.03 above the threshold to be queued, got %+v", review)
+	}
+
+	borderline.score = 0.9
+	if result, err := service.ProcessDetection(context.Background(), uuid.New(), "", request); err != nil || result.Metadata.Review != nil {
+		t.Errorf("Expected a clear detection not to be queued, got %+v (%v)", result, err)
+	}
+
+	items, meta, err := service.ListReviewQueue(models.ReviewPending, 1, 20)
+	if err != nil || len(items) != 1 || meta.Total != 1 || items[0].ID != review.ID {
+		t.Fatalf("Expected the queued detection listed as pending, got %v, %+v (%v)", items, meta, err)
+	}
+	if _, _, err := service.ListReviewQueue("done", 1, 20); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected an unknown status to be rejected, got %v", err)
+	}
+
+	reviewer := uuid.New()
+	labeled, err := service.LabelReviewItem(review.ID, reviewer, &models.ReviewLabelRequest{Label: models.LabelNormal, Note: "quoted boilerplate"})
+	if err != nil {
+		t.Fatalf("Labeling failed: %v", err)
+	}
+	if labeled.Status != models.ReviewLabeled || labeled.Label != models.LabelNormal || labeled.LabeledBy == nil ||
+		*labeled.LabeledBy != reviewer || labeled.LabeledAt == nil || labeled.Note != "quoted boilerplate" {
+		t.Errorf("Expected the label, note and reviewer recorded, got %+v", labeled)
+	}
+	if items, _, _ := service.ListReviewQueue(models.ReviewPending, 1, 20); len(items) != 0 {
+		t.Errorf("Expected no pending items once labeled, got %v", items)
+	}
+	if _, err := service.LabelReviewItem(uuid.New(), reviewer, &models.ReviewLabelRequest{Label: models.LabelAnomalous}); !errors.Is(err, services.ErrReviewItemNotFound) {
+		t.Errorf("Expected labeling an unknown item to fail with ErrReviewItemNotFound, got %v", err)
+	}
+}
+
+func TestReviewConfig(t *testing.T) {
+	cfg := config.Defaults()
+	if cfg.Review.Band <= 0 {
+		t.Errorf("Expected the review queue enabled by default, got band %v", cfg.Review.Band)
+	}
+	for _, band := range []float64{-0.1, 1} {
+		cfg.Review.Band = band
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected review band %v to be rejected", band)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestSampleText(t *testing.T) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 2000)
+	cfg := core.SamplingConfig{Fraction: 0.1, WindowSize: 1000, MinLength: 10000, Seed: 7}
+
+	sample, info := core.SampleText(text, cfg)
+	if info.Strategy != core.SamplingStrategyHeadMiddleTail {
+		t.Fatalf("Expected head+middle+tail sampling, got %s", info.Strategy)
+	}
+	if info.Windows != 9 {
+		t.Errorf("Expected 9 windows for a 10%% sample, got %d", info.Windows)
+	}
+	if info.Coverage <= 0.05 || info.Coverage > 0.1 {
+		t.Errorf("Expected coverage close to 10%%, got %f", info.Coverage)
+	}
+	if !strings.HasPrefix(sample, "The quick") {
+		t.Error("Expected the sample to start with the head of the text")
+	}
+
+	again, _ := core.SampleText(text, cfg)
+	if again != sample {
+		t.Error("Expected the same seed to select the same windows")
+	}
+
+	short := "A short text that is not worth sampling."
+	if sample, info := core.SampleText(short, cfg); sample != short || info.Coverage != 1 {
+		t.Errorf("Expected short text to be analyzed in full, got %+v", info)
+	}
+}
+
+func TestDetectorSampledAnalysis(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+	detector.RegisterAnalyzer(&warmingAnalyzer{})
+
+	text := strings.Repeat("Sampling keeps long inputs cheap to analyze. ", 1000)
+	cfg := core.DefaultSamplingConfig()
+	cfg.WindowSize = 500
+
+	full, err := detector.AnalyzeText(text)
+	if err != nil {
+		t.Fatalf("Full analysis failed: %v", err)
+	}
+	if _, ok := full.Metadata["sampling"]; ok {
+		t.Error("Full analysis must not record sampling metadata")
+	}
+
+	sampled, err := detector.AnalyzeTextSampled(text, core.ContentTypeAuto, cfg)
+	if err != nil {
+		t.Fatalf("Sampled analysis failed: %v", err)
+	}
+	info, ok := sampled.Metadata["sampling"].(core.SampleInfo)
+	if !ok || info.Strategy != core.SamplingStrategyHeadMiddleTail {
+		t.Fatalf("Expected sampling metadata, got %v", sampled.Metadata["sampling"])
+	}
+	if sampled.Confidence >= full.Confidence {
+		t.Errorf("Expected sampled confidence %f below full confidence %f", sampled.Confidence, full.Confidence)
+	}
+
+	cfg.Fraction = 0
+	if _, err := detector.AnalyzeTextSampled(text, core.ContentTypeAuto, cfg); err == nil {
+		t.Error("Expected a zero sampling fraction to be rejected")
+	}
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/repository"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+// searchRepository records the arguments of detection searches
+type searchRepository struct {
+	repository.Repository
+	search      models.AnomalySearch
+	page, limit int
+	calls       int
+}
+
+func (r *searchRepository) SearchAnomalyData(search models.AnomalySearch, page, limit int) ([]*models.AnomalySearchResult, int, error) {
+	r.search, r.page, r.limit = search, page, limit
+	r.calls++
+	return []*models.AnomalySearchResult{{AnomalyData: models.AnomalyData{Score: 0.9}, Rank: 0.4}}, 41, nil
+}
+
+func TestSearchAnomalyData(t *testing.T) {
+	repo := &searchRepository{}
+	service := services.NewAnomalyService(repo, zap.NewNop())
+
+	userID := uuid.New()
+	minScore := 0.5
+	search := models.AnomalySearch{Query: "  \"wire transfer\" -invoice ", UserID: &userID, MinScore: &minScore}
+	results, meta, err := service.SearchAnomalyData(search, 0, 500)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if repo.search.Query != "\"wire transfer\" -invoice" || repo.search.UserID != &userID || *repo.search.MinScore != 0.5 {
+		t.Errorf("Expected the trimmed query and filters to reach the repository, got %+v", repo.search)
+	}
+	if repo.page != 1 || repo.limit != 20 {
+		t.Errorf("Expected out of range paging to fall back to the defaults, got page %d limit %d", repo.page, repo.limit)
+	}
+	if len(results) != 1 || results[0].Rank != 0.4 || meta.Total != 41 || meta.TotalPages != 3 {
+		t.Errorf("Unexpected results %+v and meta %+v", results, meta)
+	}
+
+	maxScore := 0.2
+	now := time.Now()
+	invalid := []models.AnomalySearch{
+		{Query: "   "},
+		{Query: string(make([]byte, 300))},
+		{Query: "refund", MinScore: &minScore, MaxScore: &maxScore},
+		{Query: "refund", From: now, To: now.Add(-time.Hour)},
+	}
+	for _, search := range invalid {
+		repo.calls = 0
+		if _, _, err := service.SearchAnomalyData(search, 1, 20); !errors.Is(err, apperrors.ErrInvalidInput) || repo.calls != 0 {
+			t.Errorf("Expected %+v to be rejected before querying, got %v", search, err)
+		}
+	}
+}
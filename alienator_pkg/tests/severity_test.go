@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestSeverityClassification(t *testing.T) {
+	bands := core.DefaultSeverityBands()
+
+	cases := []struct {
+		score      float64
+		confidence float64
+		expected   models.Severity
+	}{
+		{0.1, 0.9, models.SeverityNone},
+		{0.6, 0.9, models.SeverityLow},
+		{0.7, 0.9, models.SeverityLow},
+		{0.75, 0.9, models.SeverityMedium},
+		{0.9, 0.9, models.SeverityHigh},
+		{0.99, 0.9, models.SeverityCritical},
+		{0.99, 0.1, models.SeverityHigh},
+		{0.6, 0.1, models.SeverityNone},
+	}
+
+	for _, tc := range cases {
+		if got := bands.Classify(tc.score, tc.confidence); got != tc.expected {
+			t.Errorf("Classify(%.2f, %.2f) = %s, expected %s", tc.score, tc.confidence, got, tc.expected)
+		}
+	}
+}
+
+func TestSeverityBandsValidation(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+
+	invalid := core.SeverityBands{Low: 0.5, Medium: 0.4, High: 0.8, Critical: 0.9}
+	if err := detector.SetSeverityBands(invalid); err == nil {
+		t.Error("Expected descending bands to be rejected")
+	}
+
+	custom := core.SeverityBands{Low: 0.05, Medium: 0.2, High: 0.3, Critical: 0.4}
+	if err := detector.SetSeverityBands(custom); err != nil {
+		t.Fatalf("Expected ascending bands to be accepted: %v", err)
+	}
+	detector.RegisterAnalyzer(&warmingAnalyzer{})
+
+	result, err := detector.AnalyzeText("some text")
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if result.Severity != models.SeverityLow {
+		t.Errorf("Expected custom bands to classify score %.2f as low, got %s", result.Score, result.Severity)
+	}
+}
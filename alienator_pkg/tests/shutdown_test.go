@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/server"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// startShutdownServer serves a route that blocks until release is closed
+func startShutdownServer(t *testing.T, limiter *middleware.ConcurrencyLimiter, entered, release chan struct{}) (*http.Server, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(limiter.Handler())
+	router.GET("/slow", func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	srv := &http.Server{Handler: router}
+	go srv.Serve(listener)
+	return srv, "http://" + listener.Addr().String()
+}
+
+func TestDrainerWaitsForInFlightRequests(t *testing.T) {
+	limiter := middleware.NewConcurrencyLimiter("all", 10, time.Second, nil)
+	entered, release := make(chan struct{}), make(chan struct{})
+	srv, url := startShutdownServer(t, limiter, entered, release)
+
+	done := make(chan int)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			done <- 0
+			return
+		}
+		resp.Body.Close()
+		done <- resp.StatusCode
+	}()
+	<-entered
+
+	drainer := server.NewDrainer(zap.NewNop(), limiter)
+	if drainer.Draining() {
+		t.Fatal("Expected the drainer not to be draining before shutdown")
+	}
+
+	shutdown := make(chan error)
+	go func() { shutdown <- drainer.Shutdown(10*time.Millisecond, 5*time.Second, srv) }()
+
+	time.Sleep(50 * time.Millisecond)
+	if !drainer.Draining() {
+		t.Error("Expected readiness to fail as soon as shutdown begins")
+	}
+	close(release)
+
+	if err := <-shutdown; err != nil {
+		t.Errorf("Expected a clean shutdown once the request finished, got %v", err)
+	}
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("Expected the in-flight request to complete, got %d", code)
+	}
+}
+
+func TestDrainerForcesCloseAtTimeout(t *testing.T) {
+	limiter := middleware.NewConcurrencyLimiter("analysis", 10, time.Second, nil)
+	entered, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	srv, url := startShutdownServer(t, limiter, entered, release)
+
+	go func() {
+		if resp, err := http.Get(url + "/slow"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-entered
+
+	core, logs := observer.New(zap.WarnLevel)
+	drainer := server.NewDrainer(zap.New(core), limiter)
+	start := time.Now()
+	if err := drainer.Shutdown(0, 100*time.Millisecond, srv); err == nil {
+		t.Error("Expected shutdown to report the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected shutdown to give up after its timeout, took %s", elapsed)
+	}
+
+	entries := logs.FilterMessageSnippet("Shutdown timed out").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected one timeout warning, got %d", len(entries))
+	}
+	if inFlight := entries[0].ContextMap()["analysis_in_flight"]; inFlight != int64(1) {
+		t.Errorf("Expected the in-flight request to be logged, got %v", entries[0].ContextMap())
+	}
+}
+
+func TestShutdownConfigValidation(t *testing.T) {
+	cfg := config.Defaults()
+	if cfg.Server.ShutdownTimeout != 30*time.Second {
+		t.Errorf("Expected a 30s default shutdown timeout, got %s", cfg.Server.ShutdownTimeout)
+	}
+
+	cfg.Server.ShutdownTimeout = 0
+	cfg.Server.DrainDelay = -time.Second
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected invalid shutdown settings to be rejected")
+	}
+	for _, want := range []string{"server.shutdown_timeout", "server.drain_delay"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected a problem for %s, got %v", want, err)
+		}
+	}
+}
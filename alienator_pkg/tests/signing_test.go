@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ruvnet/alienator/internal/config"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/services"
+	"github.com/ruvnet/alienator/internal/signing"
+	"go.uber.org/zap"
+)
+
+const testSigningSecret = "0123456789abcdef0123456789abcdef"
+
+func TestSignerDetectsTampering(t *testing.T) {
+	signer := signing.NewHMACSigner("k1", []byte(testSigningSecret))
+	verdict := signing.Verdict{Score: 0.734512345678, IsAnomaly: true, Threshold: 0.5, Algorithm: "isolation_forest"}
+	data := map[string]interface{}{"latency": 120.0, "errors": 3.0}
+
+	signature, err := signer.Sign(verdict, data, time.Now())
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if signature.Algorithm != signing.AlgorithmHMACSHA256 || signature.KeyID != "k1" || signature.Value == "" {
+		t.Fatalf("Unexpected signature: %+v", signature)
+	}
+	if err := signer.Verify(verdict, signature); err != nil {
+		t.Fatalf("Expected intact verdict to verify, got %v", err)
+	}
+
+	// Verdicts read back at the stored precision still verify
+	stored := verdict
+	stored.Score = 0.73451235
+	if err := signer.Verify(stored, signature); err != nil {
+		t.Errorf("Expected verdict rounded to 8 decimals to verify, got %v", err)
+	}
+
+	flipped := verdict
+	flipped.IsAnomaly = false
+	if err := signer.Verify(flipped, signature); !errors.Is(err, signing.ErrInvalidSignature) {
+		t.Errorf("Expected altered verdict to fail verification, got %v", err)
+	}
+
+	backdated := *signature
+	backdated.SignedAt = signature.SignedAt.Add(-time.Hour)
+	if err := signer.Verify(verdict, &backdated); !errors.Is(err, signing.ErrInvalidSignature) {
+		t.Errorf("Expected altered signing time to fail verification, got %v", err)
+	}
+
+	other := signing.NewHMACSigner("k1", []byte(strings.Repeat("x", 32)))
+	if err := other.Verify(verdict, signature); !errors.Is(err, signing.ErrInvalidSignature) {
+		t.Errorf("Expected a different key to fail verification, got %v", err)
+	}
+
+	if err := signing.VerifyInput(data, signature); err != nil {
+		t.Errorf("Expected original data to match the input hash, got %v", err)
+	}
+	if err := signing.VerifyInput(map[string]interface{}{"latency": 12.0, "errors": 3.0}, signature); err == nil {
+		t.Error("Expected altered data to fail the input hash check")
+	}
+}
+
+func TestEd25519SignerFromConfig(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "signing.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := signing.NewSigner(config.SigningConfig{Algorithm: signing.AlgorithmEd25519, KeyID: "2026-10", PrivateKeyFile: path})
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	if !signer.PublicKey().Equal(key.Public()) {
+		t.Error("Expected the public key of the configured private key")
+	}
+
+	verdict := signing.Verdict{Score: 0.2, Threshold: 0.5, Algorithm: "isolation_forest"}
+	signature, err := signer.Sign(verdict, map[string]interface{}{"latency": 30.0}, time.Now())
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := signer.Verify(verdict, signature); err != nil {
+		t.Errorf("Expected intact verdict to verify, got %v", err)
+	}
+	verdict.Score = 0.9
+	if err := signer.Verify(verdict, signature); !errors.Is(err, signing.ErrInvalidSignature) {
+		t.Errorf("Expected altered verdict to fail verification, got %v", err)
+	}
+}
+
+func TestDetectionResultsAreSigned(t *testing.T) {
+	service := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	data := map[string]interface{}{"latency": 30.0, "errors": 150.0}
+
+	if _, err := service.VerifyDetection(&models.DetectionResult{}, nil); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected verification without signing to be invalid input, got %v", err)
+	}
+
+	service.SetSigner(signing.NewHMACSigner("k1", []byte(testSigningSecret)))
+	result, err := service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{Data: data})
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+	if result.Signature == nil {
+		t.Fatal("Expected the detection result to be signed")
+	}
+
+	verification, err := service.VerifyDetection(result, data)
+	if err != nil {
+		t.Fatalf("Verification failed: %v", err)
+	}
+	if !verification.Valid || verification.KeyID != "k1" {
+		t.Errorf("Expected a valid verification with key k1, got %+v", verification)
+	}
+
+	tampered := *result
+	tampered.IsAnomaly = !result.IsAnomaly
+	if verification, _ := service.VerifyDetection(&tampered, nil); verification.Valid {
+		t.Error("Expected an altered verdict to fail verification")
+	}
+	if verification, _ := service.VerifyDetection(result, map[string]interface{}{"latency": 30.0}); verification.Valid {
+		t.Error("Expected different data to fail verification")
+	}
+
+	unsigned := *result
+	unsigned.Signature = nil
+	if verification, _ := service.VerifyDetection(&unsigned, nil); verification.Valid || verification.Reason == "" {
+		t.Errorf("Expected an unsigned result to be reported as such, got %+v", verification)
+	}
+}
+
+func TestSigningConfigValidation(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Signing.Enabled = true
+	cfg.Signing.Secret = "short"
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "signing.secret") {
+		t.Errorf("Expected a short signing secret to be rejected, got %v", err)
+	}
+
+	cfg.Signing.Algorithm = "ed25519"
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "signing.private_key_file") {
+		t.Errorf("Expected ed25519 without a key file to be rejected, got %v", err)
+	}
+}
+
+func TestSignatureCoversTheVerdictOnly(t *testing.T) {
+	service := services.NewAnomalyService(newProfileRepository(), zap.NewNop())
+	service.SetSigner(signing.NewHMACSigner("k1", []byte(testSigningSecret)))
+	data := map[string]interface{}{"latency": 30.0, "errors": 150.0}
+	result, err := service.ProcessDetection(context.Background(), uuid.New(), "", &models.DetectionRequest{Data: data})
+	if err != nil {
+		t.Fatalf("Detection failed: %v", err)
+	}
+
+	covered := map[string]func(*models.DetectionResult){
+		"score":      func(r *models.DetectionResult) { r.Score += 0.1 },
+		"is_anomaly": func(r *models.DetectionResult) { r.IsAnomaly = !r.IsAnomaly },
+		"threshold":  func(r *models.DetectionResult) { r.Threshold += 0.1 },
+		"algorithm":  func(r *models.DetectionResult) { r.Algorithm = "other" },
+		"signed_at":  func(r *models.DetectionResult) { r.Signature.SignedAt = r.Signature.SignedAt.Add(time.Second) },
+		"input_hash": func(r *models.DetectionResult) { r.Signature.InputHash = strings.Repeat("0", 64) },
+	}
+	for field, alter := range covered {
+		tampered := *result
+		signature := *result.Signature
+		tampered.Signature = &signature
+		alter(&tampered)
+		if verification, _ := service.VerifyDetection(&tampered, nil); verification.Valid {
+			t.Errorf("Expected a changed %s to fail verification", field)
+		}
+	}
+
+	uncovered := map[string]func(*models.DetectionResult){
+		"id":         func(r *models.DetectionResult) { r.ID = uuid.New() },
+		"confidence": func(r *models.DetectionResult) { r.Confidence = 1 - r.Confidence },
+		"profile":    func(r *models.DetectionResult) { r.Profile = "other" },
+		"preset":     func(r *models.DetectionResult) { r.Preset = "other" },
+		"source":     func(r *models.DetectionResult) { r.Source = "other" },
+		"language":   func(r *models.DetectionResult) { r.Language = "xx" },
+		"metadata":   func(r *models.DetectionResult) { r.Metadata = models.Metadata{Explanations: []string{"other"}} },
+	}
+	for field, alter := range uncovered {
+		changed := *result
+		alter(&changed)
+		if verification, _ := service.VerifyDetection(&changed, data); !verification.Valid {
+			t.Errorf("Expected %s to be outside the signature, got %s", field, verification.Reason)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/sink"
+)
+
+func sinkRecord(id string) *sink.Record {
+	return sink.NewRecord(id, "test", &models.AnomalyResult{Score: 0.8, Confidence: 0.9, IsAnomalous: true})
+}
+
+func TestWriterSinkWritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	s := sink.NewWriterSink("buffer", &buf)
+
+	for _, id := range []string{"a", "b"} {
+		if err := s.Write(context.Background(), sinkRecord(id)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	var record sink.Record
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", lines[1], err)
+	}
+	if record.ID != "b" || record.Source != "test" || record.Result.Score != 0.8 {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+}
+
+func TestFileSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	s, err := sink.NewFileSink(config.FileSinkConfig{Path: path, MaxSizeMB: 1, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	defer s.Close()
+
+	// Each record carries about 100KB of metadata, so a 1MB file holds nine
+	record := sinkRecord("big")
+	record.Result.Metadata = map[string]interface{}{"padding": strings.Repeat("x", 100*1024)}
+	for i := 0; i < 40; i++ {
+		if err := s.Write(context.Background(), record); err != nil {
+			t.Fatalf("Write %d failed: %v", i, err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("Expected %s to exist: %v", name, err)
+		}
+		if info.Size() > 1024*1024 {
+			t.Errorf("Expected %s to stay within 1MB, got %d bytes", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 backups to be kept, got %v", err)
+	}
+
+	file, _ := os.Open(path + ".1")
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 256*1024), 256*1024)
+	for scanner.Scan() {
+		var decoded sink.Record
+		if err := json.Unmarshal(scanner.Bytes(), &decoded); err != nil {
+			t.Fatalf("Expected whole JSON lines in the backup: %v", err)
+		}
+	}
+}
+
+func TestWebhookSinkSignsBody(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	s := sink.NewWebhookSink(config.WebhookSinkConfig{URL: server.URL, Secret: "shh", Timeout: time.Second})
+	if err := s.Write(context.Background(), sinkRecord("hook")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	req, body := <-received, <-bodies
+	if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON POST, got %s %s", req.Method, req.Header.Get("Content-Type"))
+	}
+	mac := hmac.New(sha256.New, []byte("shh"))
+	mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); req.Header.Get(sink.SignatureHeader) != want {
+		t.Errorf("Expected signature %s, got %s", want, req.Header.Get(sink.SignatureHeader))
+	}
+}
+
+func TestWebhookSinkReportsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	s := sink.NewWebhookSink(config.WebhookSinkConfig{URL: server.URL, Timeout: time.Second})
+	err := s.Write(context.Background(), sinkRecord("hook"))
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Expected a 502 error, got %v", err)
+	}
+}
+
+func TestKafkaSinkProducesThroughRESTProxy(t *testing.T) {
+	var path, contentType string
+	var produced struct {
+		Records []struct {
+			Key   string      `json:"key"`
+			Value sink.Record `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&produced)
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":1}]}`))
+	}))
+	defer server.Close()
+
+	s := sink.NewKafkaSink(config.KafkaSinkConfig{RESTURL: server.URL + "/", Topic: "results", Timeout: time.Second})
+	if err := s.Write(context.Background(), sinkRecord("k-1")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if path != "/topics/results" || contentType != "application/vnd.kafka.json.v2+json" {
+		t.Errorf("Unexpected produce request: %s (%s)", path, contentType)
+	}
+	if len(produced.Records) != 1 || produced.Records[0].Key != "k-1" || produced.Records[0].Value.ID != "k-1" {
+		t.Errorf("Unexpected records: %+v", produced.Records)
+	}
+}
+
+func TestSinksFromConfig(t *testing.T) {
+	cfg := config.Defaults()
+	multi, err := sink.New(cfg.Sinks)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if multi.Len() != 0 {
+		t.Errorf("Expected no sinks by default, got %d", multi.Len())
+	}
+	if err := multi.Write(context.Background(), sinkRecord("none")); err != nil {
+		t.Errorf("Expected writing to no sinks to succeed, got %v", err)
+	}
+
+	cfg.Sinks.File.Enabled = true
+	cfg.Sinks.File.Path = filepath.Join(t.TempDir(), "results.jsonl")
+	cfg.Sinks.Webhook.Enabled = true
+	cfg.Sinks.Webhook.URL = "http://127.0.0.1:1/unreachable"
+	multi, err = sink.New(cfg.Sinks)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer multi.Close()
+	if multi.Len() != 2 {
+		t.Fatalf("Expected 2 sinks, got %d", multi.Len())
+	}
+
+	// A failing sink does not keep the others from receiving the record
+	err = multi.Write(context.Background(), sinkRecord("both"))
+	if err == nil || !strings.Contains(err.Error(), "webhook sink") {
+		t.Errorf("Expected the webhook failure to be reported, got %v", err)
+	}
+	if content, _ := os.ReadFile(cfg.Sinks.File.Path); !strings.Contains(string(content), `"id":"both"`) {
+		t.Errorf("Expected the file sink to receive the record, got %q", content)
+	}
+}
+
+func TestSinkConfigValidation(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Sinks.Webhook.Enabled = true
+	cfg.Sinks.Webhook.URL = "not a url"
+	cfg.Sinks.Kafka.Enabled = true
+	cfg.Sinks.Kafka.Topic = ""
+	cfg.Sinks.Kafka.RESTURL = "http://kafka-rest:8082"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected invalid sink settings to be rejected")
+	}
+	for _, want := range []string{"sinks.webhook.url", "sinks.kafka.topic"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected a problem for %s, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	apperrors "github.com/ruvnet/alienator/internal/errors"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/repository"
+	"github.com/ruvnet/alienator/internal/services"
+	"go.uber.org/zap"
+)
+
+// statsRepository records the time-series queries
+type statsRepository struct {
+	repository.Repository
+	query models.StatsQuery
+}
+
+func (r *statsRepository) GetAnomalyStatsTimeSeries(query models.StatsQuery) ([]*models.StatsBucket, error) {
+	r.query = query
+	return []*models.StatsBucket{{Start: query.From, Total: 3, AnomalyCount: 1, MeanScore: 0.4}}, nil
+}
+
+func TestAnomalyStatsTimeSeries(t *testing.T) {
+	repo := &statsRepository{}
+	service := services.NewAnomalyService(repo, zap.NewNop())
+
+	userID := uuid.New()
+	series, err := service.GetAnomalyStatsTimeSeries(&userID, "", "", "", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Time series failed: %v", err)
+	}
+	if repo.query.Unit != "hour" || series.Interval != "1h" {
+		t.Errorf("Expected hourly buckets by default, got unit %q interval %q", repo.query.Unit, series.Interval)
+	}
+	if repo.query.UserID == nil || *repo.query.UserID != userID {
+		t.Error("Expected query to be scoped to the user")
+	}
+	if repo.query.Source != "" || repo.query.BySource {
+		t.Errorf("Expected every source aggregated by default, got %+v", repo.query)
+	}
+	if got := repo.query.To.Sub(repo.query.From); got != 24*time.Hour {
+		t.Errorf("Expected a 24 hour default range, got %s", got)
+	}
+	if len(series.Buckets) != 1 {
+		t.Errorf("Expected repository buckets to be returned, got %d", len(series.Buckets))
+	}
+
+	to := time.Now()
+	if _, err := service.GetAnomalyStatsTimeSeries(nil, "", "1d", "", to.Add(-7*24*time.Hour), to); err != nil || repo.query.Unit != "day" || repo.query.UserID != nil {
+		t.Errorf("Expected unscoped daily query, got unit %q, err %v", repo.query.Unit, err)
+	}
+
+	// Sources can be compared, or looked at one at a time
+	series, err = service.GetAnomalyStatsTimeSeries(nil, "forum-posts", "1h", "source", time.Time{}, time.Time{})
+	if err != nil || !repo.query.BySource || repo.query.Source != "forum-posts" {
+		t.Errorf("Expected a query for one source grouped by source, got %+v, err %v", repo.query, err)
+	}
+	if series.GroupBy != "source" || series.Source != "forum-posts" {
+		t.Errorf("Expected the grouping and source reported, got %+v", series)
+	}
+	if _, err := service.GetAnomalyStatsTimeSeries(nil, "", "1h", "user", time.Time{}, time.Time{}); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected an unknown group_by to be rejected, got %v", err)
+	}
+
+	invalid := []struct {
+		interval string
+		from, to time.Time
+	}{
+		{"5s", time.Time{}, time.Time{}},
+		{"1h", to, to.Add(-time.Hour)},
+		{"1m", to.Add(-30 * 24 * time.Hour), to},
+	}
+	for _, tc := range invalid {
+		if _, err := service.GetAnomalyStatsTimeSeries(nil, "", tc.interval, "", tc.from, tc.to); !errors.Is(err, apperrors.ErrInvalidInput) {
+			t.Errorf("Expected interval %q from %s to %s to be rejected, got %v", tc.interval, tc.from, tc.to, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/analyzers/template"
+	"github.com/ruvnet/alienator/internal/batch"
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+var templatedItems = []string{
+	"Dear Alice, your order 1042 has shipped and will arrive on Monday. Thank you for shopping with us.",
+	"Dear Bob, your order 77 has shipped and will arrive on Friday. Thank you for shopping with us.",
+	"Dear Carol, your order 5120 has shipped and will arrive tomorrow. Thank you for shopping with us.",
+	"The river flooded the lower fields again after three days of heavy rain.",
+}
+
+func TestDetectTemplates(t *testing.T) {
+	scores := template.DetectTemplates(templatedItems)
+	if len(scores) != len(templatedItems) {
+		t.Fatalf("Expected a score per item, got %d", len(scores))
+	}
+
+	first := scores[0]
+	if first.Index != 0 || !first.Templated || first.Score != 1 || first.Slots != 2 {
+		t.Errorf("Expected the first item templated with two slots, got %+v", first)
+	}
+	if expected := "{} has shipped and will arrive on {} Thank you for shopping with us."; first.Template != expected {
+		t.Errorf("Expected template %q, got %q", expected, first.Template)
+	}
+	if len(first.Spans) != 2 || first.Spans[1] != "Thank you for shopping with us." || first.Coverage != 12.0/18.0 {
+		t.Errorf("Expected the shared spans covering 12 of 18 words, got %+v", first)
+	}
+	for _, score := range scores[1:3] {
+		if !score.Templated || score.Coverage < 0.6 {
+			t.Errorf("Expected item %d templated, got %+v", score.Index, score)
+		}
+	}
+	if last := scores[3]; last.Score != 0 || last.Templated || last.Template != "" || last.Slots != 0 {
+		t.Errorf("Expected the unrelated item not templated, got %+v", last)
+	}
+
+	for _, score := range template.DetectTemplates(templatedItems[:2]) {
+		if score.Score != 0 {
+			t.Errorf("Expected no template looked for in fewer than 3 items, got %+v", score)
+		}
+	}
+
+	analyzer := template.NewTemplateAnalyzer()
+	if err := analyzer.Configure(map[string]interface{}{"min_items": 2.0, "coverage_threshold": 0.9}); err != nil {
+		t.Fatalf("Configuration failed: %v", err)
+	}
+	if scores := analyzer.DetectTemplates(templatedItems[:2]); scores[0].Coverage == 0 || scores[0].Templated {
+		t.Errorf("Expected two items scored, below the raised coverage threshold, got %+v", scores[0])
+	}
+	invalid := []map[string]interface{}{
+		{"span_length": 1},
+		{"min_support": 1.5},
+		{"min_items": 1},
+		{"coverage_threshold": 0.0},
+	}
+	for _, config := range invalid {
+		if err := analyzer.Configure(config); err == nil {
+			t.Errorf("Expected %v to be rejected", config)
+		}
+	}
+}
+
+func TestBatchRunTemplates(t *testing.T) {
+	dir := t.TempDir()
+	contents := make(map[string]string, len(templatedItems))
+	for i, item := range templatedItems {
+		contents[fmt.Sprintf("%d.txt", i)] = item
+	}
+	writeFiles(t, dir, contents)
+	files, err := batch.Collect([]string{dir})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "As an AI"})
+	opts := batch.Options{Concurrency: 2, ContentType: core.ContentTypeProse, Templates: template.NewTemplateAnalyzer()}
+	summary := batch.Run(context.Background(), detector, files, opts, func(outcome batch.Outcome) {
+		if outcome.Err != nil || outcome.Template == nil {
+			t.Fatalf("Expected %s analyzed and scored for templates, got %v", outcome.Path, outcome.Err)
+		}
+		if templated := filepath.Base(outcome.Path) != "3.txt"; outcome.Template.Templated != templated {
+			t.Errorf("Unexpected template score for %s: %+v", outcome.Path, outcome.Template)
+		}
+	})
+	if summary.Analyzed != 4 || summary.Templated != 3 {
+		t.Errorf("Expected 3 of 4 files templated, got %+v", summary)
+	}
+}
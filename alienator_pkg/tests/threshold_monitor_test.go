@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/analyzers"
+	"github.com/ruvnet/alienator/internal/analyzers/threshold"
+)
+
+// breachSeries returns n points a second apart, all 0 but for the run from
+// breachStart to breachEnd, which lies above the default threshold of 2
+func breachSeries(n, breachStart, breachEnd int) *analyzers.TimeSeries {
+	series := &analyzers.TimeSeries{Name: "breach", DataPoints: make([]analyzers.DataPoint, n)}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range series.DataPoints {
+		value := 0.0
+		if i >= breachStart && i < breachEnd {
+			value = 3.0 + float64(i-breachStart)/10
+		}
+		series.DataPoints[i] = analyzers.DataPoint{Timestamp: start.Add(time.Duration(i) * time.Second), Value: value}
+	}
+	return series
+}
+
+// boundAnomalies returns the anomalies of the configured bounds, as opposed
+// to those of the rules
+func boundAnomalies(result *analyzers.AnalysisResult) []analyzers.Anomaly {
+	var bounds []analyzers.Anomaly
+	for _, anomaly := range result.Anomalies {
+		if _, ok := anomaly.Metadata["rule_id"]; !ok {
+			bounds = append(bounds, anomaly)
+		}
+	}
+	return bounds
+}
+
+func TestThresholdMonitorCoalescesBreaches(t *testing.T) {
+	monitor, err := threshold.NewMonitor(nil)
+	if err != nil {
+		t.Fatalf("NewMonitor failed: %v", err)
+	}
+	result, err := monitor.Analyze(context.Background(), breachSeries(20, 5, 10))
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if bounds := boundAnomalies(result); len(bounds) != 5 {
+		t.Errorf("Expected an anomaly for each of the 5 points without a coalesce window, got %d", len(bounds))
+	}
+
+	monitor, err = threshold.NewMonitor(nil)
+	if err != nil {
+		t.Fatalf("NewMonitor failed: %v", err)
+	}
+	if err := monitor.Configure(map[string]interface{}{"coalesce_window": "2s"}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	result, err = monitor.Analyze(context.Background(), breachSeries(20, 5, 10))
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	bounds := boundAnomalies(result)
+	if len(bounds) != 1 {
+		t.Fatalf("Expected the sustained breach coalesced into one anomaly, got %d", len(bounds))
+	}
+	metadata := bounds[0].Metadata
+	if metadata["coalesced"] != true || metadata["point_count"] != 5 || metadata["duration"] != 4*time.Second || metadata["peak_value"] != 3.4 {
+		t.Errorf("Expected the extent and peak of the breach in the metadata, got %v", metadata)
+	}
+
+	if err := monitor.Configure(map[string]interface{}{"coalesce_window": "-1s"}); err == nil {
+		t.Error("Expected a negative coalesce window to be rejected")
+	}
+}
+
+func TestThresholdMonitorSharesConcurrentAnalyses(t *testing.T) {
+	const callers = 8
+	// Whether calls overlap depends on scheduling, so a few rounds are
+	// allowed for them to
+	for round := 0; round < 20; round++ {
+		monitor, err := threshold.NewMonitor(nil)
+		if err != nil {
+			t.Fatalf("NewMonitor failed: %v", err)
+		}
+		if err := monitor.Configure(map[string]interface{}{"coalesce_window": "2s"}); err != nil {
+			t.Fatalf("Configure failed: %v", err)
+		}
+		series := breachSeries(5000, 100, 4900)
+
+		results := make([]*analyzers.AnalysisResult, callers)
+		release := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-release
+				result, err := monitor.Analyze(context.Background(), series)
+				if err != nil {
+					t.Errorf("Analyze failed: %v", err)
+					return
+				}
+				results[i] = result
+			}(i)
+		}
+		close(release)
+		wg.Wait()
+		if t.Failed() {
+			return
+		}
+
+		distinct := make(map[*analyzers.AnalysisResult]bool)
+		for _, result := range results {
+			distinct[result] = true
+			if bounds := boundAnomalies(result); len(bounds) != 1 || bounds[0].Metadata["point_count"] != 4800 {
+				t.Fatalf("Expected every caller to get the sustained breach as one anomaly, got %d", len(bounds))
+			}
+		}
+		if len(distinct) < callers {
+			return
+		}
+	}
+	t.Error("Expected identical concurrent analyses to share an evaluation")
+}
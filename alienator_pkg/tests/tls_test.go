@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/server"
+	"go.uber.org/zap"
+)
+
+// testCA issues certificates for the TLS tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for name, usable by a server or client
+func (ca *testCA) issue(t *testing.T, name string, serial int64, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeTLSFiles(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func newTLSConfig(dir string) config.TLSConfig {
+	return config.TLSConfig{
+		Enabled:         true,
+		CertFile:        filepath.Join(dir, "server.crt"),
+		KeyFile:         filepath.Join(dir, "server.key"),
+		ClientCAFile:    filepath.Join(dir, "ca.crt"),
+		AdminClientAuth: true,
+		MinVersion:      "1.2",
+	}
+}
+
+func TestMutualTLSForAdminRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "localhost", 2, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "admin-client", 3, x509.ExtKeyUsageClientAuth)
+	writeTLSFiles(t, dir, map[string][]byte{"server.crt": serverCert, "server.key": serverKey, "ca.crt": ca.pem})
+
+	reloader, err := server.NewCertReloader(newTLSConfig(dir), zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to load certificates: %v", err)
+	}
+	tlsConfig, err := reloader.TLSConfig("1.2")
+	if err != nil {
+		t.Fatalf("Failed to build TLS config: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/public", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	router.GET("/admin", middleware.RequireClientCert(), func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	srv := httptest.NewUnstartedServer(router)
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+	pair, err := tls.X509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatalf("Failed to load client certificate: %v", err)
+	}
+	authenticated := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{pair}}}}
+
+	for _, tc := range []struct {
+		name   string
+		client *http.Client
+		path   string
+		status int
+	}{
+		{"public without client certificate", anonymous, "/public", http.StatusOK},
+		{"admin without client certificate", anonymous, "/admin", http.StatusForbidden},
+		{"admin with client certificate", authenticated, "/admin", http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := tc.client.Get(srv.URL + tc.path)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.status {
+				t.Fatalf("Expected status %d, got %d", tc.status, resp.StatusCode)
+			}
+			if tc.status != http.StatusForbidden {
+				return
+			}
+			// A missing certificate is refused outright, not as a limit
+			var response models.APIResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Fatalf("Invalid response: %v", err)
+			}
+			if response.Error == nil || response.Error.Code != "CLIENT_CERT_REQUIRED" || response.Error.Rejection != nil {
+				t.Errorf("Expected a plain CLIENT_CERT_REQUIRED error, got %+v", response.Error)
+			}
+		})
+	}
+}
+
+func TestCertReloaderPicksUpRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	cert, key := ca.issue(t, "localhost", 2, x509.ExtKeyUsageServerAuth)
+	writeTLSFiles(t, dir, map[string][]byte{"server.crt": cert, "server.key": key, "ca.crt": ca.pem})
+
+	reloader, err := server.NewCertReloader(newTLSConfig(dir), zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to load certificates: %v", err)
+	}
+	before := reloader.Certificate().Certificate[0]
+
+	rotated, rotatedKey := ca.issue(t, "localhost", 4, x509.ExtKeyUsageServerAuth)
+	writeTLSFiles(t, dir, map[string][]byte{"server.crt": rotated, "server.key": rotatedKey})
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	after := reloader.Certificate().Certificate[0]
+	if bytes.Equal(after, before) {
+		t.Error("Expected the rotated certificate to be served after reload")
+	}
+
+	// A broken rotation keeps the working certificate
+	writeTLSFiles(t, dir, map[string][]byte{"server.key": []byte("not a key")})
+	if err := reloader.Reload(); err == nil {
+		t.Error("Expected a mismatched key to fail the reload")
+	}
+	if !bytes.Equal(reloader.Certificate().Certificate[0], after) {
+		t.Error("Expected the previous certificate to stay in service")
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	handler := server.RedirectHandler(8443)
+
+	req := httptest.NewRequest(http.MethodPost, "http://api.example.com:8080/api/v1/anomalies/detect?x=1", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPermanentRedirect {
+		t.Errorf("Expected a permanent redirect, got %d", rec.Code)
+	}
+	if location := rec.Header().Get("Location"); location != "https://api.example.com:8443/api/v1/anomalies/detect?x=1" {
+		t.Errorf("Unexpected redirect location %q", location)
+	}
+
+	rec = httptest.NewRecorder()
+	server.RedirectHandler(443).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://api.example.com/health", nil))
+	if location := rec.Header().Get("Location"); location != "https://api.example.com/health" {
+		t.Errorf("Expected the default HTTPS port to be omitted, got %q", location)
+	}
+}
+
+func TestTLSConfigValidation(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Server.TLS.Enabled = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected TLS without certificate files to be rejected")
+	}
+
+	cfg.Server.TLS.CertFile = "server.crt"
+	cfg.Server.TLS.KeyFile = "server.key"
+	cfg.Server.TLS.AdminClientAuth = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected admin client auth without a client CA to be rejected")
+	}
+
+	cfg.Server.TLS.ClientCAFile = "ca.crt"
+	cfg.Server.TLS.RedirectPort = cfg.Server.Port
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a redirect port equal to the server port to be rejected")
+	}
+
+	cfg.Server.TLS.RedirectPort = 80
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a complete TLS configuration to be valid, got %v", err)
+	}
+}
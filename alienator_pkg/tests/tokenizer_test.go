@@ -0,0 +1,263 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/analyzers/embedding"
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/analyzers/linguistic"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/tokenizer"
+	"go.uber.org/zap"
+)
+
+// countingTokenizer records how often the full text is split
+type countingTokenizer struct {
+	tokenizer.DefaultTokenizer
+	mu        sync.Mutex
+	text      string
+	words     int
+	sentences int
+}
+
+func (t *countingTokenizer) Words(text string) []string {
+	t.mu.Lock()
+	if text == t.text {
+		t.words++
+	}
+	t.mu.Unlock()
+	return t.DefaultTokenizer.Words(text)
+}
+
+func (t *countingTokenizer) Sentences(text string) []string {
+	t.mu.Lock()
+	t.sentences++
+	t.mu.Unlock()
+	return t.DefaultTokenizer.Sentences(text)
+}
+
+func TestDefaultTokenizer(t *testing.T) {
+	tokens := tokenizer.New(nil, "Hello there, World!  How are you?\nFine. ")
+
+	if words := tokens.Words(); !reflect.DeepEqual(words, []string{"Hello", "there,", "World!", "How", "are", "you?", "Fine."}) {
+		t.Errorf("Unexpected words: %q", words)
+	}
+	if lower := tokens.LowerWords(); lower[0] != "hello" || lower[2] != "world!" {
+		t.Errorf("Unexpected lowercase words: %q", lower)
+	}
+	if sentences := tokens.Sentences(); !reflect.DeepEqual(sentences, []string{"Hello there, World", "How are you", "Fine"}) {
+		t.Errorf("Unexpected sentences: %q", sentences)
+	}
+	if sentenceWords := tokens.SentenceWords(); len(sentenceWords) != 3 || len(sentenceWords[1]) != 3 {
+		t.Errorf("Unexpected sentence words: %q", sentenceWords)
+	}
+}
+
+func TestCleanWords(t *testing.T) {
+	if cleaned := tokenizer.CleanWords([]string{"\"Don’t", "STOP,", "--", "3.5%", "(it's)"}); !reflect.DeepEqual(cleaned, []string{"don't", "stop", "3.5", "it's"}) {
+		t.Errorf("Unexpected cleaned words: %q", cleaned)
+	}
+
+	tokens := tokenizer.New(nil, "It WON'T stop. Costs rose 40%!")
+	if cleaned := tokens.CleanSentenceWords(); !reflect.DeepEqual(cleaned, [][]string{{"it", "won't", "stop"}, {"costs", "rose", "40"}}) {
+		t.Errorf("Unexpected cleaned sentence words: %q", cleaned)
+	}
+}
+
+func TestNormalizedWords(t *testing.T) {
+	words, original := tokenizer.New(nil, "\"Hello,\" she said -- TWICE.").NormalizedWords()
+	if !reflect.DeepEqual(words, []string{"hello", "she", "said", "twice"}) {
+		t.Errorf("Unexpected normalized words: %q", words)
+	}
+	if !reflect.DeepEqual(original, []string{"\"Hello,\"", "she", "said", "TWICE."}) {
+		t.Errorf("Unexpected original words: %q", original)
+	}
+}
+
+func TestDetectorSharesTokenization(t *testing.T) {
+	text := "The committee met on Thursday. Everyone agreed that the plan needed more detail before a vote."
+	counting := &countingTokenizer{text: text}
+
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.SetTokenizer(counting)
+	detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+	detector.RegisterAnalyzer(linguistic.NewLinguisticAnalyzer())
+	detector.RegisterAnalyzer(embedding.NewEmbeddingAnalyzer())
+
+	if _, err := detector.AnalyzeTextAs(text, core.ContentTypeProse); err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+	if counting.words != 1 || counting.sentences != 1 {
+		t.Errorf("Expected the text to be split once, got %d word and %d sentence splits", counting.words, counting.sentences)
+	}
+}
+
+func TestSetTokenizerDuringAnalysis(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+
+	// Run with -race: replacing the tokenizer must not race with analyses
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			detector.SetTokenizer(tokenizer.DefaultTokenizer{})
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if _, err := detector.AnalyzeText("The committee met on Thursday. Everyone agreed the plan needed more detail."); err != nil {
+			t.Fatalf("Analysis failed: %v", err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestSentenceSegmentation(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"titles", "Dr. Smith met Mrs. Jones. They talked.", []string{"Dr. Smith met Mrs. Jones", "They talked"}},
+		{"decimals", "Pi is about 3.14 and e is 2.718. Both are irrational.", []string{"Pi is about 3.14 and e is 2.718", "Both are irrational"}},
+		{"latin shorthand", "Use a fruit, e.g. an apple. Or i.e. something else.", []string{"Use a fruit, e.g. an apple", "Or i.e. something else"}},
+		{"dotted acronyms", "She moved to the U.S. last year. The U.S. government agreed.", []string{"She moved to the U.S. last year", "The U.S. government agreed"}},
+		{"acronym ending a sentence", "He works in the U.S. Then he left.", []string{"He works in the U.S", "Then he left"}},
+		{"initials", "J. R. R. Tolkien wrote it. Readers loved it.", []string{"J. R. R. Tolkien wrote it", "Readers loved it"}},
+		{"trailing ellipsis", "Well... maybe not. I wonder… Perhaps later.", []string{"Well... maybe not", "I wonder", "Perhaps later"}},
+		{"quoted exclamation", `"Stop!" she said. "Why?" He asked.`, []string{`"Stop!" she said`, `"Why?"`, "He asked"}},
+		{"mixed terminators", "Really?! Yes. No way!!", []string{"Really", "Yes", "No way"}},
+		{"urls and versions", "Visit example.com for v1.2.3 notes. Thanks!", []string{"Visit example.com for v1.2.3 notes", "Thanks"}},
+		{"no terminal punctuation", "Just a fragment", []string{"Just a fragment"}},
+		{"only punctuation", "... !?", []string{}},
+	}
+
+	segmenter := tokenizer.NewRuleSegmenter()
+	for _, tt := range tests {
+		if got := segmenter.Sentences(tt.text); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+
+	if got := tokenizer.NewRuleSegmenter("Inc.").Sentences("Acme Inc. announced results. Shares rose."); len(got) != 2 || got[0] != "Acme Inc. announced results" {
+		t.Errorf("Expected a configured abbreviation to be honoured, got %q", got)
+	}
+	if got := (tokenizer.PunctuationSegmenter{}).Sentences("Dr. Smith arrived."); !reflect.DeepEqual(got, []string{"Dr", "Smith arrived"}) {
+		t.Errorf("Expected punctuation segmentation to split at every period, got %q", got)
+	}
+}
+
+func TestTokenizerFromConfig(t *testing.T) {
+	rules, err := tokenizer.FromConfig(config.SegmentationConfig{Mode: tokenizer.SegmentRules, Abbreviations: []string{"approx"}})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	if got := rules.Sentences("Dr. Smith arrived. He sat."); len(got) != 2 {
+		t.Errorf("Expected rule-based segmentation, got %q", got)
+	}
+
+	punctuation, err := tokenizer.FromConfig(config.SegmentationConfig{Mode: tokenizer.SegmentPunctuation})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	if got := punctuation.Sentences("Dr. Smith arrived. He sat."); len(got) != 3 {
+		t.Errorf("Expected punctuation segmentation, got %q", got)
+	}
+
+	if _, err := tokenizer.FromConfig(config.SegmentationConfig{Mode: "nltk"}); err == nil {
+		t.Error("Expected an unknown segmentation mode to be rejected")
+	}
+	cfg := config.Defaults()
+	cfg.Detector.Segmentation.Mode = "nltk"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "detector.segmentation.mode") {
+		t.Errorf("Expected config validation to reject the mode, got %v", err)
+	}
+}
+
+func TestScriptTokenizer(t *testing.T) {
+	script := tokenizer.ScriptTokenizer{}
+	tests := []struct {
+		text      string
+		words     []string
+		sentences []string
+	}{
+		{
+			text:      "我爱北京。今天很好！",
+			words:     []string{"我", "爱", "北", "京。", "今", "天", "很", "好！"},
+			sentences: []string{"我爱北京", "今天很好"},
+		},
+		{
+			text:      "東京タワーに行きました。「すごい！」と言った。",
+			words:     []string{"東", "京", "タワー", "に", "行", "きました。", "「すごい！」", "と", "言", "った。"},
+			sentences: []string{"東京タワーに行きました", "「すごい！」", "と言った"},
+		},
+		{
+			text:      "iPhoneを買った, 2024年",
+			words:     []string{"iPhone", "を", "買", "った,", "2024", "年"},
+			sentences: []string{"iPhoneを買った, 2024年"},
+		},
+	}
+	for _, tt := range tests {
+		if got := script.Words(tt.text); !reflect.DeepEqual(got, tt.words) {
+			t.Errorf("Expected words %q, got %q", tt.words, got)
+		}
+		if got := script.Sentences(tt.text); !reflect.DeepEqual(got, tt.sentences) {
+			t.Errorf("Expected sentences %q, got %q", tt.sentences, got)
+		}
+	}
+}
+
+func TestLanguageAwareTokenization(t *testing.T) {
+	aware, err := tokenizer.FromConfig(config.Defaults().Detector.Segmentation)
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	chinese := "我们今天去公园散步。天气很好，大家都很开心。"
+	if tokens := tokenizer.New(aware, chinese); len(tokens.Words()) < 10 || tokens.NaiveWords() {
+		t.Errorf("Expected Chinese text to be segmented, got %q", tokens.Words())
+	}
+	if tokens := tokenizer.New(aware, "Dr. Smith arrived. He sat."); len(tokens.Words()) != 5 || len(tokens.Sentences()) != 2 {
+		t.Errorf("Expected English text to be split on whitespace, got %q", tokens.Words())
+	}
+	naive := tokenizer.New(tokenizer.Default, chinese)
+	if len(naive.Words()) != 1 || !naive.NaiveWords() {
+		t.Errorf("Expected whitespace splitting of Chinese text to be reported naive, got %q", naive.Words())
+	}
+
+	analyzer := linguistic.NewLinguisticAnalyzer()
+	for _, tt := range []struct {
+		tokens *tokenizer.Tokens
+		naive  bool
+	}{
+		{tokenizer.New(aware, chinese), false},
+		{tokenizer.New(tokenizer.Default, chinese), true},
+	} {
+		result, err := analyzer.AnalyzeTokens(context.Background(), tt.tokens)
+		if err != nil {
+			t.Fatalf("Analysis failed: %v", err)
+		}
+		if result.Metadata["naive_word_split"] != tt.naive {
+			t.Errorf("Expected naive_word_split %v, got %v", tt.naive, result.Metadata["naive_word_split"])
+		}
+	}
+
+	cfg := config.Defaults()
+	cfg.Detector.Segmentation.Words = "whitespace"
+	whitespace, err := tokenizer.FromConfig(cfg.Detector.Segmentation)
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	if tokens := tokenizer.New(whitespace, chinese); !tokens.NaiveWords() {
+		t.Error("Expected whitespace-only splitting to leave Chinese text unsegmented")
+	}
+	cfg.Detector.Segmentation.Words = "dictionary"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "detector.segmentation.words") {
+		t.Errorf("Expected config validation to reject the word splitting, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+// outlierAnalyzer stands in for the embedding analyzer, reporting fixed
+// sentences as semantic outliers
+type outlierAnalyzer struct {
+	sentences []int
+}
+
+func (a *outlierAnalyzer) Name() string { return "embedding" }
+
+func (a *outlierAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return &models.AnalysisResult{
+		Score:      0.5,
+		Confidence: 0.9,
+		Metadata:   map[string]interface{}{"outlier_sentences": a.sentences},
+	}, nil
+}
+
+func TestTopSentences(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "As an AI"})
+	detector.RegisterAnalyzer(&outlierAnalyzer{sentences: []int{1}})
+
+	text := "The weather was pleasant today. As an AI, I cannot go outside. " +
+		"We walked to the park after lunch. As an AI language model, I must politely decline."
+	result, err := detector.AnalyzeTextAs(text, core.ContentTypeProse)
+	if err != nil {
+		t.Fatalf("Analysis failed: %v", err)
+	}
+
+	top, err := detector.TopSentences(text, result, 2)
+	if err != nil {
+		t.Fatalf("TopSentences failed: %v", err)
+	}
+	if len(top) != 2 || top[0].Index != 3 || top[1].Index != 1 {
+		t.Fatalf("Expected the two machine-sounding sentences, longest first, got %+v", top)
+	}
+	for _, sentence := range top {
+		if text[sentence.Offset:sentence.Offset+sentence.Length] != sentence.Text {
+			t.Errorf("Expected the offset to locate %q", sentence.Text)
+		}
+	}
+	if !top[1].Outlier || top[0].Outlier {
+		t.Errorf("Expected only sentence 1 to be marked as an embedding outlier, got %+v", top)
+	}
+
+	all, err := detector.TopSentences(text, result, 10)
+	if err != nil {
+		t.Fatalf("TopSentences failed: %v", err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("Expected every sentence, got %d", len(all))
+	}
+	total, weighted, weights := 0.0, 0.0, 0.0
+	for _, sentence := range all {
+		total += sentence.Contribution
+		weight := float64(sentence.Length) * sentence.Confidence
+		weighted += sentence.Score * weight
+		weights += weight
+	}
+	if math.Abs(total-weighted/weights) > 1e-9 {
+		t.Errorf("Expected the contributions to add up to the weighted mean %.4f, got %.4f", weighted/weights, total)
+	}
+
+	if _, err := detector.TopSentences(text, result, 0); err == nil {
+		t.Error("Expected a non-positive count to be rejected")
+	}
+	if !strings.Contains(top[0].Text, "language model") {
+		t.Errorf("Expected the sentence text, got %q", top[0].Text)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestTuneScores(t *testing.T) {
+	samples := []core.ScoredSample{
+		{Score: 0.9, Anomalous: true},
+		{Score: 0.8, Anomalous: true},
+		{Score: 0.55, Anomalous: true},
+		{Score: 0.6},
+		{Score: 0.3},
+		{Score: 0.2},
+	}
+
+	cases := []struct {
+		objective string
+		costs     core.TuningCosts
+		threshold float64
+	}{
+		{core.ObjectiveF1, core.DefaultTuningCosts(), 0.425},
+		// 0.425 and 0.7 both reach a J of 2/3; the higher wins
+		{core.ObjectiveYouden, core.DefaultTuningCosts(), 0.7},
+		{core.ObjectiveCost, core.TuningCosts{FalsePositive: 1, FalseNegative: 5}, 0.425},
+		{core.ObjectiveCost, core.TuningCosts{FalsePositive: 5, FalseNegative: 1}, 0.7},
+	}
+	for _, c := range cases {
+		tuning, err := core.TuneScores(samples, c.objective, c.costs)
+		if err != nil {
+			t.Fatalf("Tuning for %s failed: %v", c.objective, err)
+		}
+		if math.Abs(tuning.Best.Threshold-c.threshold) > 1e-9 {
+			t.Errorf("Expected %s with costs %+v to pick %g, got %g", c.objective, c.costs, c.threshold, tuning.Best.Threshold)
+		}
+	}
+
+	tuning, _ := core.TuneScores(samples, core.ObjectiveF1, core.DefaultTuningCosts())
+	if len(tuning.Curve) != 7 || tuning.Curve[0].Threshold != 0.1 || tuning.Curve[6].Threshold != 0.95 {
+		t.Errorf("Expected the midpoints between the scores swept in order, got %+v", tuning.Curve)
+	}
+	best := tuning.Best
+	if best.TP != 3 || best.FP != 1 || best.TN != 2 || best.FN != 0 || best.Precision != 0.75 || best.Recall != 1 {
+		t.Errorf("Expected the confusion matrix at the best threshold, got %+v", best)
+	}
+
+	if _, err := core.TuneScores(samples[:3], core.ObjectiveF1, core.DefaultTuningCosts()); err == nil {
+		t.Error("Expected a set without normal samples to be rejected")
+	}
+	if _, err := core.TuneScores(samples, "accuracy", core.DefaultTuningCosts()); err == nil {
+		t.Error("Expected an unknown objective to be rejected")
+	}
+	if _, err := core.TuneScores(samples, core.ObjectiveCost, core.TuningCosts{FalsePositive: 1}); err == nil {
+		t.Error("Expected a cost objective without positive costs to be rejected")
+	}
+}
+
+func TestAutoTuneThreshold(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "ALERT"})
+
+	labeled, err := core.ReadLabeledSamples(strings.NewReader(`{"text": "ALERT: the reactor is overheating.", "anomalous": true}
+
+{"text": "The committee met on Tuesday.", "anomalous": false}
+{"text": "ALERT: unknown signal received.", "anomalous": true, "content_type": "log"}
+{"text": "Lunch is served at noon.", "anomalous": false}
+`))
+	if err != nil {
+		t.Fatalf("Reading the labeled samples failed: %v", err)
+	}
+	if len(labeled) != 4 || !labeled[2].Anomalous || labeled[2].ContentType != core.ContentTypeLog {
+		t.Fatalf("Expected four samples read, got %+v", labeled)
+	}
+
+	threshold, err := detector.AutoTuneThreshold(labeled, core.ObjectiveF1)
+	if err != nil {
+		t.Fatalf("Tuning failed: %v", err)
+	}
+	flagged, _ := detector.AnalyzeText(labeled[0].Text)
+	normal, _ := detector.AnalyzeText(labeled[1].Text)
+	if threshold <= normal.Score || threshold >= flagged.Score {
+		t.Errorf("Expected a threshold between %g and %g, got %g", normal.Score, flagged.Score, threshold)
+	}
+
+	cfg := config.Defaults().Detector
+	cfg.Tuning.FalsePositiveCost = 0
+	if err := detector.ApplyConfig(cfg); err == nil {
+		t.Error("Expected a zero tuning cost to be rejected")
+	}
+	cfg.Tuning.FalsePositiveCost = 3
+	if err := detector.ApplyConfig(cfg); err != nil {
+		t.Fatalf("Applying the configuration failed: %v", err)
+	}
+	tuning, err := detector.TuneThreshold(labeled, core.ObjectiveCost)
+	if err != nil || tuning.Costs.FalsePositive != 3 || tuning.Best.Cost != 0 {
+		t.Errorf("Expected the configured costs weighed, got %+v (%v)", tuning, err)
+	}
+
+	if _, err := core.ReadLabeledSamples(strings.NewReader("{\"text\": \"x\"}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected a malformed line to be reported, got %v", err)
+	}
+	if _, err := detector.TuneThreshold([]core.LabeledSample{{Text: "x", ContentType: "poetry"}}, core.ObjectiveF1); err == nil {
+		t.Error("Expected an unsupported content type to be rejected")
+	}
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/models"
+	"github.com/ruvnet/alienator/internal/validation"
+)
+
+func TestRequestValidationFieldErrors(t *testing.T) {
+	v := validation.NewValidator()
+
+	req := models.RegisterRequest{
+		Email:     "not-an-email",
+		Username:  "ok_user",
+		FirstName: "Ada",
+		LastName:  "Lovelace",
+		Password:  "",
+	}
+
+	err := v.ValidateStruct(&req)
+	var validationErr *validation.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+
+	rules := make(map[string]string)
+	for _, fe := range validationErr.Errors {
+		rules[fe.Field] = fe.Rule
+		if fe.Field == "password" && fe.Value != nil {
+			t.Error("Password value must not be echoed in validation errors")
+		}
+	}
+	if rules["email"] != "email" {
+		t.Errorf("Expected email rule failure, got %q", rules["email"])
+	}
+	if rules["password"] != "required" {
+		t.Errorf("Expected password required failure, got %q", rules["password"])
+	}
+
+	detection := models.DetectionRequest{
+		Data:      map[string]interface{}{"value": 1},
+		Threshold: 1.5,
+	}
+	err = v.ValidateStruct(&detection)
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 1 || validationErr.Errors[0].Rule != "lte" {
+		t.Errorf("Expected a single threshold range failure, got %v", err)
+	}
+}
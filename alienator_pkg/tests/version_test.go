@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruvnet/alienator/internal/config"
+	"github.com/ruvnet/alienator/internal/middleware"
+	"github.com/ruvnet/alienator/internal/models"
+)
+
+// newVersionRouter serves the negotiated schema version as a response in
+// that version
+func newVersionRouter(cfg config.VersioningConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.SchemaVersion(cfg))
+	router.GET("/version", func(c *gin.Context) {
+		middleware.Respond(c, http.StatusOK, models.APIResponse{Success: true, Data: middleware.GetSchemaVersion(c)})
+	})
+	return router
+}
+
+func TestSchemaVersionNegotiation(t *testing.T) {
+	router := newVersionRouter(config.VersioningConfig{})
+
+	for _, tc := range []struct {
+		requested string
+		expected  string
+	}{
+		{"v1", "v1"},
+		{"V1", "v1"},
+		{"1", "v1"},
+		{" v2 ", "v2"},
+		{"2", "v2"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/version", nil)
+		req.Header.Set("Accept-Version", tc.requested)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected %q to be accepted, got %d", tc.requested, w.Code)
+		}
+		if got := w.Header().Get("Content-Version"); got != tc.expected {
+			t.Errorf("Expected %q to negotiate %s, got Content-Version %q", tc.requested, tc.expected, got)
+		}
+
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		if got := string(envelope["data"]); got != `"`+tc.expected+`"` {
+			t.Errorf("Expected handlers to see %s for %q, got %s", tc.expected, tc.requested, got)
+		}
+		// Only the v2 envelope names its version
+		_, versioned := envelope["version"]
+		if versioned != (tc.expected == models.SchemaVersionV2) {
+			t.Errorf("Expected the %s envelope for %q, got %s", tc.expected, tc.requested, w.Body.String())
+		}
+	}
+}
+
+func TestSchemaVersionDefault(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      config.VersioningConfig
+		expected string
+	}{
+		{"unset", config.VersioningConfig{}, models.LatestSchemaVersion},
+		{"configured", config.VersioningConfig{DefaultVersion: "1"}, models.SchemaVersionV1},
+		{"unsupported", config.VersioningConfig{DefaultVersion: "v9"}, models.LatestSchemaVersion},
+	} {
+		w := httptest.NewRecorder()
+		newVersionRouter(tc.cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+		if w.Code != http.StatusOK || w.Header().Get("Content-Version") != tc.expected {
+			t.Errorf("%s default: expected %s, got status %d and Content-Version %q", tc.name, tc.expected, w.Code, w.Header().Get("Content-Version"))
+		}
+	}
+
+	// A custom header replaces Accept-Version
+	router := newVersionRouter(config.VersioningConfig{Header: "X-Schema-Version"})
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	req.Header.Set("X-Schema-Version", "v1")
+	req.Header.Set("Accept-Version", "v9")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Version") != models.SchemaVersionV1 {
+		t.Errorf("Expected v1 from the custom header, got status %d and Content-Version %q", w.Code, w.Header().Get("Content-Version"))
+	}
+}
+
+func TestSchemaVersionRejectsUnsupported(t *testing.T) {
+	router := newVersionRouter(config.VersioningConfig{})
+
+	for _, requested := range []string{"v3", "latest", "v1.0", "vv1"} {
+		req := httptest.NewRequest(http.MethodGet, "/version", nil)
+		req.Header.Set("Accept-Version", requested)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotAcceptable {
+			t.Errorf("Expected %q to be rejected with 406, got %d", requested, w.Code)
+			continue
+		}
+		var response models.APIResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		if response.Error == nil || response.Error.Code != "UNSUPPORTED_VERSION" || response.Error.Details != "Supported versions: v1, v2" {
+			t.Errorf("Expected the supported versions listed for %q, got %+v", requested, response.Error)
+		}
+		if w.Header().Get("Content-Version") != "" {
+			t.Errorf("Expected no Content-Version for %q, got %q", requested, w.Header().Get("Content-Version"))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ruvnet/alienator/internal/analyzers/entropy"
+	"github.com/ruvnet/alienator/internal/core"
+	"github.com/ruvnet/alienator/internal/models"
+	"go.uber.org/zap"
+)
+
+type warmingAnalyzer struct {
+	warmed bool
+	err    error
+}
+
+func (a *warmingAnalyzer) Name() string { return "warming" }
+
+func (a *warmingAnalyzer) Analyze(ctx context.Context, text string) (*models.AnalysisResult, error) {
+	return &models.AnalysisResult{Score: 0.1, Confidence: 0.5}, nil
+}
+
+func (a *warmingAnalyzer) Warmup(ctx context.Context) error {
+	a.warmed = true
+	return a.err
+}
+
+func TestDetectorWarmup(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	warming := &warmingAnalyzer{}
+	detector.RegisterAnalyzer(entropy.NewEntropyAnalyzer())
+	detector.RegisterAnalyzer(warming)
+
+	if detector.Ready() {
+		t.Fatal("Detector must not be ready before warm-up")
+	}
+	if err := detector.Warmup(context.Background()); err != nil {
+		t.Fatalf("Warm-up failed: %v", err)
+	}
+	if !warming.warmed {
+		t.Error("Expected warm-up to initialize analyzers implementing Warmer")
+	}
+	if !detector.Ready() {
+		t.Error("Detector must be ready after warm-up")
+	}
+}
+
+func TestDetectorWarmupFailure(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	warmErr := errors.New("model unavailable")
+	detector.RegisterAnalyzer(&warmingAnalyzer{err: warmErr})
+
+	if err := detector.Warmup(context.Background()); !errors.Is(err, warmErr) {
+		t.Errorf("Expected warm-up error to wrap analyzer failure, got %v", err)
+	}
+	if detector.Ready() {
+		t.Error("Detector must not be ready after a failed warm-up")
+	}
+}
+
+// flakyWarmingAnalyzer fails to warm up until its failures run out
+type flakyWarmingAnalyzer struct {
+	warmingAnalyzer
+	failures int
+	attempts int
+}
+
+func (a *flakyWarmingAnalyzer) Warmup(ctx context.Context) error {
+	a.attempts++
+	if a.failures > 0 {
+		a.failures--
+		return errors.New("model unavailable")
+	}
+	return nil
+}
+
+func TestDetectorWarmupRetries(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	flaky := &flakyWarmingAnalyzer{failures: 2}
+	detector.RegisterAnalyzer(flaky)
+
+	if err := detector.WarmupWithRetry(context.Background(), time.Second, time.Millisecond); err != nil {
+		t.Fatalf("Expected warm-up to succeed once the analyzer recovers, got %v", err)
+	}
+	if flaky.attempts != 3 || !detector.Ready() {
+		t.Errorf("Expected ready after 3 attempts, got %d attempts and ready %v", flaky.attempts, detector.Ready())
+	}
+
+	// Retries stop with the context, leaving the detector unready
+	detector = core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&flakyWarmingAnalyzer{failures: 1 << 30})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := detector.WarmupWithRetry(ctx, time.Second, time.Millisecond); err == nil {
+		t.Error("Expected warm-up to give up once the context is done")
+	}
+	if detector.Ready() {
+		t.Error("Detector must not be ready after failed warm-ups")
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruvnet/alienator/internal/core"
+	"go.uber.org/zap"
+)
+
+func TestSplitWindowsKeepsWordsWhole(t *testing.T) {
+	text := strings.Repeat("word ", 50)
+
+	windows := core.SplitWindows(text, 32)
+	if len(windows) < 7 {
+		t.Fatalf("Expected at least 7 windows, got %d", len(windows))
+	}
+	for i, window := range windows {
+		for _, word := range strings.Fields(window.Text) {
+			if word != "word" {
+				t.Errorf("Window %d split a word: %q", i, window.Text)
+			}
+		}
+		if i > 0 && window.Start != windows[i-1].End {
+			t.Errorf("Window %d starts at %d, expected %d", i, window.Start, windows[i-1].End)
+		}
+	}
+
+	if windows := core.SplitWindows("   \n\n  ", 4); len(windows) != 0 {
+		t.Errorf("Expected no windows for blank text, got %d", len(windows))
+	}
+}
+
+func TestAnalyzeTextWindowedDetectsCoAuthoredText(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "delve"})
+
+	human := strings.Repeat("we went to the lake and fished all day. ", 5)
+	machine := strings.Repeat("let us delve in the rich tapestry here. ", 5)
+
+	cfg := core.DefaultWindowConfig()
+	cfg.WindowSize = 200
+
+	mixed, err := detector.AnalyzeTextWindowed(human+machine+human+machine, core.ContentTypeProse, cfg)
+	if err != nil {
+		t.Fatalf("AnalyzeTextWindowed failed: %v", err)
+	}
+	if len(mixed.Windows) != 4 {
+		t.Fatalf("Expected 4 windows, got %d", len(mixed.Windows))
+	}
+	estimate := mixed.CoAuthorship
+	if !estimate.Reliable || estimate.Likelihood < 0.99 {
+		t.Errorf("Expected alternating windows to look co-authored, got %+v", estimate)
+	}
+	if estimate.AIFraction < 0.45 || estimate.AIFraction > 0.55 {
+		t.Errorf("Expected about half the text to be machine-written, got %.2f", estimate.AIFraction)
+	}
+	if mixed.Aggregate.Metadata["co_authorship"] != estimate {
+		t.Error("Expected the estimate in the aggregate's metadata")
+	}
+
+	uniform, err := detector.AnalyzeTextWindowed(machine+machine+machine+machine, core.ContentTypeProse, cfg)
+	if err != nil {
+		t.Fatalf("AnalyzeTextWindowed failed: %v", err)
+	}
+	if uniform.CoAuthorship.Likelihood != 0 || uniform.CoAuthorship.AIFraction != 1 {
+		t.Errorf("Expected a single machine author, got %+v", uniform.CoAuthorship)
+	}
+}
+
+func TestAnalyzeTextWindowedShortText(t *testing.T) {
+	detector := core.NewAnomalyDetector(zap.NewNop(), nil)
+	detector.RegisterAnalyzer(&markerAnalyzer{marker: "delve"})
+
+	cfg := core.DefaultWindowConfig()
+	result, err := detector.AnalyzeTextWindowed("we delve into a short note.", core.ContentTypeAuto, cfg)
+	if err != nil {
+		t.Fatalf("AnalyzeTextWindowed failed: %v", err)
+	}
+	if result.CoAuthorship.Reliable || result.CoAuthorship.Likelihood != 0 {
+		t.Errorf("Expected a single window to be unreliable, got %+v", result.CoAuthorship)
+	}
+	if result.Aggregate.Score != 0.95 {
+		t.Errorf("Expected the aggregate to match the only window, got %.2f", result.Aggregate.Score)
+	}
+
+	cfg.MixedSpread = 0
+	if _, err := detector.AnalyzeTextWindowed("text", core.ContentTypeAuto, cfg); err == nil {
+		t.Error("Expected a zero mixed spread to be rejected")
+	}
+}